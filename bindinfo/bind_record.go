@@ -52,6 +52,8 @@ const (
 	Capture = "capture"
 	// Evolve indicates the binding is evolved by TiDB from old bindings.
 	Evolve = "evolve"
+	// Regression indicates the binding is created by TiDB automatically to fall back to the previous plan after a plan regression.
+	Regression = "regression"
 	// Builtin indicates the binding is a builtin record for internal locking purpose. It is also the status for the builtin binding.
 	Builtin = "builtin"
 )
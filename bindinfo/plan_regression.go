@@ -0,0 +1,169 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindinfo
+
+import (
+	"context"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/failpoint"
+	"github.com/pingcap/tidb/metrics"
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/logutil"
+	utilparser "github.com/pingcap/tidb/util/parser"
+	"github.com/pingcap/tidb/util/sqlexec"
+	"github.com/pingcap/tidb/util/stmtsummary"
+	"go.uber.org/zap"
+)
+
+const (
+	// planRegressionMinExecCount is the min execution count of both plans before their latencies are compared,
+	// so that a few slow executions won't be regarded as a plan regression.
+	planRegressionMinExecCount = 5
+
+	planRegressionApplied  = "applied"
+	planRegressionRejected = "rejected"
+)
+
+// DetectPlanRegressions compares the newest plan of each statement in the statement summary with its previous plan.
+// If the average latency of the newest plan is more than `ratio` times that of the previous plan, a binding is created
+// to fall back to the previous plan, and the regression is recorded in mysql.plan_regressions.
+func (h *BindHandle) DetectPlanRegressions(ratio float64) {
+	stmts := stmtsummary.StmtSummaryByDigestMap.GetPlanHistoryStmts()
+	parser4Regression := parser.New()
+	for i := 1; i < len(stmts); i++ {
+		oldStmt, newStmt := stmts[i-1], stmts[i]
+		if !isSameStmt(oldStmt, newStmt) {
+			continue
+		}
+		// Only the newest plan of the statement is compared with its previous plan.
+		if i+1 < len(stmts) && isSameStmt(newStmt, stmts[i+1]) {
+			continue
+		}
+		if oldStmt.ExecCount < planRegressionMinExecCount || newStmt.ExecCount < planRegressionMinExecCount {
+			continue
+		}
+		oldAvgLatency := oldStmt.SumLatency / time.Duration(oldStmt.ExecCount)
+		newAvgLatency := newStmt.SumLatency / time.Duration(newStmt.ExecCount)
+		if float64(newAvgLatency) <= float64(oldAvgLatency)*ratio {
+			continue
+		}
+		if err := h.fixPlanRegression(parser4Regression, oldStmt, newStmt, oldAvgLatency, newAvgLatency); err != nil {
+			logutil.BgLogger().Warn("[sql-bind] fix plan regression failed", zap.String("SQL", newStmt.Query), zap.Error(err))
+		}
+	}
+}
+
+func isSameStmt(a, b *stmtsummary.PlanHistoryStmt) bool {
+	return a.Schema == b.Schema && a.Digest == b.Digest
+}
+
+// fixPlanRegression binds the statement to the previous plan and records the regression.
+func (h *BindHandle) fixPlanRegression(p *parser.Parser, oldStmt, newStmt *stmtsummary.PlanHistoryStmt, oldAvgLatency, newAvgLatency time.Duration) error {
+	stmt, err := p.ParseOneStmt(oldStmt.Query, oldStmt.Charset, oldStmt.Collation)
+	if err != nil {
+		return err
+	}
+	dbName := utilparser.GetDefaultDB(stmt, oldStmt.Schema)
+	normalizedSQL, digest := parser.NormalizeDigest(utilparser.RestoreWithDefaultDB(stmt, dbName, oldStmt.Query))
+	// The bindings created by users or the previous fixes are respected.
+	if r := h.GetBindRecord(digest.String(), normalizedSQL, dbName); r != nil && r.HasAvailableBinding() {
+		return nil
+	}
+	exec := h.sctx.Context.(sqlexec.RestrictedSQLExecutor)
+	rows, _, err := exec.ExecRestrictedSQL(context.TODO(), nil, `SELECT 1 FROM mysql.plan_regressions WHERE sql_digest = %? AND old_plan_digest = %? AND new_plan_digest = %?`,
+		newStmt.Digest, oldStmt.PlanDigest, newStmt.PlanDigest)
+	if err != nil {
+		return err
+	}
+	// The regression has been fixed before, and the binding has been rejected or dropped since then.
+	if len(rows) > 0 {
+		return nil
+	}
+	bindSQL := GenerateBindSQL(context.TODO(), stmt, oldStmt.PlanHint, true, dbName)
+	if bindSQL == "" {
+		return nil
+	}
+	charset, collation := h.sctx.GetSessionVars().GetCharsetInfo()
+	binding := Binding{
+		BindSQL:   bindSQL,
+		Status:    Enabled,
+		Charset:   charset,
+		Collation: collation,
+		Source:    Regression,
+	}
+	// The regression is recorded before the binding is created, so that the binding can always be rejected.
+	now := types.NewTime(types.FromGoTime(time.Now()), mysql.TypeTimestamp, 6)
+	err = h.execPlanRegressionSQL(`INSERT INTO mysql.plan_regressions VALUES (%?, %?, %?, %?, %?, %?, %?, %?, %?, %?)`,
+		newStmt.Digest, dbName, normalizedSQL, bindSQL, oldStmt.PlanDigest, newStmt.PlanDigest,
+		oldAvgLatency.Nanoseconds(), newAvgLatency.Nanoseconds(), now.String(), planRegressionApplied)
+	if err != nil {
+		return err
+	}
+	if err = h.createRegressionBinding(&BindRecord{OriginalSQL: normalizedSQL, Db: dbName, Bindings: []Binding{binding}}); err != nil {
+		// The record is deleted so that the regression can be fixed again.
+		if delErr := h.execPlanRegressionSQL(`DELETE FROM mysql.plan_regressions WHERE sql_digest = %? AND old_plan_digest = %? AND new_plan_digest = %?`,
+			newStmt.Digest, oldStmt.PlanDigest, newStmt.PlanDigest); delErr != nil {
+			logutil.BgLogger().Warn("[sql-bind] delete the plan regression failed", zap.String("digest", newStmt.Digest), zap.Error(delErr))
+		}
+		return err
+	}
+	metrics.PlanRegressionCounter.Inc()
+	logutil.BgLogger().Info("[sql-bind] plan regression detected, fall back to the previous plan",
+		zap.String("digest", newStmt.Digest), zap.String("oldPlanDigest", oldStmt.PlanDigest), zap.String("newPlanDigest", newStmt.PlanDigest),
+		zap.Duration("oldAvgLatency", oldAvgLatency), zap.Duration("newAvgLatency", newAvgLatency))
+	return nil
+}
+
+func (h *BindHandle) createRegressionBinding(record *BindRecord) error {
+	failpoint.Inject("createRegressionBindingFail", func() {
+		failpoint.Return(errors.New("mock create binding failure"))
+	})
+	// We don't need to pass the `sctx` because the plan hints come from an executed plan.
+	return h.CreateBindRecord(nil, record)
+}
+
+func (h *BindHandle) execPlanRegressionSQL(sql string, args ...interface{}) error {
+	h.sctx.Lock()
+	defer h.sctx.Unlock()
+	_, err := h.sctx.Context.(sqlexec.SQLExecutor).ExecuteInternal(context.TODO(), sql, args...)
+	return err
+}
+
+// RejectPlanRegression drops the bindings created for the plan regressions of the SQL digest, and marks these
+// regressions as rejected so that they won't be fixed again.
+func (h *BindHandle) RejectPlanRegression(sqlDigest string) error {
+	exec := h.sctx.Context.(sqlexec.RestrictedSQLExecutor)
+	rows, _, err := exec.ExecRestrictedSQL(context.TODO(), nil, `SELECT default_db, original_sql, bind_sql FROM mysql.plan_regressions WHERE sql_digest = %? AND status = %?`,
+		sqlDigest, planRegressionApplied)
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return errors.Errorf("there is no applied plan regression for the SQL digest '%s'", sqlDigest)
+	}
+	for _, row := range rows {
+		err = h.DropBindRecord(row.GetString(1), row.GetString(0), &Binding{BindSQL: row.GetString(2)})
+		if err != nil {
+			return err
+		}
+	}
+
+	return h.execPlanRegressionSQL(`UPDATE mysql.plan_regressions SET status = %? WHERE sql_digest = %? AND status = %?`,
+		planRegressionRejected, sqlDigest, planRegressionApplied)
+}
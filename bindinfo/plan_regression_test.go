@@ -0,0 +1,117 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindinfo_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pingcap/failpoint"
+	"github.com/pingcap/tidb/bindinfo"
+	"github.com/pingcap/tidb/parser/auth"
+	"github.com/pingcap/tidb/testkit"
+	"github.com/pingcap/tidb/util/stmtsummary"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixPlanRegression(t *testing.T) {
+	store, dom, clean := testkit.CreateMockStoreAndDomain(t)
+	defer clean()
+
+	tk := testkit.NewTestKit(t, store)
+	require.True(t, tk.Session().Auth(&auth.UserIdentity{Username: "root", Hostname: "%"}, nil, nil))
+	stmtsummary.StmtSummaryByDigestMap.Clear()
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t(a int, b int, c int, key idx_b(b), key idx_c(c))")
+
+	tk.MustExec("select * from t where b = 1 and c > 1")
+	tk.MustExec("select /*+ use_index(t, idx_c) */ * from t where b = 1 and c > 1")
+	// Both plans are executed too few times to be compared.
+	dom.BindHandle().DetectPlanRegressions(0)
+	tk.MustQuery("show global bindings").Check(testkit.Rows())
+
+	stmtsummary.StmtSummaryByDigestMap.Clear()
+	for i := 0; i < 5; i++ {
+		tk.MustExec("select * from t where b = 1 and c > 1")
+	}
+	for i := 0; i < 5; i++ {
+		tk.MustExec("select /*+ use_index(t, idx_c) */ * from t where b = 1 and c > 1")
+	}
+	// The new plan is not slow enough to be regarded as a regression.
+	dom.BindHandle().DetectPlanRegressions(1e9)
+	tk.MustQuery("show global bindings").Check(testkit.Rows())
+	// Ratio 0 regards the new plan as a regression no matter how fast it is.
+	dom.BindHandle().DetectPlanRegressions(0)
+	rows := tk.MustQuery("show global bindings").Rows()
+	require.Len(t, rows, 1)
+	require.Equal(t, "select * from `test` . `t` where `b` = ? and `c` > ?", rows[0][0])
+	require.Equal(t, "SELECT /*+ use_index(@`sel_1` `test`.`t` `idx_b`)*/ * FROM `test`.`t` WHERE `b` = 1 AND `c` > 1", rows[0][1])
+	require.Equal(t, bindinfo.Regression, rows[0][8])
+	tk.MustQuery("explain format = 'brief' select /*+ use_index(t, idx_c) */ * from t where b = 2 and c > 2")
+	tk.MustQuery("select @@last_plan_from_binding").Check(testkit.Rows("1"))
+
+	rows = tk.MustQuery("admin show plan regressions").Rows()
+	require.Len(t, rows, 1)
+	digest := rows[0][0].(string)
+	require.Equal(t, "test", rows[0][1])
+	require.Equal(t, "select * from `test` . `t` where `b` = ? and `c` > ?", rows[0][2])
+	require.NotEqual(t, rows[0][4], rows[0][5])
+	require.Equal(t, "applied", rows[0][9])
+
+	// The regression is fixed only once.
+	dom.BindHandle().DetectPlanRegressions(0)
+	require.Len(t, tk.MustQuery("admin show plan regressions").Rows(), 1)
+
+	tk.MustExec(fmt.Sprintf("admin reject plan regression '%s'", digest))
+	tk.MustQuery("show global bindings").Check(testkit.Rows())
+	tk.MustQuery("admin show plan regressions").CheckAt([]int{0, 9}, testkit.Rows(digest+" rejected"))
+	// The rejected regression won't be fixed again.
+	dom.BindHandle().DetectPlanRegressions(0)
+	tk.MustQuery("show global bindings").Check(testkit.Rows())
+	tk.MustGetErrMsg(fmt.Sprintf("admin reject plan regression '%s'", digest),
+		fmt.Sprintf("there is no applied plan regression for the SQL digest '%s'", digest))
+}
+
+func TestFixPlanRegressionCreateBindingFail(t *testing.T) {
+	store, dom, clean := testkit.CreateMockStoreAndDomain(t)
+	defer clean()
+
+	tk := testkit.NewTestKit(t, store)
+	require.True(t, tk.Session().Auth(&auth.UserIdentity{Username: "root", Hostname: "%"}, nil, nil))
+	stmtsummary.StmtSummaryByDigestMap.Clear()
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t(a int, b int, c int, key idx_b(b), key idx_c(c))")
+	for i := 0; i < 5; i++ {
+		tk.MustExec("select * from t where b = 1 and c > 1")
+	}
+	for i := 0; i < 5; i++ {
+		tk.MustExec("select /*+ use_index(t, idx_c) */ * from t where b = 1 and c > 1")
+	}
+
+	fpName := "github.com/pingcap/tidb/bindinfo/createRegressionBindingFail"
+	require.NoError(t, failpoint.Enable(fpName, "return"))
+	dom.BindHandle().DetectPlanRegressions(0)
+	require.NoError(t, failpoint.Disable(fpName))
+	// The regression isn't recorded without the binding.
+	tk.MustQuery("show global bindings").Check(testkit.Rows())
+	tk.MustQuery("admin show plan regressions").Check(testkit.Rows())
+
+	// The regression is fixed again.
+	dom.BindHandle().DetectPlanRegressions(0)
+	require.Len(t, tk.MustQuery("show global bindings").Rows(), 1)
+	tk.MustQuery("admin show plan regressions").CheckAt([]int{9}, testkit.Rows("applied"))
+}
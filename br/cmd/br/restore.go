@@ -37,6 +37,22 @@ func runRestoreCommand(command *cobra.Command, cmdName string) error {
 	return nil
 }
 
+func runRestorePointCommand(command *cobra.Command, cmdName string) error {
+	cfg := task.RestorePointConfig{
+		RestoreConfig: task.RestoreConfig{Config: task.Config{LogProgress: HasLogFile()}},
+	}
+	if err := cfg.ParseFromFlags(command.Flags()); err != nil {
+		command.SilenceUsage = false
+		return errors.Trace(err)
+	}
+
+	if err := task.RunRestorePoint(GetDefaultContext(), tidbGlue, cmdName, &cfg); err != nil {
+		log.Error("failed to restore to the point in time", zap.Error(err))
+		return errors.Trace(err)
+	}
+	return nil
+}
+
 func runRestoreRawCommand(command *cobra.Command, cmdName string) error {
 	cfg := task.RestoreRawConfig{
 		RawKvConfig: task.RawKvConfig{Config: task.Config{LogProgress: HasLogFile()}},
@@ -83,6 +99,7 @@ func NewRestoreCommand() *cobra.Command {
 		newDBRestoreCommand(),
 		newTableRestoreCommand(),
 		newRawRestoreCommand(),
+		newPointRestoreCommand(),
 	)
 	task.DefineRestoreFlags(command.PersistentFlags())
 
@@ -141,3 +158,17 @@ func newRawRestoreCommand() *cobra.Command {
 	task.DefineRawRestoreFlags(command)
 	return command
 }
+
+func newPointRestoreCommand() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "point",
+		Short: "(experimental) restore the cluster to a point in time from a full backup and the log backup",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runRestorePointCommand(cmd, task.PointRestoreCmd)
+		},
+	}
+	task.DefineFilterFlags(command, filterOutSysAndMemTables)
+	task.DefineRestorePointFlags(command)
+	return command
+}
@@ -0,0 +1,92 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package main
+
+import (
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"github.com/pingcap/tidb/br/pkg/gluetikv"
+	"github.com/pingcap/tidb/br/pkg/summary"
+	"github.com/pingcap/tidb/br/pkg/task"
+	"github.com/pingcap/tidb/br/pkg/utils"
+	"github.com/pingcap/tidb/br/pkg/version/build"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+func runStreamStartCommand(command *cobra.Command, cmdName string) error {
+	cfg := task.StreamConfig{Config: task.Config{LogProgress: HasLogFile()}}
+	if err := cfg.ParseStreamStartFromFlags(command.Flags()); err != nil {
+		command.SilenceUsage = false
+		return errors.Trace(err)
+	}
+	if err := task.RunStreamStart(GetDefaultContext(), gluetikv.Glue{}, cmdName, &cfg); err != nil {
+		log.Error("failed to run log backup", zap.Error(err))
+		return errors.Trace(err)
+	}
+	return nil
+}
+
+func runStreamTruncateCommand(command *cobra.Command, cmdName string) error {
+	cfg := task.StreamConfig{Config: task.Config{LogProgress: HasLogFile()}}
+	if err := cfg.ParseStreamTruncateFromFlags(command.Flags()); err != nil {
+		command.SilenceUsage = false
+		return errors.Trace(err)
+	}
+	if err := task.RunStreamTruncate(GetDefaultContext(), gluetikv.Glue{}, cmdName, &cfg); err != nil {
+		log.Error("failed to truncate log backup", zap.Error(err))
+		return errors.Trace(err)
+	}
+	return nil
+}
+
+// NewStreamCommand returns a log backup subcommand.
+func NewStreamCommand() *cobra.Command {
+	command := &cobra.Command{
+		Use:          "log",
+		Short:        "(experimental) back up the changes of a TiDB cluster continuously for point-in-time recovery",
+		SilenceUsage: true,
+		PersistentPreRunE: func(c *cobra.Command, args []string) error {
+			if err := Init(c); err != nil {
+				return errors.Trace(err)
+			}
+			build.LogInfo(build.BR)
+			utils.LogEnvVariables()
+			task.LogArguments(c)
+
+			summary.SetUnit(summary.BackupUnit)
+			return nil
+		},
+	}
+	command.AddCommand(
+		newStreamStartCommand(),
+		newStreamTruncateCommand(),
+	)
+	return command
+}
+
+func newStreamStartCommand() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "start",
+		Short: "start or resume a log backup task, which runs until it's interrupted",
+		Args:  cobra.NoArgs,
+		RunE: func(command *cobra.Command, _ []string) error {
+			return runStreamStartCommand(command, task.StreamStartCmd)
+		},
+	}
+	task.DefineStreamStartFlags(command)
+	return command
+}
+
+func newStreamTruncateCommand() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "truncate",
+		Short: "remove the log backup files before a ts",
+		Args:  cobra.NoArgs,
+		RunE: func(command *cobra.Command, _ []string) error {
+			return runStreamTruncateCommand(command, task.StreamTruncateCmd)
+		},
+	}
+	task.DefineStreamTruncateFlags(command)
+	return command
+}
@@ -0,0 +1,49 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package backup
+
+import (
+	"context"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"github.com/pingcap/tidb/bindinfo"
+	"github.com/pingcap/tidb/br/pkg/metautil"
+	"github.com/pingcap/tidb/domain"
+	"github.com/pingcap/tidb/util/sqlexec"
+	"go.uber.org/zap"
+)
+
+const selectBindingsSQL = "SELECT original_sql, bind_sql, default_db, status, charset, collation, source " +
+	"FROM mysql.bind_info WHERE status IN (%?, %?) AND source != %? ORDER BY update_time"
+
+// BackupBindings reads the global SQL bindings which are in use, the pseudo binding
+// to lock the bindings table is skipped.
+func BackupBindings(ctx context.Context, dom *domain.Domain) ([]*metautil.Binding, error) {
+	pool := dom.SysSessionPool()
+	res, err := pool.Get()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer pool.Put(res)
+
+	exec := res.(sqlexec.RestrictedSQLExecutor)
+	rows, _, err := exec.ExecRestrictedSQL(ctx, nil, selectBindingsSQL, bindinfo.Enabled, bindinfo.Using, bindinfo.Builtin)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	bindings := make([]*metautil.Binding, 0, len(rows))
+	for _, row := range rows {
+		bindings = append(bindings, &metautil.Binding{
+			OriginalSQL: row.GetString(0),
+			BindSQL:     row.GetString(1),
+			DefaultDB:   row.GetString(2),
+			Status:      row.GetString(3),
+			Charset:     row.GetString(4),
+			Collation:   row.GetString(5),
+			Source:      row.GetString(6),
+		})
+	}
+	log.Info("backup bindings", zap.Int("count", len(bindings)))
+	return bindings, nil
+}
@@ -0,0 +1,34 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package backup_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pingcap/tidb/br/pkg/backup"
+	"github.com/pingcap/tidb/testkit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupBindings(t *testing.T) {
+	m, clean := createMockCluster(t)
+	defer clean()
+
+	tk := testkit.NewTestKit(t, m.Storage)
+	tk.MustExec("use test")
+	tk.MustExec("create table t (a int, b int, index idx_a(a), index idx_b(b))")
+	tk.MustExec("create global binding for select * from t where a = 1 using select * from t use index(idx_b) where a = 1")
+	tk.MustExec("create global binding for select * from t where b = 1 using select * from t use index(idx_a) where b = 1")
+	tk.MustExec("drop global binding for select * from t where b = 1")
+	// session bindings aren't backed up.
+	tk.MustExec("create session binding for select a from t using select a from t use index(idx_a)")
+
+	bindings, err := backup.BackupBindings(context.Background(), m.Domain)
+	require.NoError(t, err)
+	require.Len(t, bindings, 1)
+	require.Equal(t, "select * from `test` . `t` where `a` = ?", bindings[0].OriginalSQL)
+	require.Equal(t, "test", bindings[0].DefaultDB)
+	require.Equal(t, "enabled", bindings[0].Status)
+	require.Equal(t, "manual", bindings[0].Source)
+}
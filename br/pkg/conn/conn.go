@@ -14,6 +14,7 @@ import (
 	"github.com/pingcap/errors"
 	"github.com/pingcap/failpoint"
 	backuppb "github.com/pingcap/kvproto/pkg/brpb"
+	"github.com/pingcap/kvproto/pkg/cdcpb"
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/log"
 	berrors "github.com/pingcap/tidb/br/pkg/errors"
@@ -364,6 +365,28 @@ func (mgr *Mgr) GetBackupClient(ctx context.Context, storeID uint64) (backuppb.B
 	return backuppb.NewBackupClient(conn), nil
 }
 
+// GetChangeDataClient get or create a change data client of the store, which is used by the log backup.
+func (mgr *Mgr) GetChangeDataClient(ctx context.Context, storeID uint64) (cdcpb.ChangeDataClient, error) {
+	if ctx.Err() != nil {
+		return nil, errors.Trace(ctx.Err())
+	}
+
+	mgr.grpcClis.mu.Lock()
+	defer mgr.grpcClis.mu.Unlock()
+
+	if conn, ok := mgr.grpcClis.clis[storeID]; ok {
+		return cdcpb.NewChangeDataClient(conn), nil
+	}
+
+	conn, err := mgr.getGrpcConnLocked(ctx, storeID)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	// Cache the conn.
+	mgr.grpcClis.clis[storeID] = conn
+	return cdcpb.NewChangeDataClient(conn), nil
+}
+
 // ResetBackupClient reset the connection for backup client.
 func (mgr *Mgr) ResetBackupClient(ctx context.Context, storeID uint64) (backuppb.BackupClient, error) {
 	if ctx.Err() != nil {
@@ -54,6 +54,10 @@ var (
 	ErrRestoreRTsConstrain = errors.Normalize("resolved ts constrain violation", errors.RFCCodeText("BR:Restore:ErrRestoreResolvedTsConstrain"))
 
 	ErrPiTRInvalidCDCLogFormat = errors.Normalize("invalid cdc log format", errors.RFCCodeText("BR:PiTR:ErrPiTRInvalidCDCLogFormat"))
+	ErrPiTRInvalidLogFile      = errors.Normalize("invalid log backup file", errors.RFCCodeText("BR:PiTR:ErrPiTRInvalidLogFile"))
+	ErrPiTRLogNotCover         = errors.Normalize("log backup does not cover the restore range", errors.RFCCodeText("BR:PiTR:ErrPiTRLogNotCover"))
+	ErrPiTRTaskMismatch        = errors.Normalize("log backup task mismatch", errors.RFCCodeText("BR:PiTR:ErrPiTRTaskMismatch"))
+	ErrPiTRSchemaChanged       = errors.Normalize("schema changed during the log restore range", errors.RFCCodeText("BR:PiTR:ErrPiTRSchemaChanged"))
 
 	ErrStorageUnknown           = errors.Normalize("unknown external storage error", errors.RFCCodeText("BR:ExternalStorage:ErrStorageUnknown"))
 	ErrStorageInvalidConfig     = errors.Normalize("invalid external storage config", errors.RFCCodeText("BR:ExternalStorage:ErrStorageInvalidConfig"))
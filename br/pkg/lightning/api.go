@@ -0,0 +1,193 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lightning
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/lightning/common"
+	"github.com/pingcap/tidb/br/pkg/lightning/restore"
+	"github.com/pingcap/tidb/br/pkg/lightning/web"
+)
+
+// tableProgress is the progress and the state of a table in the API responses.
+type tableProgress struct {
+	*web.TableProgress
+	State restore.TableState `json:"state"`
+}
+
+// handleAPIV1 serves the version 1 API of the current task:
+//
+//	GET  /api/v1/tables                        the progress of all the tables
+//	GET  /api/v1/tables/{db}/{table}           the progress of the table, including the engines and chunks
+//	POST /api/v1/tables/{db}/{table}/{action}  pause, resume, cancel or retry the table
+//
+// The names of the database and the table are escaped as URL path segments. The task isn't finished while
+// any table is failed or canceled, until the table is retried successfully or the task is stopped.
+func (l *Lightning) handleAPIV1(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	segments := strings.Split(strings.Trim(req.URL.EscapedPath(), "/"), "/")
+	if segments[0] != "tables" || len(segments) > 4 {
+		writeJSONError(w, http.StatusNotFound, "unknown API", nil)
+		return
+	}
+	if len(segments) == 1 {
+		l.handleGetTables(w, req)
+		return
+	}
+	if len(segments) < 3 {
+		writeJSONError(w, http.StatusNotFound, "the table name must be in the form of {db}/{table}", nil)
+		return
+	}
+	db, err := url.PathUnescape(segments[1])
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid database name", err)
+		return
+	}
+	tbl, err := url.PathUnescape(segments[2])
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid table name", err)
+		return
+	}
+	tableName := common.UniqueTable(db, tbl)
+	if len(segments) == 3 {
+		l.handleGetTable(w, req, tableName)
+		return
+	}
+	l.handleTableAction(w, req, tableName, segments[3])
+}
+
+func (l *Lightning) currentTableControls() *restore.TableControls {
+	l.cancelLock.Lock()
+	defer l.cancelLock.Unlock()
+	return l.curTables
+}
+
+func (l *Lightning) tableProgress(p *web.TableProgress) *tableProgress {
+	res := &tableProgress{TableProgress: p}
+	if controls := l.currentTableControls(); controls != nil {
+		if state, _, ok := controls.State(p.Name); ok {
+			res.State = state
+			return res
+		}
+	}
+	// the table isn't scheduled, the state is derived from the progress.
+	switch {
+	case p.Completed() && p.Error != "":
+		res.State = restore.TableStateFailed
+	case p.Completed():
+		res.State = restore.TableStateCompleted
+	case p.Running():
+		res.State = restore.TableStateRunning
+	default:
+		res.State = restore.TableStatePending
+	}
+	return res
+}
+
+func (l *Lightning) handleGetTables(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeJSONError(w, http.StatusMethodNotAllowed, "only GET is allowed", nil)
+		return
+	}
+	progress, err := web.TablesProgress()
+	if err != nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "unable to get the progress", err)
+		return
+	}
+	var response struct {
+		Tables []*tableProgress `json:"tables"`
+	}
+	response.Tables = make([]*tableProgress, 0, len(progress))
+	for _, p := range progress {
+		response.Tables = append(response.Tables, l.tableProgress(p))
+	}
+	data, err := json.Marshal(response)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "unable to serialize the progress", err)
+		return
+	}
+	writeBytesCompressed(w, req, data)
+}
+
+func (l *Lightning) handleGetTable(w http.ResponseWriter, req *http.Request, tableName string) {
+	if req.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeJSONError(w, http.StatusMethodNotAllowed, "only GET is allowed", nil)
+		return
+	}
+	progress, err := web.GetTableProgress(tableName)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			writeJSONError(w, http.StatusNotFound, "table not found", err)
+		} else {
+			writeJSONError(w, http.StatusServiceUnavailable, "unable to get the progress", err)
+		}
+		return
+	}
+	data, err := json.Marshal(l.tableProgress(progress))
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "unable to serialize the progress", err)
+		return
+	}
+	writeBytesCompressed(w, req, data)
+}
+
+func (l *Lightning) handleTableAction(w http.ResponseWriter, req *http.Request, tableName string, action string) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSONError(w, http.StatusMethodNotAllowed, "only POST is allowed", nil)
+		return
+	}
+	controls := l.currentTableControls()
+	if controls == nil {
+		writeJSONError(w, http.StatusNotFound, "no task is running", nil)
+		return
+	}
+	if _, _, ok := controls.State(tableName); !ok {
+		writeJSONError(w, http.StatusNotFound, "table is not scheduled in the current task", nil)
+		return
+	}
+
+	var err error
+	switch action {
+	case "pause":
+		err = controls.Pause(tableName)
+	case "resume":
+		err = controls.Resume(tableName)
+	case "cancel":
+		err = controls.Cancel(tableName)
+	case "retry":
+		err = controls.Retry(tableName)
+	default:
+		writeJSONError(w, http.StatusNotFound, "unknown table action", nil)
+		return
+	}
+	if err != nil {
+		writeJSONError(w, http.StatusConflict, "unable to "+action+" the table", err)
+		return
+	}
+	state, _, _ := controls.State(tableName)
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(struct {
+		State restore.TableState `json:"state"`
+	}{State: state})
+}
@@ -52,6 +52,7 @@ import (
 const (
 	maxDupCollectAttemptTimes       = 5
 	defaultRecordConflictErrorBatch = 1024
+	defaultWriteBackBatchSize       = 256
 )
 
 type pendingIndexHandles struct {
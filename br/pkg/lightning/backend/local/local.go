@@ -49,6 +49,7 @@ import (
 	"github.com/pingcap/tidb/br/pkg/logutil"
 	"github.com/pingcap/tidb/br/pkg/membuf"
 	"github.com/pingcap/tidb/br/pkg/pdutil"
+	"github.com/pingcap/tidb/br/pkg/redact"
 	split "github.com/pingcap/tidb/br/pkg/restore"
 	"github.com/pingcap/tidb/br/pkg/utils"
 	"github.com/pingcap/tidb/br/pkg/version"
@@ -56,6 +57,7 @@ import (
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/codec"
 	tikverror "github.com/tikv/client-go/v2/error"
 	"github.com/tikv/client-go/v2/oracle"
@@ -1430,7 +1432,10 @@ func (local *local) ResolveDuplicateRows(ctx context.Context, tbl table.Table, t
 	case config.DupeResAlgRecord, config.DupeResAlgNone:
 		logger.Warn("[resolve-dupe] skipping resolution due to selected algorithm. this table will become inconsistent!", zap.Stringer("algorithm", algorithm))
 		return nil
-	case config.DupeResAlgRemove:
+	case config.DupeResAlgErr:
+		// the import of the table fails if there are duplicates.
+		return nil
+	case config.DupeResAlgRemove, config.DupeResAlgReplace, config.DupeResAlgIgnore:
 		break
 	default:
 		panic(fmt.Sprintf("[resolve-dupe] unknown resolution algorithm %v", algorithm))
@@ -1466,9 +1471,114 @@ func (local *local) ResolveDuplicateRows(ctx context.Context, tbl table.Table, t
 			}
 		},
 	)
+	if err != nil || algorithm == config.DupeResAlgRemove {
+		return errors.Trace(err)
+	}
+	return errors.Trace(local.writeBackDuplicateRows(ctx, logger, tbl, tableName, algorithm))
+}
+
+// writeBackDuplicateRows writes the removed duplicate rows back through TiDB, so that one row of each
+// conflict is kept. With the 'replace' algorithm the last row of a conflict wins, and with the 'ignore'
+// algorithm the first one wins.
+func (local *local) writeBackDuplicateRows(
+	ctx context.Context,
+	logger *log.Task,
+	tbl table.Table,
+	tableName string,
+	algorithm config.DuplicateResolutionAlgorithm,
+) error {
+	db, err := local.g.GetDB()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	exec := common.SQLWithRetry{
+		DB:           db,
+		Logger:       logger.Logger,
+		HideQueryLog: redact.NeedRedact(),
+	}
+	// the rows are decoded in the time zone of the connections, so that the timestamps are written back unchanged.
+	var timeZone string
+	if err := exec.QueryRow(ctx, "get time zone", "SELECT @@time_zone", &timeZone); err != nil {
+		return errors.Trace(err)
+	}
+	decoder, err := kv.NewTableKVDecoder(tbl, tableName, &kv.SessionOptions{
+		SQLMode: mysql.ModeStrictAllTables,
+		SysVars: map[string]string{"time_zone": timeZone},
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	cols := tbl.Cols()
+	colNames := make([]string, 0, len(cols))
+	for _, col := range cols {
+		if !col.IsGenerated() {
+			colNames = append(colNames, common.EscapeIdentifier(col.Name.O))
+		}
+	}
+	insertStmt := "REPLACE INTO"
+	if algorithm == config.DupeResAlgIgnore {
+		insertStmt = "INSERT IGNORE INTO"
+	}
+	rowPlaceholder := "(" + strings.TrimSuffix(strings.Repeat("?,", len(colNames)), ",") + ")"
+
+	var count int
+	err = local.errorMgr.IterConflictRows(ctx, tableName, defaultWriteBackBatchSize,
+		func(ctx context.Context, handleRows [][2][]byte) error {
+			var sb strings.Builder
+			fmt.Fprintf(&sb, "%s %s (%s) VALUES ", insertStmt, tableName, strings.Join(colNames, ","))
+			args := make([]interface{}, 0, len(handleRows)*len(colNames))
+			for i, handleRow := range handleRows {
+				handle, err := decoder.DecodeHandleFromRowKey(handleRow[0])
+				if err != nil {
+					return errors.Trace(err)
+				}
+				row, _, err := decoder.DecodeRawRowData(handle, handleRow[1])
+				if err != nil {
+					return errors.Trace(err)
+				}
+				if i > 0 {
+					sb.WriteByte(',')
+				}
+				sb.WriteString(rowPlaceholder)
+				for j, col := range cols {
+					if col.IsGenerated() {
+						continue
+					}
+					arg, err := datumToSQLArg(row[j])
+					if err != nil {
+						return errors.Trace(err)
+					}
+					args = append(args, arg)
+				}
+			}
+			count += len(handleRows)
+			return exec.Exec(ctx, "write back duplicate rows", sb.String(), args...)
+		})
+	logger.Info("[resolve-dupe] write back duplicate rows", zap.Int("count", count), zap.Stringer("algorithm", algorithm))
 	return errors.Trace(err)
 }
 
+// datumToSQLArg converts the datum to an argument of the SQL statement. The value is parsed
+// by TiDB according to the type of the column.
+func datumToSQLArg(d types.Datum) (interface{}, error) {
+	switch d.Kind() {
+	case types.KindNull:
+		return nil, nil
+	case types.KindInt64:
+		return d.GetInt64(), nil
+	case types.KindUint64:
+		return d.GetUint64(), nil
+	case types.KindFloat32, types.KindFloat64:
+		return d.GetFloat64(), nil
+	case types.KindString, types.KindBytes, types.KindBinaryLiteral, types.KindMysqlBit:
+		return d.GetBytes(), nil
+	default:
+		str, err := d.ToString()
+		return str, errors.Trace(err)
+	}
+}
+
 func (local *local) deleteDuplicateRows(ctx context.Context, logger *log.Task, handleRows [][2][]byte, decoder *kv.TableKVDecoder) (err error) {
 	// Starts a Delete transaction.
 	txn, err := local.tikvCli.Begin()
@@ -46,6 +46,7 @@ import (
 	"github.com/pingcap/tidb/br/pkg/restore"
 	"github.com/pingcap/tidb/br/pkg/version"
 	tidbkv "github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/sessionctx/stmtctx"
 	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/types"
@@ -1188,3 +1189,25 @@ func TestMultiIngest(t *testing.T) {
 		}
 	}
 }
+
+func TestDatumToSQLArg(t *testing.T) {
+	dec := types.NewDecFromStringForTest("123.45")
+	cases := []struct {
+		datum    types.Datum
+		expected interface{}
+	}{
+		{types.NewDatum(nil), nil},
+		{types.NewIntDatum(-1), int64(-1)},
+		{types.NewUintDatum(1), uint64(1)},
+		{types.NewFloat64Datum(1.5), 1.5},
+		{types.NewStringDatum("a'b"), []byte("a'b")},
+		{types.NewBytesDatum([]byte{0, 1}), []byte{0, 1}},
+		{types.NewDecimalDatum(dec), "123.45"},
+		{types.NewTimeDatum(types.NewTime(types.FromDate(2022, 1, 2, 3, 4, 5, 0), mysql.TypeDatetime, 0)), "2022-01-02 03:04:05"},
+	}
+	for _, c := range cases {
+		arg, err := datumToSQLArg(c.datum)
+		require.NoError(t, err)
+		require.Equal(t, c.expected, arg)
+	}
+}
@@ -65,7 +65,7 @@ const (
 	CheckpointTableNameTask   = "task_v2"
 	CheckpointTableNameTable  = "table_v7"
 	CheckpointTableNameEngine = "engine_v5"
-	CheckpointTableNameChunk  = "chunk_v5"
+	CheckpointTableNameChunk  = "chunk_v6"
 
 	// Some frequently used table name or constants.
 	allTables       = "all"
@@ -123,6 +123,7 @@ const (
 			compression int NOT NULL,
 			sort_key varchar(256) NOT NULL,
 			file_size bigint NOT NULL,
+			real_size bigint NOT NULL DEFAULT 0,
 			columns text NULL,
 			should_include_row_id BOOL NOT NULL,
 			end_offset bigint NOT NULL,
@@ -151,7 +152,7 @@ const (
 		SELECT engine_id, status FROM %s.%s WHERE table_name = ? ORDER BY engine_id DESC;`
 	ReadChunkTemplate = `
 		SELECT
-			engine_id, path, offset, type, compression, sort_key, file_size, columns,
+			engine_id, path, offset, type, compression, sort_key, file_size, real_size, columns,
 			pos, end_offset, prev_rowid_max, rowid_max,
 			kvc_bytes, kvc_kvs, kvc_checksum, unix_timestamp(create_time)
 		FROM %s.%s WHERE table_name = ?
@@ -163,12 +164,12 @@ const (
 	ReplaceChunkTemplate = `
 		REPLACE INTO %s.%s (
 				table_name, engine_id,
-				path, offset, type, compression, sort_key, file_size, columns, should_include_row_id,
+				path, offset, type, compression, sort_key, file_size, real_size, columns, should_include_row_id,
 				pos, end_offset, prev_rowid_max, rowid_max,
 				kvc_bytes, kvc_kvs, kvc_checksum, create_time
 			) VALUES (
 				?, ?,
-				?, ?, ?, ?, ?, ?, ?, FALSE,
+				?, ?, ?, ?, ?, ?, ?, ?, FALSE,
 				?, ?, ?, ?,
 				0, 0, 0, from_unixtime(?)
 			);`
@@ -248,6 +249,22 @@ type ChunkCheckpoint struct {
 	Timestamp         int64
 }
 
+// TotalSize returns the size of the data in the chunk. A compressed file isn't split into
+// chunks, so the estimated size of the uncompressed file is used.
+func (ccp *ChunkCheckpoint) TotalSize() int64 {
+	// the offsets of the tables read from a database are the handles or row counts, not bytes.
+	if ccp.FileMeta.Type == mydump.SourceTypeDB {
+		return ccp.FileMeta.FileSize
+	}
+	if ccp.FileMeta.Compression == mydump.CompressionNone {
+		return ccp.Chunk.EndOffset - ccp.Key.Offset
+	}
+	if ccp.FileMeta.RealSize > 0 {
+		return ccp.FileMeta.RealSize
+	}
+	return ccp.FileMeta.FileSize
+}
+
 func (ccp *ChunkCheckpoint) DeepCopy() *ChunkCheckpoint {
 	colPerm := make([]int, 0, len(ccp.ColumnPermutation))
 	colPerm = append(colPerm, ccp.ColumnPermutation...)
@@ -780,7 +797,7 @@ func (cpdb *MySQLCheckpointsDB) Get(ctx context.Context, tableName string) (*Tab
 			)
 			if err := chunkRows.Scan(
 				&engineID, &value.Key.Path, &value.Key.Offset, &value.FileMeta.Type, &value.FileMeta.Compression,
-				&value.FileMeta.SortKey, &value.FileMeta.FileSize, &colPerm, &value.Chunk.Offset, &value.Chunk.EndOffset,
+				&value.FileMeta.SortKey, &value.FileMeta.FileSize, &value.FileMeta.RealSize, &colPerm, &value.Chunk.Offset, &value.Chunk.EndOffset,
 				&value.Chunk.PrevRowIDMax, &value.Chunk.RowIDMax, &kvcBytes, &kvcKVs, &kvcChecksum,
 				&value.Timestamp,
 			); err != nil {
@@ -851,7 +868,7 @@ func (cpdb *MySQLCheckpointsDB) InsertEngineCheckpoints(ctx context.Context, tab
 				_, err = chunkStmt.ExecContext(
 					c, tableName, engineID,
 					value.Key.Path, value.Key.Offset, value.FileMeta.Type, value.FileMeta.Compression,
-					value.FileMeta.SortKey, value.FileMeta.FileSize, columnPerm, value.Chunk.Offset, value.Chunk.EndOffset,
+					value.FileMeta.SortKey, value.FileMeta.FileSize, value.FileMeta.RealSize, columnPerm, value.Chunk.Offset, value.Chunk.EndOffset,
 					value.Chunk.PrevRowIDMax, value.Chunk.RowIDMax, value.Timestamp,
 				)
 				if err != nil {
@@ -1190,6 +1207,7 @@ func (cpdb *FileCheckpointsDB) Get(_ context.Context, tableName string) (*TableC
 					Compression: mydump.Compression(chunkModel.Compression),
 					SortKey:     chunkModel.SortKey,
 					FileSize:    chunkModel.FileSize,
+					RealSize:    chunkModel.RealSize,
 				},
 				ColumnPermutation: colPerm,
 				Chunk: mydump.Chunk{
@@ -1237,6 +1255,7 @@ func (cpdb *FileCheckpointsDB) InsertEngineCheckpoints(_ context.Context, tableN
 			chunk.Compression = int32(value.FileMeta.Compression)
 			chunk.SortKey = value.FileMeta.SortKey
 			chunk.FileSize = value.FileMeta.FileSize
+			chunk.RealSize = value.FileMeta.RealSize
 			chunk.Pos = value.Chunk.Offset
 			chunk.EndOffset = value.Chunk.EndOffset
 			chunk.PrevRowidMax = value.Chunk.PrevRowIDMax
@@ -1600,6 +1619,7 @@ func (cpdb *MySQLCheckpointsDB) DumpChunks(ctx context.Context, writer io.Writer
 			compression,
 			sort_key,
 			file_size,
+			real_size,
 			columns,
 			pos,
 			end_offset,
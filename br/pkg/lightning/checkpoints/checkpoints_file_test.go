@@ -65,6 +65,7 @@ func newFileCheckpointsDB(t *testing.T) (*checkpoints.FileCheckpointsDB, func())
 					Path:     "/tmp/path/1.sql",
 					Type:     mydump.SourceTypeSQL,
 					FileSize: 12345,
+					RealSize: 12345,
 				},
 				Chunk: mydump.Chunk{
 					Offset:       12,
@@ -170,6 +171,7 @@ func TestGet(t *testing.T) {
 						Path:     "/tmp/path/1.sql",
 						Type:     mydump.SourceTypeSQL,
 						FileSize: 12345,
+						RealSize: 12345,
 					},
 					ColumnPermutation: []int{},
 					Chunk: mydump.Chunk{
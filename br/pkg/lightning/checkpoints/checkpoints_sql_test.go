@@ -122,7 +122,7 @@ func TestNormalOperations(t *testing.T) {
 		ExpectPrepare("REPLACE INTO `mock-schema`\\.chunk_v\\d+ .+")
 	insertChunkStmt.
 		ExpectExec().
-		WithArgs("`db1`.`t2`", 0, "/tmp/path/1.sql", 0, mydump.SourceTypeSQL, 0, "", 123, []byte("null"), 12, 102400, 1, 5000, 1234567890).
+		WithArgs("`db1`.`t2`", 0, "/tmp/path/1.sql", 0, mydump.SourceTypeSQL, 0, "", 123, 123, []byte("null"), 12, 102400, 1, 5000, 1234567890).
 		WillReturnResult(sqlmock.NewResult(10, 1))
 	s.mock.ExpectCommit()
 
@@ -139,6 +139,7 @@ func TestNormalOperations(t *testing.T) {
 					Path:     "/tmp/path/1.sql",
 					Type:     mydump.SourceTypeSQL,
 					FileSize: 123,
+					RealSize: 123,
 				},
 				Chunk: mydump.Chunk{
 					Offset:       12,
@@ -241,12 +242,12 @@ func TestNormalOperations(t *testing.T) {
 		WithArgs("`db1`.`t2`").
 		WillReturnRows(
 			sqlmock.NewRows([]string{
-				"engine_id", "path", "offset", "type", "compression", "sort_key", "file_size", "columns",
+				"engine_id", "path", "offset", "type", "compression", "sort_key", "file_size", "real_size", "columns",
 				"pos", "end_offset", "prev_rowid_max", "rowid_max",
 				"kvc_bytes", "kvc_kvs", "kvc_checksum", "unix_timestamp(create_time)",
 			}).
 				AddRow(
-					0, "/tmp/path/1.sql", 0, mydump.SourceTypeSQL, 0, "", 123, "[]",
+					0, "/tmp/path/1.sql", 0, mydump.SourceTypeSQL, 0, "", 123, 123, "[]",
 					55904, 102400, 681, 5000,
 					4491, 586, 486070148917, 1234567894,
 				),
@@ -279,6 +280,7 @@ func TestNormalOperations(t *testing.T) {
 						Path:     "/tmp/path/1.sql",
 						Type:     mydump.SourceTypeSQL,
 						FileSize: 123,
+						RealSize: 123,
 					},
 					ColumnPermutation: []int{},
 					Chunk: mydump.Chunk{
@@ -318,7 +320,7 @@ func TestRemoveAllCheckpoints_SQL(t *testing.T) {
 		WithArgs("`db1`.`t2`").
 		WillReturnRows(
 			sqlmock.NewRows([]string{
-				"engine_id", "path", "offset", "type", "compression", "sort_key", "file_size", "columns",
+				"engine_id", "path", "offset", "type", "compression", "sort_key", "file_size", "real_size", "columns",
 				"pos", "end_offset", "prev_rowid_max", "rowid_max",
 				"kvc_bytes", "kvc_kvs", "kvc_checksum", "unix_timestamp(create_time)",
 			}))
@@ -474,12 +476,12 @@ func TestDump(t *testing.T) {
 		ExpectQuery("SELECT (?s:.+) FROM `mock-schema`\\.chunk_v\\d+").
 		WillReturnRows(
 			sqlmock.NewRows([]string{
-				"table_name", "path", "offset", "type", "compression", "sort_key", "file_size", "columns",
+				"table_name", "path", "offset", "type", "compression", "sort_key", "file_size", "real_size", "columns",
 				"pos", "end_offset", "prev_rowid_max", "rowid_max",
 				"kvc_bytes", "kvc_kvs", "kvc_checksum",
 				"create_time", "update_time",
 			}).AddRow(
-				"`db1`.`t2`", "/tmp/path/1.sql", 0, mydump.SourceTypeSQL, mydump.CompressionNone, "", 456, "[]",
+				"`db1`.`t2`", "/tmp/path/1.sql", 0, mydump.SourceTypeSQL, mydump.CompressionNone, "", 456, 456, "[]",
 				55904, 102400, 681, 5000,
 				4491, 586, 486070148917,
 				tm, tm,
@@ -490,8 +492,8 @@ func TestDump(t *testing.T) {
 	err := s.cpdb.DumpChunks(ctx, &csvBuilder)
 	require.NoError(t, err)
 	require.Equal(t,
-		"table_name,path,offset,type,compression,sort_key,file_size,columns,pos,end_offset,prev_rowid_max,rowid_max,kvc_bytes,kvc_kvs,kvc_checksum,create_time,update_time\n"+
-			"`db1`.`t2`,/tmp/path/1.sql,0,3,0,,456,[],55904,102400,681,5000,4491,586,486070148917,2019-04-18 02:45:55 +0000 UTC,2019-04-18 02:45:55 +0000 UTC\n",
+		"table_name,path,offset,type,compression,sort_key,file_size,real_size,columns,pos,end_offset,prev_rowid_max,rowid_max,kvc_bytes,kvc_kvs,kvc_checksum,create_time,update_time\n"+
+			"`db1`.`t2`,/tmp/path/1.sql,0,3,0,,456,456,[],55904,102400,681,5000,4491,586,486070148917,2019-04-18 02:45:55 +0000 UTC,2019-04-18 02:45:55 +0000 UTC\n",
 		csvBuilder.String(),
 	)
 
@@ -208,6 +208,7 @@ type ChunkCheckpointModel struct {
 	Compression       int32   `protobuf:"varint,15,opt,name=compression,proto3" json:"compression,omitempty"`
 	SortKey           string  `protobuf:"bytes,16,opt,name=sort_key,json=sortKey,proto3" json:"sort_key,omitempty"`
 	FileSize          int64   `protobuf:"varint,17,opt,name=file_size,json=fileSize,proto3" json:"file_size,omitempty"`
+	RealSize          int64   `protobuf:"varint,18,opt,name=real_size,json=realSize,proto3" json:"real_size,omitempty"`
 }
 
 func (m *ChunkCheckpointModel) Reset()         { *m = ChunkCheckpointModel{} }
@@ -259,62 +260,62 @@ func init() {
 }
 
 var fileDescriptor_c57c7b77a714394c = []byte{
-	// 870 bytes of a gzipped FileDescriptorProto
+	// 878 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x55, 0xcd, 0x6e, 0xdb, 0x46,
-	0x10, 0x36, 0x45, 0x8b, 0x92, 0x96, 0x92, 0x23, 0x6f, 0xed, 0x64, 0xeb, 0xb6, 0x2a, 0xab, 0xf4,
-	0x20, 0x20, 0x8d, 0x04, 0xa4, 0x97, 0x22, 0x68, 0x0b, 0xd4, 0x76, 0x80, 0x06, 0x46, 0x50, 0x63,
-	0x9b, 0xf6, 0xd0, 0x0b, 0xc1, 0x9f, 0xb5, 0x44, 0xac, 0xc8, 0x25, 0xb8, 0x4b, 0x36, 0xca, 0x53,
-	0xf4, 0x31, 0xfa, 0x12, 0xbd, 0x07, 0x3d, 0xe5, 0xd8, 0x63, 0x6a, 0xdf, 0xfb, 0x0c, 0xc5, 0xce,
-	0x52, 0x16, 0x15, 0x08, 0x46, 0x6e, 0x33, 0xdf, 0x7c, 0x3b, 0x3b, 0xfb, 0xe9, 0x1b, 0x11, 0x7d,
-	0x9f, 0xf3, 0xf9, 0x6c, 0x99, 0xcc, 0x17, 0x2a, 0x4b, 0xb2, 0xf9, 0x2c, 0x5a, 0xb0, 0x88, 0xe7,
-	0x22, 0xc9, 0x94, 0x6c, 0xc6, 0x79, 0x38, 0xbb, 0x4a, 0x96, 0xcc, 0x6f, 0x40, 0xd3, 0xbc, 0x10,
-	0x4a, 0x9c, 0x3c, 0x9e, 0x27, 0x6a, 0x51, 0x86, 0xd3, 0x48, 0xa4, 0xb3, 0xb9, 0x98, 0x8b, 0x19,
-	0xc0, 0x61, 0x79, 0x05, 0x19, 0x24, 0x10, 0x19, 0xfa, 0xf8, 0x3f, 0x0b, 0x0d, 0xcf, 0x36, 0x4d,
-	0x5e, 0x88, 0x98, 0x2d, 0xf1, 0x39, 0x72, 0x1b, 0x8d, 0x89, 0xe5, 0xd9, 0x13, 0xf7, 0xc9, 0x78,
-	0xfa, 0x3e, 0xaf, 0x09, 0x3c, 0xcb, 0x54, 0xb1, 0xa2, 0xcd, 0x63, 0xf8, 0x3b, 0x74, 0x4f, 0x05,
-	0x92, 0x37, 0x66, 0x24, 0x2d, 0xcf, 0x9a, 0xb8, 0x4f, 0x8e, 0xa6, 0x2f, 0x03, 0xc9, 0x37, 0x87,
-	0xa1, 0x19, 0x3d, 0x50, 0x5b, 0xe0, 0xc9, 0x2f, 0x5b, 0x83, 0x41, 0x7f, 0x3c, 0x44, 0x36, 0x67,
-	0x2b, 0x62, 0x79, 0xd6, 0xa4, 0x47, 0x75, 0x88, 0x1f, 0xa1, 0x76, 0x15, 0x2c, 0x4b, 0x56, 0xb7,
-	0x3e, 0x9e, 0xbe, 0x0c, 0xc2, 0x25, 0x7b, 0xbf, 0xb7, 0xe1, 0x3c, 0x6d, 0x7d, 0x63, 0x8d, 0xff,
-	0x6c, 0xa1, 0x8f, 0x76, 0x5c, 0x8f, 0x1f, 0xa0, 0x0e, 0x4c, 0x9b, 0xc4, 0xd0, 0xde, 0xa6, 0x8e,
-	0x4e, 0x9f, 0xc7, 0xf8, 0x33, 0x84, 0xa4, 0x28, 0x8b, 0x88, 0xf9, 0x71, 0x52, 0xc0, 0x35, 0x3d,
-	0xda, 0x33, 0xc8, 0x79, 0x52, 0x60, 0x82, 0x3a, 0x61, 0x10, 0x71, 0x96, 0xc5, 0xc4, 0x86, 0xda,
-	0x3a, 0xc5, 0x0f, 0xd1, 0x20, 0x49, 0x73, 0x51, 0x28, 0x56, 0xf8, 0x41, 0x1c, 0x17, 0x64, 0x1f,
-	0xea, 0xfd, 0x35, 0xf8, 0x43, 0x1c, 0x17, 0xf8, 0x13, 0xd4, 0x53, 0x49, 0x1c, 0xfa, 0x0b, 0x21,
-	0x15, 0x69, 0x03, 0xa1, 0xab, 0x81, 0x1f, 0x85, 0x54, 0xb7, 0x45, 0xcd, 0x27, 0x8e, 0x67, 0x4d,
-	0xda, 0xa6, 0x78, 0x29, 0x0a, 0xa5, 0x07, 0xce, 0x63, 0xd3, 0xb8, 0x03, 0xe7, 0x9c, 0x3c, 0x86,
-	0x96, 0x63, 0x34, 0x90, 0xfa, 0x82, 0xd8, 0xe7, 0x15, 0xcc, 0xdc, 0x85, 0xb2, 0x6b, 0xc0, 0x8b,
-	0x4a, 0x4f, 0xfd, 0x10, 0x0d, 0x6e, 0x3d, 0xe6, 0x57, 0xac, 0x20, 0x3d, 0x33, 0xdb, 0x2d, 0xf8,
-	0x2b, 0x2b, 0xc6, 0xef, 0x5a, 0xe8, 0x68, 0x97, 0x9c, 0x18, 0xa3, 0xfd, 0x45, 0x20, 0x17, 0x20,
-	0x54, 0x9f, 0x42, 0x8c, 0xef, 0x23, 0x47, 0xaa, 0x40, 0x95, 0x12, 0x64, 0x18, 0xd0, 0x3a, 0xd3,
-	0xf2, 0x05, 0xcb, 0xa5, 0x88, 0xfc, 0x30, 0x90, 0x0c, 0x24, 0xb0, 0x69, 0x0f, 0x90, 0xd3, 0x40,
-	0x32, 0xfc, 0x2d, 0xea, 0xb0, 0x6c, 0x9e, 0x64, 0x4c, 0x92, 0x6e, 0x6d, 0xb3, 0x5d, 0x57, 0x4e,
-	0x9f, 0x19, 0x92, 0xb1, 0xd9, 0xfa, 0x88, 0x16, 0x5f, 0x69, 0xf6, 0xf3, 0x73, 0x78, 0x80, 0x4d,
-	0xd7, 0x29, 0xfe, 0x18, 0x75, 0x79, 0xe5, 0x87, 0x2b, 0xc5, 0x24, 0x41, 0x9e, 0x35, 0xd9, 0xa7,
-	0x1d, 0x5e, 0x9d, 0xea, 0x14, 0x1f, 0x23, 0x87, 0x57, 0x3e, 0xaf, 0x24, 0x71, 0xa1, 0xd0, 0xe6,
-	0xd5, 0x45, 0x25, 0xf1, 0xe7, 0xc8, 0xe5, 0x95, 0x31, 0xab, 0x2c, 0x53, 0xd2, 0xf7, 0xac, 0x89,
-	0x43, 0x11, 0xaf, 0xce, 0x6a, 0xe4, 0x84, 0xa2, 0x7e, 0x73, 0x8a, 0xa6, 0x19, 0x0f, 0x8d, 0x19,
-	0xbf, 0xda, 0x36, 0xe3, 0xfd, 0x7a, 0xea, 0x3b, 0xdc, 0xf8, 0x97, 0x85, 0x8e, 0x77, 0x92, 0x1a,
-	0x7a, 0x5a, 0x5b, 0x7a, 0x3e, 0x45, 0x4e, 0xb4, 0x28, 0x33, 0x2e, 0x49, 0xab, 0xd6, 0x6b, 0xe7,
-	0xf9, 0xe9, 0x19, 0x90, 0x8c, 0x5e, 0xf5, 0x89, 0x93, 0x4b, 0xe4, 0x36, 0xe0, 0x0f, 0xd9, 0x26,
-	0xa0, 0xdf, 0x31, 0xff, 0xdf, 0x36, 0x3a, 0xda, 0xc5, 0xd1, 0x16, 0xc9, 0x03, 0xb5, 0xa8, 0x9b,
-	0x43, 0xac, 0x9f, 0x24, 0xae, 0xae, 0x24, 0x33, 0xff, 0x03, 0x36, 0xad, 0x33, 0xfc, 0x18, 0xe1,
-	0x48, 0x2c, 0xcb, 0x34, 0xf3, 0x73, 0x56, 0xa4, 0xa5, 0x0a, 0x54, 0x22, 0x32, 0xd2, 0xf7, 0xec,
-	0x49, 0x9b, 0x1e, 0x9a, 0xca, 0xe5, 0xa6, 0xa0, 0x1d, 0xc5, 0xb2, 0xd8, 0xaf, 0x5b, 0xb5, 0x8d,
-	0xa3, 0x58, 0x16, 0xff, 0x64, 0xba, 0x0d, 0x91, 0x9d, 0x0b, 0x09, 0xeb, 0x62, 0x53, 0x1d, 0xe2,
-	0x2f, 0xd1, 0x41, 0x5e, 0xb0, 0xca, 0x2f, 0xc4, 0xef, 0x49, 0xec, 0xa7, 0xc1, 0x2b, 0x58, 0x18,
-	0x9b, 0xf6, 0x35, 0x4a, 0x35, 0xf8, 0x22, 0x78, 0xa5, 0x97, 0x6d, 0x43, 0xe8, 0x02, 0xa1, 0x5b,
-	0x34, 0x8a, 0xbc, 0x8a, 0x6a, 0x3f, 0xf5, 0xc0, 0x36, 0x5d, 0x5e, 0x45, 0xc6, 0x50, 0x0f, 0x50,
-	0x47, 0x17, 0xb5, 0xa3, 0x8c, 0xd5, 0x1c, 0x5e, 0x45, 0xda, 0x52, 0x5f, 0xa0, 0xbe, 0x2e, 0xdc,
-	0x7a, 0xca, 0x05, 0x4f, 0xb9, 0xbc, 0x8a, 0xd6, 0xa6, 0xc2, 0x9f, 0xea, 0x15, 0x4f, 0x99, 0x54,
-	0x41, 0x9a, 0x93, 0x81, 0x67, 0x4d, 0x86, 0x74, 0x03, 0x68, 0x15, 0xd5, 0x2a, 0x67, 0xe4, 0x00,
-	0x76, 0x1f, 0x62, 0xec, 0x21, 0x37, 0x12, 0x69, 0x5e, 0x30, 0x29, 0xb5, 0x4c, 0xf7, 0xa0, 0xd4,
-	0x84, 0xb4, 0xf7, 0xf5, 0xae, 0xfb, 0xfa, 0xc7, 0x1d, 0x9a, 0xff, 0x24, 0x9d, 0x5f, 0xb0, 0x95,
-	0x7e, 0x07, 0x7c, 0x37, 0x64, 0xf2, 0x9a, 0x91, 0x43, 0xf3, 0x48, 0x0d, 0xfc, 0x9c, 0xbc, 0x66,
-	0xa7, 0x8f, 0xde, 0xfc, 0x3b, 0xda, 0x7b, 0x73, 0x3d, 0xb2, 0xde, 0x5e, 0x8f, 0xac, 0x77, 0xd7,
-	0x23, 0xeb, 0x8f, 0x9b, 0xd1, 0xde, 0xdb, 0x9b, 0xd1, 0xde, 0x3f, 0x37, 0xa3, 0xbd, 0xdf, 0x06,
-	0x5b, 0x9f, 0x9f, 0xd0, 0x81, 0xef, 0xc7, 0xd7, 0xff, 0x07, 0x00, 0x00, 0xff, 0xff, 0x8b, 0xff,
-	0xf2, 0x75, 0xb0, 0x06, 0x00, 0x00,
+	0x10, 0x36, 0x4d, 0xeb, 0x6f, 0x28, 0x39, 0xf2, 0xd6, 0x4e, 0x58, 0xb7, 0x55, 0x55, 0xa5, 0x07,
+	0x01, 0x69, 0x24, 0x20, 0xbd, 0x14, 0x41, 0x5b, 0xa0, 0xb6, 0x03, 0x34, 0x30, 0x82, 0x1a, 0x6c,
+	0xda, 0x43, 0x2f, 0xc4, 0x8a, 0x5c, 0x4b, 0xc4, 0x52, 0x5c, 0x82, 0xbb, 0xdc, 0x46, 0x79, 0x8a,
+	0x3e, 0x46, 0x5f, 0xa2, 0xf7, 0x1c, 0x73, 0xec, 0x31, 0xb5, 0x0f, 0xbd, 0xf5, 0x19, 0x8a, 0x9d,
+	0xa5, 0x2c, 0xda, 0x10, 0x82, 0xdc, 0x66, 0xbe, 0x6f, 0x76, 0x76, 0xf6, 0xd3, 0x37, 0x22, 0x7c,
+	0x9f, 0xf3, 0xf9, 0x34, 0x4d, 0xe6, 0x0b, 0x95, 0x25, 0xd9, 0x7c, 0x1a, 0x2d, 0x58, 0xc4, 0x73,
+	0x91, 0x64, 0x4a, 0xd6, 0xe3, 0x7c, 0x36, 0xbd, 0x4c, 0x52, 0x16, 0xd6, 0xa0, 0x49, 0x5e, 0x08,
+	0x25, 0x8e, 0x1f, 0xcf, 0x13, 0xb5, 0x28, 0x67, 0x93, 0x48, 0x2c, 0xa7, 0x73, 0x31, 0x17, 0x53,
+	0x84, 0x67, 0xe5, 0x25, 0x66, 0x98, 0x60, 0x64, 0xcb, 0x47, 0xff, 0x39, 0xd0, 0x3f, 0xdd, 0x34,
+	0x79, 0x21, 0x62, 0x96, 0x92, 0x33, 0xf0, 0x6a, 0x8d, 0x7d, 0x67, 0xe8, 0x8e, 0xbd, 0x27, 0xa3,
+	0xc9, 0xdd, 0xba, 0x3a, 0xf0, 0x2c, 0x53, 0xc5, 0x2a, 0xa8, 0x1f, 0x23, 0xdf, 0xc1, 0x3d, 0x45,
+	0x25, 0xaf, 0xcd, 0xe8, 0xef, 0x0e, 0x9d, 0xb1, 0xf7, 0xe4, 0x70, 0xf2, 0x92, 0x4a, 0xbe, 0x39,
+	0x8c, 0xcd, 0x82, 0x7d, 0x75, 0x0b, 0x3c, 0xfe, 0x05, 0xfa, 0x77, 0xfb, 0x93, 0x3e, 0xb8, 0x9c,
+	0xad, 0x7c, 0x67, 0xe8, 0x8c, 0x3b, 0x81, 0x09, 0xc9, 0x23, 0x68, 0x68, 0x9a, 0x96, 0xac, 0x6a,
+	0x7d, 0x34, 0x79, 0x49, 0x67, 0x29, 0xbb, 0xdb, 0xdb, 0xd6, 0x3c, 0xdd, 0xfd, 0xc6, 0x19, 0xfd,
+	0xb9, 0x0b, 0x1f, 0x6d, 0xb9, 0x9e, 0x3c, 0x80, 0x16, 0x4e, 0x9b, 0xc4, 0xd8, 0xde, 0x0d, 0x9a,
+	0x26, 0x7d, 0x1e, 0x93, 0xcf, 0x00, 0xa4, 0x28, 0x8b, 0x88, 0x85, 0x71, 0x52, 0xe0, 0x35, 0x9d,
+	0xa0, 0x63, 0x91, 0xb3, 0xa4, 0x20, 0x3e, 0xb4, 0x66, 0x34, 0xe2, 0x2c, 0x8b, 0x7d, 0x17, 0xb9,
+	0x75, 0x4a, 0x1e, 0x42, 0x2f, 0x59, 0xe6, 0xa2, 0x50, 0xac, 0x08, 0x69, 0x1c, 0x17, 0xfe, 0x1e,
+	0xf2, 0xdd, 0x35, 0xf8, 0x43, 0x1c, 0x17, 0xe4, 0x13, 0xe8, 0xa8, 0x24, 0x9e, 0x85, 0x0b, 0x21,
+	0x95, 0xdf, 0xc0, 0x82, 0xb6, 0x01, 0x7e, 0x14, 0x52, 0xdd, 0x90, 0xa6, 0xde, 0x6f, 0x0e, 0x9d,
+	0x71, 0xc3, 0x92, 0x17, 0xa2, 0x50, 0x66, 0xe0, 0x3c, 0xb6, 0x8d, 0x5b, 0x78, 0xae, 0x99, 0xc7,
+	0xd8, 0x72, 0x04, 0x3d, 0x69, 0x2e, 0x88, 0x43, 0xae, 0x71, 0xe6, 0x36, 0xd2, 0x9e, 0x05, 0xcf,
+	0xb5, 0x99, 0xfa, 0x21, 0xf4, 0x6e, 0x3c, 0x16, 0x6a, 0x56, 0xf8, 0x1d, 0x3b, 0xdb, 0x0d, 0xf8,
+	0x2b, 0x2b, 0x46, 0xef, 0x76, 0xe1, 0x70, 0x9b, 0x9c, 0x84, 0xc0, 0xde, 0x82, 0xca, 0x05, 0x0a,
+	0xd5, 0x0d, 0x30, 0x26, 0xf7, 0xa1, 0x29, 0x15, 0x55, 0xa5, 0x44, 0x19, 0x7a, 0x41, 0x95, 0x19,
+	0xf9, 0x68, 0x9a, 0x8a, 0x28, 0x9c, 0x51, 0xc9, 0x50, 0x02, 0x37, 0xe8, 0x20, 0x72, 0x42, 0x25,
+	0x23, 0xdf, 0x42, 0x8b, 0x65, 0xf3, 0x24, 0x63, 0xd2, 0x6f, 0x57, 0x36, 0xdb, 0x76, 0xe5, 0xe4,
+	0x99, 0x2d, 0xb2, 0x36, 0x5b, 0x1f, 0x31, 0xe2, 0x2b, 0x53, 0xfd, 0xfc, 0x0c, 0x1f, 0xe0, 0x06,
+	0xeb, 0x94, 0x7c, 0x0c, 0x6d, 0xae, 0xc3, 0xd9, 0x4a, 0x31, 0xe9, 0xc3, 0xd0, 0x19, 0xef, 0x05,
+	0x2d, 0xae, 0x4f, 0x4c, 0x4a, 0x8e, 0xa0, 0xc9, 0x75, 0xc8, 0xb5, 0xf4, 0x3d, 0x24, 0x1a, 0x5c,
+	0x9f, 0x6b, 0x49, 0x3e, 0x07, 0x8f, 0x6b, 0x6b, 0x56, 0x59, 0x2e, 0xfd, 0xee, 0xd0, 0x19, 0x37,
+	0x03, 0xe0, 0xfa, 0xb4, 0x42, 0x8e, 0x03, 0xe8, 0xd6, 0xa7, 0xa8, 0x9b, 0xf1, 0xc0, 0x9a, 0xf1,
+	0xab, 0xdb, 0x66, 0xbc, 0x5f, 0x4d, 0xfd, 0x1e, 0x37, 0xfe, 0xe5, 0xc0, 0xd1, 0xd6, 0xa2, 0x9a,
+	0x9e, 0xce, 0x2d, 0x3d, 0x9f, 0x42, 0x33, 0x5a, 0x94, 0x19, 0x97, 0xfe, 0x6e, 0xa5, 0xd7, 0xd6,
+	0xf3, 0x93, 0x53, 0x2c, 0xb2, 0x7a, 0x55, 0x27, 0x8e, 0x2f, 0xc0, 0xab, 0xc1, 0x1f, 0xb2, 0x4d,
+	0x58, 0xfe, 0x9e, 0xf9, 0xff, 0x75, 0xe1, 0x70, 0x5b, 0x8d, 0xb1, 0x48, 0x4e, 0xd5, 0xa2, 0x6a,
+	0x8e, 0xb1, 0x79, 0x92, 0xb8, 0xbc, 0x94, 0xcc, 0xfe, 0x0f, 0xb8, 0x41, 0x95, 0x91, 0xc7, 0x40,
+	0x22, 0x91, 0x96, 0xcb, 0x2c, 0xcc, 0x59, 0xb1, 0x2c, 0x15, 0x55, 0x89, 0xc8, 0xfc, 0xee, 0xd0,
+	0x1d, 0x37, 0x82, 0x03, 0xcb, 0x5c, 0x6c, 0x08, 0xe3, 0x28, 0x96, 0xc5, 0x61, 0xd5, 0xaa, 0x61,
+	0x1d, 0xc5, 0xb2, 0xf8, 0x27, 0xdb, 0xad, 0x0f, 0x6e, 0x2e, 0x24, 0xae, 0x8b, 0x1b, 0x98, 0x90,
+	0x7c, 0x09, 0xfb, 0x79, 0xc1, 0x74, 0x58, 0x88, 0xdf, 0x93, 0x38, 0x5c, 0xd2, 0x57, 0xb8, 0x30,
+	0x6e, 0xd0, 0x35, 0x68, 0x60, 0xc0, 0x17, 0xf4, 0x95, 0x59, 0xb6, 0x4d, 0x41, 0x1b, 0x0b, 0xda,
+	0x45, 0x8d, 0xe4, 0x3a, 0xaa, 0xfc, 0xd4, 0x41, 0xdb, 0xb4, 0xb9, 0x8e, 0xac, 0xa1, 0x1e, 0x40,
+	0xcb, 0x90, 0x5c, 0xaf, 0xad, 0xd6, 0xe4, 0x3a, 0x32, 0x96, 0xfa, 0x02, 0xba, 0x86, 0xb8, 0xf1,
+	0x94, 0x87, 0x9e, 0xf2, 0xb8, 0x8e, 0xd6, 0xa6, 0x22, 0x9f, 0x9a, 0x15, 0x5f, 0x32, 0xa9, 0xe8,
+	0x32, 0xf7, 0x7b, 0x43, 0x67, 0xdc, 0x0f, 0x36, 0x80, 0x51, 0x51, 0xad, 0x72, 0xe6, 0xef, 0xe3,
+	0xee, 0x63, 0x4c, 0x86, 0xe0, 0x45, 0x62, 0x99, 0x17, 0x4c, 0x4a, 0x23, 0xd3, 0x3d, 0xa4, 0xea,
+	0x90, 0xf1, 0xbe, 0xd9, 0xf5, 0xd0, 0xfc, 0xb8, 0x7d, 0xfb, 0x9f, 0x64, 0xf2, 0x73, 0xb6, 0x32,
+	0xef, 0xc0, 0xef, 0x86, 0x4c, 0x5e, 0x33, 0xff, 0xc0, 0x3e, 0xd2, 0x00, 0x3f, 0x27, 0xaf, 0x19,
+	0x2a, 0xc0, 0x68, 0x6a, 0x49, 0x52, 0x29, 0xc0, 0x68, 0x6a, 0xc8, 0x93, 0x47, 0x6f, 0xfe, 0x19,
+	0xec, 0xbc, 0xb9, 0x1a, 0x38, 0x6f, 0xaf, 0x06, 0xce, 0xbb, 0xab, 0x81, 0xf3, 0xc7, 0xf5, 0x60,
+	0xe7, 0xed, 0xf5, 0x60, 0xe7, 0xef, 0xeb, 0xc1, 0xce, 0x6f, 0xbd, 0x5b, 0xdf, 0xa6, 0x59, 0x13,
+	0x3f, 0x2e, 0x5f, 0xff, 0x3f, 0x00, 0xcc, 0xb6, 0xed, 0xb9, 0xcd, 0x06, 0x00, 0x00,
 }
 
 func (m *CheckpointsModel) Marshal() (dAtA []byte, err error) {
@@ -619,6 +620,13 @@ func (m *ChunkCheckpointModel) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.RealSize != 0 {
+		i = encodeVarintFileCheckpoints(dAtA, i, uint64(m.RealSize))
+		i--
+		dAtA[i] = 0x1
+		i--
+		dAtA[i] = 0x90
+	}
 	if m.FileSize != 0 {
 		i = encodeVarintFileCheckpoints(dAtA, i, uint64(m.FileSize))
 		i--
@@ -927,6 +935,9 @@ func (m *ChunkCheckpointModel) Size() (n int) {
 	if m.FileSize != 0 {
 		n += 2 + sovFileCheckpoints(uint64(m.FileSize))
 	}
+	if m.RealSize != 0 {
+		n += 2 + sovFileCheckpoints(uint64(m.RealSize))
+	}
 	return n
 }
 
@@ -2359,6 +2370,25 @@ func (m *ChunkCheckpointModel) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 18:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RealSize", wireType)
+			}
+			m.RealSize = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFileCheckpoints
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.RealSize |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipFileCheckpoints(dAtA[iNdEx:])
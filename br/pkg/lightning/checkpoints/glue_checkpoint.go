@@ -293,15 +293,16 @@ func (g GlueCheckpointsDB) Get(ctx context.Context, tableName string) (*TableChe
 				value.FileMeta.Compression = mydump.Compression(row.GetInt64(4))
 				value.FileMeta.SortKey = row.GetString(5)
 				value.FileMeta.FileSize = row.GetInt64(6)
-				colPerm := row.GetBytes(7)
-				value.Chunk.Offset = row.GetInt64(8)
-				value.Chunk.EndOffset = row.GetInt64(9)
-				value.Chunk.PrevRowIDMax = row.GetInt64(10)
-				value.Chunk.RowIDMax = row.GetInt64(11)
-				kvcBytes := row.GetUint64(12)
-				kvcKVs := row.GetUint64(13)
-				kvcChecksum := row.GetUint64(14)
-				value.Timestamp = row.GetInt64(15)
+				value.FileMeta.RealSize = row.GetInt64(7)
+				colPerm := row.GetBytes(8)
+				value.Chunk.Offset = row.GetInt64(9)
+				value.Chunk.EndOffset = row.GetInt64(10)
+				value.Chunk.PrevRowIDMax = row.GetInt64(11)
+				value.Chunk.RowIDMax = row.GetInt64(12)
+				kvcBytes := row.GetUint64(13)
+				kvcKVs := row.GetUint64(14)
+				kvcChecksum := row.GetUint64(15)
+				value.Timestamp = row.GetInt64(16)
 
 				value.FileMeta.Path = value.Key.Path
 				value.Checksum = verify.MakeKVChecksum(kvcBytes, kvcKVs, kvcChecksum)
@@ -394,6 +395,7 @@ func (g GlueCheckpointsDB) InsertEngineCheckpoints(ctx context.Context, tableNam
 					types.NewIntDatum(int64(value.FileMeta.Compression)),
 					types.NewStringDatum(value.FileMeta.SortKey),
 					types.NewIntDatum(value.FileMeta.FileSize),
+					types.NewIntDatum(value.FileMeta.RealSize),
 					types.NewBytesDatum(columnPerm),
 					types.NewIntDatum(value.Chunk.Offset),
 					types.NewIntDatum(value.Chunk.EndOffset),
@@ -87,6 +87,7 @@ var (
 	ErrAllocTableRowIDs   = errors.Normalize("allocate table row id error", errors.RFCCodeText("Lightning:Restore:ErrAllocTableRowIDs"))
 	ErrInvalidMetaStatus  = errors.Normalize("invalid meta status: '%s'", errors.RFCCodeText("Lightning:Restore:ErrInvalidMetaStatus"))
 	ErrTableIsChecksuming = errors.Normalize("table '%s' is checksuming", errors.RFCCodeText("Lightning:Restore:ErrTableIsChecksuming"))
+	ErrFoundDuplicateKeys = errors.Normalize("found duplicate keys in table %s", errors.RFCCodeText("Lightning:Restore:ErrFoundDuplicateKeys"))
 )
 
 type withStack struct {
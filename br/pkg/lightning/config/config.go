@@ -39,6 +39,7 @@ import (
 	"github.com/pingcap/tidb/parser/mysql"
 	filter "github.com/pingcap/tidb/util/table-filter"
 	router "github.com/pingcap/tidb/util/table-router"
+	"github.com/pingcap/tidb/util/timeutil"
 	"go.uber.org/atomic"
 	"go.uber.org/zap"
 )
@@ -69,6 +70,18 @@ const (
 	// ErrorOnDup indicates using INSERT INTO to insert data, which would violate PK or UNIQUE constraint
 	ErrorOnDup = "error"
 
+	// The consistency levels of reading the data from `source-db`, they are the same as `--consistency` of dumpling.
+	// SourceDBConsistencyAuto uses snapshot for TiDB and flush for MySQL.
+	SourceDBConsistencyAuto = "auto"
+	// SourceDBConsistencyFlush uses FLUSH TABLES WITH READ LOCK to start consistent snapshot transactions.
+	SourceDBConsistencyFlush = "flush"
+	// SourceDBConsistencyLock uses LOCK TABLES ... READ to start consistent snapshot transactions.
+	SourceDBConsistencyLock = "lock"
+	// SourceDBConsistencySnapshot reads the data of TiDB at a snapshot TSO.
+	SourceDBConsistencySnapshot = "snapshot"
+	// SourceDBConsistencyNone reads the data without any consistency guarantee.
+	SourceDBConsistencyNone = "none"
+
 	defaultDistSQLScanConcurrency     = 15
 	defaultBuildStatsConcurrency      = 20
 	defaultIndexSerialScanConcurrency = 20
@@ -91,6 +104,9 @@ const (
 	defaultEngineMemCacheSize      = 512 * units.MiB
 	defaultLocalWriterMemCacheSize = 128 * units.MiB
 
+	defaultSourceDBPort      = 3306
+	defaultSourceDBChunkRows = 200000
+
 	defaultCSVDataCharacterSet       = "binary"
 	defaultCSVDataInvalidCharReplace = utf8.RuneError
 )
@@ -130,11 +146,28 @@ type DBStore struct {
 	Vars                       map[string]string `toml:"-" json:"vars"`
 }
 
+// SourceDB is the upstream MySQL or TiDB, the data are read from it directly instead of the data files.
+type SourceDB struct {
+	Host string `toml:"host" json:"host"`
+	Port int    `toml:"port" json:"port"`
+	User string `toml:"user" json:"user"`
+	Psw  string `toml:"password" json:"-"`
+
+	Consistency string `toml:"consistency" json:"consistency"`
+	// Snapshot is the TSO or the time of the snapshot which TiDB is read at, it's the current TSO if not set.
+	Snapshot string `toml:"snapshot" json:"snapshot"`
+	// ChunkRows is the estimated number of rows read by one chunk.
+	ChunkRows int64 `toml:"chunk-rows" json:"chunk-rows"`
+	// TimeZone is the time zone to read the TIMESTAMP values in, it's the name of the system time zone if not set.
+	TimeZone string `toml:"time-zone" json:"time-zone"`
+}
+
 type Config struct {
 	TaskID int64 `toml:"-" json:"id"`
 
-	App  Lightning `toml:"lightning" json:"lightning"`
-	TiDB DBStore   `toml:"tidb" json:"tidb"`
+	App      Lightning `toml:"lightning" json:"lightning"`
+	TiDB     DBStore   `toml:"tidb" json:"tidb"`
+	SourceDB SourceDB  `toml:"source-db" json:"source-db"`
 
 	Checkpoint   Checkpoint          `toml:"checkpoint" json:"checkpoint"`
 	Mydumper     MydumperRuntime     `toml:"mydumper" json:"mydumper"`
@@ -155,6 +188,11 @@ func (cfg *Config) String() string {
 	return string(bytes)
 }
 
+// HasSourceDB returns whether the data are imported from the upstream database instead of the data files.
+func (cfg *Config) HasSourceDB() bool {
+	return len(cfg.SourceDB.Host) > 0
+}
+
 func (cfg *Config) ToTLS() (*common.TLS, error) {
 	hostPort := net.JoinHostPort(cfg.TiDB.Host, strconv.Itoa(cfg.TiDB.StatusPort))
 	return common.NewTLS(cfg.Security.CAPath, cfg.Security.CertPath, cfg.Security.KeyPath, hostPort)
@@ -366,13 +404,28 @@ const (
 	// DupeResAlgRemove records all duplicate records like the 'record' algorithm and remove all information related to the
 	// duplicated rows. Users need to analyze the lightning_task_info.conflict_error_v1 table to add back the correct rows.
 	DupeResAlgRemove
+
+	// DupeResAlgReplace records all duplicate records like the 'record' algorithm, removes them like the 'remove'
+	// algorithm, and then writes one row of each conflict back through TiDB with `REPLACE INTO`, so the last row wins.
+	// The conflicting rows are ordered by their handles, and then by their order in the data source.
+	DupeResAlgReplace
+
+	// DupeResAlgIgnore is the same as the 'replace' algorithm except that the rows are written back with
+	// `INSERT IGNORE INTO`, so the first row wins.
+	DupeResAlgIgnore
+
+	// DupeResAlgErr records all duplicate records like the 'record' algorithm, and fails the import of the table
+	// if any duplicate is found.
+	DupeResAlgErr
 )
 
+const dupeResAlgOptions = "['record', 'none', 'remove', 'replace', 'ignore', 'error']"
+
 func (dra *DuplicateResolutionAlgorithm) UnmarshalTOML(v interface{}) error {
 	if val, ok := v.(string); ok {
 		return dra.FromStringValue(val)
 	}
-	return errors.Errorf("invalid duplicate-resolution '%v', please choose valid option between ['record', 'none', 'remove']", v)
+	return errors.Errorf("invalid duplicate-resolution '%v', please choose valid option between %s", v, dupeResAlgOptions)
 }
 
 func (dra DuplicateResolutionAlgorithm) MarshalText() ([]byte, error) {
@@ -387,8 +440,14 @@ func (dra *DuplicateResolutionAlgorithm) FromStringValue(s string) error {
 		*dra = DupeResAlgNone
 	case "remove":
 		*dra = DupeResAlgRemove
+	case "replace":
+		*dra = DupeResAlgReplace
+	case "ignore":
+		*dra = DupeResAlgIgnore
+	case "error":
+		*dra = DupeResAlgErr
 	default:
-		return errors.Errorf("invalid duplicate-resolution '%s', please choose valid option between ['record', 'none', 'remove']", s)
+		return errors.Errorf("invalid duplicate-resolution '%s', please choose valid option between %s", s, dupeResAlgOptions)
 	}
 	return nil
 }
@@ -409,6 +468,12 @@ func (dra DuplicateResolutionAlgorithm) String() string {
 		return "none"
 	case DupeResAlgRemove:
 		return "remove"
+	case DupeResAlgReplace:
+		return "replace"
+	case DupeResAlgIgnore:
+		return "ignore"
+	case DupeResAlgErr:
+		return "error"
 	default:
 		panic(fmt.Sprintf("invalid duplicate-resolution type '%d'", dra))
 	}
@@ -942,9 +1007,52 @@ func (cfg *Config) Adjust(ctx context.Context) error {
 	}
 	cfg.AdjustMydumper()
 	cfg.AdjustCheckPoint()
+	if cfg.HasSourceDB() {
+		return cfg.CheckAndAdjustSourceDB()
+	}
 	return cfg.CheckAndAdjustFilePath()
 }
 
+// CheckAndAdjustSourceDB checks the settings of reading the data from `source-db`.
+func (cfg *Config) CheckAndAdjustSourceDB() error {
+	if len(cfg.Mydumper.SourceDir) > 0 {
+		return common.ErrInvalidConfig.GenWithStack("`mydumper.data-source-dir` and `source-db` cannot be both set")
+	}
+	if cfg.TikvImporter.Backend != BackendLocal {
+		return common.ErrInvalidConfig.GenWithStack("importing from `source-db` is only supported by the local backend")
+	}
+	if cfg.SourceDB.Port <= 0 {
+		cfg.SourceDB.Port = defaultSourceDBPort
+	}
+	if cfg.SourceDB.ChunkRows <= 0 {
+		cfg.SourceDB.ChunkRows = defaultSourceDBChunkRows
+	}
+	if len(cfg.SourceDB.TimeZone) == 0 {
+		// the name of the time zone is used instead of its current offset, so that the values in and out
+		// of the daylight saving time are read in the right offsets.
+		cfg.SourceDB.TimeZone = timeutil.InferSystemTZ()
+		// UTC doesn't need the time zone tables of MySQL.
+		if cfg.SourceDB.TimeZone == "UTC" {
+			cfg.SourceDB.TimeZone = "+00:00"
+		}
+	}
+
+	cfg.SourceDB.Consistency = strings.ToLower(cfg.SourceDB.Consistency)
+	switch cfg.SourceDB.Consistency {
+	case "":
+		cfg.SourceDB.Consistency = SourceDBConsistencyAuto
+	case SourceDBConsistencyAuto, SourceDBConsistencyFlush, SourceDBConsistencyLock,
+		SourceDBConsistencySnapshot, SourceDBConsistencyNone:
+	default:
+		return common.ErrInvalidConfig.GenWithStack("unsupported `source-db.consistency` (%s)", cfg.SourceDB.Consistency)
+	}
+	if len(cfg.SourceDB.Snapshot) > 0 && cfg.SourceDB.Consistency != SourceDBConsistencyAuto &&
+		cfg.SourceDB.Consistency != SourceDBConsistencySnapshot {
+		return common.ErrInvalidConfig.GenWithStack("`source-db.snapshot` is only available when `source-db.consistency` is snapshot")
+	}
+	return nil
+}
+
 func (cfg *Config) CheckAndAdjustForLocalBackend() error {
 	if len(cfg.TikvImporter.SortedKVDir) == 0 {
 		return common.ErrInvalidConfig.GenWithStack("tikv-importer.sorted-kv-dir must not be empty!")
@@ -557,10 +557,20 @@ func TestDuplicateResolutionAlgorithm(t *testing.T) {
 	require.Equal(t, config.DupeResAlgNone, dra)
 	require.NoError(t, dra.FromStringValue("remove"))
 	require.Equal(t, config.DupeResAlgRemove, dra)
+	require.NoError(t, dra.FromStringValue("REPLACE"))
+	require.Equal(t, config.DupeResAlgReplace, dra)
+	require.NoError(t, dra.FromStringValue("ignore"))
+	require.Equal(t, config.DupeResAlgIgnore, dra)
+	require.NoError(t, dra.FromStringValue("error"))
+	require.Equal(t, config.DupeResAlgErr, dra)
+	require.Error(t, dra.FromStringValue("overwrite"))
 
 	require.Equal(t, "record", config.DupeResAlgRecord.String())
 	require.Equal(t, "none", config.DupeResAlgNone.String())
 	require.Equal(t, "remove", config.DupeResAlgRemove.String())
+	require.Equal(t, "replace", config.DupeResAlgReplace.String())
+	require.Equal(t, "ignore", config.DupeResAlgIgnore.String())
+	require.Equal(t, "error", config.DupeResAlgErr.String())
 }
 
 func TestLoadConfig(t *testing.T) {
@@ -950,3 +960,38 @@ func TestCheckAndAdjustForLocalBackend(t *testing.T) {
 	cfg.TikvImporter.SortedKVDir = base
 	require.NoError(t, cfg.CheckAndAdjustForLocalBackend())
 }
+
+func TestAdjustSourceDB(t *testing.T) {
+	cfg := config.NewConfig()
+	assignMinimalLegalValue(cfg)
+	cfg.SourceDB.Host = "127.0.0.1"
+	require.EqualError(t, cfg.Adjust(context.Background()),
+		"[Lightning:Config:ErrInvalidConfig]`mydumper.data-source-dir` and `source-db` cannot be both set")
+
+	cfg.Mydumper.SourceDir = ""
+	require.NoError(t, cfg.Adjust(context.Background()))
+	require.Equal(t, 3306, cfg.SourceDB.Port)
+	require.Equal(t, config.SourceDBConsistencyAuto, cfg.SourceDB.Consistency)
+	require.Equal(t, int64(200000), cfg.SourceDB.ChunkRows)
+	require.NotEmpty(t, cfg.SourceDB.TimeZone)
+	require.NotEqual(t, "UTC", cfg.SourceDB.TimeZone)
+
+	cfg.SourceDB.Consistency = "Flush"
+	cfg.SourceDB.TimeZone = "Asia/Shanghai"
+	require.NoError(t, cfg.CheckAndAdjustSourceDB())
+	require.Equal(t, config.SourceDBConsistencyFlush, cfg.SourceDB.Consistency)
+	require.Equal(t, "Asia/Shanghai", cfg.SourceDB.TimeZone)
+
+	cfg.SourceDB.Snapshot = "433281446521700352"
+	require.EqualError(t, cfg.CheckAndAdjustSourceDB(),
+		"[Lightning:Config:ErrInvalidConfig]`source-db.snapshot` is only available when `source-db.consistency` is snapshot")
+
+	cfg.SourceDB.Consistency = "serializable"
+	require.EqualError(t, cfg.CheckAndAdjustSourceDB(),
+		"[Lightning:Config:ErrInvalidConfig]unsupported `source-db.consistency` (serializable)")
+
+	cfg.SourceDB.Consistency = config.SourceDBConsistencySnapshot
+	cfg.TikvImporter.Backend = config.BackendTiDB
+	require.EqualError(t, cfg.CheckAndAdjustSourceDB(),
+		"[Lightning:Config:ErrInvalidConfig]importing from `source-db` is only supported by the local backend")
+}
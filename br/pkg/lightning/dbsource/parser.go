@@ -0,0 +1,245 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbsource
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/lightning/common"
+	"github.com/pingcap/tidb/br/pkg/lightning/log"
+	"github.com/pingcap/tidb/br/pkg/lightning/mydump"
+	"github.com/pingcap/tidb/types"
+	"go.uber.org/zap"
+)
+
+// Parser reads the rows of a chunk from the upstream table. If the table has an integer handle,
+// the position is the handle of the next row to read, otherwise it's the number of rows read.
+// The connection is acquired when the first row is read, so the parsers which are waiting for
+// the workers don't hold the connections.
+type Parser struct {
+	ctx       context.Context
+	src       *Source
+	table     *upstreamTable
+	endOffset int64
+
+	pos     int64
+	lastRow mydump.Row
+	columns []string
+
+	conn   *sql.Conn
+	rows   *sql.Rows
+	values []sql.RawBytes
+	dest   []interface{}
+
+	logger log.Logger
+}
+
+// NewParser creates a parser to read the chunk of which the offset ends at endOffset.
+func (s *Source) NewParser(ctx context.Context, fileMeta mydump.SourceFileMeta, endOffset int64) (*Parser, error) {
+	tbl, ok := s.tableMap[fileMeta.Path]
+	if !ok {
+		return nil, errors.Errorf("table %s isn't found in the upstream database", fileMeta.Path)
+	}
+	return &Parser{
+		ctx:       ctx,
+		src:       s,
+		table:     tbl,
+		endOffset: endOffset,
+		columns:   tbl.columnNames(),
+		logger:    log.With(zap.String("table", fileMeta.Path)),
+	}, nil
+}
+
+// Pos returns the position and the row ID of the last row.
+func (p *Parser) Pos() (pos int64, rowID int64) {
+	return p.pos, p.lastRow.RowID
+}
+
+// SetPos sets the position to start reading, it must be called before reading any row.
+func (p *Parser) SetPos(pos int64, rowID int64) error {
+	if p.conn != nil {
+		return errors.New("can't set the position of a started parser")
+	}
+	p.pos = pos
+	p.lastRow.RowID = rowID
+	return nil
+}
+
+func (p *Parser) buildQuery() (string, []interface{}) {
+	var sb strings.Builder
+	sb.WriteString("SELECT ")
+	for i, col := range p.table.columns {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(common.EscapeIdentifier(col))
+	}
+	handle := p.table.handle
+	if handle == "" {
+		fmt.Fprintf(&sb, " FROM %s", p.table.path())
+		if len(p.table.orderBy) > 0 {
+			sb.WriteString(" ORDER BY ")
+			for i, col := range p.table.orderBy {
+				if i > 0 {
+					sb.WriteByte(',')
+				}
+				sb.WriteString(common.EscapeIdentifier(col))
+			}
+		}
+		if p.pos > 0 {
+			// skip the rows read before, 18446744073709551615 is the way of MySQL to read all the rest rows.
+			fmt.Fprintf(&sb, " LIMIT %d,18446744073709551615", p.pos)
+		}
+		return sb.String(), nil
+	}
+
+	escapedHandle := common.EscapeIdentifier(handle)
+	fmt.Fprintf(&sb, ",%s FROM %s WHERE %s >= ?", escapedHandle, p.table.path(), escapedHandle)
+	args := []interface{}{p.pos}
+	if p.endOffset < math.MaxInt64 {
+		fmt.Fprintf(&sb, " AND %s < ?", escapedHandle)
+		args = append(args, p.endOffset)
+	}
+	fmt.Fprintf(&sb, " ORDER BY %s", escapedHandle)
+	return sb.String(), args
+}
+
+func (p *Parser) startQuery() error {
+	conn, err := p.src.acquireConn(p.ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	p.conn = conn
+	query, args := p.buildQuery()
+	p.logger.Debug("read the chunk", zap.String("sql", query), zap.Int64("pos", p.pos))
+	rows, err := conn.QueryContext(p.ctx, query, args...)
+	if err != nil {
+		return errors.Annotatef(err, "sql: %s", query)
+	}
+	p.rows = rows
+
+	count := len(p.table.columns)
+	if p.table.handle != "" {
+		count++
+	}
+	p.values = make([]sql.RawBytes, count)
+	p.dest = make([]interface{}, count)
+	for i := range p.values {
+		p.dest[i] = &p.values[i]
+	}
+	return nil
+}
+
+// ReadRow reads a row from the upstream table.
+func (p *Parser) ReadRow() error {
+	if p.rows == nil {
+		if p.conn != nil {
+			// the rows have been read to the end.
+			return io.EOF
+		}
+		if err := p.startQuery(); err != nil {
+			return err
+		}
+	}
+	if !p.rows.Next() {
+		err := p.rows.Err()
+		_ = p.rows.Close()
+		p.rows = nil
+		if err != nil {
+			return errors.Trace(err)
+		}
+		return io.EOF
+	}
+	if err := p.rows.Scan(p.dest...); err != nil {
+		return errors.Trace(err)
+	}
+
+	columnCount := len(p.table.columns)
+	if cap(p.lastRow.Row) < columnCount {
+		p.lastRow.Row = make([]types.Datum, columnCount)
+	} else {
+		p.lastRow.Row = p.lastRow.Row[:columnCount]
+	}
+	p.lastRow.Length = 0
+	for i := 0; i < columnCount; i++ {
+		v := p.values[i]
+		if v == nil {
+			p.lastRow.Row[i].SetNull()
+			continue
+		}
+		p.lastRow.Row[i].SetString(string(v), "utf8mb4_bin")
+		p.lastRow.Length += len(v)
+	}
+
+	// the row IDs are allocated by the number of rows, see (*Source).MakeTableRegions.
+	p.lastRow.RowID++
+	if p.table.handle == "" {
+		p.pos++
+		return nil
+	}
+	handle, err := strconv.ParseInt(string(p.values[columnCount]), 10, 64)
+	if err != nil {
+		return errors.Annotatef(err, "invalid handle %q", p.values[columnCount])
+	}
+	p.pos = handle + 1
+	if handle == math.MaxInt64 {
+		p.pos = math.MaxInt64
+	}
+	return nil
+}
+
+// LastRow returns the last row read.
+func (p *Parser) LastRow() mydump.Row {
+	return p.lastRow
+}
+
+// RecycleRow implements mydump.Parser, the datum slice is reused by the next row.
+func (p *Parser) RecycleRow(_ mydump.Row) {}
+
+// Columns returns the lower-case names of the columns read.
+func (p *Parser) Columns() []string {
+	return p.columns
+}
+
+// SetColumns implements mydump.Parser.
+func (p *Parser) SetColumns(columns []string) {
+	p.columns = columns
+}
+
+// SetLogger implements mydump.Parser.
+func (p *Parser) SetLogger(logger log.Logger) {
+	p.logger = logger
+}
+
+// Close closes the rows and gives the connection back.
+func (p *Parser) Close() error {
+	var err error
+	if p.rows != nil {
+		err = p.rows.Close()
+		p.rows = nil
+	}
+	if p.conn != nil {
+		p.src.releaseConn(p.conn)
+		p.conn = nil
+	}
+	return errors.Trace(err)
+}
@@ -0,0 +1,442 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dbsource reads the data to import from a live MySQL or TiDB directly instead of the data
+// files. The connections and the consistency of the upstream database are set up in the same way
+// as dumpling.
+package dbsource
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/lightning/common"
+	"github.com/pingcap/tidb/br/pkg/lightning/config"
+	"github.com/pingcap/tidb/br/pkg/lightning/log"
+	"github.com/pingcap/tidb/br/pkg/lightning/mydump"
+	"github.com/pingcap/tidb/br/pkg/storage"
+	"github.com/pingcap/tidb/br/pkg/version"
+	tcontext "github.com/pingcap/tidb/dumpling/context"
+	"github.com/pingcap/tidb/dumpling/export"
+	dlog "github.com/pingcap/tidb/dumpling/log"
+	regexprrouter "github.com/pingcap/tidb/util/regexpr-router"
+	tfilter "github.com/pingcap/tidb/util/table-filter"
+	"go.uber.org/zap"
+)
+
+const (
+	// the schema files are generated with the same names as dumpling, so they can be loaded by mydump.
+	dbSchemaFileFmt    = "%s-schema-create.sql"
+	tableSchemaFileFmt = "%s.%s-schema.sql"
+
+	// snapshotFieldIndex is the index of the TSO in the result of SHOW MASTER STATUS on TiDB.
+	snapshotFieldIndex = 1
+)
+
+// Source is the upstream database which the data are imported from.
+type Source struct {
+	cfg      *config.Config
+	dumpConf *export.Config
+	db       *sql.DB
+	logger   log.Logger
+
+	// conns are the connections to read the data, they are created at the beginning so they
+	// all read the same consistent snapshot.
+	conns    chan *sql.Conn
+	allConns []*sql.Conn
+
+	tables   []*upstreamTable
+	tableMap map[string]*upstreamTable
+}
+
+// Open connects to the upstream database in `source-db` and sets up the consistency.
+func Open(ctx context.Context, cfg *config.Config) (*Source, error) {
+	dumpConf := export.DefaultConfig()
+	dumpConf.Host = cfg.SourceDB.Host
+	dumpConf.Port = cfg.SourceDB.Port
+	dumpConf.User = cfg.SourceDB.User
+	dumpConf.Password = cfg.SourceDB.Psw
+	dumpConf.Consistency = cfg.SourceDB.Consistency
+	dumpConf.Snapshot = cfg.SourceDB.Snapshot
+
+	db, err := sql.Open("mysql", dumpConf.GetDSN(""))
+	if err != nil {
+		return nil, common.ErrDBConnect.Wrap(err)
+	}
+	s := newSource(cfg, dumpConf, db)
+	if err := s.setup(ctx); err != nil {
+		s.Close()
+		return nil, errors.Trace(err)
+	}
+	return s, nil
+}
+
+func newSource(cfg *config.Config, dumpConf *export.Config, db *sql.DB) *Source {
+	return &Source{
+		cfg:      cfg,
+		dumpConf: dumpConf,
+		db:       db,
+		logger:   log.With(zap.String("source-db", fmt.Sprintf("%s:%d", dumpConf.Host, dumpConf.Port))),
+		tableMap: make(map[string]*upstreamTable),
+	}
+}
+
+func (s *Source) setup(ctx context.Context) error {
+	versionStr, err := version.FetchVersion(ctx, s.db)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	conf := s.dumpConf
+	conf.ServerInfo = version.ParseServerInfo(versionStr)
+	if conf.Consistency == config.SourceDBConsistencyAuto {
+		switch conf.ServerInfo.ServerType {
+		case version.ServerTypeTiDB:
+			conf.Consistency = config.SourceDBConsistencySnapshot
+		case version.ServerTypeMySQL, version.ServerTypeMariaDB:
+			conf.Consistency = config.SourceDBConsistencyFlush
+		default:
+			conf.Consistency = config.SourceDBConsistencyNone
+		}
+	}
+	if conf.Snapshot != "" && conf.Consistency != config.SourceDBConsistencySnapshot {
+		return common.ErrInvalidConfig.GenWithStack("`source-db.snapshot` is only available when the consistency is snapshot, resolved consistency: %s", conf.Consistency)
+	}
+
+	if err := s.listTables(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	for _, tbl := range s.tables {
+		conf.Tables = conf.Tables.AppendTables(tbl.db, []string{tbl.name}, []uint64{0})
+	}
+	if err := s.resetDB(ctx); err != nil {
+		return errors.Trace(err)
+	}
+
+	controller, err := export.NewConsistencyController(ctx, conf, s.db)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	tctx := s.tctx(ctx)
+	if err := controller.Setup(tctx); err != nil {
+		return errors.Trace(err)
+	}
+	err = s.initConns(ctx, s.cfg.App.RegionConcurrency)
+	if err1 := controller.TearDown(ctx); err1 != nil && err == nil {
+		err = err1
+	}
+	if err != nil {
+		return errors.Trace(err)
+	}
+	s.logger.Info("connected to the upstream database",
+		zap.Stringer("server", conf.ServerInfo.ServerType),
+		zap.String("consistency", conf.Consistency),
+		zap.String("snapshot", conf.Snapshot),
+		zap.Int("tables", len(s.tables)))
+	return nil
+}
+
+// resetDB reopens the connection pool with the session variables to read the data. The snapshot
+// of TiDB is resolved here if it's not specified.
+func (s *Source) resetDB(ctx context.Context) error {
+	conf := s.dumpConf
+	// the TIMESTAMP values are read as strings in the time zone of lightning, which are encoded in
+	// the same time zone. MySQL needs the time zone tables to be loaded to use the named time zones.
+	dsn := conf.GetDSN("") + "&time_zone=" + url.QueryEscape(fmt.Sprintf("'%s'", s.cfg.SourceDB.TimeZone))
+	if conf.Consistency == config.SourceDBConsistencySnapshot {
+		if conf.ServerInfo.ServerType != version.ServerTypeTiDB {
+			return common.ErrInvalidConfig.GenWithStack("snapshot consistency is only supported by TiDB")
+		}
+		if conf.Snapshot == "" {
+			conn, err := s.db.Conn(ctx)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			status, err := export.ShowMasterStatus(conn)
+			_ = conn.Close()
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if len(status) <= snapshotFieldIndex {
+				return errors.Errorf("unexpected result of SHOW MASTER STATUS: %v", status)
+			}
+			conf.Snapshot = status[snapshotFieldIndex]
+		}
+		dsn += "&tidb_snapshot=" + url.QueryEscape(fmt.Sprintf("'%s'", conf.Snapshot))
+		s.logger.Warn("the data are read at a snapshot of TiDB, please make sure the GC life time of the upstream "+
+			"cluster is longer than the import, and set `source-db.snapshot` to the same snapshot when resuming "+
+			"from the checkpoints", zap.String("snapshot", conf.Snapshot))
+	}
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return common.ErrDBConnect.Wrap(err)
+	}
+	_ = s.db.Close()
+	s.db = db
+	return nil
+}
+
+// initConns creates the connections to read the data, they start the consistent snapshot
+// transactions in the same way as the writers of dumpling.
+func (s *Source) initConns(ctx context.Context, count int) error {
+	conf := s.dumpConf
+	repeatableRead := conf.Consistency != config.SourceDBConsistencySnapshot || conf.ServerInfo.ServerType != version.ServerTypeTiDB
+	s.conns = make(chan *sql.Conn, count)
+	for i := 0; i < count; i++ {
+		conn, err := export.CreateConnWithConsistency(ctx, s.db, repeatableRead)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		s.allConns = append(s.allConns, conn)
+		s.conns <- conn
+	}
+	return nil
+}
+
+func (s *Source) tctx(ctx context.Context) *tcontext.Context {
+	return tcontext.Background().WithContext(ctx).WithLogger(dlog.NewAppLogger(s.logger.Logger))
+}
+
+func (s *Source) acquireConn(ctx context.Context) (*sql.Conn, error) {
+	select {
+	case conn := <-s.conns:
+		return conn, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (s *Source) releaseConn(conn *sql.Conn) {
+	s.conns <- conn
+}
+
+// Close closes all the connections to the upstream database.
+func (s *Source) Close() {
+	for _, conn := range s.allConns {
+		_ = conn.Close()
+	}
+	s.allConns = nil
+	if s.db != nil {
+		_ = s.db.Close()
+	}
+}
+
+// listTables lists the base tables in the upstream database which are matched by the filter.
+func (s *Source) listTables(ctx context.Context) error {
+	cfg := s.cfg
+	var f tfilter.Filter
+	var err error
+	if cfg.HasLegacyBlackWhiteList() {
+		f, err = tfilter.ParseMySQLReplicationRules(&cfg.BWList)
+	} else {
+		f, err = tfilter.Parse(cfg.Mydumper.Filter)
+	}
+	if err != nil {
+		return common.ErrInvalidConfig.Wrap(err).GenWithStack("parse filter failed")
+	}
+	if !cfg.Mydumper.CaseSensitive {
+		f = tfilter.CaseInsensitive(f)
+	}
+
+	const query = "SELECT TABLE_SCHEMA, TABLE_NAME, DATA_LENGTH, TABLE_ROWS FROM INFORMATION_SCHEMA.TABLES " +
+		"WHERE TABLE_TYPE = 'BASE TABLE' ORDER BY TABLE_SCHEMA, TABLE_NAME"
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return errors.Annotatef(err, "sql: %s", query)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			dbName, tblName      string
+			dataLength, rowCount sql.NullInt64
+		)
+		if err := rows.Scan(&dbName, &tblName, &dataLength, &rowCount); err != nil {
+			return errors.Trace(err)
+		}
+		if !f.MatchTable(dbName, tblName) {
+			s.logger.Debug("skip the table not matched by the filter", zap.String("table", common.UniqueTable(dbName, tblName)))
+			continue
+		}
+		tbl := &upstreamTable{
+			db:         dbName,
+			name:       tblName,
+			dataLength: dataLength.Int64,
+			rowCount:   rowCount.Int64,
+		}
+		s.tables = append(s.tables, tbl)
+		s.tableMap[tbl.path()] = tbl
+	}
+	if err := rows.Err(); err != nil {
+		return errors.Trace(err)
+	}
+	return s.checkExplicitTables()
+}
+
+// checkExplicitTables checks the tables configured by name in the filter are all found in the upstream
+// database, the misspelled ones would be skipped silently otherwise.
+func (s *Source) checkExplicitTables() error {
+	var explicitTables []tfilter.Table
+	if s.cfg.HasLegacyBlackWhiteList() {
+		for _, tbl := range s.cfg.BWList.DoTables {
+			if !strings.HasPrefix(tbl.Schema, "~") && !strings.HasPrefix(tbl.Name, "~") {
+				explicitTables = append(explicitTables, tfilter.Table{Schema: tbl.Schema, Name: tbl.Name})
+			}
+		}
+	} else {
+		for _, rule := range s.cfg.Mydumper.Filter {
+			// only the plain `db.tbl` rules name the tables, the others are patterns or exclusions.
+			parts := strings.Split(strings.TrimSpace(rule), ".")
+			if len(parts) != 2 || strings.ContainsAny(rule, "!@*?[]~\\`\"'/") {
+				continue
+			}
+			explicitTables = append(explicitTables, tfilter.Table{Schema: parts[0], Name: parts[1]})
+		}
+	}
+	found := make(map[string]struct{}, len(s.tables))
+	for _, tbl := range s.tables {
+		key := tbl.path()
+		if !s.cfg.Mydumper.CaseSensitive {
+			key = strings.ToLower(key)
+		}
+		found[key] = struct{}{}
+	}
+	for _, tbl := range explicitTables {
+		key := common.UniqueTable(tbl.Schema, tbl.Name)
+		if !s.cfg.Mydumper.CaseSensitive {
+			key = strings.ToLower(key)
+		}
+		if _, ok := found[key]; !ok {
+			return common.ErrInvalidConfig.GenWithStack("table %s in the filter isn't found in the upstream database",
+				common.UniqueTable(tbl.Schema, tbl.Name))
+		}
+	}
+	return nil
+}
+
+// Load generates the schema files of the tables in a memory storage, and loads the tables as the
+// data files of mydump. Each upstream table is a data file of which the type is mydump.SourceTypeDB.
+func (s *Source) Load(ctx context.Context) ([]*mydump.MDDatabaseMeta, storage.ExternalStorage, error) {
+	conn, err := s.acquireConn(ctx)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	defer s.releaseConn(conn)
+	baseConn := export.NewBaseConn(conn)
+	tctx := s.tctx(ctx)
+
+	store := storage.NewMemStorage()
+	createdDBs := make(map[string]struct{})
+	for _, tbl := range s.tables {
+		if _, ok := createdDBs[tbl.db]; !ok {
+			createDB, err := export.ShowCreateDatabase(tctx, baseConn, tbl.db)
+			if err != nil {
+				return nil, nil, errors.Trace(err)
+			}
+			if err := store.WriteFile(ctx, fmt.Sprintf(dbSchemaFileFmt, tbl.db), []byte(createDB+";\n")); err != nil {
+				return nil, nil, errors.Trace(err)
+			}
+			createdDBs[tbl.db] = struct{}{}
+		}
+		createTable, err := export.ShowCreateTable(tctx, baseConn, tbl.db, tbl.name)
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		if err := store.WriteFile(ctx, fmt.Sprintf(tableSchemaFileFmt, tbl.db, tbl.name), []byte(createTable+";\n")); err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		if err := tbl.loadColumns(tctx, baseConn, s.dumpConf.ServerInfo.ServerType); err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+	}
+
+	// the data files can't be routed by the file routers, only the default rules are used to find the schema files.
+	loaderCfg := *s.cfg
+	loaderCfg.Mydumper.FileRouters = nil
+	loaderCfg.Mydumper.DefaultFileRules = true
+	mdl, err := mydump.NewMyDumpLoaderWithStore(ctx, &loaderCfg, store)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	dbMetas := mdl.GetDatabases()
+	if err := s.attachTables(dbMetas); err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	return dbMetas, store, nil
+}
+
+// attachTables adds the upstream tables to the data files of the tables they are routed to.
+func (s *Source) attachTables(dbMetas []*mydump.MDDatabaseMeta) error {
+	var r *regexprrouter.RouteTable
+	var err error
+	if len(s.cfg.Routes) > 0 {
+		r, err = regexprrouter.NewRegExprRouter(s.cfg.Mydumper.CaseSensitive, s.cfg.Routes)
+		if err != nil {
+			return common.ErrInvalidConfig.Wrap(err).GenWithStack("invalid table route rule")
+		}
+	}
+	tableKey := func(db, tbl string) string {
+		key := common.UniqueTable(db, tbl)
+		if !s.cfg.Mydumper.CaseSensitive {
+			key = strings.ToLower(key)
+		}
+		return key
+	}
+	tableMetas := make(map[string]*mydump.MDTableMeta)
+	for _, dbMeta := range dbMetas {
+		for _, tblMeta := range dbMeta.Tables {
+			tableMetas[tableKey(tblMeta.DB, tblMeta.Name)] = tblMeta
+		}
+	}
+
+	for _, tbl := range s.tables {
+		targetDB, targetTable := tbl.db, tbl.name
+		if r != nil {
+			targetDB, targetTable, err = r.Route(tbl.db, tbl.name)
+			if err != nil {
+				return common.ErrTableRoute.Wrap(err).GenWithStackByArgs()
+			}
+		}
+		tblMeta, ok := tableMetas[tableKey(targetDB, targetTable)]
+		if !ok {
+			// the schema files are routed in the same way by mydump, so every table should have a target.
+			return errors.Errorf("table %s is routed to %s, which has no schema",
+				tbl.path(), common.UniqueTable(targetDB, targetTable))
+		}
+		tblMeta.DataFiles = append(tblMeta.DataFiles, mydump.FileInfo{
+			TableName: tfilter.Table{Schema: tbl.db, Name: tbl.name},
+			FileMeta: mydump.SourceFileMeta{
+				Path:     tbl.path(),
+				Type:     mydump.SourceTypeDB,
+				SortKey:  tbl.path(),
+				FileSize: tbl.dataLength,
+				RealSize: tbl.dataLength,
+			},
+		})
+		tblMeta.TotalSize += tbl.dataLength
+		// the rows are read in the order of the handle.
+		tblMeta.IsRowOrdered = len(tblMeta.DataFiles) == 1 && tbl.handle != ""
+	}
+
+	for _, dbMeta := range dbMetas {
+		meta := dbMeta
+		sort.SliceStable(meta.Tables, func(i, j int) bool {
+			return meta.Tables[i].TotalSize < meta.Tables[j].TotalSize
+		})
+	}
+	return nil
+}
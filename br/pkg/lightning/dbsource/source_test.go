@@ -0,0 +1,279 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbsource
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pingcap/tidb/br/pkg/lightning/config"
+	"github.com/pingcap/tidb/br/pkg/lightning/mydump"
+	"github.com/pingcap/tidb/dumpling/export"
+	"github.com/pingcap/tidb/types"
+	router "github.com/pingcap/tidb/util/table-router"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSource(t *testing.T, cfg *config.Config, tables ...*upstreamTable) (*Source, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	s := newSource(cfg, export.DefaultConfig(), db)
+	conn, err := db.Conn(context.Background())
+	require.NoError(t, err)
+	s.allConns = append(s.allConns, conn)
+	s.conns = make(chan *sql.Conn, 1)
+	s.conns <- conn
+	for _, tbl := range tables {
+		s.tables = append(s.tables, tbl)
+		s.tableMap[tbl.path()] = tbl
+	}
+	t.Cleanup(s.Close)
+	return s, mock
+}
+
+func newTestConfig() *config.Config {
+	cfg := config.NewConfig()
+	cfg.Mydumper.BatchImportRatio = 0.75
+	cfg.App.TableConcurrency = 1
+	cfg.SourceDB.ChunkRows = 100
+	return cfg
+}
+
+func tableMeta(tbl *upstreamTable) *mydump.MDTableMeta {
+	return &mydump.MDTableMeta{
+		DB:   "target",
+		Name: tbl.name,
+		DataFiles: []mydump.FileInfo{{
+			FileMeta: mydump.SourceFileMeta{
+				Path:     tbl.path(),
+				Type:     mydump.SourceTypeDB,
+				FileSize: tbl.dataLength,
+			},
+		}},
+		TotalSize: tbl.dataLength,
+	}
+}
+
+func TestMakeTableRegionsByHandle(t *testing.T) {
+	tbl := &upstreamTable{db: "db", name: "t", dataLength: 3000, rowCount: 250, columns: []string{"ID", "v"}, handle: "ID"}
+	s, mock := newTestSource(t, newTestConfig(), tbl)
+	mock.ExpectQuery("SELECT MIN(`ID`), MAX(`ID`) FROM `db`.`t`").
+		WillReturnRows(sqlmock.NewRows([]string{"MIN", "MAX"}).AddRow(1, 300))
+	// the row IDs are allocated by the number of rows in the chunks.
+	for i, count := range []int{100, 20, 130} {
+		mock.ExpectQuery("SELECT COUNT(*) FROM `db`.`t` WHERE `ID` >= ? AND `ID` < ?").
+			WithArgs(int64(i*100+1), int64(i*100+101)).
+			WillReturnRows(sqlmock.NewRows([]string{"COUNT"}).AddRow(count))
+	}
+
+	regions, err := s.MakeTableRegions(context.Background(), tableMeta(tbl))
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.Len(t, regions, 3)
+	expected := [][4]int64{
+		{1, 101, 0, 100},
+		{101, 201, 100, 120},
+		{201, 301, 120, 250},
+	}
+	for i, region := range regions {
+		require.Equal(t, "target", region.DB)
+		require.Equal(t, expected[i][0], region.Chunk.Offset)
+		require.Equal(t, expected[i][1], region.Chunk.EndOffset)
+		require.Equal(t, expected[i][2], region.Chunk.PrevRowIDMax)
+		require.Equal(t, expected[i][3], region.Chunk.RowIDMax)
+		require.Equal(t, []string{"id", "v"}, region.Chunk.Columns)
+		require.Equal(t, int64(1000), region.FileMeta.FileSize)
+	}
+}
+
+func TestMakeTableRegionsUnboundedHandle(t *testing.T) {
+	tbl := &upstreamTable{db: "db", name: "t", dataLength: 100, rowCount: 10, columns: []string{"id"}, handle: "id"}
+	s, mock := newTestSource(t, newTestConfig(), tbl)
+	mock.ExpectQuery("SELECT MIN(`id`), MAX(`id`) FROM `db`.`t`").
+		WillReturnRows(sqlmock.NewRows([]string{"MIN", "MAX"}).AddRow(math.MinInt64, math.MaxInt64))
+	mock.ExpectQuery("SELECT COUNT(*) FROM `db`.`t` WHERE `id` >= ?").
+		WithArgs(int64(math.MinInt64)).
+		WillReturnRows(sqlmock.NewRows([]string{"COUNT"}).AddRow(2))
+
+	regions, err := s.MakeTableRegions(context.Background(), tableMeta(tbl))
+	require.NoError(t, err)
+	require.Len(t, regions, 1)
+	require.Equal(t, int64(math.MinInt64), regions[0].Chunk.Offset)
+	require.Equal(t, int64(math.MaxInt64), regions[0].Chunk.EndOffset)
+	require.Equal(t, int64(0), regions[0].Chunk.PrevRowIDMax)
+	require.Equal(t, int64(2), regions[0].Chunk.RowIDMax)
+
+	// an empty table has no regions.
+	mock.ExpectQuery("SELECT MIN(`id`), MAX(`id`) FROM `db`.`t`").
+		WillReturnRows(sqlmock.NewRows([]string{"MIN", "MAX"}).AddRow(nil, nil))
+	regions, err = s.MakeTableRegions(context.Background(), tableMeta(tbl))
+	require.NoError(t, err)
+	require.Len(t, regions, 0)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMakeTableRegionsWithoutHandle(t *testing.T) {
+	tbl := &upstreamTable{db: "db", name: "t", dataLength: 3000, rowCount: 250, columns: []string{"a", "b"}, orderBy: []string{"a"}}
+	s, mock := newTestSource(t, newTestConfig(), tbl)
+	mock.ExpectQuery("SELECT COUNT(*) FROM `db`.`t`").
+		WillReturnRows(sqlmock.NewRows([]string{"COUNT"}).AddRow(250))
+
+	regions, err := s.MakeTableRegions(context.Background(), tableMeta(tbl))
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.Len(t, regions, 1)
+	require.Equal(t, int64(0), regions[0].Chunk.Offset)
+	require.Equal(t, mydump.TableFileSizeINF, regions[0].Chunk.EndOffset)
+	require.Equal(t, int64(0), regions[0].Chunk.PrevRowIDMax)
+	require.Equal(t, int64(250), regions[0].Chunk.RowIDMax)
+}
+
+func TestMakeTableRegionsRowIDOverflow(t *testing.T) {
+	tbl1 := &upstreamTable{db: "db", name: "t1", dataLength: 100, columns: []string{"a"}}
+	tbl2 := &upstreamTable{db: "db", name: "t2", dataLength: 100, columns: []string{"a"}}
+	s, mock := newTestSource(t, newTestConfig(), tbl1, tbl2)
+	meta := tableMeta(tbl1)
+	meta.DataFiles = append(meta.DataFiles, tableMeta(tbl2).DataFiles...)
+	mock.ExpectQuery("SELECT COUNT(*) FROM `db`.`t1`").
+		WillReturnRows(sqlmock.NewRows([]string{"COUNT"}).AddRow(math.MaxInt64 - 10))
+	mock.ExpectQuery("SELECT COUNT(*) FROM `db`.`t2`").
+		WillReturnRows(sqlmock.NewRows([]string{"COUNT"}).AddRow(11))
+
+	_, err := s.MakeTableRegions(context.Background(), meta)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "the row IDs of table `db`.`t2` overflow")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestParserByHandle(t *testing.T) {
+	tbl := &upstreamTable{db: "db", name: "t", columns: []string{"id", "v"}, handle: "id"}
+	s, mock := newTestSource(t, newTestConfig(), tbl)
+	mock.ExpectQuery("SELECT `id`,`v`,`id` FROM `db`.`t` WHERE `id` >= ? AND `id` < ? ORDER BY `id`").
+		WithArgs(int64(11), int64(101)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "v", "id"}).AddRow(11, "a", 11).AddRow(15, nil, 15))
+
+	parser, err := s.NewParser(context.Background(), mydump.SourceFileMeta{Path: tbl.path()}, 101)
+	require.NoError(t, err)
+	// 10 rows have been read before handle 11.
+	require.NoError(t, parser.SetPos(11, 10))
+
+	require.NoError(t, parser.ReadRow())
+	row := parser.LastRow()
+	require.Equal(t, int64(11), row.RowID)
+	require.Equal(t, []types.Datum{types.NewStringDatum("11"), types.NewStringDatum("a")}, row.Row)
+	pos, rowID := parser.Pos()
+	require.Equal(t, int64(12), pos)
+	require.Equal(t, int64(11), rowID)
+
+	// the row IDs are consecutive even if the handles aren't.
+	require.NoError(t, parser.ReadRow())
+	row = parser.LastRow()
+	require.Equal(t, int64(12), row.RowID)
+	require.True(t, row.Row[1].IsNull())
+	pos, _ = parser.Pos()
+	require.Equal(t, int64(16), pos)
+
+	require.Equal(t, io.EOF, parser.ReadRow())
+	require.Equal(t, io.EOF, parser.ReadRow())
+	require.NoError(t, parser.Close())
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.Len(t, s.conns, 1)
+}
+
+func TestParserWithoutHandle(t *testing.T) {
+	tbl := &upstreamTable{db: "db", name: "t", columns: []string{"a", "b"}, orderBy: []string{"a", "b"}}
+	s, mock := newTestSource(t, newTestConfig(), tbl)
+	mock.ExpectQuery("SELECT `a`,`b` FROM `db`.`t` ORDER BY `a`,`b` LIMIT 5,18446744073709551615").
+		WillReturnRows(sqlmock.NewRows([]string{"a", "b"}).AddRow("x", "y"))
+
+	parser, err := s.NewParser(context.Background(), mydump.SourceFileMeta{Path: tbl.path()}, mydump.TableFileSizeINF)
+	require.NoError(t, err)
+	require.NoError(t, parser.SetPos(5, 105))
+	require.NoError(t, parser.ReadRow())
+	pos, rowID := parser.Pos()
+	require.Equal(t, int64(6), pos)
+	require.Equal(t, int64(106), rowID)
+	require.Equal(t, io.EOF, parser.ReadRow())
+	require.Error(t, parser.SetPos(0, 0))
+	require.NoError(t, parser.Close())
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAttachTables(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.Routes = []*router.TableRule{
+		{SchemaPattern: "shard_*", TablePattern: "t_*", TargetSchema: "merged", TargetTable: "t"},
+	}
+	s, _ := newTestSource(t, cfg,
+		&upstreamTable{db: "shard_1", name: "t_1", dataLength: 100, handle: "id"},
+		&upstreamTable{db: "shard_2", name: "t_2", dataLength: 200, handle: "id"},
+		&upstreamTable{db: "db", name: "small", dataLength: 10, handle: "id"},
+		&upstreamTable{db: "db", name: "Big", dataLength: 1000},
+	)
+	dbMetas := []*mydump.MDDatabaseMeta{
+		{Name: "merged", Tables: []*mydump.MDTableMeta{{DB: "merged", Name: "t"}}},
+		{Name: "db", Tables: []*mydump.MDTableMeta{{DB: "db", Name: "big"}, {DB: "db", Name: "small"}}},
+	}
+	require.NoError(t, s.attachTables(dbMetas))
+
+	merged := dbMetas[0].Tables[0]
+	require.Len(t, merged.DataFiles, 2)
+	require.Equal(t, "`shard_1`.`t_1`", merged.DataFiles[0].FileMeta.Path)
+	require.Equal(t, mydump.SourceTypeDB, merged.DataFiles[0].FileMeta.Type)
+	require.Equal(t, "`shard_2`.`t_2`", merged.DataFiles[1].FileMeta.Path)
+	require.Equal(t, int64(300), merged.TotalSize)
+	require.False(t, merged.IsRowOrdered)
+
+	// the tables are sorted by size, and matched case-insensitively.
+	require.Equal(t, "small", dbMetas[1].Tables[0].Name)
+	require.True(t, dbMetas[1].Tables[0].IsRowOrdered)
+	require.Equal(t, "big", dbMetas[1].Tables[1].Name)
+	require.Equal(t, int64(1000), dbMetas[1].Tables[1].TotalSize)
+	require.False(t, dbMetas[1].Tables[1].IsRowOrdered)
+}
+
+func TestAttachTablesWithoutTarget(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.Routes = []*router.TableRule{
+		{SchemaPattern: "shard_*", TablePattern: "t_*", TargetSchema: "merged", TargetTable: "t"},
+	}
+	s, _ := newTestSource(t, cfg, &upstreamTable{db: "shard_1", name: "t_1", dataLength: 100, handle: "id"})
+	dbMetas := []*mydump.MDDatabaseMeta{
+		{Name: "db", Tables: []*mydump.MDTableMeta{{DB: "db", Name: "t"}}},
+	}
+	err := s.attachTables(dbMetas)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "table `shard_1`.`t_1` is routed to `merged`.`t`, which has no schema")
+}
+
+func TestCheckExplicitTables(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.Mydumper.Filter = []string{"db.T1", "db.t*", "!db.t3", "other.*"}
+	s, _ := newTestSource(t, cfg, &upstreamTable{db: "db", name: "t1"}, &upstreamTable{db: "db", name: "t2"})
+	require.NoError(t, s.checkExplicitTables())
+
+	cfg.Mydumper.Filter = append(cfg.Mydumper.Filter, "db.missing")
+	err := s.checkExplicitTables()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "table `db`.`missing` in the filter isn't found in the upstream database")
+
+	cfg.Mydumper.CaseSensitive = true
+	cfg.Mydumper.Filter = []string{"db.T1"}
+	require.Error(t, s.checkExplicitTables())
+}
@@ -0,0 +1,315 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbsource
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/lightning/common"
+	"github.com/pingcap/tidb/br/pkg/lightning/config"
+	"github.com/pingcap/tidb/br/pkg/lightning/mydump"
+	"github.com/pingcap/tidb/br/pkg/version"
+	tcontext "github.com/pingcap/tidb/dumpling/context"
+	"github.com/pingcap/tidb/dumpling/export"
+	"github.com/pingcap/tidb/parser/model"
+	"go.uber.org/zap"
+)
+
+// upstreamTable is a table in the upstream database.
+type upstreamTable struct {
+	db         string
+	name       string
+	dataLength int64
+	rowCount   int64
+
+	// columns are the columns to read, the generated columns are skipped.
+	columns []string
+	// handle is the integer column to split the table into chunks, it's empty if there isn't one,
+	// in which case the table is read as a whole chunk in the order of the primary key.
+	handle  string
+	orderBy []string
+}
+
+// path is the path of the data file of the table, it's used as the key of the chunk checkpoints.
+func (t *upstreamTable) path() string {
+	return common.UniqueTable(t.db, t.name)
+}
+
+// columnNames returns the lower-case names of the columns to read.
+func (t *upstreamTable) columnNames() []string {
+	names := make([]string, 0, len(t.columns))
+	for _, col := range t.columns {
+		names = append(names, strings.ToLower(col))
+	}
+	return names
+}
+
+func isIntegerType(dataType, columnType string) bool {
+	switch strings.ToLower(dataType) {
+	case "tinyint", "smallint", "mediumint", "int", "integer":
+		return true
+	case "bigint":
+		// the unsigned values beyond int64 can't be the offsets of the chunks.
+		return !strings.Contains(strings.ToLower(columnType), "unsigned")
+	default:
+		return false
+	}
+}
+
+// loadColumns loads the columns to read and picks the handle to split the table.
+func (t *upstreamTable) loadColumns(tctx *tcontext.Context, conn *export.BaseConn, serverType version.ServerType) error {
+	const query = "SELECT COLUMN_NAME, EXTRA, DATA_TYPE, COLUMN_TYPE FROM INFORMATION_SCHEMA.COLUMNS " +
+		"WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? ORDER BY ORDINAL_POSITION"
+	intColumns := make(map[string]struct{})
+	t.columns = t.columns[:0]
+	err := conn.QuerySQL(tctx, func(rows *sql.Rows) error {
+		var name, extra, dataType, columnType string
+		if err := rows.Scan(&name, &extra, &dataType, &columnType); err != nil {
+			return errors.Trace(err)
+		}
+		switch extra {
+		case "STORED GENERATED", "VIRTUAL GENERATED":
+			return nil
+		}
+		t.columns = append(t.columns, name)
+		if isIntegerType(dataType, columnType) {
+			intColumns[strings.ToLower(name)] = struct{}{}
+		}
+		return nil
+	}, func() {
+		t.columns = t.columns[:0]
+		intColumns = make(map[string]struct{})
+	}, query, t.db, t.name)
+	if err != nil {
+		return errors.Annotatef(err, "sql: %s", query)
+	}
+
+	t.handle, t.orderBy = "", nil
+	if serverType == version.ServerTypeTiDB {
+		hasRowID, err := export.SelectTiDBRowID(tctx, conn, t.db, t.name)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if hasRowID {
+			t.handle = model.ExtraHandleName.O
+			return nil
+		}
+	}
+	pkColumns, err := export.GetPrimaryKeyColumns(tctx, conn, t.db, t.name)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(pkColumns) == 1 {
+		if _, ok := intColumns[strings.ToLower(pkColumns[0])]; ok {
+			t.handle = pkColumns[0]
+			return nil
+		}
+	}
+	t.orderBy = pkColumns
+	return nil
+}
+
+// MakeTableRegions splits the upstream tables of the table into chunks. The offsets of a chunk are
+// the range of the handle, or the number of rows read if the table has no integer handle. The row IDs
+// of a chunk are allocated by the number of its rows.
+func (s *Source) MakeTableRegions(ctx context.Context, meta *mydump.MDTableMeta) ([]*mydump.TableRegion, error) {
+	conn, err := s.acquireConn(ctx)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer s.releaseConn(conn)
+
+	var (
+		regions   []*mydump.TableRegion
+		sizes     []float64
+		rowIDBase int64
+	)
+	for _, dataFile := range meta.DataFiles {
+		tbl, ok := s.tableMap[dataFile.FileMeta.Path]
+		if !ok {
+			return nil, errors.Errorf("table %s isn't found in the upstream database", dataFile.FileMeta.Path)
+		}
+		var fileRegions []*mydump.TableRegion
+		var fileSizes []float64
+		if tbl.handle != "" {
+			fileRegions, fileSizes, rowIDBase, err = s.splitByHandle(ctx, conn, meta, dataFile, tbl, rowIDBase)
+		} else {
+			fileRegions, fileSizes, rowIDBase, err = s.wholeTableRegion(ctx, conn, meta, dataFile, tbl, rowIDBase)
+		}
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		regions = append(regions, fileRegions...)
+		sizes = append(sizes, fileSizes...)
+	}
+
+	cfg := s.cfg
+	batchSize := float64(cfg.Mydumper.BatchSize)
+	if cfg.Mydumper.BatchSize <= 0 {
+		if meta.IsRowOrdered {
+			batchSize = float64(config.DefaultBatchSize)
+		} else {
+			batchSize = math.Max(float64(config.DefaultBatchSize), float64(meta.TotalSize))
+		}
+	}
+	s.logger.Info("makeTableRegions", zap.String("table", common.UniqueTable(meta.DB, meta.Name)),
+		zap.Int("RegionsCount", len(regions)), zap.Float64("BatchSize", batchSize))
+	mydump.AllocateEngineIDs(regions, sizes, batchSize, cfg.Mydumper.BatchImportRatio, float64(cfg.App.TableConcurrency))
+	return regions, nil
+}
+
+func (s *Source) splitByHandle(
+	ctx context.Context,
+	conn *sql.Conn,
+	meta *mydump.MDTableMeta,
+	dataFile mydump.FileInfo,
+	tbl *upstreamTable,
+	rowIDBase int64,
+) ([]*mydump.TableRegion, []float64, int64, error) {
+	handle := common.EscapeIdentifier(tbl.handle)
+	query := fmt.Sprintf("SELECT MIN(%s), MAX(%s) FROM %s", handle, handle, tbl.path())
+	var minHandle, maxHandle sql.NullInt64
+	if err := conn.QueryRowContext(ctx, query).Scan(&minHandle, &maxHandle); err != nil {
+		return nil, nil, rowIDBase, errors.Annotatef(err, "sql: %s", query)
+	}
+	if !minHandle.Valid || !maxHandle.Valid {
+		return nil, nil, rowIDBase, nil
+	}
+
+	// width may overflow to 0 if the handles cover the whole int64 range.
+	width := uint64(maxHandle.Int64-minHandle.Int64) + 1
+	if width == 0 {
+		width = math.MaxUint64
+	}
+	chunkCount := uint64(1)
+	if tbl.rowCount > s.cfg.SourceDB.ChunkRows {
+		chunkCount = uint64((tbl.rowCount + s.cfg.SourceDB.ChunkRows - 1) / s.cfg.SourceDB.ChunkRows)
+	}
+	step := width / chunkCount
+	if width%chunkCount != 0 {
+		step++
+	}
+
+	var (
+		regions []*mydump.TableRegion
+		sizes   []float64
+	)
+	for i, lo := uint64(0), minHandle.Int64; ; i++ {
+		fileMeta := dataFile.FileMeta
+		// endOffset is exclusive, except that math.MaxInt64 means no upper bound.
+		var endOffset int64
+		last := i+1 == chunkCount || uint64(maxHandle.Int64-lo) < step
+		if last {
+			endOffset = math.MaxInt64
+			if maxHandle.Int64 < math.MaxInt64 {
+				endOffset = maxHandle.Int64 + 1
+			}
+		} else {
+			endOffset = lo + int64(step)
+		}
+		// the row IDs are allocated by the number of rows instead of the span of the handles, which
+		// may be much larger if the handles are sparse.
+		count, err := s.countRows(ctx, conn, tbl, lo, endOffset)
+		if err != nil {
+			return nil, nil, rowIDBase, errors.Trace(err)
+		}
+		if count > math.MaxInt64-rowIDBase {
+			return nil, nil, rowIDBase, errors.Errorf("the row IDs of table %s overflow", tbl.path())
+		}
+		chunkWidth := uint64(endOffset - lo)
+		fileMeta.FileSize = int64(float64(tbl.dataLength) * float64(chunkWidth) / float64(width))
+		fileMeta.RealSize = fileMeta.FileSize
+		regions = append(regions, &mydump.TableRegion{
+			DB:       meta.DB,
+			Table:    meta.Name,
+			FileMeta: fileMeta,
+			Chunk: mydump.Chunk{
+				Offset:       lo,
+				EndOffset:    endOffset,
+				PrevRowIDMax: rowIDBase,
+				RowIDMax:     rowIDBase + count,
+				Columns:      tbl.columnNames(),
+			},
+		})
+		sizes = append(sizes, float64(fileMeta.FileSize))
+		rowIDBase += count
+		if last {
+			break
+		}
+		lo = endOffset
+	}
+	return regions, sizes, rowIDBase, nil
+}
+
+// countRows counts the rows whose handles are in [lo, hi), hi is math.MaxInt64 if there's no upper bound.
+// The rows are counted in the same consistent snapshot as they are read.
+func (s *Source) countRows(ctx context.Context, conn *sql.Conn, tbl *upstreamTable, lo, hi int64) (int64, error) {
+	handle := common.EscapeIdentifier(tbl.handle)
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s >= ?", tbl.path(), handle)
+	args := []interface{}{lo}
+	if hi < math.MaxInt64 {
+		query += fmt.Sprintf(" AND %s < ?", handle)
+		args = append(args, hi)
+	}
+	var count int64
+	if err := conn.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return 0, errors.Annotatef(err, "sql: %s", query)
+	}
+	return count, nil
+}
+
+func (s *Source) wholeTableRegion(
+	ctx context.Context,
+	conn *sql.Conn,
+	meta *mydump.MDTableMeta,
+	dataFile mydump.FileInfo,
+	tbl *upstreamTable,
+	rowIDBase int64,
+) ([]*mydump.TableRegion, []float64, int64, error) {
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s", tbl.path())
+	var count int64
+	if err := conn.QueryRowContext(ctx, query).Scan(&count); err != nil {
+		return nil, nil, rowIDBase, errors.Annotatef(err, "sql: %s", query)
+	}
+	if count == 0 {
+		return nil, nil, rowIDBase, nil
+	}
+	if count > math.MaxInt64-rowIDBase {
+		return nil, nil, rowIDBase, errors.Errorf("the row IDs of table %s overflow", tbl.path())
+	}
+	if len(tbl.orderBy) == 0 {
+		s.logger.Warn("the table has no primary key, the order of the rows may change when resuming from the checkpoints",
+			zap.String("table", tbl.path()))
+	}
+	region := &mydump.TableRegion{
+		DB:       meta.DB,
+		Table:    meta.Name,
+		FileMeta: dataFile.FileMeta,
+		Chunk: mydump.Chunk{
+			Offset: 0,
+			// the table is read until there are no more rows.
+			EndOffset:    mydump.TableFileSizeINF,
+			PrevRowIDMax: rowIDBase,
+			RowIDMax:     rowIDBase + count,
+			Columns:      tbl.columnNames(),
+		},
+	}
+	return []*mydump.TableRegion{region}, []float64{float64(dataFile.FileMeta.FileSize)}, region.Chunk.RowIDMax, nil
+}
@@ -15,6 +15,7 @@
 package errormanager
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"fmt"
@@ -113,6 +114,13 @@ const (
 		WHERE table_name = ? AND _tidb_rowid >= ? and _tidb_rowid < ?
 		ORDER BY _tidb_rowid LIMIT ?;
 	`
+
+	selectConflictRows = `
+		SELECT raw_handle, raw_row
+		FROM %s.` + conflictErrorTableName + `
+		WHERE task_id = ? AND table_name = ?
+		ORDER BY raw_handle, _tidb_rowid;
+	`
 )
 
 type ErrorManager struct {
@@ -411,6 +419,65 @@ func (em *ErrorManager) ResolveAllConflictKeys(
 	return errors.Trace(g.Wait())
 }
 
+// IterConflictRows iterates the distinct conflicting rows (handle and their values) of the table recorded
+// by the current task, in the order of the handles, and then in the order they were recorded. The rows are
+// passed to fn in batches of at most batchSize rows.
+func (em *ErrorManager) IterConflictRows(
+	ctx context.Context,
+	tableName string,
+	batchSize int,
+	fn func(ctx context.Context, handleRows [][2][]byte) error,
+) error {
+	if em.db == nil {
+		return nil
+	}
+
+	rows, err := em.db.QueryContext(ctx, fmt.Sprintf(selectConflictRows, em.schemaEscaped), em.taskID, tableName)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer rows.Close()
+
+	var (
+		handleRows [][2][]byte
+		lastHandle []byte
+		// seen contains the rows of lastHandle, a row may be recorded by several conflicting keys.
+		seen = make(map[string]struct{})
+	)
+	for rows.Next() {
+		var handleRow [2][]byte
+		if err := rows.Scan(&handleRow[0], &handleRow[1]); err != nil {
+			return errors.Trace(err)
+		}
+		// the row of an index conflict can't be found if it's deleted concurrently.
+		if len(handleRow[1]) == 0 {
+			continue
+		}
+		if !bytes.Equal(handleRow[0], lastHandle) {
+			lastHandle = handleRow[0]
+			seen = make(map[string]struct{})
+		}
+		if _, ok := seen[string(handleRow[1])]; ok {
+			continue
+		}
+		seen[string(handleRow[1])] = struct{}{}
+		handleRows = append(handleRows, handleRow)
+		if len(handleRows) >= batchSize {
+			if err := fn(ctx, handleRows); err != nil {
+				return errors.Trace(err)
+			}
+			handleRows = handleRows[:0]
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return errors.Trace(err)
+	}
+	if len(handleRows) > 0 {
+		return errors.Trace(fn(ctx, handleRows))
+	}
+	return nil
+}
+
 func (em *ErrorManager) errorCount(typeVal func(*config.MaxError) int64) int64 {
 	cfgVal := typeVal(em.configError)
 	val := typeVal(&em.remainingError)
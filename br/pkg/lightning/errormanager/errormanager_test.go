@@ -180,6 +180,45 @@ func TestResolveAllConflictKeys(t *testing.T) {
 	require.Equal(t, totalRows, resolved.Load())
 }
 
+func TestIterConflictRows(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	cfg := config.NewConfig()
+	cfg.TaskID = 42
+	cfg.TikvImporter.DuplicateResolution = config.DupeResAlgReplace
+	cfg.App.TaskInfoSchemaName = "lightning_errors"
+	em := New(db, cfg)
+
+	// the row of h1 is recorded by both the data conflict and the index conflict, and the row of an
+	// index conflict which can't be found is empty.
+	mock.ExpectQuery("SELECT raw_handle, raw_row FROM `lightning_errors`\\.conflict_error_v1.*ORDER BY raw_handle, _tidb_rowid").
+		WithArgs(int64(42), "`db`.`tbl`").
+		WillReturnRows(sqlmock.NewRows([]string{"raw_handle", "raw_row"}).
+			AddRow([]byte("h1"), []byte("r1")).
+			AddRow([]byte("h1"), []byte("r2")).
+			AddRow([]byte("h1"), []byte("r1")).
+			AddRow([]byte("h2"), []byte("")).
+			AddRow([]byte("h3"), []byte("r1")).
+			AddRow([]byte("h4"), []byte("r4")))
+
+	var batches [][][2][]byte
+	err = em.IterConflictRows(context.Background(), "`db`.`tbl`", 2,
+		func(ctx context.Context, handleRows [][2][]byte) error {
+			batch := make([][2][]byte, len(handleRows))
+			copy(batch, handleRows)
+			batches = append(batches, batch)
+			return nil
+		})
+	require.NoError(t, err)
+	require.Equal(t, [][][2][]byte{
+		{{[]byte("h1"), []byte("r1")}, {[]byte("h1"), []byte("r2")}},
+		{{[]byte("h3"), []byte("r1")}, {[]byte("h4"), []byte("r4")}},
+	}, batches)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestErrorMgrHasError(t *testing.T) {
 	cfg := &config.Config{}
 	cfg.App.MaxError = config.MaxError{
@@ -40,6 +40,7 @@ import (
 	"github.com/pingcap/tidb/br/pkg/lightning/checkpoints"
 	"github.com/pingcap/tidb/br/pkg/lightning/common"
 	"github.com/pingcap/tidb/br/pkg/lightning/config"
+	"github.com/pingcap/tidb/br/pkg/lightning/dbsource"
 	"github.com/pingcap/tidb/br/pkg/lightning/glue"
 	"github.com/pingcap/tidb/br/pkg/lightning/log"
 	"github.com/pingcap/tidb/br/pkg/lightning/mydump"
@@ -70,6 +71,7 @@ type Lightning struct {
 
 	cancelLock sync.Mutex
 	curTask    *config.Config
+	curTables  *restore.TableControls
 	cancel     context.CancelFunc // for per task context, which maybe different from lightning context
 }
 
@@ -152,6 +154,7 @@ func (l *Lightning) goServe(statusAddr string, realAddrWriter io.Writer) error {
 	mux.HandleFunc("/pause", handlePause)
 	mux.HandleFunc("/resume", handleResume)
 	mux.HandleFunc("/loglevel", handleLogLevel)
+	mux.Handle("/api/v1/", http.StripPrefix("/api/v1", http.HandlerFunc(l.handleAPIV1)))
 
 	mux.Handle("/web/", http.StripPrefix("/web", httpgzip.FileServer(web.Res, httpgzip.FileServerOptions{
 		IndexHTML: true,
@@ -286,9 +289,12 @@ func (l *Lightning) run(taskCtx context.Context, taskCfg *config.Config, o *opti
 	utils.LogEnvVariables()
 
 	ctx, cancel := context.WithCancel(taskCtx)
+	// in the server mode, the task waits for the failed tables to be retried by the HTTP API.
+	tableControls := restore.NewTableControls(l.globalCfg.App.ServerMode)
 	l.cancelLock.Lock()
 	l.cancel = cancel
 	l.curTask = taskCfg
+	l.curTables = tableControls
 	l.cancelLock.Unlock()
 	web.BroadcastStartTask()
 
@@ -339,51 +345,41 @@ func (l *Lightning) run(taskCtx context.Context, taskCfg *config.Config, o *opti
 		g = glue.NewExternalTiDBGlue(db, taskCfg.TiDB.SQLMode)
 	}
 
-	s := o.dumpFileStorage
-	if s == nil {
-		u, err := storage.ParseBackend(taskCfg.Mydumper.SourceDir, nil)
-		if err != nil {
-			return common.NormalizeError(err)
+	var (
+		s        storage.ExternalStorage
+		dbMetas  []*mydump.MDDatabaseMeta
+		sourceDB *dbsource.Source
+	)
+	if taskCfg.HasSourceDB() {
+		loadTask := log.L().Begin(zap.InfoLevel, "load source database")
+		sourceDB, err = dbsource.Open(ctx, taskCfg)
+		if err == nil {
+			defer sourceDB.Close()
+			// the schema files are generated into a storage in memory, and the data is read from the database.
+			dbMetas, s, err = sourceDB.Load(ctx)
 		}
-		s, err = storage.New(ctx, u, &storage.ExternalStorageOptions{})
+		loadTask.End(zap.ErrorLevel, err)
 		if err != nil {
-			return common.NormalizeError(err)
+			return errors.Trace(err)
 		}
-	}
-
-	// return expectedErr means at least meet one file
-	expectedErr := errors.New("Stop Iter")
-	walkErr := s.WalkDir(ctx, &storage.WalkOption{ListCount: 1}, func(string, int64) error {
-		// return an error when meet the first regular file to break the walk loop
-		return expectedErr
-	})
-	if !errors.ErrorEqual(walkErr, expectedErr) {
-		if walkErr == nil {
-			return common.ErrEmptySourceDir.GenWithStackByArgs(taskCfg.Mydumper.SourceDir)
+	} else {
+		s, dbMetas, err = loadDumpFiles(ctx, taskCfg, o.dumpFileStorage)
+		if err != nil {
+			return err
 		}
-		return common.NormalizeOrWrapErr(common.ErrStorageUnknown, walkErr)
 	}
-
-	loadTask := log.L().Begin(zap.InfoLevel, "load data source")
-	var mdl *mydump.MDLoader
-	mdl, err = mydump.NewMyDumpLoaderWithStore(ctx, taskCfg, s)
-	loadTask.End(zap.ErrorLevel, err)
-	if err != nil {
-		return errors.Trace(err)
-	}
-	err = checkSystemRequirement(taskCfg, mdl.GetDatabases())
+	err = checkSystemRequirement(taskCfg, dbMetas)
 	if err != nil {
 		log.L().Error("check system requirements failed", zap.Error(err))
 		return common.ErrSystemRequirementNotMet.Wrap(err).GenWithStackByArgs()
 	}
 	// check table schema conflicts
-	err = checkSchemaConflict(taskCfg, mdl.GetDatabases())
+	err = checkSchemaConflict(taskCfg, dbMetas)
 	if err != nil {
 		log.L().Error("checkpoint schema conflicts with data files", zap.Error(err))
 		return errors.Trace(err)
 	}
 
-	dbMetas := mdl.GetDatabases()
 	web.BroadcastInitProgress(dbMetas)
 
 	var procedure *restore.Controller
@@ -392,10 +388,12 @@ func (l *Lightning) run(taskCtx context.Context, taskCfg *config.Config, o *opti
 		DBMetas:           dbMetas,
 		Status:            &l.status,
 		DumpFileStorage:   s,
-		OwnExtStorage:     o.dumpFileStorage == nil,
+		OwnExtStorage:     o.dumpFileStorage == nil && sourceDB == nil,
 		Glue:              g,
 		CheckpointStorage: o.checkpointStorage,
 		CheckpointName:    o.checkpointName,
+		SourceDB:          sourceDB,
+		TableControls:     tableControls,
 	}
 
 	procedure, err = restore.NewRestoreController(ctx, taskCfg, param)
@@ -409,6 +407,45 @@ func (l *Lightning) run(taskCtx context.Context, taskCfg *config.Config, o *opti
 	return errors.Trace(err)
 }
 
+// loadDumpFiles opens the storage of the data source directory if it's not given, and loads the dump files in it.
+func loadDumpFiles(
+	ctx context.Context,
+	taskCfg *config.Config,
+	s storage.ExternalStorage,
+) (storage.ExternalStorage, []*mydump.MDDatabaseMeta, error) {
+	if s == nil {
+		u, err := storage.ParseBackend(taskCfg.Mydumper.SourceDir, nil)
+		if err != nil {
+			return nil, nil, common.NormalizeError(err)
+		}
+		s, err = storage.New(ctx, u, &storage.ExternalStorageOptions{})
+		if err != nil {
+			return nil, nil, common.NormalizeError(err)
+		}
+	}
+
+	// return expectedErr means at least meet one file
+	expectedErr := errors.New("Stop Iter")
+	walkErr := s.WalkDir(ctx, &storage.WalkOption{ListCount: 1}, func(string, int64) error {
+		// return an error when meet the first regular file to break the walk loop
+		return expectedErr
+	})
+	if !errors.ErrorEqual(walkErr, expectedErr) {
+		if walkErr == nil {
+			return nil, nil, common.ErrEmptySourceDir.GenWithStackByArgs(taskCfg.Mydumper.SourceDir)
+		}
+		return nil, nil, common.NormalizeOrWrapErr(common.ErrStorageUnknown, walkErr)
+	}
+
+	loadTask := log.L().Begin(zap.InfoLevel, "load data source")
+	mdl, err := mydump.NewMyDumpLoaderWithStore(ctx, taskCfg, s)
+	loadTask.End(zap.ErrorLevel, err)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	return s, mdl.GetDatabases(), nil
+}
+
 func (l *Lightning) Stop() {
 	l.cancelLock.Lock()
 	if l.cancel != nil {
@@ -29,7 +29,11 @@ import (
 	"time"
 
 	"github.com/pingcap/failpoint"
+	"github.com/pingcap/tidb/br/pkg/lightning/checkpoints"
+	"github.com/pingcap/tidb/br/pkg/lightning/common"
 	"github.com/pingcap/tidb/br/pkg/lightning/config"
+	"github.com/pingcap/tidb/br/pkg/lightning/mydump"
+	"github.com/pingcap/tidb/br/pkg/lightning/verification"
 	"github.com/pingcap/tidb/br/pkg/lightning/web"
 	"github.com/stretchr/testify/require"
 )
@@ -379,3 +383,96 @@ func TestHTTPAPIOutsideServerMode(t *testing.T) {
 	// ... and the task should be canceled now.
 	require.Equal(t, context.Canceled, <-errCh)
 }
+
+func TestTablesAPI(t *testing.T) {
+	s, clean := createSuite(t)
+	defer clean()
+
+	web.BroadcastInitProgress([]*mydump.MDDatabaseMeta{{
+		Name: "db",
+		Tables: []*mydump.MDTableMeta{
+			{DB: "db", Name: "t2", TotalSize: 200},
+			{DB: "db", Name: "t/1", TotalSize: 100},
+		},
+	}})
+	web.BroadcastTableCheckpoint(common.UniqueTable("db", "t/1"), &checkpoints.TableCheckpoint{
+		Status: checkpoints.CheckpointStatusLoaded,
+		Engines: map[int32]*checkpoints.EngineCheckpoint{
+			-1: {Status: checkpoints.CheckpointStatusLoaded},
+			0: {
+				Status: checkpoints.CheckpointStatusLoaded,
+				Chunks: []*checkpoints.ChunkCheckpoint{{
+					Key:      checkpoints.ChunkCheckpointKey{Path: "db.t1.sql", Offset: 0},
+					Chunk:    mydump.Chunk{Offset: 40, EndOffset: 100},
+					Checksum: verification.MakeKVChecksum(40, 6, 0),
+				}},
+			},
+		},
+	}, 2)
+
+	url := "http://" + s.lightning.serverAddr.String() + "/api/v1/tables"
+
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var tables struct {
+		Tables []struct {
+			Name         string `json:"name"`
+			State        string `json:"state"`
+			WrittenBytes int64  `json:"written_bytes"`
+			TotalBytes   int64  `json:"total_bytes"`
+			Rows         int64  `json:"rows"`
+		} `json:"tables"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&tables))
+	require.NoError(t, resp.Body.Close())
+	require.Len(t, tables.Tables, 2)
+	require.Equal(t, "`db`.`t/1`", tables.Tables[0].Name)
+	require.Equal(t, "running", tables.Tables[0].State)
+	require.Equal(t, int64(40), tables.Tables[0].WrittenBytes)
+	require.Equal(t, int64(100), tables.Tables[0].TotalBytes)
+	require.Equal(t, int64(3), tables.Tables[0].Rows)
+	require.Equal(t, "`db`.`t2`", tables.Tables[1].Name)
+	require.Equal(t, "pending", tables.Tables[1].State)
+	require.Equal(t, int64(200), tables.Tables[1].TotalBytes)
+
+	resp, err = http.Get(url + "/db/t%2F1")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var table struct {
+		Status  string `json:"status"`
+		Engines []struct {
+			ID     int32 `json:"id"`
+			Chunks []struct {
+				Path string `json:"path"`
+				Pos  int64  `json:"pos"`
+			} `json:"chunks"`
+		} `json:"engines"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&table))
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, "pending", table.Status)
+	require.Len(t, table.Engines, 2)
+	require.Equal(t, int32(-1), table.Engines[0].ID)
+	require.Len(t, table.Engines[0].Chunks, 0)
+	require.Equal(t, int32(0), table.Engines[1].ID)
+	require.Len(t, table.Engines[1].Chunks, 1)
+	require.Equal(t, "db.t1.sql", table.Engines[1].Chunks[0].Path)
+	require.Equal(t, int64(40), table.Engines[1].Chunks[0].Pos)
+
+	resp, err = http.Get(url + "/db/t3")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	// the actions are only allowed for the tables scheduled in the current task.
+	resp, err = http.Get(url + "/db/t2/pause")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	resp, err = http.Post(url+"/db/t2/pause", "application/json", nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+}
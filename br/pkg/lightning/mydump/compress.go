@@ -0,0 +1,170 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mydump
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/storage"
+	"github.com/ulikunitz/xz"
+)
+
+const (
+	// CompressSizeFactor is used to amplify the estimated uncompressed size of a compressed file
+	// when computing the row ID range, since the compress ratio of the sampled head of the file
+	// may be smaller than the whole file.
+	CompressSizeFactor = 5
+	// TableFileSizeINF is the end offset of the chunk of a compressed file. The size of the
+	// uncompressed data is unknown, so the chunk is read until EOF.
+	TableFileSizeINF = 1024 * tableRegionSizeWarningThreshold
+
+	// sampleCompressedFileSize is the size of the uncompressed data read to sample the compress ratio.
+	sampleCompressedFileSize = 4 * 1024 * 1024
+	// sampleCompressRatioConcurrency is the number of the compressed files sampled concurrently.
+	sampleCompressRatioConcurrency = 8
+)
+
+// newDecompressor creates a reader which decompresses the data of r.
+// The returned reader should be closed if it implements io.Closer.
+func newDecompressor(compression Compression, r io.Reader) (io.Reader, error) {
+	switch compression {
+	case CompressionNone:
+		return r, nil
+	case CompressionGZ:
+		return gzip.NewReader(r)
+	case CompressionLZ4:
+		return lz4.NewReader(r), nil
+	case CompressionZStd:
+		decoder, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return decoder.IOReadCloser(), nil
+	case CompressionXZ:
+		return xz.NewReader(r)
+	case CompressionSnappy:
+		return snappy.NewReader(r), nil
+	default:
+		return nil, errors.Errorf("unsupported compression type %d", compression)
+	}
+}
+
+// compressedReader decompresses a source file in a streaming way. It can't seek backward,
+// and seeking forward is done by discarding the decompressed data, so the position is the
+// offset in the uncompressed data.
+type compressedReader struct {
+	io.Reader
+	file storage.ReadSeekCloser
+	pos  int64
+}
+
+func (r *compressedReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.pos += int64(n)
+	return n, err
+}
+
+func (r *compressedReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.pos
+	default:
+		return r.pos, errors.Errorf("compressed file doesn't support seeking from the end")
+	}
+	if offset < r.pos {
+		return r.pos, errors.Errorf("compressed file doesn't support seeking backward, current: %d, required: %d", r.pos, offset)
+	}
+	if offset > r.pos {
+		if _, err := io.CopyN(io.Discard, r, offset-r.pos); err != nil {
+			return r.pos, errors.Trace(err)
+		}
+	}
+	return r.pos, nil
+}
+
+func (r *compressedReader) Close() error {
+	var err error
+	if closer, ok := r.Reader.(io.Closer); ok {
+		err = closer.Close()
+	}
+	if err1 := r.file.Close(); err == nil {
+		err = err1
+	}
+	return errors.Trace(err)
+}
+
+// OpenReader opens a source file, the data of a compressed file is decompressed on reading.
+func OpenReader(ctx context.Context, fileMeta SourceFileMeta, store storage.ExternalStorage) (storage.ReadSeekCloser, error) {
+	file, err := store.Open(ctx, fileMeta.Path)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if fileMeta.Compression == CompressionNone {
+		return file, nil
+	}
+	decompressor, err := newDecompressor(fileMeta.Compression, file)
+	if err != nil {
+		_ = file.Close()
+		return nil, errors.Annotatef(err, "failed to decompress file '%s'", fileMeta.Path)
+	}
+	return &compressedReader{Reader: decompressor, file: file}, nil
+}
+
+// countingReader counts the bytes read from the underlying reader.
+type countingReader struct {
+	io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// SampleFileCompressRatio samples the compress ratio of a compressed file by decompressing its head.
+func SampleFileCompressRatio(ctx context.Context, fileMeta SourceFileMeta, store storage.ExternalStorage) (float64, error) {
+	if fileMeta.Compression == CompressionNone {
+		return 1, nil
+	}
+	file, err := store.Open(ctx, fileMeta.Path)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	defer file.Close()
+	counter := &countingReader{Reader: file}
+	decompressor, err := newDecompressor(fileMeta.Compression, counter)
+	if err != nil {
+		return 0, errors.Annotatef(err, "failed to decompress file '%s'", fileMeta.Path)
+	}
+	if closer, ok := decompressor.(io.Closer); ok {
+		defer closer.Close()
+	}
+	n, err := io.CopyN(io.Discard, decompressor, sampleCompressedFileSize)
+	if err != nil && err != io.EOF {
+		return 0, errors.Annotatef(err, "failed to decompress file '%s'", fileMeta.Path)
+	}
+	if counter.n == 0 {
+		return 1, nil
+	}
+	return float64(n) / float64(counter.n), nil
+}
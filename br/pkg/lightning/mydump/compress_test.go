@@ -0,0 +1,192 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mydump_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4"
+	. "github.com/pingcap/tidb/br/pkg/lightning/mydump"
+	"github.com/pingcap/tidb/br/pkg/storage"
+	"github.com/stretchr/testify/require"
+	"github.com/ulikunitz/xz"
+)
+
+func compressData(t *testing.T, compression Compression, data []byte) []byte {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	var err error
+	switch compression {
+	case CompressionGZ:
+		w = gzip.NewWriter(&buf)
+	case CompressionLZ4:
+		w = lz4.NewWriter(&buf)
+	case CompressionZStd:
+		w, err = zstd.NewWriter(&buf)
+	case CompressionXZ:
+		w, err = xz.NewWriter(&buf)
+	case CompressionSnappy:
+		w = snappy.NewBufferedWriter(&buf)
+	default:
+		t.Fatalf("unexpected compression %d", compression)
+	}
+	require.NoError(t, err)
+	_, err = w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func writeCompressedFile(t *testing.T, dir, name string, compression Compression, data []byte) SourceFileMeta {
+	compressed := compressData(t, compression, data)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), compressed, 0o644))
+	return SourceFileMeta{
+		Path:        name,
+		Type:        SourceTypeCSV,
+		Compression: compression,
+		FileSize:    int64(len(compressed)),
+	}
+}
+
+func TestOpenCompressedReader(t *testing.T) {
+	dir := t.TempDir()
+	store, err := storage.NewLocalStorage(dir)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	data := []byte(strings.Repeat("1,\"abc\",2.5\n", 1000))
+	for name, compression := range map[string]Compression{
+		"t.csv.gz":     CompressionGZ,
+		"t.csv.lz4":    CompressionLZ4,
+		"t.csv.zstd":   CompressionZStd,
+		"t.csv.xz":     CompressionXZ,
+		"t.csv.snappy": CompressionSnappy,
+	} {
+		fileMeta := writeCompressedFile(t, dir, name, compression, data)
+
+		r, err := OpenReader(ctx, fileMeta, store)
+		require.NoError(t, err)
+		content, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, data, content, name)
+		require.NoError(t, r.Close())
+
+		// a compressed file can only seek forward.
+		r, err = OpenReader(ctx, fileMeta, store)
+		require.NoError(t, err)
+		pos, err := r.Seek(12, io.SeekStart)
+		require.NoError(t, err)
+		require.Equal(t, int64(12), pos)
+		pos, err = r.Seek(12, io.SeekCurrent)
+		require.NoError(t, err)
+		require.Equal(t, int64(24), pos)
+		buf := make([]byte, 12)
+		_, err = io.ReadFull(r, buf)
+		require.NoError(t, err)
+		require.Equal(t, data[24:36], buf)
+		_, err = r.Seek(0, io.SeekStart)
+		require.Error(t, err)
+		_, err = r.Seek(0, io.SeekEnd)
+		require.Error(t, err)
+		require.NoError(t, r.Close())
+
+		ratio, err := SampleFileCompressRatio(ctx, fileMeta, store)
+		require.NoError(t, err)
+		require.InDelta(t, float64(len(data))/float64(fileMeta.FileSize), ratio, 1e-6, name)
+	}
+}
+
+func TestOpenCorruptedCompressedFile(t *testing.T) {
+	dir := t.TempDir()
+	store, err := storage.NewLocalStorage(dir)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "t.csv.gz"), []byte("not a gzip file"), 0o644))
+
+	_, err = OpenReader(context.Background(), SourceFileMeta{Path: "t.csv.gz", Compression: CompressionGZ}, store)
+	require.Error(t, err)
+}
+
+func TestOpenStorageCompressedFile(t *testing.T) {
+	dir := t.TempDir()
+	store, err := storage.NewLocalStorage(dir)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// the files compressed by dumpling can be read by lightning.
+	data := []byte("INSERT INTO `t` VALUES\n(1,'a'),\n(2,'b');\n")
+	for _, tc := range []struct {
+		compressType storage.CompressType
+		compression  Compression
+		name         string
+	}{
+		{storage.Gzip, CompressionGZ, "db.t.000000000.sql.gz"},
+		{storage.Snappy, CompressionSnappy, "db.t.000000000.sql.snappy"},
+		{storage.Zstd, CompressionZStd, "db.t.000000000.sql.zst"},
+	} {
+		writer, err := storage.WithCompression(store, tc.compressType).Create(ctx, tc.name)
+		require.NoError(t, err)
+		_, err = writer.Write(ctx, data)
+		require.NoError(t, err)
+		require.NoError(t, writer.Close(ctx))
+
+		r, err := OpenReader(ctx, SourceFileMeta{Path: tc.name, Compression: tc.compression}, store)
+		require.NoError(t, err)
+		content, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, data, content)
+		require.NoError(t, r.Close())
+	}
+}
+
+func TestLoaderSampleCompressRatioPerTable(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "db-schema-create.sql"), []byte("CREATE DATABASE db;"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "db.t-schema.sql"), []byte("CREATE TABLE t(a int);"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "db.t2-schema.sql"), []byte("CREATE TABLE t2(a int);"), 0o644))
+
+	data1 := []byte(strings.Repeat("1,aaaaaaaaaa\n", 1000))
+	data2 := []byte(strings.Repeat("2,bbbbbbbbbbbbbbbbbbbb\n", 3000))
+	meta1 := writeCompressedFile(t, dir, "db.t.1.csv.gz", CompressionGZ, data1)
+	meta2 := writeCompressedFile(t, dir, "db.t.2.csv.gz", CompressionGZ, data2)
+	writeCompressedFile(t, dir, "db.t2.1.csv.zst", CompressionZStd, data2)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "db.t2.2.csv"), data1, 0o644))
+
+	cfg := newConfigWithSourceDir(dir)
+	loader, err := NewMyDumpLoader(context.Background(), cfg)
+	require.NoError(t, err)
+	dbs := loader.GetDatabases()
+	require.Len(t, dbs, 1)
+	sizes := make(map[string]int64)
+	for _, tbl := range dbs[0].Tables {
+		for _, f := range tbl.DataFiles {
+			sizes[f.FileMeta.Path] = f.FileMeta.RealSize
+		}
+	}
+	// only the first file of the table is sampled, the other files use its compress ratio
+	ratio := float64(len(data1)) / float64(meta1.FileSize)
+	require.Equal(t, int64(len(data1)), sizes["db.t.1.csv.gz"])
+	require.Equal(t, int64(ratio*float64(meta2.FileSize)), sizes["db.t.2.csv.gz"])
+	require.Equal(t, int64(len(data2)), sizes["db.t2.1.csv.zst"])
+	require.Equal(t, int64(len(data1)), sizes["db.t2.2.csv"])
+}
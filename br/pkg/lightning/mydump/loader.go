@@ -19,15 +19,18 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/pingcap/errors"
 	"github.com/pingcap/tidb/br/pkg/lightning/common"
 	"github.com/pingcap/tidb/br/pkg/lightning/config"
 	"github.com/pingcap/tidb/br/pkg/lightning/log"
 	"github.com/pingcap/tidb/br/pkg/storage"
+	"github.com/pingcap/tidb/br/pkg/utils"
 	regexprrouter "github.com/pingcap/tidb/util/regexpr-router"
 	filter "github.com/pingcap/tidb/util/table-filter"
 	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
 )
 
 type MDDatabaseMeta struct {
@@ -73,6 +76,9 @@ type SourceFileMeta struct {
 	Compression Compression
 	SortKey     string
 	FileSize    int64
+	// RealSize is the estimated size of the uncompressed data, it's the same as FileSize
+	// for the files not compressed.
+	RealSize int64
 }
 
 func (m *MDTableMeta) GetSchema(ctx context.Context, store storage.ExternalStorage) (string, error) {
@@ -272,7 +278,7 @@ func (s *mdLoaderSetup) setup(ctx context.Context, store storage.ExternalStorage
 		// set a dummy `FileInfo` here without file meta because we needn't restore the table schema
 		tableMeta, _, _ := s.insertTable(FileInfo{TableName: fileInfo.TableName})
 		tableMeta.DataFiles = append(tableMeta.DataFiles, fileInfo)
-		tableMeta.TotalSize += fileInfo.FileMeta.FileSize
+		tableMeta.TotalSize += fileInfo.FileMeta.RealSize
 	}
 
 	for _, dbMeta := range s.loader.dbs {
@@ -313,7 +319,7 @@ func (s *mdLoaderSetup) listFiles(ctx context.Context, store storage.ExternalSto
 
 		info := FileInfo{
 			TableName: filter.Table{Schema: res.Schema, Name: res.Name},
-			FileMeta:  SourceFileMeta{Path: path, Type: res.Type, Compression: res.Compression, SortKey: res.Key, FileSize: size},
+			FileMeta:  SourceFileMeta{Path: path, Type: res.Type, Compression: res.Compression, SortKey: res.Key, FileSize: size, RealSize: size},
 		}
 
 		if s.loader.shouldSkip(&info.TableName) {
@@ -338,8 +344,79 @@ func (s *mdLoaderSetup) listFiles(ctx context.Context, store storage.ExternalSto
 
 		return nil
 	})
+	if err != nil {
+		return errors.Trace(err)
+	}
 
-	return errors.Trace(err)
+	return errors.Trace(s.estimateRealSizes(ctx, store))
+}
+
+// estimateRealSizes estimates the uncompressed sizes of the compressed data files. The files of a table
+// are usually compressed in the same way, so only the first file of each table and compression type is
+// sampled, and its compression ratio is used for the other files.
+func (s *mdLoaderSetup) estimateRealSizes(ctx context.Context, store storage.ExternalStorage) error {
+	type sampleKey struct {
+		table       filter.Table
+		compression Compression
+	}
+	sampleIdx := make(map[sampleKey]int)
+	var samples []int
+	for i, info := range s.tableDatas {
+		if info.FileMeta.Compression == CompressionNone {
+			continue
+		}
+		key := sampleKey{table: info.TableName, compression: info.FileMeta.Compression}
+		if _, ok := sampleIdx[key]; !ok {
+			sampleIdx[key] = i
+			samples = append(samples, i)
+		}
+	}
+	if len(samples) == 0 {
+		return nil
+	}
+
+	ratios := make(map[int]float64, len(samples))
+	var mu sync.Mutex
+	eg, egCtx := errgroup.WithContext(ctx)
+	idxCh := make(chan int)
+	for i := 0; i < utils.MinInt(sampleCompressRatioConcurrency, len(samples)); i++ {
+		eg.Go(func() error {
+			for idx := range idxCh {
+				ratio, err := SampleFileCompressRatio(egCtx, s.tableDatas[idx].FileMeta, store)
+				if err != nil {
+					return errors.Trace(err)
+				}
+				mu.Lock()
+				ratios[idx] = ratio
+				mu.Unlock()
+			}
+			return nil
+		})
+	}
+	eg.Go(func() error {
+		defer close(idxCh)
+		for _, idx := range samples {
+			select {
+			case idxCh <- idx:
+			case <-egCtx.Done():
+				return nil
+			}
+		}
+		return nil
+	})
+	if err := eg.Wait(); err != nil {
+		return err
+	}
+
+	for i := range s.tableDatas {
+		meta := &s.tableDatas[i].FileMeta
+		if meta.Compression == CompressionNone {
+			continue
+		}
+		ratio := ratios[sampleIdx[sampleKey{table: s.tableDatas[i].TableName, compression: meta.Compression}]]
+		meta.RealSize = int64(ratio * float64(meta.FileSize))
+	}
+	return nil
 }
 
 func (l *MDLoader) shouldSkip(table *filter.Table) bool {
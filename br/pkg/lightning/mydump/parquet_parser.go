@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"math"
 	"math/big"
 	"reflect"
 	"strings"
@@ -28,6 +29,20 @@ const (
 	smallParquetFileThreshold = 256 * 1024 * 1024
 )
 
+// maxCompressedParquetSize is the max decompressed size of a compressed parquet file. A parquet file is
+// read randomly, so a compressed one is decompressed into memory as a whole, and the larger files must be
+// decompressed before importing.
+var maxCompressedParquetSize int64 = smallParquetFileThreshold
+
+const (
+	// ParquetZeroDate is the sentinel of the zero date "0000-00-00" in a parquet DATE column, which
+	// counts the days since the unix epoch. The value is out of the range of the valid MySQL dates.
+	ParquetZeroDate int32 = math.MinInt32
+	// ParquetZeroDatetime is the sentinel of the zero datetime "0000-00-00 00:00:00" in a parquet
+	// TIMESTAMP column. The value is out of the range of the valid MySQL datetimes in any time unit.
+	ParquetZeroDatetime int64 = math.MinInt64
+)
+
 type ParquetParser struct {
 	Reader      *preader.ParquetReader
 	columns     []string
@@ -107,10 +122,32 @@ func (r *bytesReaderWrapper) Open(name string) (source.ParquetFile, error) {
 func OpenParquetReader(
 	ctx context.Context,
 	store storage.ExternalStorage,
-	path string,
-	size int64,
+	fileMeta SourceFileMeta,
 ) (source.ParquetFile, error) {
-	if size <= smallParquetFileThreshold {
+	path := fileMeta.Path
+	// a compressed parquet file can't be read randomly, so we decompress the whole file into memory.
+	if fileMeta.Compression != CompressionNone {
+		r, err := OpenReader(ctx, fileMeta, store)
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		fileBytes, err := io.ReadAll(io.LimitReader(r, maxCompressedParquetSize+1))
+		if err != nil {
+			return nil, errors.Annotatef(err, "failed to decompress parquet file '%s'", path)
+		}
+		if int64(len(fileBytes)) > maxCompressedParquetSize {
+			return nil, errors.Errorf("the decompressed size of parquet file '%s' exceeds %d bytes, please decompress it before importing",
+				path, maxCompressedParquetSize)
+		}
+		return &bytesReaderWrapper{
+			Reader:   bytes.NewReader(fileBytes),
+			rawBytes: fileBytes,
+			path:     path,
+		}, nil
+	}
+
+	if fileMeta.FileSize <= smallParquetFileThreshold {
 		fileBytes, err := store.ReadFile(ctx, path)
 		if err != nil {
 			return nil, err
@@ -141,11 +178,15 @@ func ReadParquetFileRowCount(
 	r storage.ReadSeekCloser,
 	path string,
 ) (int64, error) {
-	wrapper := &readerWrapper{
-		ReadSeekCloser: r,
-		store:          store,
-		ctx:            ctx,
-		path:           path,
+	// check to avoid wrapping twice
+	wrapper, ok := r.(source.ParquetFile)
+	if !ok {
+		wrapper = &readerWrapper{
+			ReadSeekCloser: r,
+			store:          store,
+			ctx:            ctx,
+			path:           path,
+		}
 	}
 	var err error
 	res := new(preader.ParquetReader)
@@ -490,9 +531,17 @@ func setDatumByInt(d *types.Datum, v int64, meta *parquet.SchemaElement) error {
 		dotIndex := len(val) - int(*meta.Scale)
 		d.SetString(val[:dotIndex]+"."+val[dotIndex:], "")
 	case logicalType.DATE != nil:
-		dateStr := time.Unix(v*86400, 0).Format("2006-01-02")
+		if v == int64(ParquetZeroDate) {
+			d.SetString("0000-00-00", "")
+			return nil
+		}
+		dateStr := time.Unix(v*86400, 0).UTC().Format("2006-01-02")
 		d.SetString(dateStr, "")
 	case logicalType.TIMESTAMP != nil:
+		if v == ParquetZeroDatetime {
+			d.SetString("0000-00-00 00:00:00", "")
+			return nil
+		}
 		// convert all timestamp types (datetime/timestamp) to string
 		timeStr := formatTime(v, logicalType.TIMESTAMP.Unit, "2006-01-02 15:04:05.999999",
 			"2006-01-02 15:04:05.999999Z", logicalType.TIMESTAMP.IsAdjustedToUTC)
@@ -1,8 +1,11 @@
 package mydump
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"io"
+	"os"
 	"path/filepath"
 	"strconv"
 	"testing"
@@ -254,3 +257,31 @@ func TestParquetAurora(t *testing.T) {
 
 	require.ErrorIs(t, parser.ReadRow(), io.EOF)
 }
+
+func TestOpenCompressedParquetSizeLimit(t *testing.T) {
+	dir := t.TempDir()
+	store, err := storage.NewLocalStorage(dir)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	_, err = gw.Write(make([]byte, 1024))
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "t.parquet.gz"), buf.Bytes(), 0o644))
+	fileMeta := SourceFileMeta{Path: "t.parquet.gz", Type: SourceTypeParquet, Compression: CompressionGZ, FileSize: int64(buf.Len())}
+
+	originSize := maxCompressedParquetSize
+	maxCompressedParquetSize = 1023
+	defer func() {
+		maxCompressedParquetSize = originSize
+	}()
+	// the highly compressible file is decompressed into memory, so its decompressed size is limited.
+	_, err = OpenParquetReader(context.Background(), store, fileMeta)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "the decompressed size of parquet file 't.parquet.gz' exceeds 1023 bytes")
+
+	maxCompressedParquetSize = 1024
+	r, err := OpenParquetReader(context.Background(), store, fileMeta)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+}
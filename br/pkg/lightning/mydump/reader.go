@@ -68,7 +68,7 @@ func decodeCharacterSet(data []byte, characterSet string) ([]byte, error) {
 }
 
 func ExportStatement(ctx context.Context, store storage.ExternalStorage, sqlFile FileInfo, characterSet string) ([]byte, error) {
-	fd, err := store.Open(ctx, sqlFile.FileMeta.Path)
+	fd, err := OpenReader(ctx, sqlFile.FileMeta, store)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
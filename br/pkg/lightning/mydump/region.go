@@ -271,6 +271,22 @@ func makeSourceFileRegion(
 	if !isCsvFile {
 		divisor += 2
 	}
+	// a compressed file can't be split since we can't seek in it, so it's read until EOF as a whole chunk.
+	// Its row IDs are estimated by the compression ratio, and the encoder fails if the rows exceed them.
+	if fi.FileMeta.Compression != CompressionNone {
+		tableRegion := &TableRegion{
+			DB:       meta.DB,
+			Table:    meta.Name,
+			FileMeta: fi.FileMeta,
+			Chunk: Chunk{
+				Offset:       0,
+				EndOffset:    TableFileSizeINF,
+				PrevRowIDMax: 0,
+				RowIDMax:     fi.FileMeta.RealSize * CompressSizeFactor / divisor,
+			},
+		}
+		return []*TableRegion{tableRegion}, []float64{float64(fi.FileMeta.RealSize)}, nil
+	}
 	// If a csv file is overlarge, we need to split it into multiple regions.
 	// Note: We can only split a csv file whose format is strict.
 	// We increase the check threshold by 1/10 of the `max-region-size` because the source file size dumped by tools
@@ -311,7 +327,15 @@ func makeParquetFileRegion(
 	dataFile FileInfo,
 	prevRowIdxMax int64,
 ) (int64, *TableRegion, error) {
-	r, err := store.Open(ctx, dataFile.FileMeta.Path)
+	var (
+		r   storage.ReadSeekCloser
+		err error
+	)
+	if dataFile.FileMeta.Compression == CompressionNone {
+		r, err = store.Open(ctx, dataFile.FileMeta.Path)
+	} else {
+		r, err = OpenParquetReader(ctx, store, dataFile.FileMeta)
+	}
 	if err != nil {
 		return prevRowIdxMax, nil, errors.Trace(err)
 	}
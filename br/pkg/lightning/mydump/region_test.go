@@ -18,6 +18,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/pingcap/tidb/br/pkg/lightning/config"
@@ -382,3 +383,39 @@ func TestSplitLargeFileOnlyOneChunk(t *testing.T) {
 		require.Equal(t, columns, regions[i].Chunk.Columns)
 	}
 }
+
+func TestCompressedFileRegion(t *testing.T) {
+	cfg := &config.Config{
+		Mydumper: config.MydumperRuntime{
+			ReadBlockSize: config.ReadBlockSize,
+			CSV: config.CSVConfig{
+				Separator:       ",",
+				Delimiter:       "",
+				BackslashEscape: true,
+			},
+			StrictFormat:  true,
+			Filter:        []string{"*.*"},
+			MaxRegionSize: 15,
+		},
+	}
+	dir := t.TempDir()
+	store, err := storage.NewLocalStorage(dir)
+	require.NoError(t, err)
+
+	// a compressed file is never split even if it's larger than the max region size.
+	data := []byte(strings.Repeat("123,456\r\n", 100))
+	fileMeta := writeCompressedFile(t, dir, "test.csv.gz", CompressionGZ, data)
+	fileMeta.RealSize = int64(len(data))
+	meta := &MDTableMeta{
+		DB:        "csv",
+		Name:      "compressed_csv_file",
+		DataFiles: []FileInfo{{FileMeta: fileMeta}},
+	}
+	ioWorkers := worker.NewPool(context.Background(), 1, "io")
+	regions, err := MakeTableRegions(context.Background(), meta, 2, cfg, ioWorkers, store)
+	require.NoError(t, err)
+	require.Len(t, regions, 1)
+	require.Equal(t, int64(0), regions[0].Chunk.Offset)
+	require.Equal(t, int64(TableFileSizeINF), regions[0].Chunk.EndOffset)
+	require.Equal(t, fileMeta.RealSize*CompressSizeFactor/2, regions[0].Chunk.RowIDMax)
+}
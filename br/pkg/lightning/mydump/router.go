@@ -25,6 +25,8 @@ const (
 	SourceTypeCSV
 	SourceTypeParquet
 	SourceTypeViewSchema
+	// SourceTypeDB is the data read from a live database directly instead of the data files.
+	SourceTypeDB
 )
 
 const (
@@ -35,6 +37,7 @@ const (
 	TypeCSV      = "csv"
 	TypeParquet  = "parquet"
 	TypeIgnore   = "ignore"
+	TypeDB       = "db"
 )
 
 type Compression int
@@ -45,6 +48,7 @@ const (
 	CompressionLZ4
 	CompressionZStd
 	CompressionXZ
+	CompressionSnappy
 )
 
 func parseSourceType(t string) (SourceType, error) {
@@ -82,6 +86,8 @@ func (s SourceType) String() string {
 		return TypeParquet
 	case SourceTypeViewSchema:
 		return ViewSchema
+	case SourceTypeDB:
+		return TypeDB
 	default:
 		return TypeIgnore
 	}
@@ -89,14 +95,16 @@ func (s SourceType) String() string {
 
 func parseCompressionType(t string) (Compression, error) {
 	switch strings.ToLower(strings.TrimSpace(t)) {
-	case "gz":
+	case "gz", "gzip":
 		return CompressionGZ, nil
 	case "lz4":
 		return CompressionLZ4, nil
-	case "zstd":
+	case "zstd", "zst":
 		return CompressionZStd, nil
 	case "xz":
 		return CompressionXZ, nil
+	case "snappy":
+		return CompressionSnappy, nil
 	case "":
 		return CompressionNone, nil
 	default:
@@ -115,8 +123,8 @@ var defaultFileRouteRules = []*config.FileRouteRule{
 	{Pattern: `(?i)^(?:[^/]*/)*([^/.]+)\.(.*?)-schema\.sql$`, Schema: "$1", Table: "$2", Type: TableSchema, Unescape: true},
 	// view schema create file pattern, matches files like '{schema}.{table}-schema-view.sql'
 	{Pattern: `(?i)^(?:[^/]*/)*([^/.]+)\.(.*?)-schema-view\.sql$`, Schema: "$1", Table: "$2", Type: ViewSchema, Unescape: true},
-	// source file pattern, matches files like '{schema}.{table}.0001.{sql|csv}'
-	{Pattern: `(?i)^(?:[^/]*/)*([^/.]+)\.(.*?)(?:\.([0-9]+))?\.(sql|csv|parquet)$`, Schema: "$1", Table: "$2", Type: "$4", Key: "$3", Unescape: true},
+	// source file pattern, matches files like '{schema}.{table}.0001.{sql|csv}', which may be compressed, e.g. '{schema}.{table}.0001.csv.zst'
+	{Pattern: `(?i)^(?:[^/]*/)*([^/.]+)\.(.*?)(?:\.([0-9]+))?\.(sql|csv|parquet)(?:\.(gz|gzip|lz4|zst|zstd|xz|snappy))?$`, Schema: "$1", Table: "$2", Type: "$4", Key: "$3", Compression: "$5", Unescape: true},
 }
 
 // // RouteRule is a rule to route file path to target schema/table
@@ -265,14 +273,10 @@ func (p regexRouterParser) Parse(r *config.FileRouteRule) (*RegexRouter, error)
 
 	if len(r.Compression) > 0 {
 		err = p.parseFieldExtractor(rule, "compression", r.Compression, func(result *RouteResult, value string) error {
-			// TODO: should support restore compressed source files
 			compression, err := parseCompressionType(value)
 			if err != nil {
 				return err
 			}
-			if compression != CompressionNone {
-				return errors.New("Currently we don't support restore compressed source file yet")
-			}
 			result.Compression = compression
 			return nil
 		})
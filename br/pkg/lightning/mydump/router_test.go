@@ -81,6 +81,8 @@ func TestSingleRouteRule(t *testing.T) {
 		"/test/123/my_schema.my_table.sql": {"my_schema", "my_table", "", "", "sql"},
 		"my_dir/my_schema.my_table.csv":    {"my_schema", "my_table", "", "", "csv"},
 		"my_schema.my_table.0001.sql":      {"my_schema", "my_table", "0001", "", "sql"},
+		"my_schema.my_table.0001.sql.gz":   {"my_schema", "my_table", "0001", "gz", "sql"},
+		"my_schema.my_table.csv.zst":       {"my_schema", "my_table", "", "zst", "csv"},
 	}
 	for path, fields := range inputOutputMap {
 		res, err := r.Route(path)
@@ -110,8 +112,10 @@ func TestSingleRouteRule(t *testing.T) {
 	r, err = NewFileRouter([]*config.FileRouteRule{rule})
 	require.NoError(t, err)
 	require.NotNil(t, r)
+	res, err := r.Route("my_schema.my_table.sql.gz")
+	require.NoError(t, err)
+	require.Equal(t, CompressionGZ, res.Compression)
 	invalidMatchPaths := []string{
-		"my_schema.my_table.sql.gz",
 		"my_schema.my_table.sql.rar",
 		"my_schema.my_table.txt",
 	}
@@ -142,7 +146,7 @@ func TestMultiRouteRule(t *testing.T) {
 		"/test/123/my_schema.my_table.sql": {"my_schema", "my_table", "", "", "sql"},
 		"my_dir/my_schema.my_table.csv":    {"my_schema", "my_table", "", "", "csv"},
 		"my_schema.my_table.0001.sql":      {"my_schema", "my_table", "0001", "", "sql"},
-		// "my_schema.my_table.0001.sql.gz":      {"my_schema", "my_table", "0001", "gz", "sql"},
+		"my_schema.my_table.0001.sql.gz":   {"my_schema", "my_table", "0001", "gz", "sql"},
 	}
 	for path, fields := range inputOutputMap {
 		res, err := r.Route(path)
@@ -251,3 +255,31 @@ func TestRouteWithPath(t *testing.T) {
 	require.NoError(t, err)
 	require.Nil(t, res)
 }
+
+func TestDefaultRouteRuleWithCompression(t *testing.T) {
+	router, err := NewFileRouter(defaultFileRouteRules)
+	require.NoError(t, err)
+
+	inputOutputMap := map[string][]string{
+		"db.tbl.000000000.sql":        {"db", "tbl", "000000000", "", "sql"},
+		"db.tbl.000000000.sql.gz":     {"db", "tbl", "000000000", "gz", "sql"},
+		"db.tbl.000000000.csv.zst":    {"db", "tbl", "000000000", "zstd", "csv"},
+		"db.tbl.000000000.sql.snappy": {"db", "tbl", "000000000", "snappy", "sql"},
+		"dir/db.tbl.csv.lz4":          {"db", "tbl", "", "lz4", "csv"},
+		"db.tbl.parquet.xz":           {"db", "tbl", "", "xz", "parquet"},
+	}
+	for path, fields := range inputOutputMap {
+		res, err := router.Route(path)
+		require.NoError(t, err)
+		compress, err := parseCompressionType(fields[3])
+		require.NoError(t, err)
+		ty, err := parseSourceType(fields[4])
+		require.NoError(t, err)
+		exp := &RouteResult{filter.Table{Schema: fields[0], Name: fields[1]}, fields[2], compress, ty}
+		require.Equal(t, exp, res, path)
+	}
+
+	res, err := router.Route("db.tbl.000000000.sql.rar")
+	require.NoError(t, err)
+	require.Nil(t, res)
+}
@@ -384,6 +384,9 @@ func (rc *Controller) HasLargeCSV(dbMetas []*mydump.MDDatabaseMeta) {
 		for _, db := range dbMetas {
 			for _, t := range db.Tables {
 				for _, f := range t.DataFiles {
+					if f.FileMeta.Type == mydump.SourceTypeDB {
+						continue
+					}
 					if f.FileMeta.FileSize > defaultCSVSize {
 						message = fmt.Sprintf("large csv: %s file exists and it will slow down import performance", f.FileMeta.Path)
 						passed = false
@@ -414,7 +417,10 @@ func (rc *Controller) estimateSourceData(ctx context.Context) (int64, error) {
 			if ok {
 				// Do not sample small table because there may a large number of small table and it will take a long
 				// time to sample data for all of them.
-				if rc.cfg.TikvImporter.Backend == config.BackendTiDB || tbl.TotalSize < int64(config.SplitRegionSize) {
+				// The tables read from a database are not sampled either, the rows can't be read
+				// without occupying the connections of the source.
+				if rc.cfg.TikvImporter.Backend == config.BackendTiDB || rc.sourceDB != nil ||
+					tbl.TotalSize < int64(config.SplitRegionSize) {
 					sourceSize += tbl.TotalSize
 					tbl.IndexRatio = 1.0
 					tbl.IsRowOrdered = false
@@ -597,9 +603,9 @@ func hasDefault(col *model.ColumnInfo) bool {
 func (rc *Controller) readFirstRow(ctx context.Context, dataFileMeta mydump.SourceFileMeta) (cols []string, row []types.Datum, err error) {
 	var reader storage.ReadSeekCloser
 	if dataFileMeta.Type == mydump.SourceTypeParquet {
-		reader, err = mydump.OpenParquetReader(ctx, rc.store, dataFileMeta.Path, dataFileMeta.FileSize)
+		reader, err = mydump.OpenParquetReader(ctx, rc.store, dataFileMeta)
 	} else {
-		reader, err = rc.store.Open(ctx, dataFileMeta.Path)
+		reader, err = mydump.OpenReader(ctx, dataFileMeta, rc.store)
 	}
 	if err != nil {
 		return nil, nil, errors.Trace(err)
@@ -678,6 +684,10 @@ func (rc *Controller) SchemaIsValid(ctx context.Context, tableInfo *mydump.MDTab
 	// get columns name from data file.
 	dataFileMeta := dataFile.FileMeta
 
+	if dataFileMeta.Type == mydump.SourceTypeDB {
+		// the columns are read from the source table by name, so they always match.
+		return msgs, nil
+	}
 	if tp := dataFileMeta.Type; tp != mydump.SourceTypeCSV && tp != mydump.SourceTypeSQL && tp != mydump.SourceTypeParquet {
 		msgs = append(msgs, fmt.Sprintf("file '%s' with unknown source type '%s'", dataFileMeta.Path, dataFileMeta.Type.String()))
 		return msgs, nil
@@ -944,9 +954,9 @@ func (rc *Controller) sampleDataFromTable(
 	var reader storage.ReadSeekCloser
 	var err error
 	if sampleFile.Type == mydump.SourceTypeParquet {
-		reader, err = mydump.OpenParquetReader(ctx, rc.store, sampleFile.Path, sampleFile.FileSize)
+		reader, err = mydump.OpenParquetReader(ctx, rc.store, sampleFile)
 	} else {
-		reader, err = rc.store.Open(ctx, sampleFile.Path)
+		reader, err = mydump.OpenReader(ctx, sampleFile, rc.store)
 	}
 	if err != nil {
 		return errors.Trace(err)
@@ -15,9 +15,12 @@
 package restore
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -407,6 +410,69 @@ func (s *chunkRestoreSuite) TestEncodeLoopColumnsMismatch() {
 	require.Len(s.T(), kvsCh, 0)
 }
 
+func (s *chunkRestoreSuite) TestEncodeLoopCompressedRowIDMax() {
+	dir := s.T().TempDir()
+	store, err := storage.NewLocalStorage(dir)
+	require.NoError(s.T(), err)
+
+	// a highly compressible file has much more rows than estimated by a usual compression ratio.
+	data := []byte(strings.Repeat("1,2,3\r\n", 100000))
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	_, err = gw.Write(data)
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), gw.Close())
+	fileName := "db.table.000.csv.gz"
+	require.NoError(s.T(), os.WriteFile(filepath.Join(dir, fileName), buf.Bytes(), 0o644))
+
+	ctx := context.Background()
+	cfg := config.NewConfig()
+	cfg.Mydumper.CSV.Header = false
+	rc := &Controller{pauser: DeliverPauser, cfg: cfg}
+	w := worker.NewPool(ctx, 5, "io")
+	encodeFile := func(realSize int64) error {
+		fileMeta := mydump.SourceFileMeta{
+			Path:        fileName,
+			Type:        mydump.SourceTypeCSV,
+			Compression: mydump.CompressionGZ,
+			FileSize:    int64(buf.Len()),
+			RealSize:    realSize,
+		}
+		regions, err := mydump.MakeTableRegions(ctx, &mydump.MDTableMeta{
+			DB:        "db",
+			Name:      "table",
+			DataFiles: []mydump.FileInfo{{FileMeta: fileMeta}},
+		}, 3, cfg, w, store)
+		require.NoError(s.T(), err)
+		require.Len(s.T(), regions, 1)
+		chunk := &checkpoints.ChunkCheckpoint{
+			Key:      checkpoints.ChunkCheckpointKey{Path: fileName},
+			FileMeta: fileMeta,
+			Chunk:    regions[0].Chunk,
+		}
+		cr, err := newChunkRestore(ctx, 1, cfg, chunk, w, store, nil)
+		require.NoError(s.T(), err)
+		defer cr.close()
+
+		kvEncoder, err := kv.NewTableKVEncoder(s.tr.encTable, &kv.SessionOptions{
+			SQLMode:   s.cfg.TiDB.SQLMode,
+			Timestamp: 1234567895,
+		})
+		require.NoError(s.T(), err)
+		defer kvEncoder.Close()
+		kvsCh := make(chan []deliveredKVs, 1000)
+		_, _, err = cr.encodeLoop(ctx, kvsCh, s.tr, s.tr.logger, kvEncoder, make(chan deliverResult), rc)
+		return err
+	}
+
+	// the row count is estimated by the sampled compression ratio of the file itself.
+	require.NoError(s.T(), encodeFile(int64(len(data))))
+	// the rows exceeding the estimated row IDs can't be imported, or they overlap with the next chunk.
+	err = encodeFile(int64(buf.Len()) * 10)
+	require.Error(s.T(), err)
+	require.Regexp(s.T(), `row id \d+ exceeds the max row id \d+ of the chunk`, err.Error())
+}
+
 func (s *chunkRestoreSuite) TestEncodeLoopIgnoreColumnsCSV() {
 	cases := []struct {
 		s             string
@@ -40,6 +40,7 @@ import (
 	"github.com/pingcap/tidb/br/pkg/lightning/checkpoints"
 	"github.com/pingcap/tidb/br/pkg/lightning/common"
 	"github.com/pingcap/tidb/br/pkg/lightning/config"
+	"github.com/pingcap/tidb/br/pkg/lightning/dbsource"
 	"github.com/pingcap/tidb/br/pkg/lightning/errormanager"
 	"github.com/pingcap/tidb/br/pkg/lightning/glue"
 	"github.com/pingcap/tidb/br/pkg/lightning/log"
@@ -246,6 +247,7 @@ type Controller struct {
 	ioWorkers     *worker.Pool
 	checksumWorks *worker.Pool
 	pauser        *common.Pauser
+	tableControls *TableControls
 	backend       backend.Backend
 	tidbGlue      glue.Glue
 
@@ -263,6 +265,7 @@ type Controller struct {
 	closedEngineLimit *worker.Pool
 	store             storage.ExternalStorage
 	ownStore          bool
+	sourceDB          *dbsource.Source
 	metaMgrBuilder    metaMgrBuilder
 	errorMgr          *errormanager.ErrorManager
 	taskMgr           taskMetaMgr
@@ -290,12 +293,16 @@ type ControllerParam struct {
 	OwnExtStorage bool
 	// used by lightning server mode to pause tasks
 	Pauser *common.Pauser
+	// used by lightning server mode to control the tables individually
+	TableControls *TableControls
 	// lightning via SQL will implement its glue, to let lightning use host TiDB's environment
 	Glue glue.Glue
 	// storage interface to write file checkpoints
 	CheckpointStorage storage.ExternalStorage
 	// when CheckpointStorage is not nil, save file checkpoint to it with this name
 	CheckpointName string
+	// the live database to read the data from, DumpFileStorage only holds the schema files in this case
+	SourceDB *dbsource.Source
 }
 
 func NewRestoreController(
@@ -426,6 +433,7 @@ func NewRestoreControllerWithPauser(
 		ioWorkers:     worker.NewPool(ctx, cfg.App.IOConcurrency, "io"),
 		checksumWorks: worker.NewPool(ctx, cfg.TiDB.ChecksumTableConcurrency, "checksum"),
 		pauser:        p.Pauser,
+		tableControls: p.TableControls,
 		backend:       backend,
 		tidbGlue:      p.Glue,
 		sysVars:       defaultImportantVariables,
@@ -439,6 +447,7 @@ func NewRestoreControllerWithPauser(
 
 		store:          p.DumpFileStorage,
 		ownStore:       p.OwnExtStorage,
+		sourceDB:       p.SourceDB,
 		metaMgrBuilder: metaBuilder,
 		errorMgr:       errorMgr,
 		diskQuotaLock:  newDiskQuotaLock(),
@@ -1461,7 +1470,15 @@ func (rc *Controller) restoreTables(ctx context.Context) (finalErr error) {
 	}()
 
 	taskCh := make(chan task, rc.cfg.App.IndexConcurrency)
-	defer close(taskCh)
+	// taskCh isn't closed since the failed tables may be retried concurrently, the table workers exit
+	// when quitCh is closed.
+	quitCh := make(chan struct{})
+	defer close(quitCh)
+
+	if rc.tableControls == nil {
+		rc.tableControls = NewTableControls(false)
+	}
+	tableControls := rc.tableControls
 
 	manager, err := newChecksumManager(ctx, rc)
 	if err != nil {
@@ -1470,19 +1487,26 @@ func (rc *Controller) restoreTables(ctx context.Context) (finalErr error) {
 	ctx2 := context.WithValue(ctx, &checksumManagerKey, manager)
 	for i := 0; i < rc.cfg.App.IndexConcurrency; i++ {
 		go func() {
-			for task := range taskCh {
+			for {
+				var task task
+				select {
+				case task = <-taskCh:
+				case <-quitCh:
+					return
+				}
+				tableCtx, pauser := tableControls.start(ctx2, task.tr.tableName)
+				task.tr.pauser = pauser
 				tableLogTask := task.tr.logger.Begin(zap.InfoLevel, "restore table")
-				web.BroadcastTableCheckpoint(task.tr.tableName, task.cp)
-				needPostProcess, err := task.tr.restoreTable(ctx2, rc, task.cp)
+				web.BroadcastTableCheckpoint(task.tr.tableName, task.cp, task.tr.kvsPerRow(rc.cfg.TikvImporter.Backend))
+				needPostProcess, err := task.tr.restoreTable(tableCtx, rc, task.cp)
 				err = common.NormalizeOrWrapErr(common.ErrRestoreTable, err, task.tr.tableName)
 				tableLogTask.End(zap.ErrorLevel, err)
 				web.BroadcastError(task.tr.tableName, err)
 				metric.RecordTableCount("completed", err)
-				restoreErr.Set(err)
 				if needPostProcess {
 					postProcessTaskChan <- task
 				}
-				wg.Done()
+				tableControls.finish(task.tr.tableName, err)
 			}
 		}()
 	}
@@ -1508,16 +1532,35 @@ func (rc *Controller) restoreTables(ctx context.Context) (finalErr error) {
 				return errors.Trace(err)
 			}
 
-			wg.Add(1)
+			t := task{tr: tr, cp: cp}
+			tableControls.schedule(tableName, func() {
+				// the retry is requested by the HTTP API, which shouldn't be blocked by the busy table workers.
+				go func() {
+					select {
+					case taskCh <- t:
+					case <-quitCh:
+					case <-ctx.Done():
+						tableControls.finish(t.tr.tableName, ctx.Err())
+					}
+				}()
+			})
 			select {
-			case taskCh <- task{tr: tr, cp: cp}:
+			case taskCh <- t:
 			case <-ctx.Done():
 				return ctx.Err()
 			}
 		}
 	}
 
-	wg.Wait()
+	tableControls.seal()
+	select {
+	case <-tableControls.Done():
+	case <-ctx.Done():
+		// the failed tables can't be retried after the task is stopped, wait for the running tables to exit.
+		tableControls.stopRetry()
+		<-tableControls.Done()
+	}
+	restoreErr.Set(tableControls.Err())
 	// if context is done, should return directly
 	select {
 	case <-ctx.Done():
@@ -1621,7 +1664,7 @@ func (tr *TableRestore) restoreTable(
 		if err := rc.checkpointsDB.InsertEngineCheckpoints(ctx, tr.tableName, cp.Engines); err != nil {
 			return false, errors.Trace(err)
 		}
-		web.BroadcastTableCheckpoint(tr.tableName, cp)
+		web.BroadcastTableCheckpoint(tr.tableName, cp, tr.kvsPerRow(rc.cfg.TikvImporter.Backend))
 
 		// rebase the allocator so it exceeds the number of rows.
 		if tr.tableInfo.Core.PKIsHandle && tr.tableInfo.Core.ContainsAutoRandomBits() {
@@ -2031,9 +2074,9 @@ func newChunkRestore(
 	var reader storage.ReadSeekCloser
 	var err error
 	if chunk.FileMeta.Type == mydump.SourceTypeParquet {
-		reader, err = mydump.OpenParquetReader(ctx, store, chunk.FileMeta.Path, chunk.FileMeta.FileSize)
+		reader, err = mydump.OpenParquetReader(ctx, store, chunk.FileMeta)
 	} else {
-		reader, err = store.Open(ctx, chunk.FileMeta.Path)
+		reader, err = mydump.OpenReader(ctx, chunk.FileMeta, store)
 	}
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -2077,6 +2120,32 @@ func newChunkRestore(
 	}, nil
 }
 
+// newDBChunkRestore creates a chunkRestore which reads the rows of the chunk from the source database.
+func newDBChunkRestore(
+	ctx context.Context,
+	index int,
+	sourceDB *dbsource.Source,
+	chunk *checkpoints.ChunkCheckpoint,
+	tableInfo *checkpoints.TidbTableInfo,
+) (*chunkRestore, error) {
+	parser, err := sourceDB.NewParser(ctx, chunk.FileMeta, chunk.Chunk.EndOffset)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err = parser.SetPos(chunk.Chunk.Offset, chunk.Chunk.PrevRowIDMax); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(chunk.ColumnPermutation) > 0 {
+		parser.SetColumns(getColumnNames(tableInfo.Core, chunk.ColumnPermutation))
+	}
+
+	return &chunkRestore{
+		parser: parser,
+		index:  index,
+		chunk:  chunk,
+	}, nil
+}
+
 func (cr *chunkRestore) close() {
 	cr.parser.Close()
 }
@@ -2345,6 +2414,11 @@ func (cr *chunkRestore) encodeLoop(
 		if err = pauser.Wait(ctx); err != nil {
 			return
 		}
+		if t.pauser != nil {
+			if err = t.pauser.Wait(ctx); err != nil {
+				return
+			}
+		}
 		offset, _ := cr.parser.Pos()
 		if offset >= cr.chunk.Chunk.EndOffset {
 			break
@@ -2401,8 +2475,16 @@ func (cr *chunkRestore) encodeLoop(
 				return
 			}
 			readDur += time.Since(readDurStart)
-			encodeDurStart := time.Now()
 			lastRow := cr.parser.LastRow()
+			// the row IDs after RowIDMax belong to the next chunk, which happens if the row count of
+			// a compressed file is underestimated.
+			if lastRow.RowID > cr.chunk.Chunk.RowIDMax {
+				err = common.ErrEncodeKV.Wrap(errors.Errorf("row id %d exceeds the max row id %d of the chunk, "+
+					"the compression ratio of the file may be underestimated, please decompress it and retry",
+					lastRow.RowID, cr.chunk.Chunk.RowIDMax)).GenWithStackByArgs(&cr.chunk.Key, newOffset)
+				return
+			}
+			encodeDurStart := time.Now()
 			// sql -> kv
 			kvs, encodeErr := kvEncoder.Encode(logger, lastRow.Row, lastRow.RowID, cr.chunk.ColumnPermutation, cr.chunk.Key.Path, curOffset)
 			encodeDur += time.Since(encodeDurStart)
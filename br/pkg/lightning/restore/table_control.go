@@ -0,0 +1,310 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restore
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/lightning/common"
+	"github.com/pingcap/tidb/br/pkg/lightning/log"
+	"go.uber.org/zap"
+)
+
+// TableState is the state of a table scheduled to be restored in the current task.
+type TableState string
+
+const (
+	// TableStatePending means the table is waiting for a table worker.
+	TableStatePending TableState = "pending"
+	// TableStateRunning means the table is being restored.
+	TableStateRunning TableState = "running"
+	// TableStatePaused means the reading of the data files of the table is paused.
+	TableStatePaused TableState = "paused"
+	// TableStateCanceled means the table is canceled by the user, it can be retried.
+	TableStateCanceled TableState = "canceled"
+	// TableStateFailed means the restore of the table failed, it can be retried.
+	TableStateFailed TableState = "failed"
+	// TableStateCompleted means the table is restored. The post-process may be still pending
+	// if post-process-at-last is enabled.
+	TableStateCompleted TableState = "completed"
+)
+
+var (
+	errTableNotScheduled = errors.New("table is not scheduled in the current task")
+	errTaskFinished      = errors.New("all tables of the current task are finished")
+)
+
+type tableControl struct {
+	state  TableState
+	pauser *common.Pauser
+	cancel context.CancelFunc
+	err    error
+	// finished is whether the table worker has finished the table.
+	finished bool
+	// retry schedules the table again.
+	retry func()
+}
+
+// TableControls pauses, resumes, cancels and retries the tables in the current task individually.
+// A failed or canceled table can be retried until all the tables of the task are finished. If waitRetry
+// is set, the task isn't finished while any table is failed or canceled, until stopRetry is called.
+type TableControls struct {
+	mu     sync.Mutex
+	tables map[string]*tableControl
+	// failed are the tables which are failed or canceled, in the order of the failures.
+	failed []string
+	// running is the number of tables which are scheduled but not finished yet.
+	running   int
+	waitRetry bool
+	sealed    bool
+	finished  bool
+	done      chan struct{}
+}
+
+// NewTableControls creates a TableControls without any table. If waitRetry is true, the task waits for
+// the failed or canceled tables to be retried, it's used in the server mode where the tables can be
+// retried by the HTTP API.
+func NewTableControls(waitRetry bool) *TableControls {
+	return &TableControls{
+		tables:    make(map[string]*tableControl),
+		waitRetry: waitRetry,
+		done:      make(chan struct{}),
+	}
+}
+
+// schedule registers the table in pending state. retry is called to schedule the table again when
+// the table is retried.
+func (c *TableControls) schedule(tableName string, retry func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tables[tableName] = &tableControl{
+		state:  TableStatePending,
+		pauser: common.NewPauser(),
+		retry:  retry,
+	}
+	c.running++
+}
+
+// seal marks all the tables are scheduled, so the task is finished after the running tables are finished.
+func (c *TableControls) seal() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sealed = true
+	c.checkFinished()
+}
+
+// stopRetry stops waiting for the failed or canceled tables to be retried, so the task is finished after
+// the running tables are finished. It's called when the task is stopped.
+func (c *TableControls) stopRetry() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.waitRetry = false
+	c.checkFinished()
+}
+
+func (c *TableControls) checkFinished() {
+	if c.sealed && c.running == 0 && !c.finished && !(c.waitRetry && c.hasFailedTable()) {
+		c.finished = true
+		close(c.done)
+	}
+}
+
+// hasFailedTable checks whether any table is failed or canceled and isn't retried.
+func (c *TableControls) hasFailedTable() bool {
+	for _, name := range c.failed {
+		if c.tables[name].err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// start marks the table running, and returns the context and the pauser used to restore it.
+// The returned context is canceled if the table is canceled.
+func (c *TableControls) start(ctx context.Context, tableName string) (context.Context, *common.Pauser) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ctl, ok := c.tables[tableName]
+	if !ok {
+		// the table isn't scheduled by restoreTables, it can't be controlled.
+		return ctx, common.NewPauser()
+	}
+	tableCtx, cancel := context.WithCancel(ctx)
+	ctl.cancel = cancel
+	switch ctl.state {
+	case TableStateCanceled:
+		// canceled before it's started.
+		cancel()
+	case TableStatePending:
+		ctl.state = TableStateRunning
+		if ctl.pauser.IsPaused() {
+			ctl.state = TableStatePaused
+		}
+	}
+	return tableCtx, ctl.pauser
+}
+
+// finish marks the table completed, or failed if err isn't nil.
+func (c *TableControls) finish(tableName string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ctl, ok := c.tables[tableName]
+	if !ok {
+		return
+	}
+	if ctl.cancel != nil {
+		ctl.cancel()
+		ctl.cancel = nil
+	}
+	ctl.err = err
+	ctl.finished = true
+	switch {
+	case err == nil:
+		ctl.state = TableStateCompleted
+	case ctl.state == TableStateCanceled:
+		c.failed = append(c.failed, tableName)
+	default:
+		ctl.state = TableStateFailed
+		c.failed = append(c.failed, tableName)
+	}
+	if err != nil && c.waitRetry {
+		log.L().Warn("table is not restored, retry it or stop the task by the HTTP API",
+			zap.String("table", tableName), zap.String("state", string(ctl.state)), zap.Error(err))
+	}
+	c.running--
+	c.checkFinished()
+}
+
+// Done returns a channel which is closed when all the tables of the task are finished.
+func (c *TableControls) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns the error of the first table which is failed or canceled and isn't retried successfully.
+func (c *TableControls) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, name := range c.failed {
+		if ctl := c.tables[name]; ctl.err != nil {
+			return ctl.err
+		}
+	}
+	return nil
+}
+
+// State returns the state of the table, and the error if the table is failed or canceled.
+// The bool is false if the table isn't scheduled in the current task.
+func (c *TableControls) State(tableName string) (TableState, error, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ctl, ok := c.tables[tableName]
+	if !ok {
+		return "", nil, false
+	}
+	return ctl.state, ctl.err, true
+}
+
+// Pause pauses reading the data files of the table. The chunks being delivered are still written.
+func (c *TableControls) Pause(tableName string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ctl, ok := c.tables[tableName]
+	if !ok {
+		return errTableNotScheduled
+	}
+	switch ctl.state {
+	case TableStatePending, TableStateRunning, TableStatePaused:
+	default:
+		return errors.Errorf("can't pause a %s table", ctl.state)
+	}
+	ctl.pauser.Pause()
+	if ctl.state == TableStateRunning {
+		ctl.state = TableStatePaused
+	}
+	log.L().Info("table paused", zap.String("table", tableName))
+	return nil
+}
+
+// Resume resumes a paused table.
+func (c *TableControls) Resume(tableName string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ctl, ok := c.tables[tableName]
+	if !ok {
+		return errTableNotScheduled
+	}
+	ctl.pauser.Resume()
+	if ctl.state == TableStatePaused {
+		ctl.state = TableStateRunning
+	}
+	log.L().Info("table resumed", zap.String("table", tableName))
+	return nil
+}
+
+// Cancel cancels a pending or running table. The progress is kept in the checkpoints, so the table
+// can be retried later.
+func (c *TableControls) Cancel(tableName string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ctl, ok := c.tables[tableName]
+	if !ok {
+		return errTableNotScheduled
+	}
+	switch ctl.state {
+	case TableStatePending, TableStateRunning, TableStatePaused:
+	default:
+		return errors.Errorf("can't cancel a %s table", ctl.state)
+	}
+	ctl.state = TableStateCanceled
+	// a paused table must be resumed to notice the cancellation.
+	ctl.pauser.Resume()
+	if ctl.cancel != nil {
+		ctl.cancel()
+	}
+	log.L().Info("table canceled", zap.String("table", tableName))
+	return nil
+}
+
+// Retry schedules a failed or canceled table again. It fails if all the tables of the task are finished,
+// which doesn't happen before the task is stopped if waitRetry is set.
+func (c *TableControls) Retry(tableName string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ctl, ok := c.tables[tableName]
+	if !ok {
+		return errTableNotScheduled
+	}
+	if c.finished {
+		return errTaskFinished
+	}
+	switch ctl.state {
+	case TableStateFailed, TableStateCanceled:
+	default:
+		return errors.Errorf("can't retry a %s table", ctl.state)
+	}
+	if !ctl.finished {
+		return errors.New("table is still being canceled")
+	}
+	ctl.state = TableStatePending
+	ctl.err = nil
+	ctl.finished = false
+	ctl.pauser.Resume()
+	c.running++
+	ctl.retry()
+	log.L().Info("table retried", zap.String("table", tableName))
+	return nil
+}
@@ -0,0 +1,157 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pingcap/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func requireTableState(t *testing.T, c *TableControls, tableName string, expected TableState) {
+	state, _, ok := c.State(tableName)
+	require.True(t, ok)
+	require.Equal(t, expected, state)
+}
+
+func TestTableControls(t *testing.T) {
+	ctx := context.Background()
+	c := NewTableControls(false)
+
+	retried := make(map[string]int)
+	for _, name := range []string{"`db`.`t1`", "`db`.`t2`", "`db`.`t3`"} {
+		name := name
+		c.schedule(name, func() { retried[name]++ })
+	}
+	c.seal()
+	requireTableState(t, c, "`db`.`t1`", TableStatePending)
+	_, _, ok := c.State("`db`.`t4`")
+	require.False(t, ok)
+	require.Error(t, c.Pause("`db`.`t4`"))
+
+	// t1 is paused before it's started.
+	require.NoError(t, c.Pause("`db`.`t1`"))
+	requireTableState(t, c, "`db`.`t1`", TableStatePending)
+	ctx1, pauser1 := c.start(ctx, "`db`.`t1`")
+	require.True(t, pauser1.IsPaused())
+	requireTableState(t, c, "`db`.`t1`", TableStatePaused)
+	require.NoError(t, c.Resume("`db`.`t1`"))
+	require.False(t, pauser1.IsPaused())
+	requireTableState(t, c, "`db`.`t1`", TableStateRunning)
+	c.finish("`db`.`t1`", nil)
+	requireTableState(t, c, "`db`.`t1`", TableStateCompleted)
+	require.Error(t, ctx1.Err())
+	require.Error(t, c.Cancel("`db`.`t1`"))
+	require.Error(t, c.Retry("`db`.`t1`"))
+
+	// t2 is canceled before it's started.
+	require.NoError(t, c.Cancel("`db`.`t2`"))
+	requireTableState(t, c, "`db`.`t2`", TableStateCanceled)
+	require.Error(t, c.Retry("`db`.`t2`"))
+	ctx2, _ := c.start(ctx, "`db`.`t2`")
+	require.Error(t, ctx2.Err())
+	c.finish("`db`.`t2`", ctx2.Err())
+	requireTableState(t, c, "`db`.`t2`", TableStateCanceled)
+	require.Equal(t, context.Canceled, c.Err())
+
+	// t2 is retried and completed.
+	require.NoError(t, c.Retry("`db`.`t2`"))
+	require.Equal(t, 1, retried["`db`.`t2`"])
+	requireTableState(t, c, "`db`.`t2`", TableStatePending)
+	require.NoError(t, c.Err())
+	ctx2, _ = c.start(ctx, "`db`.`t2`")
+	require.NoError(t, ctx2.Err())
+	c.finish("`db`.`t2`", nil)
+	requireTableState(t, c, "`db`.`t2`", TableStateCompleted)
+
+	// t3 fails, and the task is finished.
+	_, _ = c.start(ctx, "`db`.`t3`")
+	select {
+	case <-c.Done():
+		require.FailNow(t, "the task shouldn't be finished")
+	default:
+	}
+	err := errors.New("t3 failed")
+	c.finish("`db`.`t3`", err)
+	requireTableState(t, c, "`db`.`t3`", TableStateFailed)
+	<-c.Done()
+	require.Equal(t, err, c.Err())
+	require.Error(t, c.Retry("`db`.`t3`"))
+	require.Equal(t, 0, retried["`db`.`t3`"])
+}
+
+func TestTableControlsUnscheduled(t *testing.T) {
+	c := NewTableControls(false)
+	ctx, pauser := c.start(context.Background(), "`db`.`t1`")
+	require.NoError(t, ctx.Err())
+	require.NotNil(t, pauser)
+	c.finish("`db`.`t1`", errors.New("ignored"))
+	c.seal()
+	<-c.Done()
+	require.NoError(t, c.Err())
+}
+
+func TestTableControlsWaitRetry(t *testing.T) {
+	ctx := context.Background()
+	c := NewTableControls(true)
+	retried := 0
+	c.schedule("`db`.`t1`", func() { retried++ })
+	c.schedule("`db`.`t2`", func() {})
+	c.seal()
+
+	// the task isn't finished while t1 is failed.
+	_, _ = c.start(ctx, "`db`.`t1`")
+	c.finish("`db`.`t1`", errors.New("t1 failed"))
+	_, _ = c.start(ctx, "`db`.`t2`")
+	c.finish("`db`.`t2`", nil)
+	requireTableState(t, c, "`db`.`t1`", TableStateFailed)
+	select {
+	case <-c.Done():
+		require.FailNow(t, "the task shouldn't be finished")
+	default:
+	}
+
+	// t1 is retried after all the other tables are finished.
+	require.NoError(t, c.Retry("`db`.`t1`"))
+	require.Equal(t, 1, retried)
+	_, _ = c.start(ctx, "`db`.`t1`")
+	err := errors.New("t1 failed again")
+	c.finish("`db`.`t1`", err)
+	select {
+	case <-c.Done():
+		require.FailNow(t, "the task shouldn't be finished")
+	default:
+	}
+
+	// the task is finished when it's stopped.
+	c.stopRetry()
+	<-c.Done()
+	require.Equal(t, err, c.Err())
+	require.Error(t, c.Retry("`db`.`t1`"))
+
+	// the task is finished once the failed table is retried successfully.
+	c = NewTableControls(true)
+	c.schedule("`db`.`t1`", func() {})
+	c.seal()
+	_, _ = c.start(ctx, "`db`.`t1`")
+	c.finish("`db`.`t1`", errors.New("t1 failed"))
+	require.NoError(t, c.Retry("`db`.`t1`"))
+	_, _ = c.start(ctx, "`db`.`t1`")
+	c.finish("`db`.`t1`", nil)
+	<-c.Done()
+	require.NoError(t, c.Err())
+}
@@ -53,6 +53,8 @@ type TableRestore struct {
 	encTable  table.Table
 	alloc     autoid.Allocators
 	logger    log.Logger
+	// pauser pauses the reading of the data files of the table only.
+	pauser *common.Pauser
 
 	ignoreColumns map[string]struct{}
 }
@@ -90,7 +92,13 @@ func (tr *TableRestore) Close() {
 
 func (tr *TableRestore) populateChunks(ctx context.Context, rc *Controller, cp *checkpoints.TableCheckpoint) error {
 	task := tr.logger.Begin(zap.InfoLevel, "load engines and files")
-	chunks, err := mydump.MakeTableRegions(ctx, tr.tableMeta, len(tr.tableInfo.Core.Columns), rc.cfg, rc.ioWorkers, rc.store)
+	var chunks []*mydump.TableRegion
+	var err error
+	if rc.sourceDB != nil {
+		chunks, err = rc.sourceDB.MakeTableRegions(ctx, tr.tableMeta)
+	} else {
+		chunks, err = mydump.MakeTableRegions(ctx, tr.tableMeta, len(tr.tableInfo.Core.Columns), rc.cfg, rc.ioWorkers, rc.store)
+	}
 	if err == nil {
 		timestamp := time.Now().Unix()
 		failpoint.Inject("PopulateChunkTimestamp", func(v failpoint.Value) {
@@ -146,6 +154,23 @@ func (tr *TableRestore) RebaseChunkRowIDs(cp *checkpoints.TableCheckpoint, rowID
 	}
 }
 
+// kvsPerRow returns the number of KV pairs encoded from each row, which is used to estimate the number of
+// rows restored from the checksums. The TiDB backend counts each row as one KV pair.
+func (tr *TableRestore) kvsPerRow(backend string) int64 {
+	if backend == config.BackendTiDB {
+		return 1
+	}
+	kvs := int64(1)
+	for _, index := range tr.tableInfo.Core.Indices {
+		// the clustered primary key is encoded in the row key.
+		if index.Primary && tr.tableInfo.Core.IsCommonHandle {
+			continue
+		}
+		kvs++
+	}
+	return kvs
+}
+
 // initializeColumns computes the "column permutation" for an INSERT INTO
 // statement. Suppose a table has columns (a, b, c, d) in canonical order, and
 // we execute `INSERT INTO (d, b, a) VALUES ...`, we will need to remap the
@@ -314,7 +339,7 @@ func (tr *TableRestore) restoreEngines(pCtx context.Context, rc *Controller, cp
 						err = tr.importEngine(ctx, dataClosedEngine, rc, eid, ecp)
 						if rc.status != nil {
 							for _, chunk := range ecp.Chunks {
-								rc.status.FinishedFileSize.Add(chunk.Chunk.EndOffset - chunk.Key.Offset)
+								rc.status.FinishedFileSize.Add(chunk.TotalSize())
 							}
 						}
 					}
@@ -324,7 +349,7 @@ func (tr *TableRestore) restoreEngines(pCtx context.Context, rc *Controller, cp
 				}(restoreWorker, engineID, engine)
 			} else {
 				for _, chunk := range engine.Chunks {
-					rc.status.FinishedFileSize.Add(chunk.Chunk.EndOffset - chunk.Key.Offset)
+					rc.status.FinishedFileSize.Add(chunk.TotalSize())
 				}
 			}
 		}
@@ -492,7 +517,12 @@ func (tr *TableRestore) restoreEngine(
 		// 	2. sql -> kvs
 		// 	3. load kvs data (into kv deliver server)
 		// 	4. flush kvs data (into tikv node)
-		cr, err := newChunkRestore(ctx, chunkIndex, rc.cfg, chunk, rc.ioWorkers, rc.store, tr.tableInfo)
+		var cr *chunkRestore
+		if rc.sourceDB != nil {
+			cr, err = newDBChunkRestore(ctx, chunkIndex, rc.sourceDB, chunk, tr.tableInfo)
+		} else {
+			cr, err = newChunkRestore(ctx, chunkIndex, rc.cfg, chunk, rc.ioWorkers, rc.store, tr.tableInfo)
+		}
 		if err != nil {
 			setError(err)
 			break
@@ -760,6 +790,10 @@ func (tr *TableRestore) postProcess(
 				return false, err
 			}
 		}
+		if hasDupe && rc.cfg.TikvImporter.DuplicateResolution == config.DupeResAlgErr {
+			tr.logger.Error("duplicate keys are found, please check the conflict error table")
+			return false, common.ErrFoundDuplicateKeys.GenWithStackByArgs(tr.tableName)
+		}
 
 		nextStage := checkpoints.CheckpointStatusChecksummed
 		if rc.cfg.PostRestore.Checksum != config.OpLevelOff && !hasDupe && needChecksum {
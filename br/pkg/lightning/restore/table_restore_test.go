@@ -978,7 +978,7 @@ func (s *tableRestoreSuite) TestSaveStatusCheckpoint() {
 		Name:   "test",
 		Tables: []*mydump.MDTableMeta{{DB: "test", Name: "tbl"}},
 	}})
-	web.BroadcastTableCheckpoint(common.UniqueTable("test", "tbl"), &checkpoints.TableCheckpoint{})
+	web.BroadcastTableCheckpoint(common.UniqueTable("test", "tbl"), &checkpoints.TableCheckpoint{}, 1)
 
 	saveCpCh := make(chan saveCp)
 
@@ -2,7 +2,9 @@ package web
 
 import (
 	"encoding/json"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/pingcap/errors"
 	"github.com/pingcap/tidb/br/pkg/lightning/checkpoints"
@@ -60,21 +62,29 @@ func (cpm *checkpointsMap) update(diffs map[string]*checkpoints.TableCheckpointD
 		cp := cpm.checkpoints[key]
 		cp.Apply(diff)
 
-		tw := int64(0)
-		for _, engine := range cp.Engines {
-			for _, chunk := range engine.Chunks {
-				if engine.Status >= checkpoints.CheckpointStatusAllWritten {
-					tw += chunk.Chunk.EndOffset - chunk.Key.Offset
-				} else {
-					tw += chunk.Chunk.Offset - chunk.Key.Offset
-				}
-			}
-		}
-		totalWrittens = append(totalWrittens, totalWritten{key: key, totalWritten: tw})
+		totalWrittens = append(totalWrittens, totalWritten{key: key, totalWritten: tableWritten(cp)})
 	}
 	return totalWrittens
 }
 
+// chunkWritten returns the size of the data in the chunk which has been written to the engine.
+func chunkWritten(engine *checkpoints.EngineCheckpoint, chunk *checkpoints.ChunkCheckpoint) int64 {
+	if engine.Status >= checkpoints.CheckpointStatusAllWritten {
+		return chunk.TotalSize()
+	}
+	return chunk.Chunk.Offset - chunk.Key.Offset
+}
+
+func tableWritten(cp *checkpoints.TableCheckpoint) int64 {
+	tw := int64(0)
+	for _, engine := range cp.Engines {
+		for _, chunk := range engine.Chunks {
+			tw += chunkWritten(engine, chunk)
+		}
+	}
+	return tw
+}
+
 func (cpm *checkpointsMap) marshal(key string) ([]byte, error) {
 	cpm.mu.RLock()
 	defer cpm.mu.RUnlock()
@@ -97,6 +107,13 @@ type tableInfo struct {
 	TotalSize    int64      `json:"z"`
 	Status       taskStatus `json:"s"`
 	Message      string     `json:"m,omitempty"`
+
+	// kvsPerRow is the number of KV pairs encoded from each row.
+	kvsPerRow int64
+	// startTime and startWritten are the time and the written size when the table starts running,
+	// they are used to estimate the remaining time.
+	startTime    time.Time
+	startWritten int64
 }
 
 type taskProgress struct {
@@ -165,12 +182,22 @@ func BroadcastInitProgress(databases []*mydump.MDDatabaseMeta) {
 	currentProgress.mu.Unlock()
 }
 
-func BroadcastTableCheckpoint(tableName string, cp *checkpoints.TableCheckpoint) {
+// BroadcastTableCheckpoint marks the table running. kvsPerRow is the number of KV pairs encoded from each
+// row, which is used to estimate the number of rows restored.
+func BroadcastTableCheckpoint(tableName string, cp *checkpoints.TableCheckpoint, kvsPerRow int64) {
 	if !progressEnabled.Load() {
 		return
 	}
 	currentProgress.mu.Lock()
-	currentProgress.Tables[tableName].Status = taskStatusRunning
+	if tbl := currentProgress.Tables[tableName]; tbl.Status != taskStatusRunning {
+		// the table is started or retried.
+		tbl.Status = taskStatusRunning
+		tbl.Message = ""
+		tbl.startTime = time.Now()
+		tbl.startWritten = tableWritten(cp)
+		tbl.TotalWritten = tbl.startWritten
+		tbl.kvsPerRow = kvsPerRow
+	}
 	currentProgress.mu.Unlock()
 
 	// create a deep copy to avoid false sharing
@@ -219,3 +246,153 @@ func MarshalTableCheckpoints(tableName string) ([]byte, error) {
 	}
 	return currentProgress.checkpoints.marshal(tableName)
 }
+
+// ChunkProgress is the progress of a chunk of a data file.
+type ChunkProgress struct {
+	Path         string `json:"path"`
+	Offset       int64  `json:"offset"`
+	EndOffset    int64  `json:"end_offset"`
+	Pos          int64  `json:"pos"`
+	WrittenBytes int64  `json:"written_bytes"`
+	TotalBytes   int64  `json:"total_bytes"`
+	Rows         int64  `json:"rows"`
+}
+
+// EngineProgress is the progress of an engine. The index engine doesn't contain any chunk.
+type EngineProgress struct {
+	ID           int32            `json:"id"`
+	Status       string           `json:"status"`
+	WrittenBytes int64            `json:"written_bytes"`
+	TotalBytes   int64            `json:"total_bytes"`
+	Rows         int64            `json:"rows"`
+	Chunks       []*ChunkProgress `json:"chunks"`
+}
+
+// TableProgress is the progress of a table computed from the checkpoints. The number of rows is
+// estimated from the KV pairs written, and the remaining time is estimated from the speed since
+// the table is started.
+type TableProgress struct {
+	Name         string            `json:"name"`
+	Status       string            `json:"status"`
+	WrittenBytes int64             `json:"written_bytes"`
+	TotalBytes   int64             `json:"total_bytes"`
+	Rows         int64             `json:"rows"`
+	ETASeconds   *float64          `json:"eta_seconds,omitempty"`
+	Error        string            `json:"error,omitempty"`
+	Engines      []*EngineProgress `json:"engines,omitempty"`
+
+	completed bool
+	running   bool
+}
+
+// Completed returns whether the restore of the table is finished, successfully or not.
+func (p *TableProgress) Completed() bool {
+	return p.completed
+}
+
+// Running returns whether the table is being restored.
+func (p *TableProgress) Running() bool {
+	return p.running
+}
+
+func (cpm *checkpointsMap) progress(tbl *tableInfo, tableName string, withEngines bool) *TableProgress {
+	res := &TableProgress{
+		Name:       tableName,
+		Status:     checkpoints.CheckpointStatusMissing.MetricName(),
+		TotalBytes: tbl.TotalSize,
+		Error:      tbl.Message,
+		completed:  tbl.Status == taskStatusCompleted,
+		running:    tbl.Status == taskStatusRunning,
+	}
+	cpm.mu.RLock()
+	defer cpm.mu.RUnlock()
+	cp, ok := cpm.checkpoints[tableName]
+	if !ok {
+		return res
+	}
+	res.Status = cp.Status.MetricName()
+	kvsPerRow := tbl.kvsPerRow
+	if kvsPerRow <= 0 {
+		kvsPerRow = 1
+	}
+
+	engineIDs := make([]int32, 0, len(cp.Engines))
+	for engineID := range cp.Engines {
+		engineIDs = append(engineIDs, engineID)
+	}
+	sort.Slice(engineIDs, func(i, j int) bool { return engineIDs[i] < engineIDs[j] })
+	var totalSize int64
+	for _, engineID := range engineIDs {
+		engine := cp.Engines[engineID]
+		ep := &EngineProgress{
+			ID:     engineID,
+			Status: engine.Status.MetricName(),
+			Chunks: make([]*ChunkProgress, 0, len(engine.Chunks)),
+		}
+		for _, chunk := range engine.Chunks {
+			chp := &ChunkProgress{
+				Path:         chunk.Key.Path,
+				Offset:       chunk.Key.Offset,
+				EndOffset:    chunk.Chunk.EndOffset,
+				Pos:          chunk.Chunk.Offset,
+				WrittenBytes: chunkWritten(engine, chunk),
+				TotalBytes:   chunk.TotalSize(),
+				Rows:         int64(chunk.Checksum.SumKVS()) / kvsPerRow,
+			}
+			ep.WrittenBytes += chp.WrittenBytes
+			ep.TotalBytes += chp.TotalBytes
+			ep.Rows += chp.Rows
+			ep.Chunks = append(ep.Chunks, chp)
+		}
+		res.WrittenBytes += ep.WrittenBytes
+		res.Rows += ep.Rows
+		totalSize += ep.TotalBytes
+		if withEngines {
+			res.Engines = append(res.Engines, ep)
+		}
+	}
+	// the size of the chunks is more accurate than the size of the files, which is estimated for
+	// the compressed files.
+	if len(engineIDs) > 0 {
+		res.TotalBytes = totalSize
+	}
+
+	if res.running {
+		written := res.WrittenBytes - tbl.startWritten
+		if elapsed := time.Since(tbl.startTime).Seconds(); written > 0 && res.TotalBytes >= res.WrittenBytes {
+			eta := elapsed * float64(res.TotalBytes-res.WrittenBytes) / float64(written)
+			res.ETASeconds = &eta
+		}
+	}
+	return res
+}
+
+// TablesProgress returns the progress of all the tables in the current task ordered by the names.
+// The engines and chunks are not included.
+func TablesProgress() ([]*TableProgress, error) {
+	if !progressEnabled.Load() {
+		return nil, errors.New("progress is not enabled")
+	}
+	currentProgress.mu.RLock()
+	defer currentProgress.mu.RUnlock()
+	res := make([]*TableProgress, 0, len(currentProgress.Tables))
+	for name, tbl := range currentProgress.Tables {
+		res = append(res, currentProgress.checkpoints.progress(tbl, name, false))
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res, nil
+}
+
+// GetTableProgress returns the progress of the table in the current task including the engines and chunks.
+func GetTableProgress(tableName string) (*TableProgress, error) {
+	if !progressEnabled.Load() {
+		return nil, errors.New("progress is not enabled")
+	}
+	currentProgress.mu.RLock()
+	defer currentProgress.mu.RUnlock()
+	tbl, ok := currentProgress.Tables[tableName]
+	if !ok {
+		return nil, errors.NotFoundf("table %s", tableName)
+	}
+	return currentProgress.checkpoints.progress(tbl, tableName, true), nil
+}
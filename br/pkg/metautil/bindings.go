@@ -0,0 +1,74 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package metautil
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/kvproto/pkg/encryptionpb"
+	"github.com/pingcap/log"
+	berrors "github.com/pingcap/tidb/br/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// BindingsFile represents the file name of the global SQL bindings in the backup.
+// It's not referenced by the backupmeta, the backups of old versions don't have it.
+const BindingsFile = "backupmeta.bindings"
+
+// Binding is a global SQL binding, the fields are the columns of mysql.bind_info.
+type Binding struct {
+	OriginalSQL string `json:"original_sql"`
+	BindSQL     string `json:"bind_sql"`
+	DefaultDB   string `json:"default_db"`
+	Status      string `json:"status"`
+	Charset     string `json:"charset"`
+	Collation   string `json:"collation"`
+	Source      string `json:"source"`
+}
+
+// FlushBindings writes the global SQL bindings to the storage, it's encrypted in the same way
+// as the backupmeta.
+func (writer *MetaWriter) FlushBindings(ctx context.Context, bindings []*Binding) error {
+	data, err := json.Marshal(bindings)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	encryptBuff, iv, err := Encrypt(data, writer.cipher)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	log.Info("save bindings", zap.Int("count", len(bindings)), zap.Int("size", len(data)))
+	return writer.storage.WriteFile(ctx, BindingsFile, append(iv, encryptBuff...))
+}
+
+// ReadBindings reads the global SQL bindings in the backup.
+// It returns nil if the bindings are not backed up.
+func (reader *MetaReader) ReadBindings(ctx context.Context) ([]*Binding, error) {
+	exists, err := reader.storage.FileExists(ctx, BindingsFile)
+	if err != nil || !exists {
+		return nil, errors.Trace(err)
+	}
+	data, err := reader.storage.ReadFile(ctx, BindingsFile)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	// the prefix of the file is iv(16 bytes) if encryption method is valid
+	var iv []byte
+	if reader.cipher.CipherType != encryptionpb.EncryptionMethod_PLAINTEXT {
+		if len(data) < CrypterIvLen {
+			return nil, errors.Annotatef(berrors.ErrInvalidMetaFile, "%s is too short to decrypt", BindingsFile)
+		}
+		iv = data[:CrypterIvLen]
+	}
+	data, err = Decrypt(data[len(iv):], reader.cipher, iv)
+	if err != nil {
+		return nil, errors.Annotate(err, "decrypt failed with wrong key")
+	}
+	var bindings []*Binding
+	if err = json.Unmarshal(data, &bindings); err != nil {
+		return nil, errors.Annotatef(berrors.ErrInvalidMetaFile, "failed to parse %s: %v", BindingsFile, err)
+	}
+	return bindings, nil
+}
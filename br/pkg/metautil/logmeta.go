@@ -0,0 +1,136 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package metautil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/pingcap/errors"
+	backuppb "github.com/pingcap/kvproto/pkg/brpb"
+	berrors "github.com/pingcap/tidb/br/pkg/errors"
+	"github.com/pingcap/tidb/br/pkg/storage"
+)
+
+const (
+	// LogBackupTaskFile represents the file name of the log backup task information.
+	LogBackupTaskFile = "v1/log_backup_task.json"
+	// LogMetaDir represents the directory of the log backup metadata files,
+	// one metadata file is written for each flush.
+	LogMetaDir = "v1/backupmeta"
+	// LogDataDir represents the directory of the log backup data files.
+	LogDataDir = "v1/data"
+
+	logMetaSuffix = ".meta"
+)
+
+// LogBackupTask is the information of a log backup task, it's persisted in the
+// log backup storage so that the task can be resumed and restored from.
+type LogBackupTask struct {
+	Name      string `json:"name"`
+	ClusterID uint64 `json:"cluster-id"`
+	// StartTS is the ts from which the changes are backed up.
+	StartTS uint64 `json:"start-ts"`
+	// TruncatedTS is the ts before which the log files may have been truncated.
+	TruncatedTS uint64 `json:"truncated-ts"`
+}
+
+// ReadLogBackupTask reads the log backup task from the storage.
+// It returns nil if there is no log backup task in the storage.
+func ReadLogBackupTask(ctx context.Context, s storage.ExternalStorage) (*LogBackupTask, error) {
+	exists, err := s.FileExists(ctx, LogBackupTaskFile)
+	if err != nil || !exists {
+		return nil, errors.Trace(err)
+	}
+	data, err := s.ReadFile(ctx, LogBackupTaskFile)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	task := &LogBackupTask{}
+	if err = json.Unmarshal(data, task); err != nil {
+		return nil, errors.Annotatef(berrors.ErrInvalidMetaFile, "failed to parse %s: %v", LogBackupTaskFile, err)
+	}
+	return task, nil
+}
+
+// WriteLogBackupTask writes the log backup task to the storage.
+func WriteLogBackupTask(ctx context.Context, s storage.ExternalStorage, task *LogBackupTask) error {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(s.WriteFile(ctx, LogBackupTaskFile, data))
+}
+
+// LogMeta is the metadata of a flush of the log backup.
+type LogMeta struct {
+	// Name is the file name of the metadata.
+	Name string
+	// Meta contains the data files of the flush. Its EndVersion is the checkpoint ts
+	// of the flush, before which all the changes have been backed up.
+	Meta *backuppb.BackupMeta
+}
+
+// LogMetaFileName returns the name of the metadata file of a flush.
+// The id makes the name unique when the checkpoint doesn't advance between flushes.
+func LogMetaFileName(checkpoint uint64, id string) string {
+	// Use the fixed width hex so that the files are sorted by the checkpoint.
+	return path.Join(LogMetaDir, fmt.Sprintf("%016X-%s%s", checkpoint, id, logMetaSuffix))
+}
+
+// WriteLogMeta writes the metadata of a flush to the storage.
+func WriteLogMeta(ctx context.Context, s storage.ExternalStorage, meta *LogMeta) error {
+	data, err := proto.Marshal(meta.Meta)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(s.WriteFile(ctx, meta.Name, data))
+}
+
+// ReadLogMetas reads the metadata of all the flushes from the storage, sorted by the checkpoint ts.
+func ReadLogMetas(ctx context.Context, s storage.ExternalStorage) ([]*LogMeta, error) {
+	var names []string
+	err := s.WalkDir(ctx, &storage.WalkOption{SubDir: LogMetaDir}, func(name string, _ int64) error {
+		if strings.HasSuffix(name, logMetaSuffix) {
+			names = append(names, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	metas := make([]*LogMeta, 0, len(names))
+	for _, name := range names {
+		data, err := s.ReadFile(ctx, name)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		meta := &backuppb.BackupMeta{}
+		if err = proto.Unmarshal(data, meta); err != nil {
+			return nil, errors.Annotatef(berrors.ErrInvalidMetaFile, "failed to parse %s: %v", name, err)
+		}
+		metas = append(metas, &LogMeta{Name: name, Meta: meta})
+	}
+	sort.SliceStable(metas, func(i, j int) bool {
+		return metas[i].Meta.EndVersion < metas[j].Meta.EndVersion
+	})
+	return metas, nil
+}
+
+// LogCheckpoint returns the global checkpoint ts of the log backup, i.e. all the
+// changes committed before it have been backed up.
+func LogCheckpoint(metas []*LogMeta) uint64 {
+	var checkpoint uint64
+	for _, meta := range metas {
+		if meta.Meta.EndVersion > checkpoint {
+			checkpoint = meta.Meta.EndVersion
+		}
+	}
+	return checkpoint
+}
@@ -0,0 +1,48 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package metautil
+
+import (
+	"context"
+	"testing"
+
+	backuppb "github.com/pingcap/kvproto/pkg/brpb"
+	"github.com/pingcap/tidb/br/pkg/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogBackupMeta(t *testing.T) {
+	ctx := context.Background()
+	s, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+
+	task, err := ReadLogBackupTask(ctx, s)
+	require.NoError(t, err)
+	require.Nil(t, task)
+	task = &LogBackupTask{Name: "pitr", ClusterID: 1, StartTS: 10}
+	require.NoError(t, WriteLogBackupTask(ctx, s, task))
+	readTask, err := ReadLogBackupTask(ctx, s)
+	require.NoError(t, err)
+	require.Equal(t, task, readTask)
+
+	metas, err := ReadLogMetas(ctx, s)
+	require.NoError(t, err)
+	require.Len(t, metas, 0)
+	require.Equal(t, uint64(0), LogCheckpoint(metas))
+
+	for _, checkpoint := range []uint64{30, 10, 20, 20} {
+		meta := &LogMeta{
+			Name: LogMetaFileName(checkpoint, "id"+string(rune('a'+len(metas)))),
+			Meta: &backuppb.BackupMeta{EndVersion: checkpoint},
+		}
+		metas = append(metas, meta)
+		require.NoError(t, WriteLogMeta(ctx, s, meta))
+	}
+	metas, err = ReadLogMetas(ctx, s)
+	require.NoError(t, err)
+	require.Len(t, metas, 4)
+	for i, checkpoint := range []uint64{10, 20, 20, 30} {
+		require.Equal(t, checkpoint, metas[i].Meta.EndVersion)
+	}
+	require.Equal(t, uint64(30), LogCheckpoint(metas))
+}
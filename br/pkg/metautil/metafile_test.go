@@ -11,6 +11,7 @@ import (
 	backuppb "github.com/pingcap/kvproto/pkg/brpb"
 	"github.com/pingcap/kvproto/pkg/encryptionpb"
 	mockstorage "github.com/pingcap/tidb/br/pkg/mock/storage"
+	"github.com/pingcap/tidb/br/pkg/storage"
 	"github.com/stretchr/testify/require"
 )
 
@@ -215,3 +216,38 @@ func TestEncryptAndDecrypt(t *testing.T) {
 		}
 	}
 }
+
+func TestFlushAndReadBindings(t *testing.T) {
+	ctx := context.Background()
+	bindings := []*Binding{
+		{
+			OriginalSQL: "select * from `test` . `t`",
+			BindSQL:     "SELECT * FROM `test`.`t` USE INDEX (`idx`)",
+			DefaultDB:   "test",
+			Status:      "enabled",
+			Charset:     "utf8mb4",
+			Collation:   "utf8mb4_bin",
+			Source:      "manual",
+		},
+	}
+	ciphers := []*backuppb.CipherInfo{
+		{CipherType: encryptionpb.EncryptionMethod_PLAINTEXT},
+		{CipherType: encryptionpb.EncryptionMethod_AES128_CTR, CipherKey: []byte("0123456789012345")},
+	}
+	for _, cipher := range ciphers {
+		s, err := storage.NewLocalStorage(t.TempDir())
+		require.NoError(t, err)
+		reader := NewMetaReader(&backuppb.BackupMeta{}, s, cipher)
+
+		// the backups of old versions don't have the bindings.
+		read, err := reader.ReadBindings(ctx)
+		require.NoError(t, err)
+		require.Nil(t, read)
+
+		writer := NewMetaWriter(s, MetaFileSize, false, cipher)
+		require.NoError(t, writer.FlushBindings(ctx, bindings))
+		read, err = reader.ReadBindings(ctx)
+		require.NoError(t, err)
+		require.Equal(t, bindings, read)
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package restore
+
+import (
+	"github.com/pingcap/log"
+	"github.com/pingcap/tidb/br/pkg/metautil"
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/parser/ast"
+	"go.uber.org/zap"
+)
+
+// tableNameCollector collects the names of the tables referred by a statement. The names of the
+// common table expressions in scope aren't tables, so they aren't collected.
+type tableNameCollector struct {
+	names []*ast.TableName
+	// cteScopes are the names of the common table expressions defined by the enclosing statements.
+	cteScopes []map[string]struct{}
+}
+
+func withClauseOf(node ast.Node) *ast.WithClause {
+	switch x := node.(type) {
+	case *ast.SelectStmt:
+		return x.With
+	case *ast.SetOprStmt:
+		return x.With
+	case *ast.SetOprSelectList:
+		return x.With
+	case *ast.UpdateStmt:
+		return x.With
+	case *ast.DeleteStmt:
+		return x.With
+	}
+	return nil
+}
+
+func (c *tableNameCollector) isCTE(name *ast.TableName) bool {
+	if name.Schema.L != "" {
+		return false
+	}
+	for _, scope := range c.cteScopes {
+		if _, ok := scope[name.Name.L]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *tableNameCollector) Enter(in ast.Node) (ast.Node, bool) {
+	if with := withClauseOf(in); with != nil {
+		scope := make(map[string]struct{}, len(with.CTEs))
+		for _, cte := range with.CTEs {
+			scope[cte.Name.L] = struct{}{}
+		}
+		c.cteScopes = append(c.cteScopes, scope)
+	}
+	if name, ok := in.(*ast.TableName); ok && !c.isCTE(name) {
+		c.names = append(c.names, name)
+	}
+	return in, false
+}
+
+func (c *tableNameCollector) Leave(in ast.Node) (ast.Node, bool) {
+	if withClauseOf(in) != nil {
+		c.cteScopes = c.cteScopes[:len(c.cteScopes)-1]
+	}
+	return in, true
+}
+
+// FilterBindings returns the bindings whose tables are all in the restored tables, the other
+// bindings are skipped because they refer to the tables which are filtered out.
+func FilterBindings(bindings []*metautil.Binding, tables []*metautil.Table) []*metautil.Binding {
+	restored := make(map[UniqueTableName]struct{}, len(tables))
+	for _, table := range tables {
+		restored[UniqueTableName{DB: table.DB.Name.L, Table: table.Info.Name.L}] = struct{}{}
+	}
+
+	p := parser.New()
+	filtered := make([]*metautil.Binding, 0, len(bindings))
+	for _, binding := range bindings {
+		stmt, err := p.ParseOneStmt(binding.BindSQL, binding.Charset, binding.Collation)
+		if err != nil {
+			log.Warn("skip the binding which can't be parsed", zap.String("sql", binding.BindSQL), zap.Error(err))
+			continue
+		}
+		collector := &tableNameCollector{}
+		stmt.Accept(collector)
+		matched := true
+		for _, name := range collector.names {
+			dbName := name.Schema.L
+			if dbName == "" {
+				dbName = binding.DefaultDB
+			}
+			if _, ok := restored[UniqueTableName{DB: dbName, Table: name.Name.L}]; !ok {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			filtered = append(filtered, binding)
+		}
+	}
+	return filtered
+}
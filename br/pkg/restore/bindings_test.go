@@ -0,0 +1,41 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package restore_test
+
+import (
+	"testing"
+
+	"github.com/pingcap/tidb/br/pkg/metautil"
+	"github.com/pingcap/tidb/br/pkg/restore"
+	"github.com/pingcap/tidb/parser/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterBindings(t *testing.T) {
+	newTable := func(db, tbl string) *metautil.Table {
+		return &metautil.Table{
+			DB:   &model.DBInfo{Name: model.NewCIStr(db)},
+			Info: &model.TableInfo{Name: model.NewCIStr(tbl)},
+		}
+	}
+	tables := []*metautil.Table{newTable("test", "t1"), newTable("Test", "T2")}
+	newBinding := func(bindSQL string) *metautil.Binding {
+		return &metautil.Binding{BindSQL: bindSQL, DefaultDB: "test", Charset: "utf8mb4", Collation: "utf8mb4_bin"}
+	}
+	bindings := []*metautil.Binding{
+		newBinding("SELECT * FROM `test`.`t1` USE INDEX (`idx`) WHERE `a` = 1"),
+		newBinding("SELECT /*+ HASH_JOIN(t1) */ * FROM t1 JOIN t2 ON t1.a = t2.a"),
+		newBinding("SELECT * FROM `test`.`t3` USE INDEX (`idx`) WHERE `a` = 1"),
+		newBinding("SELECT * FROM `test`.`t1` WHERE `a` IN (SELECT `a` FROM `other`.`t2`)"),
+		newBinding("SELECT * FROM"),
+		// the common table expressions aren't tables.
+		newBinding("WITH `cte` AS (SELECT `a` FROM `t1`) SELECT * FROM `cte` JOIN `t2` ON `cte`.`a` = `t2`.`a`"),
+		newBinding("WITH RECURSIVE `cte` (`n`) AS (SELECT 1 UNION ALL SELECT `n` + 1 FROM `cte` WHERE `n` < 10) SELECT * FROM `cte`"),
+		newBinding("SELECT * FROM `t1` WHERE `a` IN (WITH `t3` AS (SELECT 1) SELECT * FROM `t3`)"),
+		// the names are only CTEs in the scope of the WITH clauses.
+		newBinding("SELECT * FROM (WITH `t3` AS (SELECT 1) SELECT * FROM `t3`) `x` JOIN `t3`"),
+		newBinding("WITH `t3` AS (SELECT 1) SELECT * FROM `test`.`t3`"),
+	}
+	filtered := restore.FilterBindings(bindings, tables)
+	require.Equal(t, append(bindings[:2:2], bindings[5:8]...), filtered)
+}
@@ -105,6 +105,8 @@ type Client struct {
 	// and restore stats with #dump.LoadStatsFromJSON
 	statsHandler *handle.Handle
 	dom          *domain.Domain
+	// skipStats skips loading the stats in the backup.
+	skipStats bool
 
 	batchDdlSize uint
 
@@ -1081,6 +1083,7 @@ func (rc *Client) execChecksum(
 
 	if tbl.OldTable.NoChecksum() {
 		logger.Warn("table has no checksum, skipping checksum")
+		loadStatCh <- &tbl
 		return nil
 	}
 
@@ -1127,6 +1130,39 @@ func (rc *Client) execChecksum(
 	return nil
 }
 
+// GoUpdateMetaAndLoadStats forks a goroutine to update the stats meta and load the stats of the
+// restored tables without validating checksum. It returns a channel fires a struct{} when all things get done.
+func (rc *Client) GoUpdateMetaAndLoadStats(
+	ctx context.Context,
+	tableStream <-chan CreatedTable,
+	errCh chan<- error,
+	updateCh glue.Progress,
+) <-chan struct{} {
+	outCh := make(chan struct{}, 1)
+	loadStatCh := make(chan *CreatedTable, 1024)
+	go func() {
+		defer close(outCh)
+		rc.updateMetaAndLoadStats(ctx, loadStatCh)
+	}()
+	go func() {
+		defer close(loadStatCh)
+		for {
+			select {
+			case <-ctx.Done():
+				errCh <- ctx.Err()
+				return
+			case tbl, ok := <-tableStream:
+				if !ok {
+					return
+				}
+				loadStatCh <- &tbl
+				updateCh.Inc()
+			}
+		}
+	}()
+	return outCh
+}
+
 func (rc *Client) updateMetaAndLoadStats(ctx context.Context, input <-chan *CreatedTable) {
 	for {
 		select {
@@ -1149,7 +1185,8 @@ func (rc *Client) updateMetaAndLoadStats(ctx context.Context, input <-chan *Crea
 			}
 
 			table := tbl.OldTable
-			if table.Stats != nil {
+			// the stats are loaded into the table of the same name, which is the new table.
+			if table.Stats != nil && !rc.skipStats {
 				log.Info("start loads analyze after validate checksum",
 					zap.Int64("old id", tbl.OldTable.Info.ID),
 					zap.Int64("new id", tbl.Table.ID),
@@ -1172,6 +1209,17 @@ const (
 	restoreLabelValue = "restore"
 )
 
+// RestoreBindings creates the global SQL bindings in the backup, the same bindings in the cluster are replaced.
+func (rc *Client) RestoreBindings(ctx context.Context, bindings []*metautil.Binding) error {
+	for _, binding := range bindings {
+		if err := rc.db.CreateBinding(ctx, binding); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	log.Info("restore bindings done", zap.Int("count", len(bindings)))
+	return nil
+}
+
 // LoadRestoreStores loads the stores used to restore data.
 func (rc *Client) LoadRestoreStores(ctx context.Context) error {
 	if !rc.isOnline {
@@ -1341,6 +1389,11 @@ func (rc *Client) IsSkipCreateSQL() bool {
 	return rc.noSchema
 }
 
+// EnableSkipStats sets switch of skip loading the stats in the backup.
+func (rc *Client) EnableSkipStats() {
+	rc.skipStats = true
+}
+
 // GenerateDDLJobsMap returns a map[UniqueTableName]bool about < db table, hasCreate/hasTruncate DDL >.
 // if we execute some DDLs before create table.
 // we may get two situation that need to rebase auto increment/random id.
@@ -150,6 +150,40 @@ func (db *DB) UpdateStatsMeta(ctx context.Context, tableID int64, restoreTS uint
 	return nil
 }
 
+// CreateBinding inserts a global SQL binding into mysql.bind_info. The update time is the current
+// time so that the binding is loaded by all the TiDB instances. Like creating a binding by SQL, the
+// existing bindings of the same statement are marked deleted in the same transaction, so they are
+// also removed from the binding caches.
+func (db *DB) CreateBinding(ctx context.Context, binding *metautil.Binding) (err error) {
+	if err = db.se.ExecuteInternal(ctx, "BEGIN PESSIMISTIC"); err != nil {
+		return errors.Trace(err)
+	}
+	defer func() {
+		if err != nil {
+			log.Error("create binding failed", zap.String("sql", binding.BindSQL), zap.Error(err))
+			if rollbackErr := db.se.ExecuteInternal(ctx, "ROLLBACK"); rollbackErr != nil {
+				log.Warn("rollback failed", zap.Error(rollbackErr))
+			}
+			return
+		}
+		err = errors.Trace(db.se.ExecuteInternal(ctx, "COMMIT"))
+	}()
+
+	err = db.se.ExecuteInternal(ctx,
+		"UPDATE mysql.bind_info SET status = 'deleted', update_time = NOW(3) "+
+			"WHERE original_sql = %? AND default_db = %? AND status != 'deleted'",
+		binding.OriginalSQL, binding.DefaultDB)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	err = db.se.ExecuteInternal(ctx,
+		"INSERT INTO mysql.bind_info (original_sql, bind_sql, default_db, status, create_time, update_time, "+
+			"charset, collation, source) VALUES (%?, %?, %?, %?, NOW(3), NOW(3), %?, %?, %?)",
+		binding.OriginalSQL, binding.BindSQL, binding.DefaultDB, binding.Status,
+		binding.Charset, binding.Collation, binding.Source)
+	return errors.Trace(err)
+}
+
 // CreatePlacementPolicy check whether cluster support policy and create the policy.
 func (db *DB) CreatePlacementPolicy(ctx context.Context, policy *model.PolicyInfo) error {
 	err := db.se.CreatePlacementPolicy(ctx, policy)
@@ -322,6 +322,38 @@ func TestDB_ExecDDL(t *testing.T) {
 	}
 }
 
+func TestDB_CreateBinding(t *testing.T) {
+	s, clean := createRestoreSchemaSuite(t)
+	defer clean()
+	tk := testkit.NewTestKit(t, s.mock.Storage)
+	tk.MustExec("use test")
+	tk.MustExec("create table t (a int, b int, index idx_a(a), index idx_b(b))")
+
+	ctx := context.Background()
+	db, _, err := restore.NewDB(gluetidb.New(), s.mock.Storage, "STRICT")
+	require.NoError(t, err)
+	defer db.Close()
+
+	binding := &metautil.Binding{
+		OriginalSQL: "select * from `test` . `t` where `a` = ?",
+		BindSQL:     "SELECT * FROM `test`.`t` USE INDEX (`idx_b`) WHERE `a` = 1",
+		DefaultDB:   "test",
+		Status:      "enabled",
+		Charset:     "utf8mb4",
+		Collation:   "utf8mb4_bin",
+		Source:      "manual",
+	}
+	// the binding of the same statement in the cluster is replaced.
+	tk.MustExec("create global binding for select * from t where a = 1 using select * from t use index(idx_a) where a = 1")
+	// restoring the same binding twice doesn't duplicate it.
+	require.NoError(t, db.CreateBinding(ctx, binding))
+	require.NoError(t, db.CreateBinding(ctx, binding))
+	tk.MustQuery("select bind_sql, status, source from mysql.bind_info where original_sql = ? and status != 'deleted'", binding.OriginalSQL).
+		Check(testkit.Rows(binding.BindSQL + " enabled manual"))
+	tk.MustQuery("select count(*) from mysql.bind_info where original_sql = ? and status = 'deleted'", binding.OriginalSQL).
+		Check(testkit.Rows("2"))
+}
+
 func TestFilterDDLJobByRules(t *testing.T) {
 	ddlJobs := []*model.Job{
 		{
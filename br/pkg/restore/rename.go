@@ -0,0 +1,258 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package restore
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pingcap/errors"
+	berrors "github.com/pingcap/tidb/br/pkg/errors"
+	"github.com/pingcap/tidb/br/pkg/metautil"
+	"github.com/pingcap/tidb/br/pkg/utils"
+	"github.com/pingcap/tidb/parser/model"
+)
+
+// RenameRule restores a database or a table of the backup under a new name.
+// The table names are empty if the rule renames a whole database.
+type RenameRule struct {
+	FromDB    string `json:"from-db" toml:"from-db"`
+	FromTable string `json:"from-table" toml:"from-table"`
+	ToDB      string `json:"to-db" toml:"to-db"`
+	ToTable   string `json:"to-table" toml:"to-table"`
+}
+
+// String implements fmt.Stringer.
+func (r RenameRule) String() string {
+	if r.FromTable == "" {
+		return fmt.Sprintf("%s:%s", utils.EncloseName(r.FromDB), utils.EncloseName(r.ToDB))
+	}
+	return fmt.Sprintf("%s:%s", utils.EncloseDBAndTable(r.FromDB, r.FromTable), utils.EncloseDBAndTable(r.ToDB, r.ToTable))
+}
+
+// ParseRenameRule parses a rule in the form of `db.table:new_db.new_table` or `db:new_db`.
+// The names can be quoted by backticks if they contain `.` or `:`.
+func ParseRenameRule(rule string) (RenameRule, error) {
+	fromNames, rest, err := parseQualifiedName(rule, ':')
+	if err != nil {
+		return RenameRule{}, errors.Annotatef(berrors.ErrRestoreInvalidRewrite, "%s in '%s'", err, rule)
+	}
+	if !strings.HasPrefix(rest, ":") {
+		return RenameRule{}, errors.Annotatef(berrors.ErrRestoreInvalidRewrite, "missing ':' in '%s'", rule)
+	}
+	toNames, rest, err := parseQualifiedName(rest[1:], ':')
+	if err != nil {
+		return RenameRule{}, errors.Annotatef(berrors.ErrRestoreInvalidRewrite, "%s in '%s'", err, rule)
+	}
+	if rest != "" {
+		return RenameRule{}, errors.Annotatef(berrors.ErrRestoreInvalidRewrite, "unexpected '%s' in '%s'", rest, rule)
+	}
+	if len(fromNames) != len(toNames) {
+		return RenameRule{}, errors.Annotatef(berrors.ErrRestoreInvalidRewrite,
+			"a database can only be renamed to a database, and a table to a table, got '%s'", rule)
+	}
+	r := RenameRule{FromDB: fromNames[0], ToDB: toNames[0]}
+	if len(fromNames) == 2 {
+		r.FromTable, r.ToTable = fromNames[1], toNames[1]
+	}
+	return r, nil
+}
+
+// parseQualifiedName parses a database name or a `db.table` name at the beginning of s, which
+// ends before the terminator. It returns the names and the rest of s.
+func parseQualifiedName(s string, terminator byte) ([]string, string, error) {
+	var names []string
+	for {
+		var name string
+		if strings.HasPrefix(s, "`") {
+			var sb strings.Builder
+			i := 1
+			for ; i < len(s); i++ {
+				if s[i] != '`' {
+					sb.WriteByte(s[i])
+					continue
+				}
+				if i+1 < len(s) && s[i+1] == '`' {
+					sb.WriteByte('`')
+					i++
+					continue
+				}
+				break
+			}
+			if i >= len(s) {
+				return nil, s, errors.New("unclosed backtick")
+			}
+			name, s = sb.String(), s[i+1:]
+		} else {
+			end := strings.IndexFunc(s, func(r rune) bool {
+				return r == '.' || r == rune(terminator)
+			})
+			if end < 0 {
+				end = len(s)
+			}
+			name, s = s[:end], s[end:]
+		}
+		if name == "" {
+			return nil, s, errors.New("empty name")
+		}
+		names = append(names, name)
+		if !strings.HasPrefix(s, ".") {
+			return names, s, nil
+		}
+		if len(names) == 2 {
+			return nil, s, errors.New("too many '.'")
+		}
+		s = s[1:]
+	}
+}
+
+// RenameMapping maps the databases and tables of the backup to the names to restore them as.
+// A table rule takes precedence over the rule of its database.
+type RenameMapping struct {
+	rules  []RenameRule
+	dbs    map[string]int
+	tables map[UniqueTableName]int
+}
+
+// NewRenameMapping creates a mapping from the rules. The names are matched case-insensitively.
+func NewRenameMapping(rules []RenameRule) (*RenameMapping, error) {
+	m := &RenameMapping{
+		rules:  rules,
+		dbs:    make(map[string]int),
+		tables: make(map[UniqueTableName]int),
+	}
+	for i, r := range rules {
+		if utils.IsSysDB(strings.ToLower(r.FromDB)) || utils.IsSysDB(strings.ToLower(r.ToDB)) {
+			return nil, errors.Annotatef(berrors.ErrRestoreInvalidRewrite, "system database can't be renamed: %s", r)
+		}
+		var dup bool
+		if r.FromTable == "" {
+			key := strings.ToLower(r.FromDB)
+			_, dup = m.dbs[key]
+			m.dbs[key] = i
+		} else {
+			key := UniqueTableName{DB: strings.ToLower(r.FromDB), Table: strings.ToLower(r.FromTable)}
+			_, dup = m.tables[key]
+			m.tables[key] = i
+		}
+		if dup {
+			return nil, errors.Annotatef(berrors.ErrRestoreInvalidRewrite, "duplicated rule for %s", r)
+		}
+	}
+	return m, nil
+}
+
+// Empty returns whether there is no rule in the mapping.
+func (m *RenameMapping) Empty() bool {
+	return m == nil || len(m.rules) == 0
+}
+
+// find returns the index of the rule which applies to the table, or -1 if there isn't one.
+func (m *RenameMapping) find(db, table string) int {
+	if m.Empty() {
+		return -1
+	}
+	if i, ok := m.tables[UniqueTableName{DB: strings.ToLower(db), Table: strings.ToLower(table)}]; ok {
+		return i
+	}
+	if i, ok := m.dbs[strings.ToLower(db)]; ok {
+		return i
+	}
+	return -1
+}
+
+// Target returns the database and table name which the table of the backup is restored as.
+func (m *RenameMapping) Target(db, table model.CIStr) (model.CIStr, model.CIStr) {
+	i := m.find(db.O, table.O)
+	if i < 0 {
+		return db, table
+	}
+	r := m.rules[i]
+	if r.FromTable == "" {
+		return model.NewCIStr(r.ToDB), table
+	}
+	return model.NewCIStr(r.ToDB), model.NewCIStr(r.ToTable)
+}
+
+// Apply renames the tables to restore, and returns the databases which contain the renamed tables.
+// The renamed tables are copies, the schemas and the statistics in the backup are not modified.
+// Every rule must apply to at least one table, and no two tables can be restored as the same name.
+func (m *RenameMapping) Apply(tables []*metautil.Table) ([]*metautil.Table, []*utils.Database, error) {
+	used := make([]bool, len(m.rules))
+	targets := make(map[UniqueTableName]*metautil.Table, len(tables))
+	newDBs := make(map[string]*utils.Database)
+	var dbs []*utils.Database
+	newTables := make([]*metautil.Table, 0, len(tables))
+	for _, t := range tables {
+		table := t
+		// the system tables are restored into the temporary databases and renamed later.
+		if _, isSysDB := utils.GetSysDBName(t.DB.Name); !isSysDB {
+			if i := m.find(t.DB.Name.O, t.Info.Name.O); i >= 0 {
+				used[i] = true
+				table = renameTable(t, m.rules[i], newDBs)
+			}
+		}
+
+		key := UniqueTableName{DB: table.DB.Name.L, Table: table.Info.Name.L}
+		if other, ok := targets[key]; ok {
+			return nil, nil, errors.Annotatef(berrors.ErrRestoreInvalidRewrite,
+				"both %s and %s are restored as %s",
+				utils.EncloseDBAndTable(other.DB.Name.O, other.Info.Name.O),
+				utils.EncloseDBAndTable(t.DB.Name.O, t.Info.Name.O),
+				utils.EncloseDBAndTable(table.DB.Name.O, table.Info.Name.O))
+		}
+		targets[key] = t
+		newTables = append(newTables, table)
+
+		db, ok := newDBs[table.DB.Name.L]
+		if !ok {
+			db = &utils.Database{Info: table.DB}
+			newDBs[table.DB.Name.L] = db
+		}
+		if len(db.Tables) == 0 {
+			dbs = append(dbs, db)
+		}
+		db.Tables = append(db.Tables, table)
+	}
+
+	var unused []string
+	for i, ok := range used {
+		if !ok {
+			unused = append(unused, m.rules[i].String())
+		}
+	}
+	if len(unused) > 0 {
+		sort.Strings(unused)
+		return nil, nil, errors.Annotatef(berrors.ErrUndefinedRestoreDbOrTable,
+			"the rewrite rules %s don't match any table to restore", strings.Join(unused, ", "))
+	}
+	return newTables, dbs, nil
+}
+
+// renameTable copies the table with the new name. The tables renamed into the same database
+// share the same copy of the database info, which is registered in dbs.
+func renameTable(t *metautil.Table, rule RenameRule, dbs map[string]*utils.Database) *metautil.Table {
+	newTable := *t
+	toDB := model.NewCIStr(rule.ToDB)
+	if db, ok := dbs[toDB.L]; ok {
+		newTable.DB = db.Info
+	} else {
+		newTable.DB = t.DB.Clone()
+		newTable.DB.Name = toDB
+		// the tables of the database are created separately.
+		newTable.DB.Tables = nil
+		dbs[toDB.L] = &utils.Database{Info: newTable.DB}
+	}
+	newTable.Info = t.Info.Clone()
+	if rule.FromTable != "" {
+		newTable.Info.Name = model.NewCIStr(rule.ToTable)
+	}
+	if t.Stats != nil {
+		stats := *t.Stats
+		stats.DatabaseName = newTable.DB.Name.O
+		stats.TableName = newTable.Info.Name.O
+		newTable.Stats = &stats
+	}
+	return &newTable
+}
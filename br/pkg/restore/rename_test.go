@@ -0,0 +1,134 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package restore_test
+
+import (
+	"testing"
+
+	"github.com/pingcap/tidb/br/pkg/metautil"
+	"github.com/pingcap/tidb/br/pkg/restore"
+	"github.com/pingcap/tidb/br/pkg/utils"
+	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/statistics/handle"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRenameRule(t *testing.T) {
+	cases := []struct {
+		rule     string
+		expected restore.RenameRule
+	}{
+		{"prod.orders:staging.orders_x", restore.RenameRule{FromDB: "prod", FromTable: "orders", ToDB: "staging", ToTable: "orders_x"}},
+		{"prod:staging", restore.RenameRule{FromDB: "prod", ToDB: "staging"}},
+		{"`a.b`.`c:d`:`e``f`.g", restore.RenameRule{FromDB: "a.b", FromTable: "c:d", ToDB: "e`f", ToTable: "g"}},
+	}
+	for _, c := range cases {
+		r, err := restore.ParseRenameRule(c.rule)
+		require.NoError(t, err, c.rule)
+		require.Equal(t, c.expected, r)
+		// the string form can be parsed back.
+		r, err = restore.ParseRenameRule(r.String())
+		require.NoError(t, err)
+		require.Equal(t, c.expected, r)
+	}
+
+	for _, rule := range []string{"prod", "prod:", ":staging", "prod.orders:staging", "prod:staging.orders", "a.b.c:d.e.f", "`prod:staging", "prod:staging:x"} {
+		_, err := restore.ParseRenameRule(rule)
+		require.Error(t, err, rule)
+		require.Regexp(t, "invalid rewrite rule", err.Error())
+	}
+}
+
+func TestNewRenameMapping(t *testing.T) {
+	_, err := restore.NewRenameMapping([]restore.RenameRule{
+		{FromDB: "prod", ToDB: "staging"},
+		{FromDB: "PROD", ToDB: "staging2"},
+	})
+	require.Regexp(t, "duplicated rule", err.Error())
+	_, err = restore.NewRenameMapping([]restore.RenameRule{{FromDB: "mysql", ToDB: "mysql2"}})
+	require.Regexp(t, "system database can't be renamed", err.Error())
+
+	m, err := restore.NewRenameMapping([]restore.RenameRule{
+		{FromDB: "prod", ToDB: "staging"},
+		{FromDB: "prod", FromTable: "orders", ToDB: "audit", ToTable: "orders_x"},
+	})
+	require.NoError(t, err)
+	db, tbl := m.Target(model.NewCIStr("Prod"), model.NewCIStr("Orders"))
+	require.Equal(t, "audit.orders_x", db.O+"."+tbl.O)
+	db, tbl = m.Target(model.NewCIStr("prod"), model.NewCIStr("users"))
+	require.Equal(t, "staging.users", db.O+"."+tbl.O)
+	db, tbl = m.Target(model.NewCIStr("test"), model.NewCIStr("users"))
+	require.Equal(t, "test.users", db.O+"."+tbl.O)
+}
+
+func newRenameTestTable(db *model.DBInfo, id int64, name string) *metautil.Table {
+	return &metautil.Table{
+		DB: db,
+		Info: &model.TableInfo{
+			ID:   id,
+			Name: model.NewCIStr(name),
+			Partition: &model.PartitionInfo{
+				Definitions: []model.PartitionDefinition{{ID: id + 1, Name: model.NewCIStr("p0")}},
+			},
+		},
+		Stats: &handle.JSONTable{DatabaseName: db.Name.O, TableName: name},
+	}
+}
+
+func TestRenameMappingApply(t *testing.T) {
+	prod := &model.DBInfo{ID: 1, Name: model.NewCIStr("prod"), Charset: "utf8mb4"}
+	test := &model.DBInfo{ID: 2, Name: model.NewCIStr("test")}
+	sys := &model.DBInfo{ID: 3, Name: utils.TemporaryDBName("mysql")}
+	tables := []*metautil.Table{
+		newRenameTestTable(prod, 10, "orders"),
+		newRenameTestTable(prod, 20, "users"),
+		newRenameTestTable(test, 30, "t"),
+		newRenameTestTable(sys, 40, "user"),
+	}
+
+	m, err := restore.NewRenameMapping([]restore.RenameRule{
+		{FromDB: "prod", FromTable: "orders", ToDB: "staging", ToTable: "orders_20261015"},
+		{FromDB: "prod", ToDB: "staging"},
+	})
+	require.NoError(t, err)
+	newTables, dbs, err := m.Apply(tables)
+	require.NoError(t, err)
+	require.Len(t, newTables, 4)
+
+	orders := newTables[0]
+	require.Equal(t, "staging", orders.DB.Name.O)
+	require.Equal(t, "utf8mb4", orders.DB.Charset)
+	require.Equal(t, "orders_20261015", orders.Info.Name.O)
+	// the table ID and the partitions are kept to build the rewrite rules of the keys.
+	require.Equal(t, int64(10), orders.Info.ID)
+	require.Equal(t, int64(11), orders.Info.Partition.Definitions[0].ID)
+	require.Equal(t, "staging", orders.Stats.DatabaseName)
+	require.Equal(t, "orders_20261015", orders.Stats.TableName)
+	require.Same(t, orders.DB, newTables[1].DB)
+	require.Equal(t, "users", newTables[1].Info.Name.O)
+	require.Same(t, tables[2], newTables[2])
+	require.Same(t, tables[3], newTables[3])
+
+	// the backup isn't modified.
+	require.Equal(t, "prod", tables[0].DB.Name.O)
+	require.Equal(t, "orders", tables[0].Info.Name.O)
+	require.Equal(t, "orders", tables[0].Stats.TableName)
+
+	require.Len(t, dbs, 3)
+	require.Equal(t, "staging", dbs[0].Info.Name.O)
+	require.Len(t, dbs[0].Tables, 2)
+	require.Equal(t, "test", dbs[1].Info.Name.O)
+	require.Equal(t, sys.Name, dbs[2].Info.Name)
+
+	// two tables can't be restored as the same one.
+	m, err = restore.NewRenameMapping([]restore.RenameRule{{FromDB: "prod", FromTable: "users", ToDB: "prod", ToTable: "orders"}})
+	require.NoError(t, err)
+	_, _, err = m.Apply(tables)
+	require.Regexp(t, "both `prod`.`orders` and `prod`.`users` are restored as `prod`.`orders`", err.Error())
+
+	// the rule must match a table to restore.
+	m, err = restore.NewRenameMapping([]restore.RenameRule{{FromDB: "prod", FromTable: "order", ToDB: "staging", ToTable: "order"}})
+	require.NoError(t, err)
+	_, _, err = m.Apply(tables)
+	require.Regexp(t, "don't match any table to restore", err.Error())
+}
@@ -377,8 +377,8 @@ func (s *AzureBlobStorage) Create(ctx context.Context, name string) (ExternalFil
 		accessTier: s.accessTier,
 	}
 
-	uploaderWriter := newBufferedWriter(uploader, azblob.BlockBlobMaxUploadBlobBytes, NoCompression)
-	return uploaderWriter, nil
+	uploaderWriter, err := newBufferedWriter(uploader, azblob.BlockBlobMaxUploadBlobBytes, NoCompression, DefaultCompressLevel)
+	return uploaderWriter, errors.Trace(err)
 }
 
 func (s *AzureBlobStorage) Rename(ctx context.Context, oldFileName, newFileName string) error {
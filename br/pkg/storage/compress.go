@@ -15,21 +15,30 @@ import (
 type withCompression struct {
 	ExternalStorage
 	compressType CompressType
+	level        int
 }
 
 // WithCompression returns an ExternalStorage with compress option
 func WithCompression(inner ExternalStorage, compressionType CompressType) ExternalStorage {
+	return WithCompressionLevel(inner, compressionType, DefaultCompressLevel)
+}
+
+// WithCompressionLevel returns an ExternalStorage with compress option and the compression level.
+// The level is only used by Gzip and Zstd, DefaultCompressLevel means the default level of them.
+func WithCompressionLevel(inner ExternalStorage, compressionType CompressType, level int) ExternalStorage {
 	if compressionType == NoCompression {
 		return inner
 	}
-	return &withCompression{ExternalStorage: inner, compressType: compressionType}
+	return &withCompression{ExternalStorage: inner, compressType: compressionType, level: level}
 }
 
 func (w *withCompression) Create(ctx context.Context, name string) (ExternalFileWriter, error) {
-	var (
-		writer ExternalFileWriter
-		err    error
-	)
+	// create the compress buffer first so that no file is left if the compression level is invalid.
+	buf, err := newInterceptBuffer(hardcodedS3ChunkSize, w.compressType, w.level)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var writer ExternalFileWriter
 	if s3Storage, ok := w.ExternalStorage.(*S3Storage); ok {
 		writer, err = s3Storage.CreateUploader(ctx, name)
 	} else {
@@ -38,8 +47,7 @@ func (w *withCompression) Create(ctx context.Context, name string) (ExternalFile
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	compressedWriter := newBufferedWriter(writer, hardcodedS3ChunkSize, w.compressType)
-	return compressedWriter, nil
+	return &bufferedWriter{writer: writer, buf: buf}, nil
 }
 
 func (w *withCompression) Open(ctx context.Context, path string) (ExternalFileReader, error) {
@@ -56,8 +64,11 @@ func (w *withCompression) Open(ctx context.Context, path string) (ExternalFileRe
 
 func (w *withCompression) WriteFile(ctx context.Context, name string, data []byte) error {
 	bf := bytes.NewBuffer(make([]byte, 0, len(data)))
-	compressBf := newCompressWriter(w.compressType, bf)
-	_, err := compressBf.Write(data)
+	compressBf, err := newCompressWriter(w.compressType, w.level, bf)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	_, err = compressBf.Write(data)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -20,24 +20,63 @@ func TestWithCompressReadWriteFile(t *testing.T) {
 	ctx := context.Background()
 	storage, err := Create(ctx, backend, true)
 	require.NoError(t, err)
-	storage = WithCompression(storage, Gzip)
 	name := "with compress test"
 	content := "hello,world!"
-	fileName := strings.ReplaceAll(name, " ", "-") + ".txt.gz"
-	err = storage.WriteFile(ctx, fileName, []byte(content))
-	require.NoError(t, err)
+	for _, compressType := range []CompressType{Gzip, Snappy, Zstd} {
+		s := WithCompression(storage, compressType)
+		fileName := strings.ReplaceAll(name, " ", "-") + ".txt.compressed"
+		err = s.WriteFile(ctx, fileName, []byte(content))
+		require.NoError(t, err)
 
-	// make sure compressed file is written correctly
-	file, err := os.Open(filepath.Join(dir, fileName))
-	require.NoError(t, err)
-	uncompressedFile, err := newCompressReader(Gzip, file)
+		// make sure compressed file is written correctly
+		file, err := os.Open(filepath.Join(dir, fileName))
+		require.NoError(t, err)
+		uncompressedFile, err := newCompressReader(compressType, file)
+		require.NoError(t, err)
+		newContent, err := io.ReadAll(uncompressedFile)
+		require.NoError(t, err)
+		require.Equal(t, content, string(newContent))
+		require.NoError(t, file.Close())
+
+		// test withCompression ReadFile
+		newContent, err = s.ReadFile(ctx, fileName)
+		require.NoError(t, err)
+		require.Equal(t, content, string(newContent))
+	}
+}
+
+func TestWithCompressionLevel(t *testing.T) {
+	dir := t.TempDir()
+	backend, err := ParseBackend("local://"+filepath.ToSlash(dir), nil)
 	require.NoError(t, err)
-	newContent, err := io.ReadAll(uncompressedFile)
+	ctx := context.Background()
+	storage, err := Create(ctx, backend, true)
 	require.NoError(t, err)
-	require.Equal(t, content, string(newContent))
+	content := []byte(strings.Repeat("hello,world!", 1024))
 
-	// test withCompression ReadFile
-	newContent, err = storage.ReadFile(ctx, fileName)
-	require.NoError(t, err)
-	require.Equal(t, content, string(newContent))
+	sizes := make([]int64, 0, 2)
+	for _, level := range []int{1, 19} {
+		s := WithCompressionLevel(storage, Zstd, level)
+		writer, err := s.Create(ctx, "level.txt.zst")
+		require.NoError(t, err)
+		_, err = writer.Write(ctx, content)
+		require.NoError(t, err)
+		require.NoError(t, writer.Close(ctx))
+
+		r, err := s.Open(ctx, "level.txt.zst")
+		require.NoError(t, err)
+		newContent, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, content, newContent)
+		require.NoError(t, r.Close())
+
+		info, err := os.Stat(filepath.Join(dir, "level.txt.zst"))
+		require.NoError(t, err)
+		sizes = append(sizes, info.Size())
+	}
+	require.LessOrEqual(t, sizes[1], sizes[0])
+
+	// an invalid gzip level is rejected
+	_, err = WithCompressionLevel(storage, Gzip, 100).Create(ctx, "invalid.txt.gz")
+	require.Error(t, err)
 }
@@ -34,6 +34,10 @@ func (l *LocalStorage) DeleteFile(ctx context.Context, name string) error {
 func (l *LocalStorage) WriteFile(ctx context.Context, name string, data []byte) error {
 	// because `os.WriteFile` is not atomic, directly write into it may reset the file
 	// to an empty file if write is not finished.
+	// the file may be put in a sub directory, e.g. the log backup files.
+	if err := l.ensureDir(name); err != nil {
+		return errors.Trace(err)
+	}
 	tmpPath := filepath.Join(l.base, name) + ".tmp"
 	if err := os.WriteFile(tmpPath, data, localFilePerm); err != nil {
 		return errors.Trace(err)
@@ -105,6 +109,9 @@ func (l *LocalStorage) Open(ctx context.Context, path string) (ExternalFileReade
 
 // Create implements ExternalStorage interface.
 func (l *LocalStorage) Create(ctx context.Context, name string) (ExternalFileWriter, error) {
+	if err := l.ensureDir(name); err != nil {
+		return nil, errors.Trace(err)
+	}
 	file, err := os.Create(filepath.Join(l.base, name))
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -118,6 +125,16 @@ func (l *LocalStorage) Rename(ctx context.Context, oldFileName, newFileName stri
 	return errors.Trace(os.Rename(filepath.Join(l.base, oldFileName), filepath.Join(l.base, newFileName)))
 }
 
+// ensureDir creates the parent directory of the file if it doesn't exist.
+func (l *LocalStorage) ensureDir(name string) error {
+	dir := filepath.Dir(filepath.Join(l.base, name))
+	ok, err := pathExists(dir)
+	if err != nil || ok {
+		return errors.Trace(err)
+	}
+	return mkdirAll(dir)
+}
+
 func pathExists(_path string) (bool, error) {
 	_, err := os.Stat(_path)
 	if err != nil {
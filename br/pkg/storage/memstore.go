@@ -0,0 +1,145 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pingcap/errors"
+)
+
+// MemStorage represents the storage which keeps all the files in memory.
+// It's used to hold the small generated files, e.g. the schema files of the tables
+// which are read from a database directly.
+type MemStorage struct {
+	mu    sync.RWMutex
+	files map[string][]byte
+}
+
+// NewMemStorage creates an empty MemStorage.
+func NewMemStorage() *MemStorage {
+	return &MemStorage{files: make(map[string][]byte)}
+}
+
+// DeleteFile delete the file in storage
+func (s *MemStorage) DeleteFile(ctx context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.files[name]; !ok {
+		return errors.Annotatef(os.ErrNotExist, "file '%s'", name)
+	}
+	delete(s.files, name)
+	return nil
+}
+
+// WriteFile file to storage.
+func (s *MemStorage) WriteFile(ctx context.Context, name string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[name] = append([]byte(nil), data...)
+	return nil
+}
+
+// ReadFile storage file.
+func (s *MemStorage) ReadFile(ctx context.Context, name string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data, ok := s.files[name]
+	if !ok {
+		return nil, errors.Annotatef(os.ErrNotExist, "file '%s'", name)
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// FileExists return true if file exists.
+func (s *MemStorage) FileExists(ctx context.Context, name string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.files[name]
+	return ok, nil
+}
+
+// Open a Reader by file path.
+func (s *MemStorage) Open(ctx context.Context, name string) (ExternalFileReader, error) {
+	data, err := s.ReadFile(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return memFileReader{Reader: bytes.NewReader(data)}, nil
+}
+
+// WalkDir traverse all the files in a dir, the files are visited in lexical order.
+func (s *MemStorage) WalkDir(ctx context.Context, opt *WalkOption, fn func(string, int64) error) error {
+	prefix := ""
+	if opt != nil && opt.SubDir != "" {
+		prefix = strings.TrimSuffix(opt.SubDir, "/") + "/"
+	}
+	s.mu.RLock()
+	names := make([]string, 0, len(s.files))
+	sizes := make(map[string]int64, len(s.files))
+	for name, data := range s.files {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+			sizes[name] = int64(len(data))
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Strings(names)
+	for _, name := range names {
+		if err := fn(name, sizes[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// URI returns the base path as a URI.
+func (s *MemStorage) URI() string {
+	return "memstore:///"
+}
+
+// Create implements ExternalStorage interface, the file is visible after the writer is closed.
+func (s *MemStorage) Create(ctx context.Context, name string) (ExternalFileWriter, error) {
+	return &memFileWriter{storage: s, name: name}, nil
+}
+
+// Rename implements ExternalStorage interface.
+func (s *MemStorage) Rename(ctx context.Context, oldFileName, newFileName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.files[oldFileName]
+	if !ok {
+		return errors.Annotatef(os.ErrNotExist, "file '%s'", oldFileName)
+	}
+	delete(s.files, oldFileName)
+	s.files[newFileName] = data
+	return nil
+}
+
+type memFileReader struct {
+	*bytes.Reader
+}
+
+func (memFileReader) Close() error {
+	return nil
+}
+
+type memFileWriter struct {
+	storage *MemStorage
+	name    string
+	buf     bytes.Buffer
+}
+
+func (w *memFileWriter) Write(ctx context.Context, p []byte) (int, error) {
+	return w.buf.Write(p)
+}
+
+func (w *memFileWriter) Close(ctx context.Context) error {
+	return w.storage.WriteFile(ctx, w.name, w.buf.Bytes())
+}
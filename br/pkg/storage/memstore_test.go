@@ -0,0 +1,56 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package storage
+
+import (
+	"context"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/pingcap/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemStorage(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemStorage()
+
+	require.NoError(t, store.WriteFile(ctx, "db/t.1.sql", []byte("abc")))
+	w, err := store.Create(ctx, "db/t.0.sql")
+	require.NoError(t, err)
+	_, err = w.Write(ctx, []byte("12345"))
+	require.NoError(t, err)
+	exists, err := store.FileExists(ctx, "db/t.0.sql")
+	require.NoError(t, err)
+	require.False(t, exists)
+	require.NoError(t, w.Close(ctx))
+	require.NoError(t, store.WriteFile(ctx, "other", nil))
+
+	var names []string
+	var sizes []int64
+	require.NoError(t, store.WalkDir(ctx, &WalkOption{SubDir: "db"}, func(name string, size int64) error {
+		names = append(names, name)
+		sizes = append(sizes, size)
+		return nil
+	}))
+	require.Equal(t, []string{"db/t.0.sql", "db/t.1.sql"}, names)
+	require.Equal(t, []int64{5, 3}, sizes)
+
+	r, err := store.Open(ctx, "db/t.0.sql")
+	require.NoError(t, err)
+	_, err = r.Seek(2, io.SeekStart)
+	require.NoError(t, err)
+	content, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, "345", string(content))
+	require.NoError(t, r.Close())
+
+	require.NoError(t, store.Rename(ctx, "db/t.1.sql", "db/t.2.sql"))
+	content, err = store.ReadFile(ctx, "db/t.2.sql")
+	require.NoError(t, err)
+	require.Equal(t, "abc", string(content))
+	require.NoError(t, store.DeleteFile(ctx, "db/t.2.sql"))
+	_, err = store.ReadFile(ctx, "db/t.2.sql")
+	require.True(t, os.IsNotExist(errors.Cause(err)))
+}
@@ -773,8 +773,8 @@ func (rs *S3Storage) Create(ctx context.Context, name string) (ExternalFileWrite
 	if err != nil {
 		return nil, err
 	}
-	uploaderWriter := newBufferedWriter(uploader, hardcodedS3ChunkSize, NoCompression)
-	return uploaderWriter, nil
+	uploaderWriter, err := newBufferedWriter(uploader, hardcodedS3ChunkSize, NoCompression, DefaultCompressLevel)
+	return uploaderWriter, errors.Trace(err)
 }
 
 // Rename implements ExternalStorage interface.
@@ -6,6 +6,8 @@ import (
 	"context"
 	"io"
 
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
 	"github.com/pingcap/errors"
 )
 
@@ -17,8 +19,15 @@ const (
 	NoCompression CompressType = iota
 	// Gzip will compress given bytes in gzip format.
 	Gzip
+	// Snappy will compress given bytes in snappy framing format.
+	Snappy
+	// Zstd will compress given bytes in zstd format.
+	Zstd
 )
 
+// DefaultCompressLevel uses the default compression level of the compress type.
+const DefaultCompressLevel = 0
+
 type flusher interface {
 	Flush() error
 }
@@ -39,19 +48,34 @@ type interceptBuffer interface {
 	Compressed() bool
 }
 
-func newInterceptBuffer(chunkSize int, compressType CompressType) interceptBuffer {
+func newInterceptBuffer(chunkSize int, compressType CompressType, level int) (interceptBuffer, error) {
 	if compressType == NoCompression {
-		return newNoCompressionBuffer(chunkSize)
+		return newNoCompressionBuffer(chunkSize), nil
 	}
-	return newSimpleCompressBuffer(chunkSize, compressType)
+	return newSimpleCompressBuffer(chunkSize, compressType, level)
 }
 
-func newCompressWriter(compressType CompressType, w io.Writer) simpleCompressWriter {
+// newCompressWriter creates a compress writer, the level is ignored by the compress types
+// which don't support it.
+func newCompressWriter(compressType CompressType, level int, w io.Writer) (simpleCompressWriter, error) {
 	switch compressType {
 	case Gzip:
-		return gzip.NewWriter(w)
+		if level == DefaultCompressLevel {
+			return gzip.NewWriter(w), nil
+		}
+		writer, err := gzip.NewWriterLevel(w, level)
+		return writer, errors.Trace(err)
+	case Snappy:
+		return snappy.NewBufferedWriter(w), nil
+	case Zstd:
+		opts := []zstd.EOption{zstd.WithEncoderConcurrency(1)}
+		if level != DefaultCompressLevel {
+			opts = append(opts, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+		}
+		writer, err := zstd.NewWriter(w, opts...)
+		return writer, errors.Trace(err)
 	default:
-		return nil
+		return nil, errors.Errorf("unsupported compress type %d", compressType)
 	}
 }
 
@@ -59,8 +83,16 @@ func newCompressReader(compressType CompressType, r io.Reader) (io.ReadCloser, e
 	switch compressType {
 	case Gzip:
 		return gzip.NewReader(r)
+	case Snappy:
+		return io.NopCloser(snappy.NewReader(r)), nil
+	case Zstd:
+		decoder, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return decoder.IOReadCloser(), nil
 	default:
-		return nil, nil
+		return nil, errors.Errorf("unsupported compress type %d", compressType)
 	}
 }
 
@@ -124,13 +156,17 @@ func (b *simpleCompressBuffer) Compressed() bool {
 	return true
 }
 
-func newSimpleCompressBuffer(chunkSize int, compressType CompressType) *simpleCompressBuffer {
+func newSimpleCompressBuffer(chunkSize int, compressType CompressType, level int) (*simpleCompressBuffer, error) {
 	bf := bytes.NewBuffer(make([]byte, 0, chunkSize))
+	compressWriter, err := newCompressWriter(compressType, level, bf)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 	return &simpleCompressBuffer{
 		Buffer:         bf,
 		cap:            chunkSize,
-		compressWriter: newCompressWriter(compressType, bf),
-	}
+		compressWriter: compressWriter,
+	}, nil
 }
 
 type bufferedWriter struct {
@@ -190,16 +226,20 @@ func (u *bufferedWriter) Close(ctx context.Context) error {
 }
 
 // NewUploaderWriter wraps the Writer interface over an uploader.
-func NewUploaderWriter(writer ExternalFileWriter, chunkSize int, compressType CompressType) ExternalFileWriter {
-	return newBufferedWriter(writer, chunkSize, compressType)
+func NewUploaderWriter(writer ExternalFileWriter, chunkSize int, compressType CompressType) (ExternalFileWriter, error) {
+	return newBufferedWriter(writer, chunkSize, compressType, DefaultCompressLevel)
 }
 
 // newBufferedWriter is used to build a buffered writer.
-func newBufferedWriter(writer ExternalFileWriter, chunkSize int, compressType CompressType) *bufferedWriter {
+func newBufferedWriter(writer ExternalFileWriter, chunkSize int, compressType CompressType, level int) (*bufferedWriter, error) {
+	buf, err := newInterceptBuffer(chunkSize, compressType, level)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 	return &bufferedWriter{
 		writer: writer,
-		buf:    newInterceptBuffer(chunkSize, compressType),
-	}
+		buf:    buf,
+	}, nil
 }
 
 // BytesWriter is a Writer implementation on top of bytes.Buffer that is useful for testing.
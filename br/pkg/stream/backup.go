@@ -0,0 +1,193 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package stream
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pingcap/errors"
+	backuppb "github.com/pingcap/kvproto/pkg/brpb"
+	"github.com/pingcap/log"
+	"github.com/pingcap/tidb/br/pkg/metautil"
+	"github.com/pingcap/tidb/br/pkg/storage"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// DefaultFlushInterval is the default interval of flushing the changes to the storage.
+	DefaultFlushInterval = time.Minute
+	// maxBufferSize is the size of the buffered changes which triggers a flush.
+	maxBufferSize = 128 * 1024 * 1024
+	logFileSuffix = ".log"
+)
+
+// LogBackup backs up the changes emitted by an EventSource into the external storage.
+// The changes are buffered and flushed periodically, each flush writes a data file
+// and a metadata file whose EndVersion is the new checkpoint ts.
+type LogBackup struct {
+	storage       storage.ExternalStorage
+	source        EventSource
+	flushInterval time.Duration
+	onCheckpoint  func(ctx context.Context, checkpoint uint64) error
+
+	events     []*KVEvent
+	bufferSize int
+	checkpoint uint64
+	resolved   uint64
+}
+
+// NewLogBackup creates a LogBackup.
+func NewLogBackup(s storage.ExternalStorage, source EventSource, flushInterval time.Duration) *LogBackup {
+	if flushInterval <= 0 {
+		flushInterval = DefaultFlushInterval
+	}
+	return &LogBackup{
+		storage:       s,
+		source:        source,
+		flushInterval: flushInterval,
+	}
+}
+
+// SetCheckpointCallback sets the callback which is called after the checkpoint advances.
+func (b *LogBackup) SetCheckpointCallback(fn func(ctx context.Context, checkpoint uint64) error) {
+	b.onCheckpoint = fn
+}
+
+// Checkpoint returns the checkpoint ts of the log backup.
+func (b *LogBackup) Checkpoint() uint64 {
+	return b.checkpoint
+}
+
+// Run backs up the changes committed after the checkpoint until the context is done.
+// The buffered changes are flushed before it returns.
+func (b *LogBackup) Run(ctx context.Context, checkpoint uint64) error {
+	b.checkpoint = checkpoint
+	b.resolved = checkpoint
+	ch := make(chan *Event, 1024)
+	eg, ectx := errgroup.WithContext(ctx)
+	eg.Go(func() error {
+		return b.source.Watch(ectx, checkpoint, ch)
+	})
+	eg.Go(func() error {
+		return b.loop(ectx, ch)
+	})
+	err := eg.Wait()
+	if ctx.Err() == nil {
+		return errors.Trace(err)
+	}
+	// The task is stopped, persist the changes received so far.
+	return errors.Trace(b.flush(context.Background()))
+}
+
+func (b *LogBackup) loop(ctx context.Context, ch <-chan *Event) error {
+	ticker := time.NewTicker(b.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := b.flush(ctx); err != nil {
+				return errors.Trace(err)
+			}
+		case e := <-ch:
+			if e.KV == nil {
+				if e.ResolvedTS > b.resolved {
+					b.resolved = e.ResolvedTS
+				}
+				continue
+			}
+			// The changes before the checkpoint have been backed up.
+			if e.KV.CommitTS <= b.checkpoint {
+				continue
+			}
+			b.events = append(b.events, e.KV)
+			b.bufferSize += e.KV.size()
+			if b.bufferSize >= maxBufferSize {
+				if err := b.flush(ctx); err != nil {
+					return errors.Trace(err)
+				}
+			}
+		}
+	}
+}
+
+// flush writes the buffered changes and advances the checkpoint to the resolved ts.
+func (b *LogBackup) flush(ctx context.Context) error {
+	if len(b.events) == 0 && b.resolved <= b.checkpoint {
+		return nil
+	}
+	meta := &backuppb.BackupMeta{
+		StartVersion: b.checkpoint,
+		EndVersion:   b.resolved,
+	}
+	if b.resolved < b.checkpoint {
+		meta.EndVersion = b.checkpoint
+	}
+	if len(b.events) > 0 {
+		file, err := b.writeDataFile(ctx)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		meta.Files = []*backuppb.File{file}
+	}
+	id := uuid.New().String()
+	if err := metautil.WriteLogMeta(ctx, b.storage, &metautil.LogMeta{
+		Name: metautil.LogMetaFileName(meta.EndVersion, id),
+		Meta: meta,
+	}); err != nil {
+		return errors.Trace(err)
+	}
+	log.Info("flushed log backup",
+		zap.Int("events", len(b.events)),
+		zap.Uint64("checkpoint", meta.EndVersion))
+	b.events = b.events[:0]
+	b.bufferSize = 0
+	if meta.EndVersion <= b.checkpoint {
+		return nil
+	}
+	b.checkpoint = meta.EndVersion
+	if b.onCheckpoint != nil {
+		return errors.Trace(b.onCheckpoint(ctx, b.checkpoint))
+	}
+	return nil
+}
+
+func (b *LogBackup) writeDataFile(ctx context.Context) (*backuppb.File, error) {
+	sort.SliceStable(b.events, func(i, j int) bool {
+		return b.events[i].CommitTS < b.events[j].CommitTS
+	})
+	data := encodeKVEvents(b.events)
+	checksum := sha256.Sum256(data)
+	file := &backuppb.File{
+		Name:         path.Join(metautil.LogDataDir, fmt.Sprintf("%016X", b.checkpoint), uuid.New().String()+logFileSuffix),
+		Sha256:       checksum[:],
+		StartKey:     b.events[0].Key,
+		EndKey:       b.events[0].Key,
+		StartVersion: b.events[0].CommitTS,
+		EndVersion:   b.events[len(b.events)-1].CommitTS,
+		TotalKvs:     uint64(len(b.events)),
+		TotalBytes:   uint64(b.bufferSize),
+		Size_:        uint64(len(data)),
+	}
+	for _, e := range b.events {
+		if bytes.Compare(e.Key, file.StartKey) < 0 {
+			file.StartKey = e.Key
+		}
+		if bytes.Compare(e.Key, file.EndKey) > 0 {
+			file.EndKey = e.Key
+		}
+	}
+	if err := b.storage.WriteFile(ctx, file.Name, data); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return file, nil
+}
@@ -0,0 +1,126 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package stream
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pingcap/tidb/br/pkg/metautil"
+	"github.com/pingcap/tidb/br/pkg/storage"
+	"github.com/stretchr/testify/require"
+)
+
+// mockEventSource emits the events and then waits for the context done.
+type mockEventSource struct {
+	events []*Event
+}
+
+func (s *mockEventSource) Watch(ctx context.Context, checkpointTS uint64, ch chan<- *Event) error {
+	for _, e := range s.events {
+		if e.KV != nil && e.KV.CommitTS <= checkpointTS {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ch <- e:
+		}
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func putEvent(key, value string, commitTS uint64) *Event {
+	return &Event{KV: &KVEvent{Key: []byte(key), Value: []byte(value), Op: OpPut, StartTS: commitTS - 1, CommitTS: commitTS}}
+}
+
+func deleteEvent(key string, commitTS uint64) *Event {
+	return &Event{KV: &KVEvent{Key: []byte(key), Op: OpDelete, StartTS: commitTS - 1, CommitTS: commitTS}}
+}
+
+func runLogBackup(t *testing.T, s storage.ExternalStorage, source EventSource, checkpoint, until uint64) uint64 {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	backup := NewLogBackup(s, source, 10*time.Millisecond)
+	done := make(chan struct{})
+	backup.SetCheckpointCallback(func(_ context.Context, checkpoint uint64) error {
+		if checkpoint >= until {
+			close(done)
+		}
+		return nil
+	})
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- backup.Run(ctx, checkpoint)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "log backup doesn't advance the checkpoint")
+	}
+	cancel()
+	require.NoError(t, <-errCh)
+	return backup.Checkpoint()
+}
+
+func TestLogBackup(t *testing.T) {
+	ctx := context.Background()
+	s, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+
+	source := &mockEventSource{events: []*Event{
+		putEvent("b", "1", 12),
+		putEvent("a", "1", 11),
+		{ResolvedTS: 15},
+		deleteEvent("b", 16),
+		{ResolvedTS: 20},
+	}}
+	require.Equal(t, uint64(20), runLogBackup(t, s, source, 10, 20))
+
+	metas, err := metautil.ReadLogMetas(ctx, s)
+	require.NoError(t, err)
+	require.Equal(t, uint64(20), metautil.LogCheckpoint(metas))
+	var events []*KVEvent
+	for _, meta := range metas {
+		require.LessOrEqual(t, meta.Meta.StartVersion, meta.Meta.EndVersion)
+		for _, file := range meta.Meta.Files {
+			data, err := s.ReadFile(ctx, file.Name)
+			require.NoError(t, err)
+			fileEvents, err := decodeKVEvents(data)
+			require.NoError(t, err)
+			require.Equal(t, file.TotalKvs, uint64(len(fileEvents)))
+			events = append(events, fileEvents...)
+		}
+	}
+	require.Len(t, events, 3)
+	// The events in a file are sorted by the commit ts.
+	require.Equal(t, []byte("a"), events[0].Key)
+
+	// Resume the task from the checkpoint, the changes before it are not backed up again.
+	source.events = append(source.events, putEvent("c", "1", 25), &Event{ResolvedTS: 30})
+	require.Equal(t, uint64(30), runLogBackup(t, s, source, 20, 30))
+	metas, err = metautil.ReadLogMetas(ctx, s)
+	require.NoError(t, err)
+	total := 0
+	for _, meta := range metas {
+		for _, file := range meta.Meta.Files {
+			total += int(file.TotalKvs)
+		}
+	}
+	require.Equal(t, 4, total)
+
+	// Truncate the files before the ts.
+	removed, err := Truncate(ctx, s, 25)
+	require.NoError(t, err)
+	require.Greater(t, removed, 0)
+	metas, err = metautil.ReadLogMetas(ctx, s)
+	require.NoError(t, err)
+	require.Equal(t, uint64(30), metautil.LogCheckpoint(metas))
+	for _, meta := range metas {
+		for _, file := range meta.Meta.Files {
+			require.GreaterOrEqual(t, file.EndVersion, uint64(25))
+		}
+	}
+}
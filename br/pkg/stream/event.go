@@ -0,0 +1,100 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package stream
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/pingcap/errors"
+	berrors "github.com/pingcap/tidb/br/pkg/errors"
+)
+
+// OpType is the type of the change of a key.
+type OpType byte
+
+const (
+	// OpPut means the key is put.
+	OpPut OpType = iota + 1
+	// OpDelete means the key is deleted.
+	OpDelete
+)
+
+// KVEvent is a committed change of a key.
+type KVEvent struct {
+	Key      []byte
+	Value    []byte
+	Op       OpType
+	StartTS  uint64
+	CommitTS uint64
+}
+
+// Event is emitted by an EventSource. It's either a committed change, or a resolved ts
+// which means all the changes committed before it have been emitted.
+type Event struct {
+	KV         *KVEvent
+	ResolvedTS uint64
+}
+
+// size returns the approximate memory size of the event.
+func (e *KVEvent) size() int {
+	return len(e.Key) + len(e.Value) + 17
+}
+
+// encodeKVEvents encodes the events into the content of a log file. Each event is encoded as
+// op(1) | start ts(8) | commit ts(8) | key length(uvarint) | key | value length(uvarint) | value.
+func encodeKVEvents(events []*KVEvent) []byte {
+	var buf bytes.Buffer
+	var tmp [binary.MaxVarintLen64]byte
+	for _, e := range events {
+		buf.WriteByte(byte(e.Op))
+		binary.BigEndian.PutUint64(tmp[:], e.StartTS)
+		buf.Write(tmp[:8])
+		binary.BigEndian.PutUint64(tmp[:], e.CommitTS)
+		buf.Write(tmp[:8])
+		n := binary.PutUvarint(tmp[:], uint64(len(e.Key)))
+		buf.Write(tmp[:n])
+		buf.Write(e.Key)
+		n = binary.PutUvarint(tmp[:], uint64(len(e.Value)))
+		buf.Write(tmp[:n])
+		buf.Write(e.Value)
+	}
+	return buf.Bytes()
+}
+
+// decodeKVEvents decodes the events from the content of a log file.
+func decodeKVEvents(data []byte) ([]*KVEvent, error) {
+	var events []*KVEvent
+	readBytes := func() ([]byte, error) {
+		l, n := binary.Uvarint(data)
+		if n <= 0 || uint64(len(data)-n) < l {
+			return nil, errors.Annotate(berrors.ErrPiTRInvalidLogFile, "truncated event")
+		}
+		b := data[n : n+int(l)]
+		data = data[n+int(l):]
+		return b, nil
+	}
+	for len(data) > 0 {
+		if len(data) < 17 {
+			return nil, errors.Annotate(berrors.ErrPiTRInvalidLogFile, "truncated event")
+		}
+		e := &KVEvent{
+			Op:       OpType(data[0]),
+			StartTS:  binary.BigEndian.Uint64(data[1:9]),
+			CommitTS: binary.BigEndian.Uint64(data[9:17]),
+		}
+		if e.Op != OpPut && e.Op != OpDelete {
+			return nil, errors.Annotatef(berrors.ErrPiTRInvalidLogFile, "unknown op type %d", e.Op)
+		}
+		data = data[17:]
+		var err error
+		if e.Key, err = readBytes(); err != nil {
+			return nil, err
+		}
+		if e.Value, err = readBytes(); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
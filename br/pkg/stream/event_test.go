@@ -0,0 +1,49 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package stream
+
+import (
+	"testing"
+
+	berrors "github.com/pingcap/tidb/br/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeDecodeKVEvents(t *testing.T) {
+	events := []*KVEvent{
+		{Key: []byte("a"), Value: []byte("1"), Op: OpPut, StartTS: 1, CommitTS: 2},
+		{Key: []byte("b"), Value: []byte{}, Op: OpPut, StartTS: 3, CommitTS: 4},
+		{Key: []byte("a"), Value: []byte{}, Op: OpDelete, StartTS: 5, CommitTS: 6},
+	}
+	data := encodeKVEvents(events)
+	decoded, err := decodeKVEvents(data)
+	require.NoError(t, err)
+	require.Equal(t, events, decoded)
+
+	_, err = decodeKVEvents(data[:len(data)-1])
+	require.True(t, berrors.ErrPiTRInvalidLogFile.Equal(err))
+	data[0] = 0
+	_, err = decodeKVEvents(data)
+	require.True(t, berrors.ErrPiTRInvalidLogFile.Equal(err))
+}
+
+func TestResolvedTracker(t *testing.T) {
+	tracker := newResolvedTracker()
+	require.Equal(t, uint64(0), tracker.resolvedTS())
+	root := tracker.add(10)
+	require.Equal(t, uint64(10), tracker.resolvedTS())
+
+	// The span is split into two regions.
+	r1 := tracker.add(tracker.get(root))
+	r2 := tracker.add(tracker.get(root))
+	tracker.remove(root)
+	tracker.update(r1, 20)
+	require.Equal(t, uint64(10), tracker.resolvedTS())
+	tracker.update(r2, 15)
+	require.Equal(t, uint64(15), tracker.resolvedTS())
+	// The resolved ts never goes back.
+	tracker.update(r2, 12)
+	require.Equal(t, uint64(15), tracker.get(r2))
+	tracker.remove(r2)
+	require.Equal(t, uint64(20), tracker.resolvedTS())
+}
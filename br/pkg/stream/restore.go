@@ -0,0 +1,220 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package stream
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"sort"
+
+	"github.com/pingcap/errors"
+	backuppb "github.com/pingcap/kvproto/pkg/brpb"
+	"github.com/pingcap/log"
+	berrors "github.com/pingcap/tidb/br/pkg/errors"
+	"github.com/pingcap/tidb/br/pkg/metautil"
+	"github.com/pingcap/tidb/br/pkg/restore"
+	"github.com/pingcap/tidb/br/pkg/storage"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/tablecodec"
+	"go.uber.org/zap"
+)
+
+// DefaultReplayBatchSize is the default number of the changes applied in a transaction.
+const DefaultReplayBatchSize = 1024
+
+// LogReplayer applies the changes in the log backup to the cluster.
+type LogReplayer struct {
+	storage      storage.ExternalStorage
+	store        kv.Storage
+	rewriteRules *restore.RewriteRules
+	batchSize    int
+}
+
+// NewLogReplayer creates a LogReplayer. The keys of the tables are rewritten by the rules,
+// the changes of the tables without rewrite rules are skipped.
+func NewLogReplayer(s storage.ExternalStorage, store kv.Storage, rewriteRules *restore.RewriteRules) *LogReplayer {
+	return &LogReplayer{
+		storage:      s,
+		store:        store,
+		rewriteRules: rewriteRules,
+		batchSize:    DefaultReplayBatchSize,
+	}
+}
+
+// Replay applies the changes committed in (startTS, restoredTS] in the commit order.
+// It returns the number of the applied changes. The changes are written in new
+// transactions, so they get new commit timestamps instead of the original ones.
+//
+// The DDL isn't replayed, so Replay fails before applying anything if the schema is
+// changed in the range: the changes after the DDL may belong to the tables without
+// rewrite rules, or be encoded in the new schema.
+func (r *LogReplayer) Replay(ctx context.Context, startTS, restoredTS uint64) (int, error) {
+	metas, err := metautil.ReadLogMetas(ctx, r.storage)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	if err = r.checkSchemaUnchanged(ctx, metas, startTS, restoredTS); err != nil {
+		return 0, errors.Trace(err)
+	}
+	applied := 0
+	// The skipped changes by table ID, 0 for the non-table keys.
+	skipped := make(map[int64]int)
+	for _, m := range metas {
+		if !metaInRange(m, restoredTS) {
+			continue
+		}
+		var events []*KVEvent
+		for _, file := range m.Meta.Files {
+			if !fileInRange(file, startTS, restoredTS) {
+				continue
+			}
+			fileEvents, err := r.readFile(ctx, file)
+			if err != nil {
+				return applied, errors.Trace(err)
+			}
+			for _, e := range fileEvents {
+				if e.CommitTS <= startTS || e.CommitTS > restoredTS {
+					continue
+				}
+				key, ok := r.rewriteKey(e.Key)
+				if !ok {
+					skipped[tablecodec.DecodeTableID(e.Key)]++
+					continue
+				}
+				e.Key = key
+				events = append(events, e)
+			}
+		}
+		sort.SliceStable(events, func(i, j int) bool {
+			return events[i].CommitTS < events[j].CommitTS
+		})
+		if err = r.apply(ctx, events); err != nil {
+			return applied, errors.Trace(err)
+		}
+		applied += len(events)
+	}
+	if len(skipped) > 0 {
+		// The tables in the filter always have rewrite rules, so these are the changes of
+		// the tables out of the filter, the system tables, or the meta keys like auto IDs.
+		log.Warn("skipped the changes without rewrite rules in log backup",
+			zap.Uint64("start-ts", startTS),
+			zap.Uint64("restored-ts", restoredTS),
+			zap.Any("skipped-by-table", skipped))
+	}
+	log.Info("replayed log backup",
+		zap.Uint64("start-ts", startTS),
+		zap.Uint64("restored-ts", restoredTS),
+		zap.Int("applied", applied),
+		zap.Int("skipped", sumSkipped(skipped)))
+	return applied, nil
+}
+
+// checkSchemaUnchanged returns an error if the schema version is changed in (startTS, restoredTS].
+// Only the files containing the schema version key in their key ranges are read.
+func (r *LogReplayer) checkSchemaUnchanged(ctx context.Context, metas []*metautil.LogMeta, startTS, restoredTS uint64) error {
+	schemaVersionKey := meta.SchemaVersionKey()
+	for _, m := range metas {
+		if !metaInRange(m, restoredTS) {
+			continue
+		}
+		for _, file := range m.Meta.Files {
+			if !fileInRange(file, startTS, restoredTS) ||
+				bytes.Compare(schemaVersionKey, file.StartKey) < 0 || bytes.Compare(schemaVersionKey, file.EndKey) > 0 {
+				continue
+			}
+			events, err := r.readFile(ctx, file)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			for _, e := range events {
+				if e.CommitTS > startTS && e.CommitTS <= restoredTS && bytes.Equal(e.Key, schemaVersionKey) {
+					return errors.Annotatef(berrors.ErrPiTRSchemaChanged,
+						"DDL committed at %d, restore to a point before it or take a new full backup after it", e.CommitTS)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func metaInRange(m *metautil.LogMeta, restoredTS uint64) bool {
+	// The changes in a flush are committed after its start version.
+	return m.Meta.StartVersion < restoredTS
+}
+
+func fileInRange(file *backuppb.File, startTS, restoredTS uint64) bool {
+	return file.EndVersion > startTS && file.StartVersion <= restoredTS
+}
+
+func sumSkipped(skipped map[int64]int) int {
+	sum := 0
+	for _, n := range skipped {
+		sum += n
+	}
+	return sum
+}
+
+func (r *LogReplayer) readFile(ctx context.Context, file *backuppb.File) ([]*KVEvent, error) {
+	data, err := r.storage.ReadFile(ctx, file.Name)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	checksum := sha256.Sum256(data)
+	if !bytes.Equal(checksum[:], file.Sha256) {
+		return nil, errors.Annotatef(berrors.ErrInvalidMetaFile, "checksum mismatch of %s", file.Name)
+	}
+	events, err := decodeKVEvents(data)
+	if err != nil {
+		return nil, errors.Annotatef(err, "file %s", file.Name)
+	}
+	return events, nil
+}
+
+// rewriteKey rewrites the key of a table by the rewrite rules. The changes of the other keys,
+// e.g. the meta keys changed by the DDL, are not restored.
+func (r *LogReplayer) rewriteKey(key []byte) ([]byte, bool) {
+	if !bytes.HasPrefix(key, tablecodec.TablePrefix()) {
+		return nil, false
+	}
+	if r.rewriteRules == nil {
+		return key, true
+	}
+	for _, rule := range r.rewriteRules.Data {
+		if bytes.HasPrefix(key, rule.GetOldKeyPrefix()) {
+			newKey := make([]byte, 0, len(key)-len(rule.GetOldKeyPrefix())+len(rule.GetNewKeyPrefix()))
+			newKey = append(newKey, rule.GetNewKeyPrefix()...)
+			return append(newKey, key[len(rule.GetOldKeyPrefix()):]...), true
+		}
+	}
+	return nil, false
+}
+
+func (r *LogReplayer) apply(ctx context.Context, events []*KVEvent) error {
+	for len(events) > 0 {
+		batch := events
+		if len(batch) > r.batchSize {
+			batch = batch[:r.batchSize]
+		}
+		events = events[len(batch):]
+		err := kv.RunInNewTxn(ctx, r.store, true, func(ctx context.Context, txn kv.Transaction) error {
+			for _, e := range batch {
+				var err error
+				if e.Op == OpDelete {
+					err = txn.Delete(e.Key)
+				} else {
+					err = txn.Set(e.Key, e.Value)
+				}
+				if err != nil {
+					return errors.Trace(err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package stream
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pingcap/kvproto/pkg/import_sstpb"
+	berrors "github.com/pingcap/tidb/br/pkg/errors"
+	"github.com/pingcap/tidb/br/pkg/restore"
+	"github.com/pingcap/tidb/br/pkg/storage"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/sessionctx/stmtctx"
+	"github.com/pingcap/tidb/store/mockstore"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/rowcodec"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplay(t *testing.T) {
+	ctx := context.Background()
+	s, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	store, err := mockstore.NewMockStore()
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, store.Close())
+	}()
+
+	key := func(tableID, handle int64) string {
+		return string(tablecodec.EncodeRowKeyWithHandle(tableID, kv.IntHandle(handle)))
+	}
+	// The row values must be valid for the mock store.
+	row := func(v string) string {
+		var encoder rowcodec.Encoder
+		value, err := encoder.Encode(&stmtctx.StatementContext{}, []int64{1}, []types.Datum{types.NewStringDatum(v)}, nil)
+		require.NoError(t, err)
+		return string(value)
+	}
+	source := &mockEventSource{events: []*Event{
+		putEvent(key(1, 1), row("v1"), 11),
+		putEvent(key(1, 2), row("v2"), 12),
+		putEvent(key(2, 1), row("other"), 13),
+		putEvent("mDDL", "meta", 14),
+		{ResolvedTS: 15},
+		putEvent(key(1, 1), row("v1-new"), 16),
+		deleteEvent(key(1, 2), 17),
+		putEvent(key(1, 3), row("v3"), 21),
+		putEvent(string(meta.SchemaVersionKey()), "2", 22),
+		{ResolvedTS: 30},
+	}}
+	runLogBackup(t, s, source, 10, 30)
+
+	rules := &restore.RewriteRules{Data: []*import_sstpb.RewriteRule{{
+		OldKeyPrefix: tablecodec.EncodeTablePrefix(1),
+		NewKeyPrefix: tablecodec.EncodeTablePrefix(100),
+	}}}
+	replayer := NewLogReplayer(s, store, rules)
+	replayer.batchSize = 2
+	// The DDL isn't replayed, so the restore after it is rejected before applying anything.
+	_, err = replayer.Replay(ctx, 11, 25)
+	require.True(t, berrors.ErrPiTRSchemaChanged.Equal(err))
+	txn, err := store.Begin()
+	require.NoError(t, err)
+	_, err = txn.Get(ctx, []byte(key(100, 1)))
+	require.True(t, kv.ErrNotExist.Equal(err))
+	require.NoError(t, txn.Rollback())
+
+	// Only the changes of the table with rewrite rules in (11, 20] are replayed.
+	applied, err := replayer.Replay(ctx, 11, 20)
+	require.NoError(t, err)
+	require.Equal(t, 3, applied)
+
+	txn, err = store.Begin()
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, txn.Rollback())
+	}()
+	value, err := txn.Get(ctx, []byte(key(100, 1)))
+	require.NoError(t, err)
+	require.Equal(t, []byte(row("v1-new")), value)
+	_, err = txn.Get(ctx, []byte(key(100, 2)))
+	require.True(t, kv.ErrNotExist.Equal(err))
+	_, err = txn.Get(ctx, []byte(key(100, 3)))
+	require.True(t, kv.ErrNotExist.Equal(err))
+	_, err = txn.Get(ctx, []byte(key(2, 1)))
+	require.True(t, kv.ErrNotExist.Equal(err))
+}
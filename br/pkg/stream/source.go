@@ -0,0 +1,100 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package stream
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// EventSource watches the changes committed to the cluster.
+type EventSource interface {
+	// Watch emits the changes committed after checkpointTS into the channel until the context
+	// is done or an unrecoverable error occurs. A resolved ts must be emitted after all the
+	// changes committed before it have been emitted.
+	Watch(ctx context.Context, checkpointTS uint64, ch chan<- *Event) error
+}
+
+// resolvedTracker tracks the resolved ts of the spans watched separately,
+// the resolved ts of the whole range is the minimal one of them.
+type resolvedTracker struct {
+	mu     sync.Mutex
+	spans  map[uint64]uint64
+	nextID uint64
+}
+
+func newResolvedTracker() *resolvedTracker {
+	return &resolvedTracker{spans: make(map[uint64]uint64)}
+}
+
+// add starts tracking a span from the resolved ts and returns its id.
+func (t *resolvedTracker) add(ts uint64) uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.nextID++
+	t.spans[t.nextID] = ts
+	return t.nextID
+}
+
+// remove stops tracking a span. The sub spans replacing it must be added before removing it,
+// otherwise the resolved ts may be advanced wrongly.
+func (t *resolvedTracker) remove(id uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.spans, id)
+}
+
+func (t *resolvedTracker) update(id, ts uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if old, ok := t.spans[id]; ok && ts > old {
+		t.spans[id] = ts
+	}
+}
+
+func (t *resolvedTracker) get(id uint64) uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.spans[id]
+}
+
+func (t *resolvedTracker) resolvedTS() uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.spans) == 0 {
+		return 0
+	}
+	resolved := uint64(math.MaxUint64)
+	for _, ts := range t.spans {
+		if ts < resolved {
+			resolved = ts
+		}
+	}
+	return resolved
+}
+
+// run emits the resolved ts of the whole range periodically once it advances.
+func (t *resolvedTracker) run(ctx context.Context, interval time.Duration, ch chan<- *Event) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var lastResolved uint64
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		resolved := t.resolvedTS()
+		if resolved <= lastResolved {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ch <- &Event{ResolvedTS: resolved}:
+			lastResolved = resolved
+		}
+	}
+}
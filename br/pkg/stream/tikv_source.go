@@ -0,0 +1,321 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package stream
+
+import (
+	"bytes"
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/kvproto/pkg/cdcpb"
+	"github.com/pingcap/kvproto/pkg/kvrpcpb"
+	"github.com/pingcap/log"
+	berrors "github.com/pingcap/tidb/br/pkg/errors"
+	"github.com/pingcap/tidb/br/pkg/logutil"
+	"github.com/pingcap/tidb/util/codec"
+	pd "github.com/tikv/pd/client"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	scanRegionLimit       = 128
+	resolvedEmitInterval  = time.Second
+	watchRetryInitBackoff = 100 * time.Millisecond
+	watchRetryMaxBackoff  = 3 * time.Second
+)
+
+// ChangeDataClientFactory returns the change data client connecting to the store.
+type ChangeDataClientFactory func(ctx context.Context, storeID uint64) (cdcpb.ChangeDataClient, error)
+
+// keySpan is a range of the encoded keys, an empty end key means +inf.
+type keySpan struct {
+	start, end []byte
+}
+
+// TiKVEventSource watches the changes of a key range through the change data service of TiKV.
+// Each region in the range is watched by a separate feed, which is re-created on the region
+// changes such as split, merge and leader transfer.
+type TiKVEventSource struct {
+	pdClient  pd.Client
+	newClient ChangeDataClientFactory
+	span      keySpan
+
+	requestID uint64
+	tracker   *resolvedTracker
+}
+
+// NewTiKVEventSource creates a TiKVEventSource watching the raw key range [startKey, endKey).
+func NewTiKVEventSource(pdClient pd.Client, newClient ChangeDataClientFactory, startKey, endKey []byte) *TiKVEventSource {
+	span := keySpan{start: codec.EncodeBytes(nil, startKey)}
+	if len(endKey) > 0 {
+		span.end = codec.EncodeBytes(nil, endKey)
+	}
+	return &TiKVEventSource{
+		pdClient:  pdClient,
+		newClient: newClient,
+		span:      span,
+	}
+}
+
+// Watch implements EventSource.Watch.
+func (s *TiKVEventSource) Watch(ctx context.Context, checkpointTS uint64, ch chan<- *Event) error {
+	s.tracker = newResolvedTracker()
+	eg, ectx := errgroup.WithContext(ctx)
+	id := s.tracker.add(checkpointTS)
+	eg.Go(func() error {
+		return s.watchSpan(ectx, eg, id, s.span, ch)
+	})
+	eg.Go(func() error {
+		return s.tracker.run(ectx, resolvedEmitInterval, ch)
+	})
+	return eg.Wait()
+}
+
+// watchSpan locates the regions in the span and watches each of them, the tracked span
+// is replaced by the regions, which are watched from the resolved ts of the span.
+func (s *TiKVEventSource) watchSpan(ctx context.Context, eg *errgroup.Group, id uint64, span keySpan, ch chan<- *Event) error {
+	resolved := s.tracker.get(id)
+	regions, err := s.scanRegions(ctx, span)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, region := range regions {
+		region := region
+		regionID := s.tracker.add(resolved)
+		eg.Go(func() error {
+			return s.watchRegion(ctx, eg, regionID, region, ch)
+		})
+	}
+	s.tracker.remove(id)
+	return nil
+}
+
+type regionSpan struct {
+	region *pd.Region
+	span   keySpan
+}
+
+func (s *TiKVEventSource) scanRegions(ctx context.Context, span keySpan) ([]regionSpan, error) {
+	backoff := watchRetryInitBackoff
+	var result []regionSpan
+	for key := span.start; ; {
+		regions, err := s.pdClient.ScanRegions(ctx, key, span.end, scanRegionLimit)
+		if err == nil && len(regions) == 0 {
+			err = errors.Annotatef(berrors.ErrPDBatchScanRegion, "no region found from key %x", key)
+		}
+		if err != nil {
+			log.Warn("failed to scan regions, retry later", logutil.Key("key", key), zap.Error(err))
+			if err = sleep(ctx, &backoff); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		for _, region := range regions {
+			sub := keySpan{start: region.Meta.StartKey, end: region.Meta.EndKey}
+			if bytes.Compare(sub.start, span.start) < 0 {
+				sub.start = span.start
+			}
+			if len(span.end) > 0 && (len(sub.end) == 0 || bytes.Compare(sub.end, span.end) > 0) {
+				sub.end = span.end
+			}
+			result = append(result, regionSpan{region: region, span: sub})
+			key = region.Meta.EndKey
+		}
+		if len(key) == 0 || (len(span.end) > 0 && bytes.Compare(key, span.end) >= 0) {
+			return result, nil
+		}
+	}
+}
+
+// regionFeed handles the events of a region.
+type regionFeed struct {
+	id          uint64
+	regionID    uint64
+	initialized bool
+	// pending contains the prewritten but not committed changes, indexed by the start ts and the key.
+	pending map[pendingKey]*cdcpb.Event_Row
+}
+
+type pendingKey struct {
+	startTS uint64
+	key     string
+}
+
+// watchRegion watches the changes of a region. Once the region is changed, its span is located
+// and watched again from the resolved ts of the region.
+func (s *TiKVEventSource) watchRegion(ctx context.Context, eg *errgroup.Group, id uint64, rs regionSpan, ch chan<- *Event) error {
+	err := s.doWatchRegion(ctx, id, rs, ch)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil && !isRetryableWatchError(err) {
+		return errors.Trace(err)
+	}
+	log.Info("region feed is interrupted, watch its span again",
+		zap.Uint64("region", rs.region.Meta.GetId()),
+		zap.Uint64("resolved-ts", s.tracker.get(id)),
+		zap.Error(err))
+	backoff := watchRetryInitBackoff
+	if err = sleep(ctx, &backoff); err != nil {
+		return err
+	}
+	return s.watchSpan(ctx, eg, id, rs.span, ch)
+}
+
+func (s *TiKVEventSource) doWatchRegion(ctx context.Context, id uint64, rs regionSpan, ch chan<- *Event) error {
+	meta := rs.region.Meta
+	if rs.region.Leader == nil {
+		return errors.Annotatef(berrors.ErrBackupNoLeader, "region %d", meta.GetId())
+	}
+	client, err := s.newClient(ctx, rs.region.Leader.GetStoreId())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := client.EventFeed(streamCtx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	req := &cdcpb.ChangeDataRequest{
+		Header:       &cdcpb.Header{ClusterId: s.pdClient.GetClusterID(ctx)},
+		RegionId:     meta.GetId(),
+		RegionEpoch:  meta.GetRegionEpoch(),
+		CheckpointTs: s.tracker.get(id),
+		StartKey:     rs.span.start,
+		EndKey:       rs.span.end,
+		RequestId:    atomic.AddUint64(&s.requestID, 1),
+		ExtraOp:      kvrpcpb.ExtraOp_Noop,
+	}
+	if err = stream.Send(req); err != nil {
+		return errors.Trace(err)
+	}
+
+	feed := &regionFeed{
+		id:       id,
+		regionID: meta.GetId(),
+		pending:  make(map[pendingKey]*cdcpb.Event_Row),
+	}
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		for _, event := range resp.Events {
+			if event.RegionId != feed.regionID {
+				continue
+			}
+			switch e := event.Event.(type) {
+			case *cdcpb.Event_Entries_:
+				for _, row := range e.Entries.GetEntries() {
+					if err = s.handleRow(ctx, feed, row, ch); err != nil {
+						return errors.Trace(err)
+					}
+				}
+			case *cdcpb.Event_ResolvedTs:
+				s.resolve(feed, e.ResolvedTs)
+			case *cdcpb.Event_Error:
+				return &regionError{err: e.Error}
+			}
+		}
+		if resolved := resp.ResolvedTs; resolved != nil {
+			for _, regionID := range resolved.Regions {
+				if regionID == feed.regionID {
+					s.resolve(feed, resolved.Ts)
+					break
+				}
+			}
+		}
+	}
+}
+
+func (s *TiKVEventSource) resolve(feed *regionFeed, ts uint64) {
+	// The resolved ts is meaningless until the incremental scan of the region finishes.
+	if !feed.initialized {
+		return
+	}
+	s.tracker.update(feed.id, ts)
+}
+
+func (s *TiKVEventSource) handleRow(ctx context.Context, feed *regionFeed, row *cdcpb.Event_Row, ch chan<- *Event) error {
+	key := pendingKey{startTS: row.StartTs, key: string(row.Key)}
+	switch row.Type {
+	case cdcpb.Event_INITIALIZED:
+		feed.initialized = true
+		return nil
+	case cdcpb.Event_PREWRITE:
+		feed.pending[key] = row
+		return nil
+	case cdcpb.Event_ROLLBACK:
+		delete(feed.pending, key)
+		return nil
+	case cdcpb.Event_COMMIT:
+		prewrite, ok := feed.pending[key]
+		if !ok {
+			// The prewrite has been received before the feed is re-created and the commit ts
+			// has been resolved, so the change must have been emitted.
+			if row.CommitTs <= s.tracker.get(feed.id) {
+				return nil
+			}
+			return errors.Annotatef(berrors.ErrKVUnknown,
+				"prewrite not found for the commit of key %x at %d in region %d", row.Key, row.StartTs, feed.regionID)
+		}
+		delete(feed.pending, key)
+		prewrite.CommitTs = row.CommitTs
+		row = prewrite
+	case cdcpb.Event_COMMITTED:
+	default:
+		return nil
+	}
+
+	event := &KVEvent{
+		Key:      row.Key,
+		Value:    row.Value,
+		Op:       OpPut,
+		StartTS:  row.StartTs,
+		CommitTS: row.CommitTs,
+	}
+	if row.OpType == cdcpb.Event_Row_DELETE {
+		event.Op = OpDelete
+		event.Value = nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case ch <- &Event{KV: event}:
+		return nil
+	}
+}
+
+// regionError is the error of a region reported by TiKV.
+type regionError struct {
+	err *cdcpb.Error
+}
+
+func (e *regionError) Error() string {
+	return e.err.String()
+}
+
+func isRetryableWatchError(err error) bool {
+	if regionErr, ok := errors.Cause(err).(*regionError); ok {
+		return regionErr.err.GetClusterIdMismatch() == nil && regionErr.err.GetCompatibility() == nil
+	}
+	// The other errors are mostly network errors.
+	return !berrors.Is(err, berrors.ErrKVUnknown)
+}
+
+func sleep(ctx context.Context, backoff *time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(*backoff):
+	}
+	*backoff *= 2
+	if *backoff > watchRetryMaxBackoff {
+		*backoff = watchRetryMaxBackoff
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package stream
+
+import (
+	"context"
+
+	"github.com/pingcap/errors"
+	backuppb "github.com/pingcap/kvproto/pkg/brpb"
+	"github.com/pingcap/log"
+	"github.com/pingcap/tidb/br/pkg/metautil"
+	"github.com/pingcap/tidb/br/pkg/storage"
+	"go.uber.org/zap"
+)
+
+// Truncate removes the log files which only contain the changes committed before the ts,
+// so the log backup can't be restored to a ts before it any more. The metadata file of
+// the latest checkpoint is always kept. It returns the number of the removed data files.
+func Truncate(ctx context.Context, s storage.ExternalStorage, until uint64) (int, error) {
+	metas, err := metautil.ReadLogMetas(ctx, s)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	removed := 0
+	for i, meta := range metas {
+		var kept []*backuppb.File
+		for _, file := range meta.Meta.Files {
+			if file.EndVersion >= until {
+				kept = append(kept, file)
+				continue
+			}
+			if err = s.DeleteFile(ctx, file.Name); err != nil {
+				return removed, errors.Trace(err)
+			}
+			removed++
+		}
+		switch {
+		case len(kept) == 0 && meta.Meta.EndVersion < until && i != len(metas)-1:
+			err = s.DeleteFile(ctx, meta.Name)
+		case len(kept) != len(meta.Meta.Files):
+			meta.Meta.Files = kept
+			err = metautil.WriteLogMeta(ctx, s, meta)
+		}
+		if err != nil {
+			return removed, errors.Trace(err)
+		}
+	}
+	log.Info("truncated log backup", zap.Uint64("until", until), zap.Int("removed-files", removed))
+	return removed, nil
+}
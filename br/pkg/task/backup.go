@@ -98,13 +98,9 @@ func DefineBackupFlags(flags *pflag.FlagSet) {
 	// This flag can impact the online cluster, so hide it in case of abuse.
 	_ = flags.MarkHidden(flagRemoveSchedulers)
 
-	// Disable stats by default. because of
-	// 1. DumpStatsToJson is not stable
-	// 2. It increases memory usage and might cause BR OOM.
-	// TODO: we need a better way to backup/restore stats.
-	flags.Bool(flagIgnoreStats, true, "ignore backup stats, used for test")
-	// This flag is used for test. we should backup stats all the time.
-	_ = flags.MarkHidden(flagIgnoreStats)
+	// Backing up stats loads all the table infos and stats into memory, which might cause BR OOM
+	// on a cluster with a huge number of tables, it can be skipped in this case.
+	flags.Bool(flagIgnoreStats, false, "skip backing up the statistics of the tables and the SQL bindings")
 
 	flags.Bool(flagUseBackupMetaV2, false,
 		"use backup meta v2 to store meta info")
@@ -487,6 +483,17 @@ func RunBackup(c context.Context, g glue.Glue, cmdName string, cfg *BackupConfig
 		return errors.Trace(err)
 	}
 
+	// The bindings may refer to any table, so they are only backed up by full backup.
+	if !skipStats && isFullBackup(cmdName) {
+		bindings, err := backup.BackupBindings(ctx, mgr.GetDomain())
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err = metawriter.FlushBindings(ctx, bindings); err != nil {
+			return errors.Trace(err)
+		}
+	}
+
 	err = metawriter.FlushBackupMeta(ctx)
 	if err != nil {
 		return errors.Trace(err)
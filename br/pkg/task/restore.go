@@ -4,9 +4,11 @@ package task
 
 import (
 	"context"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/BurntSushi/toml"
 	"github.com/opentracing/opentracing-go"
 	"github.com/pingcap/errors"
 	"github.com/pingcap/failpoint"
@@ -29,8 +31,10 @@ import (
 )
 
 const (
-	flagOnline   = "online"
-	flagNoSchema = "no-schema"
+	flagOnline      = "online"
+	flagNoSchema    = "no-schema"
+	flagRewrite     = "rewrite"
+	flagRewriteFile = "rewrite-file"
 
 	// FlagMergeRegionSizeBytes is the flag name of merge small regions by size
 	FlagMergeRegionSizeBytes = "merge-region-size-bytes"
@@ -58,6 +62,7 @@ const (
 	DBRestoreCmd    = "DataBase Restore"
 	TableRestoreCmd = "Table Restore"
 	RawRestoreCmd   = "Raw Restore"
+	PointRestoreCmd = "Point Restore"
 )
 
 // RestoreCommonConfig is the common configuration for all BR restore tasks.
@@ -134,6 +139,11 @@ type RestoreConfig struct {
 	DdlBatchSize uint `json:"ddl-batch-size" toml:"ddl-batch-size"`
 
 	WithPlacementPolicy string `json:"with-tidb-placement-mode" toml:"with-tidb-placement-mode"`
+
+	// Rewrites restores the databases or tables under new names.
+	Rewrites []restore.RenameRule `json:"rewrites" toml:"rewrites"`
+	// IgnoreStats skips restoring the stats and the bindings in the backup.
+	IgnoreStats bool `json:"ignore-stats" toml:"ignore-stats"`
 }
 
 // DefineRestoreFlags defines common flags for the restore tidb command.
@@ -142,6 +152,11 @@ func DefineRestoreFlags(flags *pflag.FlagSet) {
 	// Do not expose this flag
 	_ = flags.MarkHidden(flagNoSchema)
 	flags.String(FlagWithPlacementPolicy, "STRICT", "correspond to tidb global/session variable with-tidb-placement-mode")
+	flags.StringArray(flagRewrite, nil, "restore a database or a table under a new name, "+
+		"in the form of `db:new_db` or `db.table:new_db.new_table`, can be specified multiple times")
+	flags.Bool(flagIgnoreStats, false, "skip restoring the statistics of the tables and the SQL bindings in the backup")
+	flags.String(flagRewriteFile, "", "the TOML file of the rewrite rules, whose [rewrite] table maps "+
+		"the databases or tables to their new names, e.g. `\"prod.orders\" = \"staging.orders_x\"`")
 
 	DefineRestoreCommonFlags(flags)
 }
@@ -182,7 +197,54 @@ func (cfg *RestoreConfig) ParseFromFlags(flags *pflag.FlagSet) error {
 	if err != nil {
 		return errors.Annotatef(err, "failed to get flag %s", FlagWithPlacementPolicy)
 	}
-	return nil
+	cfg.IgnoreStats, err = flags.GetBool(flagIgnoreStats)
+	if err != nil {
+		return errors.Annotatef(err, "failed to get flag %s", flagIgnoreStats)
+	}
+	cfg.Rewrites, err = parseRewriteRules(flags)
+	return errors.Trace(err)
+}
+
+// parseRewriteRules parses the rewrite rules from the --rewrite flags and the --rewrite-file.
+func parseRewriteRules(flags *pflag.FlagSet) ([]restore.RenameRule, error) {
+	rules, err := flags.GetStringArray(flagRewrite)
+	if err != nil {
+		return nil, errors.Annotatef(err, "failed to get flag %s", flagRewrite)
+	}
+	file, err := flags.GetString(flagRewriteFile)
+	if err != nil {
+		return nil, errors.Annotatef(err, "failed to get flag %s", flagRewriteFile)
+	}
+	if file != "" {
+		var content struct {
+			Rewrite map[string]string `toml:"rewrite"`
+		}
+		if _, err := toml.DecodeFile(file, &content); err != nil {
+			return nil, errors.Annotatef(berrors.ErrRestoreInvalidRewrite, "failed to parse %s: %s", file, err)
+		}
+		keys := make([]string, 0, len(content.Rewrite))
+		for key := range content.Rewrite {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			rules = append(rules, key+":"+content.Rewrite[key])
+		}
+	}
+
+	renameRules := make([]restore.RenameRule, 0, len(rules))
+	for _, rule := range rules {
+		r, err := restore.ParseRenameRule(rule)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		renameRules = append(renameRules, r)
+	}
+	// check the conflicts of the rules early.
+	if _, err := restore.NewRenameMapping(renameRules); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return renameRules, nil
 }
 
 // adjustRestoreConfig is use for BR(binary) and BR in TiDB.
@@ -220,6 +282,9 @@ func configureRestoreClient(ctx context.Context, client *restore.Client, cfg *Re
 	if cfg.NoSchema {
 		client.EnableSkipCreateSQL()
 	}
+	if cfg.IgnoreStats {
+		client.EnableSkipStats()
+	}
 	client.SetSwitchModeInterval(cfg.SwitchModeInterval)
 	client.SetBatchDdlSize(cfg.DdlBatchSize)
 	client.SetPlacementPolicyMode(cfg.WithPlacementPolicy)
@@ -304,7 +369,7 @@ func CheckNewCollationEnable(
 }
 
 func isFullRestore(cmdName string) bool {
-	return cmdName == FullRestoreCmd
+	return cmdName == FullRestoreCmd || cmdName == PointRestoreCmd
 }
 
 // RunRestore starts a restore task inside the current goroutine.
@@ -372,6 +437,21 @@ func RunRestore(c context.Context, g glue.Glue, cmdName string, cfg *RestoreConf
 	if len(dbs) == 0 && len(tables) != 0 {
 		return errors.Annotate(berrors.ErrRestoreInvalidBackup, "contain tables but no databases")
 	}
+	if len(cfg.Rewrites) > 0 {
+		// the DDL jobs of an incremental backup refer to the original names.
+		if client.IsIncremental() {
+			return errors.Annotate(berrors.ErrRestoreInvalidRewrite, "incremental restore can't rename the tables")
+		}
+		mapping, err := restore.NewRenameMapping(cfg.Rewrites)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		tables, dbs, err = mapping.Apply(tables)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		log.Info("tables are restored under new names", zap.Any("rules", cfg.Rewrites))
+	}
 	archiveSize := reader.ArchiveSize(ctx, files)
 	g.Record(summary.RestoreDataSize, archiveSize)
 	//restore from tidb will fetch a general Size issue https://github.com/pingcap/tidb/issues/27247
@@ -522,8 +602,8 @@ func RunRestore(c context.Context, g glue.Glue, cmdName string, cfg *RestoreConf
 		finish = client.GoValidateChecksum(
 			ctx, afterRestoreStream, mgr.GetStorage().GetClient(), errCh, updateCh, cfg.ChecksumConcurrency)
 	} else {
-		// when user skip checksum, just collect tables, and drop them.
-		finish = dropToBlackhole(ctx, afterRestoreStream, errCh, updateCh)
+		// when user skip checksum, the stats are still loaded.
+		finish = client.GoUpdateMetaAndLoadStats(ctx, afterRestoreStream, errCh, updateCh)
 	}
 
 	select {
@@ -541,38 +621,39 @@ func RunRestore(c context.Context, g glue.Glue, cmdName string, cfg *RestoreConf
 	// So leave it out of the pipeline for easier implementation.
 	client.RestoreSystemSchemas(ctx, cfg.TableFilter)
 
+	if err = restoreBindings(ctx, client, reader, tables, cfg); err != nil {
+		return errors.Trace(err)
+	}
+
 	// Set task summary to success status.
 	summary.SetSuccessStatus(true)
 	return nil
 }
 
-// dropToBlackhole drop all incoming tables into black hole,
-// i.e. don't execute checksum, just increase the process anyhow.
-func dropToBlackhole(
+// restoreBindings restores the global SQL bindings in the backup which only refer to the restored
+// tables. The bindings aren't restored if the tables are renamed.
+func restoreBindings(
 	ctx context.Context,
-	tableStream <-chan restore.CreatedTable,
-	errCh chan<- error,
-	updateCh glue.Progress,
-) <-chan struct{} {
-	outCh := make(chan struct{}, 1)
-	go func() {
-		defer func() {
-			close(outCh)
-		}()
-		for {
-			select {
-			case <-ctx.Done():
-				errCh <- ctx.Err()
-				return
-			case _, ok := <-tableStream:
-				if !ok {
-					return
-				}
-				updateCh.Inc()
-			}
-		}
-	}()
-	return outCh
+	client *restore.Client,
+	reader *metautil.MetaReader,
+	tables []*metautil.Table,
+	cfg *RestoreConfig,
+) error {
+	if cfg.IgnoreStats {
+		return nil
+	}
+	bindings, err := reader.ReadBindings(ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(bindings) == 0 {
+		return nil
+	}
+	if len(cfg.Rewrites) > 0 {
+		log.Warn("the bindings are not restored because the tables are renamed", zap.Int("count", len(bindings)))
+		return nil
+	}
+	return errors.Trace(client.RestoreBindings(ctx, restore.FilterBindings(bindings, tables)))
 }
 
 // filterRestoreFiles filters tables that can't be processed after applying cfg.TableFilter.MatchTable.
@@ -4,9 +4,12 @@ package task
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/pingcap/tidb/br/pkg/restore"
+	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/require"
 )
 
@@ -40,3 +43,31 @@ func TestconfigureRestoreClient(t *testing.T) {
 	require.Equal(t, client.GetBatchDdlSize(), 128)
 	require.True(t, true, client.IsOnline())
 }
+
+func TestParseRewriteRules(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "rewrite.toml")
+	err := os.WriteFile(file, []byte(`
+[rewrite]
+"prod.orders" = "staging.orders_x"
+prod2 = "staging2"
+`), 0o644)
+	require.NoError(t, err)
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	DefineRestoreFlags(flags)
+	require.NoError(t, flags.Parse([]string{"--rewrite", "a.b:c.d", "--rewrite", "e:f", "--rewrite-file", file}))
+	rules, err := parseRewriteRules(flags)
+	require.NoError(t, err)
+	require.Equal(t, []restore.RenameRule{
+		{FromDB: "a", FromTable: "b", ToDB: "c", ToTable: "d"},
+		{FromDB: "e", ToDB: "f"},
+		{FromDB: "prod", FromTable: "orders", ToDB: "staging", ToTable: "orders_x"},
+		{FromDB: "prod2", ToDB: "staging2"},
+	}, rules)
+
+	flags = pflag.NewFlagSet("test", pflag.ContinueOnError)
+	DefineRestoreFlags(flags)
+	require.NoError(t, flags.Parse([]string{"--rewrite", "a:b", "--rewrite", "A:c"}))
+	_, err = parseRewriteRules(flags)
+	require.Regexp(t, "duplicated rule", err.Error())
+}
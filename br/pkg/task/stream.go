@@ -0,0 +1,415 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package task
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/pingcap/errors"
+	backuppb "github.com/pingcap/kvproto/pkg/brpb"
+	"github.com/pingcap/log"
+	berrors "github.com/pingcap/tidb/br/pkg/errors"
+	"github.com/pingcap/tidb/br/pkg/glue"
+	"github.com/pingcap/tidb/br/pkg/metautil"
+	"github.com/pingcap/tidb/br/pkg/restore"
+	"github.com/pingcap/tidb/br/pkg/storage"
+	"github.com/pingcap/tidb/br/pkg/stream"
+	"github.com/pingcap/tidb/br/pkg/summary"
+	"github.com/pingcap/tidb/br/pkg/utils"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/tikv/client-go/v2/oracle"
+	pd "github.com/tikv/pd/client"
+	"go.uber.org/zap"
+)
+
+const (
+	flagTaskName          = "task-name"
+	flagStreamStartTS     = "start-ts"
+	flagFlushInterval     = "flush-interval"
+	flagTruncateUntil     = "until"
+	flagFullBackupStorage = "full-backup-storage"
+	flagRestoredTS        = "restored-ts"
+
+	logBackupServiceSafePointFormat = "br-log-backup-%s"
+)
+
+const (
+	StreamStartCmd    = "Log Backup Start"
+	StreamTruncateCmd = "Log Backup Truncate"
+)
+
+// StreamConfig is the configuration specific for log backup tasks.
+type StreamConfig struct {
+	Config
+
+	TaskName      string        `json:"task-name" toml:"task-name"`
+	StartTS       uint64        `json:"start-ts" toml:"start-ts"`
+	FlushInterval time.Duration `json:"flush-interval" toml:"flush-interval"`
+	// Until is the ts before which the log files are removed by truncating.
+	Until uint64 `json:"until" toml:"until"`
+}
+
+// DefineStreamStartFlags defines flags for the log backup start command.
+func DefineStreamStartFlags(command *cobra.Command) {
+	command.Flags().String(flagTaskName, "", "the name of the log backup task")
+	command.Flags().String(flagStreamStartTS, "", "the ts from which the changes are backed up, support TSO or datetime,"+
+		" e.g. '400036290571534337', '2018-05-11 01:42:23'. The current ts is used by default")
+	command.Flags().Duration(flagFlushInterval, stream.DefaultFlushInterval,
+		"the interval of flushing the changes to the storage, which is also the interval the checkpoint advances")
+	_ = command.MarkFlagRequired(flagTaskName)
+}
+
+// DefineStreamTruncateFlags defines flags for the log backup truncate command.
+func DefineStreamTruncateFlags(command *cobra.Command) {
+	command.Flags().String(flagTruncateUntil, "", "remove the log files containing only the changes committed before the ts,"+
+		" support TSO or datetime, e.g. '400036290571534337', '2018-05-11 01:42:23'")
+	_ = command.MarkFlagRequired(flagTruncateUntil)
+}
+
+// ParseStreamStartFromFlags parses the log backup start flags from the flag set.
+func (cfg *StreamConfig) ParseStreamStartFromFlags(flags *pflag.FlagSet) error {
+	var err error
+	if cfg.TaskName, err = flags.GetString(flagTaskName); err != nil {
+		return errors.Trace(err)
+	}
+	if len(cfg.TaskName) == 0 {
+		return errors.Annotate(berrors.ErrInvalidArgument, "empty task name is not allowed")
+	}
+	startTS, err := flags.GetString(flagStreamStartTS)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if cfg.StartTS, err = parseTSString(startTS); err != nil {
+		return errors.Trace(err)
+	}
+	if cfg.FlushInterval, err = flags.GetDuration(flagFlushInterval); err != nil {
+		return errors.Trace(err)
+	}
+	return cfg.Config.ParseFromFlags(flags)
+}
+
+// ParseStreamTruncateFromFlags parses the log backup truncate flags from the flag set.
+func (cfg *StreamConfig) ParseStreamTruncateFromFlags(flags *pflag.FlagSet) error {
+	until, err := flags.GetString(flagTruncateUntil)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if cfg.Until, err = parseTSString(until); err != nil {
+		return errors.Trace(err)
+	}
+	if cfg.Until == 0 {
+		return errors.Annotate(berrors.ErrInvalidArgument, "the ts to truncate until is required")
+	}
+	return cfg.Config.ParseFromFlags(flags)
+}
+
+// RunStreamStart starts a log backup task inside the current goroutine, it runs until the
+// context is canceled. A task in the storage is resumed from its checkpoint.
+func RunStreamStart(c context.Context, g glue.Glue, cmdName string, cfg *StreamConfig) error {
+	cfg.Config.adjust()
+
+	defer summary.Summary(cmdName)
+	ctx, cancel := context.WithCancel(c)
+	defer cancel()
+
+	// Log backup does not need domain.
+	needDomain := false
+	mgr, err := NewMgr(ctx, g, cfg.PD, cfg.TLS, GetKeepalive(&cfg.Config), cfg.CheckRequirements, needDomain)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer mgr.Close()
+
+	_, s, err := GetStorage(ctx, &cfg.Config)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	pdClient := mgr.GetPDClient()
+	clusterID := pdClient.GetClusterID(ctx)
+	task, err := metautil.ReadLogBackupTask(ctx, s)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	var checkpoint uint64
+	if task != nil {
+		if task.Name != cfg.TaskName || task.ClusterID != clusterID {
+			return errors.Annotatef(berrors.ErrPiTRTaskMismatch,
+				"the storage contains task %s of cluster %d", task.Name, task.ClusterID)
+		}
+		metas, err := metautil.ReadLogMetas(ctx, s)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		checkpoint = metautil.LogCheckpoint(metas)
+		if checkpoint < task.StartTS {
+			checkpoint = task.StartTS
+		}
+		log.Info("resume log backup task", zap.String("task", task.Name), zap.Uint64("checkpoint", checkpoint))
+	} else {
+		if cfg.StartTS == 0 {
+			p, l, err := pdClient.GetTS(ctx)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			cfg.StartTS = oracle.ComposeTS(p, l)
+		}
+		task = &metautil.LogBackupTask{
+			Name:      cfg.TaskName,
+			ClusterID: clusterID,
+			StartTS:   cfg.StartTS,
+		}
+		if err = metautil.WriteLogBackupTask(ctx, s, task); err != nil {
+			return errors.Trace(err)
+		}
+		checkpoint = task.StartTS
+		log.Info("start log backup task", zap.String("task", task.Name), zap.Uint64("start-ts", checkpoint))
+	}
+
+	// The changes after the checkpoint must not be garbage collected before they are backed up.
+	sp := utils.BRServiceSafePoint{
+		ID:       fmt.Sprintf(logBackupServiceSafePointFormat, task.Name),
+		TTL:      utils.DefaultBRGCSafePointTTL,
+		BackupTS: checkpoint,
+	}
+	if err = utils.CheckGCSafePoint(ctx, pdClient, checkpoint); err != nil {
+		return errors.Trace(err)
+	}
+	if err = utils.UpdateServiceSafePoint(ctx, pdClient, sp); err != nil {
+		return errors.Trace(err)
+	}
+	safePointTS := checkpoint
+	go keepLogBackupSafePoint(ctx, pdClient, sp, &safePointTS)
+
+	source := stream.NewTiKVEventSource(pdClient, mgr.GetChangeDataClient,
+		tablecodec.TablePrefix(), kv.Key(tablecodec.TablePrefix()).PrefixNext())
+	backup := stream.NewLogBackup(s, source, cfg.FlushInterval)
+	backup.SetCheckpointCallback(func(ctx context.Context, checkpoint uint64) error {
+		atomic.StoreUint64(&safePointTS, checkpoint)
+		log.Info("log backup checkpoint advanced", zap.Uint64("checkpoint", checkpoint),
+			zap.Time("time", oracle.GetTimeFromTS(checkpoint)))
+		return nil
+	})
+	err = backup.Run(ctx, checkpoint)
+	summary.CollectUint("checkpoint", backup.Checkpoint())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	summary.SetSuccessStatus(true)
+	return nil
+}
+
+// keepLogBackupSafePoint keeps the service safe point at the checkpoint of the log backup.
+func keepLogBackupSafePoint(ctx context.Context, pdClient pd.Client, sp utils.BRServiceSafePoint, checkpoint *uint64) {
+	ticker := time.NewTicker(time.Duration(sp.TTL) * time.Second / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		sp.BackupTS = atomic.LoadUint64(checkpoint)
+		if err := utils.UpdateServiceSafePoint(ctx, pdClient, sp); err != nil {
+			log.Warn("failed to update service safe point, log backup may fail if gc triggered", zap.Error(err))
+		}
+	}
+}
+
+// RunStreamTruncate removes the log files before the ts.
+func RunStreamTruncate(c context.Context, g glue.Glue, cmdName string, cfg *StreamConfig) error {
+	defer summary.Summary(cmdName)
+	ctx, cancel := context.WithCancel(c)
+	defer cancel()
+
+	_, s, err := GetStorage(ctx, &cfg.Config)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	task, err := metautil.ReadLogBackupTask(ctx, s)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if task == nil {
+		return errors.Annotate(berrors.ErrPiTRTaskMismatch, "no log backup task found in the storage")
+	}
+	removed, err := stream.Truncate(ctx, s, cfg.Until)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if cfg.Until > task.TruncatedTS {
+		task.TruncatedTS = cfg.Until
+		if err = metautil.WriteLogBackupTask(ctx, s, task); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	summary.CollectInt("removed files", removed)
+	summary.SetSuccessStatus(true)
+	return nil
+}
+
+// RestorePointConfig is the configuration specific for point-in-time restore tasks.
+type RestorePointConfig struct {
+	RestoreConfig
+
+	// FullBackupStorage is the storage of the full backup to restore from, the Storage of
+	// the config is the storage of the log backup.
+	FullBackupStorage string `json:"full-backup-storage" toml:"full-backup-storage"`
+	RestoredTS        uint64 `json:"restored-ts" toml:"restored-ts"`
+}
+
+// DefineRestorePointFlags defines flags for the point-in-time restore command.
+func DefineRestorePointFlags(command *cobra.Command) {
+	command.Flags().String(flagFullBackupStorage, "", "specify the url of the full backup storage to restore from,"+
+		` eg, "s3://bucket/path/prefix"`)
+	command.Flags().String(flagRestoredTS, "", "the ts to restore to, support TSO or datetime,"+
+		" e.g. '400036290571534337', '2018-05-11 01:42:23'. The checkpoint of the log backup is used by default")
+	_ = command.MarkFlagRequired(flagFullBackupStorage)
+}
+
+// ParseFromFlags parses the point-in-time restore flags from the flag set.
+func (cfg *RestorePointConfig) ParseFromFlags(flags *pflag.FlagSet) error {
+	var err error
+	if cfg.FullBackupStorage, err = flags.GetString(flagFullBackupStorage); err != nil {
+		return errors.Trace(err)
+	}
+	restoredTS, err := flags.GetString(flagRestoredTS)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if cfg.RestoredTS, err = parseTSString(restoredTS); err != nil {
+		return errors.Trace(err)
+	}
+	return cfg.RestoreConfig.ParseFromFlags(flags)
+}
+
+// RunRestorePoint restores the cluster to the restored ts. The full backup is restored first,
+// then the changes after it are replayed from the log backup.
+func RunRestorePoint(c context.Context, g glue.Glue, cmdName string, cfg *RestorePointConfig) error {
+	ctx, cancel := context.WithCancel(c)
+	defer cancel()
+
+	_, s, err := GetStorage(ctx, &cfg.Config)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	task, err := metautil.ReadLogBackupTask(ctx, s)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if task == nil {
+		return errors.Annotate(berrors.ErrPiTRTaskMismatch, "no log backup task found in the storage")
+	}
+	metas, err := metautil.ReadLogMetas(ctx, s)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	checkpoint := metautil.LogCheckpoint(metas)
+
+	fullCfg := cfg.Config
+	fullCfg.Storage = cfg.FullBackupStorage
+	_, fullStorage, backupMeta, err := ReadBackupMeta(ctx, metautil.MetaFile, &fullCfg)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	backupTS := backupMeta.EndVersion
+	if cfg.RestoredTS == 0 {
+		cfg.RestoredTS = checkpoint
+	}
+	if err = checkLogCoverage(task, backupTS, cfg.RestoredTS, checkpoint); err != nil {
+		return errors.Trace(err)
+	}
+
+	restoreCfg := cfg.RestoreConfig
+	restoreCfg.Config = fullCfg
+	if err = RunRestore(ctx, g, cmdName, &restoreCfg); err != nil {
+		return errors.Trace(err)
+	}
+
+	// Restore needs domain to get the restored tables.
+	needDomain := true
+	mgr, err := NewMgr(ctx, g, cfg.PD, cfg.TLS, GetKeepalive(&cfg.Config), cfg.CheckRequirements, needDomain)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer mgr.Close()
+
+	rewriteRules, err := buildPointRestoreRewriteRules(ctx, mgr.GetDomain().InfoSchema(), backupMeta, fullStorage, cfg)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	replayer := stream.NewLogReplayer(s, mgr.GetStorage(), rewriteRules)
+	applied, err := replayer.Replay(ctx, backupTS, cfg.RestoredTS)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	log.Info("point-in-time restore finished",
+		zap.Uint64("backup-ts", backupTS),
+		zap.Uint64("restored-ts", cfg.RestoredTS),
+		zap.Int("replayed", applied))
+	return nil
+}
+
+// checkLogCoverage checks the log backup contains all the changes in (backupTS, restoredTS].
+func checkLogCoverage(task *metautil.LogBackupTask, backupTS, restoredTS, checkpoint uint64) error {
+	switch {
+	case task.StartTS > backupTS:
+		return errors.Annotatef(berrors.ErrPiTRLogNotCover,
+			"the log backup starts at %d, after the full backup at %d", task.StartTS, backupTS)
+	case task.TruncatedTS > backupTS:
+		return errors.Annotatef(berrors.ErrPiTRLogNotCover,
+			"the log backup is truncated until %d, after the full backup at %d", task.TruncatedTS, backupTS)
+	case restoredTS < backupTS:
+		return errors.Annotatef(berrors.ErrPiTRLogNotCover,
+			"the restored ts %d is before the full backup at %d", restoredTS, backupTS)
+	case restoredTS > checkpoint:
+		return errors.Annotatef(berrors.ErrPiTRLogNotCover,
+			"the restored ts %d is after the checkpoint %d of the log backup", restoredTS, checkpoint)
+	}
+	return nil
+}
+
+func buildPointRestoreRewriteRules(
+	ctx context.Context,
+	is infoschema.InfoSchema,
+	backupMeta *backuppb.BackupMeta,
+	fullStorage storage.ExternalStorage,
+	cfg *RestorePointConfig,
+) (*restore.RewriteRules, error) {
+	reader := metautil.NewMetaReader(backupMeta, fullStorage, &cfg.CipherInfo)
+	databases, err := utils.LoadBackupTables(ctx, reader)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	mapping, err := restore.NewRenameMapping(cfg.Rewrites)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	rules := &restore.RewriteRules{}
+	for _, db := range databases {
+		// The system tables are restored by renaming, their changes are not replayed.
+		if utils.IsSysDB(db.Info.Name.L) {
+			continue
+		}
+		for _, table := range db.Tables {
+			if !cfg.TableFilter.MatchTable(db.Info.Name.O, table.Info.Name.O) {
+				continue
+			}
+			// the changes are replayed into the table under its new name.
+			dbName, tableName := mapping.Target(db.Info.Name, table.Info.Name)
+			newTable, err := is.TableByName(dbName, tableName)
+			if err != nil {
+				return nil, errors.Annotatef(berrors.ErrRestoreTableIDMismatch,
+					"table %s.%s is not restored", dbName, tableName)
+			}
+			rules.Append(*restore.GetRewriteRules(newTable.Meta(), table.Info, 0))
+		}
+	}
+	return rules, nil
+}
@@ -73,8 +73,8 @@ func CheckGCSafePoint(ctx context.Context, pdClient pd.Client, ts uint64) error
 	return nil
 }
 
-// updateServiceSafePoint register BackupTS to PD, to lock down BackupTS as safePoint with TTL seconds.
-func updateServiceSafePoint(ctx context.Context, pdClient pd.Client, sp BRServiceSafePoint) error {
+// UpdateServiceSafePoint register BackupTS to PD, to lock down BackupTS as safePoint with TTL seconds.
+func UpdateServiceSafePoint(ctx context.Context, pdClient pd.Client, sp BRServiceSafePoint) error {
 	log.Debug("update PD safePoint limit with TTL", zap.Object("safePoint", sp))
 
 	lastSafePoint, err := pdClient.UpdateServiceGCSafePoint(ctx, sp.ID, sp.TTL, sp.BackupTS-1)
@@ -102,7 +102,7 @@ func StartServiceSafePointKeeper(
 	}
 	// Update service safe point immediately to cover the gap between starting
 	// update goroutine and updating service safe point.
-	if err := updateServiceSafePoint(ctx, pdClient, sp); err != nil {
+	if err := UpdateServiceSafePoint(ctx, pdClient, sp); err != nil {
 		return errors.Trace(err)
 	}
 
@@ -119,7 +119,7 @@ func StartServiceSafePointKeeper(
 				log.Debug("service safe point keeper exited")
 				return
 			case <-updateTick.C:
-				if err := updateServiceSafePoint(ctx, pdClient, sp); err != nil {
+				if err := UpdateServiceSafePoint(ctx, pdClient, sp); err != nil {
 					log.Warn("failed to update service safe point, backup may fail if gc triggered",
 						zap.Error(err),
 					)
@@ -417,6 +417,8 @@ type Security struct {
 	MinTLSVersion   string `toml:"tls-version" json:"tls-version"`
 	RSAKeySize      int    `toml:"rsa-key-size" json:"rsa-key-size"`
 	SecureBootstrap bool   `toml:"secure-bootstrap" json:"secure-bootstrap"`
+	// SessionStatesSigningKey signs the session states so that they can only be restored by the servers sharing the key.
+	SessionStatesSigningKey string `toml:"session-states-signing-key" json:"-"`
 }
 
 // The ErrConfigValidationFailed error is used so that external callers can do a type assertion
@@ -507,6 +509,10 @@ type PreparedPlanCache struct {
 	Enabled          bool    `toml:"enabled" json:"enabled"`
 	Capacity         uint    `toml:"capacity" json:"capacity"`
 	MemoryGuardRatio float64 `toml:"memory-guard-ratio" json:"memory-guard-ratio"`
+	// InstanceEnabled makes the sessions share the plans which are safe to share in an instance-level cache.
+	InstanceEnabled bool `toml:"instance-enabled" json:"instance-enabled"`
+	// InstanceMaxMemory is the estimated memory in bytes used by the instance-level cache at most.
+	InstanceMaxMemory uint64 `toml:"instance-max-memory" json:"instance-max-memory"`
 }
 
 // OpenTracing is the opentracing section of the config.
@@ -515,6 +521,8 @@ type OpenTracing struct {
 	RPCMetrics bool                `toml:"rpc-metrics" json:"rpc-metrics"`
 	Sampler    OpenTracingSampler  `toml:"sampler" json:"sampler"`
 	Reporter   OpenTracingReporter `toml:"reporter" json:"reporter"`
+	// W3CExporter is the exporter of the sampled W3C traces, empty means W3C tracing is disabled.
+	W3CExporter string `toml:"w3c-exporter" json:"w3c-exporter"`
 }
 
 // OpenTracingSampler is the config for opentracing sampler.
@@ -707,9 +715,11 @@ var defaultConf = Config{
 		HeaderTimeout: 5,
 	},
 	PreparedPlanCache: PreparedPlanCache{
-		Enabled:          false,
-		Capacity:         1000,
-		MemoryGuardRatio: 0.1,
+		Enabled:           false,
+		Capacity:          1000,
+		MemoryGuardRatio:  0.1,
+		InstanceEnabled:   false,
+		InstanceMaxMemory: 256 << 20,
 	},
 	OpenTracing: OpenTracing{
 		Enable: false,
@@ -963,6 +973,9 @@ func (c *Config) Valid() error {
 	if c.PreparedPlanCache.MemoryGuardRatio < 0 || c.PreparedPlanCache.MemoryGuardRatio > 1 {
 		return fmt.Errorf("memory-guard-ratio in [prepared-plan-cache] must be NOT less than 0 and more than 1")
 	}
+	if c.PreparedPlanCache.InstanceEnabled && c.PreparedPlanCache.InstanceMaxMemory < 1 {
+		return fmt.Errorf("instance-max-memory in [prepared-plan-cache] should be at least 1 when instance-enabled is true")
+	}
 	if len(c.IsolationRead.Engines) < 1 {
 		return fmt.Errorf("the number of [isolation-read]engines for isolation read should be at least 1")
 	}
@@ -522,11 +522,13 @@ func TestTxnTotalSizeLimitValid(t *testing.T) {
 func TestPreparePlanCacheValid(t *testing.T) {
 	conf := NewConfig()
 	tests := map[PreparedPlanCache]bool{
-		{Enabled: true, Capacity: 0}:                        false,
-		{Enabled: true, Capacity: 2}:                        true,
-		{Enabled: true, MemoryGuardRatio: -0.1}:             false,
-		{Enabled: true, MemoryGuardRatio: 2.2}:              false,
-		{Enabled: true, Capacity: 2, MemoryGuardRatio: 0.5}: true,
+		{Enabled: true, Capacity: 0}:                                                    false,
+		{Enabled: true, Capacity: 2}:                                                    true,
+		{Enabled: true, MemoryGuardRatio: -0.1}:                                         false,
+		{Enabled: true, MemoryGuardRatio: 2.2}:                                          false,
+		{Enabled: true, Capacity: 2, MemoryGuardRatio: 0.5}:                             true,
+		{Enabled: true, Capacity: 2, InstanceEnabled: true}:                             false,
+		{Enabled: true, Capacity: 2, InstanceEnabled: true, InstanceMaxMemory: 1 << 20}: true,
 	}
 	for testCase, res := range tests {
 		conf.PreparedPlanCache = testCase
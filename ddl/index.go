@@ -240,6 +240,32 @@ func buildIndexInfo(tblInfo *model.TableInfo, indexName model.CIStr, indexPartSp
 	return idxInfo, nil
 }
 
+// BuildHypoIndexInfo builds the meta of a hypothetical index. A hypothetical index only lives in the session
+// which creates it, so it's public at once and never backfilled.
+func BuildHypoIndexInfo(tblInfo *model.TableInfo, keyType ast.IndexKeyType, indexName model.CIStr,
+	indexPartSpecifications []*ast.IndexPartSpecification) (*model.IndexInfo, error) {
+	if keyType == ast.IndexKeyTypeFullText || keyType == ast.IndexKeyTypeSpatial {
+		return nil, dbterror.ErrUnsupportedIndexType.GenWithStack("FULLTEXT and SPATIAL index is not supported")
+	}
+	for _, spec := range indexPartSpecifications {
+		if spec.Expr != nil {
+			return nil, dbterror.ErrUnsupportedIndexType.GenWithStack("hypothetical expression index is not supported")
+		}
+	}
+	if indexInfo := tblInfo.FindIndexByName(indexName.L); indexInfo != nil {
+		return nil, dbterror.ErrDupKeyName.GenWithStack("index already exist %s", indexName)
+	}
+
+	idxInfo, err := buildIndexInfo(tblInfo, indexName, indexPartSpecifications, model.StatePublic)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	idxInfo.Table = tblInfo.Name
+	idxInfo.Tp = model.IndexTypeHypo
+	idxInfo.Unique = keyType == ast.IndexKeyTypeUnique
+	return idxInfo, nil
+}
+
 func addIndexColumnFlag(tblInfo *model.TableInfo, indexInfo *model.IndexInfo) {
 	if indexInfo.Primary {
 		for _, col := range indexInfo.Columns {
@@ -0,0 +1,209 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/util/dbterror"
+	"github.com/pingcap/tidb/util/sqlexec"
+)
+
+// defaultResourceGroupName is the name of the implicit resource group which
+// has no limits, users not bound to any group belong to it.
+const defaultResourceGroupName = "default"
+
+func onCreateResourceGroup(d *ddlCtx, t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	groupInfo := &model.ResourceGroupInfo{}
+	if err := job.DecodeArgs(groupInfo); err != nil {
+		job.State = model.JobStateCancelled
+		return ver, errors.Trace(err)
+	}
+	groupInfo.State = model.StateNone
+
+	existGroup, err := getResourceGroupByName(d, t, groupInfo.Name)
+	if err != nil {
+		job.State = model.JobStateCancelled
+		return ver, errors.Trace(err)
+	}
+	if existGroup != nil {
+		job.State = model.JobStateCancelled
+		return ver, infoschema.ErrResourceGroupExists.GenWithStackByArgs(existGroup.Name)
+	}
+
+	switch groupInfo.State {
+	case model.StateNone:
+		// none -> public
+		groupInfo.State = model.StatePublic
+		err = t.CreateResourceGroup(groupInfo)
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+		job.SchemaID = groupInfo.ID
+
+		ver, err = updateSchemaVersion(t, job)
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+		// Finish this job.
+		job.FinishDBJob(model.JobStateDone, model.StatePublic, ver, nil)
+		return ver, nil
+	default:
+		// We can't enter here.
+		return ver, dbterror.ErrInvalidDDLState.GenWithStackByArgs("resource group", groupInfo.State)
+	}
+}
+
+func onAlterResourceGroup(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	alterGroup := &model.ResourceGroupInfo{}
+	if err := job.DecodeArgs(alterGroup); err != nil {
+		job.State = model.JobStateCancelled
+		return ver, errors.Trace(err)
+	}
+
+	oldGroup, err := checkResourceGroupExistAndCancelNonExistJob(t, job, job.SchemaID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+
+	newGroup := *oldGroup
+	newGroup.QPS = alterGroup.QPS
+	newGroup.MaxConcurrency = alterGroup.MaxConcurrency
+	newGroup.MemQuota = alterGroup.MemQuota
+	if err = t.UpdateResourceGroup(&newGroup); err != nil {
+		job.State = model.JobStateCancelled
+		return ver, errors.Trace(err)
+	}
+
+	ver, err = updateSchemaVersion(t, job)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+
+	// Finish this job.
+	job.FinishDBJob(model.JobStateDone, model.StatePublic, ver, nil)
+	return ver, nil
+}
+
+func onDropResourceGroup(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	groupInfo, err := checkResourceGroupExistAndCancelNonExistJob(t, job, job.SchemaID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+
+	// The group isn't bound to any user when the job is submitted. Sessions
+	// which still run in it fall back to the default group on their next
+	// statement, so the group can be removed in one step.
+	groupInfo.State = model.StateNone
+	if err = t.DropResourceGroup(groupInfo.ID); err != nil {
+		return ver, errors.Trace(err)
+	}
+	ver, err = updateSchemaVersion(t, job)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	// Finish this job.
+	job.FinishDBJob(model.JobStateDone, model.StateNone, ver, nil)
+	return ver, nil
+}
+
+func checkResourceGroupExistAndCancelNonExistJob(t *meta.Meta, job *model.Job, groupID int64) (*model.ResourceGroupInfo, error) {
+	group, err := t.GetResourceGroup(groupID)
+	if err == nil {
+		return group, nil
+	}
+	if meta.ErrResourceGroupNotExists.Equal(err) {
+		job.State = model.JobStateCancelled
+		return nil, infoschema.ErrResourceGroupNotExists.GenWithStackByArgs(
+			fmt.Sprintf("(Resource Group ID %d)", groupID),
+		)
+	}
+	return nil, err
+}
+
+// checkResourceGroupNotInUse checks that no user is bound to the resource group.
+func checkResourceGroupNotInUse(ctx sessionctx.Context, group *model.ResourceGroupInfo) error {
+	exec, ok := ctx.(sqlexec.RestrictedSQLExecutor)
+	if !ok {
+		return nil
+	}
+	rows, _, err := exec.ExecRestrictedSQL(context.Background(), nil,
+		"SELECT User, Host FROM %n.%n WHERE Resource_group = %? LIMIT 1", mysql.SystemDB, mysql.UserTable, group.Name.L)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(rows) > 0 {
+		return dbterror.ErrResourceGroupInUse.GenWithStackByArgs(group.Name.O, fmt.Sprintf("%s@%s", rows[0].GetString(0), rows[0].GetString(1)))
+	}
+	return nil
+}
+
+func getResourceGroupByName(d *ddlCtx, t *meta.Meta, groupName model.CIStr) (*model.ResourceGroupInfo, error) {
+	currVer, err := t.GetSchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	is := d.infoCache.GetLatest()
+	if is.SchemaMetaVersion() == currVer {
+		// Use cached resource group.
+		group, ok := is.ResourceGroupByName(groupName)
+		if ok {
+			return group, nil
+		}
+		return nil, nil
+	}
+	// Check in meta directly.
+	groups, err := t.ListResourceGroups()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, group := range groups {
+		if group.Name.L == groupName.L {
+			return group, nil
+		}
+	}
+	return nil, nil
+}
+
+// buildResourceGroupInfo applies the options on a copy of the base group.
+func buildResourceGroupInfo(base *model.ResourceGroupInfo, options []*ast.ResourceGroupOption) (*model.ResourceGroupInfo, error) {
+	groupInfo := base.Clone()
+	for _, opt := range options {
+		switch opt.Tp {
+		case ast.ResourceGroupOptionQPS:
+			groupInfo.QPS = opt.UintValue
+		case ast.ResourceGroupOptionMaxConcurrency:
+			groupInfo.MaxConcurrency = opt.UintValue
+		case ast.ResourceGroupOptionMemQuota:
+			if opt.UintValue > math.MaxInt64 {
+				return nil, errors.Errorf("MEM_QUOTA %d is out of range", opt.UintValue)
+			}
+			groupInfo.MemQuota = int64(opt.UintValue)
+		default:
+			return nil, errors.Trace(errors.New("unknown resource group option"))
+		}
+	}
+	return groupInfo, nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl_test
+
+import (
+	"testing"
+
+	mysql "github.com/pingcap/tidb/errno"
+	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/testkit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceGroupBasic(t *testing.T) {
+	store, dom, clean := testkit.CreateMockStoreAndDomain(t)
+	defer clean()
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+
+	tk.MustExec("create resource group rg1 QPS = 100 MAX_CONCURRENCY = 10")
+	group, ok := dom.InfoSchema().ResourceGroupByName(model.NewCIStr("rg1"))
+	require.True(t, ok)
+	require.NotZero(t, group.ID)
+	require.Equal(t, uint64(100), group.QPS)
+	require.Equal(t, uint64(10), group.MaxConcurrency)
+	require.Equal(t, int64(0), group.MemQuota)
+	require.Equal(t, model.StatePublic, group.State)
+
+	tk.MustGetErrCode("create resource group rg1 QPS = 1", mysql.ErrResourceGroupExists)
+	tk.MustGetErrCode("create resource group RG1 QPS = 1", mysql.ErrResourceGroupExists)
+	tk.MustExec("create resource group if not exists RG1 QPS = 1")
+	tk.MustQuery("show warnings").Check(testkit.Rows("Note 8244 Resource group 'RG1' already exists"))
+	tk.MustGetErrCode("create resource group `default` QPS = 1", mysql.ErrReservedSyntax)
+
+	// Options that are not altered keep their values.
+	tk.MustExec("alter resource group rg1 MEM_QUOTA = 1048576")
+	group, ok = dom.InfoSchema().ResourceGroupByName(model.NewCIStr("rg1"))
+	require.True(t, ok)
+	require.Equal(t, uint64(100), group.QPS)
+	require.Equal(t, uint64(10), group.MaxConcurrency)
+	require.Equal(t, int64(1048576), group.MemQuota)
+	tk.MustGetErrCode("alter resource group rg2 QPS = 1", mysql.ErrResourceGroupNotExists)
+
+	tk.MustQuery("select GROUP_NAME, QPS, MAX_CONCURRENCY, MEM_QUOTA from information_schema.resource_groups").
+		Check(testkit.Rows("rg1 100 10 1048576"))
+
+	tk.MustExec("drop resource group rg1")
+	_, ok = dom.InfoSchema().ResourceGroupByName(model.NewCIStr("rg1"))
+	require.False(t, ok)
+	tk.MustGetErrCode("drop resource group rg1", mysql.ErrResourceGroupNotExists)
+	tk.MustExec("drop resource group if exists rg1")
+	tk.MustQuery("show warnings").Check(testkit.Rows("Note 8245 Unknown resource group 'rg1'"))
+	tk.MustQuery("select * from information_schema.resource_groups").Check(testkit.Rows())
+}
+
+func TestResourceGroupOfUser(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+	tk := testkit.NewTestKit(t, store)
+
+	tk.MustGetErrCode("create user u1 resource group rg1", mysql.ErrResourceGroupNotExists)
+	tk.MustExec("create resource group rg1 QPS = 100")
+	tk.MustExec("create user u1 resource group rg1")
+	tk.MustQuery("select Resource_group from mysql.user where user = 'u1'").Check(testkit.Rows("rg1"))
+
+	tk.MustGetErrCode("alter user u1 resource group rg2", mysql.ErrResourceGroupNotExists)
+	tk.MustExec("alter user u1 resource group default")
+	tk.MustQuery("select Resource_group from mysql.user where user = 'u1'").Check(testkit.Rows(""))
+	tk.MustExec("alter user u1 resource group RG1")
+	tk.MustQuery("select Resource_group from mysql.user where user = 'u1'").Check(testkit.Rows("rg1"))
+
+	// The group can't be dropped while users are bound to it.
+	tk.MustGetErrCode("drop resource group rg1", mysql.ErrResourceGroupInUse)
+	tk.MustExec("alter user u1 resource group default")
+	tk.MustExec("drop resource group rg1")
+}
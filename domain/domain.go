@@ -54,7 +54,10 @@ import (
 	"github.com/pingcap/tidb/util/dbterror"
 	"github.com/pingcap/tidb/util/domainutil"
 	"github.com/pingcap/tidb/util/expensivequery"
+	"github.com/pingcap/tidb/util/kvcache"
 	"github.com/pingcap/tidb/util/logutil"
+	"github.com/pingcap/tidb/util/resourcegroup"
+	"github.com/pingcap/tidb/util/runaway"
 	"github.com/pingcap/tidb/util/sqlexec"
 	"github.com/tikv/client-go/v2/txnkv/transaction"
 	pd "github.com/tikv/pd/client"
@@ -86,12 +89,15 @@ type Domain struct {
 	sysVarCache          sysVarCache // replaces GlobalVariableCache
 	slowQuery            *topNSlowQueries
 	expensiveQueryHandle *expensivequery.Handle
+	resourceGroupManager *resourcegroup.Manager
+	runawayManager       *runaway.Manager
 	wg                   util.WaitGroupWrapper
 	statsUpdating        atomicutil.Int32
 	cancel               context.CancelFunc
 	indexUsageSyncLease  time.Duration
 	dumpFileGcChecker    *dumpFileGcChecker
 	expiredTimeStamp4PC  types.Time
+	instancePlanCache    *kvcache.ConcurrentLRUCache
 
 	serverID             uint64
 	serverIDSession      *concurrency.Session
@@ -164,7 +170,12 @@ func (do *Domain) loadInfoSchema(startTS uint64) (infoschema.InfoSchema, bool, i
 		return nil, false, currentSchemaVersion, nil, err
 	}
 
-	newISBuilder, err := infoschema.NewBuilder(do.Store(), do.sysFacHack).InitWithDBInfos(schemas, bundles, policies, neededSchemaVersion)
+	resourceGroups, err := m.ListResourceGroups()
+	if err != nil {
+		return nil, false, currentSchemaVersion, nil, err
+	}
+
+	newISBuilder, err := infoschema.NewBuilder(do.Store(), do.sysFacHack).InitWithDBInfos(schemas, bundles, policies, resourceGroups, neededSchemaVersion)
 	if err != nil {
 		return nil, false, currentSchemaVersion, nil, err
 	}
@@ -355,6 +366,11 @@ func (do *Domain) SetExpiredTimeStamp4PC(time types.Time) {
 	do.expiredTimeStamp4PC = time
 }
 
+// InstancePlanCache gets the plan cache shared by all the sessions of the domain.
+func (do *Domain) InstancePlanCache() *kvcache.ConcurrentLRUCache {
+	return do.instancePlanCache
+}
+
 // DDL gets DDL from domain.
 func (do *Domain) DDL() ddl.DDL {
 	return do.ddl
@@ -731,10 +747,16 @@ func NewDomain(store kv.Storage, ddlLease time.Duration, statsLease time.Duratio
 		dumpFileGcChecker:   &dumpFileGcChecker{gcLease: dumpFileGcLease, paths: []string{GetPlanReplayerDirName(), GetOptimizerTraceDirName()}},
 		onClose:             onClose,
 		expiredTimeStamp4PC: types.NewTime(types.ZeroCoreTime, mysql.TypeTimestamp, types.DefaultFsp),
+		instancePlanCache:   kvcache.NewConcurrentLRUCache(config.GetGlobalConfig().PreparedPlanCache.InstanceMaxMemory),
 	}
 
+	do.instancePlanCache.SetOnEvict(func(kvcache.Key, kvcache.Value) {
+		metrics.InstancePlanCacheCounter.WithLabelValues("evict").Inc()
+	})
 	do.SchemaValidator = NewSchemaValidator(ddlLease, do)
-	do.expensiveQueryHandle = expensivequery.NewExpensiveQueryHandle(do.exit)
+	do.runawayManager = runaway.NewManager()
+	do.expensiveQueryHandle = expensivequery.NewExpensiveQueryHandle(do.exit).SetRunawayManager(do.runawayManager)
+	do.resourceGroupManager = resourcegroup.NewManager()
 	do.sysProcesses = SysProcesses{mu: &sync.RWMutex{}, procMap: make(map[uint64]sessionctx.Context)}
 	return do
 }
@@ -1126,6 +1148,16 @@ func (do *Domain) globalBindHandleWorkerLoop(owner owner.Manager) {
 				if err == nil && variable.TiDBOptOn(optVal) {
 					do.bindHandle.CaptureBaselines()
 				}
+				optVal, err = do.GetGlobalVar(variable.TiDBFixPlanRegressions)
+				if err == nil && variable.TiDBOptOn(optVal) {
+					ratio := variable.DefTiDBPlanRegressionRatio
+					if ratioVal, err := do.GetGlobalVar(variable.TiDBPlanRegressionRatio); err == nil {
+						if r, err := strconv.ParseFloat(ratioVal, 64); err == nil {
+							ratio = r
+						}
+					}
+					do.bindHandle.DetectPlanRegressions(ratio)
+				}
 				do.bindHandle.SaveEvolveTasksToStore()
 			case <-gcBindTicker.C:
 				if !owner.IsOwner() {
@@ -1165,6 +1197,50 @@ func (do *Domain) handleEvolvePlanTasksLoop(ctx sessionctx.Context, owner owner.
 	}()
 }
 
+// runawayUpdateInterval is the interval to synchronize the runaway query
+// rules, quarantines and history with the system tables.
+const runawayUpdateInterval = 10 * time.Second
+
+// LoadRunawayLoop loads the runaway query rules and quarantines, and writes
+// the actions taken on runaway queries to the history in a loop. It should be
+// called only once in BootstrapSession.
+func (do *Domain) LoadRunawayLoop(ctx sessionctx.Context) error {
+	ctx.GetSessionVars().InRestrictedSQL = true
+	if err := do.runawayManager.Update(ctx); err != nil {
+		return err
+	}
+	do.wg.Add(1)
+	go func() {
+		defer func() {
+			do.wg.Done()
+			logutil.BgLogger().Info("LoadRunawayLoop exited.")
+			util.Recover(metrics.LabelDomain, "LoadRunawayLoop", nil, false)
+		}()
+		ticker := time.NewTicker(runawayUpdateInterval)
+		defer ticker.Stop()
+		var instance string
+		for {
+			select {
+			case <-do.exit:
+				return
+			case <-ticker.C:
+				if instance == "" {
+					if serverInfo, err := infosync.GetServerInfo(); err == nil {
+						instance = serverInfo.IP + ":" + strconv.FormatUint(uint64(serverInfo.Port), 10)
+					}
+				}
+				if err := do.runawayManager.Flush(ctx, instance); err != nil {
+					logutil.BgLogger().Warn("write runaway query history failed", zap.Error(err))
+				}
+				if err := do.runawayManager.Update(ctx); err != nil {
+					logutil.BgLogger().Warn("load runaway query rules failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+	return nil
+}
+
 // TelemetryReportLoop create a goroutine that reports usage data in a loop, it should be called only once
 // in BootstrapSession.
 func (do *Domain) TelemetryReportLoop(ctx sessionctx.Context) {
@@ -1531,6 +1607,16 @@ func (do *Domain) ExpensiveQueryHandle() *expensivequery.Handle {
 	return do.expensiveQueryHandle
 }
 
+// ResourceGroupManager returns the resource group manager.
+func (do *Domain) ResourceGroupManager() *resourcegroup.Manager {
+	return do.resourceGroupManager
+}
+
+// RunawayManager returns the runaway query manager.
+func (do *Domain) RunawayManager() *runaway.Manager {
+	return do.runawayManager
+}
+
 const (
 	privilegeKey   = "/tidb/privilege"
 	sysVarCacheKey = "/tidb/sysvars"
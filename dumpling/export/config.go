@@ -71,6 +71,11 @@ const (
 	flagReadTimeout              = "read-timeout"
 	flagTransactionalConsistency = "transactional-consistency"
 	flagCompress                 = "compress"
+	flagCompressLevel            = "compress-level"
+	flagIncrementalColumn        = "incremental-column"
+	flagIncrementalFrom          = "incremental-from"
+	flagIncrementalSafetyLag     = "incremental-safety-lag"
+	flagIncrementalSkipDeletes   = "incremental-skip-deletes"
 
 	// FlagHelp represents the help flag
 	FlagHelp = "help"
@@ -94,6 +99,7 @@ type Config struct {
 	DumpEmptyDatabase        bool
 	PosAfterConnect          bool
 	CompressType             storage.CompressType
+	CompressLevel            int
 
 	Host     string
 	Port     int
@@ -125,6 +131,8 @@ type Config struct {
 	TableFilter        filter.Filter `json:"-"`
 	Where              string
 	FileType           string
+	IncrementalColumn  string
+	IncrementalFrom    string
 	ServerInfo         version.ServerInfo
 	Logger             *zap.Logger        `json:"-"`
 	OutputFileTemplate *template.Template `json:"-"`
@@ -138,6 +146,12 @@ type Config struct {
 	Tables             DatabaseTables
 
 	CollationCompatible string
+
+	IncrementalSafetyLag   time.Duration
+	IncrementalSkipDeletes bool
+	// incrementalWhere is the predicate of the rows changed since the previous dump, keyed by the
+	// database and table names. The tables without the incremental column are dumped entirely.
+	incrementalWhere map[string]map[string]string
 }
 
 // ServerInfoUnknown is the unknown database type to dumpling
@@ -182,6 +196,8 @@ func DefaultConfig() *Config {
 		OutputFileTemplate:  DefaultOutputFileTemplate,
 		PosAfterConnect:     false,
 		CollationCompatible: LooseCollationCompatible,
+
+		IncrementalSafetyLag: time.Minute,
 	}
 }
 
@@ -240,7 +256,7 @@ func (conf *Config) DefineFlags(flags *pflag.FlagSet) {
 		"If not specified, dumpling will dump table without inner-concurrency which could be relatively slow. default unlimited")
 	flags.String(flagWhere, "", "Dump only selected records")
 	flags.Bool(flagEscapeBackslash, true, "use backslash to escape special characters")
-	flags.String(flagFiletype, "", "The type of export file (sql/csv)")
+	flags.String(flagFiletype, "", "The type of export file (sql/csv/parquet)")
 	flags.Bool(flagNoHeader, false, "whether not to dump CSV table header")
 	flags.BoolP(flagNoSchemas, "m", false, "Do not dump table schemas with the data")
 	flags.BoolP(flagNoData, "d", false, "Do not dump table data")
@@ -264,7 +280,12 @@ func (conf *Config) DefineFlags(flags *pflag.FlagSet) {
 	_ = flags.MarkHidden(flagReadTimeout)
 	flags.Bool(flagTransactionalConsistency, true, "Only support transactional consistency")
 	_ = flags.MarkHidden(flagTransactionalConsistency)
-	flags.StringP(flagCompress, "c", "", "Compress output file type, support 'gzip', 'no-compression' now")
+	flags.StringP(flagCompress, "c", "", "Compress output file type, support 'gzip', 'snappy', 'zstd', 'no-compression' now")
+	flags.Int(flagCompressLevel, storage.DefaultCompressLevel, "The compression level of 'gzip' (1-9) and 'zstd' (1-22) for the sql and csv files, 0 means the default level")
+	flags.String(flagIncrementalColumn, "", "The column that records the update time of the rows, the watermarks of the tables are recorded in metadata for the next incremental dump")
+	flags.String(flagIncrementalFrom, "", "The metadata file of the previous dump, only the rows whose incremental column changed since the watermarks of their tables are dumped")
+	flags.Duration(flagIncrementalSafetyLag, time.Minute, "The lag subtracted from the max value of the incremental column to get the watermark, it should cover the longest transaction which updates the incremental column")
+	flags.Bool(flagIncrementalSkipDeletes, false, "Allow the incremental dump which only dumps the inserted and updated rows, the rows deleted since the previous dump are not dumped")
 }
 
 // ParseFromFlags parses dumpling's export.Config from flags
@@ -359,6 +380,22 @@ func (conf *Config) ParseFromFlags(flags *pflag.FlagSet) error {
 	if err != nil {
 		return errors.Trace(err)
 	}
+	conf.IncrementalColumn, err = flags.GetString(flagIncrementalColumn)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	conf.IncrementalFrom, err = flags.GetString(flagIncrementalFrom)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	conf.IncrementalSafetyLag, err = flags.GetDuration(flagIncrementalSafetyLag)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	conf.IncrementalSkipDeletes, err = flags.GetBool(flagIncrementalSkipDeletes)
+	if err != nil {
+		return errors.Trace(err)
+	}
 	conf.NoHeader, err = flags.GetBool(flagNoHeader)
 	if err != nil {
 		return errors.Trace(err)
@@ -487,6 +524,13 @@ func (conf *Config) ParseFromFlags(flags *pflag.FlagSet) error {
 	if err != nil {
 		return errors.Trace(err)
 	}
+	conf.CompressLevel, err = flags.GetInt(flagCompressLevel)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if conf.CompressLevel != storage.DefaultCompressLevel && conf.CompressType != storage.Gzip && conf.CompressType != storage.Zstd {
+		return errors.Errorf("--compress-level is only supported by 'gzip' and 'zstd', but the compress type is '%s'", compressType)
+	}
 
 	for k, v := range params {
 		conf.SessionParams[k] = v
@@ -543,6 +587,10 @@ func ParseCompressType(compressType string) (storage.CompressType, error) {
 		return storage.NoCompression, nil
 	case "gzip", "gz":
 		return storage.Gzip, nil
+	case "snappy":
+		return storage.Snappy, nil
+	case "zstd", "zst":
+		return storage.Zstd, nil
 	default:
 		return storage.NoCompression, errors.Errorf("unknown compress type %s", compressType)
 	}
@@ -653,7 +701,7 @@ func adjustFileFormat(conf *Config) error {
 		if conf.SQL != "" {
 			return errors.Errorf("unsupported config.FileType '%s' when we specify --sql, please unset --filetype or set it to 'csv'", conf.FileType)
 		}
-	case FileFormatCSVString:
+	case FileFormatCSVString, FileFormatParquetString:
 	default:
 		return errors.Errorf("unknown config.FileType '%s'", conf.FileType)
 	}
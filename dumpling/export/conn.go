@@ -20,6 +20,12 @@ type BaseConn struct {
 	rebuildConnFn func(*sql.Conn, bool) (*sql.Conn, error)
 }
 
+// NewBaseConn creates a BaseConn which doesn't rebuild the connection on errors, it's used to
+// reuse the queries of dumpling in other tools.
+func NewBaseConn(conn *sql.Conn) *BaseConn {
+	return newBaseConn(conn, false, nil)
+}
+
 func newBaseConn(conn *sql.Conn, shouldRetry bool, rebuildConnFn func(*sql.Conn, bool) (*sql.Conn, error)) *BaseConn {
 	baseConn := &BaseConn{DBConn: conn}
 	baseConn.backOffer = newRebuildConnBackOffer(shouldRetry)
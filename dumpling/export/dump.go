@@ -55,6 +55,9 @@ type Dumper struct {
 	selectTiDBTableRegionFunc     func(tctx *tcontext.Context, conn *BaseConn, meta TableMeta) (pkFields []string, pkVals [][]string, err error)
 	totalTables                   int64
 	charsetAndDefaultCollationMap map[string]string
+	// prevWatermarks are the watermarks of the tables in the previous dump, keyed by the quoted table names.
+	// Only the rows changed after them are dumped.
+	prevWatermarks map[string]string
 }
 
 // NewDumper returns a new Dumper
@@ -82,6 +85,7 @@ func NewDumper(ctx context.Context, conf *Config) (*Dumper, error) {
 	err := adjustConfig(conf,
 		registerTLSConfig,
 		validateSpecifiedSQL,
+		validateIncremental,
 		adjustFileFormat)
 	if err != nil {
 		return nil, err
@@ -89,6 +93,7 @@ func NewDumper(ctx context.Context, conf *Config) (*Dumper, error) {
 	err = runSteps(d,
 		initLogger,
 		createExternalStore,
+		readPrevWatermarks,
 		startHTTPService,
 		openSQLDB,
 		detectServerInfo,
@@ -124,7 +129,7 @@ func (d *Dumper) Dump() (dumpErr error) {
 
 	// for consistency lock, we should get table list at first to generate the lock tables SQL
 	if conf.Consistency == consistencyTypeLock {
-		conn, err = createConnWithConsistency(tctx, pool, repeatableRead)
+		conn, err = CreateConnWithConsistency(tctx, pool, repeatableRead)
 		if err != nil {
 			return errors.Trace(err)
 		}
@@ -150,7 +155,7 @@ func (d *Dumper) Dump() (dumpErr error) {
 		}
 	}()
 
-	metaConn, err := createConnWithConsistency(tctx, pool, repeatableRead)
+	metaConn, err := CreateConnWithConsistency(tctx, pool, repeatableRead)
 	if err != nil {
 		return err
 	}
@@ -166,7 +171,6 @@ func (d *Dumper) Dump() (dumpErr error) {
 	if err != nil {
 		tctx.L().Info("get global metadata failed", log.ShortError(err))
 	}
-
 	if d.conf.CollationCompatible == StrictCollationCompatible {
 		//init charset and default collation map
 		d.charsetAndDefaultCollationMap, err = GetCharsetAndDefaultCollation(tctx.Context, metaConn)
@@ -181,6 +185,12 @@ func (d *Dumper) Dump() (dumpErr error) {
 			return err
 		}
 	}
+	// the watermarks are read from the tables to dump in the snapshot
+	if conf.IncrementalColumn != "" {
+		if err = d.setIncrementalWatermarks(tctx, metaConn, m); err != nil {
+			return err
+		}
+	}
 	if err = d.renewSelectTableRegionFuncForLowerTiDB(tctx); err != nil {
 		tctx.L().Info("cannot update select table region info for TiDB", log.ShortError(err))
 	}
@@ -195,7 +205,7 @@ func (d *Dumper) Dump() (dumpErr error) {
 		}
 		// give up the last broken connection
 		conn.Close()
-		newConn, err1 := createConnWithConsistency(tctx, pool, repeatableRead)
+		newConn, err1 := CreateConnWithConsistency(tctx, pool, repeatableRead)
 		if err1 != nil {
 			return conn, errors.Trace(err1)
 		}
@@ -286,7 +296,7 @@ func (d *Dumper) startWriters(tctx *tcontext.Context, wg *errgroup.Group, taskCh
 	conf, pool := d.conf, d.dbHandle
 	writers := make([]*Writer, conf.Threads)
 	for i := 0; i < conf.Threads; i++ {
-		conn, err := createConnWithConsistency(tctx, pool, needRepeatableRead(conf.ServerInfo.ServerType, conf.Consistency))
+		conn, err := CreateConnWithConsistency(tctx, pool, needRepeatableRead(conf.ServerInfo.ServerType, conf.Consistency))
 		if err != nil {
 			return nil, func() {}, err
 		}
@@ -736,13 +746,13 @@ func (d *Dumper) concurrentDumpTable(tctx *tcontext.Context, conn *BaseConn, met
 
 	chunkIndex := 0
 	nullValueCondition := ""
-	if conf.Where == "" {
+	if conf.tableWhere(db, tbl) == "" {
 		nullValueCondition = fmt.Sprintf("`%s` IS NULL OR ", escapeString(field))
 	}
 	for max.Cmp(cutoff) >= 0 {
 		nextCutOff := new(big.Int).Add(cutoff, bigEstimatedStep)
 		where := fmt.Sprintf("%s(`%s` >= %d AND `%s` < %d)", nullValueCondition, escapeString(field), cutoff, escapeString(field), nextCutOff)
-		query := buildSelectQuery(db, tbl, selectField, "", buildWhereCondition(conf, where), orderByClause)
+		query := buildSelectQuery(db, tbl, selectField, "", buildWhereCondition(conf, db, tbl, where), orderByClause)
 		if len(nullValueCondition) > 0 {
 			nullValueCondition = ""
 		}
@@ -774,8 +784,8 @@ func (d *Dumper) selectMinAndMaxIntValue(tctx *tcontext.Context, conn *BaseConn,
 	conf, zero := d.conf, &big.Int{}
 	query := fmt.Sprintf("SELECT MIN(`%s`),MAX(`%s`) FROM `%s`.`%s`",
 		escapeString(field), escapeString(field), escapeString(db), escapeString(tbl))
-	if conf.Where != "" {
-		query = fmt.Sprintf("%s WHERE %s", query, conf.Where)
+	if where := conf.tableWhere(db, tbl); where != "" {
+		query = fmt.Sprintf("%s WHERE %s", query, where)
 	}
 	tctx.L().Debug("split chunks", zap.String("query", query))
 
@@ -890,7 +900,7 @@ func (d *Dumper) sendConcurrentDumpTiDBTasks(tctx *tcontext.Context,
 	orderByClause := buildOrderByClauseString(handleColNames)
 
 	for i, w := range where {
-		query := buildSelectQuery(db, tbl, selectField, partition, buildWhereCondition(conf, w), orderByClause)
+		query := buildSelectQuery(db, tbl, selectField, partition, buildWhereCondition(conf, db, tbl, w), orderByClause)
 		task := NewTaskTableData(meta, newTableData(query, selectLen, false), i+startChunkIdx, totalChunk)
 		ctxDone := d.sendTaskToChan(tctx, task, taskChan)
 		if ctxDone {
@@ -1147,6 +1157,13 @@ func dumpTableMeta(tctx *tcontext.Context, conf *Config, conn *BaseConn, db stri
 	if err != nil {
 		return nil, err
 	}
+	var unsignedBigInts map[string]struct{}
+	if table.Type == TableTypeBase && strings.ToLower(conf.FileType) == FileFormatParquetString {
+		unsignedBigInts, err = GetUnsignedBigIntColumns(tctx, conn, db, tbl)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	meta := &tableMeta{
 		avgRowLength:     table.AvgRowLength,
@@ -1156,6 +1173,7 @@ func dumpTableMeta(tctx *tcontext.Context, conf *Config, conn *BaseConn, db stri
 		selectedField:    selectField,
 		selectedLen:      selectLen,
 		hasImplicitRowID: hasImplicitRowID,
+		unsignedBigInts:  unsignedBigInts,
 		specCmts: []string{
 			"/*!40101 SET NAMES binary*/;",
 		},
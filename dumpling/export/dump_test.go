@@ -104,6 +104,21 @@ func TestDumpTableMeta(t *testing.T) {
 		require.Equal(t, "", meta.ShowCreateTable())
 		require.Equal(t, hasImplicitRowID, meta.HasImplicitRowID())
 	}
+
+	// the BIGINT UNSIGNED columns are queried for the parquet format
+	conf.ServerInfo.ServerType = version.ServerTypeMySQL
+	conf.FileType = FileFormatParquetString
+	mock.ExpectQuery("SHOW COLUMNS FROM").
+		WillReturnRows(sqlmock.NewRows([]string{"Field", "Type", "Null", "Key", "Default", "Extra"}).
+			AddRow("id", "bigint(20) unsigned", "YES", "", nil, ""))
+	mock.ExpectQuery(fmt.Sprintf("SELECT \\* FROM `%s`.`%s`", database, table)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery("SELECT COLUMN_NAME FROM INFORMATION_SCHEMA.COLUMNS").WithArgs(database, table).
+		WillReturnRows(sqlmock.NewRows([]string{"COLUMN_NAME"}).AddRow("ID"))
+	meta, err := dumpTableMeta(tctx, conf, baseConn, database, &TableInfo{Type: TableTypeBase, Name: table})
+	require.NoError(t, err)
+	require.True(t, meta.(*tableMeta).isUnsignedBigInt("id"))
+	require.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestGetListTableTypeByConf(t *testing.T) {
@@ -0,0 +1,298 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pingcap/errors"
+	"go.uber.org/zap"
+
+	"github.com/pingcap/tidb/br/pkg/storage"
+	tcontext "github.com/pingcap/tidb/dumpling/context"
+)
+
+const (
+	incrementalHeader      = "Incremental:"
+	incrementalColumnField = "Column:"
+	incrementalFromField   = "From:"
+
+	watermarkLayout = "2006-01-02 15:04:05.999999"
+	// watermarkQueryFormat formats the time in the time zone of the session, so the watermark
+	// can be compared with the TIMESTAMP and DATETIME columns directly.
+	watermarkQueryFormat = "%Y-%m-%d %H:%i:%s.%f"
+	// minWatermark is the watermark when no rows are dumped, which is the min value of DATETIME.
+	minWatermark = "1000-01-01 00:00:00"
+)
+
+// validateIncremental checks the config of the incremental dump.
+func validateIncremental(conf *Config) error {
+	if conf.IncrementalFrom != "" && conf.IncrementalColumn == "" {
+		return errors.New("--incremental-from must be used with --incremental-column")
+	}
+	if conf.IncrementalColumn != "" && conf.SQL != "" {
+		return errors.New("can't specify both --sql and --incremental-column at the same time")
+	}
+	if conf.IncrementalSafetyLag < 0 {
+		return errors.New("--incremental-safety-lag can't be negative")
+	}
+	// the deleted rows are invisible to the incremental column, so the incremental dump can't write them.
+	// It's a partial implementation, which only dumps the inserted and updated rows.
+	if conf.IncrementalFrom != "" && !conf.IncrementalSkipDeletes {
+		return errors.New("the incremental dump can't dump the rows deleted since the previous dump, " +
+			"please set --incremental-skip-deletes to dump the inserted and updated rows only")
+	}
+	return nil
+}
+
+// readPrevWatermarks is an initialization step of Dumper.
+// It reads the watermarks of the tables recorded in the metadata file of the previous dump.
+func readPrevWatermarks(d *Dumper) error {
+	conf := d.conf
+	if conf.IncrementalFrom == "" {
+		return nil
+	}
+	content, err := readExternalFile(d.tctx, conf.IncrementalFrom, &conf.BackendOptions)
+	if err != nil {
+		return errors.Annotatef(err, "fail to read the metadata of the previous dump %s", conf.IncrementalFrom)
+	}
+	column, watermarks, err := parseIncrementalMetadata(content)
+	if err != nil {
+		return errors.Annotatef(err, "invalid metadata of the previous dump %s", conf.IncrementalFrom)
+	}
+	if column == "" {
+		return errors.Errorf("no watermark is recorded in the metadata of the previous dump %s, "+
+			"please make sure it's dumped with --incremental-column", conf.IncrementalFrom)
+	}
+	if !strings.EqualFold(column, conf.IncrementalColumn) {
+		return errors.Errorf("the watermark of the previous dump is recorded on column '%s', but --incremental-column is '%s'",
+			column, conf.IncrementalColumn)
+	}
+	d.prevWatermarks = watermarks
+	d.L().Info("dump the rows changed since the previous dump",
+		zap.String("column", column), zap.Int("tables", len(watermarks)))
+	return nil
+}
+
+// readExternalFile reads the file on the external storage, rawPath is the URL of the file
+// in the same format as --output.
+func readExternalFile(ctx context.Context, rawPath string, opts *storage.BackendOptions) ([]byte, error) {
+	u, err := url.Parse(rawPath)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	name := path.Base(u.Path)
+	u.Path = path.Dir(u.Path)
+	backend, err := storage.ParseBackend(u.String(), opts)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	s, err := storage.New(ctx, backend, &storage.ExternalStorageOptions{})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return s.ReadFile(ctx, name)
+}
+
+// parseIncrementalMetadata parses the watermark column and the watermarks of the tables in the metadata file.
+// The watermarks are keyed by the quoted table names. The column is empty if no watermark is recorded.
+func parseIncrementalMetadata(content []byte) (column string, watermarks map[string]string, err error) {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	inSection := false
+	watermarks = make(map[string]string)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == incrementalHeader {
+			inSection = true
+			continue
+		}
+		if !inSection {
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			inSection = false
+		case strings.HasPrefix(trimmed, incrementalColumnField):
+			column = strings.TrimSpace(strings.TrimPrefix(trimmed, incrementalColumnField))
+		case strings.HasPrefix(trimmed, "`"):
+			// the watermark doesn't contain ": ", but the table name may
+			idx := strings.LastIndex(trimmed, ": ")
+			if idx < 0 {
+				return "", nil, errors.Errorf("invalid watermark line '%s'", trimmed)
+			}
+			table, watermark := trimmed[:idx], strings.TrimSpace(trimmed[idx+2:])
+			if _, err = time.Parse(watermarkLayout, watermark); err != nil {
+				return "", nil, errors.Annotatef(err, "invalid watermark '%s' of table %s", watermark, table)
+			}
+			watermarks[table] = watermark
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return "", nil, errors.Trace(err)
+	}
+	if column == "" {
+		return "", nil, nil
+	}
+	return column, watermarks, nil
+}
+
+// recordIncremental records the watermarks of the tables in this dump, the next incremental dump
+// starts from them.
+func (m *globalMetadata) recordIncremental(column, from string, watermarks map[string]string) {
+	m.buffer.WriteString(incrementalHeader + "\n")
+	fmt.Fprintf(&m.buffer, "\t%s %s\n", incrementalColumnField, column)
+	if from != "" {
+		fmt.Fprintf(&m.buffer, "\t%s %s\n", incrementalFromField, from)
+	}
+	tables := make([]string, 0, len(watermarks))
+	for table := range watermarks {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	for _, table := range tables {
+		fmt.Fprintf(&m.buffer, "\t%s: %s\n", table, watermarks[table])
+	}
+	m.buffer.WriteString("\n")
+}
+
+// quoteTableName quotes the table name, which is the key of the watermarks.
+func quoteTableName(db, tbl string) string {
+	return fmt.Sprintf("`%s`.`%s`", escapeString(db), escapeString(tbl))
+}
+
+// getTablesWithColumn gets the base tables to dump which have the column.
+func getTablesWithColumn(tctx *tcontext.Context, conn *sql.Conn, tables DatabaseTables, column string) (map[string]map[string]struct{}, error) {
+	result := make(map[string]map[string]struct{})
+	var schema, table string
+	err := simpleQueryWithArgs(tctx, conn, func(rows *sql.Rows) error {
+		if err := rows.Scan(&schema, &table); err != nil {
+			return errors.Trace(err)
+		}
+		if _, ok := result[schema]; !ok {
+			result[schema] = make(map[string]struct{})
+		}
+		result[schema][table] = struct{}{}
+		return nil
+	}, "SELECT TABLE_SCHEMA, TABLE_NAME FROM INFORMATION_SCHEMA.COLUMNS WHERE COLUMN_NAME = ?", column)
+	if err != nil {
+		return nil, err
+	}
+	for db, tbls := range result {
+		for tbl := range tbls {
+			if !tables.hasBaseTable(db, tbl) {
+				delete(tbls, tbl)
+			}
+		}
+	}
+	return result, nil
+}
+
+// getMaxColumnTime gets the max value of the time column of the table in the session time zone, it's
+// empty if the table has no rows.
+func getMaxColumnTime(tctx *tcontext.Context, conn *sql.Conn, db, tbl, column string) (string, error) {
+	query := fmt.Sprintf("SELECT DATE_FORMAT(MAX(%s), '%s') FROM `%s`.`%s`",
+		wrapBackTicks(escapeString(column)), watermarkQueryFormat, escapeString(db), escapeString(tbl))
+	var maxTime sql.NullString
+	if err := conn.QueryRowContext(tctx, query).Scan(&maxTime); err != nil {
+		return "", errors.Annotatef(err, "sql: %s", query)
+	}
+	return maxTime.String, nil
+}
+
+// buildIncrementalWhere builds the where condition which selects the rows changed since the watermark.
+func buildIncrementalWhere(column, from string) string {
+	return fmt.Sprintf("%s > '%s'", wrapBackTicks(escapeString(column)), from)
+}
+
+// tableWhere returns the where condition of the table, which is --where combined with the incremental
+// predicate of the table.
+func (conf *Config) tableWhere(db, tbl string) string {
+	cond := conf.incrementalWhere[db][tbl]
+	switch {
+	case cond == "":
+		return conf.Where
+	case conf.Where == "":
+		return cond
+	default:
+		return fmt.Sprintf("(%s) AND %s", conf.Where, cond)
+	}
+}
+
+// setIncrementalWatermarks records the watermarks of the tables in this dump. If a table is in the previous
+// dump, only the rows changed since its previous watermark are dumped, otherwise all the rows are dumped as
+// a base. The watermark of a table is the max value of the incremental column in the dumped snapshot minus the
+// safety lag, since a transaction committed after the snapshot may update the column with a time earlier than
+// the snapshot. The rows dumped twice are upserted.
+func (d *Dumper) setIncrementalWatermarks(tctx *tcontext.Context, conn *sql.Conn, m *globalMetadata) error {
+	conf := d.conf
+	tables, err := getTablesWithColumn(tctx, conn, conf.Tables, conf.IncrementalColumn)
+	if err != nil {
+		return err
+	}
+	var (
+		watermarks  = make(map[string]string)
+		incremental = make(map[string]map[string]string)
+	)
+	for db, tbls := range conf.Tables {
+		for _, tbl := range tbls {
+			if tbl.Type != TableTypeBase {
+				continue
+			}
+			if _, ok := tables[db][tbl.Name]; !ok {
+				tctx.L().Warn("the table doesn't have the incremental column, it's dumped entirely",
+					zap.String("database", db), zap.String("table", tbl.Name),
+					zap.String("column", conf.IncrementalColumn))
+				continue
+			}
+			v, err := getMaxColumnTime(tctx, conn, db, tbl.Name, conf.IncrementalColumn)
+			if err != nil {
+				return err
+			}
+			key := quoteTableName(db, tbl.Name)
+			prev, hasPrev := d.prevWatermarks[key]
+			watermark := prev
+			if v != "" {
+				t, err := time.Parse(watermarkLayout, v)
+				if err != nil {
+					return errors.Annotatef(err, "invalid value '%s' of the incremental column of table %s", v, key)
+				}
+				watermark = t.Add(-conf.IncrementalSafetyLag).Format(watermarkLayout)
+			}
+			if watermark == "" {
+				// there are no rows to dump, the next dump starts from the beginning
+				watermark = minWatermark
+			}
+			watermarks[key] = watermark
+
+			switch {
+			case hasPrev:
+				if _, ok := incremental[db]; !ok {
+					incremental[db] = make(map[string]string)
+				}
+				incremental[db][tbl.Name] = buildIncrementalWhere(conf.IncrementalColumn, prev)
+			case conf.IncrementalFrom != "":
+				tctx.L().Warn("the table isn't in the previous dump, it's dumped entirely",
+					zap.String("database", db), zap.String("table", tbl.Name))
+			}
+		}
+	}
+	conf.incrementalWhere = incremental
+
+	m.recordIncremental(conf.IncrementalColumn, conf.IncrementalFrom, watermarks)
+	tctx.L().Info("dump incrementally",
+		zap.String("column", conf.IncrementalColumn),
+		zap.String("from", conf.IncrementalFrom),
+		zap.Int("tables", len(watermarks)),
+		zap.Duration("safety lag", conf.IncrementalSafetyLag))
+	return nil
+}
@@ -0,0 +1,162 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package export
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+
+	tcontext "github.com/pingcap/tidb/dumpling/context"
+)
+
+func TestParseIncrementalMetadata(t *testing.T) {
+	m := newGlobalMetadata(tcontext.Background(), nil, "")
+	m.recordStartTime(time.Now())
+	m.buffer.WriteString("SHOW MASTER STATUS:\n\tLog: tidb-binlog\n\tPos: 433281446521700352\n\tGTID:\n\n")
+	m.recordIncremental("updated_at", "/tmp/dump/metadata", map[string]string{
+		"`test`.`t2`":   "2022-01-02 03:04:05.123456",
+		"`test`.`a: b`": "2022-01-01 00:00:00",
+	})
+	m.recordFinishTime(time.Now())
+	require.Contains(t, m.String(), "Incremental:\n"+
+		"\tColumn: updated_at\n"+
+		"\tFrom: /tmp/dump/metadata\n"+
+		"\t`test`.`a: b`: 2022-01-01 00:00:00\n"+
+		"\t`test`.`t2`: 2022-01-02 03:04:05.123456\n\n")
+
+	column, watermarks, err := parseIncrementalMetadata([]byte(m.String()))
+	require.NoError(t, err)
+	require.Equal(t, "updated_at", column)
+	require.Equal(t, map[string]string{
+		"`test`.`t2`":   "2022-01-02 03:04:05.123456",
+		"`test`.`a: b`": "2022-01-01 00:00:00",
+	}, watermarks)
+
+	// the metadata of a dump without --incremental-column
+	column, _, err = parseIncrementalMetadata([]byte("Started dump at: 2022-01-02 03:04:05\n" +
+		"SHOW MASTER STATUS:\n\tLog: ON.000001\n\tPos: 7502\n\tGTID:\n\n" +
+		"Finished dump at: 2022-01-02 03:04:06\n"))
+	require.NoError(t, err)
+	require.Equal(t, "", column)
+
+	_, _, err = parseIncrementalMetadata([]byte("Incremental:\n\tColumn: updated_at\n\t`test`.`t`: ' OR 1=1\n\n"))
+	require.Error(t, err)
+	_, _, err = parseIncrementalMetadata([]byte("Incremental:\n\tColumn: updated_at\n\t`test`.`t`\n\n"))
+	require.Error(t, err)
+}
+
+func TestTableWhere(t *testing.T) {
+	conf := defaultConfigForTest(t)
+	conf.incrementalWhere = map[string]map[string]string{
+		"test": {"t1": buildIncrementalWhere("a`b", "2022-01-01 00:00:00")},
+	}
+	require.Equal(t, "`a``b` > '2022-01-01 00:00:00'", conf.tableWhere("test", "t1"))
+	require.Equal(t, "", conf.tableWhere("test", "t2"))
+	require.Equal(t, "WHERE (`a``b` > '2022-01-01 00:00:00') AND (id < 5) ", buildWhereCondition(conf, "test", "t1", "id < 5"))
+
+	conf.Where = "id < 5 OR id > 10"
+	require.Equal(t, "(id < 5 OR id > 10) AND `a``b` > '2022-01-01 00:00:00'", conf.tableWhere("test", "t1"))
+	require.Equal(t, "id < 5 OR id > 10", conf.tableWhere("test", "t2"))
+}
+
+func TestValidateIncremental(t *testing.T) {
+	conf := defaultConfigForTest(t)
+	require.NoError(t, validateIncremental(conf))
+
+	conf.IncrementalFrom = "/tmp/metadata"
+	require.EqualError(t, validateIncremental(conf), "--incremental-from must be used with --incremental-column")
+
+	conf.IncrementalColumn = "updated_at"
+	require.EqualError(t, validateIncremental(conf), "the incremental dump can't dump the rows deleted since the previous dump, "+
+		"please set --incremental-skip-deletes to dump the inserted and updated rows only")
+
+	conf.IncrementalSkipDeletes = true
+	require.NoError(t, validateIncremental(conf))
+
+	conf.IncrementalSafetyLag = -time.Second
+	require.EqualError(t, validateIncremental(conf), "--incremental-safety-lag can't be negative")
+
+	conf.SQL = "select * from t"
+	require.EqualError(t, validateIncremental(conf), "can't specify both --sql and --incremental-column at the same time")
+}
+
+func TestSetIncrementalWatermarks(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, db.Close())
+	}()
+	conn, err := db.Conn(context.Background())
+	require.NoError(t, err)
+	tctx := tcontext.Background()
+
+	conf := defaultConfigForTest(t)
+	conf.IncrementalColumn = "updated_at"
+	conf.IncrementalFrom = "/tmp/dump/metadata"
+	conf.Tables = DatabaseTables{}.
+		AppendTables("test", []string{"t1", "t2", "t3", "t4"}, []uint64{0, 0, 0, 0}).
+		AppendViews("test", "v")
+	d := &Dumper{tctx: tctx, conf: conf, prevWatermarks: map[string]string{
+		"`test`.`t1`": "2022-01-01 00:00:00",
+		"`test`.`t3`": "2021-06-01 00:00:00",
+	}}
+
+	mock.ExpectQuery("SELECT TABLE_SCHEMA, TABLE_NAME FROM INFORMATION_SCHEMA.COLUMNS WHERE COLUMN_NAME = \\?").
+		WithArgs("updated_at").
+		WillReturnRows(sqlmock.NewRows([]string{"TABLE_SCHEMA", "TABLE_NAME"}).
+			AddRow("test", "t1").AddRow("test", "t3").AddRow("test", "t4").AddRow("test", "v").AddRow("other", "t1"))
+	mock.ExpectQuery("SELECT DATE_FORMAT\\(MAX\\(`updated_at`\\), '%Y-%m-%d %H:%i:%s.%f'\\) FROM `test`.`t1`").
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow("2022-01-02 03:04:05.123456"))
+	mock.ExpectQuery("SELECT DATE_FORMAT\\(MAX\\(`updated_at`\\), '%Y-%m-%d %H:%i:%s.%f'\\) FROM `test`.`t3`").
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(nil))
+	mock.ExpectQuery("SELECT DATE_FORMAT\\(MAX\\(`updated_at`\\), '%Y-%m-%d %H:%i:%s.%f'\\) FROM `test`.`t4`").
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(nil))
+	m := newGlobalMetadata(tctx, nil, "")
+	require.NoError(t, d.setIncrementalWatermarks(tctx, conn, m))
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	_, watermarks, err := parseIncrementalMetadata(m.buffer.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		// the watermark is the max value of the column minus the safety lag
+		"`test`.`t1`": "2022-01-02 03:03:05.123456",
+		// the previous watermark is kept if there are no rows
+		"`test`.`t3`": "2021-06-01 00:00:00",
+		// the next dump starts from the beginning if there are no rows in a new table
+		"`test`.`t4`": "1000-01-01 00:00:00",
+	}, watermarks)
+	// each table is dumped since its own previous watermark
+	require.Equal(t, "`updated_at` > '2022-01-01 00:00:00'", conf.tableWhere("test", "t1"))
+	require.Equal(t, "`updated_at` > '2021-06-01 00:00:00'", conf.tableWhere("test", "t3"))
+	// the table without the incremental column or not in the previous dump is dumped entirely
+	require.Equal(t, "", conf.tableWhere("test", "t2"))
+	require.Equal(t, "", conf.tableWhere("test", "t4"))
+	require.Equal(t, "", conf.tableWhere("test", "v"))
+}
+
+func TestReadPrevWatermarks(t *testing.T) {
+	dir := t.TempDir()
+	metadata := "Started dump at: 2022-01-02 03:04:05\n" +
+		"Incremental:\n\tColumn: updated_at\n\t`test`.`t1`: 2022-01-02 03:04:05.123456\n\n" +
+		"Finished dump at: 2022-01-02 03:04:06\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "metadata"), []byte(metadata), 0o644))
+
+	conf := defaultConfigForTest(t)
+	conf.IncrementalColumn = "UPDATED_AT"
+	conf.IncrementalFrom = filepath.Join(dir, "metadata")
+	d := &Dumper{tctx: tcontext.Background(), conf: conf}
+	require.NoError(t, readPrevWatermarks(d))
+	require.Equal(t, map[string]string{"`test`.`t1`": "2022-01-02 03:04:05.123456"}, d.prevWatermarks)
+
+	conf.IncrementalColumn = "created_at"
+	require.Error(t, readPrevWatermarks(d))
+
+	conf.IncrementalFrom = filepath.Join(dir, "not-exist")
+	require.Error(t, readPrevWatermarks(d))
+}
@@ -259,6 +259,8 @@ type tableMeta struct {
 	showCreateView   string
 	avgRowLength     uint64
 	hasImplicitRowID bool
+	// unsignedBigInts is the lower-case names of the BIGINT UNSIGNED columns, it's only filled for the parquet format.
+	unsignedBigInts map[string]struct{}
 }
 
 func (tm *tableMeta) ColumnTypes() []string {
@@ -200,6 +200,16 @@ func (d DatabaseTables) AppendViews(dbName string, viewNames ...string) Database
 	return d
 }
 
+// hasBaseTable checks whether the basic table is in DatabaseTables
+func (d DatabaseTables) hasBaseTable(dbName, tableName string) bool {
+	for _, t := range d[dbName] {
+		if t.Name == tableName && t.Type == TableTypeBase {
+			return true
+		}
+	}
+	return false
+}
+
 // Merge merges another DatabaseTables
 func (d DatabaseTables) Merge(other DatabaseTables) {
 	for name, infos := range other {
@@ -395,7 +395,7 @@ func SelectVersion(db *sql.DB) (string, error) {
 func SelectAllFromTable(conf *Config, meta TableMeta, partition, orderByClause string) TableDataIR {
 	database, table := meta.DatabaseName(), meta.TableName()
 	selectedField, selectLen := meta.SelectedField(), meta.SelectedLen()
-	query := buildSelectQuery(database, table, selectedField, partition, buildWhereCondition(conf, ""), orderByClause)
+	query := buildSelectQuery(database, table, selectedField, partition, buildWhereCondition(conf, database, table, ""), orderByClause)
 
 	return &tableData{
 		query:  query,
@@ -509,6 +509,28 @@ func GetColumnTypes(tctx *tcontext.Context, db *BaseConn, fields, database, tabl
 	return colTypes, nil
 }
 
+// GetUnsignedBigIntColumns gets the names of the BIGINT UNSIGNED columns from a specified table. The
+// go-sql-driver/mysql reports the same database type name for the signed and unsigned BIGINT columns,
+// and only the NOT NULL unsigned columns are scanned as uint64, so the column types are queried.
+func GetUnsignedBigIntColumns(tctx *tcontext.Context, db *BaseConn, database, table string) (map[string]struct{}, error) {
+	columns := make(map[string]struct{})
+	var columnName string
+	err := db.QuerySQL(tctx, func(rows *sql.Rows) error {
+		if err := rows.Scan(&columnName); err != nil {
+			return errors.Trace(err)
+		}
+		columns[strings.ToLower(columnName)] = struct{}{}
+		return nil
+	}, func() {
+		columns = make(map[string]struct{})
+	}, "SELECT COLUMN_NAME FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND DATA_TYPE = 'bigint' AND COLUMN_TYPE LIKE '%unsigned%'",
+		database, table)
+	if err != nil {
+		return nil, err
+	}
+	return columns, nil
+}
+
 // GetPrimaryKeyAndColumnTypes gets all primary columns and their types in ordinal order
 func GetPrimaryKeyAndColumnTypes(tctx *tcontext.Context, conn *BaseConn, meta TableMeta) ([]string, []string, error) {
 	var (
@@ -880,7 +902,8 @@ func resetDBWithSessionParams(tctx *tcontext.Context, db *sql.DB, dsn string, pa
 	return newDB, errors.Trace(err)
 }
 
-func createConnWithConsistency(ctx context.Context, db *sql.DB, repeatableRead bool) (*sql.Conn, error) {
+// CreateConnWithConsistency creates a connection which starts a consistent snapshot transaction.
+func CreateConnWithConsistency(ctx context.Context, db *sql.DB, repeatableRead bool) (*sql.Conn, error) {
 	conn, err := db.Conn(ctx)
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -1169,9 +1192,9 @@ func estimateCount(tctx *tcontext.Context, dbName, tableName string, db *BaseCon
 		query = fmt.Sprintf("EXPLAIN SELECT `%s` FROM `%s`.`%s`", escapeString(field), escapeString(dbName), escapeString(tableName))
 	}
 
-	if conf.Where != "" {
+	if where := conf.tableWhere(dbName, tableName); where != "" {
 		query += " WHERE "
-		query += conf.Where
+		query += where
 	}
 
 	estRows := detectEstimateRows(tctx, db, query, []string{"rows", "estRows", "count"})
@@ -1268,19 +1291,20 @@ func parseSnapshotToTSO(pool *sql.DB, snapshot string) (uint64, error) {
 	return (uint64(tso.Int64) << 18) * 1000, nil
 }
 
-func buildWhereCondition(conf *Config, where string) string {
+func buildWhereCondition(conf *Config, db, tbl, where string) string {
 	var query strings.Builder
+	tableWhere := conf.tableWhere(db, tbl)
 	separator := "WHERE"
 	leftBracket := " "
 	rightBracket := " "
-	if conf.Where != "" && where != "" {
+	if tableWhere != "" && where != "" {
 		leftBracket = " ("
 		rightBracket = ") "
 	}
-	if conf.Where != "" {
+	if tableWhere != "" {
 		query.WriteString(separator)
 		query.WriteString(leftBracket)
-		query.WriteString(conf.Where)
+		query.WriteString(tableWhere)
 		query.WriteString(rightBracket)
 		separator = "AND"
 	}
@@ -836,7 +836,7 @@ func TestBuildTableSampleQueries(t *testing.T) {
 			}
 
 			for i, w := range testCase.expectedWhereClauses {
-				query := buildSelectQuery(database, table, selectFields, "", buildWhereCondition(d.conf, w), orderByClause)
+				query := buildSelectQuery(database, table, selectFields, "", buildWhereCondition(d.conf, database, table, w), orderByClause)
 				checkQuery(i, query)
 			}
 		}
@@ -931,7 +931,7 @@ func TestBuildWhereCondition(t *testing.T) {
 	}
 	for _, testCase := range testCases {
 		conf.Where = testCase.confWhere
-		where := buildWhereCondition(conf, testCase.chunkWhere)
+		where := buildWhereCondition(conf, "test", "t", testCase.chunkWhere)
 		require.Equal(t, testCase.expectedWhere, where)
 	}
 }
@@ -1083,7 +1083,7 @@ func TestBuildRegionQueriesWithoutPartition(t *testing.T) {
 		require.NoError(t, mock.ExpectationsWereMet())
 
 		for i, w := range testCase.expectedWhereClauses {
-			query := buildSelectQuery(database, table, "*", "", buildWhereCondition(d.conf, w), orderByClause)
+			query := buildSelectQuery(database, table, "*", "", buildWhereCondition(d.conf, database, table, w), orderByClause)
 			task := <-taskChan
 			taskTableData, ok := task.(*TaskTableData)
 			require.True(t, ok)
@@ -1283,7 +1283,7 @@ func TestBuildRegionQueriesWithPartitions(t *testing.T) {
 		chunkIdx := 0
 		for i, partition := range partitions {
 			for _, w := range testCase.expectedWhereClauses[i] {
-				query := buildSelectQuery(database, table, "*", partition, buildWhereCondition(d.conf, w), orderByClause)
+				query := buildSelectQuery(database, table, "*", partition, buildWhereCondition(d.conf, database, table, w), orderByClause)
 				task := <-taskChan
 				taskTableData, ok := task.(*TaskTableData)
 				require.True(t, ok)
@@ -1590,7 +1590,7 @@ func TestBuildVersion3RegionQueries(t *testing.T) {
 
 		chunkIdx := 0
 		for _, w := range testCase.expectedWhereClauses {
-			query := buildSelectQuery(database, table, "*", "", buildWhereCondition(d.conf, w), orderByClause)
+			query := buildSelectQuery(database, table, "*", "", buildWhereCondition(d.conf, database, table, w), orderByClause)
 			task := <-taskChan
 			taskTableData, ok := task.(*TaskTableData)
 			require.True(t, ok)
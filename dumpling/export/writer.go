@@ -50,6 +50,8 @@ func NewWriter(tctx *tcontext.Context, id int64, config *Config, conn *sql.Conn,
 		sw.fileFmt = FileFormatSQLText
 	case FileFormatCSVString:
 		sw.fileFmt = FileFormatCSV
+	case FileFormatParquetString:
+		sw.fileFmt = FileFormatParquet
 	}
 	return sw
 }
@@ -125,7 +127,7 @@ func (w *Writer) WritePolicyMeta(policy, createSQL string) error {
 	if err != nil {
 		return err
 	}
-	return writeMetaToFile(tctx, "placement-policy", createSQL, w.extStorage, fileName+".sql", conf.CompressType)
+	return writeMetaToFile(tctx, "placement-policy", createSQL, w.extStorage, fileName+".sql", conf.CompressType, conf.CompressLevel)
 }
 
 // WriteDatabaseMeta writes database meta to a file
@@ -135,7 +137,7 @@ func (w *Writer) WriteDatabaseMeta(db, createSQL string) error {
 	if err != nil {
 		return err
 	}
-	return writeMetaToFile(tctx, db, createSQL, w.extStorage, fileName+".sql", conf.CompressType)
+	return writeMetaToFile(tctx, db, createSQL, w.extStorage, fileName+".sql", conf.CompressType, conf.CompressLevel)
 }
 
 // WriteTableMeta writes table meta to a file
@@ -145,7 +147,7 @@ func (w *Writer) WriteTableMeta(db, table, createSQL string) error {
 	if err != nil {
 		return err
 	}
-	return writeMetaToFile(tctx, db, createSQL, w.extStorage, fileName+".sql", conf.CompressType)
+	return writeMetaToFile(tctx, db, createSQL, w.extStorage, fileName+".sql", conf.CompressType, conf.CompressLevel)
 }
 
 // WriteViewMeta writes view meta to a file
@@ -159,11 +161,11 @@ func (w *Writer) WriteViewMeta(db, view, createTableSQL, createViewSQL string) e
 	if err != nil {
 		return err
 	}
-	err = writeMetaToFile(tctx, db, createTableSQL, w.extStorage, fileNameTable+".sql", conf.CompressType)
+	err = writeMetaToFile(tctx, db, createTableSQL, w.extStorage, fileNameTable+".sql", conf.CompressType, conf.CompressLevel)
 	if err != nil {
 		return err
 	}
-	return writeMetaToFile(tctx, db, createViewSQL, w.extStorage, fileNameView+".sql", conf.CompressType)
+	return writeMetaToFile(tctx, db, createViewSQL, w.extStorage, fileNameView+".sql", conf.CompressType, conf.CompressLevel)
 }
 
 // WriteSequenceMeta writes sequence meta to a file
@@ -173,7 +175,7 @@ func (w *Writer) WriteSequenceMeta(db, sequence, createSQL string) error {
 	if err != nil {
 		return err
 	}
-	return writeMetaToFile(tctx, db, createSQL, w.extStorage, fileName+".sql", conf.CompressType)
+	return writeMetaToFile(tctx, db, createSQL, w.extStorage, fileName+".sql", conf.CompressType, conf.CompressLevel)
 }
 
 // WriteTableData writes table data to a file with retry
@@ -222,9 +224,15 @@ func (w *Writer) tryToWriteTableData(tctx *tcontext.Context, meta TableMeta, ir
 		return err
 	}
 
+	compressType := conf.CompressType
+	if format == FileFormatParquet {
+		// parquet file compresses its pages by itself
+		compressType = storage.NoCompression
+	}
+
 	somethingIsWritten := false
 	for {
-		fileWriter, tearDown := buildInterceptFileWriter(tctx, w.extStorage, fileName, conf.CompressType)
+		fileWriter, tearDown := buildInterceptFileWriter(tctx, w.extStorage, fileName, compressType, conf.CompressLevel)
 		n, err := format.WriteInsert(tctx, conf, meta, ir, fileWriter)
 		tearDown(tctx)
 		if err != nil {
@@ -259,8 +267,8 @@ func (w *Writer) tryToWriteTableData(tctx *tcontext.Context, meta TableMeta, ir
 	return nil
 }
 
-func writeMetaToFile(tctx *tcontext.Context, target, metaSQL string, s storage.ExternalStorage, path string, compressType storage.CompressType) error {
-	fileWriter, tearDown, err := buildFileWriter(tctx, s, path, compressType)
+func writeMetaToFile(tctx *tcontext.Context, target, metaSQL string, s storage.ExternalStorage, path string, compressType storage.CompressType, compressLevel int) error {
+	fileWriter, tearDown, err := buildFileWriter(tctx, s, path, compressType, compressLevel)
 	if err != nil {
 		return errors.Trace(err)
 	}
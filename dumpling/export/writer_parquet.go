@@ -0,0 +1,507 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package export
+
+import (
+	"database/sql"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/xitongsys/parquet-go-source/writerfile"
+	"github.com/xitongsys/parquet-go/common"
+	"github.com/xitongsys/parquet-go/layout"
+	"github.com/xitongsys/parquet-go/marshal"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/schema"
+	"github.com/xitongsys/parquet-go/writer"
+	"go.uber.org/zap"
+
+	"github.com/pingcap/tidb/br/pkg/lightning/mydump"
+	"github.com/pingcap/tidb/br/pkg/storage"
+	"github.com/pingcap/tidb/br/pkg/summary"
+	tcontext "github.com/pingcap/tidb/dumpling/context"
+	"github.com/pingcap/tidb/dumpling/log"
+)
+
+const (
+	parquetMaxRowGroupSize = 128 * 1024 * 1024
+	parquetPageSize        = 8 * 1024
+	// parquetMarshalConcurrency is the number of goroutines used to encode the buffered rows into pages.
+	parquetMarshalConcurrency = 2
+
+	parquetRootName  = "parquet_go_root"
+	parquetDateFmt   = "2006-01-02"
+	parquetTimeFmt   = "2006-01-02 15:04:05.999999"
+	parquetDaySecond = 24 * 60 * 60
+)
+
+var uint64Type = reflect.TypeOf(uint64(0))
+
+// sqlColumnTypesGetter is implemented by the TableMeta which knows the detailed column types,
+// e.g. the precision and scale of decimal columns.
+type sqlColumnTypesGetter interface {
+	sqlColumnTypes() []*sql.ColumnType
+}
+
+func (tm *tableMeta) sqlColumnTypes() []*sql.ColumnType {
+	return tm.colTypes
+}
+
+// unsignedBigIntChecker is implemented by the TableMeta which knows the BIGINT UNSIGNED columns.
+type unsignedBigIntChecker interface {
+	isUnsignedBigInt(name string) bool
+}
+
+func (tm *tableMeta) isUnsignedBigInt(name string) bool {
+	_, ok := tm.unsignedBigInts[strings.ToLower(name)]
+	return ok
+}
+
+// parquetColumn is a column in parquet file, convert converts the raw value got from database
+// to the value of the parquet physical type.
+type parquetColumn struct {
+	schema  *parquet.SchemaElement
+	convert func(raw []byte) (interface{}, error)
+}
+
+func newParquetSchemaElement(name string, tp parquet.Type) *parquet.SchemaElement {
+	se := parquet.NewSchemaElement()
+	se.Name = name
+	se.Type = parquet.TypePtr(tp)
+	se.RepetitionType = parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_OPTIONAL)
+	numChildren := int32(0)
+	se.NumChildren = &numChildren
+	return se
+}
+
+func newParquetStringColumn(name string) parquetColumn {
+	se := newParquetSchemaElement(name, parquet.Type_BYTE_ARRAY)
+	se.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_UTF8)
+	se.LogicalType = &parquet.LogicalType{STRING: parquet.NewStringType()}
+	return parquetColumn{schema: se, convert: convertParquetBytes}
+}
+
+// newParquetColumn maps the column type of database to the parquet type. The BIGINT UNSIGNED column
+// is either told by unsigned or by the scan type of the NOT NULL column, see sql.ColumnType.ScanType.
+func newParquetColumn(name, typeName string, colType *sql.ColumnType, unsigned bool) parquetColumn {
+	switch typeName {
+	case "BIGINT", "INT8":
+		if unsigned || (colType != nil && colType.ScanType() == uint64Type) {
+			se := newParquetSchemaElement(name, parquet.Type_INT64)
+			se.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_UINT_64)
+			se.LogicalType = &parquet.LogicalType{INTEGER: &parquet.IntType{BitWidth: 64, IsSigned: false}}
+			return parquetColumn{schema: se, convert: convertParquetUint64}
+		}
+		return parquetColumn{
+			schema:  newParquetSchemaElement(name, parquet.Type_INT64),
+			convert: convertParquetInt64,
+		}
+	case "YEAR", "SQL_TSI_YEAR", "BOOL", "BOOLEAN":
+		return parquetColumn{
+			schema:  newParquetSchemaElement(name, parquet.Type_INT64),
+			convert: convertParquetInt64,
+		}
+	case "FLOAT":
+		return parquetColumn{
+			schema:  newParquetSchemaElement(name, parquet.Type_FLOAT),
+			convert: convertParquetFloat,
+		}
+	case "DOUBLE", "REAL", "DOUBLE PRECISION":
+		return parquetColumn{
+			schema:  newParquetSchemaElement(name, parquet.Type_DOUBLE),
+			convert: convertParquetDouble,
+		}
+	case "DECIMAL", "NUMERIC", "FIXED":
+		if colType == nil {
+			return newParquetStringColumn(name)
+		}
+		precision, scale, ok := colType.DecimalSize()
+		if !ok {
+			return newParquetStringColumn(name)
+		}
+		se := newParquetSchemaElement(name, parquet.Type_BYTE_ARRAY)
+		p, s := int32(precision), int32(scale)
+		se.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_DECIMAL)
+		se.LogicalType = &parquet.LogicalType{DECIMAL: &parquet.DecimalType{Precision: p, Scale: s}}
+		se.Precision, se.Scale = &p, &s
+		return parquetColumn{schema: se, convert: func(raw []byte) (interface{}, error) {
+			return convertParquetDecimal(raw, int(s))
+		}}
+	case "DATE":
+		se := newParquetSchemaElement(name, parquet.Type_INT32)
+		se.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_DATE)
+		se.LogicalType = &parquet.LogicalType{DATE: parquet.NewDateType()}
+		return parquetColumn{schema: se, convert: convertParquetDate}
+	case "DATETIME", "TIMESTAMP":
+		// the values are the local time in the time zone of the dump session, so they are not adjusted
+		// to UTC, and the ConvertedType TIMESTAMP_MICROS is not set since it implies the UTC adjustment.
+		se := newParquetSchemaElement(name, parquet.Type_INT64)
+		se.LogicalType = &parquet.LogicalType{TIMESTAMP: &parquet.TimestampType{
+			IsAdjustedToUTC: false,
+			Unit:            &parquet.TimeUnit{MICROS: parquet.NewMicroSeconds()},
+		}}
+		return parquetColumn{schema: se, convert: convertParquetDatetime}
+	case "JSON":
+		se := newParquetSchemaElement(name, parquet.Type_BYTE_ARRAY)
+		se.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_JSON)
+		se.LogicalType = &parquet.LogicalType{JSON: parquet.NewJsonType()}
+		return parquetColumn{schema: se, convert: convertParquetBytes}
+	case "ENUM":
+		// ConvertedType ENUM isn't supported by the statistics of parquet-go, only set the LogicalType.
+		se := newParquetSchemaElement(name, parquet.Type_BYTE_ARRAY)
+		se.LogicalType = &parquet.LogicalType{ENUM: parquet.NewEnumType()}
+		return parquetColumn{schema: se, convert: convertParquetBytes}
+	}
+	if _, ok := dataTypeInt[typeName]; ok {
+		return parquetColumn{
+			schema:  newParquetSchemaElement(name, parquet.Type_INT64),
+			convert: convertParquetInt64,
+		}
+	}
+	if _, ok := dataTypeBin[typeName]; ok {
+		return parquetColumn{
+			schema:  newParquetSchemaElement(name, parquet.Type_BYTE_ARRAY),
+			convert: convertParquetBytes,
+		}
+	}
+	// TIME, SET, the string types and the unknown types are written as UTF8 strings
+	return newParquetStringColumn(name)
+}
+
+func newParquetColumns(meta TableMeta) ([]parquetColumn, error) {
+	typeNames, names := meta.ColumnTypes(), meta.ColumnNames()
+	if len(typeNames) == 0 {
+		return nil, errors.Errorf("can't dump table %s.%s without columns in parquet format",
+			meta.DatabaseName(), meta.TableName())
+	}
+	if len(names) != len(typeNames) {
+		return nil, errors.Errorf("the count of column names %d and column types %d of table %s.%s mismatch",
+			len(names), len(typeNames), meta.DatabaseName(), meta.TableName())
+	}
+	var colTypes []*sql.ColumnType
+	if getter, ok := meta.(sqlColumnTypesGetter); ok {
+		colTypes = getter.sqlColumnTypes()
+	}
+	checker, hasChecker := meta.(unsignedBigIntChecker)
+	columns := make([]parquetColumn, len(typeNames))
+	for i, typeName := range typeNames {
+		var colType *sql.ColumnType
+		if i < len(colTypes) {
+			colType = colTypes[i]
+		}
+		unsigned := hasChecker && checker.isUnsignedBigInt(names[i])
+		columns[i] = newParquetColumn(names[i], typeName, colType, unsigned)
+	}
+	return columns, nil
+}
+
+func newParquetSchemaHandler(columns []parquetColumn) *schema.SchemaHandler {
+	rootSchema := parquet.NewSchemaElement()
+	rootSchema.Name = rootSchemaInName()
+	numChildren := int32(len(columns))
+	rootSchema.NumChildren = &numChildren
+	rootSchema.RepetitionType = parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED)
+
+	schemaList := make([]*parquet.SchemaElement, 0, len(columns)+1)
+	schemaList = append(schemaList, rootSchema)
+	infos := make([]*common.Tag, 0, len(columns)+1)
+	rootInfo := common.NewTag()
+	rootInfo.InName, rootInfo.ExName = rootSchemaInName(), parquetRootName
+	rootInfo.RepetitionType = parquet.FieldRepetitionType_REQUIRED
+	infos = append(infos, rootInfo)
+	for _, col := range columns {
+		schemaList = append(schemaList, col.schema)
+		info := common.NewTag()
+		info.InName, info.ExName = common.StringToVariableName(col.schema.Name), col.schema.Name
+		info.RepetitionType = parquet.FieldRepetitionType_OPTIONAL
+		infos = append(infos, info)
+	}
+
+	sh := schema.NewSchemaHandlerFromSchemaList(schemaList)
+	sh.Infos = infos
+	sh.CreateInExMap()
+	return sh
+}
+
+func rootSchemaInName() string {
+	return common.StringToVariableName(parquetRootName)
+}
+
+func parquetCompressionCodec(compressType storage.CompressType) (parquet.CompressionCodec, error) {
+	switch compressType {
+	case storage.NoCompression:
+		return parquet.CompressionCodec_UNCOMPRESSED, nil
+	case storage.Gzip:
+		return parquet.CompressionCodec_GZIP, nil
+	case storage.Snappy:
+		return parquet.CompressionCodec_SNAPPY, nil
+	case storage.Zstd:
+		return parquet.CompressionCodec_ZSTD, nil
+	default:
+		return parquet.CompressionCodec_UNCOMPRESSED, errors.Errorf("unsupported compress type %d for parquet", compressType)
+	}
+}
+
+// newParquetWriter creates a writer which writes the rows to w in parquet format. The parquet
+// file is compressed by pages with the codec of cfg.CompressType, and the row group size is
+// limited by cfg.FileSize so that a file split by the file size still has several row groups.
+func newParquetWriter(w *parquetFileWriter, columns []parquetColumn, cfg *Config) (*writer.ParquetWriter, error) {
+	codec, err := parquetCompressionCodec(cfg.CompressType)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	pw := new(writer.ParquetWriter)
+	pw.SchemaHandler = newParquetSchemaHandler(columns)
+	pw.PFile = writerfile.NewWriterFile(w)
+	pw.PageSize = parquetPageSize
+	pw.RowGroupSize = parquetMaxRowGroupSize
+	if cfg.FileSize != UnspecifiedSize && cfg.FileSize < parquetMaxRowGroupSize {
+		pw.RowGroupSize = int64(cfg.FileSize)
+	}
+	pw.CompressionType = codec
+	pw.PagesMapBuf = make(map[string][]*layout.Page)
+	pw.DictRecs = make(map[string]*layout.DictRecType)
+	pw.NP = parquetMarshalConcurrency
+	pw.Footer = parquet.NewFileMetaData()
+	pw.Footer.Version = 1
+	pw.Footer.Schema = append(pw.Footer.Schema, pw.SchemaHandler.SchemaElements...)
+	pw.Offset = 4
+	pw.MarshalFunc = marshal.MarshalCSV
+	if _, err = w.Write([]byte("PAR1")); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return pw, nil
+}
+
+// parquetFileWriter writes the data encoded by the parquet writer to storage.ExternalFileWriter.
+type parquetFileWriter struct {
+	tctx    *tcontext.Context
+	w       storage.ExternalFileWriter
+	labels  prometheus.Labels
+	written uint64
+}
+
+func (p *parquetFileWriter) Write(b []byte) (int, error) {
+	if len(b) == 0 {
+		return 0, nil
+	}
+	begin := time.Now()
+	if err := writeBytes(p.tctx, p.w, b); err != nil {
+		return 0, err
+	}
+	ObserveHistogram(writeTimeHistogram, p.labels, time.Since(begin).Seconds())
+	AddGauge(finishedSizeGauge, p.labels, float64(len(b)))
+	p.written += uint64(len(b))
+	return len(b), nil
+}
+
+func receiverRawBytes(receiver RowReceiverStringer) []byte {
+	switch r := receiver.(type) {
+	case *SQLTypeString:
+		return r.RawBytes
+	case *SQLTypeNumber:
+		return r.RawBytes
+	case *SQLTypeBytes:
+		return r.RawBytes
+	default:
+		return nil
+	}
+}
+
+// WriteInsertInParquet writes TableDataIR to a storage.ExternalFileWriter in parquet type
+func WriteInsertInParquet(pCtx *tcontext.Context, cfg *Config, meta TableMeta, tblIR TableDataIR, w storage.ExternalFileWriter) (n uint64, err error) {
+	fileRowIter := tblIR.Rows()
+	if !fileRowIter.HasNext() {
+		return 0, fileRowIter.Error()
+	}
+
+	columns, err := newParquetColumns(meta)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	fw := &parquetFileWriter{tctx: pCtx, w: w, labels: cfg.Labels}
+	pw, err := newParquetWriter(fw, columns, cfg)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+
+	var (
+		row         = MakeRowReceiver(meta.ColumnTypes())
+		counter     uint64
+		lastCounter uint64
+		// rawSize is the size of the raw values, it's used to estimate the size of the parquet file
+		// since the encoded data is buffered in the row group until it's flushed.
+		rawSize     uint64
+		lastRawSize uint64
+	)
+
+	defer func() {
+		if err != nil {
+			pCtx.L().Warn("fail to dumping table(chunk), will revert some metrics and start a retry if possible",
+				zap.String("database", meta.DatabaseName()),
+				zap.String("table", meta.TableName()),
+				zap.Uint64("finished rows", lastCounter),
+				zap.Uint64("finished size", fw.written),
+				log.ShortError(err))
+			SubGauge(finishedRowsGauge, cfg.Labels, float64(lastCounter))
+			SubGauge(finishedSizeGauge, cfg.Labels, float64(fw.written))
+		} else {
+			pCtx.L().Debug("finish dumping table(chunk)",
+				zap.String("database", meta.DatabaseName()),
+				zap.String("table", meta.TableName()),
+				zap.Uint64("finished rows", counter),
+				zap.Uint64("finished size", fw.written))
+			summary.CollectSuccessUnit(summary.TotalBytes, 1, fw.written)
+			summary.CollectSuccessUnit("total rows", 1, counter)
+		}
+	}()
+
+	for fileRowIter.HasNext() {
+		if err = fileRowIter.Decode(row); err != nil {
+			return counter, errors.Trace(err)
+		}
+		// the parquet writer buffers the records, so a new record is allocated for each row
+		record := make([]interface{}, len(columns))
+		for i, receiver := range row.receivers {
+			raw := receiverRawBytes(receiver)
+			if raw == nil {
+				continue
+			}
+			if record[i], err = columns[i].convert(raw); err != nil {
+				return counter, errors.Annotatef(err, "failed to convert column %s of table %s.%s to parquet",
+					columns[i].schema.Name, meta.DatabaseName(), meta.TableName())
+			}
+			rawSize += uint64(len(raw))
+		}
+		if err = pw.Write(record); err != nil {
+			return counter, errors.Trace(err)
+		}
+		counter++
+
+		if rawSize-lastRawSize >= lengthLimit {
+			select {
+			case <-pCtx.Done():
+				return counter, pCtx.Err()
+			default:
+			}
+			AddGauge(finishedRowsGauge, cfg.Labels, float64(counter-lastCounter))
+			lastCounter = counter
+			lastRawSize = rawSize
+		}
+
+		fileRowIter.Next()
+		if cfg.FileSize != UnspecifiedSize && rawSize >= cfg.FileSize {
+			break
+		}
+	}
+
+	if err = pw.WriteStop(); err != nil {
+		return counter, errors.Trace(err)
+	}
+	AddGauge(finishedRowsGauge, cfg.Labels, float64(counter-lastCounter))
+	lastCounter = counter
+	if err = fileRowIter.Error(); err != nil {
+		return counter, errors.Trace(err)
+	}
+	return counter, nil
+}
+
+func convertParquetBytes(raw []byte) (interface{}, error) {
+	return string(raw), nil
+}
+
+func convertParquetInt64(raw []byte) (interface{}, error) {
+	v, err := strconv.ParseInt(string(raw), 10, 64)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return v, nil
+}
+
+func convertParquetUint64(raw []byte) (interface{}, error) {
+	v, err := strconv.ParseUint(string(raw), 10, 64)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	// the physical type of UINT_64 is INT64
+	return int64(v), nil
+}
+
+func convertParquetFloat(raw []byte) (interface{}, error) {
+	v, err := strconv.ParseFloat(string(raw), 32)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return float32(v), nil
+}
+
+func convertParquetDouble(raw []byte) (interface{}, error) {
+	v, err := strconv.ParseFloat(string(raw), 64)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return v, nil
+}
+
+// convertParquetDecimal encodes the decimal value as the big-endian two's complement of the unscaled value.
+func convertParquetDecimal(raw []byte, scale int) (interface{}, error) {
+	s := string(raw)
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimLeft(s, "+-")
+	intPart, fracPart := s, ""
+	if dot := strings.IndexByte(s, '.'); dot >= 0 {
+		intPart, fracPart = s[:dot], s[dot+1:]
+	}
+	if len(fracPart) > scale {
+		return nil, errors.Errorf("the scale of decimal value %s is larger than %d", raw, scale)
+	}
+	digits := intPart + fracPart + strings.Repeat("0", scale-len(fracPart))
+	unscaled, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return nil, errors.Errorf("invalid decimal value %s", raw)
+	}
+
+	n := len(unscaled.Bytes()) + 1
+	if negative {
+		// 2^(8n) - |v| is the two's complement of v in n bytes
+		unscaled.Sub(new(big.Int).Lsh(big.NewInt(1), uint(8*n)), unscaled)
+	}
+	b := unscaled.FillBytes(make([]byte, n))
+	return string(b), nil
+}
+
+// convertParquetDate converts the date to the days since the unix epoch, the zero date is written as
+// the sentinel mydump.ParquetZeroDate which is converted back to the zero date by lightning.
+func convertParquetDate(raw []byte) (interface{}, error) {
+	if isZeroDate(raw) {
+		return mydump.ParquetZeroDate, nil
+	}
+	t, err := time.Parse(parquetDateFmt, string(raw))
+	if err != nil {
+		return nil, errors.Annotate(err, "the invalid date can't be dumped in parquet format")
+	}
+	return int32(t.Unix() / parquetDaySecond), nil
+}
+
+// convertParquetDatetime converts the datetime to the microseconds since the unix epoch, the zero datetime
+// is written as the sentinel mydump.ParquetZeroDatetime which is converted back to the zero datetime by lightning.
+func convertParquetDatetime(raw []byte) (interface{}, error) {
+	if isZeroDate(raw) {
+		return mydump.ParquetZeroDatetime, nil
+	}
+	t, err := time.Parse(parquetTimeFmt, string(raw))
+	if err != nil {
+		return nil, errors.Annotate(err, "the invalid datetime can't be dumped in parquet format")
+	}
+	return t.UnixMicro(), nil
+}
+
+// isZeroDate checks whether the date or datetime is zero, e.g. "0000-00-00" and "0000-00-00 00:00:00.000".
+func isZeroDate(raw []byte) bool {
+	return len(raw) > 0 && strings.Trim(string(raw), "0-: .") == ""
+}
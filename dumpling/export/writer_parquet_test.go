@@ -0,0 +1,235 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package export
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"math/big"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pingcap/tidb/br/pkg/lightning/mydump"
+	"github.com/pingcap/tidb/br/pkg/storage"
+	"github.com/pingcap/tidb/types"
+	"github.com/stretchr/testify/require"
+)
+
+func newParquetTestTableMeta(t *testing.T, cols ...*sqlmock.Column) TableMeta {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRowsWithColumnDefinition(cols...))
+	rows, err := db.Query("SELECT * FROM `test`.`t`")
+	require.NoError(t, err)
+	defer rows.Close()
+	meta, err := setTableMetaFromRows(rows)
+	require.NoError(t, err)
+	tm := meta.(*tableMeta)
+	tm.database, tm.table = "test", "t"
+	return tm
+}
+
+func readParquetFile(t *testing.T, store storage.ExternalStorage, name string) ([]string, [][]types.Datum) {
+	ctx := context.Background()
+	r, err := store.Open(ctx, name)
+	require.NoError(t, err)
+	parser, err := mydump.NewParquetParser(ctx, store, r, name)
+	require.NoError(t, err)
+	defer parser.Close()
+
+	var rows [][]types.Datum
+	for {
+		err = parser.ReadRow()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		row := parser.LastRow().Row
+		rows = append(rows, append([]types.Datum(nil), row...))
+	}
+	return parser.Columns(), rows
+}
+
+func TestWriteTableDataInParquet(t *testing.T) {
+	meta := newParquetTestTableMeta(t,
+		sqlmock.NewColumn("id").OfType("BIGINT", sql.NullInt64{}),
+		sqlmock.NewColumn("u").OfType("BIGINT", uint64(0)),
+		sqlmock.NewColumn("f").OfType("FLOAT", sql.NullFloat64{}),
+		sqlmock.NewColumn("d").OfType("DECIMAL", sql.RawBytes{}).WithPrecisionAndScale(10, 2),
+		sqlmock.NewColumn("dt").OfType("DATE", sql.RawBytes{}),
+		sqlmock.NewColumn("ts").OfType("DATETIME", sql.RawBytes{}),
+		sqlmock.NewColumn("tm").OfType("TIME", sql.RawBytes{}),
+		sqlmock.NewColumn("j").OfType("JSON", sql.RawBytes{}),
+		sqlmock.NewColumn("e").OfType("ENUM", sql.RawBytes{}),
+		sqlmock.NewColumn("b").OfType("VARBINARY", sql.RawBytes{}),
+		sqlmock.NewColumn("s").OfType("VARCHAR", sql.RawBytes{}),
+		sqlmock.NewColumn("nu").OfType("BIGINT", sql.NullInt64{}),
+	)
+	meta.(*tableMeta).unsignedBigInts = map[string]struct{}{"nu": {}}
+	data := [][]driver.Value{
+		{"1", "18446744073709551615", "1.5", "-123.45", "2022-01-02", "2022-01-02 03:04:05.123456",
+			"-838:59:59", `{"a": [1, 2]}`, "male", "\x00\xff\x80", "abc,\"'", "18446744073709551615"},
+		{"-9223372036854775808", "0", "-0.25", "0.05", "0000-00-00", "0000-00-00 00:00:00",
+			"00:00:01", `null`, "female", "", "", "1"},
+		{nil, "2", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil},
+		{"2", "3", nil, nil, "1969-12-31", "1000-01-01 00:00:00", nil, nil, nil, nil, nil, nil},
+	}
+	tableIR := newMockTableIR("test", "t", data, nil, meta.ColumnTypes())
+
+	for _, compress := range []string{"no-compression", "gzip", "snappy", "zstd"} {
+		dir := t.TempDir()
+		config := defaultConfigForTest(t)
+		config.OutputDirPath = dir
+		config.FileType = FileFormatParquetString
+		var err error
+		config.CompressType, err = ParseCompressType(compress)
+		require.NoError(t, err)
+		tableIR.SQLRowIter = nil
+
+		writer, clean := createTestWriter(config, t)
+		require.NoError(t, writer.WriteTableData(meta, tableIR, 0))
+		clean()
+
+		columns, rows := readParquetFile(t, writer.extStorage, "test.t.000000000.parquet")
+		require.Equal(t, []string{"id", "u", "f", "d", "dt", "ts", "tm", "j", "e", "b", "s", "nu"}, columns)
+		require.Len(t, rows, 4)
+
+		require.Equal(t, int64(1), rows[0][0].GetInt64())
+		require.Equal(t, uint64(18446744073709551615), rows[0][1].GetUint64())
+		require.Equal(t, 1.5, rows[0][2].GetFloat64())
+		require.Equal(t, "-123.45", rows[0][3].GetString())
+		require.Equal(t, "2022-01-02", rows[0][4].GetString())
+		require.Equal(t, "2022-01-02 03:04:05.123456", rows[0][5].GetString())
+		require.Equal(t, "-838:59:59", rows[0][6].GetString())
+		require.Equal(t, `{"a": [1, 2]}`, rows[0][7].GetString())
+		require.Equal(t, "male", rows[0][8].GetString())
+		require.Equal(t, "\x00\xff\x80", rows[0][9].GetString())
+		require.Equal(t, "abc,\"'", rows[0][10].GetString())
+		require.Equal(t, uint64(18446744073709551615), rows[0][11].GetUint64())
+
+		require.Equal(t, int64(-9223372036854775808), rows[1][0].GetInt64())
+		require.Equal(t, uint64(0), rows[1][1].GetUint64())
+		require.Equal(t, -0.25, rows[1][2].GetFloat64())
+		require.Equal(t, "0.05", rows[1][3].GetString())
+		require.Equal(t, "0000-00-00", rows[1][4].GetString())
+		require.Equal(t, "0000-00-00 00:00:00", rows[1][5].GetString())
+		require.Equal(t, "", rows[1][9].GetString())
+		require.Equal(t, uint64(1), rows[1][11].GetUint64())
+
+		require.Equal(t, uint64(2), rows[2][1].GetUint64())
+		for i, d := range rows[2] {
+			if i != 1 {
+				require.True(t, d.IsNull(), "column %d", i)
+			}
+		}
+
+		require.Equal(t, "1969-12-31", rows[3][4].GetString())
+		require.Equal(t, "1000-01-01 00:00:00", rows[3][5].GetString())
+	}
+}
+
+func TestWriteTableDataInParquetWithFileSize(t *testing.T) {
+	dir := t.TempDir()
+	config := defaultConfigForTest(t)
+	config.OutputDirPath = dir
+	config.FileType = FileFormatParquetString
+	config.FileSize = 50
+
+	data := [][]driver.Value{
+		{"1", "male", "bob@mail.com", "020-1234", nil},
+		{"2", "female", "sarah@mail.com", "020-1253", "healthy"},
+		{"3", "male", "john@mail.com", "020-1256", "healthy"},
+		{"4", "female", "sarah@mail.com", "020-1235", "healthy"},
+	}
+	colTypes := []string{"INT", "SET", "VARCHAR", "VARCHAR", "TEXT"}
+	tableIR := newMockTableIR("test", "employee", data, nil, colTypes)
+	tableIR.colNames = []string{"id", "gender", "email", "phone_number", "status"}
+
+	writer, clean := createTestWriter(config, t)
+	defer clean()
+	require.NoError(t, writer.WriteTableData(tableIR, tableIR, 0))
+
+	ids := make([]int64, 0, len(data))
+	for _, name := range []string{"test.employee.000000000.parquet", "test.employee.000000001.parquet"} {
+		columns, rows := readParquetFile(t, writer.extStorage, name)
+		require.Equal(t, tableIR.colNames, columns)
+		require.Len(t, rows, 2)
+		for _, row := range rows {
+			ids = append(ids, row[0].GetInt64())
+		}
+	}
+	require.Equal(t, []int64{1, 2, 3, 4}, ids)
+	exists, err := writer.extStorage.FileExists(context.Background(), "test.employee.000000002.parquet")
+	require.NoError(t, err)
+	require.False(t, exists)
+}
+
+func TestConvertParquetValue(t *testing.T) {
+	for _, tc := range []struct {
+		raw      string
+		scale    int
+		expected string
+	}{
+		{"0", 0, "0"},
+		{"127", 0, "127"},
+		{"128", 0, "128"},
+		{"-128", 0, "-128"},
+		{"-129", 0, "-129"},
+		{"99999999.99", 2, "99999999.99"},
+		{"-99999999.99", 2, "-99999999.99"},
+		{"-0.01", 2, "-0.01"},
+		{"1.5", 3, "1.500"},
+	} {
+		v, err := convertParquetDecimal([]byte(tc.raw), tc.scale)
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, decimalBytesToString(v.(string), tc.scale), tc.raw)
+	}
+	_, err := convertParquetDecimal([]byte("1.234"), 2)
+	require.Error(t, err)
+
+	v, err := convertParquetDate([]byte("0000-00-00"))
+	require.NoError(t, err)
+	require.Equal(t, mydump.ParquetZeroDate, v)
+	v, err = convertParquetDatetime([]byte("0000-00-00 00:00:00.000"))
+	require.NoError(t, err)
+	require.Equal(t, mydump.ParquetZeroDatetime, v)
+	_, err = convertParquetDate([]byte("2022-00-01"))
+	require.Error(t, err)
+	v, err = convertParquetUint64([]byte("18446744073709551615"))
+	require.NoError(t, err)
+	require.Equal(t, int64(-1), v)
+
+	meta := newMockTableIR("test", "t", nil, nil, nil)
+	_, err = newParquetColumns(meta)
+	require.Error(t, err)
+
+	// the decimal column of which the precision is unknown is dumped as string
+	meta = newMockTableIR("test", "t", nil, nil, []string{"DECIMAL", "BLOB"})
+	meta.colNames = []string{"a", "b"}
+	columns, err := newParquetColumns(meta)
+	require.NoError(t, err)
+	require.NotNil(t, columns[0].schema.LogicalType.STRING)
+	require.Nil(t, columns[1].schema.LogicalType)
+}
+
+// decimalBytesToString decodes the big-endian two's complement of the unscaled decimal value.
+func decimalBytesToString(s string, scale int) string {
+	v := new(big.Int).SetBytes([]byte(s))
+	if len(s) > 0 && s[0] >= 0x80 {
+		v.Sub(v, new(big.Int).Lsh(big.NewInt(1), uint(8*len(s))))
+	}
+	sign := ""
+	if v.Sign() < 0 {
+		sign = "-"
+		v.Neg(v)
+	}
+	digits := fmt.Sprintf("%0*s", scale+1, v.String())
+	if scale == 0 {
+		return sign + digits
+	}
+	return sign + digits[:len(digits)-scale] + "." + digits[len(digits)-scale:]
+}
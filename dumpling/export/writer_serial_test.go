@@ -68,6 +68,31 @@ func TestWriteInsert(t *testing.T) {
 	require.Equal(t, ReadGauge(finishedSizeGauge, conf.Labels), float64(len(expected)))
 }
 
+func TestWriteInsertIncrementally(t *testing.T) {
+	cfg, clean := createMockConfig(t)
+	defer clean()
+
+	data := [][]driver.Value{
+		{"1", "male", "bob@mail.com", "020-1234", nil},
+		{"2", "female", "sarah@mail.com", "020-1253", "healthy"},
+	}
+	colTypes := []string{"INT", "SET", "VARCHAR", "VARCHAR", "TEXT"}
+	tableIR := newMockTableIR("test", "employee", data, nil, colTypes)
+	bf := storage.NewBufferWriter()
+
+	conf := configForWriteSQL(cfg, UnspecifiedSize, UnspecifiedSize)
+	conf.IncrementalColumn = "updated_at"
+	conf.IncrementalFrom = "metadata"
+	n, err := WriteInsert(tcontext.Background(), conf, tableIR, tableIR, bf)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), n)
+
+	expected := "REPLACE INTO `employee` VALUES\n" +
+		"(1,'male','bob@mail.com','020-1234',NULL),\n" +
+		"(2,'female','sarah@mail.com','020-1253','healthy');\n"
+	require.Equal(t, expected, bf.String())
+}
+
 func TestWriteInsertReturnsError(t *testing.T) {
 	cfg, clean := createMockConfig(t)
 	defer clean()
@@ -5,6 +5,7 @@ package export
 import (
 	"context"
 	"database/sql/driver"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path"
@@ -12,6 +13,7 @@ import (
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pingcap/tidb/br/pkg/storage"
 	"github.com/stretchr/testify/require"
 
 	tcontext "github.com/pingcap/tidb/dumpling/context"
@@ -144,6 +146,75 @@ func TestWriteTableData(t *testing.T) {
 	require.Equal(t, expected, string(bytes))
 }
 
+func TestWriteTableDataWithCompression(t *testing.T) {
+	data := [][]driver.Value{
+		{"1", "male", "bob@mail.com", "020-1234", nil},
+		{"2", "female", "sarah@mail.com", "020-1253", "healthy"},
+	}
+	colTypes := []string{"INT", "SET", "VARCHAR", "VARCHAR", "TEXT"}
+	expected := "INSERT INTO `employee` VALUES\n" +
+		"(1,'male','bob@mail.com','020-1234',NULL),\n" +
+		"(2,'female','sarah@mail.com','020-1253','healthy');\n"
+
+	for _, compress := range []string{"gzip", "snappy", "zstd"} {
+		dir := t.TempDir()
+		config := defaultConfigForTest(t)
+		config.OutputDirPath = dir
+		var err error
+		config.CompressType, err = ParseCompressType(compress)
+		require.NoError(t, err)
+
+		writer, clean := createTestWriter(config, t)
+		tableIR := newMockTableIR("test", "employee", data, nil, colTypes)
+		err = writer.WriteTableData(tableIR, tableIR, 0)
+		require.NoError(t, err)
+		clean()
+
+		fileName := "test.employee.000000000.sql" + compressFileSuffix(config.CompressType)
+		_, err = os.Stat(path.Join(dir, fileName))
+		require.NoError(t, err)
+		bytes, err := storage.WithCompression(writer.extStorage, config.CompressType).ReadFile(context.Background(), fileName)
+		require.NoError(t, err)
+		require.Equal(t, expected, string(bytes))
+	}
+}
+
+func TestWriteTableDataWithCompressLevel(t *testing.T) {
+	data := make([][]driver.Value, 0, 5000)
+	for i := 0; i < 5000; i++ {
+		data = append(data, []driver.Value{fmt.Sprintf("%d", i), fmt.Sprintf("name-%d-%d", i%97, i*7919%10007)})
+	}
+	colTypes := []string{"INT", "VARCHAR"}
+
+	sizes := make([]int64, 0, 2)
+	var expected []byte
+	for _, level := range []int{1, 19} {
+		dir := t.TempDir()
+		config := defaultConfigForTest(t)
+		config.OutputDirPath = dir
+		config.CompressType = storage.Zstd
+		config.CompressLevel = level
+
+		writer, clean := createTestWriter(config, t)
+		tableIR := newMockTableIR("test", "employee", data, nil, colTypes)
+		require.NoError(t, writer.WriteTableData(tableIR, tableIR, 0))
+		clean()
+
+		fileName := "test.employee.000000000.sql.zst"
+		info, err := os.Stat(path.Join(dir, fileName))
+		require.NoError(t, err)
+		sizes = append(sizes, info.Size())
+		bytes, err := storage.WithCompression(writer.extStorage, config.CompressType).ReadFile(context.Background(), fileName)
+		require.NoError(t, err)
+		if expected == nil {
+			expected = bytes
+		}
+		require.Equal(t, expected, bytes)
+	}
+	// the higher level compresses better, which means the level is used by the zstd encoder
+	require.Less(t, sizes[1], sizes[0])
+}
+
 func TestWriteTableDataWithFileSize(t *testing.T) {
 	dir := t.TempDir()
 	config := defaultConfigForTest(t)
@@ -1064,6 +1064,13 @@ const (
 	ErrPlacementPolicyInUse               = 8241
 	ErrOptOnCacheTable                    = 8242
 	ErrHTTPServiceError                   = 8243
+	ErrResourceGroupExists                = 8244
+	ErrResourceGroupNotExists             = 8245
+	ErrResourceGroupQueryRejected         = 8246
+	ErrRunawayQueryQuarantined            = 8247
+	ErrCannotMigrateSession               = 8248
+	ErrInvalidSessionStates               = 8249
+	ErrResourceGroupInUse                 = 8250
 	// TiKV/PD/TiFlash errors.
 	ErrPDServerTimeout           = 9001
 	ErrTiKVServerTimeout         = 9002
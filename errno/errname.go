@@ -1070,6 +1070,13 @@ var MySQLErrName = map[uint16]*mysql.ErrMessage{
 	ErrPlacementPolicyWithDirectOption: mysql.Message("Placement policy '%s' can't co-exist with direct placement options", nil),
 	ErrPlacementPolicyInUse:            mysql.Message("Placement policy '%-.192s' is still in use", nil),
 	ErrOptOnCacheTable:                 mysql.Message("'%s' is unsupported on cache tables.", nil),
+	ErrResourceGroupExists:             mysql.Message("Resource group '%-.192s' already exists", nil),
+	ErrResourceGroupNotExists:          mysql.Message("Unknown resource group '%-.192s'", nil),
+	ErrResourceGroupQueryRejected:      mysql.Message("Query is rejected by resource group '%-.192s': %s", nil),
+	ErrRunawayQueryQuarantined:         mysql.Message("Query with digest '%s' is quarantined as a runaway query until %s", nil),
+	ErrCannotMigrateSession:            mysql.Message("Cannot migrate the current session: %s", nil),
+	ErrInvalidSessionStates:            mysql.Message("Invalid session states: %s", nil),
+	ErrResourceGroupInUse:              mysql.Message("Resource group '%-.192s' is still used by user '%s'", nil),
 	// TiKV/PD errors.
 	ErrPDServerTimeout:           mysql.Message("PD server timeout", nil),
 	ErrTiKVServerTimeout:         mysql.Message("TiKV server timeout", nil),
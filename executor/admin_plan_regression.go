@@ -0,0 +1,73 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+
+	"github.com/pingcap/tidb/domain"
+	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/sqlexec"
+)
+
+// AdminShowPlanRegressionsExec is an executor for ADMIN SHOW PLAN REGRESSIONS.
+type AdminShowPlanRegressionsExec struct {
+	baseExecutor
+	rows   []chunk.Row
+	cursor int
+	done   bool
+}
+
+// Next implements the Executor Next interface.
+func (e *AdminShowPlanRegressionsExec) Next(ctx context.Context, req *chunk.Chunk) error {
+	req.Reset()
+	if !e.done {
+		e.done = true
+		exec := e.ctx.(sqlexec.RestrictedSQLExecutor)
+		rows, _, err := exec.ExecRestrictedSQL(ctx, nil, `SELECT sql_digest, default_db, original_sql, bind_sql, old_plan_digest, new_plan_digest,
+			old_avg_latency, new_avg_latency, detect_time, status FROM mysql.plan_regressions ORDER BY detect_time, sql_digest`)
+		if err != nil {
+			return err
+		}
+		e.rows = rows
+	}
+	for ; e.cursor < len(e.rows) && !req.IsFull(); e.cursor++ {
+		row := e.rows[e.cursor]
+		for i := 0; i < 6; i++ {
+			req.AppendString(i, row.GetString(i))
+		}
+		req.AppendUint64(6, row.GetUint64(6))
+		req.AppendUint64(7, row.GetUint64(7))
+		req.AppendTime(8, row.GetTime(8))
+		req.AppendString(9, row.GetEnum(9).String())
+	}
+	return nil
+}
+
+// AdminRejectPlanRegressionExec is an executor for ADMIN REJECT PLAN REGRESSION.
+type AdminRejectPlanRegressionExec struct {
+	baseExecutor
+	sqlDigest string
+	done      bool
+}
+
+// Next implements the Executor Next interface.
+func (e *AdminRejectPlanRegressionExec) Next(ctx context.Context, _ *chunk.Chunk) error {
+	if e.done {
+		return nil
+	}
+	e.done = true
+	return domain.GetDomain(e.ctx).BindHandle().RejectPlanRegression(e.sqlDigest)
+}
@@ -711,7 +711,7 @@ func analyzeColumnsPushdown(colExec *AnalyzeColumnsExec) *statistics.AnalyzeResu
 			colExec.handleNDVForSpecialIndexes(specialIndexes, idxNDVPushDownCh)
 		})
 		defer wg.Wait()
-		count, hists, topns, fmSketches, extStats, err := colExec.buildSamplingStats(ranges, collExtStats, specialIndexesOffsets, idxNDVPushDownCh)
+		count, hists, topns, fmSketches, extStats, exprResult, err := colExec.buildSamplingStats(ranges, collExtStats, specialIndexesOffsets, idxNDVPushDownCh)
 		if err != nil {
 			return &statistics.AnalyzeResults{Err: err, Job: colExec.job}
 		}
@@ -735,7 +735,7 @@ func analyzeColumnsPushdown(colExec *AnalyzeColumnsExec) *statistics.AnalyzeResu
 			TopNs: topns[:cLen],
 			Fms:   fmSketches[:cLen],
 		}
-		return &statistics.AnalyzeResults{
+		results := &statistics.AnalyzeResults{
 			TableID:       colExec.tableID,
 			Ars:           []*statistics.AnalyzeResult{colResult, colGroupResult},
 			Job:           colExec.job,
@@ -746,6 +746,12 @@ func analyzeColumnsPushdown(colExec *AnalyzeColumnsExec) *statistics.AnalyzeResu
 			BaseCount:     colExec.baseCount,
 			BaseModifyCnt: colExec.baseModifyCnt,
 		}
+		// The expression statistics are saved like the statistics of hidden virtual columns.
+		if exprResult != nil {
+			results.Ars = append(results.Ars, exprResult)
+			results.ExprsInfo = colExec.exprsInfo
+		}
+		return results
 	}
 	hists, cms, topNs, fms, extStats, err := colExec.buildStats(ranges, collExtStats)
 	if err != nil {
@@ -828,6 +834,10 @@ type AnalyzeColumnsExec struct {
 	schemaForVirtualColEval *expression.Schema
 	baseCount               int64
 	baseModifyCnt           int64
+
+	// exprs are the expressions to analyze and exprsInfo are the hidden virtual columns carrying their statistics.
+	exprs     []expression.Expression
+	exprsInfo []*model.ColumnInfo
 }
 
 func (e *AnalyzeColumnsExec) open(ranges []*ranger.Range) error {
@@ -946,10 +956,11 @@ func (e *AnalyzeColumnsExec) buildSamplingStats(
 	topns []*statistics.TopN,
 	fmSketches []*statistics.FMSketch,
 	extStats *statistics.ExtendedStatsColl,
+	exprResult *statistics.AnalyzeResult,
 	err error,
 ) {
 	if err = e.open(ranges); err != nil {
-		return 0, nil, nil, nil, nil, err
+		return 0, nil, nil, nil, nil, nil, err
 	}
 	defer func() {
 		if err1 := e.resultHandler.Close(); err1 != nil {
@@ -965,7 +976,7 @@ func (e *AnalyzeColumnsExec) buildSamplingStats(
 	sc := e.ctx.GetSessionVars().StmtCtx
 	statsConcurrency, err := getBuildStatsConcurrency(e.ctx)
 	if err != nil {
-		return 0, nil, nil, nil, nil, err
+		return 0, nil, nil, nil, nil, nil, err
 	}
 	mergeResultCh := make(chan *samplingMergeResult, statsConcurrency)
 	mergeTaskCh := make(chan []byte, statsConcurrency)
@@ -975,7 +986,7 @@ func (e *AnalyzeColumnsExec) buildSamplingStats(
 		go e.subMergeWorker(mergeResultCh, mergeTaskCh, l, i == 0)
 	}
 	if err = readDataAndSendTask(e.ctx, e.resultHandler, mergeTaskCh); err != nil {
-		return 0, nil, nil, nil, nil, err
+		return 0, nil, nil, nil, nil, nil, err
 	}
 
 	mergeWorkerPanicCnt := 0
@@ -994,7 +1005,7 @@ func (e *AnalyzeColumnsExec) buildSamplingStats(
 		rootRowCollector.MergeCollector(mergeResult.collector)
 	}
 	if err != nil {
-		return 0, nil, nil, nil, nil, err
+		return 0, nil, nil, nil, nil, nil, err
 	}
 
 	// handling virtual columns
@@ -1006,7 +1017,7 @@ func (e *AnalyzeColumnsExec) buildSamplingStats(
 		}
 		err = e.decodeSampleDataWithVirtualColumn(rootRowCollector, fieldTps, virtualColIdx, e.schemaForVirtualColEval)
 		if err != nil {
-			return 0, nil, nil, nil, nil, err
+			return 0, nil, nil, nil, nil, nil, err
 		}
 	} else {
 		// If there's no virtual column or we meet error during eval virtual column, we fallback to normal decode otherwise.
@@ -1014,7 +1025,7 @@ func (e *AnalyzeColumnsExec) buildSamplingStats(
 			for i := range sample.Columns {
 				sample.Columns[i], err = tablecodec.DecodeColumnValue(sample.Columns[i].GetBytes(), &e.colsInfo[i].FieldType, sc.TimeZone)
 				if err != nil {
-					return 0, nil, nil, nil, nil, err
+					return 0, nil, nil, nil, nil, nil, err
 				}
 			}
 		}
@@ -1024,7 +1035,7 @@ func (e *AnalyzeColumnsExec) buildSamplingStats(
 		// Calculate handle from the row data for each row. It will be used to sort the samples.
 		sample.Handle, err = e.handleCols.BuildHandleByDatums(sample.Columns)
 		if err != nil {
-			return 0, nil, nil, nil, nil, err
+			return 0, nil, nil, nil, nil, nil, err
 		}
 	}
 
@@ -1067,7 +1078,7 @@ func (e *AnalyzeColumnsExec) buildSamplingStats(
 	if indexPushedDownResult.err != nil {
 		close(exitCh)
 		e.samplingBuilderWg.Wait()
-		return 0, nil, nil, nil, nil, indexPushedDownResult.err
+		return 0, nil, nil, nil, nil, nil, indexPushedDownResult.err
 	}
 	for _, offset := range indexesWithVirtualColOffsets {
 		ret := indexPushedDownResult.results[e.indexes[offset].ID]
@@ -1102,19 +1113,90 @@ func (e *AnalyzeColumnsExec) buildSamplingStats(
 		}
 	}
 	if err != nil {
-		return 0, nil, nil, nil, nil, err
+		return 0, nil, nil, nil, nil, nil, err
 	}
 	count = rootRowCollector.Base().Count
 	if needExtStats {
 		statsHandle := domain.GetDomain(e.ctx).StatsHandle()
-		extStats, err = statsHandle.BuildExtendedStats(e.TableID.GetStatisticsID(), e.colsInfo, sampleCollectors)
+		extStats, err = statsHandle.BuildExtendedStats(e.TableID.GetStatisticsID(), e.colsInfo, sampleCollectors, e.StatsVersion, e.ctx.GetSessionVars().StmtCtx)
 		if err != nil {
-			return 0, nil, nil, nil, nil, err
+			return 0, nil, nil, nil, nil, nil, err
 		}
 	}
+	exprResult, err = e.buildExprStats(rootRowCollector)
+	if err != nil {
+		return 0, nil, nil, nil, nil, nil, err
+	}
 	return
 }
 
+// buildExprStats builds the statistics of the analyzed expressions by evaluating them on the sampled rows.
+func (e *AnalyzeColumnsExec) buildExprStats(rootRowCollector statistics.RowSampleCollector) (*statistics.AnalyzeResult, error) {
+	if len(e.exprs) == 0 {
+		return nil, nil
+	}
+	sc := e.ctx.GetSessionVars().StmtCtx
+	base := rootRowCollector.Base()
+	result := &statistics.AnalyzeResult{
+		Hist:  make([]*statistics.Histogram, 0, len(e.exprs)),
+		TopNs: make([]*statistics.TopN, 0, len(e.exprs)),
+		Fms:   make([]*statistics.FMSketch, 0, len(e.exprs)),
+	}
+	// Only the samples are evaluated, so the null count and the total size are scaled to the whole table.
+	scale := 1.0
+	if len(base.Samples) > 0 {
+		scale = float64(base.Count) / float64(len(base.Samples))
+	}
+	for i, expr := range e.exprs {
+		ft := &e.exprsInfo[i].FieldType
+		sampleItems := make([]*statistics.SampleItem, 0, len(base.Samples))
+		fmSketch := statistics.NewFMSketch(maxSketchSize)
+		var nullCount, totalSize int64
+		for j, sample := range base.Samples {
+			val, err := expr.Eval(chunk.MutRowFromDatums(sample.Columns).ToRow())
+			if err != nil {
+				return nil, err
+			}
+			if val.IsNull() {
+				nullCount++
+				continue
+			}
+			if err = fmSketch.InsertValue(sc, val); err != nil {
+				return nil, err
+			}
+			encoded, err := codec.EncodeValue(sc, nil, val)
+			if err != nil {
+				return nil, err
+			}
+			totalSize += int64(len(encoded)) - 1
+			// Use the collate key like the columns, see (*AnalyzeColumnsExec).subBuildWorker.
+			if ft.EvalType() == types.ETString && ft.Tp != mysql.TypeEnum && ft.Tp != mysql.TypeSet {
+				val.SetBytes(collate.GetCollator(ft.Collate).Key(val.GetString()))
+			}
+			sampleItems = append(sampleItems, &statistics.SampleItem{
+				Value:   val,
+				Ordinal: j,
+			})
+		}
+		nullCount = int64(float64(nullCount) * scale)
+		collector := &statistics.SampleCollector{
+			Samples:   sampleItems,
+			NullCount: nullCount,
+			Count:     base.Count - nullCount,
+			FMSketch:  fmSketch,
+			TotalSize: int64(float64(totalSize) * scale),
+		}
+		hist, topn, err := statistics.BuildHistAndTopN(e.ctx, int(e.opts[ast.AnalyzeOptNumBuckets]), int(e.opts[ast.AnalyzeOptNumTopN]), e.exprsInfo[i].ID, collector, ft, true)
+		if err != nil {
+			return nil, err
+		}
+		result.Hist = append(result.Hist, hist)
+		result.TopNs = append(result.TopNs, topn)
+		result.Fms = append(result.Fms, fmSketch)
+	}
+	return result, nil
+}
+
 type analyzeIndexNDVTotalResult struct {
 	results map[int64]*statistics.AnalyzeResults
 	err     error
@@ -1593,7 +1675,7 @@ func (e *AnalyzeColumnsExec) buildStats(ranges []*ranger.Range, needExtStats boo
 	}
 	if needExtStats {
 		statsHandle := domain.GetDomain(e.ctx).StatsHandle()
-		extStats, err = statsHandle.BuildExtendedStats(e.TableID.GetStatisticsID(), e.colsInfo, collectors)
+		extStats, err = statsHandle.BuildExtendedStats(e.TableID.GetStatisticsID(), e.colsInfo, collectors, e.StatsVersion, e.ctx.GetSessionVars().StmtCtx)
 		if err != nil {
 			return nil, nil, nil, nil, nil, err
 		}
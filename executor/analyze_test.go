@@ -2938,3 +2938,110 @@ func TestShowAanalyzeStatusJobInfo(t *testing.T) {
 	tk.MustExec("analyze table t")
 	checkJobInfo("analyze table columns a, b, d with 3 buckets, 1 topn, 1 samplerate")
 }
+
+func TestAnalyzeExpressions(t *testing.T) {
+	store, dom, clean := testkit.CreateMockStoreAndDomain(t)
+	defer clean()
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("set @@tidb_analyze_version = 2")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b varchar(20), j json)")
+	values := make([]string, 0, 100)
+	for i := 0; i < 100; i++ {
+		switch {
+		case i < 10:
+			values = append(values, fmt.Sprintf(`(%d, 'Def', '{"s": "bad"}')`, i))
+		case i < 20:
+			values = append(values, fmt.Sprintf(`(%d, 'DEF', '{"s": "ok"}')`, i))
+		default:
+			values = append(values, fmt.Sprintf(`(%d, 'Abc', '{"s": "ok"}')`, i))
+		}
+	}
+	tk.MustExec("insert into t values " + strings.Join(values, ", "))
+	h := dom.StatsHandle()
+	require.NoError(t, h.DumpStatsDeltaToKV(handle.DumpAll))
+	tk.MustExec("analyze table t")
+	estRows := func(cond string) string {
+		rows := tk.MustQuery("explain format = 'brief' select * from t where " + cond).Rows()
+		return rows[0][1].(string)
+	}
+	require.Equal(t, "80.00", estRows("lower(b) = 'def'"))
+	require.Equal(t, "80.00", estRows("j->>'$.s' = 'bad'"))
+
+	tk.MustExec("analyze table t expressions (lower(b), j->>'$.s')")
+	tk.MustQuery("select expression from mysql.stats_expressions order by expression").Check(testkit.Rows(
+		"JSON_UNQUOTE(JSON_EXTRACT(`j`, _UTF8MB4'$.s'))",
+		"LOWER(`b`)",
+	))
+	tk.MustQuery("show stats_histograms where table_name = 't' and column_name = 'LOWER(`b`)'").CheckAt([]int{3, 4, 6, 7}, testkit.Rows(
+		"LOWER(`b`) 0 2 0",
+	))
+	require.Equal(t, "20.00", estRows("lower(b) = 'def'"))
+	require.Equal(t, "80.00", estRows("lower(b) = 'abc'"))
+	require.Equal(t, "10.00", estRows("j->>'$.s' = 'bad'"))
+	require.Equal(t, "2.00", estRows("lower(b) = 'def' and j->>'$.s' = 'bad'"))
+
+	// The expressions analyzed before are analyzed again with the table.
+	tk.MustExec("update t set b = 'def' where a < 50")
+	require.NoError(t, h.DumpStatsDeltaToKV(handle.DumpAll))
+	tk.MustExec("analyze table t")
+	require.Equal(t, "50.00", estRows("lower(b) = 'def'"))
+	tk.MustQuery("select count(*) from mysql.stats_expressions").Check(testkit.Rows("2"))
+	// The expression statistics are also loaded when initializing the stats cache.
+	h.Clear()
+	require.NoError(t, h.InitStats(dom.InfoSchema()))
+	require.Equal(t, "50.00", estRows("lower(b) = 'def'"))
+
+	tk.MustGetErrMsg("analyze table t expressions (b)", "The expression `b` to analyze must be a function of the table columns")
+	tk.MustGetErrMsg("analyze table t expressions (a + rand())", "The expression `a`+RAND() to analyze must be deterministic")
+	tk.MustGetErrMsg("analyze table t expressions (json_array(a))", "The expression JSON_ARRAY(`a`) to analyze returns JSON, use ->> to extract a scalar value instead")
+	tk.MustExec("set @@tidb_analyze_version = 1")
+	tk.MustGetErrMsg("analyze table t expressions (lower(b))", "Only the analyze version 2 supports analyzing expressions")
+	tk.MustExec("set @@tidb_analyze_version = 2")
+	tk.MustExec("create table pt (a int, b varchar(20)) partition by hash(a) partitions 2")
+	tk.MustGetErrMsg("analyze table pt expressions (lower(b))", "Expression statistics on partitioned tables are not supported now")
+
+	tk.MustExec("drop stats t")
+	tk.MustQuery("select count(*) from mysql.stats_expressions").Check(testkit.Rows("0"))
+}
+
+func TestAnalyzeJSONExtractExpressions(t *testing.T) {
+	store, dom, clean := testkit.CreateMockStoreAndDomain(t)
+	defer clean()
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("set @@tidb_analyze_version = 2")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (id int, doc json)")
+	values := make([]string, 0, 100)
+	for i := 0; i < 100; i++ {
+		status := "inactive"
+		if i < 10 {
+			status = "active"
+		}
+		values = append(values, fmt.Sprintf(`(%d, '{"status": "%s"}')`, i, status))
+	}
+	tk.MustExec("insert into t values " + strings.Join(values, ", "))
+	require.NoError(t, dom.StatsHandle().DumpStatsDeltaToKV(handle.DumpAll))
+	estRows := func(cond string) string {
+		rows := tk.MustQuery("explain format = 'brief' select * from t where " + cond).Rows()
+		return rows[0][1].(string)
+	}
+
+	// JSON_EXTRACT is analyzed on its unquoted form, so it shares the statistics with `->>`.
+	tk.MustExec("analyze table t expressions (JSON_EXTRACT(doc,'$.status'))")
+	tk.MustQuery("select expression from mysql.stats_expressions").Check(testkit.Rows(
+		"JSON_UNQUOTE(JSON_EXTRACT(`doc`, _UTF8MB4'$.status'))",
+	))
+	tk.MustExec("analyze table t expressions (doc->>'$.status', doc->'$.status')")
+	tk.MustQuery("select count(*) from mysql.stats_expressions").Check(testkit.Rows("1"))
+
+	require.Equal(t, "10.00", estRows("JSON_EXTRACT(doc,'$.status') = 'active'"))
+	require.Equal(t, "90.00", estRows("'inactive' = JSON_EXTRACT(doc,'$.status')"))
+	require.Equal(t, "10.00", estRows("doc->'$.status' = 'active'"))
+	require.Equal(t, "10.00", estRows("doc->>'$.status' = 'active'"))
+	require.Equal(t, "90.00", estRows("JSON_EXTRACT(doc,'$.status') != 'active'"))
+	// The results are not changed by the rewriting.
+	tk.MustQuery("select count(*) from t where JSON_EXTRACT(doc,'$.status') = 'active'").Check(testkit.Rows("10"))
+}
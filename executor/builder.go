@@ -199,6 +199,8 @@ func (b *executorBuilder) build(p plannercore.Plan) Executor {
 		return b.buildLoadStats(v)
 	case *plannercore.IndexAdvise:
 		return b.buildIndexAdvise(v)
+	case *plannercore.RecommendIndex:
+		return b.buildRecommendIndex(v)
 	case *plannercore.PlanReplayer:
 		return b.buildPlanReplayer(v)
 	case *plannercore.PhysicalLimit:
@@ -293,6 +295,10 @@ func (b *executorBuilder) build(p plannercore.Plan) Executor {
 		return b.buildAdminShowTelemetry(v)
 	case *plannercore.AdminResetTelemetryID:
 		return b.buildAdminResetTelemetryID(v)
+	case *plannercore.AdminShowPlanRegressions:
+		return b.buildAdminShowPlanRegressions(v)
+	case *plannercore.AdminRejectPlanRegression:
+		return b.buildAdminRejectPlanRegression(v)
 	case *plannercore.PhysicalCTE:
 		return b.buildCTE(v)
 	case *plannercore.PhysicalCTETable:
@@ -953,6 +959,13 @@ func (b *executorBuilder) buildIndexAdvise(v *plannercore.IndexAdvise) Executor
 	return e
 }
 
+func (b *executorBuilder) buildRecommendIndex(v *plannercore.RecommendIndex) Executor {
+	return &RecommendIndexExec{
+		baseExecutor: newBaseExecutor(b.ctx, v.Schema(), v.ID()),
+		sql:          v.SQL,
+	}
+}
+
 func (b *executorBuilder) buildPlanReplayer(v *plannercore.PlanReplayer) Executor {
 	if v.Load {
 		e := &PlanReplayerLoadExec{
@@ -1370,9 +1383,34 @@ func (b *executorBuilder) buildHashJoin(v *plannercore.PhysicalHashJoin) Executo
 	} else {
 		e.buildTypes, e.probeTypes = rightTypes, leftTypes
 	}
+	if len(v.RuntimeFilters) > 0 {
+		b.buildRuntimeFilters(e, v, leftIsBuildSide)
+	}
 	return e
 }
 
+// buildRuntimeFilters sets up the runtime filters of the hash join if the probe side is still
+// read by a table reader.
+func (b *executorBuilder) buildRuntimeFilters(e *HashJoinExec, v *plannercore.PhysicalHashJoin, leftIsBuildSide bool) {
+	reader, ok := e.probeSideExec.(*TableReaderExecutor)
+	if !ok || reader.dummy {
+		return
+	}
+	probePlan := v.Children()[0]
+	if leftIsBuildSide {
+		probePlan = v.Children()[1]
+	}
+	readerPlan, ok := probePlan.(*plannercore.PhysicalTableReader)
+	if !ok {
+		return
+	}
+	maxInSize := b.ctx.GetSessionVars().RuntimeFilterMaxInSize
+	for _, rf := range v.RuntimeFilters {
+		e.runtimeFilters = append(e.runtimeFilters, newRuntimeFilter(rf, e.buildKeys[rf.KeyIdx].Index, e.buildTypes[rf.KeyIdx], maxInSize))
+	}
+	e.rfTarget = newRuntimeFilterTarget(reader, readerPlan)
+}
+
 func (b *executorBuilder) buildHashAgg(v *plannercore.PhysicalHashAgg) Executor {
 	src := b.build(v.Children()[0])
 	if b.err != nil {
@@ -1741,7 +1779,8 @@ func (b *executorBuilder) buildMemTable(v *plannercore.PhysicalMemTable) Executo
 			strings.ToLower(infoschema.TableClientErrorsSummaryByUser),
 			strings.ToLower(infoschema.TableClientErrorsSummaryByHost),
 			strings.ToLower(infoschema.TableAttributes),
-			strings.ToLower(infoschema.TablePlacementPolicies):
+			strings.ToLower(infoschema.TablePlacementPolicies),
+			strings.ToLower(infoschema.TableResourceGroups):
 			return &MemTableReaderExec{
 				baseExecutor: newBaseExecutor(b.ctx, v.Schema(), v.ID()),
 				table:        v.Table,
@@ -2451,6 +2490,8 @@ func (b *executorBuilder) buildAnalyzeSamplingPushdown(task plannercore.AnalyzeC
 		schemaForVirtualColEval: schemaForVirtualColEval,
 		baseCount:               count,
 		baseModifyCnt:           modifyCount,
+		exprs:                   task.Exprs,
+		exprsInfo:               task.ExprsInfo,
 	}
 	e.analyzePB.ColReq = &tipb.AnalyzeColumnsReq{
 		BucketSize:   int64(opts[ast.AnalyzeOptNumBuckets]),
@@ -4759,6 +4800,14 @@ func (b *executorBuilder) buildAdminResetTelemetryID(v *plannercore.AdminResetTe
 	return &AdminResetTelemetryIDExec{baseExecutor: newBaseExecutor(b.ctx, v.Schema(), v.ID())}
 }
 
+func (b *executorBuilder) buildAdminShowPlanRegressions(v *plannercore.AdminShowPlanRegressions) Executor {
+	return &AdminShowPlanRegressionsExec{baseExecutor: newBaseExecutor(b.ctx, v.Schema(), v.ID())}
+}
+
+func (b *executorBuilder) buildAdminRejectPlanRegression(v *plannercore.AdminRejectPlanRegression) Executor {
+	return &AdminRejectPlanRegressionExec{baseExecutor: newBaseExecutor(b.ctx, v.Schema(), v.ID()), sqlDigest: v.SQLDigest}
+}
+
 func partitionPruning(ctx sessionctx.Context, tbl table.PartitionedTable, conds []expression.Expression, partitionNames []model.CIStr,
 	columns []*expression.Column, columnNames types.NameSlice) ([]table.PhysicalTable, error) {
 	idxArr, err := plannercore.PartitionPruning(ctx, tbl, conds, partitionNames, columns, columnNames)
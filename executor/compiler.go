@@ -21,6 +21,7 @@ import (
 	"github.com/opentracing/opentracing-go"
 	"github.com/pingcap/failpoint"
 	"github.com/pingcap/tidb/config"
+	"github.com/pingcap/tidb/domain"
 	"github.com/pingcap/tidb/metrics"
 	"github.com/pingcap/tidb/parser/ast"
 	"github.com/pingcap/tidb/parser/mysql"
@@ -87,6 +88,10 @@ func (c *Compiler) Compile(ctx context.Context, stmtNode ast.StmtNode) (*ExecStm
 		}
 	})
 
+	if err = checkRunawayQuarantine(c.Ctx, finalPlan); err != nil {
+		return nil, err
+	}
+
 	CountStmtNode(stmtNode, c.Ctx.GetSessionVars().InRestrictedSQL)
 	var lowerPriority bool
 	if c.Ctx.GetSessionVars().StmtCtx.Priority == mysql.NoPriority {
@@ -108,6 +113,31 @@ func (c *Compiler) Compile(ctx context.Context, stmtNode ast.StmtNode) (*ExecStm
 	}, nil
 }
 
+// checkRunawayQuarantine rejects the statement if its digest is quarantined
+// as a runaway query.
+func checkRunawayQuarantine(sctx sessionctx.Context, p plannercore.Plan) error {
+	vars := sctx.GetSessionVars()
+	if vars.InRestrictedSQL {
+		return nil
+	}
+	dom := domain.GetDomain(sctx)
+	if dom == nil || dom.RunawayManager() == nil {
+		return nil
+	}
+	m := dom.RunawayManager()
+	_, sqlDigest := vars.StmtCtx.SQLDigest()
+	var planDigest string
+	if m.NeedPlanDigest() {
+		_, digest := getPlanDigest(sctx, p)
+		planDigest = digest.String()
+	}
+	sql := vars.StmtCtx.OriginalSQL
+	if vars.EnableRedactLog {
+		sql, _ = vars.StmtCtx.SQLDigest()
+	}
+	return m.CheckQuarantine(sqlDigest.String(), planDigest, sql)
+}
+
 // needLowerPriority checks whether it's needed to lower the execution priority
 // of a query.
 // If the estimated output row count of any operator in the physical plan tree
@@ -407,6 +437,8 @@ func GetStmtLabel(stmtNode ast.StmtNode) string {
 		return "CreateBinding"
 	case *ast.IndexAdviseStmt:
 		return "IndexAdvise"
+	case *ast.RecommendIndexStmt:
+		return "RecommendIndex"
 	case *ast.DropBindingStmt:
 		return "DropBinding"
 	case *ast.TraceStmt:
@@ -99,6 +99,14 @@ func (e *DDLExec) Next(ctx context.Context, req *chunk.Chunk) (err error) {
 		if s.TemporaryKeyword == ast.TemporaryLocal {
 			return e.createSessionTemporaryTable(s)
 		}
+	case *ast.CreateIndexStmt:
+		if s.IndexOption != nil && s.IndexOption.Tp == model.IndexTypeHypo {
+			return e.createHypoIndex(s)
+		}
+	case *ast.DropIndexStmt:
+		if e.dropHypoIndex(s) {
+			return nil
+		}
 	case *ast.DropTableStmt:
 		if s.IsView {
 			break
@@ -191,6 +199,12 @@ func (e *DDLExec) Next(ctx context.Context, req *chunk.Chunk) (err error) {
 		err = e.executeDropPlacementPolicy(x)
 	case *ast.AlterPlacementPolicyStmt:
 		err = e.executeAlterPlacementPolicy(x)
+	case *ast.CreateResourceGroupStmt:
+		err = e.executeCreateResourceGroup(x)
+	case *ast.AlterResourceGroupStmt:
+		err = e.executeAlterResourceGroup(x)
+	case *ast.DropResourceGroupStmt:
+		err = e.executeDropResourceGroup(x)
 	}
 	if err != nil {
 		// If the owner return ErrTableNotExists error when running this DDL, it may be caused by schema changed,
@@ -351,6 +365,64 @@ func (e *DDLExec) createSessionTemporaryTable(s *ast.CreateTableStmt) error {
 	return e.tempTableDDL.CreateLocalTemporaryTable(dbInfo, tbInfo)
 }
 
+// createHypoIndex creates a hypothetical index, which is only visible to the EXPLAIN statements of the session.
+func (e *DDLExec) createHypoIndex(s *ast.CreateIndexStmt) error {
+	is := e.ctx.GetInfoSchema().(infoschema.InfoSchema)
+	tbl, err := is.TableByName(s.Table.Schema, s.Table.Name)
+	if err != nil {
+		return err
+	}
+	if tbl.Meta().TempTableType == model.TempTableLocal {
+		return dbterror.ErrUnsupportedLocalTempTableDDL.GenWithStackByArgs("CREATE INDEX")
+	}
+
+	indexName := model.NewCIStr(s.IndexName)
+	sessVars := e.ctx.GetSessionVars()
+	tblHypoIndexes := sessVars.HypoIndexes[s.Table.Schema.L][s.Table.Name.L]
+	if _, ok := tblHypoIndexes[indexName.L]; ok {
+		err = dbterror.ErrDupKeyName.GenWithStack("index already exist %s", indexName)
+		if s.IfNotExists {
+			sessVars.StmtCtx.AppendNote(err)
+			return nil
+		}
+		return err
+	}
+	idxInfo, err := ddl.BuildHypoIndexInfo(tbl.Meta(), s.KeyType, indexName, s.IndexPartSpecifications)
+	if err != nil {
+		return err
+	}
+
+	if sessVars.HypoIndexes == nil {
+		sessVars.HypoIndexes = make(map[string]map[string]map[string]*model.IndexInfo)
+	}
+	dbHypoIndexes := sessVars.HypoIndexes[s.Table.Schema.L]
+	if dbHypoIndexes == nil {
+		dbHypoIndexes = make(map[string]map[string]*model.IndexInfo)
+		sessVars.HypoIndexes[s.Table.Schema.L] = dbHypoIndexes
+	}
+	if tblHypoIndexes == nil {
+		tblHypoIndexes = make(map[string]*model.IndexInfo)
+		dbHypoIndexes[s.Table.Name.L] = tblHypoIndexes
+	}
+	tblHypoIndexes[indexName.L] = idxInfo
+	return nil
+}
+
+// dropHypoIndex drops the hypothetical index if it exists, and returns whether it's dropped.
+func (e *DDLExec) dropHypoIndex(s *ast.DropIndexStmt) bool {
+	sessVars := e.ctx.GetSessionVars()
+	tblHypoIndexes := sessVars.HypoIndexes[s.Table.Schema.L][s.Table.Name.L]
+	indexName := strings.ToLower(s.IndexName)
+	if _, ok := tblHypoIndexes[indexName]; !ok {
+		return false
+	}
+	delete(tblHypoIndexes, indexName)
+	if len(tblHypoIndexes) == 0 {
+		delete(sessVars.HypoIndexes[s.Table.Schema.L], s.Table.Name.L)
+	}
+	return true
+}
+
 func (e *DDLExec) executeCreateView(s *ast.CreateViewStmt) error {
 	ret := &core.PreprocessorReturn{}
 	err := core.Preprocess(e.ctx, s.Select, core.WithPreprocessorReturn(ret))
@@ -803,3 +875,15 @@ func (e *DDLExec) executeDropPlacementPolicy(s *ast.DropPlacementPolicyStmt) err
 func (e *DDLExec) executeAlterPlacementPolicy(s *ast.AlterPlacementPolicyStmt) error {
 	return domain.GetDomain(e.ctx).DDL().AlterPlacementPolicy(e.ctx, s)
 }
+
+func (e *DDLExec) executeCreateResourceGroup(s *ast.CreateResourceGroupStmt) error {
+	return domain.GetDomain(e.ctx).DDL().CreateResourceGroup(e.ctx, s)
+}
+
+func (e *DDLExec) executeAlterResourceGroup(s *ast.AlterResourceGroupStmt) error {
+	return domain.GetDomain(e.ctx).DDL().AlterResourceGroup(e.ctx, s)
+}
+
+func (e *DDLExec) executeDropResourceGroup(s *ast.DropResourceGroupStmt) error {
+	return domain.GetDomain(e.ctx).DDL().DropResourceGroup(e.ctx, s)
+}
@@ -1767,7 +1767,14 @@ func ResetContextOfStmt(ctx sessionctx.Context, s ast.StmtNode) (err error) {
 
 	sc.InitMemTracker(memory.LabelForSQLText, vars.MemQuotaQuery)
 	sc.InitDiskTracker(memory.LabelForSQLText, -1)
-	sc.MemTracker.AttachToGlobalTracker(GlobalMemoryUsageTracker)
+	globalTracker := GlobalMemoryUsageTracker
+	if len(vars.ResourceGroupName) > 0 {
+		// Statements of a resource group are accounted to the group to enforce its memory quota.
+		if groupTracker := domain.GetDomain(ctx).ResourceGroupManager().MemTracker(vars.ResourceGroupName, GlobalMemoryUsageTracker); groupTracker != nil {
+			globalTracker = groupTracker
+		}
+	}
+	sc.MemTracker.AttachToGlobalTracker(globalTracker)
 	globalConfig := config.GetGlobalConfig()
 	if globalConfig.OOMUseTmpStorage && GlobalDiskUsageTracker != nil {
 		sc.DiskTracker.AttachToGlobalTracker(GlobalDiskUsageTracker)
@@ -1810,6 +1817,7 @@ func ResetContextOfStmt(ctx sessionctx.Context, s ast.StmtNode) (err error) {
 	sc.OriginalSQL = s.Text()
 	if explainStmt, ok := s.(*ast.ExplainStmt); ok {
 		sc.InExplainStmt = true
+		sc.InExplainAnalyzeStmt = explainStmt.Analyze
 		sc.IgnoreExplainIDSuffix = strings.ToLower(explainStmt.Format) == types.ExplainFormatBrief
 		sc.InVerboseExplain = strings.ToLower(explainStmt.Format) == types.ExplainFormatVerbose
 		s = explainStmt.Stmt
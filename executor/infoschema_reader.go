@@ -33,6 +33,7 @@ import (
 	"github.com/pingcap/failpoint"
 	"github.com/pingcap/kvproto/pkg/deadlock"
 	"github.com/pingcap/tidb/ddl/label"
+	"github.com/pingcap/tidb/domain"
 	"github.com/pingcap/tidb/domain/infosync"
 	"github.com/pingcap/tidb/errno"
 	"github.com/pingcap/tidb/expression"
@@ -64,6 +65,7 @@ import (
 	"github.com/pingcap/tidb/util/keydecoder"
 	"github.com/pingcap/tidb/util/logutil"
 	"github.com/pingcap/tidb/util/pdapi"
+	"github.com/pingcap/tidb/util/resourcegroup"
 	"github.com/pingcap/tidb/util/resourcegrouptag"
 	"github.com/pingcap/tidb/util/sem"
 	"github.com/pingcap/tidb/util/set"
@@ -165,6 +167,8 @@ func (e *memtableRetriever) retrieve(ctx context.Context, sctx sessionctx.Contex
 			err = e.setDataForAttributes(sctx, is)
 		case infoschema.TablePlacementPolicies:
 			err = e.setDataFromPlacementPolicies(sctx)
+		case infoschema.TableResourceGroups:
+			e.setDataFromResourceGroups(sctx)
 		}
 		if err != nil {
 			return nil, err
@@ -2956,6 +2960,34 @@ func (e *memtableRetriever) setDataFromPlacementPolicies(sctx sessionctx.Context
 	return nil
 }
 
+func (e *memtableRetriever) setDataFromResourceGroups(sctx sessionctx.Context) {
+	is := sessiontxn.GetTxnManager(sctx).GetTxnInfoSchema()
+	resourceGroups := is.AllResourceGroups()
+	sort.Slice(resourceGroups, func(i, j int) bool { return resourceGroups[i].Name.L < resourceGroups[j].Name.L })
+	statsMap := make(map[string]resourcegroup.Stats)
+	if dom := domain.GetDomain(sctx); dom != nil && dom.ResourceGroupManager() != nil {
+		for _, stats := range dom.ResourceGroupManager().Stats() {
+			statsMap[stats.Name] = stats
+		}
+	}
+	rows := make([][]types.Datum, 0, len(resourceGroups))
+	for _, group := range resourceGroups {
+		stats := statsMap[group.Name.L]
+		row := types.MakeDatums(
+			group.ID,
+			group.Name.O,
+			group.QPS,
+			group.MaxConcurrency,
+			group.MemQuota,
+			stats.Running,
+			stats.Queued,
+			stats.MemoryConsume,
+		)
+		rows = append(rows, row)
+	}
+	e.rows = rows
+}
+
 func checkRule(rule *label.Rule) (dbName, tableName string, partitionName string, err error) {
 	s := strings.Split(rule.ID, "/")
 	if len(s) < 3 {
@@ -93,6 +93,11 @@ type HashJoinExec struct {
 	joinWorkerWaitGroup sync.WaitGroup
 	finished            atomic.Value
 
+	// runtimeFilters are built from the build side and pushed into rfTarget before the probe side is opened.
+	runtimeFilters  []*runtimeFilter
+	rfTarget        *runtimeFilterTarget
+	probeSideOpened bool
+
 	stats *hashJoinRuntimeStats
 }
 
@@ -152,13 +157,26 @@ func (e *HashJoinExec) Close() error {
 	if e.stats != nil && e.rowContainer != nil {
 		e.stats.hashStat = *e.rowContainer.stat
 	}
+	if e.rfTarget != nil && !e.probeSideOpened {
+		return e.buildSideExec.Close()
+	}
 	err := e.baseExecutor.Close()
 	return err
 }
 
 // Open implements the Executor Open interface.
 func (e *HashJoinExec) Open(ctx context.Context) error {
-	if err := e.baseExecutor.Open(ctx); err != nil {
+	if e.rfTarget != nil {
+		// The probe side is opened after the runtime filters are pushed into it.
+		if err := e.buildSideExec.Open(ctx); err != nil {
+			return err
+		}
+		e.probeSideOpened = false
+		e.rfTarget.reset()
+		for _, f := range e.runtimeFilters {
+			f.reset()
+		}
+	} else if err := e.baseExecutor.Open(ctx); err != nil {
 		return err
 	}
 	e.prepared = false
@@ -191,6 +209,18 @@ func (e *HashJoinExec) Open(ctx context.Context) error {
 // and sends the chunks to multiple channels which will be read by multiple join workers.
 func (e *HashJoinExec) fetchProbeSideChunks(ctx context.Context) {
 	hasWaitedForBuild := false
+	if e.rfTarget != nil {
+		skipProbe, err := e.openProbeSideWithRuntimeFilters(ctx)
+		if err != nil {
+			e.joinResultCh <- &hashjoinWorkerResult{
+				err: err,
+			}
+			return
+		} else if skipProbe {
+			return
+		}
+		hasWaitedForBuild = true
+	}
 	for {
 		if e.finished.Load().(bool) {
 			return
@@ -218,6 +248,9 @@ func (e *HashJoinExec) fetchProbeSideChunks(ctx context.Context) {
 			}
 			return
 		}
+		if e.stats != nil && e.stats.runtimeFilter != nil {
+			e.stats.runtimeFilter.probeRows += int64(probeSideResult.NumRows())
+		}
 		if !hasWaitedForBuild {
 			failpoint.Inject("issue30289", func(val failpoint.Value) {
 				if val.(bool) {
@@ -779,6 +812,12 @@ func (e *HashJoinExec) buildHashTableForList(buildSideResultCh <-chan *chunk.Chu
 		if e.finished.Load().(bool) {
 			return nil
 		}
+		// When the outer filter is used, only the rows passing it are collected below, since the others are never matched.
+		if len(e.runtimeFilters) > 0 && (!e.useOuterToBuild || len(e.outerFilter) == 0) {
+			if err = e.collectRuntimeFilters(chk, nil); err != nil {
+				return err
+			}
+		}
 		if !e.useOuterToBuild {
 			err = e.rowContainer.PutChunk(chk, e.isNullEQ)
 		} else {
@@ -792,6 +831,11 @@ func (e *HashJoinExec) buildHashTableForList(buildSideResultCh <-chan *chunk.Chu
 				if err != nil {
 					return err
 				}
+				if len(e.runtimeFilters) > 0 {
+					if err = e.collectRuntimeFilters(chk, selected); err != nil {
+						return err
+					}
+				}
 				err = e.rowContainer.PutChunkSelected(chk, selected, e.isNullEQ)
 			}
 		}
@@ -1119,6 +1163,7 @@ type hashJoinRuntimeStats struct {
 	probe                  int64
 	concurrent             int
 	maxFetchAndProbe       int64
+	runtimeFilter          *runtimeFilterStats
 }
 
 func (e *hashJoinRuntimeStats) setMaxFetchAndProbeTime(t int64) {
@@ -1166,11 +1211,15 @@ func (e *hashJoinRuntimeStats) String() string {
 		}
 		buf.WriteString("}")
 	}
+	if e.runtimeFilter != nil {
+		buf.WriteString(", ")
+		buf.WriteString(e.runtimeFilter.String())
+	}
 	return buf.String()
 }
 
 func (e *hashJoinRuntimeStats) Clone() execdetails.RuntimeStats {
-	return &hashJoinRuntimeStats{
+	newStats := &hashJoinRuntimeStats{
 		fetchAndBuildHashTable: e.fetchAndBuildHashTable,
 		hashStat:               e.hashStat,
 		fetchAndProbe:          e.fetchAndProbe,
@@ -1178,6 +1227,10 @@ func (e *hashJoinRuntimeStats) Clone() execdetails.RuntimeStats {
 		concurrent:             e.concurrent,
 		maxFetchAndProbe:       e.maxFetchAndProbe,
 	}
+	if e.runtimeFilter != nil {
+		newStats.runtimeFilter = e.runtimeFilter.clone()
+	}
+	return newStats
 }
 
 func (e *hashJoinRuntimeStats) Merge(rs execdetails.RuntimeStats) {
@@ -1193,4 +1246,11 @@ func (e *hashJoinRuntimeStats) Merge(rs execdetails.RuntimeStats) {
 	if e.maxFetchAndProbe < tmp.maxFetchAndProbe {
 		e.maxFetchAndProbe = tmp.maxFetchAndProbe
 	}
+	if tmp.runtimeFilter != nil {
+		if e.runtimeFilter == nil {
+			e.runtimeFilter = tmp.runtimeFilter.clone()
+		} else {
+			e.runtimeFilter.probeRows += tmp.runtimeFilter.probeRows
+		}
+	}
 }
@@ -2834,3 +2834,58 @@ func TestIssue31129(t *testing.T) {
 	require.NoError(t, failpoint.Disable(fpName1))
 	require.NoError(t, failpoint.Disable(fpName2))
 }
+
+func TestHashJoinRuntimeFilter(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t1, t2, t3")
+	tk.MustExec("create table t1 (a int, b varchar(10))")
+	tk.MustExec("create table t2 (a int, b varchar(10), c int)")
+	tk.MustExec("create table t3 (a int, b int) partition by range (a) (partition p0 values less than (10), partition p1 values less than (20), partition p2 values less than (30), partition p3 values less than (40))")
+	tk.MustExec("insert into t1 values (1, 'b'), (3, 'd'), (5, null), (null, 'x')")
+	for i := 0; i < 40; i++ {
+		tk.MustExec(fmt.Sprintf("insert into t2 values (%d, '%c', %d)", i, 'a'+i%26, i%3))
+		tk.MustExec(fmt.Sprintf("insert into t3 values (%d, %d)", i, i))
+	}
+	tk.MustExec("analyze table t1, t2, t3")
+	tk.MustExec("set @@tidb_partition_prune_mode = 'dynamic'")
+	tk.MustExec("set @@tidb_enable_collect_execution_info = 1")
+
+	queries := []string{
+		"select /*+ hash_join(t1, t2) */ * from t1 join t2 on t1.a = t2.a",
+		"select /*+ hash_join(t1, t2) */ * from t1 join t2 on t1.a = t2.a and t1.b = t2.b where t2.c > 0",
+		"select /*+ hash_join(t1, t2) */ * from t1 left join t2 on t1.a = t2.a",
+		"select /*+ hash_join(t1, t3) */ * from t1 join t3 on t1.a = t3.a",
+		"select * from t2 where t2.a in (select a from t1)",
+	}
+	results := make([][][]interface{}, 0, len(queries))
+	for _, q := range queries {
+		results = append(results, tk.MustQuery(q).Sort().Rows())
+	}
+	tk.MustExec("set @@tidb_enable_runtime_filter = 1")
+	for i, q := range queries {
+		tk.MustQuery(q).Sort().Check(results[i])
+		rows := tk.MustQuery("explain analyze " + q).Rows()
+		require.Regexp(t, "HashJoin.*", rows[0][0])
+		require.Regexp(t, "runtime filter:\\[test\\.t1\\.a->test\\.t[23]\\.a.*\\]", rows[0][6])
+		require.Regexp(t, "runtime_filter:{filters:\\[in\\(test\\.t[23]\\.a, [23] values\\).*\\], .*scan:.*, probe:.*, filter_rate:.*}", rows[0][5])
+	}
+	// The partitions of t3 are pruned by the values of t1.a.
+	rows := tk.MustQuery("explain analyze " + queries[3]).Rows()
+	require.Regexp(t, "runtime_filter:{filters:\\[in\\(test\\.t3\\.a, 3 values\\)\\], partitions:1/4, scan:10, probe:3, filter_rate:70.00%}", rows[0][5])
+
+	// Fall back to the range of the values.
+	tk.MustExec("set @@tidb_runtime_filter_max_in_size = 1")
+	tk.MustQuery(queries[0]).Sort().Check(results[0])
+	rows = tk.MustQuery("explain analyze " + queries[0]).Rows()
+	require.Regexp(t, "runtime_filter:{filters:\\[range\\(test\\.t2\\.a, 1, 5\\)\\], scan:40, probe:5, filter_rate:87.50%}", rows[0][5])
+
+	// The probe side of a left outer join cannot be filtered when the outer side is probed.
+	rows = tk.MustQuery("explain format = 'brief' select /*+ hash_join(t1, t2) */ * from t2 left join t1 on t1.a = t2.a").Rows()
+	require.NotContains(t, rows[0][4], "runtime filter")
+	tk.MustExec("set @@tidb_enable_runtime_filter = 0")
+	rows = tk.MustQuery("explain format = 'brief' " + queries[0]).Rows()
+	require.NotContains(t, rows[0][4], "runtime filter")
+}
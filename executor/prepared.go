@@ -221,6 +221,10 @@ func (e *PrepareExec) Next(ctx context.Context, req *chunk.Chunk) error {
 		param.Datum.SetNull()
 		param.InExecute = false
 	}
+	stmtText4PC, paddedParams := stmt.Text(), []plannercore.PaddedParam(nil)
+	if prepared.UseCache {
+		stmtText4PC, paddedParams = plannercore.PadPreparedInLists(stmt, e.ParamCount)
+	}
 	var p plannercore.Plan
 	e.ctx.GetSessionVars().PlanID = 0
 	e.ctx.GetSessionVars().PlanColumnID = 0
@@ -254,10 +258,23 @@ func (e *PrepareExec) Next(ctx context.Context, req *chunk.Chunk) error {
 		SnapshotTSEvaluator: ret.SnapshotTSEvaluator,
 		NormalizedSQL4PC:    normalizedSQL4PC,
 		SQLDigest4PC:        digest4PC,
+		StmtText4PC:         stmtText4PC,
+		PaddedParams:        paddedParams,
 	}
 	return vars.AddPreparedStmt(e.ID, preparedObj)
 }
 
+// RestorePreparedStmt prepares the statement again with the original statement ID and name.
+// It's used to restore the prepared statements when the session states are migrated from another server.
+func RestorePreparedStmt(ctx context.Context, sctx sessionctx.Context, stmtID uint32, name, sqlText string) error {
+	e := NewPrepareExec(sctx, sqlText)
+	e.ID = stmtID
+	e.name = name
+	// The statement context belongs to the running `set session_states` statement, so don't reset it.
+	e.needReset = false
+	return e.Next(ctx, nil)
+}
+
 // ExecuteExec represents an EXECUTE executor.
 // It cannot be executed by itself, all it needs to do is to build
 // another Executor from a prepared statement.
@@ -333,7 +350,7 @@ func (e *DeallocateExec) Next(ctx context.Context, req *chunk.Chunk) error {
 	prepared := preparedObj.PreparedAst
 	delete(vars.PreparedStmtNameToID, e.Name)
 	if plannercore.PreparedPlanCacheEnabled() {
-		cacheKey, err := plannercore.NewPlanCacheKey(vars, preparedObj.StmtText, preparedObj.StmtDB, prepared.SchemaVersion)
+		cacheKey, err := plannercore.NewPlanCacheKey(vars, preparedObj.StmtText4PC, preparedObj.StmtDB, prepared.SchemaVersion)
 		if err != nil {
 			return err
 		}
@@ -391,6 +408,9 @@ func CompileExecutePreparedStmt(ctx context.Context, sctx sessionctx.Context,
 		stmtCtx.OriginalSQL = stmt.Text
 		stmtCtx.InitSQLDigest(preparedObj.NormalizedSQL, preparedObj.SQLDigest)
 	}
+	if err = checkRunawayQuarantine(sctx, execPlan); err != nil {
+		return nil, false, false, err
+	}
 	tiFlashPushDown, tiFlashExchangePushDown := plannercore.IsTiFlashContained(stmt.Plan)
 	return stmt, tiFlashPushDown, tiFlashExchangePushDown, nil
 }
@@ -0,0 +1,720 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/ddl"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/parser/auth"
+	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/parser/opcode"
+	plannercore "github.com/pingcap/tidb/planner/core"
+	"github.com/pingcap/tidb/privilege"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util"
+	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/hint"
+	"github.com/pingcap/tidb/util/logutil"
+	"github.com/pingcap/tidb/util/sqlexec"
+	"github.com/pingcap/tidb/util/stmtsummary"
+	"go.uber.org/zap"
+)
+
+const (
+	// recommendIndexTopN is the number of the statements with the top total latency used as the workload.
+	recommendIndexTopN = 50
+	// recommendIndexMaxNum is the max number of the recommended indexes.
+	recommendIndexMaxNum = 5
+	// recommendIndexMaxCols is the max number of the columns of a recommended index.
+	recommendIndexMaxCols = 3
+	// recommendIndexMinReduction is the min ratio of the workload cost that a recommended index should reduce.
+	recommendIndexMinReduction = 0.01
+)
+
+// RecommendIndexExec represents a recommend index executor.
+// It recommends indexes for the workload in the statement summary, or a single statement. The candidate indexes are
+// generated from the indexable predicates and orderings of the statements, and they are scored by re-optimizing the
+// statements with the candidates as hypothetical indexes.
+type RecommendIndexExec struct {
+	baseExecutor
+
+	sql  string
+	done bool
+}
+
+// workloadQuery is a statement of the workload.
+type workloadQuery struct {
+	schema    string
+	sql       string
+	charset   string
+	collation string
+	// weight is the execution count of the statement.
+	weight float64
+	// cost is the estimated cost of the statement with the recommended indexes so far.
+	cost float64
+	// tables are the keys of the tables that the statement accesses.
+	tables map[string]struct{}
+}
+
+// indexCandidate is a candidate index, which is evaluated as a hypothetical index.
+type indexCandidate struct {
+	schema  model.CIStr
+	tblInfo *model.TableInfo
+	idxInfo *model.IndexInfo
+}
+
+func (c *indexCandidate) tableKey() string {
+	return tableKeyOf(c.schema.L, c.tblInfo.Name.L)
+}
+
+func (c *indexCandidate) columnNames() []string {
+	names := make([]string, 0, len(c.idxInfo.Columns))
+	for _, col := range c.idxInfo.Columns {
+		names = append(names, col.Name.O)
+	}
+	return names
+}
+
+// indexRecommendation is a recommended index and its estimated benefit.
+type indexRecommendation struct {
+	candidate *indexCandidate
+	// benefit is the reduced cost of the workload.
+	benefit  float64
+	impacted int
+	topQuery string
+	costs    map[*workloadQuery]float64
+}
+
+func tableKeyOf(schema, table string) string {
+	return schema + "." + table
+}
+
+// Next implements the Executor Next interface.
+func (e *RecommendIndexExec) Next(ctx context.Context, req *chunk.Chunk) error {
+	req.Reset()
+	if e.done {
+		return nil
+	}
+	e.done = true
+
+	queries, err := e.loadWorkload()
+	if err != nil || len(queries) == 0 {
+		return err
+	}
+	sysCtx, err := e.getSysSession()
+	if err != nil {
+		return err
+	}
+	sysVars := sysCtx.GetSessionVars()
+	originDB, originUser := sysVars.CurrentDB, sysVars.User
+	// The statements are planned on behalf of the user, see indexAdvisor.checkPrivilege.
+	sysVars.User = e.ctx.GetSessionVars().User
+	defer func() {
+		sysVars.HypoIndexes = nil
+		sysVars.CurrentDB = originDB
+		sysVars.User = originUser
+		e.releaseSysSession(sysCtx)
+	}()
+
+	advisor := &indexAdvisor{
+		sctx:        sysCtx,
+		is:          e.ctx.GetInfoSchema().(infoschema.InfoSchema),
+		parser:      parser.New(),
+		pm:          privilege.GetPrivilegeManager(e.ctx),
+		activeRoles: e.ctx.GetSessionVars().ActiveRoles,
+		queries:     queries,
+		single:      e.sql != "",
+	}
+	advisor.parser.SetSQLMode(e.ctx.GetSessionVars().SQLMode)
+	advisor.parser.SetParserConfig(e.ctx.GetSessionVars().BuildParserConfig())
+	recommendations, totalCost, err := advisor.recommend(ctx)
+	if err != nil {
+		return err
+	}
+	for _, r := range recommendations {
+		c := r.candidate
+		req.AppendString(0, c.schema.O)
+		req.AppendString(1, c.tblInfo.Name.O)
+		req.AppendString(2, c.idxInfo.Name.O)
+		req.AppendString(3, strings.Join(c.columnNames(), ","))
+		req.AppendFloat64(4, r.benefit/totalCost*100)
+		req.AppendInt64(5, int64(r.impacted))
+		req.AppendString(6, r.topQuery)
+		req.AppendString(7, buildCreateIndexSQL(c))
+	}
+	return nil
+}
+
+// loadWorkload loads the statement given by the user, or the statements with the top total latency in the
+// statement summary.
+func (e *RecommendIndexExec) loadWorkload() ([]*workloadQuery, error) {
+	if e.sql != "" {
+		sessVars := e.ctx.GetSessionVars()
+		charset, collation := sessVars.GetCharsetInfo()
+		return []*workloadQuery{{
+			schema:    sessVars.CurrentDB,
+			sql:       e.sql,
+			charset:   charset,
+			collation: collation,
+			weight:    1,
+		}}, nil
+	}
+	stmts := stmtsummary.StmtSummaryByDigestMap.GetTopLatencyStmts(recommendIndexTopN)
+	queries := make([]*workloadQuery, 0, len(stmts))
+	for _, stmt := range stmts {
+		queries = append(queries, &workloadQuery{
+			schema:    stmt.Schema,
+			sql:       stmt.Query,
+			charset:   stmt.Charset,
+			collation: stmt.Collation,
+			weight:    float64(stmt.ExecCount),
+		})
+	}
+	return queries, nil
+}
+
+// indexAdvisor recommends indexes for the workload greedily: in each round, the candidate which reduces the cost of
+// the workload most is recommended, and it's kept as a hypothetical index for the following rounds.
+type indexAdvisor struct {
+	// sctx is the session to optimize the workload, whose hypothetical indexes are changed by the advisor.
+	sctx   sessionctx.Context
+	is     infoschema.InfoSchema
+	parser *parser.Parser
+	// pm and activeRoles are the privileges of the user, sctx is an internal session so the statements are
+	// checked against them before they're optimized.
+	pm          privilege.Manager
+	activeRoles []*auth.RoleIdentity
+	queries     []*workloadQuery
+	// single indicates the workload is a single statement given by the user, so its errors are returned.
+	single bool
+}
+
+func (a *indexAdvisor) recommend(ctx context.Context) ([]*indexRecommendation, float64, error) {
+	candidates, err := a.generateCandidates(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	queries := a.queries[:0]
+	totalCost := 0.0
+	for _, q := range a.queries {
+		q.cost, err = a.queryCost(ctx, q, nil)
+		if err != nil {
+			if a.single {
+				return nil, 0, err
+			}
+			logutil.BgLogger().Info("[recommend-index] skip the statement which fails to be optimized",
+				zap.String("sql", q.sql), zap.Error(err))
+			continue
+		}
+		queries = append(queries, q)
+		totalCost += q.weight * q.cost
+	}
+	a.queries = queries
+	if len(candidates) == 0 || totalCost <= 0 {
+		return nil, 0, nil
+	}
+
+	var recommended []*indexCandidate
+	var recommendations []*indexRecommendation
+	for len(recommendations) < recommendIndexMaxNum && len(candidates) > 0 {
+		bestIdx := -1
+		var best *indexRecommendation
+		for i, c := range candidates {
+			r, err := a.evaluate(ctx, recommended, c)
+			if err != nil {
+				return nil, 0, err
+			}
+			if best == nil || r.benefit > best.benefit {
+				bestIdx, best = i, r
+			}
+		}
+		if best.benefit < totalCost*recommendIndexMinReduction {
+			break
+		}
+		for q, cost := range best.costs {
+			q.cost = cost
+		}
+		recommended = append(recommended, best.candidate)
+		recommendations = append(recommendations, best)
+		candidates = append(candidates[:bestIdx], candidates[bestIdx+1:]...)
+	}
+	return recommendations, totalCost, nil
+}
+
+// evaluate re-optimizes the statements accessing the table of the candidate, with the recommended indexes and the
+// candidate as hypothetical indexes.
+func (a *indexAdvisor) evaluate(ctx context.Context, recommended []*indexCandidate, c *indexCandidate) (*indexRecommendation, error) {
+	hypoIndexes := append(recommended[:len(recommended):len(recommended)], c)
+	r := &indexRecommendation{candidate: c, costs: make(map[*workloadQuery]float64)}
+	topReduction := 0.0
+	for _, q := range a.queries {
+		if _, ok := q.tables[c.tableKey()]; !ok {
+			continue
+		}
+		cost, err := a.queryCost(ctx, q, hypoIndexes)
+		if err != nil {
+			return nil, err
+		}
+		if cost >= q.cost {
+			continue
+		}
+		reduction := q.weight * (q.cost - cost)
+		r.benefit += reduction
+		r.impacted++
+		r.costs[q] = cost
+		if reduction > topReduction {
+			topReduction = reduction
+			r.topQuery = q.sql
+		}
+	}
+	return r, nil
+}
+
+func (a *indexAdvisor) parse(q *workloadQuery) (ast.StmtNode, error) {
+	stmts, _, err := a.parser.ParseSQL(q.sql, parser.CharsetConnection(q.charset), parser.CollationConnection(q.collation))
+	if err != nil {
+		return nil, util.SyntaxError(err)
+	}
+	if len(stmts) != 1 {
+		return nil, errors.New("Recommend Index: only one statement is supported")
+	}
+	switch stmts[0].(type) {
+	case *ast.SelectStmt, *ast.SetOprStmt, *ast.UpdateStmt, *ast.DeleteStmt:
+		return stmts[0], nil
+	}
+	return nil, errors.New("Recommend Index: only SELECT, UPDATE and DELETE statements are supported")
+}
+
+// checkPrivilege checks whether the user has the privileges to run the statement, so the user can't get the plans
+// of the statements on the tables which the user can't access.
+func (a *indexAdvisor) checkPrivilege(ctx context.Context, q *workloadQuery, stmt ast.StmtNode) error {
+	if a.pm == nil {
+		return nil
+	}
+	a.sctx.GetSessionVars().CurrentDB = q.schema
+	err := plannercore.Preprocess(a.sctx, stmt, plannercore.WithPreprocessorReturn(&plannercore.PreprocessorReturn{InfoSchema: a.is}))
+	if err != nil {
+		return err
+	}
+	builder, _ := plannercore.NewPlanBuilder().Init(a.sctx, a.is, &hint.BlockHintProcessor{})
+	if _, err = builder.Build(ctx, stmt); err != nil {
+		return err
+	}
+	return plannercore.CheckPrivilege(a.activeRoles, a.pm, plannercore.VisitInfo4PrivCheck(a.is, stmt, builder.GetVisitInfo()))
+}
+
+// queryCost returns the estimated cost of the statement with the hypothetical indexes.
+func (a *indexAdvisor) queryCost(ctx context.Context, q *workloadQuery, hypoIndexes []*indexCandidate) (float64, error) {
+	stmt, err := a.parse(q)
+	if err != nil {
+		return 0, err
+	}
+	sessVars := a.sctx.GetSessionVars()
+	sessVars.CurrentDB = q.schema
+	sessVars.HypoIndexes = nil
+	for _, c := range hypoIndexes {
+		if sessVars.HypoIndexes == nil {
+			sessVars.HypoIndexes = make(map[string]map[string]map[string]*model.IndexInfo)
+		}
+		if sessVars.HypoIndexes[c.schema.L] == nil {
+			sessVars.HypoIndexes[c.schema.L] = make(map[string]map[string]*model.IndexInfo)
+		}
+		if sessVars.HypoIndexes[c.schema.L][c.tblInfo.Name.L] == nil {
+			sessVars.HypoIndexes[c.schema.L][c.tblInfo.Name.L] = make(map[string]*model.IndexInfo)
+		}
+		sessVars.HypoIndexes[c.schema.L][c.tblInfo.Name.L][c.idxInfo.Name.L] = c.idxInfo
+	}
+
+	rs, err := a.sctx.(sqlexec.SQLExecutor).ExecuteStmt(ctx, &ast.ExplainStmt{Stmt: stmt, Format: types.ExplainFormatVerbose})
+	if err != nil {
+		return 0, err
+	}
+	rows, err := sqlexec.DrainRecordSet(ctx, rs, 8)
+	if closeErr := rs.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, err
+	}
+	// The estCost of the root operator is the cost of the whole plan. Operators like Update have no cost.
+	for _, row := range rows {
+		if estCost := row.GetString(2); estCost != "N/A" {
+			return strconv.ParseFloat(estCost, 64)
+		}
+	}
+	return 0, errors.Errorf("Recommend Index: no cost is estimated for %s", q.sql)
+}
+
+// generateCandidates generates the candidate indexes from the workload. The columns compared with constants or other
+// columns and the columns in ORDER BY or GROUP BY clauses are indexable, and the candidates are:
+// 1. every indexable column alone;
+// 2. the columns in equal conditions together;
+// 3. the columns in equal conditions followed by a column in range conditions or the orderings.
+func (a *indexAdvisor) generateCandidates(ctx context.Context) ([]*indexCandidate, error) {
+	var candidates []*indexCandidate
+	existing := make(map[string]struct{})
+	names := make(map[string]map[string]struct{})
+	queries := a.queries[:0]
+	for _, q := range a.queries {
+		stmt, err := a.parse(q)
+		if err == nil {
+			err = a.checkPrivilege(ctx, q, stmt)
+		}
+		if err != nil {
+			if a.single {
+				return nil, err
+			}
+			// The statements of the workload may be run by other users.
+			logutil.BgLogger().Info("[recommend-index] skip the statement which can't be accessed",
+				zap.String("sql", q.sql), zap.Error(err))
+			continue
+		}
+		queries = append(queries, q)
+		collector := newIndexableColumnCollector(a.is, model.NewCIStr(q.schema))
+		collector.collect(stmt)
+		q.tables = make(map[string]struct{}, len(collector.tables))
+		for _, t := range collector.tables {
+			q.tables[t.key()] = struct{}{}
+			for _, cols := range t.candidateColumns() {
+				key := t.key() + "(" + strings.Join(cols, ",") + ")"
+				if _, ok := existing[key]; ok || t.coveredByIndex(cols) {
+					continue
+				}
+				existing[key] = struct{}{}
+				if names[t.key()] == nil {
+					names[t.key()] = make(map[string]struct{})
+				}
+				c, err := buildIndexCandidate(t, cols, names[t.key()])
+				if err != nil {
+					// The columns may be not indexable, such as BLOB and TEXT columns.
+					continue
+				}
+				candidates = append(candidates, c)
+			}
+		}
+	}
+	a.queries = queries
+	return candidates, nil
+}
+
+func buildIndexCandidate(t *indexableTable, cols []string, usedNames map[string]struct{}) (*indexCandidate, error) {
+	name := "idx_" + strings.Join(cols, "_")
+	if len(name) > mysql.MaxIndexIdentifierLen-4 {
+		name = name[:mysql.MaxIndexIdentifierLen-4]
+	}
+	for i := 1; t.tblInfo.FindIndexByName(name) != nil || hasName(usedNames, name); i++ {
+		name = fmt.Sprintf("%s_%d", strings.TrimRight(name, "_0123456789"), i)
+	}
+	specs := make([]*ast.IndexPartSpecification, 0, len(cols))
+	for _, col := range cols {
+		specs = append(specs, &ast.IndexPartSpecification{Column: &ast.ColumnName{Name: model.NewCIStr(col)}, Length: types.UnspecifiedLength})
+	}
+	idxInfo, err := ddl.BuildHypoIndexInfo(t.tblInfo, ast.IndexKeyTypeNone, model.NewCIStr(name), specs)
+	if err != nil {
+		return nil, err
+	}
+	usedNames[strings.ToLower(name)] = struct{}{}
+	return &indexCandidate{schema: t.schema, tblInfo: t.tblInfo, idxInfo: idxInfo}, nil
+}
+
+func hasName(names map[string]struct{}, name string) bool {
+	_, ok := names[strings.ToLower(name)]
+	return ok
+}
+
+func buildCreateIndexSQL(c *indexCandidate) string {
+	cols := make([]string, 0, len(c.idxInfo.Columns))
+	for _, col := range c.columnNames() {
+		cols = append(cols, quoteIdentifier(col))
+	}
+	return fmt.Sprintf("CREATE INDEX %s ON %s.%s(%s)", quoteIdentifier(c.idxInfo.Name.O),
+		quoteIdentifier(c.schema.O), quoteIdentifier(c.tblInfo.Name.O), strings.Join(cols, ", "))
+}
+
+func quoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// indexableTable is a table accessed by a statement and its indexable columns.
+type indexableTable struct {
+	schema    model.CIStr
+	tblInfo   *model.TableInfo
+	eqCols    []string
+	rangeCols []string
+	orderCols []string
+}
+
+func (t *indexableTable) key() string {
+	return tableKeyOf(t.schema.L, t.tblInfo.Name.L)
+}
+
+func (t *indexableTable) candidateColumns() [][]string {
+	var candidates [][]string
+	for _, cols := range [][]string{t.eqCols, t.rangeCols, t.orderCols} {
+		for _, col := range cols {
+			candidates = append(candidates, []string{col})
+		}
+	}
+	if len(t.eqCols) > 1 {
+		n := len(t.eqCols)
+		if n > recommendIndexMaxCols {
+			n = recommendIndexMaxCols
+		}
+		candidates = append(candidates, t.eqCols[:n])
+	}
+	if len(t.eqCols) > 0 {
+		n := len(t.eqCols)
+		if n > recommendIndexMaxCols-1 {
+			n = recommendIndexMaxCols - 1
+		}
+		for _, cols := range [][]string{t.rangeCols, t.orderCols} {
+			for _, col := range cols {
+				if containsString(t.eqCols, col) {
+					continue
+				}
+				candidate := make([]string, 0, n+1)
+				candidate = append(candidate, t.eqCols[:n]...)
+				candidates = append(candidates, append(candidate, col))
+			}
+		}
+	}
+	return candidates
+}
+
+// coveredByIndex checks whether an existing index starts with the columns.
+func (t *indexableTable) coveredByIndex(cols []string) bool {
+	for _, idx := range t.tblInfo.Indices {
+		if idx.State != model.StatePublic || len(idx.Columns) < len(cols) {
+			continue
+		}
+		covered := true
+		for i, col := range cols {
+			if idx.Columns[i].Name.L != col || idx.Columns[i].Length != types.UnspecifiedLength {
+				covered = false
+				break
+			}
+		}
+		if covered {
+			return true
+		}
+	}
+	if t.tblInfo.PKIsHandle && len(cols) == 1 {
+		if pk := t.tblInfo.GetPkColInfo(); pk != nil && pk.Name.L == cols[0] {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(strs []string, str string) bool {
+	for _, s := range strs {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
+
+func appendUnique(strs []string, str string) []string {
+	if containsString(strs, str) {
+		return strs
+	}
+	return append(strs, str)
+}
+
+// indexableColumnCollector collects the tables and their indexable columns from a statement.
+type indexableColumnCollector struct {
+	is        infoschema.InfoSchema
+	defaultDB model.CIStr
+	tables    []*indexableTable
+	// aliases maps the names or aliases of the tables in the statement to the tables.
+	aliases map[string]*indexableTable
+}
+
+func newIndexableColumnCollector(is infoschema.InfoSchema, defaultDB model.CIStr) *indexableColumnCollector {
+	return &indexableColumnCollector{is: is, defaultDB: defaultDB, aliases: make(map[string]*indexableTable)}
+}
+
+func (c *indexableColumnCollector) collect(stmt ast.StmtNode) {
+	// The tables are collected in the first pass, so that the columns can be resolved in the second pass.
+	stmt.Accept(&tableSourceVisitor{collector: c})
+	stmt.Accept(&indexableColumnVisitor{collector: c})
+}
+
+func (c *indexableColumnCollector) addTable(tn *ast.TableName, asName model.CIStr) {
+	schema := tn.Schema
+	if schema.L == "" {
+		schema = c.defaultDB
+	}
+	if util.IsMemOrSysDB(schema.L) {
+		return
+	}
+	tbl, err := c.is.TableByName(schema, tn.Name)
+	if err != nil {
+		return
+	}
+	tblInfo := tbl.Meta()
+	if tblInfo.IsView() || tblInfo.IsSequence() || tblInfo.TempTableType != model.TempTableNone {
+		return
+	}
+	dbInfo, ok := c.is.SchemaByName(schema)
+	if !ok {
+		return
+	}
+	var t *indexableTable
+	for _, existing := range c.tables {
+		if existing.key() == tableKeyOf(dbInfo.Name.L, tblInfo.Name.L) {
+			t = existing
+			break
+		}
+	}
+	if t == nil {
+		t = &indexableTable{schema: dbInfo.Name, tblInfo: tblInfo}
+		c.tables = append(c.tables, t)
+	}
+	name := tn.Name.L
+	if asName.L != "" {
+		name = asName.L
+	}
+	c.aliases[name] = t
+}
+
+// resolve finds the table of the column, it returns nil if the column is ambiguous or not found.
+func (c *indexableColumnCollector) resolve(col *ast.ColumnName) *indexableTable {
+	if col.Table.L != "" {
+		t := c.aliases[col.Table.L]
+		if t == nil || (col.Schema.L != "" && col.Schema.L != t.schema.L) {
+			return nil
+		}
+		if model.FindColumnInfo(t.tblInfo.Columns, col.Name.L) == nil {
+			return nil
+		}
+		return t
+	}
+	var found *indexableTable
+	for _, t := range c.tables {
+		if model.FindColumnInfo(t.tblInfo.Columns, col.Name.L) != nil {
+			if found != nil {
+				return nil
+			}
+			found = t
+		}
+	}
+	return found
+}
+
+const (
+	indexableEq = iota
+	indexableRange
+	indexableOrder
+)
+
+func (c *indexableColumnCollector) addColumn(expr ast.ExprNode, tp int) {
+	colExpr, ok := expr.(*ast.ColumnNameExpr)
+	if !ok {
+		return
+	}
+	t := c.resolve(colExpr.Name)
+	if t == nil {
+		return
+	}
+	name := colExpr.Name.Name.L
+	switch tp {
+	case indexableEq:
+		t.eqCols = appendUnique(t.eqCols, name)
+	case indexableRange:
+		t.rangeCols = appendUnique(t.rangeCols, name)
+	case indexableOrder:
+		t.orderCols = appendUnique(t.orderCols, name)
+	}
+}
+
+type tableSourceVisitor struct {
+	collector *indexableColumnCollector
+}
+
+// Enter implements ast.Visitor interface.
+func (v *tableSourceVisitor) Enter(in ast.Node) (ast.Node, bool) {
+	if ts, ok := in.(*ast.TableSource); ok {
+		if tn, ok := ts.Source.(*ast.TableName); ok {
+			v.collector.addTable(tn, ts.AsName)
+		}
+	}
+	return in, false
+}
+
+// Leave implements ast.Visitor interface.
+func (v *tableSourceVisitor) Leave(in ast.Node) (ast.Node, bool) {
+	return in, true
+}
+
+type indexableColumnVisitor struct {
+	collector *indexableColumnCollector
+}
+
+// Enter implements ast.Visitor interface.
+func (v *indexableColumnVisitor) Enter(in ast.Node) (ast.Node, bool) {
+	switch x := in.(type) {
+	case *ast.BinaryOperationExpr:
+		tp := -1
+		switch x.Op {
+		case opcode.EQ, opcode.NullEQ:
+			tp = indexableEq
+		case opcode.LT, opcode.LE, opcode.GT, opcode.GE:
+			tp = indexableRange
+		}
+		if tp >= 0 {
+			v.collector.addColumn(x.L, tp)
+			v.collector.addColumn(x.R, tp)
+		}
+	case *ast.PatternInExpr:
+		if !x.Not && x.Sel == nil {
+			v.collector.addColumn(x.Expr, indexableEq)
+		}
+	case *ast.BetweenExpr:
+		if !x.Not {
+			v.collector.addColumn(x.Expr, indexableRange)
+		}
+	case *ast.PatternLikeExpr:
+		if !x.Not {
+			v.collector.addColumn(x.Expr, indexableRange)
+		}
+	case *ast.OrderByClause:
+		for _, item := range x.Items {
+			v.collector.addColumn(item.Expr, indexableOrder)
+		}
+	case *ast.GroupByClause:
+		for _, item := range x.Items {
+			v.collector.addColumn(item.Expr, indexableOrder)
+		}
+	}
+	return in, false
+}
+
+// Leave implements ast.Visitor interface.
+func (v *indexableColumnVisitor) Leave(in ast.Node) (ast.Node, bool) {
+	return in, true
+}
@@ -0,0 +1,92 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pingcap/tidb/parser/auth"
+	"github.com/pingcap/tidb/testkit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecommendIndex(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+	tk := testkit.NewTestKit(t, store)
+	require.True(t, tk.Session().Auth(&auth.UserIdentity{Username: "root", Hostname: "%"}, nil, nil))
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b int, c int, d text, key ic(c))")
+
+	rows := tk.MustQuery("recommend index run for 'select * from t where a = 1 and b > 2'").Rows()
+	require.Len(t, rows, 1)
+	require.Equal(t, []interface{}{"test", "t", "idx_a_b", "a,b"}, rows[0][:4])
+	require.Equal(t, "1", rows[0][5])
+	require.Equal(t, "select * from t where a = 1 and b > 2", rows[0][6])
+	require.Equal(t, "CREATE INDEX `idx_a_b` ON `test`.`t`(`a`, `b`)", rows[0][7])
+
+	// The existing index ic is extended by the ordering column.
+	rows = tk.MustQuery("recommend index run for 'select * from t where c = 1 order by b'").Rows()
+	require.Len(t, rows, 1)
+	require.Equal(t, []interface{}{"test", "t", "idx_c_b", "c,b"}, rows[0][:4])
+	// The existing index is good enough, and TEXT columns can't be indexed without prefix lengths.
+	tk.MustQuery("recommend index run for 'select * from t where c = 1'").Check(testkit.Rows())
+	tk.MustQuery("recommend index run for 'select * from t where d = \"x\"'").Check(testkit.Rows())
+
+	err := tk.QueryToErr("recommend index run for 'insert into t values (1, 1, 1, \"x\")'")
+	require.EqualError(t, err, "Recommend Index: only SELECT, UPDATE and DELETE statements are supported")
+	err = tk.QueryToErr("recommend index run for 'select * from t1'")
+	require.EqualError(t, err, "[schema:1146]Table 'test.t1' doesn't exist")
+
+	// Recommend indexes for the workload in the statement summary.
+	tk.MustExec("set global tidb_enable_stmt_summary = 1")
+	defer tk.MustExec("set global tidb_enable_stmt_summary = default")
+	for i := 0; i < 5; i++ {
+		tk.MustQuery(fmt.Sprintf("select * from t where a = %d", i))
+		tk.MustQuery("select * from t t1 join t t2 on t1.a = t2.b where t1.c = 1")
+		tk.MustExec("update t set d = 'x' where b > 10")
+	}
+	rows = tk.MustQuery("recommend index run").Rows()
+	require.Len(t, rows, 2)
+	require.Equal(t, []interface{}{"test", "t", "idx_b", "b"}, rows[0][:4])
+	require.Equal(t, "select * from t t1 join t t2 on t1.a = t2.b where t1.c = 1", rows[0][6])
+	require.Equal(t, []interface{}{"test", "t", "idx_a", "a"}, rows[1][:4])
+
+	// The user can't get the plans of the statements on the tables which the user can't access.
+	tk.MustExec("create table t2 (a int, b int)")
+	for i := 0; i < 20; i++ {
+		tk.MustQuery(fmt.Sprintf("select * from t2 where b = %d", i))
+	}
+	rows = tk.MustQuery("recommend index run").Rows()
+	require.Len(t, rows, 3)
+	require.Equal(t, []interface{}{"test", "t2", "idx_b", "b"}, rows[0][:4])
+	tk.MustExec("create user 'recommend'@'%'")
+	tk.MustExec("grant process on *.* to 'recommend'@'%'")
+	tk.MustExec("grant select, update on test.t to 'recommend'@'%'")
+	tk1 := testkit.NewTestKit(t, store)
+	require.True(t, tk1.Session().Auth(&auth.UserIdentity{Username: "recommend", Hostname: "%"}, nil, nil))
+	tk1.MustExec("use test")
+	err = tk1.QueryToErr("recommend index run for 'select * from t2 where a = 1'")
+	require.EqualError(t, err, "[planner:1142]SELECT command denied to user 'recommend'@'%' for table 't2'")
+	rows = tk1.MustQuery("recommend index run for 'select * from t where a = 1'").Rows()
+	require.Len(t, rows, 1)
+	rows = tk1.MustQuery("recommend index run").Rows()
+	require.Len(t, rows, 2)
+	for _, row := range rows {
+		require.Equal(t, "t", row[1])
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pingcap/tidb/errno"
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/sessionctx/stmtctx"
+	"github.com/pingcap/tidb/testkit"
+	"github.com/pingcap/tidb/util"
+	"github.com/pingcap/tidb/util/runaway"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunawayQuarantine(t *testing.T) {
+	store, dom, clean := testkit.CreateMockStoreAndDomain(t)
+	defer clean()
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t (a int, b int)")
+
+	_, digest := parser.NormalizeDigest("select * from t where a = 1")
+	tk.MustExec("insert into mysql.runaway_rules (sql_digest, max_execution_time, quarantine_duration) values (?, 60000, 600)", digest.String())
+	m := dom.RunawayManager()
+	require.NoError(t, m.Update(tk.Session()))
+
+	// The statement is not quarantined until it is killed as a runaway query.
+	tk.MustQuery("select * from t where a = 1").Check(testkit.Rows())
+	info := &util.ProcessInfo{Digest: digest.String(), Info: "select * from t where a = 1", StmtCtx: &stmtctx.StatementContext{}}
+	require.Nil(t, m.CheckRunaway(info, time.Second))
+	require.NotNil(t, m.CheckRunaway(info, time.Minute+time.Second))
+
+	tk.MustGetErrCode("select * from t where a = 2", errno.ErrRunawayQueryQuarantined)
+	tk.MustQuery("select * from t where b = 1").Check(testkit.Rows())
+	tk.MustExec("prepare stmt from 'select * from t where a = ?'")
+	tk.MustExec("set @a = 1")
+	tk.MustGetErrCode("execute stmt using @a", errno.ErrRunawayQueryQuarantined)
+
+	require.NoError(t, m.Flush(tk.Session(), "127.0.0.1:4000"))
+	tk.MustQuery("select instance, action, sql_digest, quarantine_end is not null from mysql.runaway_history order by time").Check(testkit.Rows(
+		"127.0.0.1:4000 kill "+digest.String()+" 1",
+		"127.0.0.1:4000 reject "+digest.String()+" 0",
+		"127.0.0.1:4000 reject "+digest.String()+" 0",
+	))
+
+	// The quarantine is shared through the history.
+	m2 := runaway.NewManager()
+	require.NoError(t, m2.Update(tk.Session()))
+	require.True(t, runaway.ErrQueryQuarantined.Equal(m2.CheckQuarantine(digest.String(), "", "")))
+
+	// Dropping the rule releases the quarantine.
+	tk.MustExec("delete from mysql.runaway_rules")
+	require.NoError(t, m.Update(tk.Session()))
+	tk.MustQuery("select * from t where a = 1").Check(testkit.Rows())
+
+	// An invalid row is skipped without affecting the other rules.
+	tk.MustExec("insert into mysql.runaway_rules (sql_digest, max_execution_time) values ('select * from t', 60000)")
+	tk.MustExec("insert into mysql.runaway_rules (sql_digest, max_execution_time, quarantine_duration) values (?, 60000, 600)", strings.ToUpper(digest.String()))
+	require.NoError(t, m.Update(tk.Session()))
+	require.NotNil(t, m.CheckRunaway(info, time.Minute+time.Second))
+	tk.MustGetErrCode("select * from t where a = 1", errno.ErrRunawayQueryQuarantined)
+}
+
+func TestRunawayHistoryFlush(t *testing.T) {
+	store, dom, clean := testkit.CreateMockStoreAndDomain(t)
+	defer clean()
+	tk := testkit.NewTestKit(t, store)
+	m := dom.RunawayManager()
+	m.SetRules([]*runaway.Rule{{ID: 1, MaxExecutionTime: time.Minute}})
+
+	// The sample SQL is truncated without splitting a multi-byte character.
+	info := &util.ProcessInfo{Digest: "d1", Info: "select '" + strings.Repeat("中", 2000) + "'", StmtCtx: &stmtctx.StatementContext{}}
+	require.NotNil(t, m.CheckRunaway(info, time.Hour))
+	require.NoError(t, m.Flush(tk.Session(), "127.0.0.1:4000"))
+	tk.MustQuery("select sql_digest, length(sample_sql) from mysql.runaway_history").Check(testkit.Rows("d1 4094"))
+
+	// A record that can't be written is dropped after a few retries.
+	tk.MustExec("rename table mysql.runaway_history to mysql.runaway_history_bak")
+	info.Digest = "d2"
+	require.NotNil(t, m.CheckRunaway(info, time.Hour))
+	for i := 0; i < 3; i++ {
+		require.Error(t, m.Flush(tk.Session(), "127.0.0.1:4000"))
+	}
+	tk.MustExec("rename table mysql.runaway_history_bak to mysql.runaway_history")
+	require.NoError(t, m.Flush(tk.Session(), "127.0.0.1:4000"))
+	tk.MustQuery("select sql_digest from mysql.runaway_history").Check(testkit.Rows("d1"))
+	info.Digest = "d3"
+	require.NotNil(t, m.CheckRunaway(info, time.Hour))
+	require.NoError(t, m.Flush(tk.Session(), "127.0.0.1:4000"))
+	tk.MustQuery("select sql_digest from mysql.runaway_history order by sql_digest").Check(testkit.Rows("d1", "d3"))
+}
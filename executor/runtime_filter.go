@@ -0,0 +1,368 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/parser/mysql"
+	plannercore "github.com/pingcap/tidb/planner/core"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/stmtctx"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/collate"
+	"github.com/pingcap/tidb/util/execdetails"
+	"github.com/pingcap/tipb/go-tipb"
+)
+
+// runtimeFilter collects the values of a join key of the build side during the build phase.
+// After the build phase, they are turned into an IN condition when the number of distinct values
+// is small, or a range condition of the min and max value otherwise.
+type runtimeFilter struct {
+	buildColIdx  int
+	buildType    *types.FieldType
+	targetColumn *expression.Column
+	maxInSize    int
+	collator     collate.Collator
+
+	// values is set to nil once the number of distinct values exceeds maxInSize.
+	values   map[string]types.Datum
+	min, max types.Datum
+	hasValue bool
+	keyBuf   []byte
+}
+
+func newRuntimeFilter(rf *plannercore.RuntimeFilter, buildColIdx int, buildType *types.FieldType, maxInSize int) *runtimeFilter {
+	return &runtimeFilter{
+		buildColIdx:  buildColIdx,
+		buildType:    buildType,
+		targetColumn: rf.TargetColumn,
+		maxInSize:    maxInSize,
+		collator:     collate.GetCollator(buildType.Collate),
+	}
+}
+
+func (f *runtimeFilter) reset() {
+	f.values = make(map[string]types.Datum)
+	f.min, f.max = types.Datum{}, types.Datum{}
+	f.hasValue = false
+}
+
+// collect records the join keys of the rows in the chunk. If selected is not nil, only the
+// selected rows are recorded.
+func (f *runtimeFilter) collect(sc *stmtctx.StatementContext, chk *chunk.Chunk, selected []bool) (err error) {
+	for i := 0; i < chk.NumRows(); i++ {
+		if selected != nil && !selected[i] {
+			continue
+		}
+		row := chk.GetRow(i)
+		if row.IsNull(f.buildColIdx) {
+			continue
+		}
+		var d types.Datum
+		datum := row.GetDatum(f.buildColIdx, f.buildType)
+		datum.Copy(&d)
+		if !f.hasValue {
+			f.min, f.max, f.hasValue = d, d, true
+		} else {
+			if cmp, err := d.Compare(sc, &f.min, f.collator); err != nil {
+				return err
+			} else if cmp < 0 {
+				f.min = d
+			}
+			if cmp, err := d.Compare(sc, &f.max, f.collator); err != nil {
+				return err
+			} else if cmp > 0 {
+				f.max = d
+			}
+		}
+		if f.values == nil {
+			continue
+		}
+		f.keyBuf, err = codec.EncodeKey(sc, f.keyBuf[:0], d)
+		if err != nil {
+			return err
+		}
+		if _, ok := f.values[string(f.keyBuf)]; !ok {
+			f.values[string(f.keyBuf)] = d
+			if len(f.values) > f.maxInSize {
+				f.values = nil
+			}
+		}
+	}
+	return nil
+}
+
+// buildConditions builds the conditions on the target column from the collected values.
+// noMatch is true if no value is collected, which means no row of the probe side can be joined.
+func (f *runtimeFilter) buildConditions(ctx sessionctx.Context) (conds []expression.Expression, desc string, noMatch bool, err error) {
+	if !f.hasValue {
+		return nil, "", true, nil
+	}
+	sc := ctx.GetSessionVars().StmtCtx
+	retType := types.NewFieldType(mysql.TypeTiny)
+	if f.values != nil {
+		values := make([]types.Datum, 0, len(f.values))
+		for _, d := range f.values {
+			values = append(values, d)
+		}
+		sort.Slice(values, func(i, j int) bool {
+			cmp, _ := values[i].Compare(sc, &values[j], f.collator)
+			return cmp < 0
+		})
+		args := make([]expression.Expression, 0, len(values)+1)
+		args = append(args, f.targetColumn)
+		for _, d := range values {
+			args = append(args, &expression.Constant{Value: d, RetType: f.targetColumn.RetType})
+		}
+		cond, err := expression.NewFunction(ctx, ast.In, retType, args...)
+		if err != nil {
+			return nil, "", false, err
+		}
+		return []expression.Expression{cond}, fmt.Sprintf("in(%s, %d values)", f.targetColumn, len(values)), false, nil
+	}
+	lower, err := expression.NewFunction(ctx, ast.GE, retType, f.targetColumn, &expression.Constant{Value: f.min, RetType: f.targetColumn.RetType})
+	if err != nil {
+		return nil, "", false, err
+	}
+	upper, err := expression.NewFunction(ctx, ast.LE, retType, f.targetColumn, &expression.Constant{Value: f.max, RetType: f.targetColumn.RetType})
+	if err != nil {
+		return nil, "", false, err
+	}
+	minStr, err := f.min.ToString()
+	if err != nil {
+		return nil, "", false, err
+	}
+	maxStr, err := f.max.ToString()
+	if err != nil {
+		return nil, "", false, err
+	}
+	return []expression.Expression{lower, upper}, fmt.Sprintf("range(%s, %s, %s)", f.targetColumn, minStr, maxStr), false, nil
+}
+
+// runtimeFilterTarget is the table reader on the probe side of a hash join which the runtime
+// filters are pushed into. The pushed conditions are merged into the selection of the coprocessor
+// request, and are used to prune the partitions again when the table is read in dynamic prune mode.
+type runtimeFilterTarget struct {
+	reader *TableReaderExecutor
+
+	origPlans          []plannercore.PhysicalPlan
+	origTablePlan      plannercore.PhysicalPlan
+	origExecutors      []*tipb.Executor
+	origRootExecutor   *tipb.Executor
+	origKVRangeBuilder kvRangeBuilder
+
+	// partTable and partInfo are set when the partitions of the table are pruned at runtime.
+	partTable table.PartitionedTable
+	partInfo  *plannercore.PartitionInfo
+}
+
+func newRuntimeFilterTarget(reader *TableReaderExecutor, v *plannercore.PhysicalTableReader) *runtimeFilterTarget {
+	t := &runtimeFilterTarget{
+		reader:             reader,
+		origPlans:          reader.plans,
+		origTablePlan:      reader.tablePlan,
+		origExecutors:      reader.dagPB.Executors,
+		origRootExecutor:   reader.dagPB.RootExecutor,
+		origKVRangeBuilder: reader.kvRangeBuilder,
+	}
+	if _, ok := reader.kvRangeBuilder.(kvRangeBuilderFromRangeAndPartition); ok {
+		if pt, ok := reader.table.(table.PartitionedTable); ok {
+			t.partTable, t.partInfo = pt, &v.PartitionInfo
+		}
+	}
+	return t
+}
+
+// reset restores the reader, so that the filters of the last execution are not carried over.
+func (t *runtimeFilterTarget) reset() {
+	t.reader.plans = t.origPlans
+	t.reader.tablePlan = t.origTablePlan
+	t.reader.dagPB.Executors = t.origExecutors
+	t.reader.dagPB.RootExecutor = t.origRootExecutor
+	t.reader.kvRangeBuilder = t.origKVRangeBuilder
+}
+
+// apply pushes the conditions into the reader. It returns the number of the partitions left
+// after pruning, which is -1 if the partitions are not pruned at runtime.
+func (t *runtimeFilterTarget) apply(ctx sessionctx.Context, conds []expression.Expression) (partitions int, err error) {
+	var sel *plannercore.PhysicalSelection
+	scan := t.origPlans[0]
+	if origSel, ok := t.origTablePlan.(*plannercore.PhysicalSelection); ok {
+		// Only one selection is expected on the table scan, so the conditions are merged into it.
+		copied := *origSel
+		copied.Conditions = make([]expression.Expression, 0, len(origSel.Conditions)+len(conds))
+		copied.Conditions = append(append(copied.Conditions, origSel.Conditions...), conds...)
+		sel = &copied
+	} else {
+		sel = plannercore.PhysicalSelection{Conditions: conds}.Init(ctx, scan.Stats(), scan.SelectBlockOffset())
+		sel.SetChildren(scan)
+	}
+	plans := []plannercore.PhysicalPlan{scan, sel}
+	if t.reader.storeType == kv.TiFlash {
+		execs, _, err := constructDistExecForTiFlash(ctx, sel)
+		if err != nil {
+			return -1, err
+		}
+		t.reader.dagPB.RootExecutor = execs[0]
+	} else {
+		t.reader.dagPB.Executors, _, err = constructDistExec(ctx, plans)
+		if err != nil {
+			return -1, err
+		}
+	}
+	t.reader.plans, t.reader.tablePlan = plans, sel
+
+	if t.partTable == nil {
+		return -1, nil
+	}
+	pruningConds := make([]expression.Expression, 0, len(t.partInfo.PruningConds)+len(conds))
+	pruningConds = append(append(pruningConds, t.partInfo.PruningConds...), conds...)
+	parts, err := partitionPruning(ctx, t.partTable, pruningConds, t.partInfo.PartitionNames, t.partInfo.Columns, t.partInfo.ColumnNames)
+	if err != nil {
+		return -1, err
+	}
+	t.reader.kvRangeBuilder = kvRangeBuilderFromRangeAndPartition{
+		sctx:       ctx,
+		partitions: parts,
+	}
+	return len(parts), nil
+}
+
+// runtimeFilterStats records how the runtime filters work in a hash join.
+type runtimeFilterStats struct {
+	filters   []string
+	probeRows int64
+	// partitions is the number of the partitions left after runtime pruning, or -1 if not pruned.
+	partitions      int
+	totalPartitions int
+	scanID          int
+	coll            *execdetails.RuntimeStatsColl
+}
+
+func (s *runtimeFilterStats) clone() *runtimeFilterStats {
+	newStats := *s
+	newStats.filters = append([]string(nil), s.filters...)
+	return &newStats
+}
+
+// String outputs the rows read by the table scan and the rows left after the filters. The
+// filter rate also includes the conditions that were already pushed down by the planner.
+func (s *runtimeFilterStats) String() string {
+	buf := bytes.NewBuffer(make([]byte, 0, 64))
+	buf.WriteString("runtime_filter:{filters:[")
+	for i, f := range s.filters {
+		if i > 0 {
+			buf.WriteString(" ")
+		}
+		buf.WriteString(f)
+	}
+	buf.WriteString("]")
+	if s.partitions >= 0 {
+		buf.WriteString(", partitions:")
+		buf.WriteString(strconv.Itoa(s.partitions))
+		buf.WriteString("/")
+		buf.WriteString(strconv.Itoa(s.totalPartitions))
+	}
+	if s.coll != nil {
+		if copStats := s.coll.GetCopStats(s.scanID); copStats != nil {
+			scanRows := copStats.GetActRows()
+			buf.WriteString(", scan:")
+			buf.WriteString(strconv.FormatInt(scanRows, 10))
+			buf.WriteString(", probe:")
+			buf.WriteString(strconv.FormatInt(s.probeRows, 10))
+			if scanRows > 0 {
+				buf.WriteString(fmt.Sprintf(", filter_rate:%.2f%%", float64(scanRows-s.probeRows)*100/float64(scanRows)))
+			}
+		}
+	}
+	buf.WriteString("}")
+	return buf.String()
+}
+
+// openProbeSideWithRuntimeFilters waits for the build side, pushes the runtime filters into the
+// probe side and then opens it. skipProbe is true if no row of the probe side needs to be read.
+func (e *HashJoinExec) openProbeSideWithRuntimeFilters(ctx context.Context) (skipProbe bool, err error) {
+	emptyBuild, err := e.wait4BuildSide()
+	if err != nil || emptyBuild {
+		return true, err
+	}
+	var conds []expression.Expression
+	var descs []string
+	for _, f := range e.runtimeFilters {
+		fConds, desc, noMatch, err := f.buildConditions(e.ctx)
+		if err != nil {
+			return true, err
+		}
+		if noMatch {
+			return true, nil
+		}
+		conds = append(conds, fConds...)
+		descs = append(descs, desc)
+	}
+	sc := e.ctx.GetSessionVars().StmtCtx
+	if !expression.CanExprsPushDown(sc, conds, e.ctx.GetClient(), e.rfTarget.reader.storeType) {
+		return false, e.openProbeSide(ctx)
+	}
+	partitions, err := e.rfTarget.apply(e.ctx, conds)
+	if err != nil {
+		return true, err
+	}
+	if e.stats != nil {
+		e.stats.runtimeFilter = &runtimeFilterStats{
+			filters:    descs,
+			partitions: partitions,
+			scanID:     e.rfTarget.origPlans[0].ID(),
+			coll:       e.ctx.GetSessionVars().StmtCtx.RuntimeStatsColl,
+		}
+		if e.rfTarget.partTable != nil {
+			e.stats.runtimeFilter.totalPartitions = len(e.rfTarget.partTable.Meta().Partition.Definitions)
+		}
+	}
+	if partitions == 0 {
+		return true, nil
+	}
+	return false, e.openProbeSide(ctx)
+}
+
+func (e *HashJoinExec) openProbeSide(ctx context.Context) error {
+	if err := e.probeSideExec.Open(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	e.probeSideOpened = true
+	return nil
+}
+
+// collectRuntimeFilters records the join keys of the build side rows into the runtime filters.
+func (e *HashJoinExec) collectRuntimeFilters(chk *chunk.Chunk, selected []bool) error {
+	sc := e.ctx.GetSessionVars().StmtCtx
+	for _, f := range e.runtimeFilters {
+		if err := f.collect(sc, chk, selected); err != nil {
+			return err
+		}
+	}
+	return nil
+}
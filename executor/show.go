@@ -46,6 +46,7 @@ import (
 	"github.com/pingcap/tidb/privilege"
 	"github.com/pingcap/tidb/privilege/privileges"
 	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/sessionstates"
 	"github.com/pingcap/tidb/sessionctx/stmtctx"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/store/helper"
@@ -225,6 +226,8 @@ func (e *ShowExec) fetchAll(ctx context.Context) error {
 		return e.fetchShowBRIE(ast.BRIEKindRestore)
 	case ast.ShowPlacementLabels:
 		return e.fetchShowPlacementLabels(ctx)
+	case ast.ShowSessionStates:
+		return e.fetchShowSessionStates(ctx)
 	case ast.ShowPlacement:
 		return e.fetchShowPlacement(ctx)
 	case ast.ShowPlacementForDatabase:
@@ -1891,6 +1894,19 @@ func (e *ShowExec) fetchShowBuiltins() error {
 	return nil
 }
 
+func (e *ShowExec) fetchShowSessionStates(ctx context.Context) error {
+	sessionStates := &sessionstates.SessionStates{}
+	if err := e.ctx.EncodeSessionStates(ctx, e.ctx, sessionStates); err != nil {
+		return err
+	}
+	blob, err := sessionstates.Encode(sessionStates)
+	if err != nil {
+		return err
+	}
+	e.appendRow([]interface{}{blob})
+	return nil
+}
+
 // tryFillViewColumnType fill the columns type info of a view.
 // Because view's underlying table's column could change or recreate, so view's column type may change over time.
 // To avoid this situation we need to generate a logical plan and extract current column types from Schema.
@@ -86,7 +86,7 @@ func (e *ShowExec) appendTableForStatsExtended(dbName string, tbl *model.TableIn
 			statsVal = item.StringVals
 		case ast.StatsTypeCardinality:
 			statsType = "cardinality"
-			statsVal = item.StringVals
+			statsVal = fmt.Sprintf("%f", item.ScalarVals)
 		}
 		e.appendRow([]interface{}{
 			dbName,
@@ -185,6 +185,10 @@ func (e *ShowExec) appendTableForStatsHistograms(dbName, tblName, partitionName
 	for _, idx := range stableIdxsStats(statsTbl.Indices) {
 		e.histogramToRow(dbName, tblName, partitionName, idx.Info.Name.O, 1, idx.Histogram, 0)
 	}
+	// The statistics of expressions are shown like the columns named by the expressions.
+	for _, col := range stableExprsStats(statsTbl.ExprStats) {
+		e.histogramToRow(dbName, tblName, partitionName, col.Info.Name.O, 0, col.Histogram, col.AvgColSize(statsTbl.Count, false))
+	}
 }
 
 func (e *ShowExec) histogramToRow(dbName, tblName, partitionName, colName string, isIndex int, hist statistics.Histogram, avgColSize float64) {
@@ -333,6 +337,14 @@ func stableColsStats(colStats map[int64]*statistics.Column) (cols []*statistics.
 	return
 }
 
+func stableExprsStats(exprStats map[string]*statistics.Column) (cols []*statistics.Column) {
+	for _, col := range exprStats {
+		cols = append(cols, col)
+	}
+	sort.Slice(cols, func(i, j int) bool { return cols[i].Info.Name.O < cols[j].Info.Name.O })
+	return
+}
+
 func stableIdxsStats(idxStats map[int64]*statistics.Index) (idxs []*statistics.Index) {
 	for _, idx := range idxStats {
 		idxs = append(idxs, idx)
@@ -41,6 +41,7 @@ import (
 	"github.com/pingcap/tidb/plugin"
 	"github.com/pingcap/tidb/privilege"
 	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/sessionstates"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util"
@@ -162,11 +163,21 @@ func (e *SimpleExec) Next(ctx context.Context, req *chunk.Chunk) (err error) {
 		err = e.executeShutdown(x)
 	case *ast.AdminStmt:
 		err = e.executeAdmin(x)
+	case *ast.SetSessionStatesStmt:
+		err = e.executeSetSessionStates(ctx, x)
 	}
 	e.done = true
 	return err
 }
 
+func (e *SimpleExec) executeSetSessionStates(ctx context.Context, s *ast.SetSessionStatesStmt) error {
+	sessionStates, err := sessionstates.Decode(s.SessionStates)
+	if err != nil {
+		return err
+	}
+	return e.ctx.DecodeSessionStates(ctx, e.ctx, sessionStates)
+}
+
 func (e *SimpleExec) setDefaultRoleNone(s *ast.SetDefaultRoleStmt) error {
 	restrictedCtx, err := e.getSysSession()
 	if err != nil {
@@ -776,12 +787,16 @@ func (e *SimpleExec) executeCreateUser(ctx context.Context, s *ast.CreateUserStm
 	if err != nil {
 		return err
 	}
+	resourceGroup, err := e.checkResourceGroupNameOption(s.ResourceGroupNameOption)
+	if err != nil {
+		return err
+	}
 
 	sql := new(strings.Builder)
 	if s.IsCreateRole {
-		sqlexec.MustFormatSQL(sql, `INSERT INTO %n.%n (Host, User, authentication_string, plugin, Account_locked) VALUES `, mysql.SystemDB, mysql.UserTable)
+		sqlexec.MustFormatSQL(sql, `INSERT INTO %n.%n (Host, User, authentication_string, plugin, Resource_group, Account_locked) VALUES `, mysql.SystemDB, mysql.UserTable)
 	} else {
-		sqlexec.MustFormatSQL(sql, `INSERT INTO %n.%n (Host, User, authentication_string, plugin) VALUES `, mysql.SystemDB, mysql.UserTable)
+		sqlexec.MustFormatSQL(sql, `INSERT INTO %n.%n (Host, User, authentication_string, plugin, Resource_group) VALUES `, mysql.SystemDB, mysql.UserTable)
 	}
 
 	users := make([]*auth.UserIdentity, 0, len(s.Specs))
@@ -829,9 +844,9 @@ func (e *SimpleExec) executeCreateUser(ctx context.Context, s *ast.CreateUserStm
 
 		hostName := strings.ToLower(spec.User.Hostname)
 		if s.IsCreateRole {
-			sqlexec.MustFormatSQL(sql, `(%?, %?, %?, %?, %?)`, hostName, spec.User.Username, pwd, authPlugin, "Y")
+			sqlexec.MustFormatSQL(sql, `(%?, %?, %?, %?, %?, %?)`, hostName, spec.User.Username, pwd, authPlugin, resourceGroup, "Y")
 		} else {
-			sqlexec.MustFormatSQL(sql, `(%?, %?, %?, %?)`, hostName, spec.User.Username, pwd, authPlugin)
+			sqlexec.MustFormatSQL(sql, `(%?, %?, %?, %?, %?)`, hostName, spec.User.Username, pwd, authPlugin, resourceGroup)
 		}
 		users = append(users, spec.User)
 	}
@@ -879,6 +894,19 @@ func (e *SimpleExec) executeCreateUser(ctx context.Context, s *ast.CreateUserStm
 	return domain.GetDomain(e.ctx).NotifyUpdatePrivilege()
 }
 
+// checkResourceGroupNameOption checks the resource group exists and returns its
+// name in lower case, the default group is stored as an empty name.
+func (e *SimpleExec) checkResourceGroupNameOption(option *ast.ResourceGroupNameOption) (string, error) {
+	if option == nil || len(option.Value) == 0 {
+		return "", nil
+	}
+	name := model.NewCIStr(option.Value)
+	if _, ok := e.is.ResourceGroupByName(name); !ok {
+		return "", infoschema.ErrResourceGroupNotExists.GenWithStackByArgs(option.Value)
+	}
+	return name.L, nil
+}
+
 func (e *SimpleExec) executeAlterUser(ctx context.Context, s *ast.AlterUserStmt) error {
 	if s.CurrentAuth != nil {
 		user := e.ctx.GetSessionVars().User
@@ -899,6 +927,10 @@ func (e *SimpleExec) executeAlterUser(ctx context.Context, s *ast.AlterUserStmt)
 	if err != nil {
 		return err
 	}
+	resourceGroup, err := e.checkResourceGroupNameOption(s.ResourceGroupNameOption)
+	if err != nil {
+		return err
+	}
 
 	failedUsers := make([]string, 0, len(s.Specs))
 	checker := privilege.GetPrivilegeManager(e.ctx)
@@ -910,6 +942,10 @@ func (e *SimpleExec) executeAlterUser(ctx context.Context, s *ast.AlterUserStmt)
 	hasSystemUserPriv := checker.RequestDynamicVerification(activeRoles, "SYSTEM_USER", false)
 	hasRestrictedUserPriv := checker.RequestDynamicVerification(activeRoles, "RESTRICTED_USER_ADMIN", false)
 	hasSystemSchemaPriv := checker.RequestVerification(activeRoles, mysql.SystemDB, mysql.UserTable, "", mysql.UpdatePriv)
+	// Users can not escape from the limits of their resource group by themselves.
+	if s.ResourceGroupNameOption != nil && !(hasCreateUserPriv || hasSystemSchemaPriv) {
+		return plannercore.ErrSpecificAccessDenied.GenWithStackByArgs("CREATE USER")
+	}
 
 	for _, spec := range s.Specs {
 		user := e.ctx.GetSessionVars().User
@@ -988,6 +1024,16 @@ func (e *SimpleExec) executeAlterUser(ctx context.Context, s *ast.AlterUserStmt)
 				failedUsers = append(failedUsers, spec.User.String())
 			}
 		}
+
+		if s.ResourceGroupNameOption != nil {
+			_, _, err := exec.ExecRestrictedSQL(ctx, nil,
+				`UPDATE %n.%n SET Resource_group=%? WHERE Host=%? and User=%?;`,
+				mysql.SystemDB, mysql.UserTable, resourceGroup, strings.ToLower(spec.User.Hostname), spec.User.Username,
+			)
+			if err != nil {
+				failedUsers = append(failedUsers, spec.User.String())
+			}
+		}
 	}
 	if len(failedUsers) > 0 {
 		// Commit the transaction even if we returns error
@@ -1693,6 +1739,7 @@ func (e *SimpleExec) executeAdminFlushPlanCache(s *ast.AdminStmt) error {
 		// Record the timestamp. When other sessions want to use the plan cache,
 		// it will check the timestamp first to decide whether the plan cache should be flushed.
 		domain.GetDomain(e.ctx).SetExpiredTimeStamp4PC(now)
+		domain.GetDomain(e.ctx).InstancePlanCache().DeleteAll()
 	}
 	return nil
 }
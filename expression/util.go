@@ -1205,6 +1205,49 @@ func RemoveMutableConst(ctx sessionctx.Context, exprs []Expression) {
 	}
 }
 
+// CloneExprsWithCtx deep copies the expressions and binds the copies to ctx.
+// See CloneWithCtx for details.
+func CloneExprsWithCtx(ctx sessionctx.Context, exprs []Expression) []Expression {
+	cloned := make([]Expression, 0, len(exprs))
+	for _, expr := range exprs {
+		cloned = append(cloned, CloneWithCtx(ctx, expr))
+	}
+	return cloned
+}
+
+// CloneWithCtx deep copies the expression and binds the copy to ctx, so an
+// expression built in one session can be evaluated in another one. The
+// original expression isn't modified, it's used by the instance plan cache
+// to share the plans among sessions.
+func CloneWithCtx(ctx sessionctx.Context, expr Expression) Expression {
+	cloned := expr.Clone()
+	bindCtx(ctx, cloned)
+	return cloned
+}
+
+// bindCtx binds the cloned expression to ctx. The parts shared with the
+// original expression by Clone are copied before binding.
+func bindCtx(ctx sessionctx.Context, expr Expression) {
+	switch v := expr.(type) {
+	case *ScalarFunction:
+		v.Function.setCtx(ctx)
+		for _, arg := range v.GetArgs() {
+			bindCtx(ctx, arg)
+		}
+	case *Constant:
+		if v.ParamMarker != nil {
+			v.ParamMarker = &ParamMarker{ctx: ctx, order: v.ParamMarker.order}
+		}
+		if v.DeferredExpr != nil {
+			v.DeferredExpr = CloneWithCtx(ctx, v.DeferredExpr)
+		}
+	case *Column:
+		if v.VirtualExpr != nil {
+			v.VirtualExpr = CloneWithCtx(ctx, v.VirtualExpr)
+		}
+	}
+}
+
 const (
 	_   = iota
 	kib = 1 << (10 * iota)
@@ -599,3 +599,24 @@ func (m *MockExpr) CharsetAndCollation() (string, string) {
 	return "", ""
 }
 func (m *MockExpr) SetCharsetAndCollation(chs, coll string) {}
+
+func TestCloneWithCtx(t *testing.T) {
+	ctx1, ctx2 := mock.NewContext(), mock.NewContext()
+	ctx1.GetSessionVars().PreparedParams = []types.Datum{types.NewIntDatum(1)}
+	ctx2.GetSessionVars().PreparedParams = []types.Datum{types.NewIntDatum(10)}
+	param := &Constant{ParamMarker: &ParamMarker{ctx: ctx1, order: 0}, RetType: newIntFieldType()}
+	f, err := newFunctionForTest(ctx1, ast.Plus, param, newLonglong(1))
+	require.NoError(t, err)
+
+	cloned := CloneWithCtx(ctx2, f)
+	res, _, err := cloned.EvalInt(ctx2, chunk.Row{})
+	require.NoError(t, err)
+	require.Equal(t, int64(11), res)
+	require.Equal(t, ctx2, cloned.(*ScalarFunction).Function.getCtx())
+
+	// the original expression is still bound to the original context.
+	res, _, err = f.EvalInt(ctx1, chunk.Row{})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), res)
+	require.Equal(t, ctx1, f.(*ScalarFunction).Function.getCtx())
+}
@@ -59,6 +59,10 @@ type InfoSchema interface {
 	RuleBundles() []*placement.Bundle
 	// AllPlacementPolicies returns all placement policies
 	AllPlacementPolicies() []*model.PolicyInfo
+	// ResourceGroupByName is used to find the resource group.
+	ResourceGroupByName(name model.CIStr) (*model.ResourceGroupInfo, bool)
+	// AllResourceGroups returns all resource groups
+	AllResourceGroups() []*model.ResourceGroupInfo
 }
 
 type sortedTables []table.Table
@@ -101,6 +105,10 @@ type infoSchema struct {
 	policyMutex sync.RWMutex
 	policyMap   map[string]*model.PolicyInfo
 
+	// resourceGroupMap stores all resource groups.
+	resourceGroupMutex sync.RWMutex
+	resourceGroupMap   map[string]*model.ResourceGroupInfo
+
 	schemaMap map[string]*schemaTables
 
 	// sortedTablesBuckets is a slice of sortedTables, a table's bucket index is (tableID % bucketCount).
@@ -115,6 +123,7 @@ func MockInfoSchema(tbList []*model.TableInfo) InfoSchema {
 	result := &infoSchema{}
 	result.schemaMap = make(map[string]*schemaTables)
 	result.policyMap = make(map[string]*model.PolicyInfo)
+	result.resourceGroupMap = make(map[string]*model.ResourceGroupInfo)
 	result.ruleBundleMap = make(map[string]*placement.Bundle)
 	result.sortedTablesBuckets = make([]sortedTables, bucketCount)
 	dbInfo := &model.DBInfo{ID: 0, Name: model.NewCIStr("test"), Tables: tbList}
@@ -140,6 +149,7 @@ func MockInfoSchemaWithSchemaVer(tbList []*model.TableInfo, schemaVer int64) Inf
 	result := &infoSchema{}
 	result.schemaMap = make(map[string]*schemaTables)
 	result.policyMap = make(map[string]*model.PolicyInfo)
+	result.resourceGroupMap = make(map[string]*model.ResourceGroupInfo)
 	result.ruleBundleMap = make(map[string]*placement.Bundle)
 	result.sortedTablesBuckets = make([]sortedTables, bucketCount)
 	dbInfo := &model.DBInfo{ID: 0, Name: model.NewCIStr("test"), Tables: tbList}
@@ -226,6 +236,17 @@ func (is *infoSchema) PolicyByID(id int64) (val *model.PolicyInfo, ok bool) {
 	return nil, false
 }
 
+func (is *infoSchema) resourceGroupByID(id int64) (val *model.ResourceGroupInfo, ok bool) {
+	is.resourceGroupMutex.RLock()
+	defer is.resourceGroupMutex.RUnlock()
+	for _, v := range is.resourceGroupMap {
+		if v.ID == id {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
 func (is *infoSchema) SchemaByID(id int64) (val *model.DBInfo, ok bool) {
 	for _, v := range is.schemaMap {
 		if v.dbInfo.ID == id {
@@ -387,6 +408,25 @@ func (is *infoSchema) AllPlacementPolicies() []*model.PolicyInfo {
 	return policies
 }
 
+// ResourceGroupByName is used to find the resource group.
+func (is *infoSchema) ResourceGroupByName(name model.CIStr) (*model.ResourceGroupInfo, bool) {
+	is.resourceGroupMutex.RLock()
+	defer is.resourceGroupMutex.RUnlock()
+	t, r := is.resourceGroupMap[name.L]
+	return t, r
+}
+
+// AllResourceGroups returns all resource groups
+func (is *infoSchema) AllResourceGroups() []*model.ResourceGroupInfo {
+	is.resourceGroupMutex.RLock()
+	defer is.resourceGroupMutex.RUnlock()
+	groups := make([]*model.ResourceGroupInfo, 0, len(is.resourceGroupMap))
+	for _, group := range is.resourceGroupMap {
+		groups = append(groups, group)
+	}
+	return groups
+}
+
 func (is *infoSchema) BundleByName(name string) (*placement.Bundle, bool) {
 	is.ruleBundleMutex.RLock()
 	defer is.ruleBundleMutex.RUnlock()
@@ -416,6 +456,18 @@ func (is *infoSchema) deletePolicy(name string) {
 	delete(is.policyMap, name)
 }
 
+func (is *infoSchema) setResourceGroup(group *model.ResourceGroupInfo) {
+	is.resourceGroupMutex.Lock()
+	defer is.resourceGroupMutex.Unlock()
+	is.resourceGroupMap[group.Name.L] = group
+}
+
+func (is *infoSchema) deleteResourceGroup(name string) {
+	is.resourceGroupMutex.Lock()
+	defer is.resourceGroupMutex.Unlock()
+	delete(is.resourceGroupMap, name)
+}
+
 func (is *infoSchema) SetBundle(bundle *placement.Bundle) {
 	is.ruleBundleMutex.Lock()
 	defer is.ruleBundleMutex.Unlock()
@@ -496,6 +548,23 @@ func (is *LocalTemporaryTables) TableByID(id int64) (tbl table.Table, ok bool) {
 	return
 }
 
+// Count gets the count of the local temporary tables.
+func (is *LocalTemporaryTables) Count() int {
+	return len(is.idx2table)
+}
+
+// AllTables gets all the local temporary tables ordered by their IDs.
+func (is *LocalTemporaryTables) AllTables() []table.Table {
+	tables := make([]table.Table, 0, len(is.idx2table))
+	for _, tbl := range is.idx2table {
+		tables = append(tables, tbl)
+	}
+	sort.Slice(tables, func(i, j int) bool {
+		return tables[i].Meta().ID < tables[j].Meta().ID
+	})
+	return tables
+}
+
 // AddTable add a table
 func (is *LocalTemporaryTables) AddTable(db *model.DBInfo, tbl table.Table) error {
 	schemaTables := is.ensureSchema(db)
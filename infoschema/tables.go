@@ -178,6 +178,8 @@ const (
 	TableAttributes = "ATTRIBUTES"
 	// TablePlacementPolicies is the string constant of placement policies table.
 	TablePlacementPolicies = "PLACEMENT_POLICIES"
+	// TableResourceGroups is the string constant of resource groups table.
+	TableResourceGroups = "RESOURCE_GROUPS"
 )
 
 const (
@@ -276,6 +278,7 @@ var tableIDMap = map[string]int64{
 	TableAttributes:                      autoid.InformationSchemaDBID + 77,
 	TableTiDBHotRegionsHistory:           autoid.InformationSchemaDBID + 78,
 	TablePlacementPolicies:               autoid.InformationSchemaDBID + 79,
+	TableResourceGroups:                  autoid.InformationSchemaDBID + 80,
 }
 
 type columnInfo struct {
@@ -1468,6 +1471,17 @@ var tablePlacementPoliciesCols = []columnInfo{
 	{name: "LEARNERS", tp: mysql.TypeLonglong, size: 64},
 }
 
+var tableResourceGroupsCols = []columnInfo{
+	{name: "GROUP_ID", tp: mysql.TypeLonglong, size: 64, flag: mysql.NotNullFlag},
+	{name: "GROUP_NAME", tp: mysql.TypeVarchar, size: 64, flag: mysql.NotNullFlag},
+	{name: "QPS", tp: mysql.TypeLonglong, size: 64, flag: mysql.UnsignedFlag},
+	{name: "MAX_CONCURRENCY", tp: mysql.TypeLonglong, size: 64, flag: mysql.UnsignedFlag},
+	{name: "MEM_QUOTA", tp: mysql.TypeLonglong, size: 64},
+	{name: "RUNNING", tp: mysql.TypeLonglong, size: 64, comment: "The number of running statements of the group on this instance"},
+	{name: "QUEUED", tp: mysql.TypeLonglong, size: 64, comment: "The number of queued statements of the group on this instance"},
+	{name: "MEM_USAGE", tp: mysql.TypeLonglong, size: 64, comment: "The memory usage of the group on this instance"},
+}
+
 // GetShardingInfo returns a nil or description string for the sharding information of given TableInfo.
 // The returned description string may be:
 //  - "NOT_SHARDED": for tables that SHARD_ROW_ID_BITS is not specified.
@@ -1840,6 +1854,7 @@ var tableNameToColumns = map[string][]columnInfo{
 	TableDataLockWaits:                      tableDataLockWaitsCols,
 	TableAttributes:                         tableAttributesCols,
 	TablePlacementPolicies:                  tablePlacementPoliciesCols,
+	TableResourceGroups:                     tableResourceGroupsCols,
 }
 
 func createInfoSchemaTable(_ autoid.Allocators, meta *model.TableInfo) (table.Table, error) {
@@ -103,6 +103,48 @@ func TestPlacementPolicy(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestResourceGroup(t *testing.T) {
+	store, err := mockstore.NewMockStore()
+	require.NoError(t, err)
+	defer func() {
+		err := store.Close()
+		require.NoError(t, err)
+	}()
+
+	txn, err := store.Begin()
+	require.NoError(t, err)
+	m := meta.NewMeta(txn)
+
+	id, err := m.GenResourceGroupID()
+	require.NoError(t, err)
+	group := &model.ResourceGroupInfo{
+		ID:             id,
+		Name:           model.NewCIStr("rg"),
+		QPS:            100,
+		MaxConcurrency: 10,
+	}
+	require.NoError(t, m.CreateResourceGroup(group))
+	err = m.CreateResourceGroup(group)
+	require.True(t, meta.ErrResourceGroupExists.Equal(err))
+
+	group.MemQuota = 1 << 30
+	require.NoError(t, m.UpdateResourceGroup(group))
+	val, err := m.GetResourceGroup(id)
+	require.NoError(t, err)
+	require.Equal(t, group, val)
+
+	groups, err := m.ListResourceGroups()
+	require.NoError(t, err)
+	require.Equal(t, []*model.ResourceGroupInfo{group}, groups)
+
+	require.NoError(t, m.DropResourceGroup(id))
+	_, err = m.GetResourceGroup(id)
+	require.True(t, meta.ErrResourceGroupNotExists.Equal(err))
+	err = m.UpdateResourceGroup(group)
+	require.True(t, meta.ErrResourceGroupNotExists.Equal(err))
+	require.NoError(t, txn.Rollback())
+}
+
 func TestBackupAndRestoreAutoIDs(t *testing.T) {
 	store, err := mockstore.NewMockStore()
 	require.NoError(t, err)
@@ -0,0 +1,54 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Resource group metrics.
+var (
+	ResourceGroupAdmissionCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tidb",
+			Subsystem: "resource_group",
+			Name:      "admission_total",
+			Help:      "Counter of statements admitted, queued and rejected by resource groups.",
+		}, []string{LblResourceGroup, LblResult})
+
+	ResourceGroupQueueDurationHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "tidb",
+			Subsystem: "resource_group",
+			Name:      "queue_duration_seconds",
+			Help:      "Bucket histogram of the time (s) statements wait for the admission of resource groups.",
+			Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 20), // 0.5ms ~ 262s
+		}, []string{LblResourceGroup})
+
+	ResourceGroupRunningGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "tidb",
+			Subsystem: "resource_group",
+			Name:      "running_statements",
+			Help:      "Number of running statements of resource groups.",
+		}, []string{LblResourceGroup})
+)
+
+// Resource group admission results.
+const (
+	LblResourceGroup = "resource_group"
+
+	LblAdmitted = "admitted"
+	LblQueued   = "queued"
+	LblRejected = "rejected"
+)
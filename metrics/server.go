@@ -35,6 +35,14 @@ var (
 			Help:      "Counters of packet IO bytes.",
 		}, []string{LblType})
 
+	CompressedPacketIOCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tidb",
+			Subsystem: "server",
+			Name:      "compressed_packet_io_bytes",
+			Help:      "Counters of packet IO bytes of the compressed protocol, before and after compression.",
+		}, []string{LblType, LblAlgorithm, LblCompression})
+
 	QueryDurationHistogram = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Namespace: "tidb",
@@ -128,6 +136,22 @@ var (
 			Help:      "Counter of query using plan cache.",
 		}, []string{LblType})
 
+	InstancePlanCacheCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tidb",
+			Subsystem: "server",
+			Name:      "instance_plan_cache_total",
+			Help:      "Counter of the hits, misses and evictions of the instance plan cache.",
+		}, []string{LblType})
+
+	InstancePlanCacheMemUsage = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "tidb",
+			Subsystem: "server",
+			Name:      "instance_plan_cache_mem_usage",
+			Help:      "Estimated memory usage in bytes of the plans in the instance plan cache.",
+		})
+
 	ReadFromTableCacheCounter = prometheus.NewCounter(
 		prometheus.CounterOpts{
 			Namespace: "tidb",
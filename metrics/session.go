@@ -157,4 +157,6 @@ const (
 	LblVersion     = "version"
 	LblHash        = "hash"
 	LblCTEType     = "cte_type"
+	LblAlgorithm   = "algorithm"
+	LblCompression = "compression"
 )
@@ -78,3 +78,32 @@ func (n *MaxIndexNumClause) Restore(ctx *format.RestoreCtx) error {
 	}
 	return nil
 }
+
+// RecommendIndexStmt is used to recommend indexes for the workload in the statement summary,
+// or for a single statement.
+type RecommendIndexStmt struct {
+	stmtNode
+
+	// SQL is the statement to recommend indexes for. The workload in the statement summary is used if it's empty.
+	SQL string
+}
+
+// Restore implements Node interface.
+func (n *RecommendIndexStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("RECOMMEND INDEX RUN")
+	if n.SQL != "" {
+		ctx.WriteKeyWord(" FOR ")
+		ctx.WriteString(n.SQL)
+	}
+	return nil
+}
+
+// Accept implements Node Accept interface.
+func (n *RecommendIndexStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*RecommendIndexStmt)
+	return v.Leave(n)
+}
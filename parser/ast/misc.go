@@ -958,6 +958,28 @@ func (n *SetConfigStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// SetSessionStatesStmt is a statement to restore session states.
+type SetSessionStatesStmt struct {
+	stmtNode
+
+	SessionStates string
+}
+
+func (n *SetSessionStatesStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("SET SESSION_STATES ")
+	ctx.WriteString(n.SessionStates)
+	return nil
+}
+
+func (n *SetSessionStatesStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*SetSessionStatesStmt)
+	return v.Leave(n)
+}
+
 /*
 // SetCharsetStmt is a statement to assign values to character and collation variables.
 // See https://dev.mysql.com/doc/refman/5.7/en/set-statement.html
@@ -1303,6 +1325,22 @@ func (r *ResourceOption) Restore(ctx *format.RestoreCtx) error {
 	return nil
 }
 
+// ResourceGroupNameOption is the resource group a user is bound to.
+// An empty Value means the user is not bound to any resource group.
+type ResourceGroupNameOption struct {
+	Value string
+}
+
+func (r *ResourceGroupNameOption) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("RESOURCE GROUP ")
+	if r.Value == "" {
+		ctx.WriteKeyWord("DEFAULT")
+	} else {
+		ctx.WriteName(r.Value)
+	}
+	return nil
+}
+
 const (
 	PasswordExpire = iota + 1
 	PasswordExpireDefault
@@ -1344,12 +1382,13 @@ func (p *PasswordOrLockOption) Restore(ctx *format.RestoreCtx) error {
 type CreateUserStmt struct {
 	stmtNode
 
-	IsCreateRole          bool
-	IfNotExists           bool
-	Specs                 []*UserSpec
-	TLSOptions            []*TLSOption
-	ResourceOptions       []*ResourceOption
-	PasswordOrLockOptions []*PasswordOrLockOption
+	IsCreateRole            bool
+	IfNotExists             bool
+	Specs                   []*UserSpec
+	TLSOptions              []*TLSOption
+	ResourceOptions         []*ResourceOption
+	PasswordOrLockOptions   []*PasswordOrLockOption
+	ResourceGroupNameOption *ResourceGroupNameOption
 }
 
 // Restore implements Node interface.
@@ -1401,6 +1440,13 @@ func (n *CreateUserStmt) Restore(ctx *format.RestoreCtx) error {
 			return errors.Annotatef(err, "An error occurred while restore CreateUserStmt.PasswordOrLockOptions[%d]", i)
 		}
 	}
+
+	if n.ResourceGroupNameOption != nil {
+		ctx.WritePlain(" ")
+		if err := n.ResourceGroupNameOption.Restore(ctx); err != nil {
+			return errors.Annotate(err, "An error occurred while restore CreateUserStmt.ResourceGroupNameOption")
+		}
+	}
 	return nil
 }
 
@@ -1430,12 +1476,13 @@ func (n *CreateUserStmt) SecureText() string {
 type AlterUserStmt struct {
 	stmtNode
 
-	IfExists              bool
-	CurrentAuth           *AuthOption
-	Specs                 []*UserSpec
-	TLSOptions            []*TLSOption
-	ResourceOptions       []*ResourceOption
-	PasswordOrLockOptions []*PasswordOrLockOption
+	IfExists                bool
+	CurrentAuth             *AuthOption
+	Specs                   []*UserSpec
+	TLSOptions              []*TLSOption
+	ResourceOptions         []*ResourceOption
+	PasswordOrLockOptions   []*PasswordOrLockOption
+	ResourceGroupNameOption *ResourceGroupNameOption
 }
 
 // Restore implements Node interface.
@@ -1490,6 +1537,13 @@ func (n *AlterUserStmt) Restore(ctx *format.RestoreCtx) error {
 			return errors.Annotatef(err, "An error occurred while restore AlterUserStmt.PasswordOrLockOptions[%d]", i)
 		}
 	}
+
+	if n.ResourceGroupNameOption != nil {
+		ctx.WritePlain(" ")
+		if err := n.ResourceGroupNameOption.Restore(ctx); err != nil {
+			return errors.Annotate(err, "An error occurred while restore AlterUserStmt.ResourceGroupNameOption")
+		}
+	}
 	return nil
 }
 
@@ -1920,6 +1974,8 @@ const (
 	AdminResetTelemetryID
 	AdminReloadStatistics
 	AdminFlushPlanCache
+	AdminShowPlanRegressions
+	AdminRejectPlanRegression
 )
 
 // HandleRange represents a range where handle value >= Begin and < End.
@@ -2007,6 +2063,7 @@ type AdminStmt struct {
 	Plugins        []string
 	Where          ExprNode
 	StatementScope StatementScope
+	SQLDigest      string
 }
 
 // Restore implements Node interface.
@@ -2152,6 +2209,11 @@ func (n *AdminStmt) Restore(ctx *format.RestoreCtx) error {
 		} else if n.StatementScope == StatementScopeGlobal {
 			ctx.WriteKeyWord("FLUSH GLOBAL PLAN_CACHE")
 		}
+	case AdminShowPlanRegressions:
+		ctx.WriteKeyWord("SHOW PLAN REGRESSIONS")
+	case AdminRejectPlanRegression:
+		ctx.WriteKeyWord("REJECT PLAN REGRESSION ")
+		ctx.WriteString(n.SQLDigest)
 	default:
 		return errors.New("Unsupported AdminStmt type")
 	}
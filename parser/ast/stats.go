@@ -42,6 +42,8 @@ type AnalyzeTableStmt struct {
 	// ColumnNames indicate the columns whose statistics need to be collected.
 	ColumnNames  []model.CIStr
 	ColumnChoice model.ColumnChoice
+	// Expressions indicate the expressions whose statistics need to be collected.
+	Expressions []ExprNode
 }
 
 // AnalyzeOptType is the type for analyze options.
@@ -147,6 +149,19 @@ func (n *AnalyzeTableStmt) Restore(ctx *format.RestoreCtx) error {
 			ctx.WriteName(columnName.O)
 		}
 	}
+	if len(n.Expressions) > 0 {
+		ctx.WriteKeyWord(" EXPRESSIONS ")
+		ctx.WritePlain("(")
+		for i, expr := range n.Expressions {
+			if i != 0 {
+				ctx.WritePlain(", ")
+			}
+			if err := expr.Restore(ctx); err != nil {
+				return errors.Annotatef(err, "An error occurred while restore AnalyzeTableStmt.Expressions[%d]", i)
+			}
+		}
+		ctx.WritePlain(")")
+	}
 	if n.IndexFlag {
 		ctx.WriteKeyWord(" INDEX")
 	}
@@ -185,6 +200,13 @@ func (n *AnalyzeTableStmt) Accept(v Visitor) (Node, bool) {
 		}
 		n.TableNames[i] = node.(*TableName)
 	}
+	for i, val := range n.Expressions {
+		node, ok := val.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Expressions[i] = node.(ExprNode)
+	}
 	return v.Leave(n)
 }
 
@@ -328,6 +328,7 @@ var tokenMap = map[string]int{
 	"EXISTS":                   exists,
 	"EXPANSION":                expansion,
 	"EXPIRE":                   expire,
+	"EXPRESSIONS":              expressions,
 	"EXPLAIN":                  explain,
 	"EXPR_PUSHDOWN_BLACKLIST":  exprPushdownBlacklist,
 	"EXTENDED":                 extended,
@@ -373,6 +374,7 @@ var tokenMap = map[string]int{
 	"HOUR_MINUTE":              hourMinute,
 	"HOUR_SECOND":              hourSecond,
 	"HOUR":                     hour,
+	"HYPO":                     hypo,
 	"IDENTIFIED":               identified,
 	"IF":                       ifKwd,
 	"IGNORE":                   ignore,
@@ -451,6 +453,7 @@ var tokenMap = map[string]int{
 	"LOW_PRIORITY":             lowPriority,
 	"MASTER":                   master,
 	"MATCH":                    match,
+	"MAX_CONCURRENCY":          maxConcurrency,
 	"MAX_CONNECTIONS_PER_HOUR": maxConnectionsPerHour,
 	"MAX_IDXNUM":               max_idxnum,
 	"MAX_MINUTES":              max_minutes,
@@ -465,6 +468,7 @@ var tokenMap = map[string]int{
 	"MEDIUMINT":                mediumIntType,
 	"MEDIUMTEXT":               mediumtextType,
 	"MEMORY":                   memory,
+	"MEM_QUOTA":                memQuota,
 	"MERGE":                    merge,
 	"MICROSECOND":              microsecond,
 	"MIN_ROWS":                 minRows,
@@ -481,6 +485,7 @@ var tokenMap = map[string]int{
 	"NATIONAL":                 national,
 	"NATURAL":                  natural,
 	"NCHAR":                    ncharType,
+	"NDV":                      ndv,
 	"NEVER":                    never,
 	"NEXT_ROW_ID":              next_row_id,
 	"NEXT":                     next,
@@ -556,6 +561,7 @@ var tokenMap = map[string]int{
 	"PROXY":                    proxy,
 	"PUMP":                     pump,
 	"PURGE":                    purge,
+	"QPS":                      qps,
 	"QUARTER":                  quarter,
 	"QUERIES":                  queries,
 	"QUERY":                    query,
@@ -566,6 +572,7 @@ var tokenMap = map[string]int{
 	"REAL":                     realType,
 	"REBUILD":                  rebuild,
 	"RECENT":                   recent,
+	"RECOMMEND":                recommend,
 	"RECOVER":                  recover,
 	"RECURSIVE":                recursive,
 	"REDUNDANT":                redundant,
@@ -573,6 +580,9 @@ var tokenMap = map[string]int{
 	"REGEXP":                   regexpKwd,
 	"REGION":                   region,
 	"REGIONS":                  regions,
+	"REGRESSION":               regression,
+	"REGRESSIONS":              regressions,
+	"REJECT":                   reject,
 	"RELEASE":                  release,
 	"RELOAD":                   reload,
 	"REMOVE":                   remove,
@@ -589,6 +599,7 @@ var tokenMap = map[string]int{
 	"REQUIRE":                  require,
 	"REQUIRED":                 required,
 	"RESET":                    reset,
+	"RESOURCE":                 resource,
 	"RESPECT":                  respect,
 	"RESTART":                  restart,
 	"RESTORE":                  restore,
@@ -607,6 +618,7 @@ var tokenMap = map[string]int{
 	"ROWS":                     rows,
 	"RTREE":                    rtree,
 	"RESUME":                   resume,
+	"RUN":                      run,
 	"RUNNING":                  running,
 	"S3":                       s3,
 	"SAMPLES":                  samples,
@@ -628,6 +640,7 @@ var tokenMap = map[string]int{
 	"SERIAL":                   serial,
 	"SERIALIZABLE":             serializable,
 	"SESSION":                  session,
+	"SESSION_STATES":           sessionStates,
 	"SET":                      set,
 	"SETVAL":                   setval,
 	"SHARD_ROW_ID_BITS":        shardRowIDBits,
@@ -1037,6 +1037,8 @@ func (t IndexType) String() string {
 		return "HASH"
 	case IndexTypeRtree:
 		return "RTREE"
+	case IndexTypeHypo:
+		return "HYPO"
 	default:
 		return ""
 	}
@@ -1048,6 +1050,7 @@ const (
 	IndexTypeBtree
 	IndexTypeHash
 	IndexTypeRtree
+	IndexTypeHypo
 )
 
 // IndexInfo provides meta data describing a DB index.
@@ -1313,6 +1316,37 @@ func (p *PlacementSettings) Clone() *PlacementSettings {
 	return &cloned
 }
 
+// ResourceGroupInfo is the struct to store the resource group.
+// A zero limit means the resource is unlimited.
+type ResourceGroupInfo struct {
+	ID             int64       `json:"id"`
+	Name           CIStr       `json:"name"`
+	QPS            uint64      `json:"qps"`
+	MaxConcurrency uint64      `json:"max_concurrency"`
+	MemQuota       int64       `json:"mem_quota"`
+	State          SchemaState `json:"state"`
+}
+
+// Clone clones the ResourceGroupInfo.
+func (r *ResourceGroupInfo) Clone() *ResourceGroupInfo {
+	cloned := *r
+	return &cloned
+}
+
+func (r *ResourceGroupInfo) String() string {
+	sb := new(strings.Builder)
+	if r.QPS > 0 {
+		writeSettingItemToBuilder(sb, fmt.Sprintf("QPS=%d", r.QPS))
+	}
+	if r.MaxConcurrency > 0 {
+		writeSettingItemToBuilder(sb, fmt.Sprintf("MAX_CONCURRENCY=%d", r.MaxConcurrency))
+	}
+	if r.MemQuota > 0 {
+		writeSettingItemToBuilder(sb, fmt.Sprintf("MEM_QUOTA=%d", r.MemQuota))
+	}
+	return sb.String()
+}
+
 type StatsOptions struct {
 	*StatsWindowSettings
 	AutoRecalc   bool         `json:"auto_recalc"`
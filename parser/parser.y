@@ -395,6 +395,7 @@ import (
 	execute               "EXECUTE"
 	expansion             "EXPANSION"
 	expire                "EXPIRE"
+	expressions           "EXPRESSIONS"
 	extended              "EXTENDED"
 	faultsSym             "FAULTS"
 	fields                "FIELDS"
@@ -415,6 +416,7 @@ import (
 	history               "HISTORY"
 	hosts                 "HOSTS"
 	hour                  "HOUR"
+	hypo                  "HYPO"
 	identified            "IDENTIFIED"
 	identSQLErrors        "ERRORS"
 	importKwd             "IMPORT"
@@ -518,6 +520,9 @@ import (
 	rebuild               "REBUILD"
 	recover               "RECOVER"
 	redundant             "REDUNDANT"
+	regression            "REGRESSION"
+	regressions           "REGRESSIONS"
+	reject                "REJECT"
 	reload                "RELOAD"
 	remove                "REMOVE"
 	reorganize            "REORGANIZE"
@@ -665,6 +670,9 @@ import (
 	learners              "LEARNERS"
 	min                   "MIN"
 	max                   "MAX"
+	maxConcurrency        "MAX_CONCURRENCY"
+	memQuota              "MEM_QUOTA"
+	ndv                   "NDV"
 	now                   "NOW"
 	optRuleBlacklist      "OPT_RULE_BLACKLIST"
 	placement             "PLACEMENT"
@@ -673,11 +681,16 @@ import (
 	position              "POSITION"
 	predicate             "PREDICATE"
 	primaryRegion         "PRIMARY_REGION"
+	qps                   "QPS"
 	recent                "RECENT"
+	recommend             "RECOMMEND"
 	replayer              "REPLAYER"
+	resource              "RESOURCE"
+	run                   "RUN"
 	running               "RUNNING"
 	s3                    "S3"
 	schedule              "SCHEDULE"
+	sessionStates         "SESSION_STATES"
 	staleness             "STALENESS"
 	std                   "STD"
 	stddev                "STDDEV"
@@ -851,6 +864,7 @@ import (
 	AlterImportStmt            "ALTER IMPORT statement"
 	AlterInstanceStmt          "Alter instance statement"
 	AlterPolicyStmt            "Alter Placement Policy statement"
+	AlterResourceGroupStmt     "ALTER RESOURCE GROUP statement"
 	AlterSequenceStmt          "Alter sequence statement"
 	AnalyzeTableStmt           "Analyze table statement"
 	BeginTransactionStmt       "BEGIN TRANSACTION statement"
@@ -866,6 +880,7 @@ import (
 	CreateImportStmt           "CREATE IMPORT statement"
 	CreateBindingStmt          "CREATE BINDING  statement"
 	CreatePolicyStmt           "CREATE PLACEMENT POLICY statement"
+	CreateResourceGroupStmt    "CREATE RESOURCE GROUP statement"
 	CreateSequenceStmt         "CREATE SEQUENCE statement"
 	CreateStatisticsStmt       "CREATE STATISTICS statement"
 	DoStmt                     "Do statement"
@@ -881,6 +896,7 @@ import (
 	DropViewStmt               "DROP VIEW statement"
 	DropBindingStmt            "DROP BINDING  statement"
 	DropPolicyStmt             "DROP PLACEMENT POLICY statement"
+	DropResourceGroupStmt      "DROP RESOURCE GROUP statement"
 	DeallocateStmt             "Deallocate prepared statement"
 	DeleteFromStmt             "DELETE FROM statement"
 	DeleteWithoutUsingStmt     "Normal DELETE statement"
@@ -897,6 +913,7 @@ import (
 	InsertIntoStmt             "INSERT INTO statement"
 	CallStmt                   "CALL statement"
 	IndexAdviseStmt            "INDEX ADVISE statement"
+	RecommendIndexStmt         "RECOMMEND INDEX statement"
 	KillStmt                   "Kill statement"
 	LoadDataStmt               "Load data statement"
 	LoadStatsStmt              "Load statistic statement"
@@ -1318,6 +1335,9 @@ import (
 	PlacementPolicyOption                  "Anonymous or placement policy option"
 	DirectPlacementOption                  "Subset of anonymous or direct placement option"
 	PlacementOptionList                    "Anomymous or direct placement option list"
+	ResourceGroupOption                    "Resource group option"
+	ResourceGroupOptionList                "Resource group option list"
+	ResourceGroupNameOption                "Optional resource group option for create user statement"
 	AttributesOpt                          "Attributes options"
 	AllColumnsOrPredicateColumnsOpt        "all columns or predicate columns option"
 	StatsOptionsOpt                        "Stats options"
@@ -1381,6 +1401,7 @@ import (
 	ColumnFormat                    "Column format"
 	DBName                          "Database Name"
 	PolicyName                      "Placement Policy Name"
+	ResourceGroupName               "Resource Group Name"
 	ExplainFormatType               "explain format type"
 	FieldAsName                     "Field alias name"
 	FieldAsNameOpt                  "Field alias name opt"
@@ -2639,6 +2660,13 @@ AnalyzeTableStmt:
 			ColumnChoice:   model.ColumnList,
 			AnalyzeOpts:    $8.([]ast.AnalyzeOpt)}
 	}
+|	"ANALYZE" "TABLE" TableName "EXPRESSIONS" '(' ExpressionList ')' AnalyzeOptionListOpt
+	{
+		$$ = &ast.AnalyzeTableStmt{
+			TableNames:  []*ast.TableName{$3.(*ast.TableName)},
+			Expressions: $6.([]ast.ExprNode),
+			AnalyzeOpts: $8.([]ast.AnalyzeOpt)}
+	}
 
 AllColumnsOrPredicateColumnsOpt:
 	/* empty */
@@ -3440,6 +3468,10 @@ StatsType:
 	{
 		$$ = ast.StatsTypeCardinality
 	}
+|	"NDV"
+	{
+		$$ = ast.StatsTypeCardinality
+	}
 |	"DEPENDENCY"
 	{
 		$$ = ast.StatsTypeDependency
@@ -3678,6 +3710,9 @@ DBName:
 PolicyName:
 	Identifier
 
+ResourceGroupName:
+	Identifier
+
 DatabaseOption:
 	DefaultKwdOpt CharsetKw EqOpt CharsetName
 	{
@@ -5754,6 +5789,10 @@ IndexTypeName:
 	{
 		$$ = model.IndexTypeRtree
 	}
+|	"HYPO"
+	{
+		$$ = model.IndexTypeHypo
+	}
 
 IndexInvisible:
 	"VISIBLE"
@@ -5987,6 +6026,10 @@ UnReservedKeyword:
 |	"X509"
 |	"NEVER"
 |	"EXPIRE"
+|	"EXPRESSIONS"
+|	"REGRESSION"
+|	"REGRESSIONS"
+|	"REJECT"
 |	"ACCOUNT"
 |	"INCREMENTAL"
 |	"CPU"
@@ -6021,6 +6064,7 @@ UnReservedKeyword:
 |	"VALIDATION"
 |	"WITHOUT"
 |	"RTREE"
+|	"HYPO"
 |	"EXCHANGE"
 |	"COLUMN_FORMAT"
 |	"REPAIR"
@@ -6175,9 +6219,16 @@ NotKeywordToken:
 |	"INTERNAL"
 |	"MIN"
 |	"MAX"
+|	"MAX_CONCURRENCY"
+|	"MEM_QUOTA"
+|	"NDV"
 |	"NOW"
+|	"QPS"
 |	"RECENT"
+|	"RECOMMEND"
 |	"REPLAYER"
+|	"RESOURCE"
+|	"RUN"
 |	"RUNNING"
 |	"PLACEMENT"
 |	"PLAN"
@@ -6185,6 +6236,7 @@ NotKeywordToken:
 |	"POSITION"
 |	"PREDICATE"
 |	"S3"
+|	"SESSION_STATES"
 |	"STRICT"
 |	"SUBDATE"
 |	"SUBSTRING"
@@ -9636,6 +9688,10 @@ SetStmt:
 	{
 		$$ = &ast.SetConfigStmt{Instance: $3, Name: $4, Value: $6}
 	}
+|	"SET" "SESSION_STATES" stringLit
+	{
+		$$ = &ast.SetSessionStatesStmt{SessionStates: $3}
+	}
 
 SetRoleStmt:
 	"SET" "ROLE" SetRoleOpt
@@ -10212,6 +10268,19 @@ AdminStmt:
 			StatementScope: $3.(ast.StatementScope),
 		}
 	}
+|	"ADMIN" "SHOW" "PLAN" "REGRESSIONS"
+	{
+		$$ = &ast.AdminStmt{
+			Tp: ast.AdminShowPlanRegressions,
+		}
+	}
+|	"ADMIN" "REJECT" "PLAN" "REGRESSION" stringLit
+	{
+		$$ = &ast.AdminStmt{
+			Tp:        ast.AdminRejectPlanRegression,
+			SQLDigest: $5,
+		}
+	}
 
 AdminShowSlow:
 	"RECENT" NUM
@@ -10438,6 +10507,12 @@ ShowStmt:
 			Tp: ast.ShowBuiltins,
 		}
 	}
+|	"SHOW" "SESSION_STATES"
+	{
+		$$ = &ast.ShowStmt{
+			Tp: ast.ShowSessionStates,
+		}
+	}
 |	"SHOW" "PLACEMENT" "FOR" ShowPlacementTarget
 	{
 		$$ = $4.(*ast.ShowStmt)
@@ -10982,6 +11057,7 @@ Statement:
 |	AlterInstanceStmt
 |	AlterSequenceStmt
 |	AlterPolicyStmt
+|	AlterResourceGroupStmt
 |	AnalyzeTableStmt
 |	BeginTransactionStmt
 |	BinlogStmt
@@ -11001,6 +11077,7 @@ Statement:
 |	CreateRoleStmt
 |	CreateBindingStmt
 |	CreatePolicyStmt
+|	CreateResourceGroupStmt
 |	CreateSequenceStmt
 |	CreateStatisticsStmt
 |	DoStmt
@@ -11009,6 +11086,7 @@ Statement:
 |	DropIndexStmt
 |	DropTableStmt
 |	DropPolicyStmt
+|	DropResourceGroupStmt
 |	DropSequenceStmt
 |	DropViewStmt
 |	DropUserStmt
@@ -11030,6 +11108,7 @@ Statement:
 |	PlanReplayerStmt
 |	PreparedStmt
 |	PurgeImportStmt
+|	RecommendIndexStmt
 |	RollbackStmt
 |	RenameTableStmt
 |	RenameUserStmt
@@ -12240,7 +12319,7 @@ CommaOpt:
  *  https://dev.mysql.com/doc/refman/5.7/en/account-management-sql.html
  ************************************************************************************/
 CreateUserStmt:
-	"CREATE" "USER" IfNotExists UserSpecList RequireClauseOpt ConnectionOptions PasswordOrLockOptions
+	"CREATE" "USER" IfNotExists UserSpecList RequireClauseOpt ConnectionOptions PasswordOrLockOptions ResourceGroupNameOption
 	{
 		// See https://dev.mysql.com/doc/refman/5.7/en/create-user.html
 		$$ = &ast.CreateUserStmt{
@@ -12251,6 +12330,9 @@ CreateUserStmt:
 			ResourceOptions:       $6.([]*ast.ResourceOption),
 			PasswordOrLockOptions: $7.([]*ast.PasswordOrLockOption),
 		}
+		if $8 != nil {
+			$$.(*ast.CreateUserStmt).ResourceGroupNameOption = $8.(*ast.ResourceGroupNameOption)
+		}
 	}
 
 CreateRoleStmt:
@@ -12266,7 +12348,7 @@ CreateRoleStmt:
 
 /* See http://dev.mysql.com/doc/refman/5.7/en/alter-user.html */
 AlterUserStmt:
-	"ALTER" "USER" IfExists UserSpecList RequireClauseOpt ConnectionOptions PasswordOrLockOptions
+	"ALTER" "USER" IfExists UserSpecList RequireClauseOpt ConnectionOptions PasswordOrLockOptions ResourceGroupNameOption
 	{
 		$$ = &ast.AlterUserStmt{
 			IfExists:              $3.(bool),
@@ -12275,6 +12357,9 @@ AlterUserStmt:
 			ResourceOptions:       $6.([]*ast.ResourceOption),
 			PasswordOrLockOptions: $7.([]*ast.PasswordOrLockOption),
 		}
+		if $8 != nil {
+			$$.(*ast.AlterUserStmt).ResourceGroupNameOption = $8.(*ast.ResourceGroupNameOption)
+		}
 	}
 |	"ALTER" "USER" IfExists "USER" '(' ')' "IDENTIFIED" "BY" AuthString
 	{
@@ -12288,6 +12373,19 @@ AlterUserStmt:
 		}
 	}
 
+ResourceGroupNameOption:
+	{
+		$$ = nil
+	}
+|	"RESOURCE" "GROUP" ResourceGroupName
+	{
+		$$ = &ast.ResourceGroupNameOption{Value: $3}
+	}
+|	"RESOURCE" "GROUP" "DEFAULT"
+	{
+		$$ = &ast.ResourceGroupNameOption{Value: ""}
+	}
+
 /* See https://dev.mysql.com/doc/refman/8.0/en/alter-instance.html */
 AlterInstanceStmt:
 	"ALTER" "INSTANCE" InstanceOption
@@ -13497,6 +13595,72 @@ AlterPolicyStmt:
 		}
 	}
 
+/********************************************************************************************
+ *
+ *  Resource Group Statements
+ *
+ *  Example:
+ *	CREATE RESOURCE GROUP [IF NOT EXISTS] rg [QPS [=] qps] [MAX_CONCURRENCY [=] n] [MEM_QUOTA [=] bytes]
+ *	ALTER RESOURCE GROUP [IF EXISTS] rg [QPS [=] qps] [MAX_CONCURRENCY [=] n] [MEM_QUOTA [=] bytes]
+ *	DROP RESOURCE GROUP [IF EXISTS] rg
+ ********************************************************************************************/
+CreateResourceGroupStmt:
+	"CREATE" "RESOURCE" "GROUP" IfNotExists ResourceGroupName ResourceGroupOptionList
+	{
+		$$ = &ast.CreateResourceGroupStmt{
+			IfNotExists:             $4.(bool),
+			ResourceGroupName:       model.NewCIStr($5),
+			ResourceGroupOptionList: $6.([]*ast.ResourceGroupOption),
+		}
+	}
+
+AlterResourceGroupStmt:
+	"ALTER" "RESOURCE" "GROUP" IfExists ResourceGroupName ResourceGroupOptionList
+	{
+		$$ = &ast.AlterResourceGroupStmt{
+			IfExists:                $4.(bool),
+			ResourceGroupName:       model.NewCIStr($5),
+			ResourceGroupOptionList: $6.([]*ast.ResourceGroupOption),
+		}
+	}
+
+DropResourceGroupStmt:
+	"DROP" "RESOURCE" "GROUP" IfExists ResourceGroupName
+	{
+		$$ = &ast.DropResourceGroupStmt{
+			IfExists:          $4.(bool),
+			ResourceGroupName: model.NewCIStr($5),
+		}
+	}
+
+ResourceGroupOptionList:
+	ResourceGroupOption
+	{
+		$$ = []*ast.ResourceGroupOption{$1.(*ast.ResourceGroupOption)}
+	}
+|	ResourceGroupOptionList ResourceGroupOption
+	{
+		$$ = append($1.([]*ast.ResourceGroupOption), $2.(*ast.ResourceGroupOption))
+	}
+|	ResourceGroupOptionList ',' ResourceGroupOption
+	{
+		$$ = append($1.([]*ast.ResourceGroupOption), $3.(*ast.ResourceGroupOption))
+	}
+
+ResourceGroupOption:
+	"QPS" EqOpt LengthNum
+	{
+		$$ = &ast.ResourceGroupOption{Tp: ast.ResourceGroupOptionQPS, UintValue: $3.(uint64)}
+	}
+|	"MAX_CONCURRENCY" EqOpt LengthNum
+	{
+		$$ = &ast.ResourceGroupOption{Tp: ast.ResourceGroupOptionMaxConcurrency, UintValue: $3.(uint64)}
+	}
+|	"MEM_QUOTA" EqOpt LengthNum
+	{
+		$$ = &ast.ResourceGroupOption{Tp: ast.ResourceGroupOptionMemQuota, UintValue: $3.(uint64)}
+	}
+
 /********************************************************************************************
  *
  *  Create Sequence Statement
@@ -13717,6 +13881,21 @@ IndexAdviseStmt:
 		$$ = x
 	}
 
+/********************************************************************
+ * Index Recommendation Statement
+ *
+ * RECOMMEND INDEX RUN [FOR 'sql']
+ *******************************************************************/
+RecommendIndexStmt:
+	"RECOMMEND" "INDEX" "RUN"
+	{
+		$$ = &ast.RecommendIndexStmt{}
+	}
+|	"RECOMMEND" "INDEX" "RUN" "FOR" stringLit
+	{
+		$$ = &ast.RecommendIndexStmt{SQL: $5}
+	}
+
 MaxMinutesOpt:
 	{
 		$$ = uint64(ast.UnspecifiedSize)
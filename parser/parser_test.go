@@ -807,6 +807,10 @@ func TestDMLStmt(t *testing.T) {
 		{"admin reload bindings", true, "ADMIN RELOAD BINDINGS"},
 		{"admin show telemetry", true, "ADMIN SHOW TELEMETRY"},
 		{"admin reset telemetry_id", true, "ADMIN RESET TELEMETRY_ID"},
+		{"admin show plan regressions", true, "ADMIN SHOW PLAN REGRESSIONS"},
+		{"admin reject plan regression 'e5796985ccafe2f71126ed6c0ac939ffa015a8c0744a24b7aee6d587103fd2f7'", true, "ADMIN REJECT PLAN REGRESSION 'e5796985ccafe2f71126ed6c0ac939ffa015a8c0744a24b7aee6d587103fd2f7'"},
+		{"admin reject plan regression", false, ""},
+		{"admin show plan regression", false, ""},
 		// This case would be removed once TiDB PR to remove ADMIN RELOAD STATISTICS is merged.
 		{"admin reload statistics", true, "ADMIN RELOAD STATS_EXTENDED"},
 		{"admin reload stats_extended", true, "ADMIN RELOAD STATS_EXTENDED"},
@@ -1027,6 +1031,13 @@ AAAAAAAAAAAA5gm5Mg==
 		{"SHOW PLACEMENT LABELS", true, "SHOW PLACEMENT LABELS"},
 		{"SHOW PLACEMENT LABELS LIKE '%zone%'", true, "SHOW PLACEMENT LABELS LIKE _UTF8MB4'%zone%'"},
 		{"SHOW PLACEMENT LABELS WHERE label='l123'", true, "SHOW PLACEMENT LABELS WHERE `label`=_UTF8MB4'l123'"},
+
+		// for session states
+		{"SHOW SESSION_STATES", true, "SHOW SESSION_STATES"},
+		{"SET SESSION_STATES '{\"version\":1}'", true, "SET SESSION_STATES '{\"version\":1}'"},
+		{"SET SESSION_STATES 'it''s'", true, "SET SESSION_STATES 'it''s'"},
+		{"SET SESSION_STATES 1", false, ""},
+		{"create table session_states (a int)", true, "CREATE TABLE `session_states` (`a` INT)"},
 	}
 	RunTest(t, table, false)
 }
@@ -3080,6 +3091,8 @@ func TestDDL(t *testing.T) {
 		{"CREATE UNIQUE INDEX ident ON d_n.t_n ( ident , ident ASC ) TYPE BTREE", true, "CREATE UNIQUE INDEX `ident` ON `d_n`.`t_n` (`ident`, `ident`) USING BTREE"},
 		{"CREATE UNIQUE INDEX ident ON d_n.t_n ( ident , ident ASC ) TYPE HASH", true, "CREATE UNIQUE INDEX `ident` ON `d_n`.`t_n` (`ident`, `ident`) USING HASH"},
 		{"CREATE UNIQUE INDEX ident ON d_n.t_n ( ident , ident ASC ) TYPE RTREE", true, "CREATE UNIQUE INDEX `ident` ON `d_n`.`t_n` (`ident`, `ident`) USING RTREE"},
+		{"CREATE INDEX idx ON t (a, b) TYPE HYPO", true, "CREATE INDEX `idx` ON `t` (`a`, `b`) USING HYPO"},
+		{"CREATE INDEX idx USING HYPO ON t (a)", true, "CREATE INDEX `idx` ON `t` (`a`) USING HYPO"},
 		{"CREATE UNIQUE INDEX ident TYPE BTREE ON d_n.t_n ( ident , ident ASC )", true, "CREATE UNIQUE INDEX `ident` ON `d_n`.`t_n` (`ident`, `ident`) USING BTREE"},
 		{"CREATE UNIQUE INDEX ident USING BTREE ON d_n.t_n ( ident , ident ASC )", true, "CREATE UNIQUE INDEX `ident` ON `d_n`.`t_n` (`ident`, `ident`) USING BTREE"},
 		{"CREATE SPATIAL INDEX idx ON t (a)", true, "CREATE SPATIAL INDEX `idx` ON `t` (`a`)"},
@@ -3520,6 +3533,17 @@ func TestDDL(t *testing.T) {
 		{"drop placement policy x, y", false, ""},
 		{"drop placement policy if exists x", true, "DROP PLACEMENT POLICY IF EXISTS `x`"},
 		{"drop placement policy if exists x, y", false, ""},
+
+		// for resource group
+		{"create resource group rg qps = 100, max_concurrency 10 mem_quota=1024", true, "CREATE RESOURCE GROUP `rg` QPS = 100 MAX_CONCURRENCY = 10 MEM_QUOTA = 1024"},
+		{"create resource group if not exists rg qps 100", true, "CREATE RESOURCE GROUP IF NOT EXISTS `rg` QPS = 100"},
+		{"create resource group rg", false, ""},
+		{"create resource group rg qps = 'a'", false, ""},
+		{"alter resource group rg max_concurrency = 20", true, "ALTER RESOURCE GROUP `rg` MAX_CONCURRENCY = 20"},
+		{"alter resource group if exists rg mem_quota = 0", true, "ALTER RESOURCE GROUP IF EXISTS `rg` MEM_QUOTA = 0"},
+		{"drop resource group rg", true, "DROP RESOURCE GROUP `rg`"},
+		{"drop resource group if exists rg", true, "DROP RESOURCE GROUP IF EXISTS `rg`"},
+		{"drop resource group rg, rg2", false, ""},
 		// for show create placement policy
 		{"show create placement policy x", true, "SHOW CREATE PLACEMENT POLICY `x`"},
 		{"show create placement policy if exists x", false, ""},
@@ -4294,6 +4318,9 @@ func TestPrivilege(t *testing.T) {
 		{`ALTER USER 'root'@'localhost' IDENTIFIED BY 'new-password', 'root'@'127.0.0.1' IDENTIFIED BY PASSWORD 'hashstring'`, true, "ALTER USER `root`@`localhost` IDENTIFIED BY 'new-password', `root`@`127.0.0.1` IDENTIFIED WITH 'mysql_native_password' AS 'hashstring'"},
 		{`ALTER USER USER() IDENTIFIED BY 'new-password'`, true, "ALTER USER USER() IDENTIFIED BY 'new-password'"},
 		{`ALTER USER IF EXISTS USER() IDENTIFIED BY 'new-password'`, true, "ALTER USER IF EXISTS USER() IDENTIFIED BY 'new-password'"},
+		{"create user 'u'@'%' resource group rg", true, "CREATE USER `u`@`%` RESOURCE GROUP `rg`"},
+		{"alter user 'u'@'%' resource group rg", true, "ALTER USER `u`@`%` RESOURCE GROUP `rg`"},
+		{"alter user 'u'@'%' resource group default", true, "ALTER USER `u`@`%` RESOURCE GROUP DEFAULT"},
 		{"alter user 'test@localhost' password expire;", true, "ALTER USER `test@localhost`@`%` PASSWORD EXPIRE"},
 		{"alter user 'test@localhost' password expire never;", true, "ALTER USER `test@localhost`@`%` PASSWORD EXPIRE NEVER"},
 		{"alter user 'test@localhost' password expire default;", true, "ALTER USER `test@localhost`@`%` PASSWORD EXPIRE DEFAULT"},
@@ -5290,6 +5317,10 @@ func TestAnalyze(t *testing.T) {
 		{"analyze table t index a predicate columns", false, ""},
 		{"analyze table t with 10 samplerate", true, "ANALYZE TABLE `t` WITH 10 SAMPLERATE"},
 		{"analyze table t with 0.1 samplerate", true, "ANALYZE TABLE `t` WITH 0.1 SAMPLERATE"},
+		{"analyze table t expressions (lower(c1))", true, "ANALYZE TABLE `t` EXPRESSIONS (LOWER(`c1`))"},
+		{"analyze table t expressions (c1->>'$.a', c1 + c2) with 4 topn", true, "ANALYZE TABLE `t` EXPRESSIONS (JSON_UNQUOTE(JSON_EXTRACT(`c1`, _UTF8MB4'$.a')), `c1`+`c2`) WITH 4 TOPN"},
+		{"analyze table t expressions ()", false, ""},
+		{"analyze table t1, t2 expressions (lower(c1))", false, ""},
 	}
 	RunTest(t, table, false)
 }
@@ -6161,6 +6192,17 @@ func TestIndexAdviseStmt(t *testing.T) {
 	RunTest(t, table, false)
 }
 
+func TestRecommendIndexStmt(t *testing.T) {
+	table := []testCase{
+		{"RECOMMEND INDEX RUN", true, "RECOMMEND INDEX RUN"},
+		{"recommend index run for 'select * from t where a = 1'", true, "RECOMMEND INDEX RUN FOR 'select * from t where a = 1'"},
+		{"RECOMMEND INDEX", false, ""},
+		{"RECOMMEND INDEX RUN FOR select 1", false, ""},
+		{"create table recommend (run int)", true, "CREATE TABLE `recommend` (`run` INT)"},
+	}
+	RunTest(t, table, false)
+}
+
 // For BRIE
 func TestBRIE(t *testing.T) {
 	table := []testCase{
@@ -6284,6 +6326,7 @@ func TestStatisticsOps(t *testing.T) {
 		{"create statistics if not exists stats3 (correlation) on t(a,b)", true, "CREATE STATISTICS IF NOT EXISTS `stats3` (CORRELATION) ON `t`(`a`, `b`)"},
 		{"create statistics if not exists stats3 on t(a,b)", false, ""},
 		{"create statistics stats1(cardinality) on t(a,b,c)", true, "CREATE STATISTICS `stats1` (CARDINALITY) ON `t`(`a`, `b`, `c`)"},
+		{"create statistics stats1 (ndv) on t(a,b)", true, "CREATE STATISTICS `stats1` (CARDINALITY) ON `t`(`a`, `b`)"},
 		{"drop statistics stats1", true, "DROP STATISTICS `stats1`"},
 	}
 	RunTest(t, table, false)
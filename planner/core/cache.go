@@ -44,6 +44,8 @@ var (
 	PreparedPlanCacheMemoryGuardRatio = 0.1
 	// PreparedPlanCacheMaxMemory stores the max memory size defined in the global config "performance-server-memory-quota".
 	PreparedPlanCacheMaxMemory = *atomic2.NewUint64(math.MaxUint64)
+	// instancePlanCacheEnabled stores the global config "prepared-plan-cache.instance-enabled".
+	instancePlanCacheEnabled = atomic2.NewBool(false)
 )
 
 const (
@@ -66,6 +68,17 @@ func PreparedPlanCacheEnabled() bool {
 	return isEnabled == preparedPlanCacheEnabled
 }
 
+// SetInstancePlanCache sets whether the sessions share the cached plans in the instance plan cache.
+func SetInstancePlanCache(isEnabled bool) {
+	instancePlanCacheEnabled.Store(isEnabled)
+}
+
+// InstancePlanCacheEnabled returns whether the instance plan cache is enabled.
+// It only takes effect when the prepared plan cache is enabled.
+func InstancePlanCacheEnabled() bool {
+	return instancePlanCacheEnabled.Load()
+}
+
 // planCacheKey is used to access Plan Cache. We put some variables that do not affect the plan into planCacheKey, such as the sql text.
 // Put the parameters that may affect the plan in planCacheValue, such as bindSQL.
 // However, due to some compatibility reasons, we will temporarily keep some system variable-related values in planCacheKey.
@@ -79,6 +92,7 @@ type planCacheKey struct {
 	timezoneOffset       int
 	isolationReadEngines map[kv.StoreType]struct{}
 	selectLimit          uint64
+	partitionPruneMode   string
 
 	hash []byte
 }
@@ -109,6 +123,7 @@ func (key *planCacheKey) Hash() []byte {
 			key.hash = append(key.hash, kv.TiFlash.Name()...)
 		}
 		key.hash = codec.EncodeInt(key.hash, int64(key.selectLimit))
+		key.hash = append(key.hash, hack.Slice(key.partitionPruneMode)...)
 	}
 	return key.hash
 }
@@ -150,6 +165,7 @@ func NewPlanCacheKey(sessionVars *variable.SessionVars, stmtText, stmtDB string,
 		timezoneOffset:       timezoneOffset,
 		isolationReadEngines: make(map[kv.StoreType]struct{}),
 		selectLimit:          sessionVars.SelectLimit,
+		partitionPruneMode:   sessionVars.PartitionPruneMode.Load(),
 	}
 	for k, v := range sessionVars.IsolationReadEngines {
 		key.isolationReadEngines[k] = v
@@ -238,6 +254,10 @@ type CachedPrepareStmt struct {
 	//  NormalizedSQL4PC: select * from `test` . `t` where `a` > ? and `b` < ? --> schema name is added,
 	//  StmtText: select * from t where a>1 and b <? --> just format the original query;
 	StmtText string
+	// StmtText4PC is the text of the statement whose IN-lists are padded by PadPreparedInLists, it's used as the
+	// key of the plan cache. PaddedParams are the parameters padded into the IN-lists.
+	StmtText4PC  string
+	PaddedParams []PaddedParam
 }
 
 // GetPreparedStmt extract the prepared statement from the execute statement.
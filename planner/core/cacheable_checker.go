@@ -15,12 +15,15 @@
 package core
 
 import (
+	"strings"
+
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/parser/ast"
 	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/sessionctx"
 	driver "github.com/pingcap/tidb/types/parser_driver"
+	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/logutil"
 	"go.uber.org/zap"
 )
@@ -51,6 +54,26 @@ func CacheableWithCtx(sctx sessionctx.Context, node ast.Node, is infoschema.Info
 	return checker.cacheable
 }
 
+// GeneralPlanCacheableWithCtx checks whether the plain-text statement can use the general plan cache.
+// The reason is returned if it can't.
+func GeneralPlanCacheableWithCtx(sctx sessionctx.Context, node ast.Node, is infoschema.InfoSchema) (bool, string) {
+	sel, isSelect := node.(*ast.SelectStmt)
+	if !isSelect {
+		return false, "only SELECT statements are supported"
+	}
+	if sel.SelectIntoOpt != nil {
+		return false, "SELECT INTO is not supported"
+	}
+	checker := cacheableChecker{
+		sctx:      sctx,
+		cacheable: true,
+		schema:    is,
+		general:   true,
+	}
+	node.Accept(&checker)
+	return checker.cacheable, checker.reason
+}
+
 // cacheableChecker checks whether a query's plan can be cached, querys that:
 //	 1. have ExistsSubqueryExpr, or
 //	 2. have VariableExpr
@@ -60,6 +83,10 @@ type cacheableChecker struct {
 	sctx      sessionctx.Context
 	cacheable bool
 	schema    infoschema.InfoSchema
+	// general is true if the statement is checked for the general plan cache.
+	general bool
+	// reason is the reason why the statement can't be cached.
+	reason string
 }
 
 // Enter implements Visitor interface.
@@ -68,83 +95,99 @@ func (checker *cacheableChecker) Enter(in ast.Node) (out ast.Node, skipChildren
 	case *ast.SelectStmt:
 		for _, hints := range node.TableHints {
 			if hints.HintName.L == HintIgnorePlanCache {
-				checker.cacheable = false
+				checker.setUncacheable("ignore_plan_cache hint")
 				return in, true
 			}
 		}
 	case *ast.DeleteStmt:
 		for _, hints := range node.TableHints {
 			if hints.HintName.L == HintIgnorePlanCache {
-				checker.cacheable = false
+				checker.setUncacheable("ignore_plan_cache hint")
 				return in, true
 			}
 		}
 	case *ast.UpdateStmt:
 		for _, hints := range node.TableHints {
 			if hints.HintName.L == HintIgnorePlanCache {
-				checker.cacheable = false
+				checker.setUncacheable("ignore_plan_cache hint")
 				return in, true
 			}
 		}
-	case *ast.VariableExpr, *ast.ExistsSubqueryExpr, *ast.SubqueryExpr:
-		checker.cacheable = false
+	case *ast.VariableExpr:
+		checker.setUncacheable("query has variables")
+		return in, true
+	case *ast.ExistsSubqueryExpr, *ast.SubqueryExpr:
+		checker.setUncacheable("query has sub-queries")
 		return in, true
 	case *ast.FuncCallExpr:
 		if _, found := expression.UnCacheableFunctions[node.FnName.L]; found {
-			checker.cacheable = false
+			checker.setUncacheable(node.FnName.L + " function is not cacheable")
+			return in, true
+		}
+	case *driver.ParamMarkerExpr:
+		if checker.general {
+			checker.setUncacheable("query has parameter markers")
 			return in, true
 		}
 	case *ast.OrderByClause:
 		for _, item := range node.Items {
 			if _, isParamMarker := item.Expr.(*driver.ParamMarkerExpr); isParamMarker {
-				checker.cacheable = false
+				checker.setUncacheable("ORDER BY with parameter markers")
 				return in, true
 			}
 		}
 	case *ast.GroupByClause:
 		for _, item := range node.Items {
 			if _, isParamMarker := item.Expr.(*driver.ParamMarkerExpr); isParamMarker {
-				checker.cacheable = false
+				checker.setUncacheable("GROUP BY with parameter markers")
 				return in, true
 			}
 		}
 	case *ast.Limit:
 		if node.Count != nil {
 			if _, isParamMarker := node.Count.(*driver.ParamMarkerExpr); isParamMarker {
-				checker.cacheable = false
+				checker.setUncacheable("LIMIT with parameter markers")
 				return in, true
 			}
 		}
 		if node.Offset != nil {
 			if _, isParamMarker := node.Offset.(*driver.ParamMarkerExpr); isParamMarker {
-				checker.cacheable = false
+				checker.setUncacheable("LIMIT with parameter markers")
 				return in, true
 			}
 		}
 	case *ast.FrameBound:
 		if _, ok := node.Expr.(*driver.ParamMarkerExpr); ok {
-			checker.cacheable = false
+			checker.setUncacheable("window frame with parameter markers")
 			return in, true
 		}
 	case *ast.TableName:
 		if checker.schema != nil {
 			if checker.isPartitionTable(node) {
-				// Temporary disable prepared plan cache until https://github.com/pingcap/tidb/issues/33031
-				// is fixed and additional tests with dynamic partition prune mode has been added.
-				/*
-					if checker.sctx != nil && checker.sctx.GetSessionVars().UseDynamicPartitionPrune() {
-						return in, false // dynamic-mode for partition tables can use plan-cache
-					}
-				*/
-				checker.cacheable = false
-				return in, true
+				// In dynamic mode the partitions are pruned when the executors are built, so the cached
+				// plans are pruned again with the new parameters. In static mode the pruned partitions
+				// are a part of the plan.
+				if checker.sctx == nil || !checker.sctx.GetSessionVars().UseDynamicPartitionPrune() {
+					checker.setUncacheable("query accesses partitioned tables")
+					return in, true
+				}
 			}
 			if checker.hasGeneratedCol(node) {
-				checker.cacheable = false
+				checker.setUncacheable("query accesses generated columns")
 				return in, true
 			}
 			if checker.isTempTable(node) {
-				checker.cacheable = false
+				checker.setUncacheable("query accesses temporary tables")
+				return in, true
+			}
+		}
+		if checker.general {
+			if node.AsOf != nil {
+				checker.setUncacheable("query uses stale read")
+				return in, true
+			}
+			if checker.isMemTable(node) {
+				checker.setUncacheable("query accesses memory tables")
 				return in, true
 			}
 		}
@@ -190,6 +233,19 @@ func (checker *cacheableChecker) isPartitionTable(tn *ast.TableName) bool {
 	return false
 }
 
+func (checker *cacheableChecker) isMemTable(tn *ast.TableName) bool {
+	schema := tn.Schema.L
+	if schema == "" && checker.sctx != nil {
+		schema = strings.ToLower(checker.sctx.GetSessionVars().CurrentDB)
+	}
+	return util.IsMemDB(schema)
+}
+
+func (checker *cacheableChecker) setUncacheable(reason string) {
+	checker.cacheable = false
+	checker.reason = reason
+}
+
 // Leave implements Visitor interface.
 func (checker *cacheableChecker) Leave(in ast.Node) (out ast.Node, ok bool) {
 	return in, checker.cacheable
@@ -169,6 +169,17 @@ type AdminResetTelemetryID struct {
 	baseSchemaProducer
 }
 
+// AdminShowPlanRegressions displays the plan regressions detected and fixed automatically.
+type AdminShowPlanRegressions struct {
+	baseSchemaProducer
+}
+
+// AdminRejectPlanRegression drops the bindings created for the plan regressions of a SQL digest.
+type AdminRejectPlanRegression struct {
+	baseSchemaProducer
+	SQLDigest string
+}
+
 // Change represents a change plan.
 type Change struct {
 	baseSchemaProducer
@@ -240,7 +251,8 @@ func (e *Execute) OptimizePreparedPlan(ctx context.Context, sctx sessionctx.Cont
 		if len(prepared.Params) != paramLen {
 			return errors.Trace(ErrWrongParamCount)
 		}
-		vars.PreparedParams = e.PrepareParams
+		// Clip the capacity, the parameters padded into the IN-lists are appended below.
+		vars.PreparedParams = e.PrepareParams[:paramLen:paramLen]
 		for i, val := range vars.PreparedParams {
 			param := prepared.Params[i].(*driver.ParamMarkerExpr)
 			param.Datum = val
@@ -270,6 +282,13 @@ func (e *Execute) OptimizePreparedPlan(ctx context.Context, sctx sessionctx.Cont
 			vars.PreparedParams = append(vars.PreparedParams, val)
 		}
 	}
+	// The parameters padded into the IN-lists take the values of the last items, see PadPreparedInLists.
+	for _, padded := range preparedObj.PaddedParams {
+		val := vars.PreparedParams[padded.Source]
+		padded.Marker.Datum = val
+		padded.Marker.InExecute = true
+		vars.PreparedParams = append(vars.PreparedParams, val)
+	}
 
 	// Just setting `e.SnapshotTS`, `e.ReadReplicaScope` and `e.IsStaleness` with the return value of `handleExecuteBuilderOption`
 	// for asserting the stale read context after refactoring is exactly the same with the previous logic.
@@ -452,7 +471,7 @@ func (e *Execute) getPhysicalPlan(ctx context.Context, sctx sessionctx.Context,
 	var bindSQL string
 	if prepared.UseCache {
 		bindSQL = GetBindSQL4PlanCache(sctx, preparedStmt)
-		if cacheKey, err = NewPlanCacheKey(sctx.GetSessionVars(), preparedStmt.StmtText, preparedStmt.StmtDB, prepared.SchemaVersion); err != nil {
+		if cacheKey, err = NewPlanCacheKey(sctx.GetSessionVars(), preparedStmt.StmtText4PC, preparedStmt.StmtDB, prepared.SchemaVersion); err != nil {
 			return err
 		}
 	}
@@ -465,6 +484,12 @@ func (e *Execute) getPhysicalPlan(ctx context.Context, sctx sessionctx.Context,
 			tps[i] = types.NewFieldType(mysql.TypeNull)
 		}
 	}
+	// The parameters padded into the IN-lists have the types of the last items, see PadPreparedInLists.
+	if varsNum > 0 {
+		for _, padded := range preparedStmt.PaddedParams {
+			tps = append(tps, tps[padded.Source])
+		}
+	}
 	if prepared.CachedPlan != nil {
 		// Rewriting the expression in the select.where condition  will convert its
 		// type from "paramMarker" to "Constant".When Point Select queries are executed,
@@ -549,6 +574,12 @@ func (e *Execute) getPhysicalPlan(ctx context.Context, sctx sessionctx.Context,
 				break
 			}
 		}
+		if InstancePlanCacheEnabled() {
+			hit, err := e.getInstanceCachedPlan(ctx, sctx, is, preparedStmt, bindSQL, e.planCacheParamTypes(sctx, tps))
+			if err != nil || hit {
+				return err
+			}
+		}
 	}
 
 REBUILD:
@@ -571,7 +602,7 @@ REBUILD:
 		// rebuild key to exclude kv.TiFlash when stmt is not read only
 		if _, isolationReadContainTiFlash := sessVars.IsolationReadEngines[kv.TiFlash]; isolationReadContainTiFlash && !IsReadOnly(stmt, sessVars) {
 			delete(sessVars.IsolationReadEngines, kv.TiFlash)
-			if cacheKey, err = NewPlanCacheKey(sessVars, preparedStmt.StmtText, preparedStmt.StmtDB, prepared.SchemaVersion); err != nil {
+			if cacheKey, err = NewPlanCacheKey(sessVars, preparedStmt.StmtText4PC, preparedStmt.StmtDB, prepared.SchemaVersion); err != nil {
 				return err
 			}
 			sessVars.IsolationReadEngines[kv.TiFlash] = struct{}{}
@@ -579,7 +610,16 @@ REBUILD:
 		cached := NewPlanCacheValue(p, names, stmtCtx.TblInfo2UnionScan, tps, sessVars.StmtCtx.BindSQL)
 		preparedStmt.NormalizedPlan, preparedStmt.PlanDigest = NormalizePlan(p)
 		stmtCtx.SetPlanDigest(preparedStmt.NormalizedPlan, preparedStmt.PlanDigest)
-		if cacheVals, exists := sctx.PreparedPlanCache().Get(cacheKey); exists {
+		shared := false
+		if InstancePlanCacheEnabled() {
+			// the plans which can't be shared are still cached in the session.
+			if shared, err = putInstanceCachedPlan(sctx, preparedStmt, cached, e.planCacheParamTypes(sctx, tps)); err != nil {
+				return err
+			}
+		}
+		if shared {
+			// the plan is cached in the instance plan cache, don't cache it in the session again.
+		} else if cacheVals, exists := sctx.PreparedPlanCache().Get(cacheKey); exists {
 			hitVal := false
 			for i, cacheVal := range cacheVals.([]*PlanCacheValue) {
 				if cacheVal.UserVarTypes.CheckTypesCompatibility4PC(tps) {
@@ -700,6 +740,11 @@ func (e *Execute) rebuildRange(p Plan) error {
 			return err
 		}
 	case *PointGetPlan:
+		// The point get generated by cbo for partition table is only valid for the parameters it's built with,
+		// its partition is pruned by the access conditions.
+		if x.PartitionInfo != nil && x.AccessConditions != nil {
+			return errors.New("point get for partition table can not use plan cache")
+		}
 		// if access condition is not nil, which means it's a point get generated by cbo.
 		if x.AccessConditions != nil {
 			if x.IndexInfo != nil {
@@ -732,12 +777,6 @@ func (e *Execute) rebuildRange(p Plan) error {
 				}
 			}
 		}
-		// The code should never run here as long as we're not using point get for partition table.
-		// And if we change the logic one day, here work as defensive programming to cache the error.
-		if x.PartitionInfo != nil {
-			// TODO: relocate the partition after rebuilding range to make PlanCache support PointGet
-			return errors.New("point get for partition table can not use plan cache")
-		}
 		if x.HandleConstant != nil {
 			dVal, err := convertConstant2Datum(sc, x.HandleConstant, x.handleFieldType)
 			if err != nil {
@@ -748,7 +787,6 @@ func (e *Execute) rebuildRange(p Plan) error {
 				return err
 			}
 			x.Handle = kv.IntHandle(iv)
-			return nil
 		}
 		for i, param := range x.IndexConstants {
 			if param != nil {
@@ -759,6 +797,10 @@ func (e *Execute) rebuildRange(p Plan) error {
 				x.IndexValues[i] = *dVal
 			}
 		}
+		if x.PartitionInfo != nil {
+			// the partition is located by the old parameters, locate it again.
+			return x.relocatePartition()
+		}
 		return nil
 	case *BatchPointGetPlan:
 		// if access condition is not nil, which means it's a point get generated by cbo.
@@ -1118,6 +1160,10 @@ type AnalyzeColumnsTask struct {
 	ColsInfo         []*model.ColumnInfo
 	TblInfo          *model.TableInfo
 	Indexes          []*model.IndexInfo
+	// Exprs are the expressions to analyze, their columns refer to ColsInfo.
+	Exprs []expression.Expression
+	// ExprsInfo are the hidden virtual columns carrying the statistics of Exprs.
+	ExprsInfo []*model.ColumnInfo
 	AnalyzeInfo
 }
 
@@ -1184,6 +1230,14 @@ type IndexAdvise struct {
 	LinesInfo   *ast.LinesClause
 }
 
+// RecommendIndex represents a recommend index plan.
+type RecommendIndex struct {
+	baseSchemaProducer
+
+	// SQL is the statement to recommend indexes for, the workload in the statement summary is used if it's empty.
+	SQL string
+}
+
 // SplitRegion represents a split regions plan.
 type SplitRegion struct {
 	baseSchemaProducer
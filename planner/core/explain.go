@@ -736,6 +736,16 @@ func (p *PhysicalHashJoin) explainInfo(normalized bool) string {
 		buffer.WriteString(", other cond:")
 		buffer.Write(sortedExplainExpressionList(p.OtherConditions))
 	}
+	if len(p.RuntimeFilters) > 0 {
+		buffer.WriteString(", runtime filter:[")
+		for i, rf := range p.RuntimeFilters {
+			if i != 0 {
+				buffer.WriteString(" ")
+			}
+			fmt.Fprintf(buffer, "%s->%s", rf.BuildKey, rf.TargetColumn)
+		}
+		buffer.WriteString("]")
+	}
 	return buffer.String()
 }
 
@@ -0,0 +1,388 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb/domain"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/metrics"
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/parser/format"
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/parser/opcode"
+	"github.com/pingcap/tidb/privilege"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/types"
+	driver "github.com/pingcap/tidb/types/parser_driver"
+	"github.com/pingcap/tidb/util/kvcache"
+	"github.com/pingcap/tidb/util/logutil"
+	"github.com/pingcap/tidb/util/stringutil"
+	"go.uber.org/zap"
+)
+
+var generalPlanCacheCounter = metrics.PlanCacheCounter.WithLabelValues("general")
+
+// GeneralPlanCacheStmtKey is used to get the GeneralPlanCacheStmt of the statement being optimized.
+const GeneralPlanCacheStmtKey = stringutil.StringerStr("generalPlanCacheStmtKey")
+
+// GeneralPlanCacheStmt is a plain-text statement whose literals are replaced by parameter markers,
+// so the statements which only differ in these literals share the plans in the general plan cache.
+type GeneralPlanCacheStmt struct {
+	stmt       ast.StmtNode
+	key        kvcache.Key
+	paramTypes []*types.FieldType
+	// restores put the literals back into the statement.
+	restores   []func()
+	visitInfos []visitInfo
+}
+
+// SetVisitInfos sets the visit information collected when the statement is built, they're used to
+// check the privileges when the cached plan is used.
+func (s *GeneralPlanCacheStmt) SetVisitInfos(visitInfos []visitInfo) {
+	s.visitInfos = visitInfos
+}
+
+// Restore puts the literals back into the statement.
+func (s *GeneralPlanCacheStmt) Restore() {
+	for _, restore := range s.restores {
+		restore()
+	}
+	s.restores = nil
+}
+
+// generalPlanCacheKey is the key of the plans of the plain-text statements. It never equals the key of
+// a prepared statement, even if their texts are the same.
+type generalPlanCacheKey struct {
+	*planCacheKey
+	hash []byte
+}
+
+// Hash implements Key interface.
+func (key *generalPlanCacheKey) Hash() []byte {
+	if len(key.hash) == 0 {
+		key.hash = append(key.hash, "general:"...)
+		key.hash = append(key.hash, key.planCacheKey.Hash()...)
+	}
+	return key.hash
+}
+
+// generalPlanCacheValue is a plan of the plain-text statements in the session plan cache.
+type generalPlanCacheValue struct {
+	*PlanCacheValue
+	visitInfos     []visitInfo
+	normalizedPlan string
+	planDigest     *parser.Digest
+	// cachedAt is used to drop the plans cached before 'admin flush instance plan_cache'.
+	cachedAt types.Time
+}
+
+// literalParameterizer replaces the literals compared with other expressions by parameter markers.
+type literalParameterizer struct {
+	params   []types.Datum
+	restores []func()
+}
+
+// Enter implements Visitor interface.
+func (p *literalParameterizer) Enter(in ast.Node) (ast.Node, bool) {
+	switch x := in.(type) {
+	case *ast.BinaryOperationExpr:
+		switch x.Op {
+		case opcode.EQ, opcode.NE, opcode.LT, opcode.LE, opcode.GT, opcode.GE, opcode.NullEQ:
+			p.parameterize(&x.L)
+			p.parameterize(&x.R)
+		}
+	case *ast.PatternInExpr:
+		parameterized := true
+		for i := range x.List {
+			parameterized = p.parameterize(&x.List[i]) && parameterized
+		}
+		if parameterized && x.Sel == nil {
+			p.padInList(x)
+		}
+	case *ast.BetweenExpr:
+		p.parameterize(&x.Left)
+		p.parameterize(&x.Right)
+	}
+	return in, false
+}
+
+// Leave implements Visitor interface.
+func (p *literalParameterizer) Leave(in ast.Node) (ast.Node, bool) {
+	return in, true
+}
+
+func (p *literalParameterizer) parameterize(expr *ast.ExprNode) bool {
+	v, ok := (*expr).(*driver.ValueExpr)
+	if !ok || !isParameterizableLiteral(v) {
+		return false
+	}
+	*expr = p.newParamMarker(v)
+	p.restores = append(p.restores, func() { *expr = v })
+	return true
+}
+
+func (p *literalParameterizer) newParamMarker(v *driver.ValueExpr) *driver.ParamMarkerExpr {
+	param := &driver.ParamMarkerExpr{
+		ValueExpr: *v,
+		Order:     len(p.params),
+		InExecute: true,
+	}
+	p.params = append(p.params, v.Datum)
+	return param
+}
+
+// padInList pads the parameterized IN-list to the bucket size by repeating its last item, so the
+// statements whose IN-lists only differ in the length share the plans. The repeated items don't
+// change the result, 'a in (1, 2, 3, 3)' equals 'a in (1, 2, 3)'.
+func (p *literalParameterizer) padInList(x *ast.PatternInExpr) {
+	size := inListBucketSize(len(x.List))
+	if size == len(x.List) {
+		return
+	}
+	last := &x.List[len(x.List)-1].(*driver.ParamMarkerExpr).ValueExpr
+	list := make([]ast.ExprNode, size)
+	copy(list, x.List)
+	for i := len(x.List); i < size; i++ {
+		list[i] = p.newParamMarker(last)
+	}
+	origList := x.List
+	x.List = list
+	p.restores = append(p.restores, func() { x.List = origList })
+}
+
+// PaddedParam is a parameter marker padded into an IN-list of a prepared statement. Its value is copied from
+// the parameter at the offset Source when the statement is executed.
+type PaddedParam struct {
+	Marker *driver.ParamMarkerExpr
+	Source int
+}
+
+// inListPadder pads the IN-lists of parameter markers in a prepared statement.
+type inListPadder struct {
+	paramCount int
+	hasInList  bool
+	padded     []PaddedParam
+	restores   []func()
+}
+
+// Enter implements Visitor interface.
+func (p *inListPadder) Enter(in ast.Node) (ast.Node, bool) {
+	x, ok := in.(*ast.PatternInExpr)
+	if !ok || x.Sel != nil {
+		return in, false
+	}
+	for _, item := range x.List {
+		if _, ok := item.(*driver.ParamMarkerExpr); !ok {
+			return in, false
+		}
+	}
+	p.hasInList = true
+	size := inListBucketSize(len(x.List))
+	if size == len(x.List) {
+		return in, false
+	}
+	last := x.List[len(x.List)-1].(*driver.ParamMarkerExpr)
+	list := make([]ast.ExprNode, size)
+	copy(list, x.List)
+	for i := len(x.List); i < size; i++ {
+		marker := *last
+		marker.Order = p.paramCount + len(p.padded)
+		list[i] = &marker
+		p.padded = append(p.padded, PaddedParam{Marker: &marker, Source: last.Order})
+	}
+	origList := x.List
+	x.List = list
+	p.restores = append(p.restores, func() { x.List = origList })
+	return in, false
+}
+
+// Leave implements Visitor interface.
+func (p *inListPadder) Leave(in ast.Node) (ast.Node, bool) {
+	return in, true
+}
+
+// PadPreparedInLists pads the IN-lists of parameter markers in the prepared statement to the bucket size like
+// padInList, so the prepared statements whose IN-lists only differ in the length share the plans. The client
+// still binds paramCount parameters, the padded ones are ordered after them and returned. It also returns the
+// text of the padded statement, which is used as the key of the plan cache. The statements whose IN-lists are
+// already of the bucket size are restored in the same way, so they share the key with the padded ones. If there
+// are no IN-lists of parameter markers, it returns the text of the statement.
+func PadPreparedInLists(stmt ast.StmtNode, paramCount int) (string, []PaddedParam) {
+	p := &inListPadder{paramCount: paramCount}
+	stmt.Accept(p)
+	if !p.hasInList {
+		return stmt.Text(), nil
+	}
+	var sb strings.Builder
+	if err := stmt.Restore(format.NewRestoreCtx(format.DefaultRestoreFlags, &sb)); err != nil {
+		for _, restore := range p.restores {
+			restore()
+		}
+		return stmt.Text(), nil
+	}
+	return sb.String(), p.padded
+}
+
+// inListBucketSize returns the smallest power of 2 which isn't less than n.
+func inListBucketSize(n int) int {
+	size := 1
+	for size < n {
+		size <<= 1
+	}
+	return size
+}
+
+// isParameterizableLiteral checks whether the literal keeps its type after it's parameterized,
+// the type of a parameter is derived from its value only.
+func isParameterizableLiteral(v *driver.ValueExpr) bool {
+	switch v.Kind() {
+	case types.KindInt64, types.KindUint64, types.KindFloat64, types.KindMysqlDecimal, types.KindString:
+	default:
+		return false
+	}
+	if v.Type.Flag&mysql.IsBooleanFlag != 0 {
+		return false
+	}
+	tp := types.NewFieldType(mysql.TypeUnspecified)
+	types.DefaultParamTypeForValue(v.GetValue(), tp)
+	return tp.Tp == v.Type.Tp && tp.Charset == v.Type.Charset && tp.Collate == v.Type.Collate
+}
+
+// ParameterizeStmt replaces the literals compared in the WHERE clause of the plain-text statement by
+// parameter markers, and sets their values to the parameters of the session. It returns the reason if
+// the statement can't use the general plan cache.
+// Restore must be called to put the literals back after the statement is optimized.
+func ParameterizeStmt(sctx sessionctx.Context, stmt ast.StmtNode, is infoschema.InfoSchema) (*GeneralPlanCacheStmt, string) {
+	if cacheable, reason := GeneralPlanCacheableWithCtx(sctx, stmt, is); !cacheable {
+		return nil, reason
+	}
+	p := &literalParameterizer{}
+	if where := stmt.(*ast.SelectStmt).Where; where != nil {
+		where.Accept(p)
+	}
+	s := &GeneralPlanCacheStmt{
+		stmt:       stmt,
+		paramTypes: make([]*types.FieldType, 0, len(p.params)),
+		restores:   p.restores,
+	}
+	var sb strings.Builder
+	if err := stmt.Restore(format.NewRestoreCtx(format.DefaultRestoreFlags, &sb)); err != nil {
+		s.Restore()
+		return nil, "failed to restore the parameterized query"
+	}
+	sessVars := sctx.GetSessionVars()
+	key, err := NewPlanCacheKey(sessVars, sb.String(), "", is.SchemaMetaVersion())
+	if err != nil {
+		s.Restore()
+		return nil, err.Error()
+	}
+	s.key = &generalPlanCacheKey{planCacheKey: key.(*planCacheKey)}
+	for _, param := range p.params {
+		tp := types.NewFieldType(mysql.TypeUnspecified)
+		types.DefaultParamTypeForValue(param.GetValue(), tp)
+		s.paramTypes = append(s.paramTypes, tp)
+	}
+	sessVars.PreparedParams = p.params
+	sessVars.StmtCtx.UseCache = true
+	return s, ""
+}
+
+// GetGeneralCachedPlan tries to use a cached plan for the parameterized statement.
+// It returns false if no plan can be used.
+func GetGeneralCachedPlan(sctx sessionctx.Context, is infoschema.InfoSchema, s *GeneralPlanCacheStmt) (Plan, types.NameSlice, bool, error) {
+	cache := sctx.PreparedPlanCache()
+	cacheValue, exists := cache.Get(s.key)
+	if !exists {
+		return nil, nil, false, nil
+	}
+	sessVars := sctx.GetSessionVars()
+	expiredTimeStamp4PC := domain.GetDomain(sctx).ExpiredTimeStamp4PC()
+	for _, cachedVal := range cacheValue.([]*generalPlanCacheValue) {
+		if !cachedVal.UserVarTypes.CheckTypesCompatibility4PC(s.paramTypes) {
+			continue
+		}
+		if expiredTimeStamp4PC.Compare(cachedVal.cachedAt) > 0 {
+			// other sessions have executed 'admin flush instance plan_cache'.
+			cache.Delete(s.key)
+			return nil, nil, false, nil
+		}
+		for tblInfo, unionScan := range cachedVal.TblInfo2UnionScan {
+			if !unionScan && tableHasDirtyContent(sctx, tblInfo) {
+				cache.Delete(s.key)
+				return nil, nil, false, nil
+			}
+		}
+		if pm := privilege.GetPrivilegeManager(sctx); pm != nil {
+			visitInfo := VisitInfo4PrivCheck(is, s.stmt, cachedVal.visitInfos)
+			if err := CheckPrivilege(sessVars.ActiveRoles, pm, visitInfo); err != nil {
+				return nil, nil, false, err
+			}
+		}
+		if err := CheckTableLock(sctx, is, cachedVal.visitInfos); err != nil {
+			return nil, nil, false, err
+		}
+		if err := (&Execute{}).RebuildPlan(cachedVal.Plan); err != nil {
+			logutil.BgLogger().Debug("rebuild range failed", zap.Error(err))
+			return nil, nil, false, nil
+		}
+		if err := sessVars.SetSystemVar(variable.TiDBFoundInPlanCache, variable.BoolToOnOff(true)); err != nil {
+			return nil, nil, false, err
+		}
+		if metrics.ResettablePlanCacheCounterFortTest {
+			metrics.PlanCacheCounter.WithLabelValues("general").Inc()
+		} else {
+			generalPlanCacheCounter.Inc()
+		}
+		sessVars.StmtCtx.SetPlanDigest(cachedVal.normalizedPlan, cachedVal.planDigest)
+		return cachedVal.Plan, cachedVal.OutPutNames, true, nil
+	}
+	return nil, nil, false, nil
+}
+
+// PutGeneralCachedPlan caches the plan of the parameterized statement. It returns the reason if the plan can't be cached.
+func PutGeneralCachedPlan(sctx sessionctx.Context, s *GeneralPlanCacheStmt, p Plan, names types.NameSlice) string {
+	stmtCtx := sctx.GetSessionVars().StmtCtx
+	if stmtCtx.SkipPlanCache || (containTableDual(p) && len(s.paramTypes) > 0) {
+		return "the plan is optimized for the literals"
+	}
+	if _, ok := p.(PhysicalPlan); !ok {
+		return "the plan isn't a physical plan"
+	}
+	normalizedPlan, planDigest := NormalizePlan(p)
+	stmtCtx.SetPlanDigest(normalizedPlan, planDigest)
+	value := &generalPlanCacheValue{
+		PlanCacheValue: NewPlanCacheValue(p, names, stmtCtx.TblInfo2UnionScan, s.paramTypes, ""),
+		visitInfos:     s.visitInfos,
+		normalizedPlan: normalizedPlan,
+		planDigest:     planDigest,
+		cachedAt:       types.NewTime(types.FromGoTime(time.Now().In(stmtCtx.TimeZone)), mysql.TypeTimestamp, 3),
+	}
+	cache := sctx.PreparedPlanCache()
+	values := []*generalPlanCacheValue{value}
+	if cacheValue, exists := cache.Get(s.key); exists {
+		for _, cachedVal := range cacheValue.([]*generalPlanCacheValue) {
+			if !cachedVal.UserVarTypes.CheckTypesCompatibility4PC(s.paramTypes) {
+				values = append(values, cachedVal)
+			}
+		}
+	}
+	cache.Put(s.key, values)
+	return ""
+}
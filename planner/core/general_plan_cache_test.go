@@ -0,0 +1,193 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/planner/core"
+	"github.com/pingcap/tidb/session"
+	"github.com/pingcap/tidb/testkit"
+	"github.com/pingcap/tidb/util/kvcache"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneralPlanCacheable(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t(a int, b int)")
+	tk.MustExec("create table tp(a int, b int) partition by hash(a) partitions 4")
+	tk.MustExec("set @@tidb_partition_prune_mode = 'static'")
+	is := tk.Session().GetInfoSchema().(infoschema.InfoSchema)
+
+	for _, ca := range []struct {
+		sql    string
+		reason string
+	}{
+		{"select * from t where a > 1", ""},
+		{"select a, count(*) from t where a in (1, 2) group by a order by a limit 10", ""},
+		{"select * from t t1 join t t2 on t1.a = t2.b where t1.b between 1 and 10", ""},
+		{"update t set a = 1 where b = 2", "only SELECT statements are supported"},
+		{"select * from t where a > @x", "query has variables"},
+		{"select * from t where a > (select max(a) from t)", "query has sub-queries"},
+		{"select * from t where a > 1 and b = database()", "database function is not cacheable"},
+		{"select /*+ ignore_plan_cache() */ * from t where a > 1", "ignore_plan_cache hint"},
+		{"select * from test.tp where a > 1", "query accesses partitioned tables"},
+		{"select * from information_schema.tables where table_name = 't'", "query accesses memory tables"},
+		{"select * from t where a > 1 into outfile '/tmp/t'", "SELECT INTO is not supported"},
+	} {
+		stmt, err := parser.New().ParseOneStmt(ca.sql, "", "")
+		require.NoError(t, err)
+		cacheable, reason := core.GeneralPlanCacheableWithCtx(tk.Session(), stmt, is)
+		require.Equal(t, ca.reason == "", cacheable, ca.sql)
+		require.Equal(t, ca.reason, reason, ca.sql)
+	}
+
+	// the partitions are pruned again when the cached plans are executed in dynamic prune mode.
+	tk.MustExec("set @@tidb_partition_prune_mode = 'dynamic'")
+	stmt, err := parser.New().ParseOneStmt("select * from test.tp where a > 1", "", "")
+	require.NoError(t, err)
+	cacheable, reason := core.GeneralPlanCacheableWithCtx(tk.Session(), stmt, is)
+	require.True(t, cacheable)
+	require.Equal(t, "", reason)
+}
+
+func TestGeneralPlanCache(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+	orgEnable := core.PreparedPlanCacheEnabled()
+	defer core.SetPreparedPlanCache(orgEnable)
+	core.SetPreparedPlanCache(true)
+	se, err := session.CreateSession4TestWithOpt(store, &session.Opt{
+		PreparedPlanCache: kvcache.NewSimpleLRUCache(100, 0.1, math.MaxUint64),
+	})
+	require.NoError(t, err)
+	tk := testkit.NewTestKitWithSession(t, store, se)
+	tk.MustExec("use test")
+	tk.MustExec("create table t(a int, b int, c varchar(10), key(b))")
+	tk.MustExec("insert into t values (1, 1, 'a'), (2, 2, 'b'), (3, 3, 'c'), (4, 4, 'd')")
+
+	// the cache is disabled by default.
+	tk.MustQuery("select a from t where b > 1 order by a").Check(testkit.Rows("2", "3", "4"))
+	tk.MustQuery("select a from t where b > 2 order by a").Check(testkit.Rows("3", "4"))
+	tk.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("0"))
+
+	tk.MustExec("set @@tidb_enable_general_plan_cache = 1")
+	tk.MustQuery("select a from t where b > 1 order by a").Check(testkit.Rows("2", "3", "4"))
+	tk.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("0"))
+	tk.MustQuery("select a from t where b > 2 order by a").Check(testkit.Rows("3", "4"))
+	tk.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("1"))
+	tk.MustQuery("select a from t where b > 3 order by a").Check(testkit.Rows("4"))
+	tk.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("1"))
+
+	// the ranges are rebuilt with the new literals.
+	tk.MustQuery("select a from t use index(b) where b between 2 and 3 order by a").Check(testkit.Rows("2", "3"))
+	tk.MustQuery("select a from t use index(b) where b between 3 and 4 order by a").Check(testkit.Rows("3", "4"))
+	tk.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("1"))
+	tk.MustQuery("select a from t where c in ('a', 'b') order by a").Check(testkit.Rows("1", "2"))
+	tk.MustQuery("select a from t where c in ('c', 'd') order by a").Check(testkit.Rows("3", "4"))
+	tk.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("1"))
+
+	// the IN-lists are bucketed by their lengths, the statements in different buckets or with literals of
+	// different types don't share plans.
+	tk.MustQuery("select a from t where c in ('a', 'b', 'c') order by a").Check(testkit.Rows("1", "2", "3"))
+	tk.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("0"))
+	tk.MustQuery("select a from t where c in ('a', 'b', 'c', 'd') order by a").Check(testkit.Rows("1", "2", "3", "4"))
+	tk.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("1"))
+	tk.MustQuery("select a from t where c in ('d', 'b', 'c') order by a").Check(testkit.Rows("2", "3", "4"))
+	tk.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("1"))
+	tk.MustQuery("select a from t where c in ('a', 'b', 'c', 'd', 'e') order by a").Check(testkit.Rows("1", "2", "3", "4"))
+	tk.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("0"))
+	tk.MustQuery("select a from t where b > 2.5 order by a").Check(testkit.Rows("3", "4"))
+	tk.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("0"))
+
+	// the literals which aren't parameterized are a part of the cache key.
+	tk.MustQuery("select a, 1 from t where b = 1").Check(testkit.Rows("1 1"))
+	tk.MustQuery("select a, 2 from t where b = 1").Check(testkit.Rows("1 2"))
+	tk.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("0"))
+	tk.MustQuery("select a from t where b > 1 order by a limit 1").Check(testkit.Rows("2"))
+	tk.MustQuery("select a from t where b > 1 order by a limit 2").Check(testkit.Rows("2", "3"))
+	tk.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("0"))
+
+	// EXPLAIN shows whether the cached plan is used, and why the statement can't use the cache.
+	tk.MustQuery("explain select a from t where b > 1 order by a")
+	tk.MustQuery("show warnings").Check(testkit.Rows("Note 1105 use the plan from the general plan cache"))
+	tk.MustQuery("explain select a from t where b > @x")
+	tk.MustQuery("show warnings").Check(testkit.Rows("Note 1105 skip general plan cache: query has variables"))
+	tk.MustQuery("explain select a from t where b > '1'")
+	tk.MustQuery("show warnings").Check(testkit.Rows("Note 1105 skip general plan cache: the plan is optimized for the literals"))
+
+	// the cached plans can't read the uncommitted data.
+	tk.MustExec("begin")
+	tk.MustExec("insert into t values (5, 5, 'e')")
+	tk.MustQuery("select a from t where b > 3 order by a").Check(testkit.Rows("4", "5"))
+	tk.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("0"))
+	tk.MustExec("rollback")
+	tk.MustQuery("select a from t where b > 3 order by a").Check(testkit.Rows("4"))
+
+	// the plans are rebuilt after the schema is changed.
+	tk.MustQuery("select a from t where b > 2 order by a").Check(testkit.Rows("3", "4"))
+	tk.MustExec("alter table t add column d int")
+	tk.MustQuery("select a from t where b > 2 order by a").Check(testkit.Rows("3", "4"))
+	tk.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("0"))
+
+	tk.MustExec("admin flush session plan_cache")
+	tk.MustQuery("select a from t where b > 2 order by a").Check(testkit.Rows("3", "4"))
+	tk.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("0"))
+}
+
+func TestGeneralPlanCacheForPartition(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+	orgEnable := core.PreparedPlanCacheEnabled()
+	defer core.SetPreparedPlanCache(orgEnable)
+	core.SetPreparedPlanCache(true)
+	se, err := session.CreateSession4TestWithOpt(store, &session.Opt{
+		PreparedPlanCache: kvcache.NewSimpleLRUCache(100, 0.1, math.MaxUint64),
+	})
+	require.NoError(t, err)
+	tk := testkit.NewTestKitWithSession(t, store, se)
+	tk.MustExec("use test")
+	tk.MustExec("set @@tidb_enable_general_plan_cache = 1")
+	tk.MustExec("set @@tidb_partition_prune_mode = 'dynamic'")
+	tk.MustExec("create table t(a int primary key, b int, key(b)) partition by range(a) (partition p0 values less than (10), partition p1 values less than (20), partition p2 values less than (30))")
+	tk.MustExec("insert into t values (1, 1), (11, 11), (21, 21), (22, 22)")
+
+	tk.MustQuery("select b from t where a in (1, 11) order by b").Check(testkit.Rows("1", "11"))
+	tk.MustQuery("select b from t where a in (11, 21) order by b").Check(testkit.Rows("11", "21"))
+	tk.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("1"))
+	tk.MustQuery("select b from t where a in (1, 21, 22) order by b").Check(testkit.Rows("1", "21", "22"))
+	tk.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("0"))
+	tk.MustQuery("select b from t where a in (1, 1, 21, 22) order by b").Check(testkit.Rows("1", "21", "22"))
+	tk.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("1"))
+	tk.MustQuery("select a from t use index(b) where b > 5 and b < 15 order by a").Check(testkit.Rows("11"))
+	tk.MustQuery("select a from t use index(b) where b > 15 and b < 25 order by a").Check(testkit.Rows("21", "22"))
+	tk.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("1"))
+	tk.MustQuery("select b from t where a > 5 and a < 15 order by b").Check(testkit.Rows("11"))
+	tk.MustQuery("select b from t where a > 15 and a < 25 order by b").Check(testkit.Rows("21", "22"))
+	tk.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("1"))
+
+	// the plans built in static prune mode aren't cached.
+	tk.MustExec("set @@tidb_partition_prune_mode = 'static'")
+	tk.MustQuery("select b from t where a > 5 and a < 15 order by b").Check(testkit.Rows("11"))
+	tk.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("0"))
+	tk.MustQuery("explain select b from t where a > 5 and a < 15 order by b")
+	tk.MustQuery("show warnings").Check(testkit.Rows("Note 1105 skip general plan cache: query accesses partitioned tables"))
+}
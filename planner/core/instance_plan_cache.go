@@ -0,0 +1,462 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pingcap/tidb/domain"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/metrics"
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/planner/util"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/hack"
+	"github.com/pingcap/tidb/util/logutil"
+	"go.uber.org/zap"
+)
+
+var (
+	instancePlanCacheHitCounter  = metrics.InstancePlanCacheCounter.WithLabelValues("hit")
+	instancePlanCacheMissCounter = metrics.InstancePlanCacheCounter.WithLabelValues("miss")
+)
+
+const (
+	// instancePlanCacheOperatorMemUsage is the rough memory usage of an operator, including its schema,
+	// statistics and table information.
+	instancePlanCacheOperatorMemUsage = 2048
+	// instancePlanCacheExprMemUsage is the rough memory usage of an expression tree.
+	instancePlanCacheExprMemUsage = 256
+)
+
+var (
+	instancePlanCacheSysVarsOnce sync.Once
+	instancePlanCacheSysVars     []string
+)
+
+// getInstancePlanCacheSysVars returns the variables which affect the plans but aren't in planCacheKey.
+// The session plan cache doesn't care about them because they are rarely changed in a session, but the
+// sessions sharing the instance plan cache may set them differently.
+func getInstancePlanCacheSysVars() []string {
+	instancePlanCacheSysVarsOnce.Do(func() {
+		for name := range variable.GetSysVars() {
+			if strings.HasPrefix(name, "tidb_opt_") {
+				instancePlanCacheSysVars = append(instancePlanCacheSysVars, name)
+			}
+		}
+		instancePlanCacheSysVars = append(instancePlanCacheSysVars,
+			variable.TiDBOptimizerSelectivityLevel,
+			variable.TiDBEnableIndexMerge,
+			variable.TiDBEnableIndexMergeJoin,
+			variable.TiDBPartitionPruneMode,
+			variable.TiDBAllowMPPExecution,
+			variable.TiDBEnforceMPPExecution,
+			variable.TiDBEnableCascadesPlanner,
+		)
+		sort.Strings(instancePlanCacheSysVars)
+	})
+	return instancePlanCacheSysVars
+}
+
+// instancePlanCacheKey is the key of the instance plan cache. It's the planCacheKey without the
+// connection ID, plus the values of the variables affecting the plans.
+type instancePlanCacheKey struct {
+	*planCacheKey
+	sysVarValues []string
+
+	hash []byte
+}
+
+// Hash implements Key interface.
+func (key *instancePlanCacheKey) Hash() []byte {
+	if len(key.hash) == 0 {
+		key.hash = append(key.hash, key.planCacheKey.Hash()...)
+		for _, value := range key.sysVarValues {
+			key.hash = codec.EncodeCompactBytes(key.hash, hack.Slice(value))
+		}
+	}
+	return key.hash
+}
+
+func newInstancePlanCacheKey(sessionVars *variable.SessionVars, stmtText, stmtDB string, schemaVersion int64) (*instancePlanCacheKey, error) {
+	key, err := NewPlanCacheKey(sessionVars, stmtText, stmtDB, schemaVersion)
+	if err != nil {
+		return nil, err
+	}
+	sessionKey := key.(*planCacheKey)
+	sessionKey.connID = 0
+	names := getInstancePlanCacheSysVars()
+	values := make([]string, 0, len(names))
+	for _, name := range names {
+		value, ok := sessionVars.GetSystemVar(name)
+		if !ok {
+			// the variable isn't set in the session, its value is the default one.
+			if sysVar := variable.GetSysVar(name); sysVar != nil {
+				value = sysVar.Value
+			}
+		}
+		values = append(values, value)
+	}
+	return &instancePlanCacheKey{planCacheKey: sessionKey, sysVarValues: values}, nil
+}
+
+// instancePlanCacheValue is a plan shared by the sessions. The plan isn't bound to any session and
+// is never executed, it's cloned and bound to the session on every use.
+type instancePlanCacheValue struct {
+	*PlanCacheValue
+	normalizedPlan string
+	planDigest     *parser.Digest
+	memUsage       uint64
+}
+
+// planCacheParamTypes returns the types of the parameters, which are used to check whether a cached plan
+// can be used. Unlike the session plan cache, the types of the parameters set by the binary protocol are
+// checked too, because the plan may be built by another session with different types of parameters.
+func (e *Execute) planCacheParamTypes(sctx sessionctx.Context, userVarTypes []*types.FieldType) []*types.FieldType {
+	if len(e.UsingVars) > 0 {
+		return userVarTypes
+	}
+	sessVars := sctx.GetSessionVars()
+	charset, collation := sessVars.GetCharsetInfo()
+	tps := make([]*types.FieldType, 0, len(sessVars.PreparedParams))
+	for _, param := range sessVars.PreparedParams {
+		tp := types.NewFieldType(mysql.TypeUnspecified)
+		types.DefaultTypeForValue(param.GetValue(), tp, charset, collation)
+		tps = append(tps, tp)
+	}
+	return tps
+}
+
+// getInstanceCachedPlan tries to use a plan in the instance plan cache. It returns false if no plan can be used.
+func (e *Execute) getInstanceCachedPlan(ctx context.Context, sctx sessionctx.Context, is infoschema.InfoSchema,
+	preparedStmt *CachedPrepareStmt, bindSQL string, paramTypes []*types.FieldType) (bool, error) {
+	sessVars := sctx.GetSessionVars()
+	key, err := newInstancePlanCacheKey(sessVars, preparedStmt.StmtText4PC, preparedStmt.StmtDB, preparedStmt.PreparedAst.SchemaVersion)
+	if err != nil {
+		return false, err
+	}
+	cacheValue, exists := domain.GetDomain(sctx).InstancePlanCache().Get(key)
+	if !exists {
+		instancePlanCacheMissCounter.Inc()
+		return false, nil
+	}
+	for _, cachedVal := range cacheValue.([]*instancePlanCacheValue) {
+		if cachedVal.BindSQL != bindSQL || !cachedVal.UserVarTypes.CheckTypesCompatibility4PC(paramTypes) {
+			continue
+		}
+		for tblInfo, unionScan := range cachedVal.TblInfo2UnionScan {
+			if !unionScan && tableHasDirtyContent(sctx, tblInfo) {
+				// the shared plan can't read the uncommitted data of this session.
+				instancePlanCacheMissCounter.Inc()
+				return false, nil
+			}
+		}
+		if err := e.checkPreparedPriv(ctx, sctx, preparedStmt, is); err != nil {
+			return false, err
+		}
+		plan, err := cachedVal.Plan.(PhysicalPlan).Clone()
+		if err != nil {
+			return false, err
+		}
+		bindPlanCtx(plan, sctx)
+		if err := e.RebuildPlan(plan); err != nil {
+			logutil.BgLogger().Debug("rebuild range failed", zap.Error(err))
+			instancePlanCacheMissCounter.Inc()
+			return false, nil
+		}
+		if err := e.setFoundInPlanCache(sctx, true); err != nil {
+			return false, err
+		}
+		if len(bindSQL) > 0 {
+			if err := sessVars.SetSystemVar(variable.TiDBFoundInBinding, variable.BoolToOnOff(true)); err != nil {
+				return false, err
+			}
+		}
+		if metrics.ResettablePlanCacheCounterFortTest {
+			metrics.PlanCacheCounter.WithLabelValues("prepare").Inc()
+		} else {
+			planCacheCounter.Inc()
+		}
+		instancePlanCacheHitCounter.Inc()
+		e.names = cachedVal.OutPutNames
+		e.Plan = plan
+		preparedStmt.NormalizedPlan, preparedStmt.PlanDigest = cachedVal.normalizedPlan, cachedVal.planDigest
+		sessVars.StmtCtx.SetPlanDigest(preparedStmt.NormalizedPlan, preparedStmt.PlanDigest)
+		return true, nil
+	}
+	instancePlanCacheMissCounter.Inc()
+	return false, nil
+}
+
+// putInstanceCachedPlan puts the plan into the instance plan cache if it's safe to be shared by the sessions.
+// It returns false if the plan should be cached in the session plan cache instead.
+func putInstanceCachedPlan(sctx sessionctx.Context, preparedStmt *CachedPrepareStmt, cached *PlanCacheValue,
+	paramTypes []*types.FieldType) (bool, error) {
+	p, ok := cached.Plan.(PhysicalPlan)
+	if !ok {
+		return false, nil
+	}
+	memUsage, ok := sharablePlanMemUsage(p)
+	if !ok {
+		return false, nil
+	}
+	template, err := p.Clone()
+	if err != nil {
+		return false, nil
+	}
+	// the template mustn't refer to the session which builds it.
+	bindPlanCtx(template, nil)
+
+	sessVars := sctx.GetSessionVars()
+	key, err := newInstancePlanCacheKey(sessVars, preparedStmt.StmtText4PC, preparedStmt.StmtDB, preparedStmt.PreparedAst.SchemaVersion)
+	if err != nil {
+		return false, err
+	}
+	memUsage += uint64(len(key.Hash()) + len(cached.BindSQL))
+	for _, name := range cached.OutPutNames {
+		memUsage += uint64(len(name.OrigTblName.O) + len(name.OrigColName.O) + len(name.DBName.O) + len(name.TblName.O) + len(name.ColName.O))
+	}
+	value := &instancePlanCacheValue{
+		PlanCacheValue: NewPlanCacheValue(template, cached.OutPutNames, cached.TblInfo2UnionScan, paramTypes, cached.BindSQL),
+		normalizedPlan: preparedStmt.NormalizedPlan,
+		planDigest:     preparedStmt.PlanDigest,
+		memUsage:       memUsage,
+	}
+
+	cache := domain.GetDomain(sctx).InstancePlanCache()
+	// the cached values may be used by other sessions, so a new slice is put instead of modifying it.
+	values := []*instancePlanCacheValue{value}
+	if cacheValue, exists := cache.Get(key); exists {
+		for _, cachedVal := range cacheValue.([]*instancePlanCacheValue) {
+			if cachedVal.BindSQL == cached.BindSQL && cachedVal.UserVarTypes.CheckTypesCompatibility4PC(paramTypes) {
+				continue
+			}
+			values = append(values, cachedVal)
+			memUsage += cachedVal.memUsage
+		}
+	}
+	cache.Put(key, values, memUsage)
+	metrics.InstancePlanCacheMemUsage.Set(float64(cache.MemUsage()))
+	return true, nil
+}
+
+// sharablePlanMemUsage checks whether the physical plan is safe to be shared by the sessions, and estimates
+// its memory usage if it is. Only the plans reading non-partitioned tables from TiKV with the operators
+// which can be cloned and bound to another session are shared.
+func sharablePlanMemUsage(p PhysicalPlan) (uint64, bool) {
+	var (
+		exprs      []expression.Expression
+		innerPlans []PhysicalPlan
+	)
+	switch x := p.(type) {
+	case *PhysicalTableReader:
+		if x.StoreType != kv.TiKV || x.ReadReqType != Cop {
+			return 0, false
+		}
+		innerPlans = x.TablePlans
+	case *PhysicalIndexReader:
+		innerPlans = x.IndexPlans
+	case *PhysicalIndexLookUpReader:
+		innerPlans = append(innerPlans, x.IndexPlans...)
+		innerPlans = append(innerPlans, x.TablePlans...)
+	case *PhysicalTableScan:
+		if x.StoreType != kv.TiKV || x.isPartition || x.Table.GetPartitionInfo() != nil || x.SampleInfo != nil {
+			return 0, false
+		}
+		exprs = append(exprs, x.AccessCondition...)
+		exprs = append(exprs, x.filterCondition...)
+	case *PhysicalIndexScan:
+		if x.isPartition || x.Table.GetPartitionInfo() != nil || len(x.GenExprs) > 0 {
+			return 0, false
+		}
+		exprs = x.AccessCondition
+	case *PointGetPlan:
+		// the lock wait time of the locking plans is read from the session which builds them.
+		if x.Lock || x.IsTableDual || x.PartitionInfo != nil || x.TblInfo.GetPartitionInfo() != nil {
+			return 0, false
+		}
+		exprs = append(exprs, x.AccessConditions...)
+		if x.HandleConstant != nil {
+			exprs = append(exprs, x.HandleConstant)
+		}
+		exprs = appendConstants(exprs, x.IndexConstants)
+	case *BatchPointGetPlan:
+		if x.Lock || x.SinglePart || x.TblInfo.GetPartitionInfo() != nil {
+			return 0, false
+		}
+		exprs = append(exprs, x.AccessConditions...)
+		exprs = appendConstants(exprs, x.HandleParams)
+		for _, params := range x.IndexValueParams {
+			exprs = appendConstants(exprs, params)
+		}
+	case *PhysicalSelection:
+		exprs = x.Conditions
+	case *PhysicalProjection:
+		exprs = x.Exprs
+	case *PhysicalLimit:
+	case *PhysicalTopN:
+		exprs = byItemExprs(x.ByItems)
+	case *PhysicalSort:
+		exprs = byItemExprs(x.ByItems)
+	case *PhysicalHashAgg:
+		exprs = aggExprs(&x.basePhysicalAgg)
+	case *PhysicalStreamAgg:
+		exprs = aggExprs(&x.basePhysicalAgg)
+	default:
+		return 0, false
+	}
+	for _, col := range p.Schema().Columns {
+		if col.VirtualExpr != nil {
+			return 0, false
+		}
+	}
+	for _, expr := range exprs {
+		if expression.CheckNonDeterministic(expr) || expression.IsMutableEffectsExpr(expr) ||
+			len(expression.ExtractCorColumns(expr)) > 0 {
+			return 0, false
+		}
+	}
+	memUsage := uint64(instancePlanCacheOperatorMemUsage + len(exprs)*instancePlanCacheExprMemUsage)
+	for _, child := range append(innerPlans, p.Children()...) {
+		childMemUsage, ok := sharablePlanMemUsage(child)
+		if !ok {
+			return 0, false
+		}
+		memUsage += childMemUsage
+	}
+	return memUsage, true
+}
+
+func appendConstants(exprs []expression.Expression, constants []*expression.Constant) []expression.Expression {
+	for _, c := range constants {
+		if c != nil {
+			exprs = append(exprs, c)
+		}
+	}
+	return exprs
+}
+
+func byItemExprs(byItems []*util.ByItems) []expression.Expression {
+	exprs := make([]expression.Expression, 0, len(byItems))
+	for _, item := range byItems {
+		exprs = append(exprs, item.Expr)
+	}
+	return exprs
+}
+
+func aggExprs(agg *basePhysicalAgg) []expression.Expression {
+	exprs := append([]expression.Expression{}, agg.GroupByItems...)
+	for _, aggFunc := range agg.AggFuncs {
+		exprs = append(exprs, aggFunc.Args...)
+		exprs = append(exprs, byItemExprs(aggFunc.OrderByItems)...)
+	}
+	return exprs
+}
+
+// bindPlanCtx binds a plan cloned from a sharable plan to sctx. The expressions shared with the
+// original plan are copied before being bound.
+func bindPlanCtx(p PhysicalPlan, sctx sessionctx.Context) {
+	p.setSCtx(sctx)
+	switch x := p.(type) {
+	case *PhysicalTableReader:
+		bindPlanCtx(x.tablePlan, sctx)
+		x.TablePlans = flattenPushDownPlan(x.tablePlan)
+	case *PhysicalIndexReader:
+		bindPlanCtx(x.indexPlan, sctx)
+		x.IndexPlans = flattenPushDownPlan(x.indexPlan)
+	case *PhysicalIndexLookUpReader:
+		bindPlanCtx(x.indexPlan, sctx)
+		bindPlanCtx(x.tablePlan, sctx)
+		x.IndexPlans = flattenPushDownPlan(x.indexPlan)
+		x.TablePlans = flattenPushDownPlan(x.tablePlan)
+	case *PhysicalTableScan:
+		x.AccessCondition = expression.CloneExprsWithCtx(sctx, x.AccessCondition)
+		x.filterCondition = expression.CloneExprsWithCtx(sctx, x.filterCondition)
+		if handleCols, ok := x.HandleCols.(*CommonHandleCols); ok {
+			cloned := *handleCols
+			cloned.sc = nil
+			if sctx != nil {
+				cloned.sc = sctx.GetSessionVars().StmtCtx
+			}
+			x.HandleCols = &cloned
+		}
+	case *PhysicalIndexScan:
+		x.AccessCondition = expression.CloneExprsWithCtx(sctx, x.AccessCondition)
+	case *PointGetPlan:
+		x.ctx = sctx
+		if x.AccessConditions != nil {
+			x.AccessConditions = expression.CloneExprsWithCtx(sctx, x.AccessConditions)
+		}
+		if x.HandleConstant != nil {
+			x.HandleConstant = expression.CloneWithCtx(sctx, x.HandleConstant).(*expression.Constant)
+		}
+		bindConstantsCtx(x.IndexConstants, sctx)
+	case *BatchPointGetPlan:
+		x.ctx = sctx
+		if x.AccessConditions != nil {
+			x.AccessConditions = expression.CloneExprsWithCtx(sctx, x.AccessConditions)
+		}
+		bindConstantsCtx(x.HandleParams, sctx)
+		for _, params := range x.IndexValueParams {
+			bindConstantsCtx(params, sctx)
+		}
+	case *PhysicalSelection:
+		x.Conditions = expression.CloneExprsWithCtx(sctx, x.Conditions)
+	case *PhysicalProjection:
+		x.Exprs = expression.CloneExprsWithCtx(sctx, x.Exprs)
+	case *PhysicalTopN:
+		bindByItemsCtx(x.ByItems, sctx)
+	case *PhysicalSort:
+		bindByItemsCtx(x.ByItems, sctx)
+	case *PhysicalHashAgg:
+		bindAggCtx(&x.basePhysicalAgg, sctx)
+	case *PhysicalStreamAgg:
+		bindAggCtx(&x.basePhysicalAgg, sctx)
+	}
+	for _, child := range p.Children() {
+		bindPlanCtx(child, sctx)
+	}
+}
+
+func bindConstantsCtx(constants []*expression.Constant, sctx sessionctx.Context) {
+	for i, c := range constants {
+		if c != nil {
+			constants[i] = expression.CloneWithCtx(sctx, c).(*expression.Constant)
+		}
+	}
+}
+
+func bindByItemsCtx(byItems []*util.ByItems, sctx sessionctx.Context) {
+	for _, item := range byItems {
+		item.Expr = expression.CloneWithCtx(sctx, item.Expr)
+	}
+}
+
+func bindAggCtx(agg *basePhysicalAgg, sctx sessionctx.Context) {
+	agg.GroupByItems = expression.CloneExprsWithCtx(sctx, agg.GroupByItems)
+	for _, aggFunc := range agg.AggFuncs {
+		aggFunc.Args = expression.CloneExprsWithCtx(sctx, aggFunc.Args)
+		bindByItemsCtx(aggFunc.OrderByItems, sctx)
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/pingcap/failpoint"
 	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/domain"
+	"github.com/pingcap/tidb/errno"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/parser/auth"
@@ -6505,3 +6506,94 @@ func TestIssue29663(t *testing.T) {
 		"        └─Selection_25 2.00 cop[tikv]  eq(test.t2.c, test.t1.b)",
 		"          └─TableFullScan_24 2000.00 cop[tikv] table:two keep order:false, stats:pseudo"))
 }
+
+func TestHypoIndex(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b int, c int, key ic(c))")
+	tk.MustExec("insert into t values (1, 1, 1), (2, 2, 2), (3, 3, 3), (4, 4, 4), (5, 5, 5), (6, 6, 6), (7, 7, 7), (8, 8, 8)")
+	tk.MustExec("analyze table t")
+
+	tk.MustExec("create index iab on t (a, b) type hypo")
+	tk.MustGetErrCode("create index iab on t (a) type hypo", mysql.ErrDupKeyName)
+	tk.MustExec("create index if not exists iab on t (a) type hypo")
+	tk.MustGetErrCode("create index ic on t (a) type hypo", mysql.ErrDupKeyName)
+	tk.MustGetErrCode("create index id on t (d) type hypo", mysql.ErrKeyColumnDoesNotExits)
+	tk.MustGetErrCode("alter table t add index ia (a) using hypo", errno.ErrUnsupportedDDLOperation)
+	tk.MustGetErrCode("create table t2 (a int, key ia (a) using hypo)", errno.ErrUnsupportedDDLOperation)
+
+	// The hypothetical index is only visible to EXPLAIN, and its row count is derived from the column histograms.
+	tk.MustQuery("explain format = 'brief' select * from t where a = 1 and b = 1").Check(testkit.Rows(
+		"IndexLookUp 0.12 root  ",
+		"├─IndexRangeScan(Build) 0.12 cop[tikv] table:t, index:iab(a, b) range:[1 1,1 1], keep order:false",
+		"└─TableRowIDScan(Probe) 0.12 cop[tikv] table:t keep order:false"))
+	rows := tk.MustQuery("explain analyze select * from t where a = 1 and b = 1").Rows()
+	require.NotContains(t, fmt.Sprintf("%v", rows), "iab")
+	tk.MustQuery("select * from t where a = 1 and b = 1").Check(testkit.Rows("1 1 1"))
+	tk.MustQuery("show index from t").Check(testkit.Rows(
+		"t 1 ic 1 c A 0 <nil> <nil> YES BTREE   YES <nil> NO"))
+
+	// Hypothetical indexes are session-scoped.
+	tk2 := testkit.NewTestKit(t, store)
+	tk2.MustExec("use test")
+	rows = tk2.MustQuery("explain format = 'brief' select * from t where a = 1 and b = 1").Rows()
+	require.NotContains(t, fmt.Sprintf("%v", rows), "iab")
+
+	tk.MustExec("drop index iab on t")
+	rows = tk.MustQuery("explain format = 'brief' select * from t where a = 1 and b = 1").Rows()
+	require.NotContains(t, fmt.Sprintf("%v", rows), "iab")
+	tk.MustGetErrCode("drop index iab on t", mysql.ErrCantDropFieldOrKey)
+}
+
+func TestOuterJoinReorder(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists f, s, d1, d2")
+	tk.MustExec("create table f (id int, d1 int, d2 int, v int)")
+	tk.MustExec("create table s (fid int, k int)")
+	tk.MustExec("create table d1 (id int, x int)")
+	tk.MustExec("create table d2 (id int, y int)")
+	for i := 0; i < 100; i++ {
+		tk.MustExec(fmt.Sprintf("insert into f values (%d, %d, %d, %d)", i, i%20, i%30, i))
+	}
+	for i := 0; i < 50; i++ {
+		tk.MustExec(fmt.Sprintf("insert into s values (%d, %d)", i*2, i%25))
+	}
+	for i := 0; i < 15; i++ {
+		tk.MustExec(fmt.Sprintf("insert into d1 values (%d, %d)", i, i%10))
+		tk.MustExec(fmt.Sprintf("insert into d2 values (%d, %d)", i*2, i))
+	}
+	tk.MustExec("analyze table f, s, d1, d2")
+
+	var input []string
+	var output []struct {
+		SQL    string
+		Greedy []string
+		DP     []string
+	}
+	integrationSuiteData := core.GetIntegrationSuiteData()
+	integrationSuiteData.GetTestCases(t, &input, &output)
+	for i, tt := range input {
+		tk.MustExec("set @@tidb_enable_outer_join_reorder = 0")
+		expected := tk.MustQuery(tt).Sort().Rows()
+		tk.MustExec("set @@tidb_enable_outer_join_reorder = 1")
+		tk.MustExec("set @@tidb_opt_join_reorder_threshold = 0")
+		testdata.OnRecord(func() {
+			output[i].SQL = tt
+			output[i].Greedy = testdata.ConvertRowsToStrings(tk.MustQuery("explain format = 'brief' " + tt).Rows())
+		})
+		tk.MustQuery("explain format = 'brief' " + tt).Check(testkit.Rows(output[i].Greedy...))
+		tk.MustQuery(tt).Sort().Check(expected)
+		tk.MustExec("set @@tidb_opt_join_reorder_threshold = 10")
+		testdata.OnRecord(func() {
+			output[i].DP = testdata.ConvertRowsToStrings(tk.MustQuery("explain format = 'brief' " + tt).Rows())
+		})
+		tk.MustQuery("explain format = 'brief' " + tt).Check(testkit.Rows(output[i].DP...))
+		tk.MustQuery(tt).Sort().Check(expected)
+	}
+}
@@ -4082,6 +4082,11 @@ func (b *PlanBuilder) buildDataSource(ctx context.Context, tn *ast.TableName, as
 		// Use the new partition implementation, clean up the code here when it's full implemented.
 		if !b.ctx.GetSessionVars().UseDynamicPartitionPrune() {
 			b.optFlag = b.optFlag | flagPartitionProcessor
+			// The partitions pruned in static mode are a part of the plan, so the plan can't be
+			// reused with other parameters.
+			if b.ctx.GetSessionVars().StmtCtx.UseCache {
+				b.ctx.GetSessionVars().StmtCtx.SkipPlanCache = true
+			}
 		}
 
 		pt := tbl.(table.PartitionedTable)
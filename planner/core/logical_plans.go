@@ -1392,7 +1392,15 @@ func (ds *DataSource) fillIndexPath(path *util.AccessPath, conds []expression.Ex
 				path.ConstCols[i] = res.ColumnValues[i] != nil
 			}
 		}
-		path.CountAfterAccess, err = ds.tableStats.HistColl.GetRowCountByIndexRanges(ds.ctx, path.Index.ID, path.Ranges)
+		if path.Index.Tp == model.IndexTypeHypo {
+			colIDs := make([]int64, 0, len(path.IdxCols))
+			for _, col := range path.IdxCols {
+				colIDs = append(colIDs, col.UniqueID)
+			}
+			path.CountAfterAccess, err = ds.tableStats.HistColl.GetRowCountByHypoIndexRanges(ds.ctx, colIDs, path.Ranges)
+		} else {
+			path.CountAfterAccess, err = ds.tableStats.HistColl.GetRowCountByIndexRanges(ds.ctx, path.Index.ID, path.Ranges)
+		}
 		if err != nil {
 			return err
 		}
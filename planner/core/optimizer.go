@@ -363,6 +363,7 @@ func postOptimize(sctx sessionctx.Context, plan PhysicalPlan) PhysicalPlan {
 	mergeContinuousSelections(plan)
 	plan = eliminateUnionScanAndLock(sctx, plan)
 	plan = enableParallelApply(sctx, plan)
+	generateRuntimeFilters(sctx, plan)
 	checkPlanCacheable(sctx, plan)
 	return plan
 }
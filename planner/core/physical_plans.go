@@ -200,6 +200,7 @@ func (p *PhysicalTableReader) Clone() (PhysicalPlan, error) {
 	cloned.StoreType = p.StoreType
 	cloned.ReadReqType = p.ReadReqType
 	cloned.IsCommonHandle = p.IsCommonHandle
+	cloned.PartitionInfo = p.PartitionInfo
 	if cloned.tablePlan, err = p.tablePlan.Clone(); err != nil {
 		return nil, err
 	}
@@ -253,6 +254,7 @@ func (p *PhysicalIndexReader) Clone() (PhysicalPlan, error) {
 		return nil, err
 	}
 	cloned.OutputColumns = cloneCols(p.OutputColumns)
+	cloned.PartitionInfo = p.PartitionInfo
 	return cloned, err
 }
 
@@ -350,6 +352,11 @@ func (p *PhysicalIndexLookUpReader) Clone() (PhysicalPlan, error) {
 	if p.PushedLimit != nil {
 		cloned.PushedLimit = p.PushedLimit.Clone()
 	}
+	cloned.Paging = p.Paging
+	cloned.CommonHandleCols = cloneCols(p.CommonHandleCols)
+	cloned.PartitionInfo = p.PartitionInfo
+	cloned.expectedCnt = p.expectedCnt
+	cloned.keepOrder = p.keepOrder
 	return cloned, nil
 }
 
@@ -822,6 +829,21 @@ type PhysicalHashJoin struct {
 	// on which store the join executes.
 	storeTp        kv.StoreType
 	mppShuffleJoin bool
+
+	// RuntimeFilters are pushed into the table reader on the probe side after the build phase.
+	RuntimeFilters []*RuntimeFilter
+}
+
+// RuntimeFilter is built from a join key of the build side of a hash join after the build phase,
+// and pushed into the coprocessor requests of the table reader on the probe side, so that the
+// scan can skip the rows which can never be joined.
+type RuntimeFilter struct {
+	// KeyIdx is the offset of the join key in the equal conditions.
+	KeyIdx int
+	// BuildKey is the join key of the build side.
+	BuildKey *expression.Column
+	// TargetColumn is the join key of the probe side, resolved against the schema of the table scan.
+	TargetColumn *expression.Column
 }
 
 // Clone implements PhysicalPlan interface.
@@ -837,6 +859,13 @@ func (p *PhysicalHashJoin) Clone() (PhysicalPlan, error) {
 	for _, c := range p.EqualConditions {
 		cloned.EqualConditions = append(cloned.EqualConditions, c.Clone().(*expression.ScalarFunction))
 	}
+	for _, rf := range p.RuntimeFilters {
+		cloned.RuntimeFilters = append(cloned.RuntimeFilters, &RuntimeFilter{
+			KeyIdx:       rf.KeyIdx,
+			BuildKey:     rf.BuildKey.Clone().(*expression.Column),
+			TargetColumn: rf.TargetColumn.Clone().(*expression.Column),
+		})
+	}
 	return cloned, nil
 }
 
@@ -1095,6 +1124,8 @@ func (p *basePhysicalAgg) cloneWithSelf(newSelf PhysicalPlan) (*basePhysicalAgg,
 		cloned.AggFuncs = append(cloned.AggFuncs, aggDesc.Clone())
 	}
 	cloned.GroupByItems = cloneExprs(p.GroupByItems)
+	cloned.MppRunMode = p.MppRunMode
+	cloned.MppPartitionCols = append(cloned.MppPartitionCols, p.MppPartitionCols...)
 	return cloned, nil
 }
 
@@ -57,6 +57,9 @@ type Plan interface {
 
 	SCtx() sessionctx.Context
 
+	// setSCtx binds the plan to another session, it's only used on the cloned plans.
+	setSCtx(ctx sessionctx.Context)
+
 	// property.StatsInfo will return the property.StatsInfo for this plan.
 	statsInfo() *property.StatsInfo
 
@@ -723,6 +726,11 @@ func (p *basePlan) SCtx() sessionctx.Context {
 	return p.ctx
 }
 
+// setSCtx implements Plan setSCtx interface.
+func (p *basePlan) setSCtx(ctx sessionctx.Context) {
+	p.ctx = ctx
+}
+
 // buildPlanTrace implements Plan
 func (p *basePhysicalPlan) buildPlanTrace() *tracing.PlanTrace {
 	planTrace := &tracing.PlanTrace{ID: p.ID(), TP: p.TP(), ExplainInfo: p.self.ExplainInfo(), Cost: p.Cost()}
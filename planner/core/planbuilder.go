@@ -20,6 +20,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"math"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -36,6 +37,7 @@ import (
 	"github.com/pingcap/tidb/parser/ast"
 	"github.com/pingcap/tidb/parser/auth"
 	"github.com/pingcap/tidb/parser/charset"
+	"github.com/pingcap/tidb/parser/format"
 	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/parser/opcode"
@@ -689,6 +691,8 @@ func (b *PlanBuilder) Build(ctx context.Context, node ast.Node) (Plan, error) {
 		return b.buildLoadStats(x), nil
 	case *ast.IndexAdviseStmt:
 		return b.buildIndexAdvise(x), nil
+	case *ast.RecommendIndexStmt:
+		return b.buildRecommendIndex(x), nil
 	case *ast.PlanReplayerStmt:
 		return b.buildPlanReplayer(x), nil
 	case *ast.PrepareStmt:
@@ -716,7 +720,7 @@ func (b *PlanBuilder) Build(ctx context.Context, node ast.Node) (Plan, error) {
 		*ast.BeginStmt, *ast.CommitStmt, *ast.RollbackStmt, *ast.CreateUserStmt, *ast.SetPwdStmt, *ast.AlterInstanceStmt,
 		*ast.GrantStmt, *ast.DropUserStmt, *ast.AlterUserStmt, *ast.RevokeStmt, *ast.KillStmt, *ast.DropStatsStmt,
 		*ast.GrantRoleStmt, *ast.RevokeRoleStmt, *ast.SetRoleStmt, *ast.SetDefaultRoleStmt, *ast.ShutdownStmt,
-		*ast.RenameUserStmt:
+		*ast.RenameUserStmt, *ast.SetSessionStatesStmt:
 		return b.buildSimple(ctx, node.(ast.StmtNode))
 	case ast.DDLNode:
 		return b.buildDDL(ctx, x)
@@ -1122,6 +1126,9 @@ func getPossibleAccessPaths(ctx sessionctx.Context, tableHints *tableHintInfo, i
 			publicPaths = append(publicPaths, &util.AccessPath{Index: index})
 		}
 	}
+	if sc := ctx.GetSessionVars().StmtCtx; sc.InExplainStmt && !sc.InExplainAnalyzeStmt {
+		publicPaths = append(publicPaths, getHypoIndexPaths(ctx, tblInfo, dbName)...)
+	}
 
 	hasScanHint, hasUseOrForce := false, false
 	available := make([]*util.AccessPath, 0, len(publicPaths))
@@ -1209,6 +1216,48 @@ func getPossibleAccessPaths(ctx sessionctx.Context, tableHints *tableHintInfo, i
 	return available, nil
 }
 
+// getHypoIndexPaths returns the access paths of the hypothetical indexes created on the table in this session.
+// The hypothetical indexes are matched with the latest table meta, the ones whose columns don't exist any more are skipped.
+func getHypoIndexPaths(ctx sessionctx.Context, tblInfo *model.TableInfo, dbName model.CIStr) []*util.AccessPath {
+	hypoIndexes := ctx.GetSessionVars().HypoIndexes[dbName.L][tblInfo.Name.L]
+	if len(hypoIndexes) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(hypoIndexes))
+	for name := range hypoIndexes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	paths := make([]*util.AccessPath, 0, len(names))
+	for _, name := range names {
+		// The real index takes precedence over the hypothetical one with the same name.
+		if tblInfo.FindIndexByName(name) != nil {
+			continue
+		}
+		index := hypoIndexes[name].Clone()
+		valid := true
+		for _, idxCol := range index.Columns {
+			col := model.FindColumnInfo(tblInfo.Columns, idxCol.Name.L)
+			if col == nil || col.State != model.StatePublic {
+				valid = false
+				break
+			}
+			idxCol.Offset = col.Offset
+		}
+		if !valid {
+			continue
+		}
+		// Hypothetical indexes have no statistics, give them IDs which never collide with the real indexes.
+		index.ID = tblInfo.MaxIndexID + int64(len(paths)) + 1
+		paths = append(paths, &util.AccessPath{Index: index})
+	}
+	if len(paths) > 0 && ctx.GetSessionVars().StmtCtx.UseCache {
+		ctx.GetSessionVars().StmtCtx.SkipPlanCache = true
+	}
+	return paths
+}
+
 func filterPathByIsolationRead(ctx sessionctx.Context, paths []*util.AccessPath, tblName model.CIStr, dbName model.CIStr) ([]*util.AccessPath, error) {
 	// TODO: filter paths with isolation read locations.
 	if dbName.L == mysql.SystemDB {
@@ -1422,6 +1471,12 @@ func (b *PlanBuilder) buildAdmin(ctx context.Context, as *ast.AdminStmt) (Plan,
 		return &Simple{Statement: as}, nil
 	case ast.AdminFlushPlanCache:
 		return &Simple{Statement: as}, nil
+	case ast.AdminShowPlanRegressions:
+		p := &AdminShowPlanRegressions{}
+		p.setSchemaAndNames(buildShowPlanRegressionsSchema())
+		ret = p
+	case ast.AdminRejectPlanRegression:
+		ret = &AdminRejectPlanRegression{SQLDigest: as.SQLDigest}
 	default:
 		return nil, ErrUnsupportedType.GenWithStack("Unsupported ast.AdminStmt(%T) for buildAdmin", as)
 	}
@@ -2105,6 +2160,20 @@ func (b *PlanBuilder) buildAnalyzeFullSamplingTask(
 	statsHandle := domain.GetDomain(b.ctx).StatsHandle()
 	// If the statistics of the table is version 1, we must analyze all columns to overwrites all of old statistics.
 	mustAllColumns := !statsHandle.CheckAnalyzeVersion(tbl.TableInfo, physicalIDs, &ver)
+	exprStrs, exprColIDs, err := b.getAnalyzeExprStrings(as, tbl)
+	if err != nil {
+		return nil, err
+	}
+	if len(exprColIDs) > 0 {
+		// The columns used by the expressions must be sampled to evaluate the expressions.
+		mustAnalyzed, err := b.getMustAnalyzedColumns(tbl, &mustAnalyzedCols)
+		if err != nil {
+			return nil, err
+		}
+		for colID := range exprColIDs {
+			mustAnalyzed[colID] = struct{}{}
+		}
+	}
 	astColsInfo, _, err := b.getFullAnalyzeColumnsInfo(tbl, as.ColumnChoice, astColList, &predicateCols, &mustAnalyzedCols, mustAllColumns, true)
 	if err != nil {
 		return nil, err
@@ -2147,6 +2216,7 @@ func (b *PlanBuilder) buildAnalyzeFullSamplingTask(
 			TblInfo:     tbl.TableInfo,
 			Indexes:     indexes,
 		}
+		newTask.Exprs, newTask.ExprsInfo = b.buildAnalyzeExprs(tbl, execColsInfo, exprStrs)
 		if newTask.HandleCols == nil {
 			extraCol := model.NewExtraHandleColInfo()
 			// Always place _tidb_rowid at the end of colsInfo, this is corresponding to logics in `analyzeColumnsPushdown`.
@@ -2158,6 +2228,117 @@ func (b *PlanBuilder) buildAnalyzeFullSamplingTask(
 	return taskSlice, nil
 }
 
+// getAnalyzeExprStrings returns the expressions whose statistics need to be collected, including the ones specified in
+// `ANALYZE TABLE ... EXPRESSIONS` and the ones analyzed before. The second return value is the IDs of the columns used by them.
+func (b *PlanBuilder) getAnalyzeExprStrings(as *ast.AnalyzeTableStmt, tbl *ast.TableName) ([]string, map[int64]struct{}, error) {
+	tblInfo := tbl.TableInfo
+	if tblInfo.GetPartitionInfo() != nil {
+		if len(as.Expressions) > 0 {
+			return nil, nil, errors.Errorf("Expression statistics on partitioned tables are not supported now")
+		}
+		return nil, nil, nil
+	}
+	statsTbl := domain.GetDomain(b.ctx).StatsHandle().GetTableStats(tblInfo)
+	if len(as.Expressions) == 0 && len(statsTbl.ExprStats) == 0 {
+		return nil, nil, nil
+	}
+	columns, names, err := expression.ColumnInfos2ColumnsAndNames(b.ctx, tbl.Schema, tbl.Name, tblInfo.Columns, tblInfo)
+	if err != nil {
+		return nil, nil, err
+	}
+	schema := expression.NewSchema(columns...)
+	exprStrs := make([]string, 0, len(as.Expressions))
+	colIDs := make(map[int64]struct{})
+	addExpr := func(exprStr string, expr expression.Expression) {
+		for _, s := range exprStrs {
+			if s == exprStr {
+				return
+			}
+		}
+		exprStrs = append(exprStrs, exprStr)
+		for _, col := range expression.ExtractColumns(expr) {
+			colIDs[col.ID] = struct{}{}
+		}
+	}
+	for _, node := range as.Expressions {
+		expr, err := expression.RewriteAstExpr(b.ctx, node, schema, names)
+		if err != nil {
+			return nil, nil, err
+		}
+		// JSON_EXTRACT is analyzed on its unquoted scalar form, which is the same as `->>`. The conditions on
+		// JSON_EXTRACT are rewritten to this form when estimating, see (*HistColl).normalizeJSONExtractCmp.
+		if sf, ok := expr.(*expression.ScalarFunction); ok && sf.FuncName.L == ast.JSONExtract {
+			node = &ast.FuncCallExpr{FnName: model.NewCIStr(ast.JSONUnquote), Args: []ast.ExprNode{node}}
+			if expr, err = expression.RewriteAstExpr(b.ctx, node, schema, names); err != nil {
+				return nil, nil, err
+			}
+		}
+		var sb strings.Builder
+		if err := node.Restore(format.NewRestoreCtx(format.DefaultRestoreFlags, &sb)); err != nil {
+			return nil, nil, err
+		}
+		exprStr := sb.String()
+		if _, ok := expr.(*expression.ScalarFunction); !ok || len(expression.ExtractColumns(expr)) == 0 {
+			return nil, nil, errors.Errorf("The expression %s to analyze must be a function of the table columns", exprStr)
+		}
+		if expression.CheckNonDeterministic(expr) || expression.IsMutableEffectsExpr(expr) {
+			return nil, nil, errors.Errorf("The expression %s to analyze must be deterministic", exprStr)
+		}
+		if expr.GetType().EvalType() == types.ETJson {
+			return nil, nil, errors.Errorf("The expression %s to analyze returns JSON, use ->> to extract a scalar value instead", exprStr)
+		}
+		addExpr(exprStr, expr)
+	}
+	// Refresh the statistics of the expressions analyzed before.
+	oldExprStrs := make([]string, 0, len(statsTbl.ExprStats))
+	for _, col := range statsTbl.ExprStats {
+		oldExprStrs = append(oldExprStrs, col.Info.GeneratedExprString)
+	}
+	sort.Strings(oldExprStrs)
+	for _, exprStr := range oldExprStrs {
+		exprs, err := expression.ParseSimpleExprsWithNames(b.ctx, exprStr, schema, names)
+		if err != nil {
+			continue
+		}
+		addExpr(exprStr, exprs[0])
+	}
+	return exprStrs, colIDs, nil
+}
+
+// buildAnalyzeExprs builds the expressions to analyze on the sampled columns and the hidden virtual columns carrying
+// their statistics. The expressions which can't be built on the sampled columns are skipped.
+func (b *PlanBuilder) buildAnalyzeExprs(tbl *ast.TableName, colsInfo []*model.ColumnInfo, exprStrs []string) ([]expression.Expression, []*model.ColumnInfo) {
+	if len(exprStrs) == 0 {
+		return nil, nil
+	}
+	columns := make([]*expression.Column, 0, len(colsInfo))
+	names := make(types.NameSlice, 0, len(colsInfo))
+	for i, colInfo := range colsInfo {
+		col := colInfoToColumn(colInfo, i)
+		col.UniqueID = b.ctx.GetSessionVars().AllocPlanColumnID()
+		columns = append(columns, col)
+		names = append(names, &types.FieldName{
+			DBName:      tbl.Schema,
+			TblName:     tbl.Name,
+			OrigTblName: tbl.Name,
+			ColName:     colInfo.Name,
+			OrigColName: colInfo.Name,
+		})
+	}
+	schema := expression.NewSchema(columns...)
+	exprs := make([]expression.Expression, 0, len(exprStrs))
+	exprsInfo := make([]*model.ColumnInfo, 0, len(exprStrs))
+	for _, exprStr := range exprStrs {
+		parsed, err := expression.ParseSimpleExprsWithNames(b.ctx, exprStr, schema, names)
+		if err != nil {
+			continue
+		}
+		exprs = append(exprs, parsed[0])
+		exprsInfo = append(exprsInfo, statistics.NewExprStatsColumnInfo(exprStr, parsed[0].GetType()))
+	}
+	return exprs, exprsInfo
+}
+
 func (b *PlanBuilder) genV2AnalyzeOptions(
 	persist bool,
 	tbl *ast.TableName,
@@ -2339,6 +2520,9 @@ func (b *PlanBuilder) buildAnalyzeTable(as *ast.AnalyzeTableStmt, opts map[ast.A
 		if as.ColumnChoice == model.ColumnList {
 			return nil, errors.Errorf("Only the analyze version 2 supports analyzing the specified columns")
 		}
+		if len(as.Expressions) > 0 {
+			return nil, errors.Errorf("Only the analyze version 2 supports analyzing expressions")
+		}
 		for _, idx := range idxInfo {
 			// For prefix common handle. We don't use analyze mixed to handle it with columns. Because the full value
 			// is read by coprocessor, the prefix index would get wrong stats in this case.
@@ -2821,6 +3005,21 @@ func buildShowTelemetrySchema() (*expression.Schema, types.NameSlice) {
 	return schema.col2Schema(), schema.names
 }
 
+func buildShowPlanRegressionsSchema() (*expression.Schema, types.NameSlice) {
+	schema := newColumnsWithNames(10)
+	schema.Append(buildColumnWithName("", "SQL_DIGEST", mysql.TypeVarchar, 64))
+	schema.Append(buildColumnWithName("", "DEFAULT_DB", mysql.TypeVarchar, mysql.MaxBlobWidth))
+	schema.Append(buildColumnWithName("", "ORIGINAL_SQL", mysql.TypeBlob, mysql.MaxBlobWidth))
+	schema.Append(buildColumnWithName("", "BIND_SQL", mysql.TypeBlob, mysql.MaxBlobWidth))
+	schema.Append(buildColumnWithName("", "OLD_PLAN_DIGEST", mysql.TypeVarchar, 64))
+	schema.Append(buildColumnWithName("", "NEW_PLAN_DIGEST", mysql.TypeVarchar, 64))
+	schema.Append(buildColumnWithName("", "OLD_AVG_LATENCY", mysql.TypeLonglong, 20))
+	schema.Append(buildColumnWithName("", "NEW_AVG_LATENCY", mysql.TypeLonglong, 20))
+	schema.Append(buildColumnWithName("", "DETECT_TIME", mysql.TypeDatetime, 26))
+	schema.Append(buildColumnWithName("", "STATUS", mysql.TypeVarchar, 8))
+	return schema.col2Schema(), schema.names
+}
+
 func buildColumnWithName(tableName, name string, tp byte, size int) (*expression.Column, *types.FieldName) {
 	cs, cl := types.DefaultCharsetForType(tp)
 	flag := mysql.UnsignedFlag
@@ -3887,6 +4086,25 @@ func (b *PlanBuilder) buildIndexAdvise(node *ast.IndexAdviseStmt) Plan {
 	return p
 }
 
+func (b *PlanBuilder) buildRecommendIndex(node *ast.RecommendIndexStmt) Plan {
+	p := &RecommendIndex{SQL: node.SQL}
+	schema := newColumnsWithNames(8)
+	schema.Append(buildColumnWithName("", "Database", mysql.TypeVarchar, 64))
+	schema.Append(buildColumnWithName("", "Table", mysql.TypeVarchar, 64))
+	schema.Append(buildColumnWithName("", "Index_name", mysql.TypeVarchar, 64))
+	schema.Append(buildColumnWithName("", "Index_columns", mysql.TypeVarchar, 256))
+	schema.Append(buildColumnWithName("", "Est_cost_reduction", mysql.TypeDouble, 22))
+	schema.Append(buildColumnWithName("", "Impacted_queries", mysql.TypeLonglong, 21))
+	schema.Append(buildColumnWithName("", "Top_impacted_query", mysql.TypeBlob, 65535))
+	schema.Append(buildColumnWithName("", "Create_index_statement", mysql.TypeVarchar, 512))
+	p.SetSchema(schema.col2Schema())
+	p.names = schema.names
+	// The workload in the statement summary contains the statements of all users.
+	err := ErrSpecificAccessDenied.GenWithStackByArgs("PROCESS")
+	b.visitInfo = appendVisitInfo(b.visitInfo, mysql.ProcessPriv, "", "", "", err)
+	return p
+}
+
 func (b *PlanBuilder) buildSplitRegion(node *ast.SplitRegionStmt) (Plan, error) {
 	if node.Table.TableInfo.TempTableType != model.TempTableNone {
 		return nil, ErrOptOnTemporaryTable.GenWithStackByArgs("split table")
@@ -4381,6 +4599,9 @@ func (b *PlanBuilder) buildDDL(ctx context.Context, node ast.DDLNode) (Plan, err
 	case *ast.DropPlacementPolicyStmt, *ast.CreatePlacementPolicyStmt, *ast.AlterPlacementPolicyStmt:
 		err := ErrSpecificAccessDenied.GenWithStackByArgs("SUPER or PLACEMENT_ADMIN")
 		b.visitInfo = appendDynamicVisitInfo(b.visitInfo, "PLACEMENT_ADMIN", false, err)
+	case *ast.CreateResourceGroupStmt, *ast.AlterResourceGroupStmt, *ast.DropResourceGroupStmt:
+		err := ErrSpecificAccessDenied.GenWithStackByArgs("SUPER or RESOURCE_GROUP_ADMIN")
+		b.visitInfo = appendDynamicVisitInfo(b.visitInfo, "RESOURCE_GROUP_ADMIN", false, err)
 	}
 	p := &DDL{Statement: node}
 	return p, nil
@@ -4736,6 +4957,9 @@ func buildShowSchema(s *ast.ShowStmt, isView bool, isSequence bool) (schema *exp
 	case ast.ShowPlacementLabels:
 		names = []string{"Key", "Values"}
 		ftypes = []byte{mysql.TypeVarchar, mysql.TypeJSON}
+	case ast.ShowSessionStates:
+		names = []string{"Session_states"}
+		ftypes = []byte{mysql.TypeVarchar}
 	case ast.ShowPlacement, ast.ShowPlacementForDatabase, ast.ShowPlacementForTable, ast.ShowPlacementForPartition:
 		names = []string{"Target", "Placement", "Scheduling_State"}
 		ftypes = []byte{mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar}
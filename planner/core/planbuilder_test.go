@@ -343,6 +343,35 @@ func TestPhysicalPlanClone(t *testing.T) {
 	mergeJoin = mergeJoin.Init(ctx, stats, 0)
 	mergeJoin.SetSchema(schema)
 	require.NoError(t, checkPhysicalPlanClone(mergeJoin))
+
+	// point get
+	pointGet := &PointGetPlan{
+		schema:           schema,
+		TblInfo:          tblInfo,
+		IndexInfo:        idxInfo,
+		HandleConstant:   cst,
+		IndexValues:      []types.Datum{types.NewIntDatum(1)},
+		IndexConstants:   []*expression.Constant{cst, nil},
+		IdxCols:          []*expression.Column{col},
+		IdxColLens:       []int{1},
+		AccessConditions: []expression.Expression{col, cst},
+	}
+	pointGet = pointGet.Init(ctx, stats, 0)
+	require.NoError(t, checkPhysicalPlanClone(pointGet))
+
+	// batch point get
+	batchPointGet := &BatchPointGetPlan{
+		TblInfo:          tblInfo,
+		IndexInfo:        idxInfo,
+		HandleParams:     []*expression.Constant{cst, nil},
+		IndexValues:      [][]types.Datum{{types.NewIntDatum(1)}, {types.NewIntDatum(2)}},
+		IndexValueParams: [][]*expression.Constant{{cst}, {nil}},
+		IdxCols:          []*expression.Column{col},
+		IdxColLens:       []int{1},
+		AccessConditions: []expression.Expression{col, cst},
+	}
+	batchPointGet = batchPointGet.Init(ctx, stats, schema, nil, 0)
+	require.NoError(t, checkPhysicalPlanClone(batchPointGet))
 }
 
 //go:linkname valueInterface reflect.valueInterface
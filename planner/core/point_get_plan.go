@@ -78,8 +78,10 @@ type PointGetPlan struct {
 	outputNames        []*types.FieldName
 	LockWaitTime       int64
 	partitionColumnPos int
-	Columns            []*model.ColumnInfo
-	cost               float64
+	// partitionNames are the partitions selected by the PARTITION clause.
+	partitionNames []model.CIStr
+	Columns        []*model.ColumnInfo
+	cost           float64
 }
 
 type nameValuePair struct {
@@ -117,7 +119,30 @@ func (p *PointGetPlan) ToPB(ctx sessionctx.Context, _ kv.StoreType) (*tipb.Execu
 
 // Clone implements PhysicalPlan interface.
 func (p *PointGetPlan) Clone() (PhysicalPlan, error) {
-	return nil, errors.Errorf("%T doesn't support cloning", p)
+	cloned := new(PointGetPlan)
+	*cloned = *p
+	if p.schema != nil {
+		cloned.schema = p.schema.Clone()
+	}
+	if p.TblInfo != nil {
+		cloned.TblInfo = p.TblInfo.Clone()
+	}
+	if p.IndexInfo != nil {
+		cloned.IndexInfo = p.IndexInfo.Clone()
+	}
+	if p.HandleConstant != nil {
+		cloned.HandleConstant = p.HandleConstant.Clone().(*expression.Constant)
+	}
+	cloned.IndexValues = cloneDatums(p.IndexValues)
+	cloned.IndexConstants = cloneConstants(p.IndexConstants)
+	cloned.IdxCols = cloneCols(p.IdxCols)
+	cloned.IdxColLens = append([]int(nil), p.IdxColLens...)
+	cloned.Columns = cloneColInfos(p.Columns)
+	// the nil access conditions mean the plan isn't generated by cbo.
+	if p.AccessConditions != nil {
+		cloned.AccessConditions = cloneExprs(p.AccessConditions)
+	}
+	return cloned, nil
 }
 
 // ExplainInfo implements Plan interface.
@@ -319,7 +344,41 @@ func (p *BatchPointGetPlan) SetCost(cost float64) {
 
 // Clone implements PhysicalPlan interface.
 func (p *BatchPointGetPlan) Clone() (PhysicalPlan, error) {
-	return nil, errors.Errorf("%T doesn't support cloning", p)
+	cloned := new(BatchPointGetPlan)
+	*cloned = *p
+	if p.schema != nil {
+		cloned.schema = p.schema.Clone()
+	}
+	if p.TblInfo != nil {
+		cloned.TblInfo = p.TblInfo.Clone()
+	}
+	if p.IndexInfo != nil {
+		cloned.IndexInfo = p.IndexInfo.Clone()
+	}
+	if p.Handles != nil {
+		cloned.Handles = append(make([]kv.Handle, 0, len(p.Handles)), p.Handles...)
+	}
+	cloned.HandleParams = cloneConstants(p.HandleParams)
+	if p.IndexValues != nil {
+		cloned.IndexValues = make([][]types.Datum, 0, len(p.IndexValues))
+		for _, values := range p.IndexValues {
+			cloned.IndexValues = append(cloned.IndexValues, cloneDatums(values))
+		}
+	}
+	if p.IndexValueParams != nil {
+		cloned.IndexValueParams = make([][]*expression.Constant, 0, len(p.IndexValueParams))
+		for _, params := range p.IndexValueParams {
+			cloned.IndexValueParams = append(cloned.IndexValueParams, cloneConstants(params))
+		}
+	}
+	cloned.IdxCols = cloneCols(p.IdxCols)
+	cloned.IdxColLens = append([]int(nil), p.IdxColLens...)
+	cloned.Columns = cloneColInfos(p.Columns)
+	// the nil access conditions mean the plan isn't generated by cbo.
+	if p.AccessConditions != nil {
+		cloned.AccessConditions = cloneExprs(p.AccessConditions)
+	}
+	return cloned, nil
 }
 
 // ExtractCorrelatedCols implements PhysicalPlan interface.
@@ -962,6 +1021,7 @@ func tryPointGetPlan(ctx sessionctx.Context, selStmt *ast.SelectStmt, check bool
 		p.handleFieldType = fieldType
 		p.HandleConstant = handlePair.con
 		p.PartitionInfo = partitionInfo
+		p.partitionNames = tblName.PartitionNames
 		return p
 	} else if handlePair.value.Kind() != types.KindNull {
 		return nil
@@ -1017,6 +1077,7 @@ func tryPointGetPlan(ctx sessionctx.Context, selStmt *ast.SelectStmt, check bool
 		p.PartitionInfo = partitionInfo
 		if p.PartitionInfo != nil {
 			p.partitionColumnPos = findPartitionIdx(idxInfo, pos, pairs)
+			p.partitionNames = tblName.PartitionNames
 		}
 		return p
 	}
@@ -1680,6 +1741,33 @@ func getPartitionInfo(ctx sessionctx.Context, tbl *model.TableInfo, pairs []name
 	return nil, 0, false
 }
 
+// relocatePartition locates the partition by the handle or the index values again, it's used when
+// the cached plan is rebuilt with new parameters.
+func (p *PointGetPlan) relocatePartition() error {
+	var pair nameValuePair
+	if p.IndexInfo == nil {
+		pkColInfo := p.TblInfo.GetPkColInfo()
+		if pkColInfo == nil {
+			return errors.New("failed to relocate the partition: no handle column")
+		}
+		pair = nameValuePair{colName: pkColInfo.Name.L, value: types.NewIntDatum(p.Handle.IntValue())}
+	} else {
+		pair = nameValuePair{
+			colName: p.IndexInfo.Columns[p.partitionColumnPos].Name.L,
+			value:   p.IndexValues[p.partitionColumnPos],
+		}
+	}
+	partitionInfo, _, _ := getPartitionInfo(p.SCtx(), p.TblInfo, []nameValuePair{pair})
+	if partitionInfo == nil {
+		return errors.New("failed to relocate the partition: no partition is matched")
+	}
+	if len(p.partitionNames) > 0 && !partitionNameInSet(partitionInfo.Name, p.partitionNames) {
+		return errors.New("failed to relocate the partition: the partition isn't selected")
+	}
+	p.PartitionInfo = partitionInfo
+	return nil
+}
+
 func findPartitionIdx(idxInfo *model.IndexInfo, pos int, pairs []nameValuePair) int {
 	for i, idxCol := range idxInfo.Columns {
 		if idxCol.Name.L == pairs[pos].colName {
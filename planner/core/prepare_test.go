@@ -35,6 +35,7 @@ import (
 	"github.com/pingcap/tidb/session"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/testkit"
+	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/hint"
 	"github.com/pingcap/tidb/util/kvcache"
 	"github.com/prometheus/client_golang/prometheus"
@@ -500,6 +501,173 @@ func TestPrepareCacheIndexScan(t *testing.T) {
 	tk.MustQuery("execute stmt1 using @a, @b").Check(testkit.Rows("1 3", "1 3"))
 }
 
+func TestInstancePlanCache(t *testing.T) {
+	store, dom, clean := testkit.CreateMockStoreAndDomain(t)
+	defer clean()
+	orgEnable := core.PreparedPlanCacheEnabled()
+	defer core.SetPreparedPlanCache(orgEnable)
+	core.SetPreparedPlanCache(true)
+	orgInstanceEnable := core.InstancePlanCacheEnabled()
+	defer core.SetInstancePlanCache(orgInstanceEnable)
+	core.SetInstancePlanCache(true)
+
+	newTestKit := func() (*testkit.TestKit, *kvcache.SimpleLRUCache) {
+		sessionCache := kvcache.NewSimpleLRUCache(100, 0.1, math.MaxUint64)
+		se, err := session.CreateSession4TestWithOpt(store, &session.Opt{PreparedPlanCache: sessionCache})
+		require.NoError(t, err)
+		tk := testkit.NewTestKitWithSession(t, store, se)
+		tk.MustExec("use test")
+		return tk, sessionCache
+	}
+	tk1, sessionCache1 := newTestKit()
+	tk2, sessionCache2 := newTestKit()
+	instanceCache := dom.InstancePlanCache()
+	instanceCache.DeleteAll()
+
+	tk1.MustExec("create table t(a int primary key, b int, c int, key(b))")
+	tk1.MustExec("insert into t values (1, 1, 10), (2, 2, 20), (3, 3, 30), (4, 4, 40)")
+	for _, tk := range []*testkit.TestKit{tk1, tk2} {
+		tk.MustExec(`prepare stmt from "select c from t where b > ? order by c"`)
+		tk.MustExec(`prepare join_stmt from "select t1.c from t t1 join t t2 on t1.a = t2.b where t1.b > ?"`)
+	}
+
+	// the plan built by tk1 is used by tk2 with its own parameter.
+	tk1.MustExec("set @p = 1")
+	tk1.MustQuery("execute stmt using @p").Check(testkit.Rows("20", "30", "40"))
+	tk1.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("0"))
+	require.Equal(t, 1, instanceCache.Size())
+	require.Greater(t, instanceCache.MemUsage(), uint64(0))
+	tk2.MustExec("set @p = 2")
+	tk2.MustQuery("execute stmt using @p").Check(testkit.Rows("30", "40"))
+	tk2.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("1"))
+	tk1.MustExec("set @p = 3")
+	tk1.MustQuery("execute stmt using @p").Check(testkit.Rows("40"))
+	tk1.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("1"))
+	require.Equal(t, 0, sessionCache1.Size())
+	require.Equal(t, 0, sessionCache2.Size())
+
+	// the shared plan isn't used when the table is modified in the transaction.
+	tk2.MustExec("begin")
+	tk2.MustExec("insert into t values (5, 5, 50)")
+	tk2.MustQuery("execute stmt using @p").Check(testkit.Rows("30", "40", "50"))
+	tk2.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("0"))
+	tk2.MustExec("rollback")
+	// the plan reading the uncommitted data is cached in the session.
+	require.Equal(t, 1, sessionCache2.Size())
+	sessionCache2.DeleteAll()
+
+	// the sessions with different optimizer variables don't share the plans.
+	tk2.MustExec("set @@tidb_opt_agg_push_down = 1")
+	tk2.MustQuery("execute stmt using @p").Check(testkit.Rows("30", "40"))
+	tk2.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("0"))
+	tk2.MustQuery("execute stmt using @p").Check(testkit.Rows("30", "40"))
+	tk2.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("1"))
+	require.Equal(t, 2, instanceCache.Size())
+	tk2.MustExec("set @@tidb_opt_agg_push_down = default")
+
+	// the plans which can't be shared are cached in the session.
+	tk1.MustExec("set @p = 1")
+	tk1.MustQuery("execute join_stmt using @p").Sort().Check(testkit.Rows("20", "30", "40"))
+	tk1.MustQuery("execute join_stmt using @p").Sort().Check(testkit.Rows("20", "30", "40"))
+	tk1.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("1"))
+	require.Equal(t, 1, sessionCache1.Size())
+	tk2.MustQuery("execute join_stmt using @p").Sort().Check(testkit.Rows("30", "40"))
+	tk2.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("0"))
+	require.Equal(t, 2, instanceCache.Size())
+
+	// the shared plans are dropped after the schema is changed.
+	tk1.MustExec("alter table t add column d int")
+	tk2.MustQuery("execute stmt using @p").Check(testkit.Rows("30", "40"))
+	tk2.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("0"))
+	tk1.MustQuery("execute stmt using @p").Check(testkit.Rows("20", "30", "40"))
+	tk1.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("1"))
+
+	tk1.MustExec("admin flush instance plan_cache")
+	require.Equal(t, 0, instanceCache.Size())
+	tk2.MustQuery("execute stmt using @p").Check(testkit.Rows("30", "40"))
+	tk2.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("0"))
+
+	// the memory quota is respected.
+	instanceCache.SetQuota(1)
+	require.Equal(t, 0, instanceCache.Size())
+	tk2.MustQuery("execute stmt using @p").Check(testkit.Rows("30", "40"))
+	tk2.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("0"))
+	require.Equal(t, 0, instanceCache.Size())
+}
+
+func TestInstancePlanCachePointGet(t *testing.T) {
+	store, dom, clean := testkit.CreateMockStoreAndDomain(t)
+	defer clean()
+	orgEnable := core.PreparedPlanCacheEnabled()
+	defer core.SetPreparedPlanCache(orgEnable)
+	core.SetPreparedPlanCache(true)
+	orgInstanceEnable := core.InstancePlanCacheEnabled()
+	defer core.SetInstancePlanCache(orgInstanceEnable)
+	core.SetInstancePlanCache(true)
+
+	newTestKit := func() (*testkit.TestKit, *kvcache.SimpleLRUCache) {
+		sessionCache := kvcache.NewSimpleLRUCache(100, 0.1, math.MaxUint64)
+		se, err := session.CreateSession4TestWithOpt(store, &session.Opt{PreparedPlanCache: sessionCache})
+		require.NoError(t, err)
+		tk := testkit.NewTestKitWithSession(t, store, se)
+		tk.MustExec("use test")
+		// the point gets in auto-commit transactions are cached by the prepared statements instead.
+		tk.MustExec("set @@autocommit = 0")
+		return tk, sessionCache
+	}
+	tk1, sessionCache1 := newTestKit()
+	tk2, sessionCache2 := newTestKit()
+	instanceCache := dom.InstancePlanCache()
+	instanceCache.DeleteAll()
+
+	tk1.MustExec("create table t(a int primary key, b int, c int, unique key(b))")
+	tk1.MustExec("insert into t values (1, 1, 10), (2, 2, 20), (3, 3, 30), (4, 4, 40)")
+	tk1.MustExec("commit")
+	stmts := []struct {
+		sql    string
+		rows   [3][][]interface{}
+		shared bool
+	}{
+		{"select c from t where a = ?", [3][][]interface{}{testkit.Rows("10"), testkit.Rows("20"), testkit.Rows("30")}, true},
+		{"select c from t where b = ?", [3][][]interface{}{testkit.Rows("10"), testkit.Rows("20"), testkit.Rows("30")}, true},
+		{"select c from t where a in (?, 4) order by c", [3][][]interface{}{testkit.Rows("10", "40"), testkit.Rows("20", "40"), testkit.Rows("30", "40")}, true},
+		{"select c from t where b in (?, 4) order by c", [3][][]interface{}{testkit.Rows("10", "40"), testkit.Rows("20", "40"), testkit.Rows("30", "40")}, true},
+		// the locking point gets read the lock wait time of the session.
+		{"select c from t where a = ? for update", [3][][]interface{}{testkit.Rows("10"), testkit.Rows("20"), testkit.Rows("30")}, false},
+	}
+	for _, stmt := range stmts {
+		instanceCache.DeleteAll()
+		sessionCache1.DeleteAll()
+		sessionCache2.DeleteAll()
+		for _, tk := range []*testkit.TestKit{tk1, tk2} {
+			tk.MustExec(fmt.Sprintf(`prepare stmt from "%s"`, stmt.sql))
+		}
+		tk1.MustExec("set @p = 1")
+		tk1.MustQuery("execute stmt using @p").Check(stmt.rows[0])
+		tk1.MustExec("commit")
+		// the plan built by tk1 is used by tk2 with its own parameter.
+		tk2.MustExec("set @p = 2")
+		tk2.MustQuery("execute stmt using @p").Check(stmt.rows[1])
+		if stmt.shared {
+			tk2.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("1"))
+		} else {
+			tk2.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("0"))
+		}
+		tk2.MustExec("commit")
+		tk1.MustExec("set @p = 3")
+		tk1.MustQuery("execute stmt using @p").Check(stmt.rows[2])
+		tk1.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("1"))
+		tk1.MustExec("commit")
+		if stmt.shared {
+			require.Equal(t, 1, instanceCache.Size(), stmt.sql)
+			require.Equal(t, 0, sessionCache1.Size(), stmt.sql)
+		} else {
+			require.Equal(t, 0, instanceCache.Size(), stmt.sql)
+			require.Equal(t, 1, sessionCache1.Size(), stmt.sql)
+		}
+	}
+}
+
 // dtype: tinyint, unsigned, float, decimal, year
 // rtype: null, valid, out-of-range, invalid, str, exists
 func randValue(tk *testkit.TestKit, tbl, col, dtype, rtype string) string {
@@ -1177,14 +1345,67 @@ func TestPrepareCacheForPartition(t *testing.T) {
 		tk.MustExec(`set @a=112, @b=-2, @c=-5, @d=33`)
 		tk.MustQuery(`execute stmt using @d,@a,@b,@c`).Check(testkit.Rows("-5 7 33"))
 		if pruneMode == string(variable.Dynamic) {
-			// When the temporary disabling of prepared plan cache for dynamic partition prune mode is disabled, change this to 1!
-			tk.MustQuery(`select @@last_plan_from_cache`).Check(testkit.Rows("0"))
+			tk.MustQuery(`select @@last_plan_from_cache`).Check(testkit.Rows("1"))
 		} else {
 			tk.MustQuery(`select @@last_plan_from_cache`).Check(testkit.Rows("0"))
 		}
 	}
 }
 
+func TestPrepareCacheRelocatePartition(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+	orgEnable := core.PreparedPlanCacheEnabled()
+	defer core.SetPreparedPlanCache(orgEnable)
+	core.SetPreparedPlanCache(true)
+	se, err := session.CreateSession4TestWithOpt(store, &session.Opt{
+		PreparedPlanCache: kvcache.NewSimpleLRUCache(100, 0.1, math.MaxUint64),
+	})
+	require.NoError(t, err)
+	tk := testkit.NewTestKitWithSession(t, store, se)
+	tk.MustExec("use test")
+	tk.MustExec("set @@tidb_partition_prune_mode = 'dynamic'")
+	tk.MustExec("create table t(a int primary key, b int, c int, key(c)) partition by range(a) (partition p0 values less than (10), partition p1 values less than (20))")
+	tk.MustExec("insert into t values (1, 1, 1), (11, 11, 11)")
+	tk.MustExec("create table th(a int, b int, unique key(a)) partition by hash(a) partitions 4")
+	tk.MustExec("insert into th values (1, 1), (2, 2), (3, 3)")
+
+	// the partitions of the cached point get plans are located again with the new parameters.
+	tk.MustExec("prepare stmt from 'select b from t where a = ?'")
+	tk.MustExec("set @a = 1")
+	tk.MustQuery("execute stmt using @a").Check(testkit.Rows("1"))
+	tk.MustExec("set @a = 11")
+	tk.MustQuery("execute stmt using @a").Check(testkit.Rows("11"))
+	tk.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("1"))
+	tk.MustExec("set @a = 21")
+	tk.MustQuery("execute stmt using @a").Check(testkit.Rows())
+	tk.MustExec("prepare stmt from 'select a from th where a = ?'")
+	for i := 1; i <= 3; i++ {
+		tk.MustExec(fmt.Sprintf("set @a = %v", i))
+		tk.MustQuery("execute stmt using @a").Check(testkit.Rows(strconv.Itoa(i)))
+	}
+	tk.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("1"))
+
+	// the plans selecting partitions aren't reused for the rows in other partitions.
+	tk.MustExec("prepare stmt from 'select b from t partition(p0) where a = ?'")
+	tk.MustExec("set @a = 1")
+	tk.MustQuery("execute stmt using @a").Check(testkit.Rows("1"))
+	tk.MustExec("set @a = 11")
+	tk.MustQuery("execute stmt using @a").Check(testkit.Rows())
+
+	// the plans built in static prune mode aren't cached.
+	tk.MustExec("prepare stmt from 'select b from t where c > ?'")
+	tk.MustExec("set @a = 5")
+	tk.MustQuery("execute stmt using @a").Check(testkit.Rows("11"))
+	tk.MustQuery("execute stmt using @a").Check(testkit.Rows("11"))
+	tk.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("1"))
+	tk.MustExec("set @@tidb_partition_prune_mode = 'static'")
+	tk.MustQuery("execute stmt using @a").Check(testkit.Rows("11"))
+	tk.MustExec("set @a = 0")
+	tk.MustQuery("execute stmt using @a").Check(testkit.Rows("1", "11"))
+	tk.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("0"))
+}
+
 func newSession(t *testing.T, store kv.Storage, dbName string) session.Session {
 	se, err := session.CreateSession4Test(store)
 	require.NoError(t, err)
@@ -2538,8 +2759,7 @@ func TestPartitionTable(t *testing.T) {
 		for i := 0; i < 100; i++ {
 			tk.MustExec(fmt.Sprintf("set @a=%v", tc.varGener()))
 			result1 := tk.MustQuery("execute stmt1 using @a").Sort().Rows()
-			// When https://github.com/pingcap/tidb/pull/33098 is reverted this should be 1 again
-			tk.MustQuery("select @@last_plan_from_cache /* i=" + strconv.Itoa(i) + " prepared statement: (t1) " + tc.query + "\n-- create table: " + tc.t1Create + "*/").Check(testkit.Rows("0"))
+			tk.MustQuery("select @@last_plan_from_cache /* i=" + strconv.Itoa(i) + " prepared statement: (t1) " + tc.query + "\n-- create table: " + tc.t1Create + "*/").Check(testkit.Rows("1"))
 			tk.MustQuery("execute stmt2 using @a").Sort().Check(result1)
 			tk.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("1"))
 		}
@@ -2650,16 +2870,10 @@ func TestPartitionWithVariedDataSources(t *testing.T) {
 		tk.MustExec(fmt.Sprintf(`set @a0=%v, @a1=%v, @a2=%v`, rand.Intn(40000), rand.Intn(40000), rand.Intn(40000)))
 
 		var rscan, rlookup, rpoint, rbatch [][]interface{}
-		var expectedFromPlanCache string
 		for id, tbl := range []string{"trangeIdx", "thashIdx", "tnormalIdx"} {
 			scan := tk.MustQuery(fmt.Sprintf(`execute stmt%v_indexscan using @mina, @maxa`, tbl)).Sort()
-			if id == 2 {
-				expectedFromPlanCache = "1"
-			} else {
-				expectedFromPlanCache = "0"
-			}
 			if i > 0 {
-				tk.MustQuery(`select @@last_plan_from_cache /* table: ` + tbl + " */").Check(testkit.Rows(expectedFromPlanCache))
+				tk.MustQuery(`select @@last_plan_from_cache /* table: ` + tbl + " */").Check(testkit.Rows("1"))
 			}
 			if id == 0 {
 				rscan = scan.Rows()
@@ -2669,7 +2883,7 @@ func TestPartitionWithVariedDataSources(t *testing.T) {
 
 			lookup := tk.MustQuery(fmt.Sprintf(`execute stmt%v_indexlookup using @mina, @maxa`, tbl)).Sort()
 			if i > 0 {
-				tk.MustQuery(`select @@last_plan_from_cache`).Check(testkit.Rows(expectedFromPlanCache))
+				tk.MustQuery(`select @@last_plan_from_cache`).Check(testkit.Rows("1"))
 			}
 			if id == 0 {
 				rlookup = lookup.Rows()
@@ -2678,10 +2892,8 @@ func TestPartitionWithVariedDataSources(t *testing.T) {
 			}
 
 			point := tk.MustQuery(fmt.Sprintf(`execute stmt%v_pointget_idx using @pointa`, tbl)).Sort()
-			if tbl == `tnormalPK` && i > 0 {
-				// PlanCache cannot support PointGet now since we haven't relocated partition after rebuilding range.
-				// Please see Execute.rebuildRange for more details.
-				tk.MustQuery(`select @@last_plan_from_cache`).Check(testkit.Rows(expectedFromPlanCache))
+			if i > 0 {
+				tk.MustQuery(`select @@last_plan_from_cache`).Check(testkit.Rows("1"))
 			}
 			if id == 0 {
 				rpoint = point.Rows()
@@ -2691,7 +2903,7 @@ func TestPartitionWithVariedDataSources(t *testing.T) {
 
 			batch := tk.MustQuery(fmt.Sprintf(`execute stmt%v_batchget_idx using @a0, @a1, @a2`, tbl)).Sort()
 			if i > 0 {
-				tk.MustQuery(`select @@last_plan_from_cache`).Check(testkit.Rows(expectedFromPlanCache))
+				tk.MustQuery(`select @@last_plan_from_cache`).Check(testkit.Rows("1"))
 			}
 			if id == 0 {
 				rbatch = batch.Rows()
@@ -2767,3 +2979,47 @@ func TestCachedTable(t *testing.T) {
 	require.True(t, lastReadFromCache(tk))
 	tk.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("1"))
 }
+
+func TestPrepareCacheBucketInList(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+	orgEnable := core.PreparedPlanCacheEnabled()
+	defer core.SetPreparedPlanCache(orgEnable)
+	core.SetPreparedPlanCache(true)
+	se, err := session.CreateSession4TestWithOpt(store, &session.Opt{
+		PreparedPlanCache: kvcache.NewSimpleLRUCache(100, 0.1, math.MaxUint64),
+	})
+	require.NoError(t, err)
+	tk := testkit.NewTestKitWithSession(t, store, se)
+	tk.MustExec("use test")
+	tk.MustExec("create table t(a int, b int, key(b))")
+	tk.MustExec("insert into t values (1, 1), (2, 2), (3, 3), (4, 4), (5, 5)")
+
+	// the IN-lists are padded to the bucket size, so the statements in the same bucket share the plans.
+	tk.MustExec("prepare stmt3 from 'select a from t where b in (?, ?, ?) order by a'")
+	tk.MustExec("set @a = 1, @b = 2, @c = 3, @d = 4, @e = 5")
+	tk.MustQuery("execute stmt3 using @a, @b, @c").Check(testkit.Rows("1", "2", "3"))
+	tk.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("0"))
+	tk.MustExec("prepare stmt4 from 'select a from t where b in (?, ?, ?, ?) order by a'")
+	tk.MustQuery("execute stmt4 using @a, @b, @c, @d").Check(testkit.Rows("1", "2", "3", "4"))
+	tk.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("1"))
+	tk.MustQuery("execute stmt3 using @e, @d, @c").Check(testkit.Rows("3", "4", "5"))
+	tk.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("1"))
+	tk.MustExec("prepare stmt5 from 'select a from t where b in (?, ?, ?, ?, ?) order by a'")
+	tk.MustQuery("execute stmt5 using @a, @b, @c, @d, @e").Check(testkit.Rows("1", "2", "3", "4", "5"))
+	tk.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("0"))
+
+	// the client still binds the parameters of the original statement.
+	err = tk.ExecToErr("execute stmt3 using @a, @b, @c, @d")
+	require.True(t, core.ErrWrongParamCount.Equal(err))
+	stmtID, paramCount, _, err := tk.Session().PrepareStmt("select a from t where b in (?, ?, ?) and a > ? order by a")
+	require.NoError(t, err)
+	require.Equal(t, 4, paramCount)
+	rs, err := tk.Session().ExecutePreparedStmt(context.Background(), stmtID, types.MakeDatums(1, 4, 5, 2))
+	require.NoError(t, err)
+	tk.ResultSetToResult(rs, "").Check(testkit.Rows("4", "5"))
+	rs, err = tk.Session().ExecutePreparedStmt(context.Background(), stmtID, types.MakeDatums(1, 2, 5, 0))
+	require.NoError(t, err)
+	tk.ResultSetToResult(rs, "").Check(testkit.Rows("1", "2", "5"))
+	tk.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("1"))
+}
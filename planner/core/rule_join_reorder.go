@@ -32,14 +32,34 @@ import (
 //
 // For example: "InnerJoin(InnerJoin(a, b), LeftJoin(c, d))"
 // results in a join group {a, b, LeftJoin(c, d)}.
-func extractJoinGroup(p LogicalPlan) (group []LogicalPlan, eqEdges []*expression.ScalarFunction, otherConds []expression.Expression) {
+//
+// If tidb_enable_outer_join_reorder is on, the outer joins are extracted too,
+// but only their outer sides are extracted recursively. The example above
+// results in a join group {a, b, c, d}, and the LeftJoin is recorded in the
+// outerJoins, see outerJoinEdge for details. The outer joins in bannedJoins
+// are never extracted.
+func extractJoinGroup(p LogicalPlan, bannedJoins map[*LogicalJoin]struct{}) (group []LogicalPlan, eqEdges []*expression.ScalarFunction, otherConds []expression.Expression, outerJoins []*outerJoinEdge) {
 	join, isJoin := p.(*LogicalJoin)
-	if !isJoin || join.preferJoinType > uint(0) || join.JoinType != InnerJoin || join.StraightJoin {
-		return []LogicalPlan{p}, nil, nil
+	if !isJoin || join.preferJoinType > uint(0) || join.StraightJoin {
+		return []LogicalPlan{p}, nil, nil, nil
+	}
+	if join.JoinType != InnerJoin {
+		if _, banned := bannedJoins[join]; banned || !canReorderOuterJoin(join) {
+			return []LogicalPlan{p}, nil, nil, nil
+		}
+		edge := newOuterJoinEdge(join)
+		outerIdx := 0
+		if join.JoinType == RightOuterJoin {
+			outerIdx = 1
+		}
+		group, eqEdges, otherConds, outerJoins = extractJoinGroup(join.children[outerIdx], bannedJoins)
+		group = append(group, join.children[1-outerIdx])
+		outerJoins = append(outerJoins, edge)
+		return group, eqEdges, otherConds, outerJoins
 	}
 
-	lhsGroup, lhsEqualConds, lhsOtherConds := extractJoinGroup(join.children[0])
-	rhsGroup, rhsEqualConds, rhsOtherConds := extractJoinGroup(join.children[1])
+	lhsGroup, lhsEqualConds, lhsOtherConds, lhsOuterJoins := extractJoinGroup(join.children[0], bannedJoins)
+	rhsGroup, rhsEqualConds, rhsOtherConds, rhsOuterJoins := extractJoinGroup(join.children[1], bannedJoins)
 
 	group = append(group, lhsGroup...)
 	group = append(group, rhsGroup...)
@@ -49,7 +69,141 @@ func extractJoinGroup(p LogicalPlan) (group []LogicalPlan, eqEdges []*expression
 	otherConds = append(otherConds, join.OtherConditions...)
 	otherConds = append(otherConds, lhsOtherConds...)
 	otherConds = append(otherConds, rhsOtherConds...)
-	return group, eqEdges, otherConds
+	outerJoins = append(outerJoins, lhsOuterJoins...)
+	outerJoins = append(outerJoins, rhsOuterJoins...)
+	return group, eqEdges, otherConds, outerJoins
+}
+
+// outerJoinEdge is an outer join which is reordered together with the inner
+// joins of its join group. The inner side of the outer join stays a single
+// node of the group, and all the conditions of the outer join are kept here,
+// so the join can be rebuilt as a left outer join wherever it is placed.
+//
+// The reorder algorithms must follow these conflict rules:
+//  1. The inner node can only be joined through this outer join, with a plan
+//     containing the outer node, which is the only node referenced by the
+//     conditions on the outer side. So an outer join is never reordered before
+//     the joins producing its outer side, and a nested outer join is never
+//     reordered before the outer join producing its outer node.
+//  2. No inner join of the group references the inner node. Otherwise, the
+//     inner join must stay above the outer join, which the group cannot express.
+//
+// Under these rules, an inner join and an outer join can be swapped as long as
+// the inner join doesn't reference the inner node, which is exactly the
+// associativity of inner join and left outer join.
+type outerJoinEdge struct {
+	join *LogicalJoin
+	// eqConds are the equal conditions whose first arguments are from the outer side.
+	eqConds    []*expression.ScalarFunction
+	outerConds expression.CNFExprs
+	innerConds expression.CNFExprs
+	otherConds expression.CNFExprs
+}
+
+func canReorderOuterJoin(join *LogicalJoin) bool {
+	if !join.ctx.GetSessionVars().EnableOuterJoinReorder {
+		return false
+	}
+	if join.JoinType != LeftOuterJoin && join.JoinType != RightOuterJoin {
+		return false
+	}
+	return len(join.EqualConditions) > 0 && join.DefaultValues == nil
+}
+
+func newOuterJoinEdge(join *LogicalJoin) *outerJoinEdge {
+	edge := &outerJoinEdge{
+		join:       join,
+		eqConds:    join.EqualConditions,
+		outerConds: join.LeftConditions,
+		innerConds: join.RightConditions,
+		otherConds: join.OtherConditions,
+	}
+	if join.JoinType == RightOuterJoin {
+		edge.outerConds, edge.innerConds = join.RightConditions, join.LeftConditions
+		edge.eqConds = make([]*expression.ScalarFunction, 0, len(join.EqualConditions))
+		for _, cond := range join.EqualConditions {
+			lCol := cond.GetArgs()[0].(*expression.Column)
+			rCol := cond.GetArgs()[1].(*expression.Column)
+			newSf := expression.NewFunctionInternal(join.ctx, cond.FuncName.L, cond.GetType(), rCol, lCol).(*expression.ScalarFunction)
+			edge.eqConds = append(edge.eqConds, newSf)
+		}
+	}
+	return edge
+}
+
+// innerCol returns a column of the inner side of the outer join.
+func (e *outerJoinEdge) innerCol() *expression.Column {
+	return e.eqConds[0].GetArgs()[1].(*expression.Column)
+}
+
+// outerCol returns a column of the outer side of the outer join.
+func (e *outerJoinEdge) outerCol() *expression.Column {
+	return e.eqConds[0].GetArgs()[0].(*expression.Column)
+}
+
+// extractReorderableJoinGroup extracts the join group of p, and bans the outer
+// joins that break the conflict rules of outerJoinEdge until none is left.
+func extractReorderableJoinGroup(p LogicalPlan) (group []LogicalPlan, eqEdges []*expression.ScalarFunction, otherConds []expression.Expression, outerJoins []*outerJoinEdge) {
+	var bannedJoins map[*LogicalJoin]struct{}
+	for {
+		group, eqEdges, otherConds, outerJoins = extractJoinGroup(p, bannedJoins)
+		if len(outerJoins) == 0 {
+			return group, eqEdges, otherConds, outerJoins
+		}
+		if bannedJoins == nil {
+			bannedJoins = make(map[*LogicalJoin]struct{})
+		}
+		// There is nothing to reorder if all the joins are outer joins.
+		if len(outerJoins) == len(group)-1 {
+			for _, edge := range outerJoins {
+				bannedJoins[edge.join] = struct{}{}
+			}
+			continue
+		}
+		conflicted := false
+		for _, edge := range outerJoins {
+			if outerJoinConflicts(edge, group, eqEdges, otherConds) {
+				bannedJoins[edge.join] = struct{}{}
+				conflicted = true
+			}
+		}
+		if !conflicted {
+			return group, eqEdges, otherConds, outerJoins
+		}
+	}
+}
+
+// outerJoinConflicts checks whether the outer join breaks the conflict rules of outerJoinEdge.
+func outerJoinConflicts(edge *outerJoinEdge, group []LogicalPlan, eqEdges []*expression.ScalarFunction, otherConds []expression.Expression) bool {
+	innerIdx, err := findNodeIndexInGroup(group, edge.innerCol())
+	if err != nil {
+		return true
+	}
+	inner := group[innerIdx].Schema()
+	outerIdx := -1
+	outerCols := make([]*expression.Column, 0, len(edge.eqConds))
+	for _, cond := range edge.eqConds {
+		outerCols = append(outerCols, cond.GetArgs()[0].(*expression.Column))
+	}
+	outerCols = expression.ExtractColumnsFromExpressions(outerCols, edge.outerConds, nil)
+	outerCols = expression.ExtractColumnsFromExpressions(outerCols, edge.otherConds, func(col *expression.Column) bool {
+		return !inner.Contains(col)
+	})
+	for _, col := range outerCols {
+		idx, err := findNodeIndexInGroup(group, col)
+		if err != nil || idx == innerIdx || (outerIdx >= 0 && idx != outerIdx) {
+			return true
+		}
+		outerIdx = idx
+	}
+	innerJoinCols := expression.ExtractColumnsFromExpressions(nil, expression.ScalarFuncs2Exprs(eqEdges), nil)
+	innerJoinCols = expression.ExtractColumnsFromExpressions(innerJoinCols, otherConds, nil)
+	for _, col := range innerJoinCols {
+		if inner.Contains(col) {
+			return true
+		}
+	}
+	return false
 }
 
 type joinReOrderSolver struct {
@@ -72,7 +226,7 @@ func (s *joinReOrderSolver) optimize(ctx context.Context, p LogicalPlan, opt *lo
 // optimizeRecursive recursively collects join groups and applies join reorder algorithm for each group.
 func (s *joinReOrderSolver) optimizeRecursive(ctx sessionctx.Context, p LogicalPlan, tracer *joinReorderTrace) (LogicalPlan, error) {
 	var err error
-	curJoinGroup, eqEdges, otherConds := extractJoinGroup(p)
+	curJoinGroup, eqEdges, otherConds, outerJoins := extractReorderableJoinGroup(p)
 	if len(curJoinGroup) > 1 {
 		for i := range curJoinGroup {
 			curJoinGroup[i], err = s.optimizeRecursive(ctx, curJoinGroup[i], tracer)
@@ -83,6 +237,7 @@ func (s *joinReOrderSolver) optimizeRecursive(ctx sessionctx.Context, p LogicalP
 		baseGroupSolver := &baseSingleGroupJoinOrderSolver{
 			ctx:        ctx,
 			otherConds: otherConds,
+			outerJoins: outerJoins,
 		}
 		originalSchema := p.Schema()
 		if len(curJoinGroup) > ctx.GetSessionVars().TiDBOptJoinReorderThreshold {
@@ -139,6 +294,7 @@ type baseSingleGroupJoinOrderSolver struct {
 	ctx          sessionctx.Context
 	curJoinGroup []*jrNode
 	otherConds   []expression.Expression
+	outerJoins   []*outerJoinEdge
 }
 
 // baseNodeCumCost calculate the cumulative cost of the node in the join group.
@@ -196,6 +352,28 @@ func (s *baseSingleGroupJoinOrderSolver) newJoinWithEdges(lChild, rChild Logical
 	return newJoin
 }
 
+// newOuterJoin rebuilds the outer join of the edge as a left outer join, whose inner side is the rChild.
+func (s *baseSingleGroupJoinOrderSolver) newOuterJoin(lChild, rChild LogicalPlan, edge *outerJoinEdge) LogicalPlan {
+	newJoin := s.newCartesianJoin(lChild, rChild)
+	newJoin.JoinType = LeftOuterJoin
+	newJoin.EqualConditions = edge.eqConds
+	newJoin.LeftConditions = edge.outerConds
+	newJoin.RightConditions = edge.innerConds
+	newJoin.OtherConditions = edge.otherConds
+	resetNotNullFlag(newJoin.schema, lChild.Schema().Len(), newJoin.schema.Len())
+	return newJoin
+}
+
+// findOuterJoinByInner finds the outer join whose inner side is the node of the join group.
+func (s *baseSingleGroupJoinOrderSolver) findOuterJoinByInner(node LogicalPlan) *outerJoinEdge {
+	for _, edge := range s.outerJoins {
+		if node.Schema().Contains(edge.innerCol()) {
+			return edge
+		}
+	}
+	return nil
+}
+
 // calcJoinCumCost calculates the cumulative cost of the join node.
 func (s *baseSingleGroupJoinOrderSolver) calcJoinCumCost(join LogicalPlan, lNode, rNode *jrNode) float64 {
 	return join.statsInfo().RowCount + lNode.cumCost + rNode.cumCost
@@ -31,6 +31,12 @@ type joinGroupEqEdge struct {
 	edge    *expression.ScalarFunction
 }
 
+type joinGroupOuterEdge struct {
+	innerID int
+	outerID int
+	edge    *outerJoinEdge
+}
+
 type joinGroupNonEqEdge struct {
 	nodeIDs    []int
 	nodeIDMask uint
@@ -75,6 +81,24 @@ func (s *joinReorderDPSolver) solve(joinGroup []LogicalPlan, eqConds []expressio
 		}
 		addEqEdge(lIdx, rIdx, sf)
 	}
+	totalOuterEdges := make([]joinGroupOuterEdge, 0, len(s.outerJoins))
+	for _, edge := range s.outerJoins {
+		innerIdx, err := findNodeIndexInGroup(joinGroup, edge.innerCol())
+		if err != nil {
+			return nil, err
+		}
+		outerIdx, err := findNodeIndexInGroup(joinGroup, edge.outerCol())
+		if err != nil {
+			return nil, err
+		}
+		totalOuterEdges = append(totalOuterEdges, joinGroupOuterEdge{
+			innerID: innerIdx,
+			outerID: outerIdx,
+			edge:    edge,
+		})
+		adjacents[innerIdx] = append(adjacents[innerIdx], outerIdx)
+		adjacents[outerIdx] = append(adjacents[outerIdx], innerIdx)
+	}
 	totalNonEqEdges := make([]joinGroupNonEqEdge, 0, len(s.otherConds))
 	for _, cond := range s.otherConds {
 		cols := expression.ExtractColumns(cond)
@@ -121,8 +145,14 @@ func (s *joinReorderDPSolver) solve(joinGroup []LogicalPlan, eqConds []expressio
 			subNonEqEdges = append(subNonEqEdges, totalNonEqEdges[i])
 			totalNonEqEdges = append(totalNonEqEdges[:i], totalNonEqEdges[i+1:]...)
 		}
+		var subOuterEdges []joinGroupOuterEdge
+		for _, edge := range totalOuterEdges {
+			if nodeIDMask&(1<<uint(edge.innerID)) > 0 {
+				subOuterEdges = append(subOuterEdges, edge)
+			}
+		}
 		// Do DP on each sub graph.
-		join, err := s.dpGraph(visitID2NodeID, nodeID2VisitID, joinGroup, totalEqEdges, subNonEqEdges, tracer)
+		join, err := s.dpGraph(visitID2NodeID, nodeID2VisitID, joinGroup, totalEqEdges, subNonEqEdges, subOuterEdges, tracer)
 		if err != nil {
 			return nil, err
 		}
@@ -160,8 +190,10 @@ func (s *joinReorderDPSolver) bfsGraph(startNode int, visited []bool, adjacents
 // dpGraph is the core part of this algorithm.
 // It implements the traditional join reorder algorithm: DP by subset using the following formula:
 //   bestPlan[S:set of node] = the best one among Join(bestPlan[S1:subset of S], bestPlan[S2: S/S1])
+// Since S1 and S2 can both be joins, the result can be a bushy tree. The outer joins only join the inner
+// node with the subsets containing the outer node, see outerJoinEdge.
 func (s *joinReorderDPSolver) dpGraph(visitID2NodeID, nodeID2VisitID []int, joinGroup []LogicalPlan,
-	totalEqEdges []joinGroupEqEdge, totalNonEqEdges []joinGroupNonEqEdge, tracer *joinReorderTrace) (LogicalPlan, error) {
+	totalEqEdges []joinGroupEqEdge, totalNonEqEdges []joinGroupNonEqEdge, totalOuterEdges []joinGroupOuterEdge, tracer *joinReorderTrace) (LogicalPlan, error) {
 	nodeCnt := uint(len(visitID2NodeID))
 	bestPlan := make([]*jrNode, 1<<nodeCnt)
 	// bestPlan[s] is nil can be treated as bestCost[s] = +inf.
@@ -183,13 +215,27 @@ func (s *joinReorderDPSolver) dpGraph(visitID2NodeID, nodeID2VisitID []int, join
 			if bestPlan[sub] == nil || bestPlan[remain] == nil {
 				continue
 			}
-			// Get the edge connecting the two parts.
-			usedEdges, otherConds := s.nodesAreConnected(sub, remain, nodeID2VisitID, totalEqEdges, totalNonEqEdges)
-			// Here we only check equal condition currently.
-			if len(usedEdges) == 0 {
+			outerEdge, innerIsSub, ok := s.checkOuterJoin(sub, remain, nodeID2VisitID, totalOuterEdges)
+			if !ok {
 				continue
 			}
-			join, err := s.newJoinWithEdge(bestPlan[sub].p, bestPlan[remain].p, usedEdges, otherConds)
+			var join LogicalPlan
+			var err error
+			if outerEdge != nil {
+				if innerIsSub {
+					join, err = s.newOuterJoinWithEdge(bestPlan[remain].p, bestPlan[sub].p, outerEdge)
+				} else {
+					join, err = s.newOuterJoinWithEdge(bestPlan[sub].p, bestPlan[remain].p, outerEdge)
+				}
+			} else {
+				// Get the edge connecting the two parts.
+				usedEdges, otherConds := s.nodesAreConnected(sub, remain, nodeID2VisitID, totalEqEdges, totalNonEqEdges)
+				// Here we only check equal condition currently.
+				if len(usedEdges) == 0 {
+					continue
+				}
+				join, err = s.newJoinWithEdge(bestPlan[sub].p, bestPlan[remain].p, usedEdges, otherConds)
+			}
 			if err != nil {
 				return nil, err
 			}
@@ -236,6 +282,37 @@ func (s *joinReorderDPSolver) nodesAreConnected(leftMask, rightMask uint, oldPos
 	return usedEqEdges, otherConds
 }
 
+// checkOuterJoin checks whether the two parts can be joined under the conflict rules of outerJoinEdge.
+// If one part is the inner node of an outer join, it returns that outer join, and the two parts can
+// only be joined if the other part contains the outer node.
+func (s *joinReorderDPSolver) checkOuterJoin(leftMask, rightMask uint, oldPos2NewPos []int,
+	totalOuterEdges []joinGroupOuterEdge) (edge *joinGroupOuterEdge, innerIsLeft bool, ok bool) {
+	var leftInner, rightInner *joinGroupOuterEdge
+	for i := range totalOuterEdges {
+		innerMask := uint(1) << uint(oldPos2NewPos[totalOuterEdges[i].innerID])
+		if leftMask == innerMask {
+			leftInner = &totalOuterEdges[i]
+		} else if rightMask == innerMask {
+			rightInner = &totalOuterEdges[i]
+		}
+	}
+	switch {
+	case leftInner != nil && rightInner != nil:
+		return nil, false, false
+	case leftInner != nil:
+		return leftInner, true, rightMask&(1<<uint(oldPos2NewPos[leftInner.outerID])) > 0
+	case rightInner != nil:
+		return rightInner, false, leftMask&(1<<uint(oldPos2NewPos[rightInner.outerID])) > 0
+	}
+	return nil, false, true
+}
+
+func (s *joinReorderDPSolver) newOuterJoinWithEdge(outerPlan, innerPlan LogicalPlan, edge *joinGroupOuterEdge) (LogicalPlan, error) {
+	join := s.newOuterJoin(outerPlan, innerPlan, edge.edge)
+	_, err := join.recursiveDeriveStats(nil)
+	return join, err
+}
+
 func (s *joinReorderDPSolver) newJoinWithEdge(leftPlan, rightPlan LogicalPlan, edges []joinGroupEqEdge, otherConds []expression.Expression) (LogicalPlan, error) {
 	var eqConds []*expression.ScalarFunction
 	for _, edge := range edges {
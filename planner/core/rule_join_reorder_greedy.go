@@ -41,6 +41,9 @@ type joinReorderGreedySolver struct {
 //
 // For the nodes and join trees which don't have a join equal condition to
 // connect them, we make a bushy join tree to do the cartesian joins finally.
+//
+// The inner node of an outer join never starts a join tree, and can only be
+// joined with the join tree containing its outer node, see outerJoinEdge.
 func (s *joinReorderGreedySolver) solve(joinNodePlans []LogicalPlan, tracer *joinReorderTrace) (LogicalPlan, error) {
 	for _, node := range joinNodePlans {
 		_, err := node.recursiveDeriveStats(nil)
@@ -71,8 +74,15 @@ func (s *joinReorderGreedySolver) solve(joinNodePlans []LogicalPlan, tracer *joi
 }
 
 func (s *joinReorderGreedySolver) constructConnectedJoinTree(tracer *joinReorderTrace) (*jrNode, error) {
-	curJoinTree := s.curJoinGroup[0]
-	s.curJoinGroup = s.curJoinGroup[1:]
+	startIdx := 0
+	for i, node := range s.curJoinGroup {
+		if s.findOuterJoinByInner(node.p) == nil {
+			startIdx = i
+			break
+		}
+	}
+	curJoinTree := s.curJoinGroup[startIdx]
+	s.curJoinGroup = append(s.curJoinGroup[:startIdx], s.curJoinGroup[startIdx+1:]...)
 	for {
 		bestCost := math.MaxFloat64
 		bestIdx := -1
@@ -111,6 +121,12 @@ func (s *joinReorderGreedySolver) constructConnectedJoinTree(tracer *joinReorder
 }
 
 func (s *joinReorderGreedySolver) checkConnectionAndMakeJoin(leftNode, rightNode LogicalPlan) (LogicalPlan, []expression.Expression) {
+	if edge := s.findOuterJoinByInner(rightNode); edge != nil {
+		if !leftNode.Schema().Contains(edge.outerCol()) {
+			return nil, nil
+		}
+		return s.newOuterJoin(leftNode, rightNode, edge), s.otherConds
+	}
 	var usedEdges []*expression.ScalarFunction
 	remainOtherConds := make([]expression.Expression, len(s.otherConds))
 	copy(remainOtherConds, s.otherConds)
@@ -389,7 +389,7 @@ func newListPartitionPruner(ctx sessionctx.Context, tbl table.Table, partitionNa
 func (l *listPartitionPruner) locatePartition(cond expression.Expression) (tables.ListPartitionLocation, bool, error) {
 	switch sf := cond.(type) {
 	case *expression.Constant:
-		b, err := sf.Value.ToBool(l.ctx.GetSessionVars().StmtCtx)
+		b, err := evalConstant4Prune(sf).ToBool(l.ctx.GetSessionVars().StmtCtx)
 		if err == nil && b == 0 {
 			// A constant false expression.
 			return nil, false, nil
@@ -1009,7 +1009,7 @@ type rangePruner struct {
 
 func (p *rangePruner) partitionRangeForExpr(sctx sessionctx.Context, expr expression.Expression) (int, int, bool) {
 	if constExpr, ok := expr.(*expression.Constant); ok {
-		if b, err := constExpr.Value.ToBool(sctx.GetSessionVars().StmtCtx); err == nil && b == 0 {
+		if b, err := evalConstant4Prune(constExpr).ToBool(sctx.GetSessionVars().StmtCtx); err == nil && b == 0 {
 			// A constant false expression.
 			return 0, 0, true
 		}
@@ -1050,7 +1050,7 @@ func partitionRangeColumnForInExpr(sctx sessionctx.Context, args []expression.Ex
 		if !ok {
 			return pruner.fullRange()
 		}
-		switch constExpr.Value.Kind() {
+		switch evalConstant4Prune(constExpr).Kind() {
 		case types.KindInt64, types.KindUint64, types.KindMysqlTime, types.KindString: // for safety, only support string,int and datetime now
 		case types.KindNull:
 			result = append(result, partitionRange{0, 1})
@@ -1088,7 +1088,8 @@ func partitionRangeForInExpr(sctx sessionctx.Context, args []expression.Expressi
 		if !ok {
 			return pruner.fullRange()
 		}
-		switch constExpr.Value.Kind() {
+		constVal := evalConstant4Prune(constExpr)
+		switch constVal.Kind() {
 		case types.KindInt64, types.KindUint64:
 		case types.KindNull:
 			result = append(result, partitionRange{0, 1})
@@ -1104,7 +1105,7 @@ func partitionRangeForInExpr(sctx sessionctx.Context, args []expression.Expressi
 			partFnConst := replaceColumnWithConst(pruner.partFn, constExpr)
 			val, _, err = partFnConst.EvalInt(sctx, chunk.Row{})
 		} else {
-			val, err = constExpr.Value.ToInt64(sctx.GetSessionVars().StmtCtx)
+			val, err = constVal.ToInt64(sctx.GetSessionVars().StmtCtx)
 		}
 		if err != nil {
 			return pruner.fullRange()
@@ -1226,6 +1227,16 @@ func (p *rangePruner) extractDataForPrune(sctx sessionctx.Context, expr expressi
 	return ret, false
 }
 
+// evalConstant4Prune returns the value of the constant. The value of a parameter in a cached plan
+// changes in each execution, so it must be evaluated instead of using Constant.Value.
+func evalConstant4Prune(con *expression.Constant) *types.Datum {
+	d, err := con.Eval(chunk.Row{})
+	if err != nil {
+		return &con.Value
+	}
+	return &d
+}
+
 // replaceColumnWithConst change fn(col) to fn(const)
 func replaceColumnWithConst(partFn *expression.ScalarFunction, con *expression.Constant) *expression.ScalarFunction {
 	args := partFn.GetArgs()
@@ -0,0 +1,134 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/types"
+)
+
+// generateRuntimeFilters attaches runtime filters to the hash joins whose probe side reads a table
+// directly, so that the values collected in the build phase can be pushed into the probe-side scan.
+func generateRuntimeFilters(sctx sessionctx.Context, plan PhysicalPlan) {
+	if !sctx.GetSessionVars().EnableRuntimeFilter {
+		return
+	}
+	for _, child := range plan.Children() {
+		generateRuntimeFilters(sctx, child)
+	}
+	join, ok := plan.(*PhysicalHashJoin)
+	if !ok {
+		return
+	}
+	join.RuntimeFilters = nil
+	buildIdx := join.InnerChildIdx
+	if join.UseOuterToBuild {
+		buildIdx = 1 - join.InnerChildIdx
+	}
+	if !canProbeSideBeFiltered(join) {
+		return
+	}
+	ts := runtimeFilterTargetScan(join.Children()[1-buildIdx])
+	if ts == nil {
+		return
+	}
+	buildKeys, probeKeys := join.LeftJoinKeys, join.RightJoinKeys
+	if buildIdx == 1 {
+		buildKeys, probeKeys = probeKeys, buildKeys
+	}
+	for i := range buildKeys {
+		if i < len(join.IsNullEQ) && join.IsNullEQ[i] {
+			continue
+		}
+		offset := ts.Schema().ColumnIndex(probeKeys[i])
+		if offset < 0 {
+			continue
+		}
+		target := ts.Schema().Columns[offset]
+		if target.VirtualExpr != nil || !runtimeFilterTypeMatch(buildKeys[i].RetType, target.RetType) {
+			continue
+		}
+		targetCol := target.Clone().(*expression.Column)
+		targetCol.Index = offset
+		join.RuntimeFilters = append(join.RuntimeFilters, &RuntimeFilter{
+			KeyIdx:       i,
+			BuildKey:     buildKeys[i],
+			TargetColumn: targetCol,
+		})
+	}
+}
+
+// canProbeSideBeFiltered checks whether the rows of the probe side that find no match in the
+// build side can be dropped without changing the result of the join.
+func canProbeSideBeFiltered(join *PhysicalHashJoin) bool {
+	switch join.JoinType {
+	case InnerJoin:
+		return true
+	case SemiJoin:
+		return !join.UseOuterToBuild
+	case LeftOuterJoin, RightOuterJoin:
+		// The probe side is the inner side only when the outer side is used to build the hash table.
+		return join.UseOuterToBuild
+	}
+	return false
+}
+
+// runtimeFilterTargetScan returns the table scan which the runtime filters can be pushed into.
+func runtimeFilterTargetScan(p PhysicalPlan) *PhysicalTableScan {
+	reader, ok := p.(*PhysicalTableReader)
+	if !ok || (reader.ReadReqType != Cop && reader.ReadReqType != BatchCop) {
+		return nil
+	}
+	if reader.StoreType != kv.TiKV && reader.StoreType != kv.TiFlash {
+		return nil
+	}
+	switch len(reader.TablePlans) {
+	case 1:
+	case 2:
+		if _, ok := reader.TablePlans[1].(*PhysicalSelection); !ok {
+			return nil
+		}
+	default:
+		return nil
+	}
+	ts, _ := reader.TablePlans[0].(*PhysicalTableScan)
+	return ts
+}
+
+// runtimeFilterTypeMatch checks whether the values of the build key can be compared with the
+// probe key in the coprocessor without any cast.
+func runtimeFilterTypeMatch(buildTp, probeTp *types.FieldType) bool {
+	if buildTp.EvalType() != probeTp.EvalType() {
+		return false
+	}
+	for _, tp := range []*types.FieldType{buildTp, probeTp} {
+		switch tp.Tp {
+		case mysql.TypeEnum, mysql.TypeSet, mysql.TypeBit, mysql.TypeJSON:
+			return false
+		}
+	}
+	switch buildTp.EvalType() {
+	case types.ETInt:
+		return mysql.HasUnsignedFlag(buildTp.Flag) == mysql.HasUnsignedFlag(probeTp.Flag)
+	case types.ETString:
+		return buildTp.Collate == probeTp.Collate
+	case types.ETDatetime:
+		return buildTp.Tp == probeTp.Tp
+	}
+	return true
+}
@@ -33,6 +33,7 @@ import (
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/logutil"
 	"github.com/pingcap/tidb/util/ranger"
+	"github.com/pingcap/tidb/util/tracing"
 	"go.uber.org/zap"
 )
 
@@ -217,9 +218,94 @@ func (ds *DataSource) getGroupNDVs(colGroups [][]*expression.Column) []property.
 			}
 		}
 	}
+	return ds.appendExtendedGroupNDVs(colGroups, ndvs)
+}
+
+// appendExtendedGroupNDVs appends the NDVs of the column groups which are not covered by indexes
+// but have the NDV extended statistics collected.
+func (ds *DataSource) appendExtendedGroupNDVs(colGroups [][]*expression.Column, ndvs []property.GroupNDV) []property.GroupNDV {
+	sessVars := ds.ctx.GetSessionVars()
+	if !sessVars.EnableExtendedStats || ds.statisticTable == nil || ds.statisticTable.Pseudo ||
+		ds.statisticTable.ExtendedStats == nil || len(ds.statisticTable.ExtendedStats.Stats) == 0 {
+		return ndvs
+	}
+	colID2UniqueID := make(map[int64]int64, len(ds.Columns))
+	for i, col := range ds.Columns {
+		if i < ds.schema.Len() {
+			colID2UniqueID[col.ID] = ds.schema.Columns[i].UniqueID
+		}
+	}
+	// Sort the names of the stats to make the choice deterministic when several stats match a group.
+	names := make([]string, 0, len(ds.statisticTable.ExtendedStats.Stats))
+	for name := range ds.statisticTable.ExtendedStats.Stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, g := range colGroups {
+		if getGroupNDV4Cols(g, &property.StatsInfo{GroupNDVs: ndvs}) != nil {
+			continue
+		}
+		for _, name := range names {
+			item := ds.statisticTable.ExtendedStats.Stats[name]
+			if item.Tp != ast.StatsTypeCardinality || len(item.ColIDs) != len(g) {
+				continue
+			}
+			groupCols := make([]int64, 0, len(item.ColIDs))
+			for _, colID := range item.ColIDs {
+				if uniqueID, ok := colID2UniqueID[colID]; ok {
+					groupCols = append(groupCols, uniqueID)
+				}
+			}
+			if len(groupCols) != len(g) {
+				continue
+			}
+			sort.Slice(groupCols, func(i, j int) bool {
+				return groupCols[i] < groupCols[j]
+			})
+			match := true
+			for i, col := range g {
+				// Both slices are sorted according to UniqueID.
+				if col.UniqueID != groupCols[i] {
+					match = false
+					break
+				}
+			}
+			if !match {
+				continue
+			}
+			ndvs = append(ndvs, property.GroupNDV{
+				Cols: groupCols,
+				NDV:  item.ScalarVals,
+			})
+			if sessVars.StmtCtx.EnableOptimizerCETrace {
+				ds.traceExtendedGroupNDV(g, item.ScalarVals)
+			}
+			break
+		}
+	}
 	return ndvs
 }
 
+// traceExtendedGroupNDV records the NDV of the column group taken from extended stats into CE trace.
+func (ds *DataSource) traceExtendedGroupNDV(cols []*expression.Column, ndv float64) {
+	names := make([]string, 0, len(cols))
+	for _, col := range cols {
+		for i, schemaCol := range ds.schema.Columns {
+			if schemaCol.UniqueID == col.UniqueID && i < len(ds.Columns) {
+				names = append(names, ds.Columns[i].Name.O)
+				break
+			}
+		}
+	}
+	sc := ds.ctx.GetSessionVars().StmtCtx
+	sc.OptimizerCETrace = append(sc.OptimizerCETrace, &tracing.CETraceRecord{
+		TableID:  ds.tableInfo.ID,
+		Type:     "Column Group Stats-NDV",
+		Expr:     fmt.Sprintf("ndv(%s)", strings.Join(names, ", ")),
+		RowCount: uint64(ndv),
+	})
+}
+
 func (ds *DataSource) initStats(colGroups [][]*expression.Column) {
 	if ds.tableStats != nil {
 		// Reload GroupNDVs since colGroups may have changed.
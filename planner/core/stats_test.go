@@ -149,3 +149,47 @@ func TestNDVGroupCols(t *testing.T) {
 		tk.MustQuery("explain format = 'brief' " + tt).Check(testkit.Rows(output[i].Plan...))
 	}
 }
+
+func TestGroupNDVFromExtendedStats(t *testing.T) {
+	store, dom, clean := testkit.CreateMockStoreAndDomain(t)
+	defer clean()
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("set session tidb_enable_extended_stats = on")
+	tk.MustExec("drop table if exists t1, t2")
+	tk.MustExec("create table t1(a int, b int, c int)")
+	tk.MustExec("create table t2(a int, b int, c int)")
+	for i := 0; i < 20; i++ {
+		tk.MustExec(fmt.Sprintf("insert into t1 values(%d, %d, %d)", i%4, i%5, i))
+		tk.MustExec(fmt.Sprintf("insert into t2 values(%d, %d, %d)", i%4, i%5, i))
+	}
+	tk.MustExec("alter table t1 add stats_extended s1 ndv(a,b)")
+	tk.MustExec("alter table t2 add stats_extended s2 ndv(a,b)")
+	tk.MustExec("analyze table t1")
+	tk.MustExec("analyze table t2")
+	require.NoError(t, dom.StatsHandle().Update(dom.InfoSchema()))
+
+	// The column groups (a,b) have 20 distinct values while each single column has at most 5.
+	tests := []struct {
+		sql     string
+		withExt string
+		noExt   string
+	}{
+		{"select a, b from t1 group by a, b", "20.00", "5.00"},
+		{"select distinct a, b from t1", "20.00", "5.00"},
+		{"select * from t1, t2 where t1.a = t2.a and t1.b = t2.b", "20.00", "80.00"},
+	}
+	for _, tt := range tests {
+		rows := tk.MustQuery("explain format = 'brief' " + tt.sql).Rows()
+		require.Equal(t, tt.withExt, rows[0][1], tt.sql)
+	}
+	rows := tk.MustQuery("trace plan target='estimation' select a, b from t1 group by a, b").Rows()
+	require.Len(t, rows, 1)
+	require.Contains(t, rows[0][0], `{"table_name":"t1","type":"Column Group Stats-NDV","expr":"ndv(a, b)","row_count":20}`)
+
+	tk.MustExec("set session tidb_enable_extended_stats = off")
+	for _, tt := range tests {
+		rows := tk.MustQuery("explain format = 'brief' " + tt.sql).Rows()
+		require.Equal(t, tt.noExt, rows[0][1], tt.sql)
+	}
+}
@@ -364,6 +364,28 @@ func cloneCols(cols []*expression.Column) []*expression.Column {
 	return cloned
 }
 
+func cloneDatums(datums []types.Datum) []types.Datum {
+	if datums == nil {
+		return nil
+	}
+	return append(make([]types.Datum, 0, len(datums)), datums...)
+}
+
+// cloneConstants clones the constants, the nil constants are kept.
+func cloneConstants(constants []*expression.Constant) []*expression.Constant {
+	if constants == nil {
+		return nil
+	}
+	cloned := make([]*expression.Constant, 0, len(constants))
+	for _, c := range constants {
+		if c != nil {
+			c = c.Clone().(*expression.Constant)
+		}
+		cloned = append(cloned, c)
+	}
+	return cloned
+}
+
 func cloneColInfos(cols []*model.ColumnInfo) []*model.ColumnInfo {
 	cloned := make([]*model.ColumnInfo, 0, len(cols))
 	for _, c := range cols {
@@ -219,7 +219,11 @@ func Optimize(ctx context.Context, sctx sessionctx.Context, node ast.Node, is in
 	// No plan found from the bindings, or the bindings are ignored.
 	if bestPlan == nil {
 		sessVars.StmtCtx.StmtHints = originStmtHints
-		bestPlan, names, _, err = optimize(ctx, sctx, node, is)
+		if useGeneralPlanCache(sctx, stmtNode, useBinding) {
+			bestPlan, names, err = optimizeWithGeneralPlanCache(ctx, sctx, stmtNode, is)
+		} else {
+			bestPlan, names, _, err = optimize(ctx, sctx, node, is)
+		}
 		if err != nil {
 			return nil, nil, err
 		}
@@ -266,6 +270,64 @@ func Optimize(ctx context.Context, sctx sessionctx.Context, node ast.Node, is in
 	return bestPlan, names, nil
 }
 
+// useGeneralPlanCache checks whether the plain-text statement should try the general plan cache.
+func useGeneralPlanCache(sctx sessionctx.Context, stmtNode ast.StmtNode, useBinding bool) bool {
+	sessVars := sctx.GetSessionVars()
+	if !sessVars.EnableGeneralPlanCache || !plannercore.PreparedPlanCacheEnabled() || sctx.PreparedPlanCache() == nil ||
+		stmtNode == nil || sessVars.InRestrictedSQL || sessVars.StmtCtx.UseCache {
+		// the statements of EXECUTE are optimized with the prepared plan cache.
+		return false
+	}
+	if _, isExplain := stmtNode.(*ast.ExplainStmt); isExplain {
+		// the explained statement is checked when it's optimized.
+		return false
+	}
+	if useBinding {
+		if sessVars.StmtCtx.InExplainStmt {
+			sessVars.StmtCtx.AppendNote(errors.New("skip general plan cache: query has bindings"))
+		}
+		return false
+	}
+	return true
+}
+
+// optimizeWithGeneralPlanCache optimizes the plain-text statement with the general plan cache. Its literals are
+// parameterized, so the statements which only differ in the literals share the cached plans.
+func optimizeWithGeneralPlanCache(ctx context.Context, sctx sessionctx.Context, stmtNode ast.StmtNode, is infoschema.InfoSchema) (plannercore.Plan, types.NameSlice, error) {
+	stmtCtx := sctx.GetSessionVars().StmtCtx
+	gpcStmt, reason := plannercore.ParameterizeStmt(sctx, stmtNode, is)
+	if gpcStmt == nil {
+		if stmtCtx.InExplainStmt {
+			stmtCtx.AppendNote(errors.Errorf("skip general plan cache: %s", reason))
+		}
+		p, names, _, err := optimize(ctx, sctx, stmtNode, is)
+		return p, names, err
+	}
+	defer gpcStmt.Restore()
+
+	p, names, ok, err := plannercore.GetGeneralCachedPlan(sctx, is, gpcStmt)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ok {
+		if stmtCtx.InExplainStmt {
+			stmtCtx.AppendNote(errors.New("use the plan from the general plan cache"))
+		}
+		return p, names, nil
+	}
+
+	sctx.SetValue(plannercore.GeneralPlanCacheStmtKey, gpcStmt)
+	p, names, _, err = optimize(ctx, sctx, stmtNode, is)
+	sctx.ClearValue(plannercore.GeneralPlanCacheStmtKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	if reason := plannercore.PutGeneralCachedPlan(sctx, gpcStmt, p, names); reason != "" && stmtCtx.InExplainStmt {
+		stmtCtx.AppendNote(errors.Errorf("skip general plan cache: %s", reason))
+	}
+	return p, names, nil
+}
+
 func allowInReadOnlyMode(sctx sessionctx.Context, node ast.Node) (bool, error) {
 	pm := privilege.GetPrivilegeManager(sctx)
 	if pm == nil {
@@ -368,6 +430,10 @@ func optimize(ctx context.Context, sctx sessionctx.Context, node ast.Node, is in
 		return nil, nil, 0, err
 	}
 
+	if gpcStmt, ok := sctx.Value(plannercore.GeneralPlanCacheStmtKey).(*plannercore.GeneralPlanCacheStmt); ok {
+		gpcStmt.SetVisitInfos(builder.GetVisitInfo())
+	}
+
 	// Handle the execute statement.
 	if execPlan, ok := p.(*plannercore.Execute); ok {
 		err := execPlan.OptimizePreparedPlan(ctx, sctx, is)
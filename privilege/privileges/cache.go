@@ -59,14 +59,17 @@ const (
 	sqlLoadColumnsPrivTable = "SELECT HIGH_PRIORITY Host,DB,User,Table_name,Column_name,Timestamp,Column_priv FROM mysql.columns_priv"
 	sqlLoadDefaultRoles     = "SELECT HIGH_PRIORITY HOST, USER, DEFAULT_ROLE_HOST, DEFAULT_ROLE_USER FROM mysql.default_roles"
 	// list of privileges from mysql.Priv2UserCol
-	sqlLoadUserTable = `SELECT HIGH_PRIORITY Host,User,authentication_string,
+	sqlLoadUserTableCols = `Host,User,authentication_string,
 	Create_priv, Select_priv, Insert_priv, Update_priv, Delete_priv, Show_db_priv, Super_priv,
 	Create_user_priv,Create_tablespace_priv,Trigger_priv,Drop_priv,Process_priv,Grant_priv,
 	References_priv,Alter_priv,Execute_priv,Index_priv,Create_view_priv,Show_view_priv,
 	Create_role_priv,Drop_role_priv,Create_tmp_table_priv,Lock_tables_priv,Create_routine_priv,
 	Alter_routine_priv,Event_priv,Shutdown_priv,Reload_priv,File_priv,Config_priv,Repl_client_priv,Repl_slave_priv,
-	account_locked,plugin FROM mysql.user`
-	sqlLoadGlobalGrantsTable = `SELECT HIGH_PRIORITY Host,User,Priv,With_Grant_Option FROM mysql.global_grants`
+	account_locked,plugin`
+	sqlLoadUserTable = "SELECT HIGH_PRIORITY " + sqlLoadUserTableCols + ",Resource_group FROM mysql.user"
+	// sqlLoadUserTableWithoutResourceGroup is used if mysql.user is not upgraded yet.
+	sqlLoadUserTableWithoutResourceGroup = "SELECT HIGH_PRIORITY " + sqlLoadUserTableCols + " FROM mysql.user"
+	sqlLoadGlobalGrantsTable             = `SELECT HIGH_PRIORITY Host,User,Priv,With_Grant_Option FROM mysql.global_grants`
 )
 
 func computePrivMask(privs []mysql.PrivilegeType) mysql.PrivilegeType {
@@ -99,6 +102,7 @@ type UserRecord struct {
 	Privileges           mysql.PrivilegeType
 	AccountLocked        bool // A role record when this field is true
 	AuthPlugin           string
+	ResourceGroup        string
 }
 
 // NewUserRecord return a UserRecord, only use for unit test.
@@ -392,6 +396,16 @@ func noSuchTable(err error) bool {
 	return false
 }
 
+func unknownColumn(err error) bool {
+	e1 := errors.Cause(err)
+	if e2, ok := e1.(*terror.Error); ok {
+		if terror.ErrCode(e2.Code()) == terror.ErrCode(mysql.ErrBadField) {
+			return true
+		}
+	}
+	return false
+}
+
 // LoadRoleGraph loads the mysql.role_edges table from database.
 func (p *MySQLPrivilege) LoadRoleGraph(ctx sessionctx.Context) error {
 	p.RoleGraph = make(map[string]roleGraphEdgesTable)
@@ -405,6 +419,9 @@ func (p *MySQLPrivilege) LoadRoleGraph(ctx sessionctx.Context) error {
 // LoadUserTable loads the mysql.user table from database.
 func (p *MySQLPrivilege) LoadUserTable(ctx sessionctx.Context) error {
 	err := p.loadTable(ctx, sqlLoadUserTable, p.decodeUserTableRow)
+	if unknownColumn(err) {
+		err = p.loadTable(ctx, sqlLoadUserTableWithoutResourceGroup, p.decodeUserTableRow)
+	}
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -653,6 +670,8 @@ func (p *MySQLPrivilege) decodeUserTableRow(row chunk.Row, fs []*ast.ResultField
 			} else {
 				value.AuthPlugin = mysql.AuthNativePassword
 			}
+		case f.ColumnAsName.L == "resource_group":
+			value.ResourceGroup = strings.ToLower(row.GetString(i))
 		case f.Column.Tp == mysql.TypeEnum:
 			if row.GetEnum(i).String() != "Y" {
 				continue
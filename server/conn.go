@@ -59,6 +59,7 @@ import (
 	"github.com/pingcap/errors"
 	"github.com/pingcap/failpoint"
 	"github.com/pingcap/tidb/config"
+	"github.com/pingcap/tidb/domain"
 	"github.com/pingcap/tidb/domain/infosync"
 	"github.com/pingcap/tidb/errno"
 	"github.com/pingcap/tidb/executor"
@@ -68,6 +69,7 @@ import (
 	"github.com/pingcap/tidb/parser"
 	"github.com/pingcap/tidb/parser/ast"
 	"github.com/pingcap/tidb/parser/auth"
+	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/parser/terror"
 	plannercore "github.com/pingcap/tidb/planner/core"
@@ -87,6 +89,7 @@ import (
 	"github.com/pingcap/tidb/util/logutil"
 	"github.com/pingcap/tidb/util/memory"
 	topsqlstate "github.com/pingcap/tidb/util/topsql/state"
+	"github.com/pingcap/tidb/util/tracing"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/tikv/client-go/v2/util"
 	"go.uber.org/zap"
@@ -184,6 +187,7 @@ type clientConn struct {
 	lastPacket    []byte            // latest sql query string, currently used for logging error.
 	ctx           *TiDBContext      // an interface to execute sql statements.
 	attrs         map[string]string // attributes parsed from client handshake response, not used for now.
+	zstdLevel     int               // zstd compression level requested by client.
 	peerHost      string            // peer host
 	peerPort      string            // peer port
 	status        int32             // dispatching/reading/shutdown/waitshutdown
@@ -290,7 +294,7 @@ func (cc *clientConn) handshake(ctx context.Context) error {
 	}
 
 	err := cc.writePacket(data)
-	cc.pkt.sequence = 0
+	cc.pkt.resetSequence()
 	if err != nil {
 		err = errors.SuspendStack(err)
 		logutil.Logger(ctx).Debug("write response to client failed", zap.Error(err))
@@ -303,6 +307,14 @@ func (cc *clientConn) handshake(ctx context.Context) error {
 		logutil.Logger(ctx).Debug("flush response to client failed", zap.Error(err))
 		return err
 	}
+
+	// The packets are compressed after the OK packet of the handshake.
+	// zlib is preferred if the client supports both, the same as MySQL.
+	if cc.capability&mysql.ClientCompress > 0 {
+		cc.pkt.setCompression(compressionZlib, 0)
+	} else if cc.capability&mysql.ClientZstdCompressionAlgorithm > 0 {
+		cc.pkt.setCompression(compressionZstd, cc.zstdLevel)
+	}
 	return err
 }
 
@@ -429,6 +441,7 @@ type handshakeResponse41 struct {
 	Auth       []byte
 	AuthPlugin string
 	Attrs      map[string]string
+	ZstdLevel  int
 }
 
 // parseOldHandshakeResponseHeader parses the old version handshake header HandshakeResponse320
@@ -572,9 +585,11 @@ func parseHandshakeResponseBody(ctx context.Context, packet *handshakeResponse41
 			// Defend some ill-formated packet, connection attribute is not important and can be ignored.
 			return nil
 		}
-		if num, null, off := parseLengthEncodedInt(data[offset:]); !null {
-			offset += off
+		num, null, off := parseLengthEncodedInt(data[offset:])
+		offset += off
+		if !null {
 			row := data[offset : offset+int(num)]
+			offset += int(num)
 			attrs, err := parseAttrs(row)
 			if err != nil {
 				logutil.Logger(ctx).Warn("parse attrs failed", zap.Error(err))
@@ -584,6 +599,10 @@ func parseHandshakeResponseBody(ctx context.Context, packet *handshakeResponse41
 		}
 	}
 
+	if packet.Capability&mysql.ClientZstdCompressionAlgorithm > 0 && len(data[offset:]) > 0 {
+		packet.ZstdLevel = int(data[offset])
+	}
+
 	return nil
 }
 
@@ -688,6 +707,7 @@ func (cc *clientConn) readOptionalSSLRequestAndHandshakeResponse(ctx context.Con
 	cc.dbname = resp.DBName
 	cc.collation = resp.Collation
 	cc.attrs = resp.Attrs
+	cc.zstdLevel = resp.ZstdLevel
 
 	err = cc.handleAuthPlugin(ctx, &resp)
 	if err != nil {
@@ -1134,7 +1154,7 @@ func (cc *clientConn) Run(ctx context.Context) {
 			terror.Log(err1)
 		}
 		cc.addMetrics(data[0], startTime, err)
-		cc.pkt.sequence = 0
+		cc.pkt.resetSequence()
 	}
 }
 
@@ -1235,6 +1255,23 @@ func (cc *clientConn) addMetrics(cmd byte, startTime time.Time, err error) {
 	}
 }
 
+// traceParentAttr is the query attribute which carries the W3C trace context of the statement.
+const traceParentAttr = "traceparent"
+
+// startW3CSpan starts the root span of the W3C trace of the request, it returns nil if the request
+// isn't traced. The `traceparent` query attribute takes precedence over the session variable.
+func (cc *clientConn) startW3CSpan(attrs map[string]string) opentracing.Span {
+	traceParent, ok := attrs[traceParentAttr]
+	if !ok {
+		traceParent = cc.ctx.GetSessionVars().TraceParent
+	}
+	span := tracing.StartW3CSpan("server.dispatch", traceParent)
+	if span != nil {
+		span.SetTag("conn_id", cc.connectionID)
+	}
+	return span
+}
+
 // dispatch handles client request based on command which is the first byte of the data.
 // It also gets a token from server which is used to limit the concurrently handling clients.
 // The most frequently used command is ComQuery.
@@ -1250,10 +1287,34 @@ func (cc *clientConn) dispatch(ctx context.Context, data []byte) error {
 		connIdleDurationHistogramNotInTxn.Observe(t.Sub(cc.lastActive).Seconds())
 	}
 
-	span := opentracing.StartSpan("server.dispatch")
-	cfg := config.GetGlobalConfig()
-	if cfg.OpenTracing.Enable {
+	var attrs map[string]string
+	if data[0] == mysql.ComQuery && cc.capability&mysql.ClientQueryAttributes > 0 {
+		cc.initInputEncoder(ctx)
+		var (
+			query []byte
+			err   error
+		)
+		attrs, query, err = parseQueryAttributes(cc.ctx.GetSessionVars().StmtCtx, data[1:], cc.inputDecoder)
+		if err != nil {
+			return err
+		}
+		// Strip the query attributes, the rest of the request only handles the query.
+		data = data[:1+copy(data[1:], query)]
+	}
+
+	var span opentracing.Span
+	// ComStmtExecute starts the span itself, since its query attributes are sent with the parameters.
+	if data[0] != mysql.ComStmtExecute {
+		span = cc.startW3CSpan(attrs)
+	}
+	if span != nil {
 		ctx = opentracing.ContextWithSpan(ctx, span)
+	} else {
+		span = opentracing.StartSpan("server.dispatch")
+		cfg := config.GetGlobalConfig()
+		if cfg.OpenTracing.Enable {
+			ctx = opentracing.ContextWithSpan(ctx, span)
+		}
 	}
 
 	var cancelFunc context.CancelFunc
@@ -1311,6 +1372,16 @@ func (cc *clientConn) dispatch(ctx context.Context, data []byte) error {
 		cc.ctx.SetCommandValue(cmd)
 	}
 
+	if cmd == mysql.ComQuery || cmd == mysql.ComStmtExecute {
+		release, err := cc.admitByResourceGroup(ctx)
+		if err != nil {
+			return err
+		}
+		if release != nil {
+			defer release()
+		}
+	}
+
 	dataStr := string(hack.String(data))
 	switch cmd {
 	case mysql.ComPing, mysql.ComStmtClose, mysql.ComStmtSendLongData, mysql.ComStmtReset,
@@ -1384,6 +1455,31 @@ func (cc *clientConn) dispatch(ctx context.Context, data []byte) error {
 	}
 }
 
+// admitByResourceGroup waits for the admission of the resource group bound to
+// the current user. It returns a nil release function if the user belongs to
+// the default group, which has no limits.
+func (cc *clientConn) admitByResourceGroup(ctx context.Context) (release func(), err error) {
+	vars := cc.ctx.GetSessionVars()
+	// The user may be bound to another group after the session is authenticated,
+	// so the group is resolved again for each statement.
+	if checker := privilege.GetPrivilegeManager(cc.ctx.Session); checker != nil && vars.User != nil {
+		vars.ResourceGroupName = checker.GetUserResourceGroup(vars.User.AuthUsername, vars.User.AuthHostname)
+	}
+	groupName := vars.ResourceGroupName
+	if len(groupName) == 0 {
+		return nil, nil
+	}
+	dom := domain.GetDomain(cc.ctx)
+	group, ok := dom.InfoSchema().ResourceGroupByName(model.NewCIStr(groupName))
+	if !ok {
+		// The group has been dropped, fall back to the default group.
+		vars.ResourceGroupName = ""
+		return nil, nil
+	}
+	timeout := time.Duration(variable.ResourceGroupQueueTimeout.Load()) * time.Millisecond
+	return dom.ResourceGroupManager().Admit(ctx, group, timeout)
+}
+
 func (cc *clientConn) writeStats(ctx context.Context) error {
 	var err error
 	var uptime int64 = 0
@@ -44,6 +44,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/opentracing/opentracing-go"
 	"github.com/pingcap/errors"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/parser/charset"
@@ -121,6 +122,10 @@ func (cc *clientConn) handleStmtPrepare(ctx context.Context, sql string) error {
 	return cc.flush(ctx)
 }
 
+// parameterCountAvailable is set in the flag of ComStmtExecute if the client sends the count of the
+// parameters even if the statement has no parameter, it's only sent with the query attributes.
+const parameterCountAvailable byte = 0x08
+
 func (cc *clientConn) handleStmtExecute(ctx context.Context, data []byte) (err error) {
 	defer trace.StartRegion(ctx, "HandleStmtExecute").End()
 	if len(data) < 9 {
@@ -144,9 +149,10 @@ func (cc *clientConn) handleStmtExecute(ctx context.Context, data []byte) (err e
 	// 0x01 CURSOR_TYPE_READ_ONLY
 	// 0x02 CURSOR_TYPE_FOR_UPDATE
 	// 0x04 CURSOR_TYPE_SCROLLABLE
+	// The parameterCountAvailable bit doesn't affect the cursor.
 	// Now we only support forward-only, read-only cursor.
 	var useCursor bool
-	switch flag {
+	switch flag &^ parameterCountAvailable {
 	case 0:
 		useCursor = false
 	case 1:
@@ -162,41 +168,80 @@ func (cc *clientConn) handleStmtExecute(ctx context.Context, data []byte) (err e
 		nullBitmaps []byte
 		paramTypes  []byte
 		paramValues []byte
+		names       []string
+		n           int
+		attrs       map[string]string
 	)
 	cc.initInputEncoder(ctx)
 	numParams := stmt.NumParams()
 	args := make([]types.Datum, numParams)
-	if numParams > 0 {
-		nullBitmapLen := (numParams + 7) >> 3
-		if len(data) < (pos + nullBitmapLen + 1) {
-			return mysql.ErrMalformPacket
+	queryAttrs := cc.capability&mysql.ClientQueryAttributes > 0
+	if numParams > 0 || (queryAttrs && flag&parameterCountAvailable > 0) {
+		// The client sends the count of the parameters if it supports the query attributes, which are
+		// sent as the parameters after the parameters of the statement.
+		paramCount := numParams
+		if queryAttrs {
+			var count uint64
+			count, n, err = parseLengthEncodedIntChecked(data[pos:])
+			if err != nil {
+				return err
+			}
+			pos += n
+			if count < uint64(numParams) || count > math.MaxUint16 {
+				return mysql.ErrMalformPacket
+			}
+			paramCount = int(count)
 		}
-		nullBitmaps = data[pos : pos+nullBitmapLen]
-		pos += nullBitmapLen
-
-		// new param bound flag
-		if data[pos] == 1 {
-			pos++
-			if len(data) < (pos + (numParams << 1)) {
+		if paramCount > 0 {
+			nullBitmapLen := (paramCount + 7) >> 3
+			if len(data) < (pos + nullBitmapLen + 1) {
 				return mysql.ErrMalformPacket
 			}
+			nullBitmaps = data[pos : pos+nullBitmapLen]
+			pos += nullBitmapLen
 
-			paramTypes = data[pos : pos+(numParams<<1)]
-			pos += numParams << 1
-			paramValues = data[pos:]
-			// Just the first StmtExecute packet contain parameters type,
-			// we need save it for further use.
-			stmt.SetParamsType(paramTypes)
-		} else {
-			paramValues = data[pos+1:]
+			// new param bound flag
+			if data[pos] == 1 {
+				pos++
+				paramTypes, names, n, err = parseParamTypesAndNames(data[pos:], paramCount, queryAttrs)
+				if err != nil {
+					return err
+				}
+				pos += n
+				paramValues = data[pos:]
+				// Just the first StmtExecute packet contain parameters type,
+				// we need save it for further use.
+				stmt.SetParamsType(paramTypes[:numParams<<1])
+			} else {
+				// The query attributes can't be parsed without their types, so they're ignored.
+				paramTypes = stmt.GetParamsType()
+				paramValues = data[pos+1:]
+				paramCount = numParams
+			}
 		}
 
-		err = parseExecArgs(cc.ctx.GetSessionVars().StmtCtx, args, stmt.BoundParams(), nullBitmaps, stmt.GetParamsType(), paramValues, cc.inputDecoder)
+		// The query attributes are parsed with the parameters of the statement.
+		allArgs, boundParams := args, stmt.BoundParams()
+		if paramCount > numParams {
+			allArgs = make([]types.Datum, paramCount)
+			boundParams = make([][]byte, paramCount)
+			copy(boundParams, stmt.BoundParams())
+		}
+		err = parseExecArgs(cc.ctx.GetSessionVars().StmtCtx, allArgs, boundParams, nullBitmaps, paramTypes, paramValues, cc.inputDecoder)
 		stmt.Reset()
 		if err != nil {
 			return errors.Annotate(err, cc.preparedStmt2String(stmtID))
 		}
+		if paramCount > numParams {
+			copy(args, allArgs)
+			attrs = queryAttributes(names[numParams:], allArgs[numParams:])
+		}
 	}
+	if span := cc.startW3CSpan(attrs); span != nil {
+		defer span.Finish()
+		ctx = opentracing.ContextWithSpan(ctx, span)
+	}
+
 	ctx = context.WithValue(ctx, execdetails.StmtExecDetailKey, &execdetails.StmtExecDetails{})
 	ctx = context.WithValue(ctx, util.ExecDetailsKey, &util.ExecDetails{})
 	retryable, err := cc.executePreparedStmtAndWriteResult(ctx, stmt, args, useCursor)
@@ -316,8 +361,104 @@ func parseStmtFetchCmd(data []byte) (uint32, uint32, error) {
 }
 
 func parseExecArgs(sc *stmtctx.StatementContext, args []types.Datum, boundParams [][]byte,
-	nullBitmap, paramTypes, paramValues []byte, enc *inputDecoder) (err error) {
-	pos := 0
+	nullBitmap, paramTypes, paramValues []byte, enc *inputDecoder) error {
+	_, err := parseBinaryArgs(sc, args, boundParams, nullBitmap, paramTypes, paramValues, enc)
+	return err
+}
+
+// parseParamTypesAndNames parses the types of the parameters, each type is followed by the name of the
+// parameter if the client sends the query attributes. It returns the types, the names and the length
+// of the parsed data.
+func parseParamTypesAndNames(data []byte, paramCount int, withNames bool) (paramTypes []byte, names []string, pos int, err error) {
+	if !withNames {
+		if len(data) < (paramCount << 1) {
+			return nil, nil, 0, mysql.ErrMalformPacket
+		}
+		return data[:paramCount<<1], nil, paramCount << 1, nil
+	}
+	paramTypes = make([]byte, 0, paramCount<<1)
+	names = make([]string, 0, paramCount)
+	for i := 0; i < paramCount; i++ {
+		if len(data) < (pos + 2) {
+			return nil, nil, 0, mysql.ErrMalformPacket
+		}
+		paramTypes = append(paramTypes, data[pos], data[pos+1])
+		pos += 2
+		if len(data) < (pos + 1) {
+			return nil, nil, 0, mysql.ErrMalformPacket
+		}
+		name, _, n, err := parseLengthEncodedBytes(data[pos:])
+		if err != nil {
+			return nil, nil, 0, mysql.ErrMalformPacket
+		}
+		names = append(names, string(name))
+		pos += n
+	}
+	return paramTypes, names, pos, nil
+}
+
+// parseQueryAttributes splits the payload of ComQuery into the query attributes and the query, the
+// attributes are sent before the query if the client supports the query attributes.
+// See https://dev.mysql.com/doc/dev/mysql-server/latest/page_protocol_com_query.html.
+func parseQueryAttributes(sc *stmtctx.StatementContext, data []byte, enc *inputDecoder) (map[string]string, []byte, error) {
+	paramCount, pos, err := parseLengthEncodedIntChecked(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	// skip parameter_set_count, always 1
+	_, n, err := parseLengthEncodedIntChecked(data[pos:])
+	if err != nil {
+		return nil, nil, err
+	}
+	pos += n
+	if paramCount == 0 {
+		return nil, data[pos:], nil
+	}
+	if paramCount > math.MaxUint16 {
+		return nil, nil, mysql.ErrMalformPacket
+	}
+	count := int(paramCount)
+	nullBitmapLen := (count + 7) >> 3
+	if len(data) < (pos + nullBitmapLen + 1) {
+		return nil, nil, mysql.ErrMalformPacket
+	}
+	nullBitmap := data[pos : pos+nullBitmapLen]
+	// skip new_params_bind_flag, always 1
+	pos += nullBitmapLen + 1
+	paramTypes, names, n, err := parseParamTypesAndNames(data[pos:], count, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	pos += n
+	values := make([]types.Datum, count)
+	n, err = parseBinaryArgs(sc, values, make([][]byte, count), nullBitmap, paramTypes, data[pos:], enc)
+	if err != nil {
+		// io.EOF means the connection is closed by the client in dispatch.
+		return nil, nil, mysql.ErrMalformPacket
+	}
+	pos += n
+	return queryAttributes(names, values), data[pos:], nil
+}
+
+// queryAttributes returns the query attributes by their names, the attributes whose values are NULL
+// are ignored.
+func queryAttributes(names []string, values []types.Datum) map[string]string {
+	attrs := make(map[string]string, len(names))
+	for i, name := range names {
+		if values[i].IsNull() {
+			continue
+		}
+		if v, err := values[i].ToString(); err == nil {
+			attrs[name] = v
+		}
+	}
+	return attrs
+}
+
+// parseBinaryArgs parses the values of the arguments in the binary protocol, it also returns the
+// length of the parsed values.
+func parseBinaryArgs(sc *stmtctx.StatementContext, args []types.Datum, boundParams [][]byte,
+	nullBitmap, paramTypes, paramValues []byte, enc *inputDecoder) (pos int, err error) {
 	var (
 		tmp    interface{}
 		v      []byte
@@ -349,7 +490,7 @@ func parseExecArgs(sc *stmtctx.StatementContext, args []types.Datum, boundParams
 		}
 
 		if (i<<1)+1 >= len(paramTypes) {
-			return mysql.ErrMalformPacket
+			return pos, mysql.ErrMalformPacket
 		}
 
 		tp := paramTypes[i<<1]
@@ -516,7 +657,7 @@ func parseExecArgs(sc *stmtctx.StatementContext, args []types.Datum, boundParams
 				var dec types.MyDecimal
 				err = sc.HandleTruncate(dec.FromString(v))
 				if err != nil {
-					return err
+					return pos, err
 				}
 				args[i] = types.NewDecimalDatum(&dec)
 			}
@@ -27,6 +27,7 @@ import (
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/tidb/domain"
 	"github.com/pingcap/tidb/executor"
+	"github.com/pingcap/tidb/parser/auth"
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/session"
 	"github.com/pingcap/tidb/sessionctx/variable"
@@ -36,6 +37,7 @@ import (
 	"github.com/pingcap/tidb/testkit/external"
 	"github.com/pingcap/tidb/util/arena"
 	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/tracing"
 	"github.com/stretchr/testify/require"
 	tikverr "github.com/tikv/client-go/v2/error"
 	"github.com/tikv/client-go/v2/testutils"
@@ -197,6 +199,20 @@ func TestParseHandshakeResponse(t *testing.T) {
 		"_client_name":    "libmysql",
 		"_pid":            "22344"})
 	require.True(t, eq)
+	require.Equal(t, 0, p.ZstdLevel)
+
+	// The zstd compression level follows the connection attributes.
+	zstdData := append([]byte{}, data...)
+	zstdData[3] |= byte(mysql.ClientZstdCompressionAlgorithm >> 24)
+	zstdData = append(zstdData, 0x0a)
+	p = handshakeResponse41{}
+	offset, err = parseHandshakeResponseHeader(context.Background(), &p, zstdData)
+	require.NoError(t, err)
+	require.Equal(t, mysql.ClientZstdCompressionAlgorithm, p.Capability&mysql.ClientZstdCompressionAlgorithm)
+	err = parseHandshakeResponseBody(context.Background(), &p, zstdData, offset)
+	require.NoError(t, err)
+	require.Equal(t, "bar", p.Attrs["foo"])
+	require.Equal(t, 10, p.ZstdLevel)
 
 	data = []byte{
 		0x8d, 0xa6, 0x0f, 0x00, 0x00, 0x00, 0x00, 0x01, 0x08, 0x00, 0x00, 0x00,
@@ -630,6 +646,138 @@ func testDispatch(t *testing.T, inputs []dispatchInput, capability uint32) {
 	}
 }
 
+func TestDispatchQueryAttributes(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+
+	se, err := session.CreateSession4Test(store)
+	require.NoError(t, err)
+	tc := &TiDBContext{
+		Session: se,
+		stmts:   make(map[int]*TiDBStatement),
+	}
+	var outBuffer bytes.Buffer
+	tidbdrv := NewTiDBDriver(store)
+	cfg := newTestConfig()
+	cfg.Port, cfg.Status.StatusPort = 0, 0
+	cfg.Status.ReportStatus = false
+	server, err := NewServer(cfg, tidbdrv)
+	require.NoError(t, err)
+	defer server.Close()
+
+	cc := &clientConn{
+		connectionID: 1,
+		server:       server,
+		pkt: &packetIO{
+			bufWriter: bufio.NewWriter(&outBuffer),
+		},
+		collation:  mysql.DefaultCollationID,
+		peerHost:   "localhost",
+		alloc:      arena.NewAllocator(512),
+		chunkAlloc: chunk.NewAllocator(),
+		ctx:        tc,
+		capability: mysql.ClientProtocol41 | mysql.ClientQueryAttributes,
+	}
+	exporter := &tracing.MemoryExporter{}
+	tracing.SetExporter(exporter)
+	defer tracing.SetExporter(nil)
+
+	const traceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	// null_bitmap, new_params_bind_flag and the attribute named traceparent whose type is MYSQL_TYPE_STRING.
+	attr := []byte{0x0, 0x1, 0xfe, 0x0}
+	attr = dumpLengthEncodedString(attr, []byte(traceParentAttr))
+	attr = dumpLengthEncodedString(attr, []byte(traceParent))
+	checkTraced := func(traced bool) {
+		var traceIDs []string
+		for _, span := range exporter.Spans() {
+			if span.Operation == "server.dispatch" {
+				traceIDs = append(traceIDs, span.TraceID)
+			}
+		}
+		if traced {
+			require.Equal(t, []string{"4bf92f3577b34da6a3ce929d0e0e4736"}, traceIDs)
+		} else {
+			require.Empty(t, traceIDs)
+		}
+		exporter.Reset()
+	}
+	dispatch := func(com byte, in []byte) error {
+		err := cc.dispatch(context.Background(), append([]byte{com}, in...))
+		_ = cc.flush(context.TODO())
+		outBuffer.Reset()
+		return err
+	}
+
+	// parameter_count and parameter_set_count come before the attribute.
+	query := append([]byte{0x1, 0x1}, attr...)
+	require.NoError(t, dispatch(mysql.ComQuery, append(query, "do 1"...)))
+	checkTraced(true)
+	require.NoError(t, dispatch(mysql.ComQuery, append([]byte{0x0, 0x1}, "do 1"...)))
+	checkTraced(false)
+	require.Equal(t, mysql.ErrMalformPacket, dispatch(mysql.ComQuery, query[:len(query)-1]))
+
+	// the attribute is sent after the parameter of the statement.
+	require.NoError(t, dispatch(mysql.ComStmtPrepare, []byte("select ?")))
+	execute := []byte{0x1, 0x0, 0x0, 0x0, 0x0, 0x1, 0x0, 0x0, 0x0, 0x2, 0x0, 0x1, 0x8, 0x0, 0x0, 0xfe, 0x0}
+	execute = dumpLengthEncodedString(execute, []byte(traceParentAttr))
+	execute = append(execute, 0x1, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0)
+	execute = dumpLengthEncodedString(execute, []byte(traceParent))
+	require.NoError(t, dispatch(mysql.ComStmtExecute, execute))
+	checkTraced(true)
+	// the attribute is ignored if the types of the parameters aren't sent again.
+	execute = []byte{0x1, 0x0, 0x0, 0x0, 0x0, 0x1, 0x0, 0x0, 0x0, 0x2, 0x0, 0x0, 0x1, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0}
+	execute = dumpLengthEncodedString(execute, []byte(traceParent))
+	require.NoError(t, dispatch(mysql.ComStmtExecute, execute))
+	checkTraced(false)
+	// the flag PARAMETER_COUNT_AVAILABLE is set if the statement has no parameter.
+	require.NoError(t, dispatch(mysql.ComStmtPrepare, []byte("select 1")))
+	execute = append([]byte{0x2, 0x0, 0x0, 0x0, 0x8, 0x1, 0x0, 0x0, 0x0, 0x1}, attr...)
+	require.NoError(t, dispatch(mysql.ComStmtExecute, execute))
+	checkTraced(true)
+	// the session variable is used without the attribute.
+	tc.GetSessionVars().TraceParent = traceParent
+	require.NoError(t, dispatch(mysql.ComStmtExecute, []byte{0x2, 0x0, 0x0, 0x0, 0x0, 0x1, 0x0, 0x0, 0x0}))
+	checkTraced(true)
+}
+
+func TestAdmitByResourceGroup(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("create resource group rg1 MAX_CONCURRENCY = 1")
+	tk.MustExec("create resource group rg2 MAX_CONCURRENCY = 1")
+	tk.MustExec("create user u1 resource group rg1")
+
+	se, err := session.CreateSession4Test(store)
+	require.NoError(t, err)
+	require.True(t, se.Auth(&auth.UserIdentity{Username: "u1", Hostname: "localhost"}, nil, nil))
+	cc := &clientConn{
+		connectionID: 1,
+		ctx:          &TiDBContext{Session: se, stmts: make(map[int]*TiDBStatement)},
+	}
+	ctx := context.Background()
+	release, err := cc.admitByResourceGroup(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, release)
+	release()
+	require.Equal(t, "rg1", se.GetSessionVars().ResourceGroupName)
+
+	// The group bound after the authentication takes effect on the next statement.
+	tk.MustExec("alter user u1 resource group rg2")
+	release, err = cc.admitByResourceGroup(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, release)
+	release()
+	require.Equal(t, "rg2", se.GetSessionVars().ResourceGroupName)
+
+	tk.MustExec("alter user u1 resource group default")
+	release, err = cc.admitByResourceGroup(ctx)
+	require.NoError(t, err)
+	require.Nil(t, release)
+	require.Equal(t, "", se.GetSessionVars().ResourceGroupName)
+}
+
 func TestGetSessionVarsWaitTimeout(t *testing.T) {
 	store, clean := testkit.CreateMockStore(t)
 	defer clean()
@@ -27,6 +27,8 @@ import (
 	"github.com/pingcap/tidb/parser/terror"
 	"github.com/pingcap/tidb/planner/core"
 	"github.com/pingcap/tidb/session"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/sessionstates"
 	"github.com/pingcap/tidb/sessionctx/stmtctx"
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/chunk"
@@ -164,7 +166,7 @@ func (ts *TiDBStatement) Close() error {
 			if !ok {
 				return errors.Errorf("invalid CachedPrepareStmt type")
 			}
-			cacheKey, err := core.NewPlanCacheKey(ts.ctx.GetSessionVars(), preparedObj.StmtText, preparedObj.StmtDB, preparedObj.PreparedAst.SchemaVersion)
+			cacheKey, err := core.NewPlanCacheKey(ts.ctx.GetSessionVars(), preparedObj.StmtText4PC, preparedObj.StmtDB, preparedObj.PreparedAst.SchemaVersion)
 			if err != nil {
 				return err
 			}
@@ -201,6 +203,7 @@ func (qd *TiDBDriver) OpenCtx(connID uint64, capability uint32, collation uint8,
 		currentDB: dbname,
 		stmts:     make(map[int]*TiDBStatement),
 	}
+	se.SetSessionStatesHandler(sessionstates.StatePrepareStmt, tc)
 	return tc, nil
 }
 
@@ -295,6 +298,57 @@ func (tc *TiDBContext) Prepare(sql string) (statement PreparedStatement, columns
 	return
 }
 
+// EncodeSessionStates implements SessionStatesHandler.EncodeSessionStates interface.
+// It supplements the bound parameters of the statements prepared by the binary protocol.
+func (tc *TiDBContext) EncodeSessionStates(ctx context.Context, sctx sessionctx.Context, sessionStates *sessionstates.SessionStates) error {
+	for id, stmt := range tc.stmts {
+		// The cursor can't be migrated because it holds the result set.
+		if stmt.rs != nil {
+			return sessionstates.ErrCannotMigrateSession.GenWithStackByArgs("prepared statements have open cursors")
+		}
+		preparedStmtInfo, ok := sessionStates.PreparedStmts[uint32(id)]
+		if !ok {
+			return errors.Errorf("prepared statement %d not found", id)
+		}
+		preparedStmtInfo.ParamTypes = stmt.paramsType
+		preparedStmtInfo.BoundParams = stmt.boundParams
+	}
+	return nil
+}
+
+// DecodeSessionStates implements SessionStatesHandler.DecodeSessionStates interface.
+// The statements have already been prepared by the session, so it only creates TiDBStatements for them.
+func (tc *TiDBContext) DecodeSessionStates(ctx context.Context, sctx sessionctx.Context, sessionStates *sessionstates.SessionStates) error {
+	for id, preparedStmtInfo := range sessionStates.PreparedStmts {
+		// Only the statements prepared by the binary protocol are managed by TiDBContext.
+		if len(preparedStmtInfo.Name) > 0 {
+			continue
+		}
+		preparedPointer, ok := tc.GetSessionVars().PreparedStmts[id]
+		if !ok {
+			return errors.Errorf("prepared statement %d not found", id)
+		}
+		preparedObj, ok := preparedPointer.(*core.CachedPrepareStmt)
+		if !ok {
+			return errors.Errorf("invalid CachedPrepareStmt type")
+		}
+		numParams := len(preparedObj.PreparedAst.Params)
+		boundParams := preparedStmtInfo.BoundParams
+		if len(boundParams) != numParams {
+			boundParams = make([][]byte, numParams)
+		}
+		tc.stmts[int(id)] = &TiDBStatement{
+			sql:         preparedStmtInfo.StmtText,
+			id:          id,
+			numParams:   numParams,
+			boundParams: boundParams,
+			paramsType:  preparedStmtInfo.ParamTypes,
+			ctx:         tc,
+		}
+	}
+	return nil
+}
+
 // GetStmtStats implements the sessionctx.Context interface.
 func (tc *TiDBContext) GetStmtStats() *stmtstats.StatementStats {
 	return tc.Session.GetStmtStats()
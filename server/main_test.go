@@ -17,6 +17,7 @@ package server
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -24,6 +25,7 @@ import (
 	"github.com/pingcap/tidb/metrics"
 	"github.com/pingcap/tidb/session"
 	"github.com/pingcap/tidb/store/mockstore/unistore"
+	"github.com/pingcap/tidb/testkit/testmain"
 	"github.com/pingcap/tidb/util/testbridge"
 	topsqlstate "github.com/pingcap/tidb/util/topsql/state"
 	"github.com/tikv/client-go/v2/tikv"
@@ -56,6 +58,16 @@ func TestMain(m *testing.M) {
 		_, _ = fmt.Fprintf(os.Stderr, "default: %#v\nglobal: %#v", defaultConfig, globalConfig)
 	}
 
+	// Write the slow log to a temp dir rather than the package directory.
+	slowLogDir, err := os.MkdirTemp("", "tidb-server-test")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "server: failed to create the slow log dir: %v\n", err)
+		os.Exit(1)
+	}
+	config.UpdateGlobal(func(conf *config.Config) {
+		conf.Log.SlowQueryFile = filepath.Join(slowLogDir, "tidb-slow.log")
+	})
+
 	opts := []goleak.Option{
 		goleak.IgnoreTopFunction("github.com/golang/glog.(*loggingT).flushDaemon"),
 		goleak.IgnoreTopFunction("time.Sleep"),
@@ -70,5 +82,9 @@ func TestMain(m *testing.M) {
 		goleak.IgnoreTopFunction("github.com/go-sql-driver/mysql.(*mysqlConn).startWatcher.func1"),
 	}
 
-	goleak.VerifyTestMain(m, opts...)
+	callback := func(i int) int {
+		_ = os.RemoveAll(slowLogDir)
+		return i
+	}
+	goleak.VerifyTestMain(testmain.WrapTestingM(m, callback), opts...)
 }
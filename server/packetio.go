@@ -59,6 +59,13 @@ type packetIO struct {
 	bufWriter   *bufio.Writer
 	sequence    uint8
 	readTimeout time.Duration
+	// pktReader is where the packets are read from, it decompresses the
+	// data read from bufReadConn if the compressed protocol is used.
+	pktReader io.Reader
+	// compressor is nil unless the compressed protocol is negotiated.
+	compressor *packetCompressor
+	// compressedSequence is the sequence of the compressed packets.
+	compressedSequence uint8
 }
 
 func newPacketIO(bufReadConn *bufferedReadConn) *packetIO {
@@ -70,6 +77,21 @@ func newPacketIO(bufReadConn *bufferedReadConn) *packetIO {
 func (p *packetIO) setBufferedReadConn(bufReadConn *bufferedReadConn) {
 	p.bufReadConn = bufReadConn
 	p.bufWriter = bufio.NewWriterSize(bufReadConn, defaultWriterSize)
+	p.pktReader = bufReadConn
+}
+
+// setCompression enables the compressed protocol for all the packets after.
+// It must be called after the pending packets are flushed.
+func (p *packetIO) setCompression(algorithm string, level int) {
+	p.compressor = newPacketCompressor(algorithm, level)
+	p.compressedSequence = p.sequence
+	p.bufWriter = bufio.NewWriterSize(&compressedWriter{p: p}, defaultWriterSize)
+	p.pktReader = &compressedReader{p: p}
+}
+
+func (p *packetIO) resetSequence() {
+	p.sequence = 0
+	p.compressedSequence = 0
 }
 
 func (p *packetIO) setReadTimeout(timeout time.Duration) {
@@ -83,17 +105,19 @@ func (p *packetIO) readOnePacket() ([]byte, error) {
 			return nil, err
 		}
 	}
-	if _, err := io.ReadFull(p.bufReadConn, header[:]); err != nil {
+	if _, err := io.ReadFull(p.pktReader, header[:]); err != nil {
 		return nil, errors.Trace(err)
 	}
 
-	sequence := header[3]
-	if sequence != p.sequence {
-		return nil, errInvalidSequence.GenWithStack("invalid sequence %d != %d", sequence, p.sequence)
+	// The sequence of the packets inside the compressed packets is not checked, just like MySQL.
+	if p.compressor == nil {
+		sequence := header[3]
+		if sequence != p.sequence {
+			return nil, errInvalidSequence.GenWithStack("invalid sequence %d != %d", sequence, p.sequence)
+		}
+		p.sequence++
 	}
 
-	p.sequence++
-
 	length := int(uint32(header[0]) | uint32(header[1])<<8 | uint32(header[2])<<16)
 
 	data := make([]byte, length)
@@ -102,7 +126,7 @@ func (p *packetIO) readOnePacket() ([]byte, error) {
 			return nil, err
 		}
 	}
-	if _, err := io.ReadFull(p.bufReadConn, data); err != nil {
+	if _, err := io.ReadFull(p.pktReader, data); err != nil {
 		return nil, errors.Trace(err)
 	}
 	return data, nil
@@ -186,5 +210,9 @@ func (p *packetIO) flush() error {
 	if err != nil {
 		return errors.Trace(err)
 	}
+	if p.compressor != nil {
+		// The next packet from the client continues the sequence of the compressed packets.
+		p.sequence = p.compressedSequence
+	}
 	return err
 }
@@ -0,0 +1,273 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/metrics"
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	compressionZlib = "zlib"
+	compressionZstd = "zstd"
+
+	// defaultZstdLevel is the compression level used by MySQL if the client does not specify one.
+	defaultZstdLevel = 3
+	// minCompressLength is the minimum length of the payload to be compressed, the same as MySQL.
+	minCompressLength = 50
+	// compressedHeaderLen is the length of the header of the compressed packets.
+	compressedHeaderLen = 7
+)
+
+var (
+	zlibWriterPool = sync.Pool{
+		New: func() interface{} {
+			return zlib.NewWriter(nil)
+		},
+	}
+	zlibReaderPool sync.Pool
+
+	// The zstd encoders and decoder are safe for concurrent use of EncodeAll and DecodeAll,
+	// so they are shared by all the connections to save the memory.
+	zstdEncoders     = make(map[zstd.EncoderLevel]*zstd.Encoder)
+	zstdEncodersLock sync.Mutex
+	zstdDecoder      *zstd.Decoder
+	zstdDecoderOnce  sync.Once
+)
+
+func getZstdEncoder(level zstd.EncoderLevel) (*zstd.Encoder, error) {
+	zstdEncodersLock.Lock()
+	defer zstdEncodersLock.Unlock()
+	if encoder, ok := zstdEncoders[level]; ok {
+		return encoder, nil
+	}
+	encoder, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(level))
+	if err != nil {
+		return nil, err
+	}
+	zstdEncoders[level] = encoder
+	return encoder, nil
+}
+
+func getZstdDecoder() (*zstd.Decoder, error) {
+	var err error
+	zstdDecoderOnce.Do(func() {
+		zstdDecoder, err = zstd.NewReader(nil, zstd.WithDecoderMaxMemory(mysql.MaxPayloadLen))
+	})
+	if err != nil {
+		return nil, err
+	}
+	if zstdDecoder == nil {
+		return nil, errors.New("failed to create the zstd decoder")
+	}
+	return zstdDecoder, nil
+}
+
+// packetCompressor compresses and decompresses the payload of the compressed packets.
+type packetCompressor struct {
+	algorithm string
+	zstdLevel zstd.EncoderLevel
+
+	readCompressedBytes    prometheus.Counter
+	readUncompressedBytes  prometheus.Counter
+	writeCompressedBytes   prometheus.Counter
+	writeUncompressedBytes prometheus.Counter
+}
+
+func newPacketCompressor(algorithm string, level int) *packetCompressor {
+	if level <= 0 {
+		level = defaultZstdLevel
+	}
+	return &packetCompressor{
+		algorithm:              algorithm,
+		zstdLevel:              zstd.EncoderLevelFromZstd(level),
+		readCompressedBytes:    metrics.CompressedPacketIOCounter.WithLabelValues("read", algorithm, "compressed"),
+		readUncompressedBytes:  metrics.CompressedPacketIOCounter.WithLabelValues("read", algorithm, "uncompressed"),
+		writeCompressedBytes:   metrics.CompressedPacketIOCounter.WithLabelValues("write", algorithm, "compressed"),
+		writeUncompressedBytes: metrics.CompressedPacketIOCounter.WithLabelValues("write", algorithm, "uncompressed"),
+	}
+}
+
+func (c *packetCompressor) compress(data []byte) ([]byte, error) {
+	if c.algorithm == compressionZstd {
+		encoder, err := getZstdEncoder(c.zstdLevel)
+		if err != nil {
+			return nil, err
+		}
+		return encoder.EncodeAll(data, nil), nil
+	}
+
+	var buf bytes.Buffer
+	w := zlibWriterPool.Get().(*zlib.Writer)
+	defer zlibWriterPool.Put(w)
+	w.Reset(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c *packetCompressor) decompress(data []byte, length int) ([]byte, error) {
+	var uncompressed []byte
+	if c.algorithm == compressionZstd {
+		decoder, err := getZstdDecoder()
+		if err != nil {
+			return nil, err
+		}
+		if uncompressed, err = decoder.DecodeAll(data, make([]byte, 0, length)); err != nil {
+			return nil, err
+		}
+	} else {
+		var r io.ReadCloser
+		var err error
+		if pooled := zlibReaderPool.Get(); pooled != nil {
+			r = pooled.(io.ReadCloser)
+			err = r.(zlib.Resetter).Reset(bytes.NewReader(data), nil)
+		} else {
+			r, err = zlib.NewReader(bytes.NewReader(data))
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer zlibReaderPool.Put(r)
+		uncompressed = make([]byte, length)
+		n, err := io.ReadFull(r, uncompressed)
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return nil, err
+		}
+		uncompressed = uncompressed[:n]
+	}
+	if len(uncompressed) != length {
+		return nil, errors.Errorf("invalid uncompressed length %d != %d", len(uncompressed), length)
+	}
+	return uncompressed, nil
+}
+
+// compressedReader reads the compressed packets from the connection
+// and returns the decompressed data.
+type compressedReader struct {
+	p   *packetIO
+	buf []byte
+}
+
+func (r *compressedReader) Read(data []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if err := r.readCompressedPacket(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(data, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *compressedReader) readCompressedPacket() error {
+	p := r.p
+	var header [compressedHeaderLen]byte
+	if _, err := io.ReadFull(p.bufReadConn, header[:]); err != nil {
+		return errors.Trace(err)
+	}
+
+	sequence := header[3]
+	if sequence != p.sequence {
+		return errInvalidSequence.GenWithStack("invalid compressed sequence %d != %d", sequence, p.sequence)
+	}
+	p.sequence++
+	p.compressedSequence = p.sequence
+
+	compressedLength := int(uint32(header[0]) | uint32(header[1])<<8 | uint32(header[2])<<16)
+	uncompressedLength := int(uint32(header[4]) | uint32(header[5])<<8 | uint32(header[6])<<16)
+	data := make([]byte, compressedLength)
+	if _, err := io.ReadFull(p.bufReadConn, data); err != nil {
+		return errors.Trace(err)
+	}
+	p.compressor.readCompressedBytes.Add(float64(compressedHeaderLen + compressedLength))
+
+	// The uncompressed length is 0 if the payload is not compressed.
+	if uncompressedLength == 0 {
+		p.compressor.readUncompressedBytes.Add(float64(compressedLength))
+		r.buf = data
+		return nil
+	}
+	p.compressor.readUncompressedBytes.Add(float64(uncompressedLength))
+	uncompressed, err := p.compressor.decompress(data, uncompressedLength)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	r.buf = uncompressed
+	return nil
+}
+
+// compressedWriter wraps the data written to it in compressed packets and
+// writes them to the connection.
+type compressedWriter struct {
+	p *packetIO
+}
+
+func (w *compressedWriter) Write(data []byte) (int, error) {
+	written := 0
+	for len(data) > 0 {
+		length := len(data)
+		if length > mysql.MaxPayloadLen {
+			length = mysql.MaxPayloadLen
+		}
+		if err := w.writeCompressedPacket(data[:length]); err != nil {
+			return written, err
+		}
+		written += length
+		data = data[length:]
+	}
+	return written, nil
+}
+
+func (w *compressedWriter) writeCompressedPacket(data []byte) error {
+	p := w.p
+	payload, uncompressedLength := data, 0
+	if len(data) >= minCompressLength {
+		compressed, err := p.compressor.compress(data)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		// Send the payload as is if it can't be compressed.
+		if len(compressed) < len(data) {
+			payload, uncompressedLength = compressed, len(data)
+		}
+	}
+
+	packet := make([]byte, 0, compressedHeaderLen+len(payload))
+	packet = append(packet,
+		byte(len(payload)), byte(len(payload)>>8), byte(len(payload)>>16),
+		p.compressedSequence,
+		byte(uncompressedLength), byte(uncompressedLength>>8), byte(uncompressedLength>>16))
+	packet = append(packet, payload...)
+	if _, err := p.bufReadConn.Write(packet); err != nil {
+		return errors.Trace(err)
+	}
+	p.compressedSequence++
+	p.compressor.writeCompressedBytes.Add(float64(compressedHeaderLen + len(payload)))
+	p.compressor.writeUncompressedBytes.Add(float64(len(data)))
+	return nil
+}
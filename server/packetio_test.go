@@ -121,3 +121,62 @@ func (c *bytesConn) SetReadDeadline(t time.Time) error {
 func (c *bytesConn) SetWriteDeadline(t time.Time) error {
 	return nil
 }
+
+type loopbackConn struct {
+	bytesConn
+}
+
+func (c *loopbackConn) Write(b []byte) (n int, err error) {
+	return c.b.Write(b)
+}
+
+func TestCompressedPacketIO(t *testing.T) {
+	for _, algorithm := range []string{compressionZlib, compressionZstd} {
+		conn := &loopbackConn{}
+		writer := newPacketIO(newBufferedReadConn(conn))
+		writer.setCompression(algorithm, 0)
+		reader := newPacketIO(newBufferedReadConn(conn))
+		reader.setCompression(algorithm, 0)
+
+		// A short payload is not compressed.
+		require.NoError(t, writer.writePacket([]byte{0x00, 0x00, 0x00, 0x00, 0x01, 0x02, 0x03}))
+		require.NoError(t, writer.flush())
+		require.Equal(t, []byte{0x07, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, 0x02, 0x03}, conn.b.Bytes())
+		data, err := reader.readPacket()
+		require.NoError(t, err)
+		require.Equal(t, []byte{0x01, 0x02, 0x03}, data)
+		require.Equal(t, uint8(1), writer.sequence)
+		require.Equal(t, uint8(1), reader.sequence)
+
+		// A long payload is compressed.
+		writer.resetSequence()
+		reader.resetSequence()
+		payload := bytes.Repeat([]byte("compressed protocol"), 1000)
+		require.NoError(t, writer.writePacket(append(make([]byte, 4), payload...)))
+		require.NoError(t, writer.flush())
+		uncompressedLength := int(uint32(conn.b.Bytes()[4]) | uint32(conn.b.Bytes()[5])<<8 | uint32(conn.b.Bytes()[6])<<16)
+		require.Equal(t, len(payload)+4, uncompressedLength)
+		require.Less(t, conn.b.Len(), len(payload))
+		data, err = reader.readPacket()
+		require.NoError(t, err)
+		require.Equal(t, payload, data)
+
+		// A payload larger than the max packet size is split.
+		writer.resetSequence()
+		reader.resetSequence()
+		payload = bytes.Repeat([]byte{0x01}, mysql.MaxPayloadLen+10)
+		require.NoError(t, writer.writePacket(append(make([]byte, 4), payload...)))
+		require.NoError(t, writer.flush())
+		data, err = reader.readPacket()
+		require.NoError(t, err)
+		require.Equal(t, payload, data)
+		require.Equal(t, writer.sequence, reader.sequence)
+
+		// The sequence of the compressed packets is checked.
+		require.NoError(t, writer.writePacket([]byte{0x00, 0x00, 0x00, 0x00, 0x01}))
+		require.NoError(t, writer.flush())
+		reader.resetSequence()
+		_, err = reader.readPacket()
+		require.True(t, errInvalidSequence.Equal(err))
+	}
+}
@@ -100,6 +100,28 @@ func parseLengthEncodedInt(b []byte) (num uint64, isNull bool, n int) {
 	return
 }
 
+// parseLengthEncodedIntChecked is parseLengthEncodedInt which checks the length of the data first.
+func parseLengthEncodedIntChecked(b []byte) (num uint64, n int, err error) {
+	if len(b) == 0 {
+		return 0, 0, mysql.ErrMalformPacket
+	}
+	switch b[0] {
+	case 0xfc:
+		n = 3
+	case 0xfd:
+		n = 4
+	case 0xfe:
+		n = 9
+	default:
+		n = 1
+	}
+	if len(b) < n {
+		return 0, 0, mysql.ErrMalformPacket
+	}
+	num, _, n = parseLengthEncodedInt(b)
+	return num, n, nil
+}
+
 func dumpLengthEncodedInt(buffer []byte, n uint64) []byte {
 	switch {
 	case n <= 250:
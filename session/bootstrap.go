@@ -91,6 +91,7 @@ const (
 		Create_Tablespace_Priv  ENUM('N','Y') NOT NULL DEFAULT 'N',
 		Repl_slave_priv	    	ENUM('N','Y') NOT NULL DEFAULT 'N',
 		Repl_client_priv		ENUM('N','Y') NOT NULL DEFAULT 'N',
+		Resource_group			VARCHAR(64) NOT NULL DEFAULT '',
 		PRIMARY KEY (Host, User));`
 	// CreateGlobalPrivTable is the SQL statement creates Global scope privilege table in system db.
 	CreateGlobalPrivTable = "CREATE TABLE IF NOT EXISTS mysql.global_priv (" +
@@ -414,6 +415,53 @@ const (
 		PRIMARY KEY (id),
 		KEY (update_time)
 	);`
+	// CreateRunawayRulesTable stores the rules to identify runaway queries.
+	CreateRunawayRulesTable = `CREATE TABLE IF NOT EXISTS mysql.runaway_rules (
+		id BIGINT(64) NOT NULL AUTO_INCREMENT,
+		sql_digest VARCHAR(64) NOT NULL DEFAULT '' comment 'empty string matches all statements',
+		plan_digest VARCHAR(64) NOT NULL DEFAULT '' comment 'empty string matches all plans',
+		max_execution_time BIGINT(64) UNSIGNED NOT NULL DEFAULT 0 comment 'in milliseconds, 0 means no limit',
+		max_processed_keys BIGINT(64) UNSIGNED NOT NULL DEFAULT 0 comment '0 means no limit',
+		quarantine_duration BIGINT(64) UNSIGNED NOT NULL DEFAULT 0 comment 'in seconds, 0 means the digest is not quarantined',
+		PRIMARY KEY (id)
+	);`
+	// CreateRunawayHistoryTable stores the actions taken on runaway queries.
+	CreateRunawayHistoryTable = `CREATE TABLE IF NOT EXISTS mysql.runaway_history (
+		time TIMESTAMP(6) NOT NULL,
+		instance VARCHAR(512) NOT NULL DEFAULT '',
+		rule_id BIGINT(64) NOT NULL,
+		action ENUM('kill', 'reject') NOT NULL,
+		sql_digest VARCHAR(64) NOT NULL,
+		plan_digest VARCHAR(64) NOT NULL DEFAULT '',
+		sample_sql TEXT,
+		quarantine_end TIMESTAMP(6) NULL DEFAULT NULL comment 'the statements of the digest are rejected until this time',
+		KEY (time),
+		KEY (quarantine_end)
+	);`
+	// CreateStatsExpressionsTable stores the expressions whose statistics are collected by `ANALYZE TABLE ... EXPRESSIONS`.
+	CreateStatsExpressionsTable = `CREATE TABLE IF NOT EXISTS mysql.stats_expressions (
+		table_id BIGINT(64) NOT NULL,
+		hist_id BIGINT(64) NOT NULL,
+		expression TEXT NOT NULL,
+		version BIGINT(64) UNSIGNED NOT NULL DEFAULT 0,
+		PRIMARY KEY (table_id, hist_id)
+	);`
+	// CreatePlanRegressionsTable stores the plan regressions detected from the statement summary and the bindings
+	// created to fall back to the previous plans.
+	CreatePlanRegressionsTable = `CREATE TABLE IF NOT EXISTS mysql.plan_regressions (
+		sql_digest VARCHAR(64) NOT NULL,
+		default_db TEXT NOT NULL,
+		original_sql TEXT NOT NULL,
+		bind_sql TEXT NOT NULL,
+		old_plan_digest VARCHAR(64) NOT NULL,
+		new_plan_digest VARCHAR(64) NOT NULL,
+		old_avg_latency BIGINT(64) UNSIGNED NOT NULL comment 'in nanoseconds',
+		new_avg_latency BIGINT(64) UNSIGNED NOT NULL comment 'in nanoseconds',
+		detect_time TIMESTAMP(6) NOT NULL,
+		status ENUM('applied', 'rejected') NOT NULL DEFAULT 'applied',
+		PRIMARY KEY (sql_digest, old_plan_digest, new_plan_digest),
+		KEY (detect_time)
+	);`
 )
 
 // bootstrap initiates system DB for a store.
@@ -605,11 +653,19 @@ const (
 	version86 = 86
 	// version87 adds the mysql.analyze_jobs table
 	version87 = 87
+	// version88 adds the column Resource_group to mysql.user
+	version88 = 88
+	// version89 adds the tables mysql.runaway_rules and mysql.runaway_history
+	version89 = 89
+	// version90 adds the mysql.stats_expressions table
+	version90 = 90
+	// version91 adds the mysql.plan_regressions table
+	version91 = 91
 )
 
 // currentBootstrapVersion is defined as a variable, so we can modify its value for testing.
 // please make sure this is the largest version
-var currentBootstrapVersion int64 = version87
+var currentBootstrapVersion int64 = version91
 
 var (
 	bootstrapVersion = []func(Session, int64){
@@ -700,6 +756,10 @@ var (
 		upgradeToVer85,
 		upgradeToVer86,
 		upgradeToVer87,
+		upgradeToVer88,
+		upgradeToVer89,
+		upgradeToVer90,
+		upgradeToVer91,
 	}
 )
 
@@ -1790,6 +1850,35 @@ func upgradeToVer87(s Session, ver int64) {
 	doReentrantDDL(s, CreateAnalyzeJobs)
 }
 
+func upgradeToVer88(s Session, ver int64) {
+	if ver >= version88 {
+		return
+	}
+	doReentrantDDL(s, "ALTER TABLE mysql.user ADD COLUMN `Resource_group` VARCHAR(64) NOT NULL DEFAULT '' AFTER `Repl_client_priv`", infoschema.ErrColumnExists)
+}
+
+func upgradeToVer89(s Session, ver int64) {
+	if ver >= version89 {
+		return
+	}
+	doReentrantDDL(s, CreateRunawayRulesTable)
+	doReentrantDDL(s, CreateRunawayHistoryTable)
+}
+
+func upgradeToVer90(s Session, ver int64) {
+	if ver >= version90 {
+		return
+	}
+	doReentrantDDL(s, CreateStatsExpressionsTable)
+}
+
+func upgradeToVer91(s Session, ver int64) {
+	if ver >= version91 {
+		return
+	}
+	doReentrantDDL(s, CreatePlanRegressionsTable)
+}
+
 func writeOOMAction(s Session) {
 	comment := "oom-action is `log` by default in v3.0.x, `cancel` by default in v4.0.11+"
 	mustExecute(s, `INSERT HIGH_PRIORITY INTO %n.%n VALUES (%?, %?, %?) ON DUPLICATE KEY UPDATE VARIABLE_VALUE= %?`,
@@ -1882,6 +1971,14 @@ func doDDLWorks(s Session) {
 	mustExecute(s, CreateStatsMetaHistory)
 	// Create analyze_jobs table.
 	mustExecute(s, CreateAnalyzeJobs)
+	// Create runaway_rules table.
+	mustExecute(s, CreateRunawayRulesTable)
+	// Create runaway_history table.
+	mustExecute(s, CreateRunawayHistoryTable)
+	// Create stats_expressions table.
+	mustExecute(s, CreateStatsExpressionsTable)
+	// Create plan_regressions table.
+	mustExecute(s, CreatePlanRegressionsTable)
 }
 
 // doDMLWorks executes DML statements in bootstrap stage.
@@ -1898,10 +1995,10 @@ func doDMLWorks(s Session) {
 			logutil.BgLogger().Fatal("failed to read current user. unable to secure bootstrap.", zap.Error(err))
 		}
 		mustExecute(s, `INSERT HIGH_PRIORITY INTO mysql.user VALUES
-		("localhost", "root", %?, "auth_socket", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "N", "Y", "Y", "Y", "Y", "Y", "Y", "Y")`, u.Username)
+		("localhost", "root", %?, "auth_socket", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "N", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "")`, u.Username)
 	} else {
 		mustExecute(s, `INSERT HIGH_PRIORITY INTO mysql.user VALUES
-		("%", "root", "", "mysql_native_password", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "N", "Y", "Y", "Y", "Y", "Y", "Y", "Y")`)
+		("%", "root", "", "mysql_native_password", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "N", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "")`)
 	}
 
 	// Init global system variables table.
@@ -28,6 +28,7 @@ import (
 	"fmt"
 	"runtime/pprof"
 	"runtime/trace"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -80,6 +81,7 @@ import (
 	"github.com/pingcap/tidb/session/txninfo"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/binloginfo"
+	"github.com/pingcap/tidb/sessionctx/sessionstates"
 	"github.com/pingcap/tidb/sessionctx/stmtctx"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/statistics"
@@ -95,6 +97,7 @@ import (
 	"github.com/pingcap/tidb/util/execdetails"
 	"github.com/pingcap/tidb/util/kvcache"
 	"github.com/pingcap/tidb/util/logutil"
+	"github.com/pingcap/tidb/util/sem"
 	"github.com/pingcap/tidb/util/sli"
 	"github.com/pingcap/tidb/util/sqlexec"
 	"github.com/pingcap/tidb/util/tableutil"
@@ -247,6 +250,9 @@ type session struct {
 	// all the local data in each session, and finally report them to the remote
 	// regularly.
 	stmtStats *stmtstats.StatementStats
+
+	// sessionStatesHandlers are the handlers to encode and decode session states of other modules.
+	sessionStatesHandlers map[sessionstates.SessionStateType]sessionctx.SessionStatesHandler
 }
 
 var parserPool = &sync.Pool{New: func() interface{} { return parser.New() }}
@@ -332,7 +338,7 @@ func (s *session) cleanRetryInfo() {
 			preparedObj, ok := preparedPointer.(*plannercore.CachedPrepareStmt)
 			if ok {
 				preparedAst = preparedObj.PreparedAst
-				stmtText, stmtDB = preparedObj.StmtText, preparedObj.StmtDB
+				stmtText, stmtDB = preparedObj.StmtText4PC, preparedObj.StmtDB
 				cacheKey, err = plannercore.NewPlanCacheKey(s.sessionVars, stmtText, stmtDB, preparedAst.SchemaVersion)
 				if err != nil {
 					logutil.Logger(s.currentCtx).Warn("clean cached plan failed", zap.Error(err))
@@ -1418,6 +1424,11 @@ func (s *session) SetProcessInfo(sql string, t time.Time, command byte, maxExecu
 	}
 	_, digest := s.sessionVars.StmtCtx.SQLDigest()
 	pi.Digest = digest.String()
+	// The plan digest is generated before execution only if it is required
+	// by the runaway query rules.
+	if _, planDigest := s.sessionVars.StmtCtx.GetPlanDigest(); planDigest != nil {
+		pi.PlanDigest = planDigest.String()
+	}
 	// DO NOT reset the currentPlan to nil until this query finishes execution, otherwise reentrant calls
 	// of SetProcessInfo would override Plan and PlanExplainRows to nil.
 	if command == mysql.ComSleep {
@@ -2588,6 +2599,7 @@ func (s *session) Auth(user *auth.UserIdentity, authentication []byte, salt []by
 		user.AuthHostname = authUser.Hostname
 		s.sessionVars.User = user
 		s.sessionVars.ActiveRoles = pm.GetDefaultRoles(user.AuthUsername, user.AuthHostname)
+		s.sessionVars.ResourceGroupName = pm.GetUserResourceGroup(user.AuthUsername, user.AuthHostname)
 		return true
 	}
 	return false
@@ -2624,6 +2636,7 @@ func (s *session) AuthWithoutVerification(user *auth.UserIdentity) bool {
 		user.AuthHostname = authUser.Hostname
 		s.sessionVars.User = user
 		s.sessionVars.ActiveRoles = pm.GetDefaultRoles(user.AuthUsername, user.AuthHostname)
+		s.sessionVars.ResourceGroupName = pm.GetUserResourceGroup(user.AuthUsername, user.AuthHostname)
 		return true
 	}
 	return false
@@ -2793,7 +2806,7 @@ func BootstrapSession(store kv.Storage) (*domain.Domain, error) {
 	}
 
 	concurrency := int(config.GetGlobalConfig().Performance.StatsLoadConcurrency)
-	ses, err := createSessions(store, 7+concurrency)
+	ses, err := createSessions(store, 8+concurrency)
 	if err != nil {
 		return nil, err
 	}
@@ -2859,11 +2872,15 @@ func BootstrapSession(store kv.Storage) (*domain.Domain, error) {
 	dom.TelemetryReportLoop(ses[5])
 	dom.TelemetryRotateSubWindowLoop(ses[5])
 
+	if err = dom.LoadRunawayLoop(ses[6]); err != nil {
+		return nil, err
+	}
+
 	// A sub context for update table stats, and other contexts for concurrent stats loading.
 	cnt := 1 + concurrency
 	subCtxs := make([]sessionctx.Context, cnt)
 	for i := 0; i < cnt; i++ {
-		subCtxs[i] = sessionctx.Context(ses[6+i])
+		subCtxs[i] = sessionctx.Context(ses[7+i])
 	}
 	if err = dom.LoadAndUpdateStatsLoop(subCtxs); err != nil {
 		return nil, err
@@ -2936,6 +2953,8 @@ func createSessionWithOpt(store kv.Storage, opt *Opt) (*session, error) {
 		client:          store.GetClient(),
 		mppClient:       store.GetMPPClient(),
 		stmtStats:       stmtstats.CreateStatementStats(),
+
+		sessionStatesHandlers: make(map[sessionstates.SessionStateType]sessionctx.SessionStatesHandler),
 	}
 	s.functionUsageMu.builtinFunctionUsage = make(telemetry.BuiltinFunctionsUsage)
 	if plannercore.PreparedPlanCacheEnabled() {
@@ -2970,6 +2989,8 @@ func CreateSessionWithDomain(store kv.Storage, dom *domain.Domain) (*session, er
 		client:      store.GetClient(),
 		mppClient:   store.GetMPPClient(),
 		stmtStats:   stmtstats.CreateStatementStats(),
+
+		sessionStatesHandlers: make(map[sessionstates.SessionStateType]sessionctx.SessionStatesHandler),
 	}
 	s.functionUsageMu.builtinFunctionUsage = make(telemetry.BuiltinFunctionsUsage)
 	if plannercore.PreparedPlanCacheEnabled() {
@@ -3439,3 +3460,200 @@ func (s *session) getSnapshotInterceptor() kv.SnapshotInterceptor {
 func (s *session) GetStmtStats() *stmtstats.StatementStats {
 	return s.stmtStats
 }
+
+// sysVarsNotMigrated are the session variables that only make sense in the current statement or connection,
+// so they are not migrated with the session states.
+var sysVarsNotMigrated = map[string]struct{}{
+	variable.Timestamp:           {},
+	variable.LastInsertID:        {},
+	variable.Identity:            {},
+	variable.TxnIsolationOneShot: {},
+	variable.RandSeed1:           {},
+	variable.RandSeed2:           {},
+}
+
+// SetSessionStatesHandler implements the Session.SetSessionStatesHandler interface.
+func (s *session) SetSessionStatesHandler(stateType sessionstates.SessionStateType, handler sessionctx.SessionStatesHandler) {
+	s.sessionStatesHandlers[stateType] = handler
+}
+
+func (s *session) checkSessionMigratable() error {
+	if s.txn.Valid() || s.sessionVars.InTxn() {
+		return sessionstates.ErrCannotMigrateSession.GenWithStackByArgs("session has an active transaction")
+	}
+	if s.HasLockedTables() {
+		return sessionstates.ErrCannotMigrateSession.GenWithStackByArgs("session has locked tables")
+	}
+	return nil
+}
+
+// checkSessionStatesPrivilege checks that the session states belong to the current account and that
+// the account could set them by itself, in case the privileges are revoked after they are encoded.
+func (s *session) checkSessionStatesPrivilege(sessionStates *sessionstates.SessionStates) error {
+	user := s.sessionVars.User
+	if sessionStates.User != user.String() {
+		return sessionstates.ErrInvalidSessionStates.GenWithStackByArgs(
+			fmt.Sprintf("the session states belong to '%s' but the current user is '%s'", sessionStates.User, user.String()))
+	}
+	checker := privilege.GetPrivilegeManager(s)
+	if checker == nil || user == nil {
+		return nil
+	}
+	activeRoles := s.sessionVars.ActiveRoles
+	dbs := []string{sessionStates.CurrentDB}
+	for _, info := range sessionStates.PreparedStmts {
+		dbs = append(dbs, info.StmtDB)
+	}
+	for _, db := range dbs {
+		if db != "" && !checker.DBIsVisible(activeRoles, db) {
+			return plannercore.ErrDBaccessDenied.GenWithStackByArgs(user.AuthUsername, user.AuthHostname, db)
+		}
+	}
+	// The same privileges as the SET statement are required.
+	for name := range sessionStates.SystemVars {
+		sv := variable.GetSysVar(name)
+		if sv == nil {
+			continue
+		}
+		if !sv.HasSessionScope() && !checker.RequestDynamicVerification(activeRoles, "SYSTEM_VARIABLES_ADMIN", false) {
+			return plannercore.ErrSpecificAccessDenied.GenWithStackByArgs("SUPER or SYSTEM_VARIABLES_ADMIN")
+		}
+		if sem.IsEnabled() && sem.IsInvisibleSysVar(name) && !checker.RequestDynamicVerification(activeRoles, "RESTRICTED_VARIABLES_ADMIN", false) {
+			return plannercore.ErrSpecificAccessDenied.GenWithStackByArgs("RESTRICTED_VARIABLES_ADMIN")
+		}
+	}
+	return nil
+}
+
+// EncodeSessionStates implements SessionStatesHandler.EncodeSessionStates interface.
+func (s *session) EncodeSessionStates(ctx context.Context, sctx sessionctx.Context, sessionStates *sessionstates.SessionStates) error {
+	if err := s.checkSessionMigratable(); err != nil {
+		return err
+	}
+
+	// Encode session variables. We put it here instead of SessionVars to avoid cycle import.
+	if err := s.sessionVars.EncodeSessionStates(ctx, sessionStates); err != nil {
+		return err
+	}
+	sessionStates.User = s.sessionVars.User.String()
+	// Only the system variables that differ from the global (or default) values are encoded.
+	sessionStates.SystemVars = make(map[string]string)
+	for _, sv := range variable.GetSysVars() {
+		if !sv.HasSessionScope() || sv.ReadOnly || sv.Hidden {
+			continue
+		}
+		if _, ok := sysVarsNotMigrated[sv.Name]; ok {
+			continue
+		}
+		if sem.IsEnabled() && sem.IsInvisibleSysVar(sv.Name) {
+			continue
+		}
+		val, err := variable.GetSessionOrGlobalSystemVar(s.sessionVars, sv.Name)
+		if err != nil {
+			return err
+		}
+		defaultVal := sv.Value
+		if sv.HasGlobalScope() {
+			if defaultVal, err = s.sessionVars.GlobalVarsAccessor.GetGlobalSysVar(sv.Name); err != nil {
+				return err
+			}
+		}
+		if val != defaultVal {
+			sessionStates.SystemVars[sv.Name] = val
+		}
+	}
+
+	// Encode prepared statements, including those prepared by the text protocol and the binary protocol.
+	// The binary protocol ones are supplemented by the handler in the server package.
+	stmtNames := make(map[uint32]string, len(s.sessionVars.PreparedStmtNameToID))
+	for name, id := range s.sessionVars.PreparedStmtNameToID {
+		stmtNames[id] = name
+	}
+	sessionStates.PreparedStmts = make(map[uint32]*sessionstates.PreparedStmtInfo, len(s.sessionVars.PreparedStmts))
+	for id, stmt := range s.sessionVars.PreparedStmts {
+		preparedObj, ok := stmt.(*plannercore.CachedPrepareStmt)
+		if !ok {
+			return errors.Errorf("invalid CachedPrepareStmt type")
+		}
+		sessionStates.PreparedStmts[id] = &sessionstates.PreparedStmtInfo{
+			Name:     stmtNames[id],
+			StmtText: preparedObj.StmtText,
+			StmtDB:   preparedObj.StmtDB,
+		}
+	}
+
+	// Encode local temporary tables.
+	if err := temptable.EncodeSessionStates(s, sessionStates); err != nil {
+		return err
+	}
+
+	// Encode the states of other modules.
+	for _, handler := range s.sessionStatesHandlers {
+		if err := handler.EncodeSessionStates(ctx, s, sessionStates); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DecodeSessionStates implements SessionStatesHandler.DecodeSessionStates interface.
+func (s *session) DecodeSessionStates(ctx context.Context, sctx sessionctx.Context, sessionStates *sessionstates.SessionStates) error {
+	if err := s.checkSessionMigratable(); err != nil {
+		return err
+	}
+	if err := s.checkSessionStatesPrivilege(sessionStates); err != nil {
+		return err
+	}
+
+	// Decode session variables.
+	if err := s.sessionVars.DecodeSessionStates(ctx, sessionStates); err != nil {
+		return err
+	}
+	// Set the system variables in a fixed order so that the result is deterministic.
+	names := make([]string, 0, len(sessionStates.SystemVars))
+	for name := range sessionStates.SystemVars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		// The variable may be removed in this version.
+		if variable.GetSysVar(name) == nil {
+			continue
+		}
+		if err := variable.SetSessionSystemVar(s.sessionVars, name, sessionStates.SystemVars[name]); err != nil {
+			return err
+		}
+	}
+
+	// Decode local temporary tables before the prepared statements because the statements may refer to them.
+	if err := temptable.DecodeSessionStates(s, sessionStates); err != nil {
+		return err
+	}
+
+	// Decode prepared statements. They are prepared again in their original databases.
+	currentDB := s.sessionVars.CurrentDB
+	defer func() {
+		s.sessionVars.CurrentDB = currentDB
+	}()
+	ids := make([]uint32, 0, len(sessionStates.PreparedStmts))
+	for id := range sessionStates.PreparedStmts {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		info := sessionStates.PreparedStmts[id]
+		s.sessionVars.CurrentDB = info.StmtDB
+		if err := executor.RestorePreparedStmt(ctx, s, id, info.Name, info.StmtText); err != nil {
+			return err
+		}
+	}
+	s.sessionVars.CurrentDB = currentDB
+
+	// Decode the states of other modules.
+	for _, handler := range s.sessionStatesHandlers {
+		if err := handler.DecodeSessionStates(ctx, s, sessionStates); err != nil {
+			return err
+		}
+	}
+	return nil
+}
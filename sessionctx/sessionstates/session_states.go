@@ -0,0 +1,157 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sessionstates
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+
+	"github.com/pingcap/tidb/config"
+	"github.com/pingcap/tidb/errno"
+	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/dbterror"
+)
+
+// currentVersion is the version of the encoded session states.
+// It must be increased once the format of SessionStates is changed incompatibly.
+const currentVersion = 1
+
+// SessionStateType is the type of session states.
+type SessionStateType int
+
+// These enums represents the types of session state handlers.
+const (
+	// StatePrepareStmt represents prepared statements.
+	StatePrepareStmt SessionStateType = iota
+)
+
+// Session states errors.
+var (
+	ErrCannotMigrateSession = dbterror.ClassSession.NewStd(errno.ErrCannotMigrateSession)
+	ErrInvalidSessionStates = dbterror.ClassSession.NewStd(errno.ErrInvalidSessionStates)
+)
+
+// PreparedStmtInfo contains the information about prepared statements, both text and binary protocols.
+type PreparedStmtInfo struct {
+	// Name is empty for the statements prepared by the binary protocol.
+	Name     string `json:"name,omitempty"`
+	StmtText string `json:"text"`
+	StmtDB   string `json:"db,omitempty"`
+	// ParamTypes and BoundParams are only set for the binary protocol.
+	ParamTypes  []byte   `json:"param-types,omitempty"`
+	BoundParams [][]byte `json:"bound-params,omitempty"`
+}
+
+// KeyValue is a key-value pair of the data in local temporary tables.
+type KeyValue struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// LocalTemporaryTable contains the definition and the data of a local temporary table.
+type LocalTemporaryTable struct {
+	DBName    string           `json:"db"`
+	TableInfo *model.TableInfo `json:"table"`
+	AutoIDs   int64            `json:"auto-ids,omitempty"`
+	Data      []KeyValue       `json:"data,omitempty"`
+}
+
+// SessionStates contains all the states in the session that should be migrated when the session
+// is migrated to another server. It is shown by `show session_states` and recovered by `set session_states`.
+type SessionStates struct {
+	// User is the account of the session, the states can only be restored by the same account.
+	User                 string                       `json:"user,omitempty"`
+	UserVars             map[string]*types.Datum      `json:"user-var-values,omitempty"`
+	UserVarTypes         map[string]*types.FieldType  `json:"user-var-types,omitempty"`
+	SystemVars           map[string]string            `json:"sys-vars,omitempty"`
+	PreparedStmts        map[uint32]*PreparedStmtInfo `json:"prepared-stmts,omitempty"`
+	PreparedStmtID       uint32                       `json:"prepared-stmt-id,omitempty"`
+	CurrentDB            string                       `json:"current-db,omitempty"`
+	LastInsertID         uint64                       `json:"last-insert-id,omitempty"`
+	LocalTemporaryTables []*LocalTemporaryTable       `json:"local-temporary-tables,omitempty"`
+}
+
+// signedSessionStates is the format of the blob returned by `show session_states`.
+type signedSessionStates struct {
+	Version   int             `json:"version"`
+	States    json.RawMessage `json:"states"`
+	Signature string          `json:"signature"`
+}
+
+func signingKey() ([]byte, error) {
+	key := config.GetGlobalConfig().Security.SessionStatesSigningKey
+	if len(key) == 0 {
+		return nil, ErrCannotMigrateSession.GenWithStackByArgs("security.session-states-signing-key is not configured")
+	}
+	return []byte(key), nil
+}
+
+func sign(key []byte, version int, states []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(strconv.Itoa(version)))
+	mac.Write([]byte{':'})
+	mac.Write(states)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Encode encodes the session states into a versioned blob signed by the signing key,
+// so that only the servers sharing the key can restore it.
+func Encode(sessionStates *SessionStates) (string, error) {
+	key, err := signingKey()
+	if err != nil {
+		return "", err
+	}
+	states, err := json.Marshal(sessionStates)
+	if err != nil {
+		return "", err
+	}
+	blob, err := json.Marshal(&signedSessionStates{
+		Version:   currentVersion,
+		States:    states,
+		Signature: sign(key, currentVersion, states),
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(blob), nil
+}
+
+// Decode verifies the version and the signature of the blob and decodes the session states from it.
+func Decode(blob string) (*SessionStates, error) {
+	key, err := signingKey()
+	if err != nil {
+		return nil, err
+	}
+	var signed signedSessionStates
+	if err := json.Unmarshal([]byte(blob), &signed); err != nil {
+		return nil, ErrInvalidSessionStates.GenWithStackByArgs(err.Error())
+	}
+	if signed.Version != currentVersion {
+		return nil, ErrInvalidSessionStates.GenWithStackByArgs("unsupported version " + strconv.Itoa(signed.Version))
+	}
+	expected := sign(key, signed.Version, signed.States)
+	if !hmac.Equal([]byte(expected), []byte(signed.Signature)) {
+		return nil, ErrInvalidSessionStates.GenWithStackByArgs("signature mismatch")
+	}
+	sessionStates := new(SessionStates)
+	if err := json.Unmarshal(signed.States, sessionStates); err != nil {
+		return nil, ErrInvalidSessionStates.GenWithStackByArgs(err.Error())
+	}
+	return sessionStates, nil
+}
@@ -0,0 +1,200 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sessionstates_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pingcap/tidb/config"
+	"github.com/pingcap/tidb/errno"
+	"github.com/pingcap/tidb/parser/auth"
+	"github.com/pingcap/tidb/sessionctx/sessionstates"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/testkit"
+	"github.com/pingcap/tidb/util/sem"
+	"github.com/pingcap/tidb/util/sqlexec"
+	"github.com/stretchr/testify/require"
+)
+
+func showSessionStates(tk *testkit.TestKit) string {
+	rows := tk.MustQuery("show session_states").Rows()
+	return rows[0][0].(string)
+}
+
+func setSessionStates(tk *testkit.TestKit, blob string) {
+	tk.MustExec(sqlexec.MustEscapeSQL("set session_states %?", blob))
+}
+
+func TestEncodeDecode(t *testing.T) {
+	states := &sessionstates.SessionStates{
+		SystemVars:   map[string]string{"sql_mode": ""},
+		CurrentDB:    "test",
+		LastInsertID: 10,
+	}
+	blob, err := sessionstates.Encode(states)
+	require.NoError(t, err)
+	decoded, err := sessionstates.Decode(blob)
+	require.NoError(t, err)
+	require.Equal(t, states, decoded)
+
+	// Tampered states.
+	_, err = sessionstates.Decode(strings.Replace(blob, `"test"`, `"mysql"`, 1))
+	require.True(t, sessionstates.ErrInvalidSessionStates.Equal(err))
+	// Malformed blob.
+	_, err = sessionstates.Decode("abc")
+	require.True(t, sessionstates.ErrInvalidSessionStates.Equal(err))
+
+	// The blob can't be encoded or decoded without the signing key.
+	defer config.RestoreFunc()()
+	config.UpdateGlobal(func(conf *config.Config) {
+		conf.Security.SessionStatesSigningKey = ""
+	})
+	_, err = sessionstates.Encode(states)
+	require.True(t, sessionstates.ErrCannotMigrateSession.Equal(err))
+	_, err = sessionstates.Decode(blob)
+	require.True(t, sessionstates.ErrCannotMigrateSession.Equal(err))
+}
+
+func TestUserAndSystemVars(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+
+	tk1 := testkit.NewTestKit(t, store)
+	tk1.MustExec("set @a = 1, @b = 'abc', @c = 1.5, @d = null")
+	tk1.MustExec("set @e = now()")
+	tk1.MustExec("set sql_mode = 'ANSI_QUOTES'")
+	tk1.MustExec("set tidb_opt_agg_push_down = 1")
+	tk1.MustExec("use test")
+	tk1.MustExec("create table t(id int primary key auto_increment)")
+	tk1.MustExec("insert into t values ()")
+	blob := showSessionStates(tk1)
+
+	tk2 := testkit.NewTestKit(t, store)
+	setSessionStates(tk2, blob)
+	tk2.MustQuery("select @a, @b, @c, @d").Check(testkit.Rows("1 abc 1.5 <nil>"))
+	tk2.MustQuery("select @e").Check(tk1.MustQuery("select @e").Rows())
+	tk2.MustQuery("select @@sql_mode, @@tidb_opt_agg_push_down").Check(testkit.Rows("ANSI_QUOTES 1"))
+	tk2.MustQuery("select database(), last_insert_id()").Check(testkit.Rows("test 1"))
+}
+
+func TestPreparedStmts(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+
+	tk1 := testkit.NewTestKit(t, store)
+	tk1.MustExec("use test")
+	tk1.MustExec("create table t(id int)")
+	tk1.MustExec("insert into t values (1), (2)")
+	tk1.MustExec("prepare stmt from 'select * from t where id > ?'")
+	tk1.MustExec("use mysql")
+	blob := showSessionStates(tk1)
+
+	tk2 := testkit.NewTestKit(t, store)
+	setSessionStates(tk2, blob)
+	// The statement is prepared in its original database.
+	tk2.MustExec("set @x = 1")
+	tk2.MustQuery("execute stmt using @x").Check(testkit.Rows("2"))
+	tk2.MustQuery("select database()").Check(testkit.Rows("mysql"))
+	// The statement IDs are not reused.
+	tk2.MustExec("prepare stmt2 from 'select 1'")
+	require.Greater(t, tk2.Session().GetSessionVars().PreparedStmtNameToID["stmt2"], tk2.Session().GetSessionVars().PreparedStmtNameToID["stmt"])
+}
+
+func TestLocalTemporaryTables(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+
+	tk1 := testkit.NewTestKit(t, store)
+	tk1.MustExec("use test")
+	tk1.MustExec("create temporary table tmp(id int primary key auto_increment, v int, key(v))")
+	tk1.MustExec("insert into tmp(v) values (1), (2), (3)")
+	tk1.MustExec("delete from tmp where v = 2")
+	blob := showSessionStates(tk1)
+
+	tk2 := testkit.NewTestKit(t, store)
+	setSessionStates(tk2, blob)
+	tk2.MustQuery("select * from test.tmp order by id").Check(testkit.Rows("1 1", "3 3"))
+	tk2.MustQuery("select v from test.tmp use index(v) where v > 1").Check(testkit.Rows("3"))
+	// The auto IDs continue from the original ones.
+	tk2.MustExec("insert into test.tmp(v) values (4)")
+	tk2.MustQuery("select * from test.tmp where v = 4").Check(testkit.Rows("4 4"))
+	// The temporary table is invisible to other sessions.
+	tk3 := testkit.NewTestKit(t, store)
+	tk3.MustGetErrCode("select * from test.tmp", errno.ErrNoSuchTable)
+}
+
+func TestCannotMigrate(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+
+	tk := testkit.NewTestKit(t, store)
+	blob := showSessionStates(tk)
+	tk.MustExec("begin")
+	err := tk.QueryToErr("show session_states")
+	require.True(t, sessionstates.ErrCannotMigrateSession.Equal(err))
+	tk.MustGetErrCode(sqlexec.MustEscapeSQL("set session_states %?", blob), errno.ErrCannotMigrateSession)
+	tk.MustExec("rollback")
+	setSessionStates(tk, blob)
+	tk.MustGetErrCode("set session_states 'abc'", errno.ErrInvalidSessionStates)
+}
+
+func TestPrivileges(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("create user u1, u2")
+	tk.MustExec("create database db1")
+	tk.MustExec("grant select on db1.* to u1")
+	newUserTestKit := func(user string) *testkit.TestKit {
+		userTK := testkit.NewTestKit(t, store)
+		require.True(t, userTK.Session().Auth(&auth.UserIdentity{Username: user, Hostname: "%"}, nil, nil))
+		return userTK
+	}
+
+	tk1 := newUserTestKit("u1")
+	tk1.MustExec("use db1")
+	blob := showSessionStates(tk1)
+	// The states can only be restored by the same account.
+	tk2 := newUserTestKit("u2")
+	tk2.MustGetErrCode(sqlexec.MustEscapeSQL("set session_states %?", blob), errno.ErrInvalidSessionStates)
+	tk.MustGetErrCode(sqlexec.MustEscapeSQL("set session_states %?", blob), errno.ErrInvalidSessionStates)
+	setSessionStates(newUserTestKit("u1"), blob)
+
+	// The privileges are checked again when the states are restored.
+	tk.MustExec("revoke select on db1.* from u1")
+	tk1 = newUserTestKit("u1")
+	tk1.MustGetErrCode(sqlexec.MustEscapeSQL("set session_states %?", blob), errno.ErrDBaccessDenied)
+
+	states := &sessionstates.SessionStates{
+		User:       "u1@%",
+		SystemVars: map[string]string{variable.TiDBGCEnable: "OFF"},
+	}
+	blob, err := sessionstates.Encode(states)
+	require.NoError(t, err)
+	tk1.MustGetErrCode(sqlexec.MustEscapeSQL("set session_states %?", blob), errno.ErrSpecificAccessDenied)
+
+	sem.Enable()
+	defer sem.Disable()
+	states.SystemVars = map[string]string{variable.TiDBOptWriteRowID: "ON"}
+	blob, err = sessionstates.Encode(states)
+	require.NoError(t, err)
+	tk1.MustGetErrCode(sqlexec.MustEscapeSQL("set session_states %?", blob), errno.ErrSpecificAccessDenied)
+	tk.MustExec("grant RESTRICTED_VARIABLES_ADMIN on *.* to u1")
+	tk1 = newUserTestKit("u1")
+	setSessionStates(tk1, blob)
+	tk1.MustQuery("select @@tidb_opt_write_row_id").Check(testkit.Rows("ON"))
+}
@@ -16,6 +16,7 @@ package variable
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/binary"
 	"fmt"
@@ -41,6 +42,7 @@ import (
 	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/parser/terror"
+	"github.com/pingcap/tidb/sessionctx/sessionstates"
 	"github.com/pingcap/tidb/sessionctx/stmtctx"
 	pumpcli "github.com/pingcap/tidb/tidb-binlog/pump_client"
 	"github.com/pingcap/tidb/types"
@@ -981,6 +983,10 @@ type SessionVars struct {
 	// TemporaryTableData stores committed kv values for temporary table for current session.
 	TemporaryTableData TemporaryTableData
 
+	// HypoIndexes stores the hypothetical indexes created by `CREATE INDEX ... TYPE HYPO` in the session, which are
+	// keyed by the lower-case schema name, table name and index name. Only EXPLAIN statements can see them.
+	HypoIndexes map[string]map[string]map[string]*model.IndexInfo
+
 	// MPPStoreLastFailTime records the lastest fail time that a TiFlash store failed.
 	MPPStoreLastFailTime map[string]time.Time
 
@@ -1026,6 +1032,19 @@ type SessionVars struct {
 	RcReadCheckTS bool
 	// RemoveOrderbyInSubquery indicates whether to remove ORDER BY in subquery.
 	RemoveOrderbyInSubquery bool
+	// EnableGeneralPlanCache indicates whether to cache the plans of the plain-text queries.
+	EnableGeneralPlanCache bool
+	// EnableOuterJoinReorder indicates whether the join reorder can reorder the outer joins together with the inner joins.
+	EnableOuterJoinReorder bool
+	// EnableRuntimeFilter indicates whether the hash join pushes the runtime filters into the probe side scan.
+	EnableRuntimeFilter bool
+	// RuntimeFilterMaxInSize is the max number of distinct build side values in an IN runtime filter.
+	RuntimeFilterMaxInSize int
+	// TraceParent is the W3C `traceparent` supplied by the caller, empty if none.
+	TraceParent string
+
+	// ResourceGroupName is the resource group of the current user, empty means the default group.
+	ResourceGroupName string
 }
 
 // InitStatementContext initializes a StatementContext, the object is reused to reduce allocation.
@@ -1262,6 +1281,10 @@ func NewSessionVars() *SessionVars {
 		StatsLoadSyncWait:           StatsLoadSyncWait.Load(),
 		EnableLegacyInstanceScope:   DefEnableLegacyInstanceScope,
 		RemoveOrderbyInSubquery:     DefTiDBRemoveOrderbyInSubquery,
+		EnableGeneralPlanCache:      DefTiDBEnableGeneralPlanCache,
+		EnableOuterJoinReorder:      DefTiDBEnableOuterJoinReorder,
+		EnableRuntimeFilter:         DefTiDBEnableRuntimeFilter,
+		RuntimeFilterMaxInSize:      DefTiDBRuntimeFilterMaxInSize,
 	}
 	vars.KVVars = tikvstore.NewVariables(&vars.Killed)
 	vars.Concurrency = Concurrency{
@@ -1607,6 +1630,52 @@ func (s *SessionVars) WithdrawAllPreparedStmt() {
 	metrics.PreparedStmtGauge.Set(float64(afterMinus))
 }
 
+// EncodeSessionStates saves session states into SessionStates.
+func (s *SessionVars) EncodeSessionStates(ctx context.Context, sessionStates *sessionstates.SessionStates) error {
+	// Encode user-defined variables.
+	s.UsersLock.RLock()
+	sessionStates.UserVars = make(map[string]*types.Datum, len(s.Users))
+	for name, userVar := range s.Users {
+		sessionStates.UserVars[name] = userVar.Clone()
+	}
+	sessionStates.UserVarTypes = make(map[string]*types.FieldType, len(s.UserVarTypes))
+	for name, userVarType := range s.UserVarTypes {
+		sessionStates.UserVarTypes[name] = userVarType.Clone()
+	}
+	s.UsersLock.RUnlock()
+
+	sessionStates.PreparedStmtID = s.preparedStmtID
+	sessionStates.CurrentDB = s.CurrentDB
+	sessionStates.LastInsertID = s.StmtCtx.PrevLastInsertID
+	return nil
+}
+
+// DecodeSessionStates restores session states from SessionStates.
+func (s *SessionVars) DecodeSessionStates(ctx context.Context, sessionStates *sessionstates.SessionStates) error {
+	// Decode user-defined variables.
+	s.UsersLock.Lock()
+	s.Users = make(map[string]types.Datum, len(sessionStates.UserVars))
+	for name, userVar := range sessionStates.UserVars {
+		s.Users[name] = *userVar
+	}
+	s.UserVarTypes = make(map[string]*types.FieldType, len(sessionStates.UserVarTypes))
+	for name, userVarType := range sessionStates.UserVarTypes {
+		s.UserVarTypes[name] = userVarType
+	}
+	s.UsersLock.Unlock()
+
+	if sessionStates.PreparedStmtID > s.preparedStmtID {
+		s.preparedStmtID = sessionStates.PreparedStmtID
+	}
+	if s.CurrentDB != sessionStates.CurrentDB {
+		s.CurrentDB = sessionStates.CurrentDB
+		s.CurrentDBChanged = true
+	}
+	// The last insert ID is passed to the next statement like the last insert ID of a statement.
+	s.StmtCtx.PrevLastInsertID = sessionStates.LastInsertID
+	return nil
+}
+
 // SetStmtVar sets the value of a system variable temporarily
 func (s *SessionVars) SetStmtVar(name string, val string) error {
 	s.stmtVars[name] = val
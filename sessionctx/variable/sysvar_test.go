@@ -947,3 +947,20 @@ func TestTiDBBatchPendingTiFlashCount(t *testing.T) {
 	require.Error(t, err)
 	require.EqualError(t, err, "[variable:1232]Incorrect argument type to variable 'tidb_batch_pending_tiflash_count'")
 }
+
+func TestTiDBTraceParent(t *testing.T) {
+	sv := GetSysVar(TiDBTraceParent)
+	vars := NewSessionVars()
+	val, err := sv.Validate(vars, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", ScopeSession)
+	require.NoError(t, err)
+	require.NoError(t, sv.SetSessionFromHook(vars, val))
+	require.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", vars.TraceParent)
+
+	_, err = sv.Validate(vars, "00-4bf92f3577b34da6a3ce929d0e0e4736", ScopeSession)
+	require.EqualError(t, err, "[variable:1231]Variable 'tidb_trace_parent' can't be set to the value of '00-4bf92f3577b34da6a3ce929d0e0e4736'")
+
+	val, err = sv.Validate(vars, "", ScopeSession)
+	require.NoError(t, err)
+	require.NoError(t, sv.SetSessionFromHook(vars, val))
+	require.Empty(t, vars.TraceParent)
+}
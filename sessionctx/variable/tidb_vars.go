@@ -217,6 +217,11 @@ const (
 
 	// TiDBSysdateIsNow is the name of the `tidb_sysdate_is_now` system variable
 	TiDBSysdateIsNow = "tidb_sysdate_is_now"
+
+	// TiDBTraceParent is the W3C `traceparent` of the caller, statements of the session are traced
+	// as children of it when its sampled flag is set. The `traceparent` query attribute of a statement
+	// takes precedence over it.
+	TiDBTraceParent = "tidb_trace_parent"
 )
 
 // TiDB system variable names that both in session and global scope.
@@ -492,6 +497,13 @@ const (
 	// TiDBEvolvePlanBaselines indicates whether the evolution of plan baselines is enabled.
 	TiDBEvolvePlanBaselines = "tidb_evolve_plan_baselines"
 
+	// TiDBFixPlanRegressions indicates whether the plan regressions are detected and fixed by binding the previous plans automatically.
+	TiDBFixPlanRegressions = "tidb_fix_plan_regressions"
+
+	// TiDBPlanRegressionRatio is the ratio of the average latency of the new plan to that of the previous plan,
+	// above which the new plan is regarded as a regression.
+	TiDBPlanRegressionRatio = "tidb_plan_regression_ratio"
+
 	// TiDBEnableExtendedStats indicates whether the extended statistics feature is enabled.
 	TiDBEnableExtendedStats = "tidb_enable_extended_stats"
 
@@ -597,6 +609,19 @@ const (
 	// TiDBRemoveOrderbyInSubquery indicates whether to remove ORDER BY in subquery.
 	TiDBRemoveOrderbyInSubquery = "tidb_remove_orderby_in_subquery"
 
+	// TiDBEnableGeneralPlanCache indicates whether to cache the plans of the plain-text (non-prepared) queries.
+	TiDBEnableGeneralPlanCache = "tidb_enable_general_plan_cache"
+
+	// TiDBEnableOuterJoinReorder indicates whether the join reorder can reorder the outer joins together with the inner joins.
+	TiDBEnableOuterJoinReorder = "tidb_enable_outer_join_reorder"
+
+	// TiDBEnableRuntimeFilter indicates whether the hash join pushes the runtime filters built from its build side into the probe side scan.
+	TiDBEnableRuntimeFilter = "tidb_enable_runtime_filter"
+
+	// TiDBRuntimeFilterMaxInSize is the max number of distinct build side values in an IN runtime filter,
+	// a MIN/MAX runtime filter is used when the build side has more distinct values.
+	TiDBRuntimeFilterMaxInSize = "tidb_runtime_filter_max_in_size"
+
 	// TiDBEnablePseudoForOutdatedStats indicates whether use pseudo for outdated stats
 	TiDBEnablePseudoForOutdatedStats = "tidb_enable_pseudo_for_outdated_stats"
 
@@ -661,6 +686,11 @@ const (
 	TiDBMemQuotaBindingCache = "tidb_mem_quota_binding_cache"
 	// TiDBRCReadCheckTS indicates the tso optimization for read-consistency read is enabled.
 	TiDBRCReadCheckTS = "tidb_rc_read_check_ts"
+	// TiDBTraceSampleRate is the probability that a statement without a caller supplied trace context is traced.
+	TiDBTraceSampleRate = "tidb_trace_sample_rate"
+	// TiDBResourceGroupQueueTimeout is the max time in milliseconds that a statement waits for the
+	// admission of its resource group before it is rejected.
+	TiDBResourceGroupQueueTimeout = "tidb_resource_group_queue_timeout"
 )
 
 // TiDB intentional limits
@@ -815,6 +845,8 @@ const (
 	DefTiDBStmtSummaryMaxStmtCount        = 3000
 	DefTiDBStmtSummaryMaxSQLLength        = 4096
 	DefTiDBCapturePlanBaseline            = Off
+	DefTiDBFixPlanRegressions             = Off
+	DefTiDBPlanRegressionRatio            = 2.0
 	DefTiDBEnableIndexMerge               = true
 	DefEnableLegacyInstanceScope          = true
 	DefTiDBTableCacheLease                = 3 // 3s
@@ -829,8 +861,14 @@ const (
 	DefTiDBBatchPendingTiFlashCount       = 4000
 	DefRCReadCheckTS                      = false
 	DefTiDBRemoveOrderbyInSubquery        = false
+	DefTiDBEnableGeneralPlanCache         = false
+	DefTiDBEnableOuterJoinReorder         = false
+	DefTiDBEnableRuntimeFilter            = false
+	DefTiDBRuntimeFilterMaxInSize         = 1024
 	DefTiDBReadStaleness                  = 0
 	DefTiDBGCMaxWaitTime                  = 24 * 60 * 60
+	DefTiDBTraceSampleRate                = 0.0
+	DefTiDBResourceGroupQueueTimeout      = 5000
 )
 
 // Process global variables.
@@ -866,4 +904,5 @@ var (
 	StatsLoadPseudoTimeout                = atomic.NewBool(DefTiDBStatsLoadPseudoTimeout)
 	MemQuotaBindingCache                  = atomic.NewInt64(DefTiDBMemQuotaBindingCache)
 	GCMaxWaitTime                         = atomic.NewInt64(DefTiDBGCMaxWaitTime)
+	ResourceGroupQueueTimeout             = atomic.NewInt64(DefTiDBResourceGroupQueueTimeout)
 )
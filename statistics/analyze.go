@@ -16,6 +16,8 @@ package statistics
 
 import (
 	"fmt"
+
+	"github.com/pingcap/tidb/parser/model"
 )
 
 // AnalyzeTableID is hybrid table id used to analyze table.
@@ -80,4 +82,6 @@ type AnalyzeResults struct {
 	BaseCount int64
 	// BaseModifyCnt is the original modify_count in mysql.stats_meta at the beginning of analyze.
 	BaseModifyCnt int64
+	// ExprsInfo is the hidden virtual columns of the analyzed expressions. Their statistics are in Ars like the columns.
+	ExprsInfo []*model.ColumnInfo
 }
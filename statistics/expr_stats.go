@@ -0,0 +1,233 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statistics
+
+import (
+	"hash/fnv"
+	"strconv"
+	"strings"
+
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/stmtctx"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/types/json"
+	"github.com/pingcap/tidb/util/chunk"
+)
+
+// exprStatsIDBase is the lower bound of the histogram IDs of expression statistics. The histogram of an expression is
+// stored in mysql.stats_histograms as a hidden virtual column, so its ID must never collide with a real column ID.
+const exprStatsIDBase = int64(1) << 48
+
+// IsExprStatsID checks whether the histogram ID belongs to the statistics of an expression.
+func IsExprStatsID(id int64) bool {
+	return id >= exprStatsIDBase
+}
+
+// NewExprStatsColumnInfo builds the hidden virtual column which carries the statistics of the expression.
+// The ID is derived from the expression string, so analyzing the same expression again overwrites the old statistics.
+func NewExprStatsColumnInfo(exprStr string, tp *types.FieldType) *model.ColumnInfo {
+	h := fnv.New64a()
+	// The hash of fnv never returns error.
+	_, _ = h.Write([]byte(exprStr))
+	return &model.ColumnInfo{
+		ID:                  exprStatsIDBase | int64(h.Sum64()&uint64(exprStatsIDBase-1)),
+		Name:                model.NewCIStr(exprStr),
+		Offset:              -1,
+		FieldType:           *tp,
+		GeneratedExprString: exprStr,
+		Hidden:              true,
+		State:               model.StatePublic,
+	}
+}
+
+// ExprStatsKey returns the key used to match an expression against the collected expression statistics.
+// Columns are identified by their column ID, so the key is the same no matter which query the expression comes from.
+// The second return value is false if the expression can't have statistics.
+func ExprStatsKey(sc *stmtctx.StatementContext, expr expression.Expression) (string, bool) {
+	var sb strings.Builder
+	if !writeExprStatsKey(sc, &sb, expr) {
+		return "", false
+	}
+	return sb.String(), true
+}
+
+func writeExprStatsKey(sc *stmtctx.StatementContext, sb *strings.Builder, expr expression.Expression) bool {
+	switch x := expr.(type) {
+	case *expression.Column:
+		if x.ID <= 0 {
+			return false
+		}
+		sb.WriteString("#")
+		sb.WriteString(strconv.FormatInt(x.ID, 10))
+	case *expression.Constant:
+		if x.DeferredExpr != nil || x.ParamMarker != nil {
+			return false
+		}
+		sb.Write(x.HashCode(sc))
+	case *expression.ScalarFunction:
+		sb.WriteString(x.FuncName.L)
+		sb.WriteString("(")
+		for i, arg := range x.GetArgs() {
+			if i > 0 {
+				sb.WriteString(",")
+			}
+			if !writeExprStatsKey(sc, sb, arg) {
+				return false
+			}
+		}
+		sb.WriteString(")")
+	default:
+		return false
+	}
+	return true
+}
+
+// substituteExprStats replaces the sub-expressions which have expression statistics with columns carrying these
+// statistics, so that they can be estimated like the normal columns. The returned HistColl contains the statistics
+// of the substituted columns. If nothing is substituted, the original expressions and HistColl are returned.
+func (coll *HistColl) substituteExprStats(sctx sessionctx.Context, exprs []expression.Expression) ([]expression.Expression, *HistColl) {
+	if len(coll.ExprStats) == 0 {
+		return exprs, coll
+	}
+	substituted := make(map[string]*expression.Column)
+	newExprs := make([]expression.Expression, 0, len(exprs))
+	for _, expr := range exprs {
+		newExprs = append(newExprs, coll.substituteExprStatsImpl(sctx, expr, substituted))
+	}
+	if len(substituted) == 0 {
+		return exprs, coll
+	}
+	newColl := *coll
+	newColl.Columns = make(map[int64]*Column, len(coll.Columns)+len(substituted))
+	for id, col := range coll.Columns {
+		newColl.Columns[id] = col
+	}
+	for key, col := range substituted {
+		newColl.Columns[col.UniqueID] = coll.ExprStats[key]
+	}
+	return newExprs, &newColl
+}
+
+func (coll *HistColl) substituteExprStatsImpl(sctx sessionctx.Context, expr expression.Expression, substituted map[string]*expression.Column) expression.Expression {
+	sf, ok := expr.(*expression.ScalarFunction)
+	if !ok {
+		return expr
+	}
+	if newSf := coll.normalizeJSONExtractCmp(sctx, sf); newSf != nil {
+		sf = newSf
+	}
+	if key, ok := ExprStatsKey(sctx.GetSessionVars().StmtCtx, sf); ok {
+		if col, ok := substituted[key]; ok {
+			return col
+		}
+		if exprStats, ok := coll.ExprStats[key]; ok && !exprStats.IsInvalid(sctx, coll.Pseudo) {
+			col := &expression.Column{
+				UniqueID: sctx.GetSessionVars().AllocPlanColumnID(),
+				ID:       exprStats.Info.ID,
+				RetType:  sf.GetType(),
+				OrigName: exprStats.Info.Name.O,
+			}
+			substituted[key] = col
+			return col
+		}
+	}
+	args := sf.GetArgs()
+	var newArgs []expression.Expression
+	for i, arg := range args {
+		newArg := coll.substituteExprStatsImpl(sctx, arg, substituted)
+		if newArg == arg {
+			continue
+		}
+		if newArgs == nil {
+			newArgs = make([]expression.Expression, len(args))
+			copy(newArgs, args)
+		}
+		newArgs[i] = newArg
+	}
+	if newArgs == nil {
+		return expr
+	}
+	newExpr, err := expression.NewFunction(sctx, sf.FuncName.L, sf.RetType, newArgs...)
+	if err != nil {
+		return expr
+	}
+	return newExpr
+}
+
+// normalizeJSONExtractCmp rewrites the comparison between JSON_EXTRACT and a JSON string, like `JSON_EXTRACT(j, '$.s') = 'ok'`,
+// to the comparison on the unquoted scalar `JSON_UNQUOTE(JSON_EXTRACT(j, '$.s')) = 'ok'`, because the statistics of
+// JSON_EXTRACT are collected on its unquoted form. It returns nil if the comparison can't be rewritten or there're no
+// statistics of the unquoted form.
+func (coll *HistColl) normalizeJSONExtractCmp(sctx sessionctx.Context, sf *expression.ScalarFunction) *expression.ScalarFunction {
+	switch sf.FuncName.L {
+	case ast.EQ, ast.NE, ast.LT, ast.LE, ast.GT, ast.GE, ast.NullEQ:
+	default:
+		return nil
+	}
+	args := sf.GetArgs()
+	extractIdx := -1
+	for i, arg := range args {
+		if f, ok := arg.(*expression.ScalarFunction); ok && f.FuncName.L == ast.JSONExtract {
+			extractIdx = i
+		}
+	}
+	if extractIdx < 0 {
+		return nil
+	}
+	// The string compared with JSON_EXTRACT is wrapped by a cast to JSON.
+	other := args[1-extractIdx]
+	if f, ok := other.(*expression.ScalarFunction); ok && f.FuncName.L == ast.Cast {
+		other = f.GetArgs()[0]
+	}
+	con, ok := other.(*expression.Constant)
+	if !ok || con.DeferredExpr != nil || con.ParamMarker != nil {
+		return nil
+	}
+	val, err := args[1-extractIdx].Eval(chunk.Row{})
+	if err != nil || val.Kind() != types.KindMysqlJSON {
+		return nil
+	}
+	jsonVal := val.GetMysqlJSON()
+	if jsonVal.TypeCode != json.TypeCodeString {
+		return nil
+	}
+	unquote, err := expression.NewFunction(sctx, ast.JSONUnquote, types.NewFieldType(mysql.TypeUnspecified), args[extractIdx].Clone())
+	if err != nil {
+		return nil
+	}
+	key, ok := ExprStatsKey(sctx.GetSessionVars().StmtCtx, unquote)
+	if !ok {
+		return nil
+	}
+	if _, ok := coll.ExprStats[key]; !ok {
+		return nil
+	}
+	newArgs := make([]expression.Expression, 2)
+	newArgs[extractIdx] = unquote
+	newArgs[1-extractIdx] = &expression.Constant{
+		Value:   types.NewStringDatum(string(jsonVal.GetString())),
+		RetType: unquote.GetType().Clone(),
+	}
+	newExpr, err := expression.NewFunction(sctx, sf.FuncName.L, sf.RetType, newArgs...)
+	if err != nil {
+		return nil
+	}
+	newSf, _ := newExpr.(*expression.ScalarFunction)
+	return newSf
+}
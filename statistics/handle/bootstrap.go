@@ -381,6 +381,28 @@ func (h *Handle) initStatsBuckets(cache *statsCache) error {
 	return nil
 }
 
+func (h *Handle) initStatsExpressions(is infoschema.InfoSchema, cache *statsCache) error {
+	reader := &statsReader{ctx: h.mu.ctx.(sqlexec.RestrictedSQLExecutor)}
+	rows, _, err := reader.read("select HIGH_PRIORITY distinct table_id from mysql.stats_expressions")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, row := range rows {
+		table, ok := cache.tables[row.GetInt64(0)]
+		if !ok {
+			continue
+		}
+		tbl, ok := h.getTableByPhysicalID(is, table.PhysicalID)
+		if !ok {
+			continue
+		}
+		if err = h.exprStatsFromStorage(reader, table, tbl.Meta()); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
 // InitStats will init the stats cache using full load strategy.
 func (h *Handle) InitStats(is infoschema.InfoSchema) (err error) {
 	h.mu.Lock()
@@ -415,6 +437,10 @@ func (h *Handle) InitStats(is infoschema.InfoSchema) (err error) {
 	if err != nil {
 		return errors.Trace(err)
 	}
+	err = h.initStatsExpressions(is, &cache)
+	if err != nil {
+		return errors.Trace(err)
+	}
 	cache.initMemoryUsage()
 	h.updateStatsCache(cache)
 	return nil
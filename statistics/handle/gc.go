@@ -22,6 +22,7 @@ import (
 	"github.com/cznic/mathutil"
 	"github.com/pingcap/errors"
 	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/statistics"
 	"github.com/pingcap/tidb/util/logutil"
 	"github.com/pingcap/tidb/util/sqlexec"
 	"github.com/tikv/client-go/v2/oracle"
@@ -85,6 +86,9 @@ func (h *Handle) gcTableStats(is infoschema.InfoSchema, physicalID int64) error
 					break
 				}
 			}
+		} else if statistics.IsExprStatsID(histID) {
+			// The statistics of expressions are only removed together with the table.
+			find = true
 		} else {
 			for _, col := range tblInfo.Columns {
 				if col.ID == histID {
@@ -233,6 +237,9 @@ func (h *Handle) DeleteTableStatsFromKV(statsIDs []int64) (err error) {
 		if _, err = exec.ExecuteInternal(ctx, "delete from mysql.analyze_options where table_id = %?", statsID); err != nil {
 			return err
 		}
+		if _, err = exec.ExecuteInternal(ctx, "delete from mysql.stats_expressions where table_id = %?", statsID); err != nil {
+			return err
+		}
 	}
 	return nil
 }
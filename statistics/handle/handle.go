@@ -18,6 +18,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"sort"
 	"strconv"
 	"sync"
@@ -31,6 +32,7 @@ import (
 	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/ddl/util"
 	"github.com/pingcap/tidb/domain/infosync"
+	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/parser/ast"
 	"github.com/pingcap/tidb/parser/model"
@@ -42,6 +44,7 @@ import (
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/codec"
 	"github.com/pingcap/tidb/util/logutil"
 	"github.com/pingcap/tidb/util/memory"
 	"github.com/pingcap/tidb/util/sqlexec"
@@ -906,16 +909,78 @@ func (h *Handle) TableStatsFromStorage(tableInfo *model.TableInfo, physicalID in
 	for _, row := range rows {
 		if row.GetInt64(1) > 0 {
 			err = h.indexStatsFromStorage(reader, row, table, tableInfo)
-		} else {
+		} else if !statistics.IsExprStatsID(row.GetInt64(2)) {
 			err = h.columnStatsFromStorage(reader, row, table, tableInfo, loadAll)
 		}
 		if err != nil {
 			return nil, err
 		}
 	}
+	if err = h.exprStatsFromStorage(reader, table, tableInfo); err != nil {
+		return nil, err
+	}
 	return h.extendedStatsFromStorage(reader, table, physicalID, loadAll)
 }
 
+// exprStatsFromStorage loads the statistics of the expressions analyzed by `ANALYZE TABLE ... EXPRESSIONS`.
+// They are always fully loaded since they can't be loaded on demand like the columns.
+func (h *Handle) exprStatsFromStorage(reader *statsReader, table *statistics.Table, tableInfo *model.TableInfo) error {
+	rows, _, err := reader.read("select e.hist_id, e.expression, h.distinct_count, h.version, h.null_count, h.tot_col_size, h.stats_ver, h.flag, h.correlation "+
+		"from mysql.stats_expressions e join mysql.stats_histograms h on e.table_id = h.table_id and e.hist_id = h.hist_id and h.is_index = 0 where e.table_id = %?", table.PhysicalID)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	table.ExprStats = nil
+	if len(rows) == 0 {
+		return nil
+	}
+	sc := h.mu.ctx.GetSessionVars().StmtCtx
+	exprStats := make(map[string]*statistics.Column, len(rows))
+	for _, row := range rows {
+		histID, exprStr := row.GetInt64(0), row.GetString(1)
+		expr, err := expression.ParseSimpleExprWithTableInfo(h.mu.ctx, exprStr, tableInfo)
+		if err != nil {
+			// The columns used by the expression may have been dropped or modified.
+			logutil.BgLogger().Debug("[stats] cannot build the analyzed expression", zap.String("expression", exprStr), zap.String("table", tableInfo.Name.O), zap.Error(err))
+			continue
+		}
+		key, ok := statistics.ExprStatsKey(sc, expr)
+		if !ok {
+			continue
+		}
+		info := statistics.NewExprStatsColumnInfo(exprStr, expr.GetType())
+		info.ID = histID
+		distinct, histVer, nullCount, totColSize := row.GetInt64(2), row.GetUint64(3), row.GetInt64(4), row.GetInt64(5)
+		hg, err := h.histogramFromStorage(reader, table.PhysicalID, histID, &info.FieldType, distinct, 0, histVer, nullCount, totColSize, row.GetFloat64(8))
+		if err != nil {
+			return errors.Trace(err)
+		}
+		cms, topN, err := h.cmSketchAndTopNFromStorage(reader, table.PhysicalID, 0, histID)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		fmSketch, err := h.fmSketchFromStorage(reader, table.PhysicalID, 0, histID)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		col := &statistics.Column{
+			PhysicalID: table.PhysicalID,
+			Histogram:  *hg,
+			Info:       info,
+			CMSketch:   cms,
+			TopN:       topN,
+			FMSketch:   fmSketch,
+			Flag:       row.GetInt64(7),
+			StatsVer:   row.GetInt64(6),
+			Loaded:     true,
+		}
+		col.Count = int64(col.TotalRowCount())
+		exprStats[key] = col
+	}
+	table.ExprStats = exprStats
+	return nil
+}
+
 func (h *Handle) extendedStatsFromStorage(reader *statsReader, table *statistics.Table, physicalID int64, loadAll bool) (*statistics.Table, error) {
 	failpoint.Inject("injectExtStatsLoadErr", func() {
 		failpoint.Return(nil, errors.New("gofail extendedStatsFromStorage error"))
@@ -1129,14 +1194,20 @@ func (h *Handle) SaveTableStatsToStorage(results *statistics.AnalyzeResults, nee
 					return err
 				}
 			}
-			if result.IsIndex == 0 {
+			if result.IsIndex == 0 && !statistics.IsExprStatsID(hg.ID) {
 				if _, err = exec.ExecuteInternal(ctx, "insert into mysql.column_stats_usage (table_id, column_id, last_analyzed_at) values(%?, %?, current_timestamp()) on duplicate key update last_analyzed_at = values(last_analyzed_at)", tableID, hg.ID); err != nil {
 					return err
 				}
 			}
 		}
 	}
-	// 3. Save extended statistics.
+	// 3. Save the definitions of the analyzed expressions.
+	for _, info := range results.ExprsInfo {
+		if _, err = exec.ExecuteInternal(ctx, "replace into mysql.stats_expressions (table_id, hist_id, expression, version) values (%?, %?, %?, %?)", tableID, info.ID, info.GeneratedExprString, version); err != nil {
+			return err
+		}
+	}
+	// 4. Save extended statistics.
 	extStats := results.ExtStats
 	if extStats == nil || len(extStats.Stats) == 0 {
 		return nil
@@ -1648,7 +1719,9 @@ func (h *Handle) ReloadExtendedStatistics() error {
 }
 
 // BuildExtendedStats build extended stats for column groups if needed based on the column samples.
-func (h *Handle) BuildExtendedStats(tableID int64, cols []*model.ColumnInfo, collectors []*statistics.SampleCollector) (*statistics.ExtendedStatsColl, error) {
+// The samples of different columns are paired by rows only in the analyze version 2, so the cardinality
+// stats are skipped in the analyze version 1 with a warning appended to sc.
+func (h *Handle) BuildExtendedStats(tableID int64, cols []*model.ColumnInfo, collectors []*statistics.SampleCollector, statsVer int, sc *stmtctx.StatementContext) (*statistics.ExtendedStatsColl, error) {
 	ctx := context.Background()
 	const sql = "SELECT name, type, column_ids FROM mysql.stats_extended WHERE table_id = %? and status in (%?, %?)"
 	rows, _, err := h.execRestrictedSQL(ctx, sql, tableID, StatsStatusAnalyzed, StatsStatusInited)
@@ -1668,6 +1741,10 @@ func (h *Handle) BuildExtendedStats(tableID int64, cols []*model.ColumnInfo, col
 			logutil.BgLogger().Error("invalid column_ids in mysql.stats_extended, skip collecting extended stats for this row", zap.String("column_ids", colIDs), zap.Error(err))
 			continue
 		}
+		if item.Tp == ast.StatsTypeCardinality && statsVer < statistics.Version2 {
+			sc.AppendWarning(errors.Errorf("The cardinality statistics %s can only be collected by the analyze version 2", name))
+			continue
+		}
 		item = h.fillExtendedStatsItemVals(item, cols, collectors)
 		if item != nil {
 			statsColl.Stats[name] = item
@@ -1681,14 +1758,85 @@ func (h *Handle) BuildExtendedStats(tableID int64, cols []*model.ColumnInfo, col
 
 func (h *Handle) fillExtendedStatsItemVals(item *statistics.ExtendedStatsItem, cols []*model.ColumnInfo, collectors []*statistics.SampleCollector) *statistics.ExtendedStatsItem {
 	switch item.Tp {
-	case ast.StatsTypeCardinality, ast.StatsTypeDependency:
+	case ast.StatsTypeDependency:
 		return nil
+	case ast.StatsTypeCardinality:
+		return h.fillExtStatsNDVVals(item, cols, collectors)
 	case ast.StatsTypeCorrelation:
 		return h.fillExtStatsCorrVals(item, cols, collectors)
 	}
 	return nil
 }
 
+// fillExtStatsNDVVals estimates the number of distinct value combinations of the column group
+// from the row samples, using the GEE estimator on the sampled column tuples. The Ordinal of
+// the samples must be the index of the sampled row, which is only true in the analyze version 2.
+func (h *Handle) fillExtStatsNDVVals(item *statistics.ExtendedStatsItem, cols []*model.ColumnInfo, collectors []*statistics.SampleCollector) *statistics.ExtendedStatsItem {
+	colOffsets := make([]int, 0, len(item.ColIDs))
+	for _, id := range item.ColIDs {
+		for i, col := range cols {
+			if col.ID == id {
+				colOffsets = append(colOffsets, i)
+				break
+			}
+		}
+	}
+	if len(colOffsets) != len(item.ColIDs) || len(colOffsets) < 2 {
+		return nil
+	}
+	// Group the sampled values by the row they come from. A column missing in a row is NULL.
+	rows := make(map[int][]types.Datum)
+	var totalCount int64
+	maxNDV, productNDV := float64(0), float64(1)
+	for i, offset := range colOffsets {
+		collector := collectors[offset]
+		for _, sample := range collector.Samples {
+			row, ok := rows[sample.Ordinal]
+			if !ok {
+				row = make([]types.Datum, len(colOffsets))
+				rows[sample.Ordinal] = row
+			}
+			row[i] = sample.Value
+		}
+		totalCount = mathutil.MaxInt64(totalCount, collector.Count+collector.NullCount)
+		ndv := float64(1)
+		if collector.FMSketch != nil {
+			ndv = math.Max(float64(collector.FMSketch.NDV()), 1)
+		}
+		maxNDV = math.Max(maxNDV, ndv)
+		productNDV *= ndv
+	}
+	sampleNum := len(rows)
+	if sampleNum == 0 {
+		item.ScalarVals = 0
+		return item
+	}
+	h.mu.Lock()
+	sc := h.mu.ctx.GetSessionVars().StmtCtx
+	h.mu.Unlock()
+	freq := make(map[string]int, sampleNum)
+	for _, row := range rows {
+		key, err := codec.EncodeKey(sc, nil, row...)
+		if err != nil {
+			return nil
+		}
+		freq[string(key)]++
+	}
+	var singletons int
+	for _, cnt := range freq {
+		if cnt == 1 {
+			singletons++
+		}
+	}
+	totalCount = mathutil.MaxInt64(totalCount, int64(sampleNum))
+	// GEE: D = sqrt(N/n) * f1 + (d - f1), where f1 is the number of values seen exactly once.
+	ndv := math.Sqrt(float64(totalCount)/float64(sampleNum))*float64(singletons) + float64(len(freq)-singletons)
+	ndv = math.Min(ndv, math.Min(float64(totalCount), productNDV))
+	ndv = math.Max(ndv, math.Min(maxNDV, float64(totalCount)))
+	item.ScalarVals = math.Round(ndv)
+	return item
+}
+
 func (h *Handle) fillExtStatsCorrVals(item *statistics.ExtendedStatsItem, cols []*model.ColumnInfo, collectors []*statistics.SampleCollector) *statistics.ExtendedStatsItem {
 	colOffsets := make([]int, 0, 2)
 	for _, id := range item.ColIDs {
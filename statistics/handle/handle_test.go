@@ -2036,6 +2036,81 @@ func TestCorrelationStatsCompute(t *testing.T) {
 	))
 }
 
+func TestNDVStatsCompute(t *testing.T) {
+	store, dom, clean := testkit.CreateMockStoreAndDomain(t)
+	defer clean()
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("set session tidb_enable_extended_stats = on")
+	tk.MustExec("use test")
+	tk.MustExec("create table t(a int, b int, c int)")
+	for i := 0; i < 20; i++ {
+		tk.MustExec(fmt.Sprintf("insert into t values(%d, %d, %d)", i%4, i%2, i%5))
+	}
+	err := tk.ExecToErr("alter table t add stats_extended s1 ndv(a)")
+	require.EqualError(t, err, "Only support Cardinality statistics type on at least 2 columns")
+	err = tk.ExecToErr("alter table t add stats_extended s1 dependency(a,b)")
+	require.EqualError(t, err, "Dependency statistics type is not supported now")
+	tk.MustExec("alter table t add stats_extended s1 ndv(a,b)")
+	tk.MustExec("alter table t add stats_extended s2 cardinality(a,c)")
+	tk.MustQuery("select type, column_ids, stats, status from mysql.stats_extended").Sort().Check(testkit.Rows(
+		"0 [1,2] <nil> 0",
+		"0 [1,3] <nil> 0",
+	))
+	// the samples of different columns can't be paired by rows in the analyze version 1
+	tk.MustExec("set @@session.tidb_analyze_version=1")
+	tk.MustExec("analyze table t")
+	tk.MustQuery("show warnings").Sort().Check(testkit.Rows(
+		"Warning 1105 The cardinality statistics s1 can only be collected by the analyze version 2",
+		"Warning 1105 The cardinality statistics s2 can only be collected by the analyze version 2",
+	))
+	tk.MustQuery("select type, column_ids, stats, status from mysql.stats_extended").Sort().Check(testkit.Rows(
+		"0 [1,2] <nil> 0",
+		"0 [1,3] <nil> 0",
+	))
+
+	tk.MustExec("set @@session.tidb_analyze_version=2")
+	tk.MustExec("analyze table t")
+	tk.MustQuery("select type, column_ids, stats, status from mysql.stats_extended").Sort().Check(testkit.Rows(
+		"0 [1,2] 4.000000 1",
+		"0 [1,3] 20.000000 1",
+	))
+	require.NoError(t, dom.StatsHandle().Update(dom.InfoSchema()))
+	rows := tk.MustQuery("show stats_extended where stats_name = 's1'").Rows()
+	require.Len(t, rows, 1)
+	require.Equal(t, "cardinality", rows[0][4])
+	require.Equal(t, "4.000000", rows[0][5])
+}
+
+func TestNDVStatsComputeWithSampling(t *testing.T) {
+	store, clean := testkit.CreateMockStore(t)
+	defer clean()
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("set session tidb_enable_extended_stats = on")
+	tk.MustExec("set @@session.tidb_analyze_version=2")
+	tk.MustExec("use test")
+	tk.MustExec("create table t(a int, b int, c int, d int)")
+	values := make([]string, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		values = append(values, fmt.Sprintf("(%d, %d, %d, %d)", i%4, i%2, i%5, i))
+	}
+	tk.MustExec("insert into t values " + strings.Join(values, ","))
+	tk.MustExec("alter table t add stats_extended s1 cardinality(a,b)")
+	tk.MustExec("alter table t add stats_extended s2 cardinality(a,c)")
+	tk.MustExec("alter table t add stats_extended s3 cardinality(a,d)")
+	// the table is larger than the sample size, the samples of different columns are still paired by rows
+	tk.MustExec("analyze table t with 100 samples")
+	rows := tk.MustQuery("select column_ids, stats from mysql.stats_extended").Sort().Rows()
+	require.Len(t, rows, 3)
+	require.Equal(t, []interface{}{"[1,2]", "4.000000"}, rows[0])
+	require.Equal(t, []interface{}{"[1,4]", "1000.000000"}, rows[2])
+	// some of the 20 value combinations may be missing in the samples
+	ndv, err := strconv.ParseFloat(rows[1][1].(string), 64)
+	require.NoError(t, err)
+	require.Equal(t, "[1,3]", rows[1][0])
+	require.GreaterOrEqual(t, ndv, float64(15))
+	require.LessOrEqual(t, ndv, float64(20))
+}
+
 func TestSyncStatsExtendedRemoval(t *testing.T) {
 	store, dom, clean := testkit.CreateMockStoreAndDomain(t)
 	defer clean()
@@ -184,6 +184,8 @@ func (coll *HistColl) Selectivity(ctx sessionctx.Context, exprs []expression.Exp
 	if coll.Count == 0 || len(exprs) == 0 {
 		return 1, nil, nil
 	}
+	// Replace the expressions which have statistics with the columns carrying them.
+	exprs, coll = coll.substituteExprStats(ctx, exprs)
 	ret := 1.0
 	sc := ctx.GetSessionVars().StmtCtx
 	tableID := coll.PhysicalID
@@ -103,6 +103,8 @@ type HistColl struct {
 	Idx2ColumnIDs map[int64][]int64
 	// ColID2IdxID maps the column id to index id whose first column is it. It's used to calculate the selectivity in planner.
 	ColID2IdxID map[int64]int64
+	// ExprStats maps the key of an expression to its statistics. It's used to estimate the conditions on expressions.
+	ExprStats   map[string]*Column
 	Count       int64
 	ModifyCount int64 // Total modify count in a table.
 
@@ -126,6 +128,9 @@ func (t *Table) MemoryUsage() (sum int64) {
 			sum += index.MemoryUsage()
 		}
 	}
+	for _, col := range t.ExprStats {
+		sum += col.MemoryUsage()
+	}
 	return
 }
 
@@ -139,6 +144,7 @@ func (t *Table) Copy() *Table {
 		Indices:        make(map[int64]*Index, len(t.Indices)),
 		Pseudo:         t.Pseudo,
 		ModifyCount:    t.ModifyCount,
+		ExprStats:      t.ExprStats,
 	}
 	for id, col := range t.Columns {
 		newHistColl.Columns[id] = col
@@ -418,6 +424,40 @@ func (coll *HistColl) GetRowCountByIndexRanges(sctx sessionctx.Context, idxID in
 	return result, errors.Trace(err)
 }
 
+// GetRowCountByHypoIndexRanges estimates the row count by a slice of Range of a hypothetical index. The hypothetical
+// index has no statistics of its own, so the index columns are regarded as independent and the row count is derived
+// from their column histograms. colIDs are the IDs of the columns that the ranges are built on.
+func (coll *HistColl) GetRowCountByHypoIndexRanges(sctx sessionctx.Context, colIDs []int64, indexRanges []*ranger.Range) (float64, error) {
+	if coll.Count <= 0 {
+		return 0, nil
+	}
+	totalCount := float64(coll.Count)
+	var result float64
+	for _, ran := range indexRanges {
+		selectivity := 1.0
+		for i := range ran.LowVal {
+			colRange := &ranger.Range{
+				LowVal:  []types.Datum{ran.LowVal[i]},
+				HighVal: []types.Datum{ran.HighVal[i]},
+			}
+			if i < len(ran.Collators) {
+				colRange.Collators = []collate.Collator{ran.Collators[i]}
+			}
+			// Only the last column of an index range can be a non-point range.
+			if i == len(ran.LowVal)-1 {
+				colRange.LowExclude, colRange.HighExclude = ran.LowExclude, ran.HighExclude
+			}
+			count, err := coll.GetRowCountByColumnRanges(sctx, colIDs[i], []*ranger.Range{colRange})
+			if err != nil {
+				return 0, errors.Trace(err)
+			}
+			selectivity *= math.Min(count/totalCount, 1)
+		}
+		result += selectivity * totalCount
+	}
+	return math.Min(result, totalCount), nil
+}
+
 // CETraceRange appends a list of ranges and related information into CE trace
 func CETraceRange(sctx sessionctx.Context, tableID int64, colNames []string, ranges []*ranger.Range, tp string, rowCount uint64) {
 	sc := sctx.GetSessionVars().StmtCtx
@@ -487,6 +527,7 @@ func (coll *HistColl) ID2UniqueID(columns []*expression.Column) *HistColl {
 		Count:          coll.Count,
 		ModifyCount:    coll.ModifyCount,
 		Columns:        cols,
+		ExprStats:      coll.ExprStats,
 	}
 	return newColl
 }
@@ -542,6 +583,7 @@ func (coll *HistColl) GenerateHistCollFromColumnInfo(infos []*model.ColumnInfo,
 		Indices:        newIdxHistMap,
 		ColID2IdxID:    colID2IdxID,
 		Idx2ColumnIDs:  idx2Columns,
+		ExprStats:      coll.ExprStats,
 	}
 	return newColl
 }
@@ -43,6 +43,7 @@ import (
 	"github.com/pingcap/tidb/util/logutil"
 	"github.com/pingcap/tidb/util/memory"
 	"github.com/pingcap/tidb/util/paging"
+	"github.com/pingcap/tidb/util/tracing"
 	"github.com/pingcap/tidb/util/trxevents"
 	"github.com/pingcap/tipb/go-tipb"
 	"github.com/tikv/client-go/v2/metrics"
@@ -668,6 +669,10 @@ func (worker *copIteratorWorker) handleTask(ctx context.Context, task *copTask,
 			worker.sendToRespCh(resp, respCh, false)
 		}
 	}()
+	span, ctx := tracing.ChildSpanFromContxt(ctx, "copr.handleTask")
+	defer span.Finish()
+	span.SetTag("region_id", task.region.GetID())
+	span.SetTag("store_type", task.storeType.Name())
 	remainTasks := []*copTask{task}
 	backoffermap := make(map[uint64]*Backoffer)
 	for len(remainTasks) > 0 {
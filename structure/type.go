@@ -43,6 +43,12 @@ const (
 // Make linter happy, since encodeHashMetaKey is unused in this repo.
 var _ = (&TxStructure{}).encodeHashMetaKey
 
+// EncodeStringDataKey encodes the key of the string data, e.g. to recognize the
+// changes of the key in the raw KV changes.
+func (t *TxStructure) EncodeStringDataKey(key []byte) kv.Key {
+	return t.encodeStringDataKey(key)
+}
+
 func (t *TxStructure) encodeStringDataKey(key []byte) kv.Key {
 	// for codec Encode, we may add extra bytes data, so here and following encode
 	// we will use extra length like 4 for a little optimization.
@@ -0,0 +1,125 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package temptable
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/meta/autoid"
+	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/sessionstates"
+	"github.com/pingcap/tidb/tablecodec"
+)
+
+// EncodeSessionStates encodes the definitions and the data of the local temporary tables into the session states.
+func EncodeSessionStates(sctx sessionctx.Context, sessionStates *sessionstates.SessionStates) error {
+	localTempTables := getLocalTemporaryTables(sctx)
+	if localTempTables == nil || localTempTables.Count() == 0 {
+		return nil
+	}
+
+	sessionData := getSessionData(sctx)
+	for _, tbl := range localTempTables.AllTables() {
+		tblInfo := tbl.Meta()
+		db, _ := localTempTables.SchemaByTable(tblInfo)
+		tempTable := &sessionstates.LocalTemporaryTable{
+			DBName:    db.Name.O,
+			TableInfo: tblInfo,
+		}
+		if alloc := tbl.Allocators(nil).Get(autoid.RowIDAllocType); alloc != nil {
+			tempTable.AutoIDs = alloc.Base()
+		}
+
+		if sessionData != nil {
+			tblPrefix := tablecodec.EncodeTablePrefix(tblInfo.ID)
+			endKey := tablecodec.EncodeTablePrefix(tblInfo.ID + 1)
+			iter, err := sessionData.Iter(tblPrefix, endKey)
+			if err != nil {
+				return err
+			}
+			for iter.Valid() && bytes.HasPrefix(iter.Key(), tblPrefix) {
+				// The deleted keys are kept as empty values in the buffer.
+				if len(iter.Value()) > 0 {
+					tempTable.Data = append(tempTable.Data, sessionstates.KeyValue{
+						Key:   append([]byte{}, iter.Key()...),
+						Value: append([]byte{}, iter.Value()...),
+					})
+				}
+				if err = iter.Next(); err != nil {
+					iter.Close()
+					return err
+				}
+			}
+			iter.Close()
+		}
+		sessionStates.LocalTemporaryTables = append(sessionStates.LocalTemporaryTables, tempTable)
+	}
+	return nil
+}
+
+// DecodeSessionStates restores the local temporary tables and their data from the session states.
+// The tables are assigned new IDs, so the keys of the data are rewritten with the new table prefixes.
+func DecodeSessionStates(sctx sessionctx.Context, sessionStates *sessionstates.SessionStates) error {
+	if len(sessionStates.LocalTemporaryTables) == 0 {
+		return nil
+	}
+
+	sessionData, err := ensureSessionData(sctx)
+	if err != nil {
+		return err
+	}
+	is := sctx.GetInfoSchema().(infoschema.InfoSchema)
+	for _, tempTable := range sessionStates.LocalTemporaryTables {
+		tblInfo := tempTable.TableInfo
+		if tblInfo == nil {
+			return sessionstates.ErrInvalidSessionStates.GenWithStackByArgs("missing the definition of a local temporary table")
+		}
+		// Local temporary tables can be accessed after the db is dropped.
+		dbName := model.NewCIStr(tempTable.DBName)
+		db, ok := is.SchemaByName(dbName)
+		if !ok {
+			db = &model.DBInfo{Name: dbName}
+		}
+
+		oldPrefix := tablecodec.EncodeTablePrefix(tblInfo.ID)
+		tbl, err := newTemporaryTableFromTableInfo(sctx, tblInfo)
+		if err != nil {
+			return err
+		}
+		if alloc := tbl.Allocators(nil).Get(autoid.RowIDAllocType); alloc != nil && tempTable.AutoIDs > 0 {
+			if err = alloc.Rebase(context.Background(), tempTable.AutoIDs, false); err != nil {
+				return err
+			}
+		}
+		if err = ensureLocalTemporaryTables(sctx).AddTable(db, tbl); err != nil {
+			return err
+		}
+
+		newPrefix := tablecodec.EncodeTablePrefix(tblInfo.ID)
+		for _, kv := range tempTable.Data {
+			if !bytes.HasPrefix(kv.Key, oldPrefix) {
+				return sessionstates.ErrInvalidSessionStates.GenWithStackByArgs("the data does not belong to the local temporary table")
+			}
+			key := append(append([]byte{}, newPrefix...), kv.Key[len(oldPrefix):]...)
+			if err = sessionData.SetTableKey(tblInfo.ID, key, kv.Value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -67,6 +67,7 @@ import (
 	storageSys "github.com/pingcap/tidb/util/sys/storage"
 	"github.com/pingcap/tidb/util/systimemon"
 	"github.com/pingcap/tidb/util/topsql"
+	"github.com/pingcap/tidb/util/tracing"
 	"github.com/pingcap/tidb/util/versioninfo"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/push"
@@ -627,6 +628,7 @@ func setGlobalVars() {
 	// For CI environment we default enable prepare-plan-cache.
 	plannercore.SetPreparedPlanCache(config.CheckTableBeforeDrop || cfg.PreparedPlanCache.Enabled)
 	if plannercore.PreparedPlanCacheEnabled() {
+		plannercore.SetInstancePlanCache(cfg.PreparedPlanCache.InstanceEnabled)
 		plannercore.PreparedPlanCacheCapacity = cfg.PreparedPlanCache.Capacity
 		plannercore.PreparedPlanCacheMemoryGuardRatio = cfg.PreparedPlanCache.MemoryGuardRatio
 		if plannercore.PreparedPlanCacheMemoryGuardRatio < 0.0 || plannercore.PreparedPlanCacheMemoryGuardRatio > 1.0 {
@@ -726,6 +728,13 @@ func setupTracing() {
 		log.Fatal("setup jaeger tracer failed", zap.String("error message", err.Error()))
 	}
 	opentracing.SetGlobalTracer(tracer)
+	switch cfg.OpenTracing.W3CExporter {
+	case "":
+	case "log":
+		tracing.SetExporter(tracing.LogExporter{})
+	default:
+		log.Fatal("unknown w3c exporter", zap.String("w3c-exporter", cfg.OpenTracing.W3CExporter))
+	}
 }
 
 func closeDomainAndStorage(storage kv.Storage, dom *domain.Domain) {
@@ -15,6 +15,7 @@
 package types
 
 import (
+	gjson "encoding/json"
 	"fmt"
 	"math"
 	"sort"
@@ -429,6 +430,67 @@ func (d *Datum) SetAutoID(id int64, flag uint) {
 	}
 }
 
+// jsonDatum is used to encode and decode Datum in JSON.
+type jsonDatum struct {
+	K         byte   `json:"k"`
+	Decimal   uint16 `json:"decimal,omitempty"`
+	Length    uint32 `json:"length,omitempty"`
+	I         int64  `json:"i,omitempty"`
+	Collation string `json:"collation,omitempty"`
+	B         []byte `json:"b,omitempty"`
+	MyDecimal string `json:"mydecimal,omitempty"`
+	Time      uint64 `json:"time,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (d *Datum) MarshalJSON() ([]byte, error) {
+	jd := &jsonDatum{
+		K:         d.k,
+		Decimal:   d.decimal,
+		Length:    d.length,
+		I:         d.i,
+		Collation: d.collation,
+		B:         d.b,
+	}
+	switch d.k {
+	case KindMysqlDecimal:
+		jd.MyDecimal = string(d.GetMysqlDecimal().ToString())
+	case KindMysqlTime:
+		jd.Time = uint64(d.GetMysqlTime().coreTime)
+	case KindInterface:
+		return nil, errors.Errorf("unsupported datum kind %d", d.k)
+	}
+	return gjson.Marshal(jd)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (d *Datum) UnmarshalJSON(data []byte) error {
+	var jd jsonDatum
+	if err := gjson.Unmarshal(data, &jd); err != nil {
+		return err
+	}
+	d.k = jd.K
+	d.decimal = jd.Decimal
+	d.length = jd.Length
+	d.i = jd.I
+	d.collation = jd.Collation
+	d.b = jd.B
+	d.x = nil
+	switch jd.K {
+	case KindMysqlDecimal:
+		dec := new(MyDecimal)
+		if err := dec.FromString([]byte(jd.MyDecimal)); err != nil {
+			return err
+		}
+		d.x = dec
+	case KindMysqlTime:
+		d.x = Time{coreTime: coreTime(jd.Time)}
+	case KindInterface:
+		return errors.Errorf("unsupported datum kind %d", jd.K)
+	}
+	return nil
+}
+
 // String returns a human-readable description of Datum. It is intended only for debugging.
 func (d Datum) String() string {
 	var t string
@@ -317,6 +317,8 @@ var (
 
 	// ErrPlacementPolicyInUse is returned when placement policy is in use in drop/alter.
 	ErrPlacementPolicyInUse = ClassDDL.NewStd(mysql.ErrPlacementPolicyInUse)
+	// ErrResourceGroupInUse is returned when the resource group is bound to users in drop.
+	ErrResourceGroupInUse = ClassDDL.NewStd(mysql.ErrResourceGroupInUse)
 
 	// ErrMultipleDefConstInListPart returns multiple definition of same constant in list partitioning.
 	ErrMultipleDefConstInListPart = ClassDDL.NewStd(mysql.ErrMultipleDefConstInListPart)
@@ -26,14 +26,16 @@ import (
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/logutil"
+	"github.com/pingcap/tidb/util/runaway"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
 // Handle is the handler for expensive query.
 type Handle struct {
-	exitCh chan struct{}
-	sm     atomic.Value
+	exitCh  chan struct{}
+	sm      atomic.Value
+	runaway *runaway.Manager
 }
 
 // NewExpensiveQueryHandle builds a new expensive query handler.
@@ -48,6 +50,12 @@ func (eqh *Handle) SetSessionManager(sm util.SessionManager) *Handle {
 	return eqh
 }
 
+// SetRunawayManager sets the runaway.Manager which is used to kill the runaway queries.
+func (eqh *Handle) SetRunawayManager(m *runaway.Manager) *Handle {
+	eqh.runaway = m
+	return eqh
+}
+
 // Run starts a expensive query checker goroutine at the start time of the server.
 func (eqh *Handle) Run() {
 	threshold := atomic.LoadUint64(&variable.ExpensiveQueryTimeThreshold)
@@ -74,6 +82,11 @@ func (eqh *Handle) Run() {
 				if info.MaxExecutionTime > 0 && costTime > time.Duration(info.MaxExecutionTime)*time.Millisecond {
 					sm.Kill(info.ID, true)
 				}
+
+				if !info.Runaway && eqh.runaway != nil && eqh.runaway.CheckRunaway(info, costTime) != nil {
+					info.Runaway = true
+					sm.Kill(info.ID, true)
+				}
 			}
 			threshold = atomic.LoadUint64(&variable.ExpensiveQueryTimeThreshold)
 
@@ -0,0 +1,160 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kvcache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// concurrentCacheEntry is the value of list.Element in ConcurrentLRUCache.
+type concurrentCacheEntry struct {
+	hash     string
+	key      Key
+	value    Value
+	memUsage uint64
+}
+
+// ConcurrentLRUCache is a least recently used cache which is safe for concurrent use.
+// The memory usage of each entry is given by the caller, the least recently used
+// entries are evicted when the total memory usage exceeds the quota.
+// The values may be read by several goroutines at the same time, so they must not
+// be modified after they are put into the cache.
+type ConcurrentLRUCache struct {
+	mu       sync.Mutex
+	quota    uint64
+	memUsage uint64
+	elements map[string]*list.Element
+	cache    *list.List
+
+	// onEvict function will be called if any eviction happened
+	onEvict func(Key, Value)
+}
+
+// NewConcurrentLRUCache creates a ConcurrentLRUCache object, whose memory quota is "quota" bytes.
+func NewConcurrentLRUCache(quota uint64) *ConcurrentLRUCache {
+	return &ConcurrentLRUCache{
+		quota:    quota,
+		elements: make(map[string]*list.Element),
+		cache:    list.New(),
+	}
+}
+
+// SetOnEvict set the function called on each eviction. It's called with the lock held,
+// so it must not call the methods of the cache.
+func (l *ConcurrentLRUCache) SetOnEvict(onEvict func(Key, Value)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.onEvict = onEvict
+}
+
+// Get tries to find the corresponding value according to the given key.
+func (l *ConcurrentLRUCache) Get(key Key) (value Value, ok bool) {
+	hash := string(key.Hash())
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	element, exists := l.elements[hash]
+	if !exists {
+		return nil, false
+	}
+	l.cache.MoveToFront(element)
+	return element.Value.(*concurrentCacheEntry).value, true
+}
+
+// Put puts the (key, value) pair into the cache, memUsage is the memory used by the pair.
+// The pair isn't put if it alone exceeds the quota.
+func (l *ConcurrentLRUCache) Put(key Key, value Value, memUsage uint64) {
+	hash := string(key.Hash())
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if element, exists := l.elements[hash]; exists {
+		l.remove(element)
+	}
+	if memUsage > l.quota {
+		return
+	}
+	element := l.cache.PushFront(&concurrentCacheEntry{
+		hash:     hash,
+		key:      key,
+		value:    value,
+		memUsage: memUsage,
+	})
+	l.elements[hash] = element
+	l.memUsage += memUsage
+	for l.memUsage > l.quota {
+		lru := l.cache.Back()
+		entry := lru.Value.(*concurrentCacheEntry)
+		l.remove(lru)
+		if l.onEvict != nil {
+			l.onEvict(entry.key, entry.value)
+		}
+	}
+}
+
+// Delete deletes the key-value pair from the cache.
+func (l *ConcurrentLRUCache) Delete(key Key) {
+	hash := string(key.Hash())
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if element, exists := l.elements[hash]; exists {
+		l.remove(element)
+	}
+}
+
+// DeleteAll deletes all elements from the cache.
+func (l *ConcurrentLRUCache) DeleteAll() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.elements = make(map[string]*list.Element)
+	l.cache.Init()
+	l.memUsage = 0
+}
+
+func (l *ConcurrentLRUCache) remove(element *list.Element) {
+	entry := element.Value.(*concurrentCacheEntry)
+	l.cache.Remove(element)
+	delete(l.elements, entry.hash)
+	l.memUsage -= entry.memUsage
+}
+
+// Size gets the number of the elements in the cache.
+func (l *ConcurrentLRUCache) Size() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.cache.Len()
+}
+
+// MemUsage gets the total memory usage of the elements in the cache.
+func (l *ConcurrentLRUCache) MemUsage() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.memUsage
+}
+
+// SetQuota sets the memory quota of the cache, the least recently used elements
+// are evicted if the memory usage exceeds the new quota.
+func (l *ConcurrentLRUCache) SetQuota(quota uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.quota = quota
+	for l.memUsage > l.quota {
+		lru := l.cache.Back()
+		entry := lru.Value.(*concurrentCacheEntry)
+		l.remove(lru)
+		if l.onEvict != nil {
+			l.onEvict(entry.key, entry.value)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kvcache
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrentLRUCachePutAndGet(t *testing.T) {
+	lru := NewConcurrentLRUCache(100)
+	evicted := make(map[int64]Value)
+	lru.SetOnEvict(func(key Key, value Value) {
+		evicted[key.(*mockCacheKey).key] = value
+	})
+
+	for i := int64(0); i < 4; i++ {
+		lru.Put(newMockHashKey(i), i, 30)
+	}
+	// the oldest one is evicted.
+	require.Equal(t, 3, lru.Size())
+	require.Equal(t, uint64(90), lru.MemUsage())
+	require.Equal(t, map[int64]Value{0: int64(0)}, evicted)
+	_, ok := lru.Get(newMockHashKey(0))
+	require.False(t, ok)
+
+	// 1 becomes the most recently used one, so 2 is evicted.
+	v, ok := lru.Get(newMockHashKey(1))
+	require.True(t, ok)
+	require.Equal(t, int64(1), v)
+	lru.Put(newMockHashKey(4), int64(4), 30)
+	require.Contains(t, evicted, int64(2))
+	_, ok = lru.Get(newMockHashKey(1))
+	require.True(t, ok)
+
+	// replacing a value updates the memory usage.
+	lru.Put(newMockHashKey(4), int64(40), 10)
+	require.Equal(t, uint64(70), lru.MemUsage())
+	v, ok = lru.Get(newMockHashKey(4))
+	require.True(t, ok)
+	require.Equal(t, int64(40), v)
+
+	// a value exceeding the quota isn't put.
+	lru.Put(newMockHashKey(5), int64(5), 101)
+	_, ok = lru.Get(newMockHashKey(5))
+	require.False(t, ok)
+	require.Equal(t, 3, lru.Size())
+
+	lru.SetQuota(40)
+	require.Equal(t, 2, lru.Size())
+	require.Equal(t, uint64(40), lru.MemUsage())
+	require.Contains(t, evicted, int64(3))
+}
+
+func TestConcurrentLRUCacheDelete(t *testing.T) {
+	lru := NewConcurrentLRUCache(100)
+	for i := int64(0); i < 3; i++ {
+		lru.Put(newMockHashKey(i), i, 10)
+	}
+	lru.Delete(newMockHashKey(1))
+	lru.Delete(newMockHashKey(5))
+	require.Equal(t, 2, lru.Size())
+	require.Equal(t, uint64(20), lru.MemUsage())
+	_, ok := lru.Get(newMockHashKey(1))
+	require.False(t, ok)
+
+	lru.DeleteAll()
+	require.Equal(t, 0, lru.Size())
+	require.Equal(t, uint64(0), lru.MemUsage())
+	_, ok = lru.Get(newMockHashKey(0))
+	require.False(t, ok)
+}
+
+func TestConcurrentLRUCacheConcurrency(t *testing.T) {
+	lru := NewConcurrentLRUCache(1000)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := int64(0); j < 1000; j++ {
+				key := newMockHashKey(j % 50)
+				if v, ok := lru.Get(key); ok {
+					require.Equal(t, j%50, v)
+				}
+				lru.Put(key, j%50, uint64(i+1))
+				if j%100 == 0 {
+					lru.Delete(key)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	require.LessOrEqual(t, lru.MemUsage(), uint64(1000))
+}
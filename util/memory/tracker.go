@@ -558,4 +558,6 @@ const (
 	LabelForIndexJoinOuterWorker int = -21
 	// LabelForBindCache represents the label of the bind cache
 	LabelForBindCache int = -22
+	// LabelForResourceGroup represents the label of the resource group
+	LabelForResourceGroup int = -23
 )
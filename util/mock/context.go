@@ -27,6 +27,7 @@ import (
 	"github.com/pingcap/tidb/parser/ast"
 	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/sessionstates"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/disk"
@@ -358,6 +359,20 @@ func (c *Context) GetStmtStats() *stmtstats.StatementStats {
 	return nil
 }
 
+// SetSessionStatesHandler implements the sessionctx.Context interface.
+func (c *Context) SetSessionStatesHandler(_ sessionstates.SessionStateType, _ sessionctx.SessionStatesHandler) {
+}
+
+// EncodeSessionStates implements the sessionctx.Context interface.
+func (c *Context) EncodeSessionStates(_ context.Context, _ sessionctx.Context, _ *sessionstates.SessionStates) error {
+	return errors.Errorf("Not Supported")
+}
+
+// DecodeSessionStates implements the sessionctx.Context interface.
+func (c *Context) DecodeSessionStates(_ context.Context, _ sessionctx.Context, _ *sessionstates.SessionStates) error {
+	return errors.Errorf("Not Supported")
+}
+
 // Close implements the sessionctx.Context interface.
 func (c *Context) Close() {
 }
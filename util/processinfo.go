@@ -37,6 +37,7 @@ type ProcessInfo struct {
 	Port             string
 	DB               string
 	Digest           string
+	PlanDigest       string
 	Plan             interface{}
 	PlanExplainRows  [][]string
 	RuntimeStatsColl *execdetails.RuntimeStatsColl
@@ -52,7 +53,9 @@ type ProcessInfo struct {
 	State                     uint16
 	Command                   byte
 	ExceedExpensiveTimeThresh bool
-	RedactSQL                 bool
+	// Runaway is set once the statement is killed as a runaway query.
+	Runaway   bool
+	RedactSQL bool
 }
 
 // ToRowForShow returns []interface{} for the row data of "SHOW [FULL] PROCESSLIST".
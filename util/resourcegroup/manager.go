@@ -0,0 +1,241 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourcegroup
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pingcap/tidb/errno"
+	"github.com/pingcap/tidb/metrics"
+	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/util/dbterror"
+	"github.com/pingcap/tidb/util/memory"
+	"golang.org/x/sync/semaphore"
+	"golang.org/x/time/rate"
+)
+
+// ErrQueryRejected is returned when a statement is not admitted by its resource group.
+var ErrQueryRejected = dbterror.ClassUtil.NewStd(errno.ErrResourceGroupQueryRejected)
+
+// Manager enforces the limits of resource groups on the statements of their
+// users. The limits are read from the ResourceGroupInfo passed in on each
+// admission, so altering a group takes effect on the next statement.
+type Manager struct {
+	mu     sync.Mutex
+	groups map[string]*group
+}
+
+// group is the runtime state of a resource group on this instance.
+type group struct {
+	name string
+
+	// The settings the limiters are built from.
+	qps            uint64
+	maxConcurrency uint64
+
+	// limiter is nil if the QPS is unlimited.
+	limiter *rate.Limiter
+	// sem is nil if the concurrency is unlimited.
+	sem        *semaphore.Weighted
+	memTracker *memory.Tracker
+	attachOnce sync.Once
+
+	running int64
+	queued  int64
+}
+
+// Stats is the runtime statistics of a resource group on this instance.
+type Stats struct {
+	Name          string
+	Running       int64
+	Queued        int64
+	MemoryConsume int64
+}
+
+// NewManager creates a Manager.
+func NewManager() *Manager {
+	return &Manager{groups: make(map[string]*group)}
+}
+
+// getGroup returns the runtime state of the group, and rebuilds its limiters
+// if the settings are changed.
+func (m *Manager) getGroup(info *model.ResourceGroupInfo) *group {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	g, ok := m.groups[info.Name.L]
+	if !ok {
+		g = &group{name: info.Name.L}
+		g.memTracker = memory.NewGlobalTracker(memory.LabelForResourceGroup, -1)
+		g.memTracker.SetActionOnExceed(&panicOnExceed{name: info.Name.O})
+		m.groups[info.Name.L] = g
+	}
+	if !ok || g.qps != info.QPS {
+		g.qps = info.QPS
+		g.limiter = nil
+		if info.QPS > 0 {
+			burst := int(info.QPS)
+			if info.QPS > math.MaxInt32 {
+				burst = math.MaxInt32
+			}
+			g.limiter = rate.NewLimiter(rate.Limit(info.QPS), burst)
+		}
+	}
+	if !ok || g.maxConcurrency != info.MaxConcurrency {
+		// Statements holding the old semaphore release it when they finish.
+		g.maxConcurrency = info.MaxConcurrency
+		g.sem = nil
+		if info.MaxConcurrency > 0 {
+			g.sem = semaphore.NewWeighted(int64(info.MaxConcurrency))
+		}
+	}
+	if info.MemQuota > 0 {
+		g.memTracker.SetBytesLimit(info.MemQuota)
+	} else {
+		g.memTracker.SetBytesLimit(-1)
+	}
+	return g
+}
+
+// Admit waits until the statement is admitted by the resource group, or
+// rejects it if it can not be admitted within the timeout. The returned
+// function must be called when the statement finishes.
+func (m *Manager) Admit(ctx context.Context, info *model.ResourceGroupInfo, timeout time.Duration) (release func(), err error) {
+	g := m.getGroup(info)
+	m.mu.Lock()
+	limiter, sem := g.limiter, g.sem
+	m.mu.Unlock()
+
+	start := time.Now()
+	deadline := start.Add(timeout)
+	queued := false
+	if limiter != nil {
+		r := limiter.Reserve()
+		delay := r.Delay()
+		if !r.OK() || delay > timeout {
+			r.Cancel()
+			return nil, g.reject("the QPS limit is exceeded")
+		}
+		if delay > 0 {
+			queued = true
+			atomic.AddInt64(&g.queued, 1)
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				r.Cancel()
+				atomic.AddInt64(&g.queued, -1)
+				return nil, ctx.Err()
+			}
+			atomic.AddInt64(&g.queued, -1)
+		}
+	}
+	if sem != nil && !sem.TryAcquire(1) {
+		queued = true
+		atomic.AddInt64(&g.queued, 1)
+		waitCtx, cancel := context.WithDeadline(ctx, deadline)
+		err = sem.Acquire(waitCtx, 1)
+		cancel()
+		atomic.AddInt64(&g.queued, -1)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, g.reject("the max concurrency is exceeded")
+		}
+	}
+
+	if queued {
+		metrics.ResourceGroupAdmissionCounter.WithLabelValues(g.name, metrics.LblQueued).Inc()
+		metrics.ResourceGroupQueueDurationHistogram.WithLabelValues(g.name).Observe(time.Since(start).Seconds())
+	}
+	metrics.ResourceGroupAdmissionCounter.WithLabelValues(g.name, metrics.LblAdmitted).Inc()
+	metrics.ResourceGroupRunningGauge.WithLabelValues(g.name).Set(float64(atomic.AddInt64(&g.running, 1)))
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			if sem != nil {
+				sem.Release(1)
+			}
+			metrics.ResourceGroupRunningGauge.WithLabelValues(g.name).Set(float64(atomic.AddInt64(&g.running, -1)))
+		})
+	}, nil
+}
+
+func (g *group) reject(reason string) error {
+	metrics.ResourceGroupAdmissionCounter.WithLabelValues(g.name, metrics.LblRejected).Inc()
+	return ErrQueryRejected.GenWithStackByArgs(g.name, reason)
+}
+
+// MemTracker returns the memory tracker of the resource group, statement
+// trackers are attached to it to enforce the memory quota of the group. The
+// tracker of the group is attached to the global tracker on the first call.
+// It returns nil if no statement of the group has been admitted on this instance.
+func (m *Manager) MemTracker(name string, globalTracker *memory.Tracker) *memory.Tracker {
+	m.mu.Lock()
+	g, ok := m.groups[name]
+	m.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	g.attachOnce.Do(func() {
+		g.memTracker.AttachToGlobalTracker(globalTracker)
+	})
+	return g.memTracker
+}
+
+// Stats returns the runtime statistics of the resource groups, sorted by name.
+func (m *Manager) Stats() []Stats {
+	m.mu.Lock()
+	stats := make([]Stats, 0, len(m.groups))
+	for _, g := range m.groups {
+		stats = append(stats, Stats{
+			Name:          g.name,
+			Running:       atomic.LoadInt64(&g.running),
+			Queued:        atomic.LoadInt64(&g.queued),
+			MemoryConsume: g.memTracker.BytesConsumed(),
+		})
+	}
+	m.mu.Unlock()
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats
+}
+
+// panicOnExceed cancels the statement whose allocation makes the resource
+// group exceed its memory quota. Unlike memory.PanicOnExceed, it acts every
+// time because the tracker of a group lives across statements.
+type panicOnExceed struct {
+	memory.BaseOOMAction
+	name string
+}
+
+// SetLogHook implements memory.ActionOnExceed.
+func (a *panicOnExceed) SetLogHook(func(uint64)) {}
+
+// Action implements memory.ActionOnExceed.
+func (a *panicOnExceed) Action(*memory.Tracker) {
+	panic(memory.PanicMemoryExceed + fmt.Sprintf("[resource_group=%s]", a.name))
+}
+
+// GetPriority implements memory.ActionOnExceed.
+func (a *panicOnExceed) GetPriority() int64 {
+	return memory.DefPanicPriority
+}
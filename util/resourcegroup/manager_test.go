@@ -0,0 +1,120 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourcegroup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/util/memory"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdmitMaxConcurrency(t *testing.T) {
+	m := NewManager()
+	info := &model.ResourceGroupInfo{Name: model.NewCIStr("rg"), MaxConcurrency: 1}
+
+	release, err := m.Admit(context.Background(), info, time.Second)
+	require.NoError(t, err)
+	require.Equal(t, []Stats{{Name: "rg", Running: 1}}, m.Stats())
+
+	// The second statement is rejected after waiting for the timeout.
+	_, err = m.Admit(context.Background(), info, 10*time.Millisecond)
+	require.True(t, ErrQueryRejected.Equal(err))
+
+	// The queued statement is admitted once the running one finishes.
+	done := make(chan error)
+	go func() {
+		release2, err := m.Admit(context.Background(), info, 10*time.Second)
+		if err == nil {
+			release2()
+		}
+		done <- err
+	}()
+	require.Eventually(t, func() bool { return m.Stats()[0].Queued == 1 }, time.Second, time.Millisecond)
+	release()
+	// Calling release twice is a no-op.
+	release()
+	require.NoError(t, <-done)
+	require.Equal(t, []Stats{{Name: "rg"}}, m.Stats())
+
+	// Canceling the context stops waiting.
+	release, err = m.Admit(context.Background(), info, time.Second)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = m.Admit(ctx, info, time.Second)
+	require.Equal(t, context.Canceled, err)
+	release()
+
+	// Raising the limit takes effect on the next statement.
+	info.MaxConcurrency = 2
+	release, err = m.Admit(context.Background(), info, time.Second)
+	require.NoError(t, err)
+	release2, err := m.Admit(context.Background(), info, time.Second)
+	require.NoError(t, err)
+	release()
+	release2()
+}
+
+func TestAdmitQPS(t *testing.T) {
+	m := NewManager()
+	info := &model.ResourceGroupInfo{Name: model.NewCIStr("rg"), QPS: 1}
+
+	release, err := m.Admit(context.Background(), info, 0)
+	require.NoError(t, err)
+	release()
+	// The burst is used up, the next token is available after one second.
+	_, err = m.Admit(context.Background(), info, 10*time.Millisecond)
+	require.True(t, ErrQueryRejected.Equal(err))
+
+	// An unlimited group never waits.
+	info = &model.ResourceGroupInfo{Name: model.NewCIStr("unlimited")}
+	for i := 0; i < 100; i++ {
+		release, err = m.Admit(context.Background(), info, 0)
+		require.NoError(t, err)
+		release()
+	}
+}
+
+func TestMemTracker(t *testing.T) {
+	m := NewManager()
+	info := &model.ResourceGroupInfo{Name: model.NewCIStr("rg"), MemQuota: 100}
+	global := memory.NewGlobalTracker(memory.LabelForGlobalMemory, -1)
+
+	require.Nil(t, m.MemTracker("rg", global))
+	release, err := m.Admit(context.Background(), info, time.Second)
+	require.NoError(t, err)
+	defer release()
+
+	groupTracker := m.MemTracker("rg", global)
+	require.NotNil(t, groupTracker)
+	require.Same(t, groupTracker, m.MemTracker("rg", global))
+	require.Equal(t, int64(100), groupTracker.GetBytesLimit())
+
+	stmtTracker := memory.NewTracker(memory.LabelForSQLText, -1)
+	stmtTracker.AttachToGlobalTracker(groupTracker)
+	stmtTracker.Consume(50)
+	require.Equal(t, int64(50), groupTracker.BytesConsumed())
+	require.Equal(t, int64(50), global.BytesConsumed())
+	require.Equal(t, int64(50), m.Stats()[0].MemoryConsume)
+	require.PanicsWithValue(t, memory.PanicMemoryExceed+"[resource_group=rg]", func() {
+		stmtTracker.Consume(60)
+	})
+	stmtTracker.DetachFromGlobalTracker()
+	require.Equal(t, int64(0), groupTracker.BytesConsumed())
+}
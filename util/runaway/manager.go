@@ -0,0 +1,419 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package runaway identifies runaway queries and quarantines their digests.
+//
+// The rules have no dedicated SQL syntax, they are managed by DML on
+// mysql.runaway_rules, e.g.
+//
+//	INSERT INTO mysql.runaway_rules (sql_digest, max_execution_time, quarantine_duration) VALUES ('<digest>', 60000, 600);
+//
+// Changes take effect on every instance after the next Update. A row that
+// can't be used as a rule, such as a malformed digest or a rule without any
+// limit, is skipped with a warning log instead of failing the whole load.
+package runaway
+
+import (
+	"context"
+	"encoding/hex"
+	"math"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/errno"
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util"
+	"github.com/pingcap/tidb/util/dbterror"
+	"github.com/pingcap/tidb/util/logutil"
+	"github.com/pingcap/tidb/util/sqlexec"
+	"go.uber.org/zap"
+)
+
+const (
+	// ActionKill is recorded when a runaway query is killed.
+	ActionKill = "kill"
+	// ActionReject is recorded when a statement is rejected because its digest is quarantined.
+	ActionReject = "reject"
+
+	// maxPendingRecords is the max number of records kept in memory before
+	// they are written to mysql.runaway_history, records beyond it are dropped.
+	maxPendingRecords = 1024
+	// maxSampleSQLLen is the max length of the sample SQL in a record.
+	maxSampleSQLLen = 4096
+	// maxFlushRetries is the max number of times a record is written to
+	// mysql.runaway_history, the record is dropped after that many failures.
+	maxFlushRetries = 3
+)
+
+// ErrQueryQuarantined is returned when a statement is rejected because its digest is quarantined.
+var ErrQueryQuarantined = dbterror.ClassUtil.NewStd(errno.ErrRunawayQueryQuarantined)
+
+// Rule identifies runaway queries, it is loaded from mysql.runaway_rules.
+type Rule struct {
+	ID int64
+	// SQLDigest and PlanDigest restrict the statements the rule applies to,
+	// an empty digest matches all statements.
+	SQLDigest  string
+	PlanDigest string
+	// A statement is a runaway query once it exceeds any of the limits,
+	// a zero limit means no limit.
+	MaxExecutionTime time.Duration
+	MaxProcessedKeys uint64
+	// QuarantineDuration is how long the digest of a killed statement is
+	// quarantined, zero means the digest is not quarantined.
+	QuarantineDuration time.Duration
+}
+
+// ruleFromRow builds a rule from a row of mysql.runaway_rules, the row is
+// checked because the table is edited by users directly.
+func ruleFromRow(id int64, sqlDigest, planDigest string, maxExecutionTime, maxProcessedKeys, quarantineDuration uint64) (*Rule, error) {
+	sqlDigest, planDigest = strings.ToLower(sqlDigest), strings.ToLower(planDigest)
+	if !isValidDigest(sqlDigest) {
+		return nil, errors.Errorf("invalid sql_digest %q", sqlDigest)
+	}
+	if !isValidDigest(planDigest) {
+		return nil, errors.Errorf("invalid plan_digest %q", planDigest)
+	}
+	if maxExecutionTime == 0 && maxProcessedKeys == 0 {
+		return nil, errors.New("neither max_execution_time nor max_processed_keys is set")
+	}
+	if maxExecutionTime > uint64(math.MaxInt64/time.Millisecond) {
+		return nil, errors.Errorf("max_execution_time %d is too large", maxExecutionTime)
+	}
+	if quarantineDuration > uint64(math.MaxInt64/time.Second) {
+		return nil, errors.Errorf("quarantine_duration %d is too large", quarantineDuration)
+	}
+	return &Rule{
+		ID:                 id,
+		SQLDigest:          sqlDigest,
+		PlanDigest:         planDigest,
+		MaxExecutionTime:   time.Duration(maxExecutionTime) * time.Millisecond,
+		MaxProcessedKeys:   maxProcessedKeys,
+		QuarantineDuration: time.Duration(quarantineDuration) * time.Second,
+	}, nil
+}
+
+// isValidDigest checks whether the digest is empty or a hex encoded SHA-256 hash.
+func isValidDigest(digest string) bool {
+	if digest == "" {
+		return true
+	}
+	if len(digest) != hex.EncodedLen(32) {
+		return false
+	}
+	_, err := hex.DecodeString(digest)
+	return err == nil
+}
+
+func (r *Rule) match(sqlDigest, planDigest string) bool {
+	return (r.SQLDigest == "" || r.SQLDigest == sqlDigest) && (r.PlanDigest == "" || r.PlanDigest == planDigest)
+}
+
+// Record is an action taken on a runaway query, it is written to mysql.runaway_history.
+type Record struct {
+	Time       time.Time
+	RuleID     int64
+	Action     string
+	SQLDigest  string
+	PlanDigest string
+	SampleSQL  string
+	// QuarantineEnd is zero if the digest is not quarantined.
+	QuarantineEnd time.Time
+
+	flushFailures int
+}
+
+// quarantine rejects the statements of a SQL digest, and of a plan digest if
+// PlanDigest is not empty, until End.
+type quarantine struct {
+	RuleID     int64
+	PlanDigest string
+	End        time.Time
+}
+
+// Manager identifies runaway queries by the rules, and quarantines their
+// digests. The rules and the quarantines are shared by all instances through
+// the system tables, which are synchronized by Update and Flush.
+type Manager struct {
+	rules          atomic.Value // []*Rule
+	needPlanDigest uint32
+
+	mu          sync.Mutex
+	quarantines map[string][]quarantine // sql digest -> quarantines
+	records     []*Record
+}
+
+// NewManager creates a Manager without any rules.
+func NewManager() *Manager {
+	m := &Manager{quarantines: make(map[string][]quarantine)}
+	m.rules.Store([]*Rule(nil))
+	return m
+}
+
+// SetRules replaces the rules.
+func (m *Manager) SetRules(rules []*Rule) {
+	m.rules.Store(rules)
+	m.mu.Lock()
+	m.updateNeedPlanDigestLocked()
+	m.mu.Unlock()
+}
+
+func (m *Manager) getRules() []*Rule {
+	return m.rules.Load().([]*Rule)
+}
+
+func (m *Manager) updateNeedPlanDigestLocked() {
+	need := false
+	for _, rule := range m.getRules() {
+		need = need || rule.PlanDigest != ""
+	}
+	for _, qs := range m.quarantines {
+		for _, q := range qs {
+			need = need || q.PlanDigest != ""
+		}
+	}
+	if need {
+		atomic.StoreUint32(&m.needPlanDigest, 1)
+	} else {
+		atomic.StoreUint32(&m.needPlanDigest, 0)
+	}
+}
+
+// NeedPlanDigest returns whether any rule or quarantine matches plan digests,
+// the plan digest of statements should be generated before execution if so.
+func (m *Manager) NeedPlanDigest() bool {
+	return atomic.LoadUint32(&m.needPlanDigest) == 1
+}
+
+// CheckQuarantine returns ErrQueryQuarantined and records the rejection if
+// the digests are quarantined.
+func (m *Manager) CheckQuarantine(sqlDigest, planDigest, sql string) error {
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, q := range m.quarantines[sqlDigest] {
+		if now.After(q.End) || (q.PlanDigest != "" && q.PlanDigest != planDigest) {
+			continue
+		}
+		m.addRecordLocked(&Record{
+			Time:       now,
+			RuleID:     q.RuleID,
+			Action:     ActionReject,
+			SQLDigest:  sqlDigest,
+			PlanDigest: planDigest,
+			SampleSQL:  sql,
+		})
+		return ErrQueryQuarantined.GenWithStackByArgs(sqlDigest, q.End.Format(types.TimeFSPFormat))
+	}
+	return nil
+}
+
+// CheckRunaway returns the rule that the running statement violates, nil
+// is returned if there is none. If a rule is returned, the statement should
+// be killed, and the kill is recorded and the digest quarantined here.
+func (m *Manager) CheckRunaway(info *util.ProcessInfo, costTime time.Duration) *Rule {
+	rules := m.getRules()
+	if len(rules) == 0 || info.StmtCtx == nil {
+		return nil
+	}
+	var processedKeys uint64
+	if scanDetail := info.StmtCtx.GetExecDetails().ScanDetail; scanDetail != nil {
+		processedKeys = uint64(scanDetail.ProcessedKeys)
+	}
+	for _, rule := range rules {
+		if !rule.match(info.Digest, info.PlanDigest) {
+			continue
+		}
+		if (rule.MaxExecutionTime > 0 && costTime > rule.MaxExecutionTime) ||
+			(rule.MaxProcessedKeys > 0 && processedKeys > rule.MaxProcessedKeys) {
+			m.onRunaway(rule, info)
+			return rule
+		}
+	}
+	return nil
+}
+
+func (m *Manager) onRunaway(rule *Rule, info *util.ProcessInfo) {
+	sql := info.Info
+	if info.RedactSQL {
+		sql = parser.Normalize(sql)
+	}
+	record := &Record{
+		// Keep the precision of the timestamp columns.
+		Time:       time.Now().Truncate(time.Microsecond),
+		RuleID:     rule.ID,
+		Action:     ActionKill,
+		SQLDigest:  info.Digest,
+		PlanDigest: info.PlanDigest,
+		SampleSQL:  sql,
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if rule.QuarantineDuration > 0 {
+		record.QuarantineEnd = record.Time.Add(rule.QuarantineDuration)
+		q := quarantine{RuleID: rule.ID, End: record.QuarantineEnd}
+		// Only quarantine the plan if the rule is about the plan.
+		if rule.PlanDigest != "" {
+			q.PlanDigest = info.PlanDigest
+		}
+		m.quarantines[info.Digest] = append(m.quarantines[info.Digest], q)
+		m.updateNeedPlanDigestLocked()
+	}
+	m.addRecordLocked(record)
+	logutil.BgLogger().Warn("kill runaway query",
+		zap.Int64("rule", rule.ID),
+		zap.Uint64("conn", info.ID),
+		zap.String("sql digest", info.Digest),
+		zap.String("plan digest", info.PlanDigest),
+		zap.Time("quarantine end", record.QuarantineEnd))
+}
+
+func (m *Manager) addRecordLocked(record *Record) {
+	if len(m.records) >= maxPendingRecords {
+		return
+	}
+	record.SampleSQL = truncateSQL(record.SampleSQL, maxSampleSQLLen)
+	m.records = append(m.records, record)
+}
+
+// truncateSQL cuts sql to at most maxLen bytes without splitting a UTF-8 character.
+func truncateSQL(sql string, maxLen int) string {
+	if len(sql) <= maxLen {
+		return sql
+	}
+	end := maxLen
+	for end > 0 && !utf8.RuneStart(sql[end]) {
+		end--
+	}
+	return sql[:end]
+}
+
+// Update reloads the rules from mysql.runaway_rules, and the unexpired
+// quarantines from mysql.runaway_history. Invalid rows of mysql.runaway_rules
+// are skipped with a warning log, the digests quarantined by them are released.
+func (m *Manager) Update(sctx sessionctx.Context) error {
+	exec := sctx.(sqlexec.RestrictedSQLExecutor)
+	ctx := context.Background()
+	rows, _, err := exec.ExecRestrictedSQL(ctx, nil, "SELECT id, sql_digest, plan_digest, max_execution_time, max_processed_keys, quarantine_duration FROM mysql.runaway_rules")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	rules := make([]*Rule, 0, len(rows))
+	ruleMap := make(map[int64]*Rule, len(rows))
+	for _, row := range rows {
+		rule, err := ruleFromRow(row.GetInt64(0), row.GetString(1), row.GetString(2), row.GetUint64(3), row.GetUint64(4), row.GetUint64(5))
+		if err != nil {
+			logutil.BgLogger().Warn("skip invalid runaway query rule", zap.Int64("id", row.GetInt64(0)), zap.Error(err))
+			continue
+		}
+		rules = append(rules, rule)
+		ruleMap[rule.ID] = rule
+	}
+
+	loc := sctx.GetSessionVars().Location()
+	rows, _, err = exec.ExecRestrictedSQL(ctx, nil, "SELECT rule_id, sql_digest, plan_digest, quarantine_end FROM mysql.runaway_history WHERE quarantine_end > %?", time.Now().In(loc))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	quarantines := make(map[string][]quarantine, len(rows))
+	for _, row := range rows {
+		// Dropping a rule releases the digests quarantined by it.
+		rule, ok := ruleMap[row.GetInt64(0)]
+		if !ok {
+			continue
+		}
+		end, err := row.GetTime(3).GoTime(loc)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		q := quarantine{RuleID: rule.ID, End: end}
+		if rule.PlanDigest != "" {
+			q.PlanDigest = row.GetString(2)
+		}
+		sqlDigest := row.GetString(1)
+		quarantines[sqlDigest] = append(quarantines[sqlDigest], q)
+	}
+
+	m.rules.Store(rules)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// Keep the quarantines which are not written to the table yet.
+	now := time.Now()
+	for sqlDigest, qs := range m.quarantines {
+		for _, q := range qs {
+			if _, ok := ruleMap[q.RuleID]; ok && q.End.After(now) && !containsQuarantine(quarantines[sqlDigest], q) {
+				quarantines[sqlDigest] = append(quarantines[sqlDigest], q)
+			}
+		}
+	}
+	m.quarantines = quarantines
+	m.updateNeedPlanDigestLocked()
+	return nil
+}
+
+func containsQuarantine(qs []quarantine, target quarantine) bool {
+	for _, q := range qs {
+		if q.RuleID == target.RuleID && q.PlanDigest == target.PlanDigest && q.End.Equal(target.End) {
+			return true
+		}
+	}
+	return false
+}
+
+// Flush writes the pending records to mysql.runaway_history.
+func (m *Manager) Flush(sctx sessionctx.Context, instance string) error {
+	m.mu.Lock()
+	records := m.records
+	m.records = nil
+	m.mu.Unlock()
+	if len(records) == 0 {
+		return nil
+	}
+
+	loc := sctx.GetSessionVars().Location()
+	var sql strings.Builder
+	sqlexec.MustFormatSQL(&sql, "INSERT INTO mysql.runaway_history (time, instance, rule_id, action, sql_digest, plan_digest, sample_sql, quarantine_end) VALUES ")
+	for i, record := range records {
+		if i > 0 {
+			sql.WriteString(", ")
+		}
+		var quarantineEnd interface{}
+		if !record.QuarantineEnd.IsZero() {
+			quarantineEnd = record.QuarantineEnd.In(loc)
+		}
+		sqlexec.MustFormatSQL(&sql, "(%?, %?, %?, %?, %?, %?, %?, %?)", record.Time.In(loc), instance, record.RuleID,
+			record.Action, record.SQLDigest, record.PlanDigest, record.SampleSQL, quarantineEnd)
+	}
+	_, _, err := sctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(context.Background(), nil, sql.String())
+	if err != nil {
+		// Retry on the next flush, a record that keeps failing is dropped so
+		// that it doesn't block the records after it.
+		m.mu.Lock()
+		for _, record := range records {
+			record.flushFailures++
+			if record.flushFailures < maxFlushRetries {
+				m.addRecordLocked(record)
+			}
+		}
+		m.mu.Unlock()
+		return errors.Trace(err)
+	}
+	return nil
+}
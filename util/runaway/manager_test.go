@@ -0,0 +1,129 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runaway
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pingcap/tidb/sessionctx/stmtctx"
+	"github.com/pingcap/tidb/util"
+	"github.com/pingcap/tidb/util/execdetails"
+	"github.com/stretchr/testify/require"
+	tikvutil "github.com/tikv/client-go/v2/util"
+)
+
+func newProcessInfo(sqlDigest, planDigest string, processedKeys int64) *util.ProcessInfo {
+	sc := &stmtctx.StatementContext{}
+	sc.MergeExecDetails(&execdetails.ExecDetails{ScanDetail: &tikvutil.ScanDetail{ProcessedKeys: processedKeys}}, nil)
+	return &util.ProcessInfo{
+		ID:         1,
+		Digest:     sqlDigest,
+		PlanDigest: planDigest,
+		Info:       "select * from t",
+		StmtCtx:    sc,
+	}
+}
+
+func TestCheckRunaway(t *testing.T) {
+	m := NewManager()
+	require.Nil(t, m.CheckRunaway(newProcessInfo("s1", "p1", 100), time.Hour))
+
+	m.SetRules([]*Rule{
+		{ID: 1, SQLDigest: "s1", MaxExecutionTime: time.Minute},
+		{ID: 2, MaxProcessedKeys: 1000},
+	})
+	require.False(t, m.NeedPlanDigest())
+	require.Nil(t, m.CheckRunaway(newProcessInfo("s1", "p1", 100), time.Second))
+	require.Nil(t, m.CheckRunaway(newProcessInfo("s2", "p2", 100), time.Hour))
+	require.Equal(t, int64(1), m.CheckRunaway(newProcessInfo("s1", "p1", 100), time.Hour).ID)
+	require.Equal(t, int64(2), m.CheckRunaway(newProcessInfo("s2", "p2", 1001), time.Second).ID)
+
+	// No rule quarantines the digests.
+	require.NoError(t, m.CheckQuarantine("s1", "p1", ""))
+	require.Len(t, m.records, 2)
+	for _, record := range m.records {
+		require.Equal(t, ActionKill, record.Action)
+		require.True(t, record.QuarantineEnd.IsZero())
+	}
+}
+
+func TestQuarantine(t *testing.T) {
+	m := NewManager()
+	m.SetRules([]*Rule{
+		{ID: 1, SQLDigest: "s1", MaxExecutionTime: time.Minute, QuarantineDuration: time.Hour},
+		{ID: 2, SQLDigest: "s2", PlanDigest: "p2", MaxExecutionTime: time.Minute, QuarantineDuration: time.Hour},
+	})
+	require.True(t, m.NeedPlanDigest())
+
+	require.NotNil(t, m.CheckRunaway(newProcessInfo("s1", "p1", 0), time.Hour))
+	// All plans of the digest are quarantined.
+	require.True(t, ErrQueryQuarantined.Equal(m.CheckQuarantine("s1", "p1", "")))
+	require.True(t, ErrQueryQuarantined.Equal(m.CheckQuarantine("s1", "p3", "")))
+	require.NoError(t, m.CheckQuarantine("s3", "p1", ""))
+
+	require.NotNil(t, m.CheckRunaway(newProcessInfo("s2", "p2", 0), time.Hour))
+	// Only the plan of the rule is quarantined.
+	require.True(t, ErrQueryQuarantined.Equal(m.CheckQuarantine("s2", "p2", "")))
+	require.NoError(t, m.CheckQuarantine("s2", "p3", ""))
+
+	actions := make([]string, 0, len(m.records))
+	for _, record := range m.records {
+		actions = append(actions, record.Action)
+		if record.Action == ActionKill {
+			require.Equal(t, record.Time.Add(time.Hour), record.QuarantineEnd)
+		}
+	}
+	require.Equal(t, []string{ActionKill, ActionReject, ActionReject, ActionKill, ActionReject}, actions)
+}
+
+func TestTruncateSQL(t *testing.T) {
+	require.Equal(t, "select 1", truncateSQL("select 1", 10))
+	require.Equal(t, "select", truncateSQL("select 1", 6))
+	// "中" takes 3 bytes, the cut never splits it.
+	sql := "select '" + strings.Repeat("中", 10) + "'"
+	require.Equal(t, "select '中", truncateSQL(sql, 11))
+	require.Equal(t, "select '中", truncateSQL(sql, 12))
+	require.Equal(t, "select '中中", truncateSQL(sql, 14))
+}
+
+func TestRuleFromRow(t *testing.T) {
+	digest := strings.Repeat("ab", 32)
+	rule, err := ruleFromRow(1, strings.ToUpper(digest), "", 60000, 0, 600)
+	require.NoError(t, err)
+	require.Equal(t, &Rule{ID: 1, SQLDigest: digest, MaxExecutionTime: time.Minute, QuarantineDuration: 10 * time.Minute}, rule)
+	rule, err = ruleFromRow(2, "", digest, 0, 1000, 0)
+	require.NoError(t, err)
+	require.Equal(t, &Rule{ID: 2, PlanDigest: digest, MaxProcessedKeys: 1000}, rule)
+
+	for _, c := range []struct {
+		sqlDigest, planDigest                                  string
+		maxExecutionTime, maxProcessedKeys, quarantineDuration uint64
+		errMsg                                                 string
+	}{
+		{"select 1", "", 1, 0, 0, "invalid sql_digest"},
+		{digest[:62], "", 1, 0, 0, "invalid sql_digest"},
+		{"", strings.Repeat("zz", 32), 1, 0, 0, "invalid plan_digest"},
+		{digest, "", 0, 0, 600, "neither max_execution_time nor max_processed_keys is set"},
+		{digest, "", math.MaxUint64, 0, 0, "max_execution_time"},
+		{digest, "", 1, 0, math.MaxUint64, "quarantine_duration"},
+	} {
+		_, err := ruleFromRow(3, c.sqlDigest, c.planDigest, c.maxExecutionTime, c.maxProcessedKeys, c.quarantineDuration)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), c.errMsg)
+	}
+}
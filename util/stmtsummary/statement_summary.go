@@ -419,6 +419,158 @@ func (ssMap *stmtSummaryByDigestMap) GetMoreThanCntBindableStmt(cnt int64) []*Bi
 	return stmts
 }
 
+// WorkloadStmt is a statement of the workload, which is used to recommend indexes.
+type WorkloadStmt struct {
+	Schema     string
+	Digest     string
+	Query      string
+	Charset    string
+	Collation  string
+	ExecCount  int64
+	SumLatency time.Duration
+}
+
+// GetTopLatencyStmts gets users' select/update/delete SQLs with the top n total latency in all the intervals.
+// Prepared statements are skipped because their sample SQLs don't carry the parameters.
+func (ssMap *stmtSummaryByDigestMap) GetTopLatencyStmts(n int) []*WorkloadStmt {
+	ssMap.Lock()
+	values := ssMap.summaryMap.Values()
+	ssMap.Unlock()
+
+	// Statements with the same digest but different plans are merged.
+	stmtMap := make(map[string]*WorkloadStmt, len(values))
+	for _, value := range values {
+		ssbd := value.(*stmtSummaryByDigest)
+		func() {
+			ssbd.Lock()
+			defer ssbd.Unlock()
+			if !ssbd.initialized || ssbd.isInternal || (ssbd.stmtType != "Select" && ssbd.stmtType != "Delete" && ssbd.stmtType != "Update") {
+				return
+			}
+			for elem := ssbd.history.Front(); elem != nil; elem = elem.Next() {
+				ssElement := elem.Value.(*stmtSummaryByDigestElement)
+				ssElement.Lock()
+				// Empty auth users means that it is an internal queries.
+				if len(ssElement.authUsers) > 0 && !ssElement.prepared {
+					key := ssbd.schemaName + "." + ssbd.digest
+					stmt, ok := stmtMap[key]
+					if !ok {
+						stmt = &WorkloadStmt{
+							Schema:    ssbd.schemaName,
+							Digest:    ssbd.digest,
+							Query:     ssElement.sampleSQL,
+							Charset:   ssElement.charset,
+							Collation: ssElement.collation,
+						}
+						stmtMap[key] = stmt
+					}
+					stmt.ExecCount += ssElement.execCount
+					stmt.SumLatency += ssElement.sumLatency
+				}
+				ssElement.Unlock()
+			}
+		}()
+	}
+
+	stmts := make([]*WorkloadStmt, 0, len(stmtMap))
+	for _, stmt := range stmtMap {
+		stmts = append(stmts, stmt)
+	}
+	sort.Slice(stmts, func(i, j int) bool {
+		if stmts[i].SumLatency != stmts[j].SumLatency {
+			return stmts[i].SumLatency > stmts[j].SumLatency
+		}
+		return stmts[i].Digest < stmts[j].Digest
+	})
+	if len(stmts) > n {
+		stmts = stmts[:n]
+	}
+	return stmts
+}
+
+// PlanHistoryStmt is the summary of a statement executed with one plan, which is used to detect plan regressions.
+type PlanHistoryStmt struct {
+	Schema     string
+	Digest     string
+	PlanDigest string
+	Query      string
+	PlanHint   string
+	Charset    string
+	Collation  string
+	ExecCount  int64
+	SumLatency time.Duration
+	// FirstSeen is the first time the statement executes with this plan.
+	FirstSeen time.Time
+}
+
+// GetPlanHistoryStmts gets the plans of users' select/update/delete SQLs in all the intervals.
+// Each plan of a statement is returned as one PlanHistoryStmt, and the plans of the same statement are adjacent and
+// ordered by the time they are first seen.
+func (ssMap *stmtSummaryByDigestMap) GetPlanHistoryStmts() []*PlanHistoryStmt {
+	ssMap.Lock()
+	values := ssMap.summaryMap.Values()
+	ssMap.Unlock()
+
+	stmts := make([]*PlanHistoryStmt, 0, len(values))
+	for _, value := range values {
+		ssbd := value.(*stmtSummaryByDigest)
+		func() {
+			ssbd.Lock()
+			defer ssbd.Unlock()
+			if !ssbd.initialized || ssbd.isInternal || len(ssbd.planDigest) == 0 || (ssbd.stmtType != "Select" && ssbd.stmtType != "Delete" && ssbd.stmtType != "Update") {
+				return
+			}
+			var stmt *PlanHistoryStmt
+			for elem := ssbd.history.Front(); elem != nil; elem = elem.Next() {
+				ssElement := elem.Value.(*stmtSummaryByDigestElement)
+				ssElement.Lock()
+				// Empty auth users means that it is an internal queries.
+				if len(ssElement.authUsers) > 0 && len(ssElement.planHint) > 0 {
+					if stmt == nil {
+						stmt = &PlanHistoryStmt{
+							Schema:     ssbd.schemaName,
+							Digest:     ssbd.digest,
+							PlanDigest: ssbd.planDigest,
+							Query:      ssElement.sampleSQL,
+							PlanHint:   ssElement.planHint,
+							Charset:    ssElement.charset,
+							Collation:  ssElement.collation,
+							FirstSeen:  ssElement.firstSeen,
+						}
+						// The sample SQL of the prepared statements is `execute ...`, so the normalized SQL is used.
+						if ssElement.prepared {
+							stmt.Query = ssbd.normalizedSQL
+						}
+					}
+					if ssElement.firstSeen.Before(stmt.FirstSeen) {
+						stmt.FirstSeen = ssElement.firstSeen
+					}
+					stmt.ExecCount += ssElement.execCount
+					stmt.SumLatency += ssElement.sumLatency
+				}
+				ssElement.Unlock()
+			}
+			if stmt != nil {
+				stmts = append(stmts, stmt)
+			}
+		}()
+	}
+
+	sort.Slice(stmts, func(i, j int) bool {
+		if stmts[i].Schema != stmts[j].Schema {
+			return stmts[i].Schema < stmts[j].Schema
+		}
+		if stmts[i].Digest != stmts[j].Digest {
+			return stmts[i].Digest < stmts[j].Digest
+		}
+		if !stmts[i].FirstSeen.Equal(stmts[j].FirstSeen) {
+			return stmts[i].FirstSeen.Before(stmts[j].FirstSeen)
+		}
+		return stmts[i].PlanDigest < stmts[j].PlanDigest
+	})
+	return stmts
+}
+
 // SetEnabled enables or disables statement summary
 func (ssMap *stmtSummaryByDigestMap) SetEnabled(value bool) error {
 	// `optEnabled` and `ssMap` don't need to be strictly atomically updated.
@@ -1145,6 +1145,83 @@ func TestGetMoreThanCntBindableStmt(t *testing.T) {
 	require.Equal(t, 1, len(stmts))
 }
 
+// Test GetTopLatencyStmts.
+func TestGetTopLatencyStmts(t *testing.T) {
+	ssMap := newStmtSummaryByDigestMap()
+
+	stmtExecInfo1 := generateAnyExecInfo()
+	stmtExecInfo1.OriginalSQL = "insert 1"
+	stmtExecInfo1.NormalizedSQL = "insert ?"
+	stmtExecInfo1.StmtCtx.StmtType = "Insert"
+	ssMap.AddStatement(stmtExecInfo1)
+	require.Empty(t, ssMap.GetTopLatencyStmts(10))
+
+	stmtExecInfo1.OriginalSQL = "select 1"
+	stmtExecInfo1.NormalizedSQL = "select ?"
+	stmtExecInfo1.Digest = "digest1"
+	stmtExecInfo1.StmtCtx.StmtType = "Select"
+	ssMap.AddStatement(stmtExecInfo1)
+	// The same digest with another plan is merged.
+	stmtExecInfo1.PlanDigest = "plan_digest2"
+	ssMap.AddStatement(stmtExecInfo1)
+
+	stmtExecInfo2 := generateAnyExecInfo()
+	stmtExecInfo2.OriginalSQL = "update t set a = 1"
+	stmtExecInfo2.NormalizedSQL = "update t set a = ?"
+	stmtExecInfo2.Digest = "digest2"
+	stmtExecInfo2.StmtCtx.StmtType = "Update"
+	stmtExecInfo2.TotalLatency = 30000
+	ssMap.AddStatement(stmtExecInfo2)
+
+	stmts := ssMap.GetTopLatencyStmts(10)
+	require.Len(t, stmts, 2)
+	require.Equal(t, "update t set a = 1", stmts[0].Query)
+	require.Equal(t, int64(1), stmts[0].ExecCount)
+	require.Equal(t, "select 1", stmts[1].Query)
+	require.Equal(t, int64(2), stmts[1].ExecCount)
+	require.Equal(t, 2*stmtExecInfo1.TotalLatency, stmts[1].SumLatency)
+
+	stmts = ssMap.GetTopLatencyStmts(1)
+	require.Len(t, stmts, 1)
+	require.Equal(t, "digest2", stmts[0].Digest)
+}
+
+// Test GetPlanHistoryStmts.
+func TestGetPlanHistoryStmts(t *testing.T) {
+	ssMap := newStmtSummaryByDigestMap()
+
+	stmtExecInfo1 := generateAnyExecInfo()
+	stmtExecInfo1.OriginalSQL = "select 1"
+	stmtExecInfo1.NormalizedSQL = "select ?"
+	stmtExecInfo1.Digest = "digest1"
+	stmtExecInfo1.StmtCtx.StmtType = "Select"
+	// Plans without hints can't be bound, so they are skipped.
+	ssMap.AddStatement(stmtExecInfo1)
+	require.Empty(t, ssMap.GetPlanHistoryStmts())
+
+	stmtExecInfo1.PlanGenerator = func() (string, string) { return "", "hint1" }
+	stmtExecInfo1.PlanDigest = "plan_digest2"
+	stmtExecInfo1.StartTime = time.Date(2019, 1, 1, 10, 10, 30, 10, time.UTC)
+	ssMap.AddStatement(stmtExecInfo1)
+	ssMap.AddStatement(stmtExecInfo1)
+	stmtExecInfo1.PlanGenerator = func() (string, string) { return "", "hint2" }
+	stmtExecInfo1.PlanDigest = "plan_digest3"
+	stmtExecInfo1.StartTime = time.Date(2019, 1, 1, 10, 10, 25, 10, time.UTC)
+	stmtExecInfo1.TotalLatency = 30000
+	ssMap.AddStatement(stmtExecInfo1)
+
+	stmts := ssMap.GetPlanHistoryStmts()
+	require.Len(t, stmts, 2)
+	require.Equal(t, "plan_digest3", stmts[0].PlanDigest)
+	require.Equal(t, "hint2", stmts[0].PlanHint)
+	require.Equal(t, int64(1), stmts[0].ExecCount)
+	require.Equal(t, time.Duration(30000), stmts[0].SumLatency)
+	require.Equal(t, "plan_digest2", stmts[1].PlanDigest)
+	require.Equal(t, "hint1", stmts[1].PlanHint)
+	require.Equal(t, "select 1", stmts[1].Query)
+	require.Equal(t, int64(2), stmts[1].ExecCount)
+}
+
 // Test `formatBackoffTypes`.
 func TestFormatBackoffTypes(t *testing.T) {
 	backoffMap := make(map[string]int)
@@ -0,0 +1,207 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/opentracing/basictracer-go"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pingcap/log"
+	"go.uber.org/zap"
+)
+
+const (
+	// w3cTraceIDKey is the baggage key carrying the 128-bit W3C trace id.
+	w3cTraceIDKey = "w3c-trace-id"
+	// w3cParentIDKey is the baggage key carrying the span id of the remote caller.
+	w3cParentIDKey = "w3c-parent-id"
+)
+
+// ExportedSpan is a finished span of a sampled W3C trace.
+type ExportedSpan struct {
+	TraceID      string
+	SpanID       string
+	ParentSpanID string
+	Operation    string
+	Start        time.Time
+	Duration     time.Duration
+	Tags         map[string]interface{}
+}
+
+// SpanExporter receives the finished spans of sampled W3C traces.
+// ExportSpan is called synchronously when a span finishes, so implementations
+// should hand the span off to a background worker instead of blocking.
+type SpanExporter interface {
+	ExportSpan(span ExportedSpan)
+}
+
+type exporterHolder struct {
+	exporter SpanExporter
+}
+
+var (
+	globalExporter atomic.Value // *exporterHolder
+	// sampleRate is the probability, scaled to [0, math.MaxUint32], that a
+	// statement without a caller supplied trace context is traced.
+	sampleRate uint32
+)
+
+// SetExporter sets the exporter for sampled W3C traces. A nil exporter
+// disables W3C tracing.
+func SetExporter(exporter SpanExporter) {
+	globalExporter.Store(&exporterHolder{exporter: exporter})
+}
+
+// GetExporter returns the current exporter, or nil if none is set.
+func GetExporter() SpanExporter {
+	if h, ok := globalExporter.Load().(*exporterHolder); ok {
+		return h.exporter
+	}
+	return nil
+}
+
+// SetSampleRate sets the probability in [0, 1] that a statement is traced
+// when the client does not supply a trace context.
+func SetSampleRate(rate float64) {
+	if rate < 0 {
+		rate = 0
+	} else if rate > 1 {
+		rate = 1
+	}
+	atomic.StoreUint32(&sampleRate, uint32(rate*float64(^uint32(0))))
+}
+
+// GetSampleRate returns the sample rate set by SetSampleRate.
+func GetSampleRate() float64 {
+	return float64(atomic.LoadUint32(&sampleRate)) / float64(^uint32(0))
+}
+
+func shouldSample() bool {
+	rate := atomic.LoadUint32(&sampleRate)
+	return rate != 0 && rand.Uint32() < rate // #nosec G404
+}
+
+var w3cTracer = basictracer.NewWithOptions(basictracer.Options{
+	ShouldSample:   func(uint64) bool { return true },
+	MaxLogsPerSpan: 100,
+	Recorder:       CallbackRecorder(exportRawSpan),
+})
+
+// StartW3CSpan starts a root span of a W3C trace if the statement should be
+// traced, otherwise it returns nil. A non-empty traceParent is the
+// `traceparent` supplied by the client; its sampled flag decides whether the
+// statement is traced. Without it, the statement is traced according to the
+// sample rate. Child spans started from the returned span inherit the trace id
+// and are handed to the exporter when they finish.
+func StartW3CSpan(opName string, traceParent string) opentracing.Span {
+	if GetExporter() == nil {
+		return nil
+	}
+	var parent TraceParent
+	if len(traceParent) > 0 {
+		var err error
+		if parent, err = ParseTraceParent(traceParent); err != nil || !parent.Sampled() {
+			return nil
+		}
+	} else {
+		if !shouldSample() {
+			return nil
+		}
+		parent = NewTraceParent(true)
+		// The trace starts here, so there is no remote parent.
+		parent.ParentID = [8]byte{}
+	}
+	sp := w3cTracer.StartSpan(opName)
+	sp.SetBaggageItem(w3cTraceIDKey, parent.TraceIDString())
+	if !isZero(parent.ParentID[:]) {
+		sp.SetBaggageItem(w3cParentIDKey, parent.ParentIDString())
+	}
+	return sp
+}
+
+func exportRawSpan(sp basictracer.RawSpan) {
+	exporter := GetExporter()
+	if exporter == nil {
+		return
+	}
+	traceID, ok := sp.Context.Baggage[w3cTraceIDKey]
+	if !ok {
+		return
+	}
+	parentSpanID := sp.Context.Baggage[w3cParentIDKey]
+	if sp.ParentSpanID != 0 {
+		parentSpanID = formatSpanID(sp.ParentSpanID)
+	}
+	exporter.ExportSpan(ExportedSpan{
+		TraceID:      traceID,
+		SpanID:       formatSpanID(sp.Context.SpanID),
+		ParentSpanID: parentSpanID,
+		Operation:    sp.Operation,
+		Start:        sp.Start,
+		Duration:     sp.Duration,
+		Tags:         sp.Tags,
+	})
+}
+
+func formatSpanID(id uint64) string {
+	return fmt.Sprintf("%016x", id)
+}
+
+// MemoryExporter keeps exported spans in memory, it is mainly used in tests.
+type MemoryExporter struct {
+	mu    sync.Mutex
+	spans []ExportedSpan
+}
+
+// ExportSpan implements SpanExporter.
+func (e *MemoryExporter) ExportSpan(span ExportedSpan) {
+	e.mu.Lock()
+	e.spans = append(e.spans, span)
+	e.mu.Unlock()
+}
+
+// Spans returns a copy of the exported spans.
+func (e *MemoryExporter) Spans() []ExportedSpan {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]ExportedSpan(nil), e.spans...)
+}
+
+// Reset drops all exported spans.
+func (e *MemoryExporter) Reset() {
+	e.mu.Lock()
+	e.spans = nil
+	e.mu.Unlock()
+}
+
+// LogExporter writes exported spans to the log.
+type LogExporter struct{}
+
+// ExportSpan implements SpanExporter.
+func (LogExporter) ExportSpan(span ExportedSpan) {
+	log.Info("trace span",
+		zap.String("trace-id", span.TraceID),
+		zap.String("span-id", span.SpanID),
+		zap.String("parent-span-id", span.ParentSpanID),
+		zap.String("operation", span.Operation),
+		zap.Time("start", span.Start),
+		zap.Duration("duration", span.Duration),
+		zap.Any("tags", span.Tags))
+}
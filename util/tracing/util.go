@@ -60,7 +60,7 @@ func SpanFromContext(ctx context.Context) (sp opentracing.Span) {
 func ChildSpanFromContxt(ctx context.Context, opName string) (opentracing.Span, context.Context) {
 	if sp := opentracing.SpanFromContext(ctx); sp != nil {
 		if _, ok := sp.Tracer().(opentracing.NoopTracer); !ok {
-			child := opentracing.StartSpan(opName, opentracing.ChildOf(sp.Context()))
+			child := sp.Tracer().StartSpan(opName, opentracing.ChildOf(sp.Context()))
 			return child, opentracing.ContextWithSpan(ctx, child)
 		}
 	}
@@ -0,0 +1,122 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+const (
+	// traceParentVersion is the only version of the W3C trace context we generate.
+	traceParentVersion = "00"
+	// traceFlagSampled is the `sampled` bit of the W3C trace-flags field.
+	traceFlagSampled byte = 0x01
+)
+
+// TraceParent is the parsed form of a W3C `traceparent` value,
+// see https://www.w3.org/TR/trace-context/#traceparent-header.
+type TraceParent struct {
+	TraceID  [16]byte
+	ParentID [8]byte
+	Flags    byte
+}
+
+// ParseTraceParent parses a W3C `traceparent` value such as
+// `00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01`.
+func ParseTraceParent(s string) (TraceParent, error) {
+	var tp TraceParent
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) < 4 {
+		return tp, fmt.Errorf("invalid traceparent %q", s)
+	}
+	version := parts[0]
+	if len(version) != 2 || version == "ff" {
+		return tp, fmt.Errorf("invalid traceparent version %q", version)
+	}
+	// Version 00 has exactly 4 fields, future versions may append more.
+	if version == traceParentVersion && len(parts) != 4 {
+		return tp, fmt.Errorf("invalid traceparent %q", s)
+	}
+	if err := decodeHexField(tp.TraceID[:], parts[1]); err != nil {
+		return tp, fmt.Errorf("invalid trace-id %q", parts[1])
+	}
+	if err := decodeHexField(tp.ParentID[:], parts[2]); err != nil {
+		return tp, fmt.Errorf("invalid parent-id %q", parts[2])
+	}
+	var flags [1]byte
+	if err := decodeHexField(flags[:], parts[3]); err != nil {
+		return tp, fmt.Errorf("invalid trace-flags %q", parts[3])
+	}
+	tp.Flags = flags[0]
+	if isZero(tp.TraceID[:]) || isZero(tp.ParentID[:]) {
+		return tp, fmt.Errorf("invalid traceparent %q: all-zero id", s)
+	}
+	return tp, nil
+}
+
+// NewTraceParent generates a TraceParent with random trace and parent ids.
+func NewTraceParent(sampled bool) TraceParent {
+	var tp TraceParent
+	for isZero(tp.TraceID[:]) {
+		_, _ = rand.Read(tp.TraceID[:])
+	}
+	for isZero(tp.ParentID[:]) {
+		_, _ = rand.Read(tp.ParentID[:])
+	}
+	if sampled {
+		tp.Flags |= traceFlagSampled
+	}
+	return tp
+}
+
+// Sampled returns whether the caller has recorded the trace.
+func (tp TraceParent) Sampled() bool {
+	return tp.Flags&traceFlagSampled != 0
+}
+
+// TraceIDString returns the lowercase hex encoding of the trace id.
+func (tp TraceParent) TraceIDString() string {
+	return hex.EncodeToString(tp.TraceID[:])
+}
+
+// ParentIDString returns the lowercase hex encoding of the parent id.
+func (tp TraceParent) ParentIDString() string {
+	return hex.EncodeToString(tp.ParentID[:])
+}
+
+// String implements fmt.Stringer and returns the `traceparent` encoding.
+func (tp TraceParent) String() string {
+	return fmt.Sprintf("%s-%s-%s-%02x", traceParentVersion, tp.TraceIDString(), tp.ParentIDString(), tp.Flags)
+}
+
+func decodeHexField(dst []byte, s string) error {
+	if len(s) != hex.EncodedLen(len(dst)) || strings.ToLower(s) != s {
+		return fmt.Errorf("invalid hex field %q", s)
+	}
+	_, err := hex.Decode(dst, []byte(s))
+	return err
+}
+
+func isZero(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,114 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pingcap/tidb/util/tracing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	tp, err := tracing.ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.NoError(t, err)
+	require.True(t, tp.Sampled())
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", tp.TraceIDString())
+	require.Equal(t, "00f067aa0ba902b7", tp.ParentIDString())
+	require.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", tp.String())
+
+	tp, err = tracing.ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	require.NoError(t, err)
+	require.False(t, tp.Sampled())
+
+	// Future versions may carry more fields.
+	_, err = tracing.ParseTraceParent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra")
+	require.NoError(t, err)
+
+	for _, s := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-zz",
+	} {
+		_, err = tracing.ParseTraceParent(s)
+		require.Error(t, err, s)
+	}
+
+	tp = tracing.NewTraceParent(true)
+	require.True(t, tp.Sampled())
+	parsed, err := tracing.ParseTraceParent(tp.String())
+	require.NoError(t, err)
+	require.Equal(t, tp, parsed)
+}
+
+func TestW3CSpanExport(t *testing.T) {
+	exporter := &tracing.MemoryExporter{}
+	tracing.SetExporter(exporter)
+	defer tracing.SetExporter(nil)
+
+	// A sampled caller is always traced.
+	sp := tracing.StartW3CSpan("root", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.NotNil(t, sp)
+	ctx := opentracing.ContextWithSpan(context.Background(), sp)
+	child, ctx := tracing.ChildSpanFromContxt(ctx, "child")
+	grandChild, _ := tracing.ChildSpanFromContxt(ctx, "grand_child")
+	grandChild.Finish()
+	child.Finish()
+	sp.Finish()
+
+	spans := exporter.Spans()
+	require.Len(t, spans, 3)
+	require.Equal(t, "grand_child", spans[0].Operation)
+	require.Equal(t, "child", spans[1].Operation)
+	require.Equal(t, "root", spans[2].Operation)
+	for _, span := range spans {
+		require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.TraceID)
+		require.Len(t, span.SpanID, 16)
+	}
+	require.Equal(t, "00f067aa0ba902b7", spans[2].ParentSpanID)
+	require.Equal(t, spans[2].SpanID, spans[1].ParentSpanID)
+	require.Equal(t, spans[1].SpanID, spans[0].ParentSpanID)
+
+	// An unsampled or malformed caller is not traced.
+	require.Nil(t, tracing.StartW3CSpan("root", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"))
+	require.Nil(t, tracing.StartW3CSpan("root", "invalid"))
+
+	// Without a caller, the sample rate decides.
+	exporter.Reset()
+	tracing.SetSampleRate(0)
+	require.Nil(t, tracing.StartW3CSpan("root", ""))
+	tracing.SetSampleRate(1)
+	defer tracing.SetSampleRate(0)
+	require.Equal(t, float64(1), tracing.GetSampleRate())
+	sp = tracing.StartW3CSpan("root", "")
+	require.NotNil(t, sp)
+	sp.Finish()
+	spans = exporter.Spans()
+	require.Len(t, spans, 1)
+	require.Len(t, spans[0].TraceID, 32)
+	require.Empty(t, spans[0].ParentSpanID)
+
+	// Nothing is traced without an exporter.
+	tracing.SetExporter(nil)
+	require.Nil(t, tracing.StartW3CSpan("root", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"))
+}